@@ -40,11 +40,17 @@ func (src *VSphereMachine) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.TagIDs = restored.Spec.TagIDs
 	dst.Spec.PowerOffMode = restored.Spec.PowerOffMode
 	dst.Spec.GuestSoftPowerOffTimeout = restored.Spec.GuestSoftPowerOffTimeout
+	dst.Spec.ClassName = restored.Spec.ClassName
 	for i := range dst.Spec.Network.Devices {
 		dst.Spec.Network.Devices[i].AddressesFromPools = restored.Spec.Network.Devices[i].AddressesFromPools
 		dst.Spec.Network.Devices[i].DHCP4Overrides = restored.Spec.Network.Devices[i].DHCP4Overrides
 		dst.Spec.Network.Devices[i].DHCP6Overrides = restored.Spec.Network.Devices[i].DHCP6Overrides
 		dst.Spec.Network.Devices[i].SkipIPAllocation = restored.Spec.Network.Devices[i].SkipIPAllocation
+		dst.Spec.Network.Devices[i].Primary = restored.Spec.Network.Devices[i].Primary
+		dst.Spec.Network.Devices[i].AdapterType = restored.Spec.Network.Devices[i].AdapterType
+		dst.Spec.Network.Devices[i].PhysicalFunction = restored.Spec.Network.Devices[i].PhysicalFunction
+		dst.Spec.Network.Devices[i].WakeOnLanEnabled = restored.Spec.Network.Devices[i].WakeOnLanEnabled
+		dst.Spec.Network.Devices[i].AllowGuestControl = restored.Spec.Network.Devices[i].AllowGuestControl
 	}
 
 	return nil
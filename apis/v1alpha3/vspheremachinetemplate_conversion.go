@@ -43,11 +43,17 @@ func (src *VSphereMachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.Template.Spec.AdditionalDisksGiB = restored.Spec.Template.Spec.AdditionalDisksGiB
 	dst.Spec.Template.Spec.PowerOffMode = restored.Spec.Template.Spec.PowerOffMode
 	dst.Spec.Template.Spec.GuestSoftPowerOffTimeout = restored.Spec.Template.Spec.GuestSoftPowerOffTimeout
+	dst.Spec.Template.Spec.ClassName = restored.Spec.Template.Spec.ClassName
 	for i := range dst.Spec.Template.Spec.Network.Devices {
 		dst.Spec.Template.Spec.Network.Devices[i].AddressesFromPools = restored.Spec.Template.Spec.Network.Devices[i].AddressesFromPools
 		dst.Spec.Template.Spec.Network.Devices[i].DHCP4Overrides = restored.Spec.Template.Spec.Network.Devices[i].DHCP4Overrides
 		dst.Spec.Template.Spec.Network.Devices[i].DHCP6Overrides = restored.Spec.Template.Spec.Network.Devices[i].DHCP6Overrides
 		dst.Spec.Template.Spec.Network.Devices[i].SkipIPAllocation = restored.Spec.Template.Spec.Network.Devices[i].SkipIPAllocation
+		dst.Spec.Template.Spec.Network.Devices[i].Primary = restored.Spec.Template.Spec.Network.Devices[i].Primary
+		dst.Spec.Template.Spec.Network.Devices[i].AdapterType = restored.Spec.Template.Spec.Network.Devices[i].AdapterType
+		dst.Spec.Template.Spec.Network.Devices[i].PhysicalFunction = restored.Spec.Template.Spec.Network.Devices[i].PhysicalFunction
+		dst.Spec.Template.Spec.Network.Devices[i].WakeOnLanEnabled = restored.Spec.Template.Spec.Network.Devices[i].WakeOnLanEnabled
+		dst.Spec.Template.Spec.Network.Devices[i].AllowGuestControl = restored.Spec.Template.Spec.Network.Devices[i].AllowGuestControl
 	}
 
 	return nil
@@ -665,6 +665,11 @@ func autoConvert_v1beta1_NetworkDeviceSpec_To_v1alpha3_NetworkDeviceSpec(in *v1b
 	// WARNING: in.DHCP4Overrides requires manual conversion: does not exist in peer-type
 	// WARNING: in.DHCP6Overrides requires manual conversion: does not exist in peer-type
 	// WARNING: in.SkipIPAllocation requires manual conversion: does not exist in peer-type
+	// WARNING: in.Primary requires manual conversion: does not exist in peer-type
+	// WARNING: in.AdapterType requires manual conversion: does not exist in peer-type
+	// WARNING: in.PhysicalFunction requires manual conversion: does not exist in peer-type
+	// WARNING: in.WakeOnLanEnabled requires manual conversion: does not exist in peer-type
+	// WARNING: in.AllowGuestControl requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1062,6 +1067,13 @@ func autoConvert_v1beta1_VSphereClusterSpec_To_v1alpha3_VSphereClusterSpec(in *v
 	out.IdentityRef = (*VSphereIdentityReference)(unsafe.Pointer(in.IdentityRef))
 	// WARNING: in.ClusterModules requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureDomainSelector requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourcePool requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHAuthorizedKeys requires manual conversion: does not exist in peer-type
+	// WARNING: in.Proxy requires manual conversion: does not exist in peer-type
+	// WARNING: in.MinHostFreeMemoryMiB requires manual conversion: does not exist in peer-type
+	// WARNING: in.DrainingDatastores requires manual conversion: does not exist in peer-type
+	// WARNING: in.RegistryMirrors requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneEndpointAddressFromPool requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1082,6 +1094,7 @@ func autoConvert_v1beta1_VSphereClusterStatus_To_v1alpha3_VSphereClusterStatus(i
 	out.Conditions = *(*Conditions)(unsafe.Pointer(&in.Conditions))
 	out.FailureDomains = *(*FailureDomains)(unsafe.Pointer(&in.FailureDomains))
 	// WARNING: in.VCenterVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourcePool requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1393,6 +1406,7 @@ func autoConvert_v1beta1_VSphereMachineSpec_To_v1alpha3_VSphereMachineSpec(in *v
 	out.FailureDomain = (*string)(unsafe.Pointer(in.FailureDomain))
 	// WARNING: in.PowerOffMode requires manual conversion: does not exist in peer-type
 	// WARNING: in.GuestSoftPowerOffTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.ClassName requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1644,6 +1658,12 @@ func autoConvert_v1beta1_VSphereVMSpec_To_v1alpha3_VSphereVMSpec(in *v1beta1.VSp
 	out.BiosUUID = in.BiosUUID
 	// WARNING: in.PowerOffMode requires manual conversion: does not exist in peer-type
 	// WARNING: in.GuestSoftPowerOffTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.DesiredPowerState requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHAuthorizedKeys requires manual conversion: does not exist in peer-type
+	// WARNING: in.Proxy requires manual conversion: does not exist in peer-type
+	// WARNING: in.MinHostFreeMemoryMiB requires manual conversion: does not exist in peer-type
+	// WARNING: in.DrainingDatastores requires manual conversion: does not exist in peer-type
+	// WARNING: in.RegistryMirrors requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1672,9 +1692,22 @@ func autoConvert_v1beta1_VSphereVMStatus_To_v1alpha3_VSphereVMStatus(in *v1beta1
 	out.Addresses = *(*[]string)(unsafe.Pointer(&in.Addresses))
 	out.CloneMode = CloneMode(in.CloneMode)
 	out.Snapshot = in.Snapshot
+	// WARNING: in.AdditionalDisksBusSharing requires manual conversion: does not exist in peer-type
 	out.RetryAfter = in.RetryAfter
+	// WARNING: in.ExcludedDatastores requires manual conversion: does not exist in peer-type
+	// WARNING: in.ExcludedHosts requires manual conversion: does not exist in peer-type
+	// WARNING: in.VMName requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeployedTemplate requires manual conversion: does not exist in peer-type
 	out.TaskRef = in.TaskRef
 	out.Network = *(*[]NetworkStatus)(unsafe.Pointer(&in.Network))
+	// WARNING: in.SerialPorts requires manual conversion: does not exist in peer-type
+	// WARNING: in.Disks requires manual conversion: does not exist in peer-type
+	// WARNING: in.Datastores requires manual conversion: does not exist in peer-type
+	// WARNING: in.CPUShares requires manual conversion: does not exist in peer-type
+	// WARNING: in.MemoryShares requires manual conversion: does not exist in peer-type
+	// WARNING: in.GuestDiskUsage requires manual conversion: does not exist in peer-type
+	// WARNING: in.GuestNetwork requires manual conversion: does not exist in peer-type
+	// WARNING: in.MigrationHistory requires manual conversion: does not exist in peer-type
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*Conditions)(unsafe.Pointer(&in.Conditions))
@@ -1740,8 +1773,10 @@ func Convert_v1alpha3_VirtualMachineCloneSpec_To_v1beta1_VirtualMachineCloneSpec
 
 func autoConvert_v1beta1_VirtualMachineCloneSpec_To_v1alpha3_VirtualMachineCloneSpec(in *v1beta1.VirtualMachineCloneSpec, out *VirtualMachineCloneSpec, s conversion.Scope) error {
 	out.Template = in.Template
+	// WARNING: in.FallbackTemplates requires manual conversion: does not exist in peer-type
 	out.CloneMode = CloneMode(in.CloneMode)
 	out.Snapshot = in.Snapshot
+	// WARNING: in.ProvisioningPriority requires manual conversion: does not exist in peer-type
 	out.Server = in.Server
 	out.Thumbprint = in.Thumbprint
 	out.Datacenter = in.Datacenter
@@ -1749,18 +1784,68 @@ func autoConvert_v1beta1_VirtualMachineCloneSpec_To_v1alpha3_VirtualMachineClone
 	out.Datastore = in.Datastore
 	out.StoragePolicyName = in.StoragePolicyName
 	out.ResourcePool = in.ResourcePool
+	// WARNING: in.ComputeCluster requires manual conversion: does not exist in peer-type
+	// WARNING: in.Host requires manual conversion: does not exist in peer-type
 	if err := Convert_v1beta1_NetworkSpec_To_v1alpha3_NetworkSpec(&in.Network, &out.Network, s); err != nil {
 		return err
 	}
 	out.NumCPUs = in.NumCPUs
 	out.NumCoresPerSocket = in.NumCoresPerSocket
 	out.MemoryMiB = in.MemoryMiB
+	// WARNING: in.ReconfigurePolicy requires manual conversion: does not exist in peer-type
 	out.DiskGiB = in.DiskGiB
 	// WARNING: in.AdditionalDisksGiB requires manual conversion: does not exist in peer-type
+	// WARNING: in.AdditionalDisksController requires manual conversion: does not exist in peer-type
+	// WARNING: in.SCSIControllerCount requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskEnableUUID requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskStorageIOAllocation requires manual conversion: does not exist in peer-type
+	// WARNING: in.SharedDisks requires manual conversion: does not exist in peer-type
 	out.CustomVMXKeys = *(*map[string]string)(unsafe.Pointer(&in.CustomVMXKeys))
 	// WARNING: in.TagIDs requires manual conversion: does not exist in peer-type
+	// WARNING: in.CustomAttributes requires manual conversion: does not exist in peer-type
 	// WARNING: in.PciDevices requires manual conversion: does not exist in peer-type
+	// WARNING: in.MemoryReservationLockedToMax requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourceAllocation requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourceDriftPolicy requires manual conversion: does not exist in peer-type
 	// WARNING: in.OS requires manual conversion: does not exist in peer-type
+	// WARNING: in.CloudInitDatasource requires manual conversion: does not exist in peer-type
 	// WARNING: in.HardwareVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootOptions requires manual conversion: does not exist in peer-type
+	// WARNING: in.NestedHardwareVirtualization requires manual conversion: does not exist in peer-type
+	// WARNING: in.CPUMMUVirtualization requires manual conversion: does not exist in peer-type
+	// WARNING: in.EVCMode requires manual conversion: does not exist in peer-type
+	// WARNING: in.Firmware requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecureBoot requires manual conversion: does not exist in peer-type
+	// WARNING: in.VirtualTPM requires manual conversion: does not exist in peer-type
+	// WARNING: in.TrustedLaunch requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecureBootFailureTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecureBootFailurePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.CloneConflictPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.DRSAutomationLevel requires manual conversion: does not exist in peer-type
+	// WARNING: in.OVA requires manual conversion: does not exist in peer-type
+	// WARNING: in.TimeZone requires manual conversion: does not exist in peer-type
+	// WARNING: in.NTPServers requires manual conversion: does not exist in peer-type
+	// WARNING: in.KernelArgs requires manual conversion: does not exist in peer-type
+	// WARNING: in.InjectedCommandsOrder requires manual conversion: does not exist in peer-type
+	// WARNING: in.Files requires manual conversion: does not exist in peer-type
+	// WARNING: in.PerformanceOptions requires manual conversion: does not exist in peer-type
+	// WARNING: in.ToolsUpgradePolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.ToolsSyncTimeWithHost requires manual conversion: does not exist in peer-type
+	// WARNING: in.SerialPorts requires manual conversion: does not exist in peer-type
+	// WARNING: in.CDROMs requires manual conversion: does not exist in peer-type
+	// WARNING: in.GuestIPWaitPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.PowerOnAfterClone requires manual conversion: does not exist in peer-type
+	// WARNING: in.QuestionPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.StorageAffinity requires manual conversion: does not exist in peer-type
+	// WARNING: in.LoggingOptions requires manual conversion: does not exist in peer-type
+	// WARNING: in.MemoryBacking requires manual conversion: does not exist in peer-type
+	// WARNING: in.NUMANodeAffinity requires manual conversion: does not exist in peer-type
+	// WARNING: in.SwapPlacement requires manual conversion: does not exist in peer-type
+	// WARNING: in.Isolation requires manual conversion: does not exist in peer-type
+	// WARNING: in.OVFEnvironment requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReadinessProbe requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxProvisioningTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProvisioningStalledPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.Hostname requires manual conversion: does not exist in peer-type
 	return nil
 }
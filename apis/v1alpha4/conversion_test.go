@@ -70,6 +70,13 @@ func overrideVSphereClusterSpecFieldsFuncs(runtimeserializer.CodecFactory) []int
 			c.FuzzNoCustom(in)
 			in.ClusterModules = nil
 			in.FailureDomainSelector = nil
+			in.ResourcePool = nil
+			in.SSHAuthorizedKeys = nil
+			in.Proxy = nil
+			in.MinHostFreeMemoryMiB = 0
+			in.DrainingDatastores = nil
+			in.RegistryMirrors = nil
+			in.ControlPlaneEndpointAddressFromPool = nil
 		},
 	}
 }
@@ -79,6 +86,7 @@ func overrideVSphereClusterStatusFieldsFuncs(runtimeserializer.CodecFactory) []i
 		func(in *infrav1.VSphereClusterStatus, c fuzz.Continue) {
 			c.FuzzNoCustom(in)
 			in.VCenterVersion = ""
+			in.ResourcePool = ""
 		},
 	}
 }
@@ -95,14 +103,68 @@ func CustomSpecNewFieldFuzzer(in *infrav1.VirtualMachineCloneSpec, c fuzz.Contin
 
 	in.PciDevices = nil
 	in.AdditionalDisksGiB = nil
+	in.AdditionalDisksController = nil
 	in.OS = ""
 	in.HardwareVersion = ""
+	in.BootOptions = nil
+	in.CloneConflictPolicy = ""
+	in.DRSAutomationLevel = ""
+	in.OVA = nil
+	in.TimeZone = ""
+	in.NTPServers = nil
+	in.PerformanceOptions = nil
+	in.ToolsUpgradePolicy = ""
+	in.ToolsSyncTimeWithHost = nil
+	in.CDROMs = nil
+	in.GuestIPWaitPolicy = ""
+	in.StorageAffinity = nil
+	in.LoggingOptions = nil
+	in.OVFEnvironment = nil
+	in.ReadinessProbe = nil
+	in.MemoryReservationLockedToMax = nil
+	in.NestedHardwareVirtualization = nil
+	in.CPUMMUVirtualization = ""
+	in.ResourceAllocation = nil
+	in.DiskStorageIOAllocation = nil
+	in.CloudInitDatasource = ""
+	in.Hostname = nil
+	in.SharedDisks = nil
+	in.ReconfigurePolicy = ""
+	in.Firmware = ""
+	in.SecureBoot = nil
+	in.VirtualTPM = nil
+	in.TrustedLaunch = nil
+	in.CustomAttributes = nil
+	in.PowerOnAfterClone = nil
+	in.QuestionPolicy = ""
+	in.EVCMode = ""
+	in.InjectedCommandsOrder = ""
+	in.ResourceDriftPolicy = ""
+	in.Files = nil
+	in.FallbackTemplates = nil
+	in.MemoryBacking = nil
+	in.Isolation = nil
+	in.KernelArgs = nil
+	in.ProvisioningPriority = 0
+	in.SerialPorts = nil
+	in.SCSIControllerCount = 0
+	in.DiskEnableUUID = nil
+	in.Host = ""
+	in.ComputeCluster = ""
+	in.NUMANodeAffinity = nil
+	in.SwapPlacement = ""
+	in.MaxProvisioningTime = nil
+	in.ProvisioningStalledPolicy = ""
+	in.SecureBootFailureTimeout = nil
+	in.SecureBootFailurePolicy = ""
 }
 
 func CustomStatusNewFieldFuzzer(in *infrav1.VSphereVMStatus, c fuzz.Continue) {
 	c.FuzzNoCustom(in)
 
 	in.Host = ""
+	in.AdditionalDisksBusSharing = ""
 	in.ModuleUUID = nil
 	in.VMRef = ""
+	in.SerialPorts = nil
 }
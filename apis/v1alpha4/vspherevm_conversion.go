@@ -39,12 +39,35 @@ func (src *VSphereVM) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.AdditionalDisksGiB = restored.Spec.AdditionalDisksGiB
 	dst.Spec.PowerOffMode = restored.Spec.PowerOffMode
 	dst.Spec.GuestSoftPowerOffTimeout = restored.Spec.GuestSoftPowerOffTimeout
+	dst.Spec.SSHAuthorizedKeys = restored.Spec.SSHAuthorizedKeys
+	dst.Spec.Proxy = restored.Spec.Proxy
+	dst.Spec.MinHostFreeMemoryMiB = restored.Spec.MinHostFreeMemoryMiB
+	dst.Spec.DrainingDatastores = restored.Spec.DrainingDatastores
+	dst.Spec.RegistryMirrors = restored.Spec.RegistryMirrors
+	dst.Spec.DesiredPowerState = restored.Spec.DesiredPowerState
 	dst.Status.Host = restored.Status.Host
+	dst.Status.SerialPorts = restored.Status.SerialPorts
+	dst.Status.Disks = restored.Status.Disks
+	dst.Status.Datastores = restored.Status.Datastores
+	dst.Status.CPUShares = restored.Status.CPUShares
+	dst.Status.MemoryShares = restored.Status.MemoryShares
+	dst.Status.ExcludedDatastores = restored.Status.ExcludedDatastores
+	dst.Status.ExcludedHosts = restored.Status.ExcludedHosts
+	dst.Status.GuestDiskUsage = restored.Status.GuestDiskUsage
+	dst.Status.GuestNetwork = restored.Status.GuestNetwork
+	dst.Status.MigrationHistory = restored.Status.MigrationHistory
+	dst.Status.VMName = restored.Status.VMName
+	dst.Status.DeployedTemplate = restored.Status.DeployedTemplate
 	for i := range dst.Spec.Network.Devices {
 		dst.Spec.Network.Devices[i].AddressesFromPools = restored.Spec.Network.Devices[i].AddressesFromPools
 		dst.Spec.Network.Devices[i].DHCP4Overrides = restored.Spec.Network.Devices[i].DHCP4Overrides
 		dst.Spec.Network.Devices[i].DHCP6Overrides = restored.Spec.Network.Devices[i].DHCP6Overrides
 		dst.Spec.Network.Devices[i].SkipIPAllocation = restored.Spec.Network.Devices[i].SkipIPAllocation
+		dst.Spec.Network.Devices[i].Primary = restored.Spec.Network.Devices[i].Primary
+		dst.Spec.Network.Devices[i].AdapterType = restored.Spec.Network.Devices[i].AdapterType
+		dst.Spec.Network.Devices[i].PhysicalFunction = restored.Spec.Network.Devices[i].PhysicalFunction
+		dst.Spec.Network.Devices[i].WakeOnLanEnabled = restored.Spec.Network.Devices[i].WakeOnLanEnabled
+		dst.Spec.Network.Devices[i].AllowGuestControl = restored.Spec.Network.Devices[i].AllowGuestControl
 	}
 
 	return nil
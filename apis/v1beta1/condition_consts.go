@@ -57,6 +57,16 @@ const (
 	// NOTE: This reason does not apply to VSphereVM (this state happens before the VSphereVM is actually created).
 	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
 
+	// MachineClassNotFoundReason (Severity=Warning) documents a VSphereMachine referencing a
+	// VSphereMachineClass which does not exist.
+	//
+	// NOTE: This reason does not apply to VSphereVM (this state happens before the VSphereVM is actually created).
+	MachineClassNotFoundReason = "MachineClassNotFound"
+
+	// WaitingForPreTerminateHookReason (Severity=Info) documents a deleted VSphereVM waiting for the
+	// pre-terminate hooks of its Machine to be cleared before the VM is powered off and deleted.
+	WaitingForPreTerminateHookReason = "WaitingForPreTerminateHook"
+
 	// WaitingForStaticIPAllocationReason (Severity=Info) documents a VSphereVM waiting for the allocation of
 	// a static IP address.
 	WaitingForStaticIPAllocationReason = "WaitingForStaticIPAllocation"
@@ -67,9 +77,17 @@ const (
 	// relevant IP address  to show up on the VM.
 	WaitingForIPAllocationReason = "WaitingForIPAllocation"
 
+	// PlacementProfileInvalidReason (Severity=Error) documents a VSphereMachine controller detecting
+	// the placement profile selected by the Machine does not exist or defines unknown keys.
+	PlacementProfileInvalidReason = "PlacementProfileInvalid"
+
 	// CloningReason documents (Severity=Info) a VSphereMachine/VSphereVM currently executing the clone operation.
 	CloningReason = "Cloning"
 
+	// WaitingForProvisioningPriorityReason (Severity=Info) documents a VSphereVM deferring the clone of
+	// its VM while VSphereVMs of a higher provisioning priority wait to be cloned.
+	WaitingForProvisioningPriorityReason = "WaitingForProvisioningPriority"
+
 	// CloningFailedReason (Severity=Warning) documents a VSphereMachine/VSphereVM controller detecting
 	// an error while provisioning; those kind of errors are usually transient and failed provisioning
 	// are automatically re-tried by the controller.
@@ -88,6 +106,115 @@ const (
 	// reconciled by the controller.
 	NotFoundByBIOSUUIDReason = "NotFoundByBIOSUUID"
 
+	// CloneConflictReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an existing VM with the name of the VSphereVM which was not provisioned for it.
+	CloneConflictReason = "CloneConflict"
+
+	// HostnameFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// derive the hostname of the guest from the IP address of the VM, e.g. because the
+	// reverse DNS lookup failed or the result is not a valid hostname.
+	HostnameFailedReason = "HostnameFailed"
+
+	// NetworkBootFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// that the network device booting the VM from the network is missing, disconnected or
+	// not attached to the provisioning network.
+	NetworkBootFailedReason = "NetworkBootFailed"
+
+	// SharedDiskFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// attach a shared disk to the VM, e.g. because the disk does not exist or is not
+	// eagerZeroedThick provisioned.
+	SharedDiskFailedReason = "SharedDiskFailed"
+
+	// DRSOverrideFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an error while overriding the DRS automation level of the VM, e.g. because DRS is not
+	// enabled on the compute cluster.
+	DRSOverrideFailedReason = "DRSOverrideFailed"
+
+	// OVAImportFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an error while importing the template of the VM from an OVA, e.g. because the checksum
+	// of the downloaded OVA does not match.
+	OVAImportFailedReason = "OVAImportFailed"
+
+	// ISONotFoundReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an ISO image to be inserted into a CD-ROM drive of the VM does not exist.
+	ISONotFoundReason = "ISONotFound"
+
+	// StorageAffinityFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an error while keeping the VM on the hosts which store its data, e.g. because the storage
+	// policy is not a vSAN policy or the VM-Host rule of the DRS groups does not exist.
+	StorageAffinityFailedReason = "StorageAffinityFailed"
+
+	// SriovUnavailableReason (Severity=Warning) documents a VSphereVM controller detecting
+	// no SR-IOV virtual function is available for a network adapter of the VM, e.g. because
+	// SR-IOV is not active on the physical function.
+	SriovUnavailableReason = "SriovUnavailable"
+
+	// NestedHardwareVirtualizationNotSupportedReason (Severity=Warning) documents a VSphereVM
+	// controller detecting the host of the VM does not support nested hardware virtualization.
+	NestedHardwareVirtualizationNotSupportedReason = "NestedHardwareVirtualizationNotSupported"
+
+	// CPUMMUVirtualizationNotSupportedReason (Severity=Warning) documents a VSphereVM whose CPU/MMU
+	// virtualization mode is not supported by the host of the VM.
+	CPUMMUVirtualizationNotSupportedReason = "CPUMMUVirtualizationNotSupported"
+
+	// EVCModeNotSupportedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// the EVC mode of the VM is unknown or not supported by the cluster of the VM.
+	EVCModeNotSupportedReason = "EVCModeNotSupported"
+
+	// HugePagesNotSupportedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// a host of the VM does not back the memory of VMs by huge pages.
+	HugePagesNotSupportedReason = "HugePagesNotSupported"
+
+	// NUMANodeNotAvailableReason (Severity=Warning) documents a VSphereVM controller detecting
+	// a host of the VM does not have a NUMA node of the NUMA node affinity of the VM.
+	NUMANodeNotAvailableReason = "NUMANodeNotAvailable"
+
+	// HostLocalSwapNotAvailableReason (Severity=Warning) documents a VSphereVM controller
+	// detecting a host of the VM has no local swap datastore for the host local swap file.
+	HostLocalSwapNotAvailableReason = "HostLocalSwapNotAvailable"
+
+	// StorageIOControlDisabledReason (Severity=Warning) documents a VSphereVM controller detecting
+	// Storage I/O Control is not enabled on a datastore of the disks of the VM, so the Storage I/O
+	// allocation of the disks cannot be applied.
+	StorageIOControlDisabledReason = "StorageIOControlDisabled"
+
+	// NoCloudSeedFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an error while attaching the seed ISO image of the cloud-init NoCloud datasource to the VM.
+	NoCloudSeedFailedReason = "NoCloudSeedFailed"
+
+	// DatastoreFullReason (Severity=Warning) documents a VSphereVM controller detecting
+	// the datastore of the VM ran out of space while cloning the VM.
+	DatastoreFullReason = "DatastoreFull"
+
+	// FilesInvalidReason (Severity=Warning) documents a VSphereVM controller failing to add the
+	// files of the spec to the bootstrap data, e.g. because a Secret does not exist or the
+	// files exceed the size limit.
+	FilesInvalidReason = "FilesInvalid"
+
+	// RegistryMirrorsInvalidReason (Severity=Warning) documents a VSphereVM controller failing to
+	// add the registry mirrors to the bootstrap data, e.g. because the Secret of a CA certificate
+	// does not exist.
+	RegistryMirrorsInvalidReason = "RegistryMirrorsInvalid"
+
+	// InsufficientHostMemoryReason (Severity=Warning) documents a VSphereVM controller detecting
+	// no host of the resource pool of the VM has the minimum free memory required for placing
+	// the VM.
+	InsufficientHostMemoryReason = "InsufficientHostMemory"
+
+	// HostUnavailableReason (Severity=Warning) documents a VSphereVM controller detecting
+	// the host of the VM entered maintenance mode or disconnected while cloning the VM, or
+	// no host of the resource pool of the VM being available for placing the VM.
+	HostUnavailableReason = "HostUnavailable"
+
+	// WaitingForReadinessProbeReason (Severity=Info) documents a VSphereVM waiting for the
+	// readiness probe of the guest to succeed.
+	WaitingForReadinessProbeReason = "WaitingForReadinessProbe"
+
+	// ReadinessProbeFailedReason (Severity=Warning) documents a VSphereVM controller detecting
+	// an error while running the readiness probe of the guest, e.g. because the secret with the
+	// credentials of the guest user does not exist.
+	ReadinessProbeFailedReason = "ReadinessProbeFailed"
+
 	// TaskFailure (Severity=Warning) documents a VSphereMachine/VSphere task failure; the reconcile look will automatically
 	// retry the operation, but a user intervention might be required to fix the problem.
 	TaskFailure = "TaskFailure"
@@ -101,6 +228,10 @@ const (
 	// TagsAttachmentFailedReason (Severity=Error) documents a VSphereMachine/VSphereVM tags attachment failure.
 	TagsAttachmentFailedReason = "TagsAttachmentFailed"
 
+	// CustomAttributesFailedReason (Severity=Warning) documents a VSphereVM controller failing
+	// to set the custom attributes of the VM.
+	CustomAttributesFailedReason = "CustomAttributesFailed"
+
 	// PCIDevicesDetachedCondition documents the status of the attached PCI devices on the VSphereVM.
 	// It is a negative condition to notify the user that the device(s) is no longer attached to
 	// the underlying VM and would require manual intervention to fix the situation.
@@ -143,6 +274,23 @@ const (
 	ClusterModuleSetupFailedReason = "ClusterModuleSetupFailed"
 )
 
+const (
+	// ResourcePoolReadyCondition documents the status of the resource pool created for the VSphereCluster object.
+	ResourcePoolReadyCondition clusterv1.ConditionType = "ResourcePoolReady"
+
+	// ParentResourcePoolNotFoundReason (Severity=Error) documents that the parent resource pool
+	// of the resource pool created for the VSphereCluster cannot be found.
+	ParentResourcePoolNotFoundReason = "ParentResourcePoolNotFound"
+
+	// ResourcePoolPrivilegesMissingReason (Severity=Error) documents that the principal used by the
+	// VSphereCluster is not allowed to create or delete resource pools under the parent resource pool.
+	ResourcePoolPrivilegesMissingReason = "ResourcePoolPrivilegesMissing"
+
+	// ResourcePoolCreationFailedReason (Severity=Warning) documents a controller detecting
+	// issues when creating the resource pool for the VSphereCluster.
+	ResourcePoolCreationFailedReason = "ResourcePoolCreationFailed"
+)
+
 const (
 	// CredentialsAvailableCondidtion is used by VSphereClusterIdentity when a credential
 	// secret is available and unused by other VSphereClusterIdentities.
@@ -225,6 +373,235 @@ const (
 	IPAddressClaimNotFoundReason = "IPAddressClaimNotFound"
 )
 
+const (
+	// ReconfigurePendingCondition documents changes of the hardware of the VSphereVM which
+	// are deferred until the VM is powered off or hot-added. It is a negative condition which
+	// is removed once the changes are applied.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	ReconfigurePendingCondition clusterv1.ConditionType = "ReconfigurePending"
+
+	// ReconfigureDeferredUntilPowerOffReason (Severity=Info) documents changes of the
+	// hardware of the VSphereVM which are applied when the VM is next powered off.
+	ReconfigureDeferredUntilPowerOffReason = "ReconfigureDeferredUntilPowerOff"
+
+	// ApplyingDeferredReconfigureReason (Severity=Info) documents changes of the hardware of
+	// the VSphereVM which are applied while the VM is powered off.
+	ApplyingDeferredReconfigureReason = "ApplyingDeferredReconfigure"
+
+	// HotAddingReason (Severity=Info) documents increases of the CPUs or memory of the
+	// VSphereVM which are hot-added to the powered on VM.
+	HotAddingReason = "HotAdding"
+)
+
+const (
+	// NodeCapacityRefreshedCondition documents whether the node of a VSphereVM reports the
+	// capacity of its VM after a deferred reconfigure or hot-add of the CPUs or memory of the
+	// VM. It is only set if the NodeCapacityRefresh feature gate is enabled. While it is false
+	// with the WaitingForNodeCapacity reason, the node is cordoned. It is removed once the
+	// kubelet restarted and the node was uncordoned.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	NodeCapacityRefreshedCondition clusterv1.ConditionType = "NodeCapacityRefreshed"
+
+	// WaitingForNodeCapacityReason (Severity=Info) documents the node of a VSphereVM being
+	// cordoned until the kubelet restarted on the reconfigured VM and reports its capacity.
+	WaitingForNodeCapacityReason = "WaitingForNodeCapacity"
+
+	// NodeCapacityRefreshTimedOutReason (Severity=Warning) documents the node of a VSphereVM
+	// which was uncordoned as the kubelet did not restart within the node capacity refresh
+	// timeout of the controller.
+	NodeCapacityRefreshTimedOutReason = "NodeCapacityRefreshTimedOut"
+)
+
+const (
+	// ProvisioningStalledCondition documents a VSphereVM which did not become ready within
+	// its maximum provisioning time. It is a negative condition which is removed once the
+	// VSphereVM is ready.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	ProvisioningStalledCondition clusterv1.ConditionType = "ProvisioningStalled"
+
+	// MaxProvisioningTimeExceededReason (Severity=Warning) documents a VSphereVM which is not
+	// ready after its maximum provisioning time.
+	MaxProvisioningTimeExceededReason = "MaxProvisioningTimeExceeded"
+)
+
+const (
+	// SecureBootLikelyFailedCondition documents a VSphereVM whose VM runs with Secure Boot
+	// but has no guest heartbeat within its Secure Boot failure timeout. It is a negative
+	// condition which is removed once the guest sends a heartbeat, unless Secure Boot was
+	// disabled.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	SecureBootLikelyFailedCondition clusterv1.ConditionType = "SecureBootLikelyFailed"
+
+	// NoGuestHeartbeatReason (Severity=Warning) documents a VM with Secure Boot which has no
+	// guest heartbeat within the Secure Boot failure timeout.
+	NoGuestHeartbeatReason = "NoGuestHeartbeat"
+
+	// DisablingSecureBootReason (Severity=Warning) documents a VM which likely failed to boot
+	// with Secure Boot and is powered off to boot again with Secure Boot disabled.
+	DisablingSecureBootReason = "DisablingSecureBoot"
+
+	// SecureBootDisabledReason (Severity=Warning) documents a VM which likely failed to boot
+	// with Secure Boot and runs with Secure Boot disabled.
+	SecureBootDisabledReason = "SecureBootDisabled"
+)
+
+const (
+	// MarkedAsTemplateCondition documents whether the VM of a VSphereVM with the
+	// MarkAsTemplateAnnotation is marked as a template. It is only set while the VSphereVM
+	// has the annotation.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	MarkedAsTemplateCondition clusterv1.ConditionType = "MarkedAsTemplate"
+
+	// MarkAsTemplatePendingReason (Severity=Info) documents a VM which is marked as a template
+	// once the VSphereVM is ready and the VM is powered off.
+	MarkAsTemplatePendingReason = "MarkAsTemplatePending"
+
+	// MarkAsTemplateFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// mark the VM as a template.
+	MarkAsTemplateFailedReason = "MarkAsTemplateFailed"
+)
+
+const (
+	// VMRedeployedCondition documents whether the VM of a VSphereVM with the RedeployAnnotation
+	// is redeployed from the template of the VSphereVM. It is only set once the template was
+	// changed.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	VMRedeployedCondition clusterv1.ConditionType = "VMRedeployed"
+
+	// RedeployPoweringOffReason (Severity=Info) documents a VM being powered off to be
+	// redeployed from a new template.
+	RedeployPoweringOffReason = "RedeployPoweringOff"
+
+	// RedeployDestroyingReason (Severity=Info) documents a VM being destroyed to be redeployed
+	// from a new template.
+	RedeployDestroyingReason = "RedeployDestroying"
+
+	// RedeployCloningReason (Severity=Info) documents a VM being cloned from the new template
+	// it is redeployed from.
+	RedeployCloningReason = "RedeployCloning"
+
+	// RedeployFailedReason (Severity=Warning) documents a VSphereVM controller failing to power
+	// off or destroy a VM to redeploy it.
+	RedeployFailedReason = "RedeployFailed"
+)
+
+const (
+	// GuestNetworkReconfiguredCondition documents whether the guest of a provisioned VSphereVM
+	// applied the network metadata after it changed, which requires a reboot of the guest. It is
+	// only set if the GuestNetworkReconfiguration feature gate is enabled.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	GuestNetworkReconfiguredCondition clusterv1.ConditionType = "GuestNetworkReconfigured"
+
+	// GuestRebootPendingReason (Severity=Info) documents a VSphereVM controller which updated
+	// the network metadata of the VM and reboots the guest to apply it.
+	GuestRebootPendingReason = "GuestRebootPending"
+
+	// GuestRebootFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// reboot the guest to apply the network metadata.
+	GuestRebootFailedReason = "GuestRebootFailed"
+)
+
+const (
+	// VMReconfiguredCondition documents the reconfiguration of the VM of a VSphereVM which
+	// corrects its drift from the spec. All drifted attributes are changed by a single
+	// reconfigure task. The condition is only set once the VM was reconfigured.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	VMReconfiguredCondition clusterv1.ConditionType = "VMReconfigured"
+
+	// ReconfiguringReason (Severity=Info) documents a VSphereVM controller reconfiguring the
+	// VM; the message lists the attributes which are changed.
+	ReconfiguringReason = "Reconfiguring"
+
+	// ReconfigureFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// reconfigure the VM.
+	ReconfigureFailedReason = "ReconfigureFailed"
+
+	// ConcurrentModificationReason (Severity=Info) documents vCenter rejecting the reconfigure
+	// of the VM of a VSphereVM, because the VM was changed by another tool since its drift was
+	// detected. The drift is detected again and the reconfigure is retried.
+	ConcurrentModificationReason = "ConcurrentModification"
+
+	// MovingToFolderReason (Severity=Info) documents a VSphereVM controller moving the VM
+	// into the folder of the spec after the folder changed.
+	MovingToFolderReason = "MovingToFolder"
+)
+
+const (
+	// ResourcesConvergedCondition documents whether the resource allocation and the disk Storage
+	// I/O allocation of the VM of a VSphereVM match its spec, e.g. once a rollout of the template
+	// of a MachineDeployment converged. It is only set if either of them is defined.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	ResourcesConvergedCondition clusterv1.ConditionType = "ResourcesConverged"
+
+	// ResourcesDriftedReason documents the VM of a VSphereVM whose resources drifted from the
+	// spec; the message lists the drifted attributes. The severity is Info if the drift is
+	// corrected and Warning if it is only reported.
+	ResourcesDriftedReason = "ResourcesDrifted"
+)
+
+const (
+	// TrustedLaunchCondition documents whether the VM of a VSphereVM with TrustedLaunch runs
+	// with the efi firmware, Secure Boot and a virtual TPM. It is only set if TrustedLaunch is
+	// true.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	TrustedLaunchCondition clusterv1.ConditionType = "TrustedLaunchReady"
+
+	// TrustedLaunchPendingReason (Severity=Info) documents changes of the firmware, Secure Boot
+	// or virtual TPM of the VM which are applied when the VM is next powered off.
+	TrustedLaunchPendingReason = "TrustedLaunchPending"
+
+	// TrustedLaunchPrerequisitesNotMetReason (Severity=Warning) documents a VSphereVM controller
+	// detecting the VM does not fulfill the prerequisites of a virtual TPM, e.g. because its
+	// hardware version is older than vmx-14.
+	TrustedLaunchPrerequisitesNotMetReason = "TrustedLaunchPrerequisitesNotMet"
+
+	// FirmwareReconfigureFailedReason (Severity=Warning) documents a VSphereVM controller
+	// failing to change the firmware, Secure Boot or virtual TPM of the VM.
+	FirmwareReconfigureFailedReason = "FirmwareReconfigureFailed"
+)
+
+const (
+	// BootstrapDataAvailableCondition documents whether the bootstrap data of a VSphereVM is
+	// available to the guest of its VM before the VM is powered on, i.e. set in the guestinfo
+	// variables or attached as NoCloud seed CD-ROM.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	BootstrapDataAvailableCondition clusterv1.ConditionType = "BootstrapDataAvailable"
+
+	// BootstrapDataMissingReason (Severity=Warning) documents a VSphereVM controller detecting
+	// the bootstrap data was removed from the VM, e.g. by another tool, which is re-applied before
+	// the VM is powered on.
+	BootstrapDataMissingReason = "BootstrapDataMissing"
+
+	// BootstrapDataDetachedReason (Severity=Info) documents a VSphereVM controller detaching the
+	// NoCloud seed CD-ROM from the VM once it was ready, which is attached again before the VM
+	// is powered on.
+	BootstrapDataDetachedReason = "BootstrapDataDetached"
+)
+
+const (
+	// VMQuestionAnsweredCondition documents whether the VM of a VSphereVM is blocked by a question,
+	// e.g. whether the VM was moved or copied when powering on a cloned VM. It is only set once
+	// the VM asked a question.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	VMQuestionAnsweredCondition clusterv1.ConditionType = "VMQuestionAnswered"
+
+	// VMQuestionPendingReason (Severity=Warning) documents a question of the VM which is not answered
+	// according to the question policy of the VSphereVM and requires a user to answer it in vCenter.
+	VMQuestionPendingReason = "VMQuestionPending"
+)
+
 const (
 	// GuestSoftPowerOffSucceededCondition documents the status of performing guest initiated
 	// graceful shutdown.
@@ -238,3 +615,89 @@ const (
 	// shutdown request fails.
 	GuestSoftPowerOffFailedReason = "GuestSoftPowerOffFailed"
 )
+
+const (
+	// StorageCompliantCondition documents whether the VM of a VSphereVM and its disks comply with
+	// the storage policy of the VSphereVM, e.g. whether vSAN objects are degraded after a disk
+	// group failure. It is only set if the storage compliance check interval of the controller
+	// is not zero.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	StorageCompliantCondition clusterv1.ConditionType = "StorageCompliant"
+
+	// StorageNonCompliantReason (Severity=Warning) documents the VM of a VSphereVM or its disks
+	// not complying with the storage policy; the message lists the non-compliant objects.
+	StorageNonCompliantReason = "StorageNonCompliant"
+
+	// StorageComplianceCheckFailedReason (Severity=Warning) documents a VSphereVM controller
+	// failing to get the storage policy compliance of the VM.
+	StorageComplianceCheckFailedReason = "StorageComplianceCheckFailed"
+)
+
+const (
+	// HostAvailableCondition documents whether the ESXi host of the VM of a VSphereVM is
+	// available, i.e. neither entering nor in maintenance mode. It is only set if the host
+	// maintenance check interval of the controller is not zero, and is removed once the
+	// HostMaintenanceTaint was removed from the node of the VSphereVM.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	HostAvailableCondition clusterv1.ConditionType = "HostAvailable"
+
+	// EnteringMaintenanceModeReason (Severity=Info) documents the ESXi host of a VM entering
+	// maintenance mode, e.g. while DRS migrates its VMs to other hosts.
+	EnteringMaintenanceModeReason = "EnteringMaintenanceMode"
+
+	// InMaintenanceModeReason (Severity=Info) documents the ESXi host of a VM being in
+	// maintenance mode.
+	InMaintenanceModeReason = "InMaintenanceMode"
+)
+
+const (
+	// DatastoresDrainedCondition documents whether the disks and files of the VM of a VSphereVM
+	// are off the draining datastores of the VSphereVM. It is only set once datastores are
+	// drained.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	DatastoresDrainedCondition clusterv1.ConditionType = "DatastoresDrained"
+
+	// RelocatingReason (Severity=Info) documents a VSphereVM controller relocating the VM off
+	// a draining datastore by storage vMotion; the message reports the progress.
+	RelocatingReason = "Relocating"
+
+	// RelocationFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// relocate the VM off a draining datastore, e.g. as no other datastore is available.
+	RelocationFailedReason = "RelocationFailed"
+)
+
+const (
+	// HostPinnedCondition documents whether the VM of a VSphereVM runs on the host of its
+	// spec. It is only set if the spec has a host.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	HostPinnedCondition clusterv1.ConditionType = "HostPinned"
+
+	// MigratingToHostReason (Severity=Info) documents a VSphereVM controller migrating the VM
+	// back to the host of its spec by vMotion.
+	MigratingToHostReason = "MigratingToHost"
+
+	// HostPinningFailedReason (Severity=Warning) documents a VSphereVM controller failing to
+	// migrate the VM back to the host of its spec, e.g. as the host is not connected.
+	HostPinningFailedReason = "HostPinningFailed"
+)
+
+const (
+	// VMNameSyncedCondition documents whether the name of the VM of a VSphereVM in vCenter
+	// matches the name of the VSphereVM. It is only set once the names differed while the
+	// VMRename feature gate is enabled.
+	//
+	// NOTE: This condition does not apply to VSphereMachine.
+	VMNameSyncedCondition clusterv1.ConditionType = "VMNameSynced"
+
+	// RenamingReason (Severity=Info) documents a VSphereVM controller renaming the VM to the
+	// name of the VSphereVM.
+	RenamingReason = "Renaming"
+
+	// RenameFailedReason (Severity=Warning) documents a VSphereVM controller failing to rename
+	// the VM, e.g. as the name exceeds the maximum length or another VM in the folder has it.
+	RenameFailedReason = "RenameFailed"
+)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -50,6 +51,13 @@ const (
 	// clone mode, but it also prevents expanding a VMs disk beyond the size of
 	// the source VM/template.
 	LinkedClone CloneMode = "linkedClone"
+
+	// InstantClone means resulting VMs are forked from the running and frozen
+	// source VM, sharing its memory and disk state. This is the fastest clone
+	// mode, but the source VM must be prepared for instant clones and the
+	// bootstrap data is only delivered through guestinfo, as instant clones
+	// cannot be customized by guest customization.
+	InstantClone CloneMode = "instantClone"
 )
 
 // OS is the type of Operating System the virtual machine uses.
@@ -63,6 +71,37 @@ const (
 	Windows OS = "Windows"
 )
 
+// CloudInitDatasource is the cloud-init datasource the image of the virtual machine
+// reads its metadata and user data from.
+// +kubebuilder:validation:Enum=VMware;NoCloud
+type CloudInitDatasource string
+
+const (
+	// CloudInitDatasourceVMware delivers the metadata and user data through the
+	// guestinfo variables of the virtual machine.
+	CloudInitDatasourceVMware CloudInitDatasource = "VMware"
+
+	// CloudInitDatasourceNoCloud delivers the metadata and user data on an ISO
+	// image with the label cidata, attached to an additional CD-ROM drive.
+	CloudInitDatasourceNoCloud CloudInitDatasource = "NoCloud"
+)
+
+// VirtualMachineReconfigurePolicy defines how changes of the hardware of existing
+// virtual machines are applied.
+// +kubebuilder:validation:Enum=deferredUntilPowerOff;hotAdd
+type VirtualMachineReconfigurePolicy string
+
+const (
+	// ReconfigurePolicyDeferredUntilPowerOff means changes are applied when the
+	// virtual machine is next observed powered off.
+	ReconfigurePolicyDeferredUntilPowerOff VirtualMachineReconfigurePolicy = "deferredUntilPowerOff"
+
+	// ReconfigurePolicyHotAdd means increases of the CPUs and memory are hot-added to
+	// powered on virtual machines which have CPU or memory hot-add enabled. Other
+	// changes are applied when the virtual machine is next observed powered off.
+	ReconfigurePolicyHotAdd VirtualMachineReconfigurePolicy = "hotAdd"
+)
+
 // VirtualMachinePowerOpMode represents the various power operation modes
 // when powering off or suspending a VM.
 // +kubebuilder:validation:Enum=hard;soft;trySoft
@@ -91,6 +130,216 @@ const (
 	VirtualMachinePowerOpModeTrySoft VirtualMachinePowerOpMode = "trySoft"
 )
 
+// CloneConflictPolicy describes how to handle an existing virtual machine
+// which has the name of the virtual machine to be cloned, but which was not
+// provisioned for it, e.g. the remains of a clone operation which failed
+// partway.
+// +kubebuilder:validation:Enum=adopt;delete;fail;suffix
+type CloneConflictPolicy string
+
+const (
+	// CloneConflictPolicyAdopt indicates the existing virtual machine is
+	// adopted and reconciled as if it had been cloned for this object,
+	// unless it was created for another object, which is reported as an
+	// error.
+	CloneConflictPolicyAdopt CloneConflictPolicy = "adopt"
+
+	// CloneConflictPolicyDelete indicates the existing virtual machine is
+	// deleted and the clone operation is retried. Only virtual machines
+	// which were created by an earlier clone attempt for the same object
+	// are deleted, any other conflicting virtual machine is left untouched
+	// and reported as an error.
+	CloneConflictPolicyDelete CloneConflictPolicy = "delete"
+
+	// CloneConflictPolicyFail indicates the existing virtual machine is left
+	// untouched and reported as an error.
+	CloneConflictPolicyFail CloneConflictPolicy = "fail"
+
+	// CloneConflictPolicySuffix indicates the virtual machine is cloned with
+	// its name followed by a random suffix, leaving the existing virtual
+	// machine untouched. The name of the object, and so the hostname of the
+	// guest, is not changed.
+	CloneConflictPolicySuffix CloneConflictPolicy = "suffix"
+)
+
+// CloneConflictSuffixLength is the length of the random suffix, including
+// the separating dash, appended to the name of a virtual machine by the
+// suffix CloneConflictPolicy.
+const CloneConflictSuffixLength = 6
+
+// MaxVirtualMachineNameLength is the maximum length of the name of a virtual
+// machine in vCenter.
+const MaxVirtualMachineNameLength = 80
+
+// DRSAutomationLevel is the DRS automation level of a virtual machine.
+// +kubebuilder:validation:Enum=manual;disabled
+type DRSAutomationLevel string
+
+const (
+	// DRSAutomationLevelManual indicates DRS only recommends migrations of the
+	// virtual machine instead of migrating it automatically.
+	DRSAutomationLevelManual DRSAutomationLevel = "manual"
+
+	// DRSAutomationLevelDisabled indicates DRS neither migrates the virtual
+	// machine nor recommends migrations for it.
+	DRSAutomationLevelDisabled DRSAutomationLevel = "disabled"
+)
+
+// CPUMMUVirtualizationMode is the mode of the virtualization of the CPU
+// instructions and the memory management unit (MMU) of a virtual machine.
+// +kubebuilder:validation:Enum=automatic;software;hardware
+type CPUMMUVirtualizationMode string
+
+const (
+	// CPUMMUVirtualizationModeAutomatic indicates the host chooses whether
+	// the CPU and MMU are virtualized by hardware or software.
+	CPUMMUVirtualizationModeAutomatic CPUMMUVirtualizationMode = "automatic"
+
+	// CPUMMUVirtualizationModeSoftware indicates the CPU and MMU are
+	// virtualized by software only, without hardware assistance.
+	CPUMMUVirtualizationModeSoftware CPUMMUVirtualizationMode = "software"
+
+	// CPUMMUVirtualizationModeHardware indicates the CPU and MMU are
+	// virtualized by hardware assistance, i.e. Intel VT-x and EPT or AMD-V
+	// and RVI.
+	CPUMMUVirtualizationModeHardware CPUMMUVirtualizationMode = "hardware"
+)
+
+// ToolsUpgradePolicy is the upgrade policy of the VMware Tools of a virtual machine.
+// +kubebuilder:validation:Enum=manual;upgradeAtPowerCycle
+type ToolsUpgradePolicy string
+
+const (
+	// ToolsUpgradePolicyManual indicates the VMware Tools are only upgraded
+	// when requested explicitly.
+	ToolsUpgradePolicyManual ToolsUpgradePolicy = "manual"
+
+	// ToolsUpgradePolicyUpgradeAtPowerCycle indicates the VMware Tools are
+	// upgraded when the virtual machine is power cycled.
+	ToolsUpgradePolicyUpgradeAtPowerCycle ToolsUpgradePolicy = "upgradeAtPowerCycle"
+)
+
+// ResourceDriftPolicy defines how drift of the resource allocation of a virtual
+// machine from its spec is handled.
+// +kubebuilder:validation:Enum=correct;report
+type ResourceDriftPolicy string
+
+const (
+	// ResourceDriftPolicyCorrect indicates drift is reported and corrected by
+	// reconfiguring the virtual machine.
+	ResourceDriftPolicyCorrect ResourceDriftPolicy = "correct"
+
+	// ResourceDriftPolicyReport indicates drift is only reported, the virtual
+	// machine is not reconfigured.
+	ResourceDriftPolicyReport ResourceDriftPolicy = "report"
+)
+
+// InjectedCommandsOrder defines whether the commands CAPV injects into the
+// bootstrap data run before or after the commands of the bootstrap provider.
+// +kubebuilder:validation:Enum=beforeBootstrap;afterBootstrap
+type InjectedCommandsOrder string
+
+const (
+	// InjectedCommandsOrderBeforeBootstrap indicates the injected commands
+	// run before the commands of the bootstrap provider.
+	InjectedCommandsOrderBeforeBootstrap InjectedCommandsOrder = "beforeBootstrap"
+
+	// InjectedCommandsOrderAfterBootstrap indicates the injected commands
+	// run after the commands of the bootstrap provider.
+	InjectedCommandsOrderAfterBootstrap InjectedCommandsOrder = "afterBootstrap"
+)
+
+// VirtualMachineFirmware is the firmware of a virtual machine.
+// +kubebuilder:validation:Enum=bios;efi
+type VirtualMachineFirmware string
+
+const (
+	// VirtualMachineFirmwareBIOS indicates the virtual machine boots with BIOS.
+	VirtualMachineFirmwareBIOS VirtualMachineFirmware = "bios"
+
+	// VirtualMachineFirmwareEFI indicates the virtual machine boots with UEFI.
+	VirtualMachineFirmwareEFI VirtualMachineFirmware = "efi"
+)
+
+// GuestIPWaitPolicy defines whether the provisioning of a virtual machine waits
+// for the guest to report its IP addresses.
+// +kubebuilder:validation:Enum=wait;skip
+type GuestIPWaitPolicy string
+
+const (
+	// GuestIPWaitPolicyWait indicates the virtual machine is only ready once
+	// the guest reported its IP addresses.
+	GuestIPWaitPolicyWait GuestIPWaitPolicy = "wait"
+
+	// GuestIPWaitPolicySkip indicates the virtual machine is ready once it is
+	// powered on. The IP addresses are reported as soon as they are available.
+	GuestIPWaitPolicySkip GuestIPWaitPolicy = "skip"
+)
+
+// VirtualMachineQuestionPolicy defines how questions of a virtual machine which
+// block its operation are answered.
+// +kubebuilder:validation:Enum=autoAnswer;manual
+type VirtualMachineQuestionPolicy string
+
+const (
+	// VirtualMachineQuestionPolicyAutoAnswer indicates known questions are answered
+	// with a safe answer, e.g. "I Copied It" when asked whether a cloned virtual
+	// machine was moved or copied. Other questions are left for a user to answer.
+	VirtualMachineQuestionPolicyAutoAnswer VirtualMachineQuestionPolicy = "autoAnswer"
+
+	// VirtualMachineQuestionPolicyManual indicates all questions are left for a
+	// user to answer in vCenter.
+	VirtualMachineQuestionPolicyManual VirtualMachineQuestionPolicy = "manual"
+)
+
+// ProvisioningStalledPolicy defines what happens to a virtual machine which
+// exceeds its maximum provisioning time.
+// +kubebuilder:validation:Enum=report;fail
+type ProvisioningStalledPolicy string
+
+const (
+	// ProvisioningStalledPolicyReport indicates the virtual machine is only
+	// reported by the ProvisioningStalled condition and an event.
+	ProvisioningStalledPolicyReport ProvisioningStalledPolicy = "report"
+
+	// ProvisioningStalledPolicyFail indicates the virtual machine is also
+	// marked as failed, so a MachineHealthCheck remediates its Machine.
+	ProvisioningStalledPolicyFail ProvisioningStalledPolicy = "fail"
+)
+
+// SwapPlacement defines where the swap file of a virtual machine is stored.
+// +kubebuilder:validation:Enum=inherit;vmDirectory;hostLocal
+type SwapPlacement string
+
+const (
+	// SwapPlacementInherit indicates the swap file is stored where the
+	// compute cluster or the host of the virtual machine defines.
+	SwapPlacementInherit SwapPlacement = "inherit"
+
+	// SwapPlacementVMDirectory indicates the swap file is stored in the
+	// directory of the virtual machine on its datastore.
+	SwapPlacementVMDirectory SwapPlacement = "vmDirectory"
+
+	// SwapPlacementHostLocal indicates the swap file is stored on the local
+	// swap datastore of the host of the virtual machine.
+	SwapPlacementHostLocal SwapPlacement = "hostLocal"
+)
+
+// SecureBootFailurePolicy defines what happens to a virtual machine whose boot
+// likely failed because of Secure Boot.
+// +kubebuilder:validation:Enum=report;disable
+type SecureBootFailurePolicy string
+
+const (
+	// SecureBootFailurePolicyReport indicates the virtual machine is only
+	// reported by the SecureBootLikelyFailed condition and an event.
+	SecureBootFailurePolicyReport SecureBootFailurePolicy = "report"
+
+	// SecureBootFailurePolicyDisable indicates the virtual machine is also
+	// powered off and booted again with Secure Boot disabled.
+	SecureBootFailurePolicyDisable SecureBootFailurePolicy = "disable"
+)
+
 // VirtualMachineCloneSpec is information used to clone a virtual machine.
 type VirtualMachineCloneSpec struct {
 	// Template is the name or inventory path of the template used to clone
@@ -98,6 +347,13 @@ type VirtualMachineCloneSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	Template string `json:"template"`
 
+	// FallbackTemplates are the names or inventory paths of templates which are
+	// tried in order if Template is not found, e.g. because it was not replicated
+	// to the vCenter of the virtual machine yet. The templates should provide the
+	// same Kubernetes version as Template.
+	// +optional
+	FallbackTemplates []string `json:"fallbackTemplates,omitempty"`
+
 	// CloneMode specifies the type of clone operation.
 	// The LinkedClone mode is only support for templates that have at least
 	// one snapshot. If the template has no snapshots, then CloneMode defaults
@@ -106,6 +362,8 @@ type VirtualMachineCloneSpec struct {
 	// not possible to expand disks of linked clones.
 	// Defaults to LinkedClone, but fails gracefully to FullClone if the source
 	// of the clone operation has no snapshots.
+	// The InstantClone mode requires the template to be a powered on and
+	// frozen VM running on a host of the resource pool.
 	// +optional
 	CloneMode CloneMode `json:"cloneMode,omitempty"`
 
@@ -115,6 +373,17 @@ type VirtualMachineCloneSpec struct {
 	// +optional
 	Snapshot string `json:"snapshot,omitempty"`
 
+	// ProvisioningPriority is the priority of cloning the virtual machine
+	// relative to the other virtual machines of the same vSphere server which
+	// wait to be cloned. The virtual machine is not cloned while virtual
+	// machines of a higher priority wait to be cloned, e.g. so control plane
+	// and critical pools are provisioned first when MachineDeployments scale
+	// up simultaneously. Virtual machines of the same priority are cloned in
+	// the order they are processed.
+	// Defaults to 0.
+	// +optional
+	ProvisioningPriority int32 `json:"provisioningPriority,omitempty"`
+
 	// Server is the IP address or FQDN of the vSphere server on which
 	// the virtual machine is created/located.
 	// +optional
@@ -135,6 +404,9 @@ type VirtualMachineCloneSpec struct {
 
 	// Folder is the name or inventory path of the folder in which the
 	// virtual machine is created/located.
+	// The folder can only be changed if the VMFolderMove feature gate is
+	// enabled, which moves the virtual machine into the new folder and
+	// creates the folder if it does not exist.
 	// +optional
 	Folder string `json:"folder,omitempty"`
 
@@ -153,6 +425,22 @@ type VirtualMachineCloneSpec struct {
 	// +optional
 	ResourcePool string `json:"resourcePool,omitempty"`
 
+	// ComputeCluster is the name or inventory path of the compute cluster on
+	// whose hosts the virtual machine is placed, e.g. to remove ambiguity in
+	// inventories whose resource pool paths do not identify the cluster.
+	// The compute cluster must own the resource pool of the virtual machine,
+	// otherwise the virtual machine is not created.
+	// +optional
+	ComputeCluster string `json:"computeCluster,omitempty"`
+
+	// Host is the name or inventory path of the ESXi host on which the
+	// virtual machine is created and kept, e.g. for compute resources without
+	// DRS. The host must be connected, not in maintenance mode and a host of
+	// the compute resource of the resource pool. The virtual machine is
+	// migrated back to the host if it was moved to another host.
+	// +optional
+	Host string `json:"host,omitempty"`
+
 	// Network is the network configuration for this machine's VM.
 	Network NetworkSpec `json:"network"`
 
@@ -172,6 +460,18 @@ type VirtualMachineCloneSpec struct {
 	// virtual machine is cloned.
 	// +optional
 	MemoryMiB int64 `json:"memoryMiB,omitempty"`
+	// ReconfigurePolicy defines how changes of NumCPUs, NumCoresPerSocket and
+	// MemoryMiB are applied to existing virtual machines.
+	// If deferredUntilPowerOff, changes are recorded in the ReconfigurePending
+	// condition and applied when the virtual machine is next observed powered
+	// off, e.g. after a planned shutdown, instead of forcing a power cycle.
+	// Rebooting the guest does not power off the virtual machine.
+	// If hotAdd, increases of NumCPUs and MemoryMiB are hot-added to the powered
+	// on virtual machine if CPU or memory hot-add is enabled on it, and other
+	// changes are applied like with deferredUntilPowerOff.
+	// If not set, NumCPUs, NumCoresPerSocket and MemoryMiB cannot be changed.
+	// +optional
+	ReconfigurePolicy VirtualMachineReconfigurePolicy `json:"reconfigurePolicy,omitempty"`
 	// DiskGiB is the size of a virtual machine's disk, in GiB.
 	// Defaults to the eponymous property value in the template from which the
 	// virtual machine is cloned.
@@ -180,8 +480,54 @@ type VirtualMachineCloneSpec struct {
 	// AdditionalDisksGiB holds the sizes of additional disks of the virtual machine, in GiB
 	// Defaults to the eponymous property value in the template from which the
 	// virtual machine is cloned.
+	// Additional disks which are not in the template are created as thin
+	// provisioned disks and attached to the SCSI controllers of the virtual
+	// machine, see SCSIControllerCount. Their size must be set.
 	// +optional
 	AdditionalDisksGiB []int32 `json:"additionalDisksGiB,omitempty"`
+	// AdditionalDisksController defines the SCSI controller of the additional
+	// disks of the virtual machine, i.e. the disks of the template except its
+	// first disk.
+	// +optional
+	AdditionalDisksController *AdditionalDisksControllerSpec `json:"additionalDisksController,omitempty"`
+
+	// SCSIControllerCount is the number of SCSI controllers of the virtual
+	// machine, e.g. to spread many disks across controllers. The additional
+	// disks which are not in the template are distributed across the SCSI
+	// controllers in the order of their bus numbers. Missing SCSI controllers
+	// are added with the type of the first SCSI controller of the template.
+	// Defaults to the number of SCSI controllers required for the additional
+	// disks, and at least the number of SCSI controllers of the template.
+	// vSphere supports up to 4 SCSI controllers with 15 disks each.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4
+	SCSIControllerCount int32 `json:"scsiControllerCount,omitempty"`
+
+	// DiskEnableUUID exposes the UUIDs of the disks of the virtual machine to
+	// the guest as their serial numbers by setting disk.EnableUUID, so that
+	// e.g. the vSphere CSI driver identifies the disks consistently. It
+	// applies to all disks of the virtual machine, as vSphere does not support
+	// setting it per disk, and takes effect when the virtual machine is next
+	// powered on.
+	// Defaults to the setting of the template from which the virtual machine
+	// is cloned.
+	// +optional
+	DiskEnableUUID *bool `json:"diskEnableUUID,omitempty"`
+
+	// DiskStorageIOAllocation defines the Storage I/O Control shares and IOPS
+	// limit of each disk of the virtual machine. Storage I/O Control must be
+	// enabled on the datastores of the disks.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	DiskStorageIOAllocation *DiskStorageIOAllocation `json:"diskStorageIOAllocation,omitempty"`
+	// SharedDisks are virtual disks which are attached to multiple virtual
+	// machines, e.g. for clustered filesystems like GFS2 or OCFS2.
+	// The disks are attached while the virtual machine is powered off, and are
+	// detached, not deleted, when the virtual machine is deleted.
+	// +optional
+	SharedDisks []SharedDiskSpec `json:"sharedDisks,omitempty"`
 	// CustomVMXKeys is a dictionary of advanced VMX options that can be set on VM
 	// Defaults to empty map
 	// +optional
@@ -190,19 +536,977 @@ type VirtualMachineCloneSpec struct {
 	// must use URN-notation instead of display names.
 	// +optional
 	TagIDs []string `json:"tagIDs,omitempty"`
+	// CustomAttributes is a map of vCenter custom attributes set on the virtual
+	// machine, keyed by the name of the attribute. Missing attribute definitions
+	// are created for virtual machines.
+	// Drift of the values is reconciled; attributes which are not defined here
+	// are left untouched.
+	// +optional
+	CustomAttributes map[string]string `json:"customAttributes,omitempty"`
 	// PciDevices is the list of pci devices used by the virtual machine.
 	// +optional
 	PciDevices []PCIDeviceSpec `json:"pciDevices,omitempty"`
+	// MemoryReservationLockedToMax locks the memory reservation of the virtual
+	// machine to its memory size.
+	// Defaults to true if PCI devices or SR-IOV network adapters are defined,
+	// which require the memory to be reserved; it must not be false then.
+	// +optional
+	MemoryReservationLockedToMax *bool `json:"memoryReservationLockedToMax,omitempty"`
+	// ResourceAllocation defines the reservation, limit and shares of the CPU
+	// and memory of the virtual machine.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	ResourceAllocation *VirtualMachineResourceAllocation `json:"resourceAllocation,omitempty"`
+	// ResourceDriftPolicy defines whether drift of the resource allocation and
+	// the disk Storage I/O allocation of the virtual machine from the spec is
+	// corrected or only reported by the ResourcesConverged condition of the
+	// VSphereVM, e.g. to audit a rollout without reconfiguring running
+	// virtual machines.
+	// Defaults to correct.
+	// +optional
+	ResourceDriftPolicy ResourceDriftPolicy `json:"resourceDriftPolicy,omitempty"`
 	// OS is the Operating System of the virtual machine
 	// Defaults to Linux
 	// +optional
 	OS OS `json:"os,omitempty"`
+	// CloudInitDatasource is the cloud-init datasource the image reads the
+	// bootstrap data from, which must be included in its cloud-init.
+	// VMware requires cloud-init 21.3 or later, or the cloud-init-vmware-guestinfo
+	// datasource, like the images built by image-builder.
+	// NoCloud is supported by the cloud-init of any image, e.g. the generic cloud
+	// images of Linux distributions, and requires cloud-config bootstrap data.
+	// The NoCloud ISO image is attached before the virtual machine is powered on
+	// for the first time, so it is not supported with the instantClone clone mode.
+	// Defaults to VMware.
+	// +optional
+	CloudInitDatasource CloudInitDatasource `json:"cloudInitDatasource,omitempty"`
 	// HardwareVersion is the hardware version of the virtual machine.
 	// Defaults to the eponymous property value in the template from which the
 	// virtual machine is cloned.
 	// Check the compatibility with the ESXi version before setting the value.
 	// +optional
 	HardwareVersion string `json:"hardwareVersion,omitempty"`
+	// BootOptions configures the boot delay and boot retry behavior of the
+	// virtual machine.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	BootOptions *VirtualMachineBootOptions `json:"bootOptions,omitempty"`
+	// NestedHardwareVirtualization exposes hardware-assisted virtualization
+	// to the guest, e.g. to run nested hypervisors like KubeVirt. The CPUs of
+	// the hosts must support it.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to false.
+	// +optional
+	NestedHardwareVirtualization *bool `json:"nestedHardwareVirtualization,omitempty"`
+	// CPUMMUVirtualization is the mode of the virtualization of the CPU and
+	// MMU of the virtual machine, e.g. to virtualize them by hardware for
+	// nested or performance sensitive workloads. The hosts must support
+	// configuring the mode.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned, which is usually automatic.
+	// +optional
+	CPUMMUVirtualization CPUMMUVirtualizationMode `json:"cpuMMUVirtualization,omitempty"`
+	// EVCMode is the key of the per-VM Enhanced vMotion Compatibility (EVC)
+	// mode of the virtual machine, e.g. intel-broadwell. It must not exceed the
+	// EVC mode of the cluster or, if EVC is disabled on the cluster, the CPUs
+	// of its hosts. It requires hardware version vmx-14 or later and is applied
+	// after cloning, before the virtual machine is powered on.
+	// Defaults to the EVC mode of the cluster.
+	// +optional
+	EVCMode string `json:"evcMode,omitempty"`
+	// Firmware is the firmware of the virtual machine. The guest OS of the
+	// template must support it.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	Firmware VirtualMachineFirmware `json:"firmware,omitempty"`
+	// SecureBoot enables UEFI Secure Boot, which requires the efi firmware.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	SecureBoot *bool `json:"secureBoot,omitempty"`
+	// VirtualTPM adds a virtual Trusted Platform Module 2.0 to the virtual
+	// machine if true, or removes it if false. It requires hardware version
+	// vmx-14 or later and a key provider configured in vCenter.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	VirtualTPM *bool `json:"virtualTPM,omitempty"`
+	// TrustedLaunch enables the efi firmware, SecureBoot and VirtualTPM
+	// together if true. Firmware, SecureBoot and VirtualTPM must then either be
+	// unset or consistent with it. Whether the virtual machine fulfills the
+	// prerequisites and runs with all of them is reported by the
+	// TrustedLaunchReady condition.
+	// If false, Firmware, SecureBoot and VirtualTPM apply individually.
+	// +optional
+	TrustedLaunch *bool `json:"trustedLaunch,omitempty"`
+	// SecureBootFailureTimeout is the time the virtual machine may run with
+	// Secure Boot without a guest heartbeat from VMware Tools before its boot
+	// is reported to have likely failed by the SecureBootLikelyFailed
+	// condition, e.g. because the boot loader or kernel of the template is not
+	// signed.
+	// Defaults to 10m.
+	// +optional
+	SecureBootFailureTimeout *metav1.Duration `json:"secureBootFailureTimeout,omitempty"`
+	// SecureBootFailurePolicy defines what happens to the virtual machine once
+	// its boot likely failed because of Secure Boot.
+	// Defaults to report.
+	// +optional
+	SecureBootFailurePolicy SecureBootFailurePolicy `json:"secureBootFailurePolicy,omitempty"`
+	// CloneConflictPolicy defines how to handle an existing virtual machine
+	// with the same name which was not provisioned for this object.
+	// Defaults to adopt.
+	// +optional
+	CloneConflictPolicy CloneConflictPolicy `json:"cloneConflictPolicy,omitempty"`
+	// DRSAutomationLevel overrides the DRS automation level of the compute
+	// cluster for the virtual machine, e.g. to prevent DRS from automatically
+	// migrating control plane nodes.
+	// DRS must be enabled on the compute cluster the virtual machine is
+	// placed in.
+	// Defaults to the automation level of the compute cluster.
+	// +optional
+	DRSAutomationLevel DRSAutomationLevel `json:"drsAutomationLevel,omitempty"`
+	// OVA is the source of the template, if it does not exist yet.
+	// When set and the template can not be found, the OVA is imported into
+	// vCenter as a template with the name defined by Template, which is then
+	// used to clone this and subsequent virtual machines.
+	// +optional
+	OVA *OVASource `json:"ova,omitempty"`
+	// TimeZone is the IANA time zone of the guest, e.g. "Europe/Berlin".
+	// It is rendered into the bootstrap data of the virtual machine.
+	// Defaults to the time zone configured in the template.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// NTPServers is the list of NTP servers the guest synchronizes its
+	// time with. They are rendered into the bootstrap data of the virtual
+	// machine.
+	// Defaults to the NTP servers configured in the template.
+	// +optional
+	NTPServers []string `json:"ntpServers,omitempty"`
+	// KernelArgs is the list of parameters which are added to the kernel
+	// command line of the guest, e.g. "isolcpus=2-7" or "hugepages=1024".
+	// They are rendered into the bootstrap data of the virtual machine, as
+	// vSphere cannot set them. cloud-config guests must boot by GRUB and
+	// provide grubby or update-grub, and are rebooted once cloud-init
+	// finished. Ignition configs must be of version 3.3 or later.
+	// They are not supported for Windows guests.
+	// +optional
+	KernelArgs []string `json:"kernelArgs,omitempty"`
+	// InjectedCommandsOrder defines whether the commands CAPV adds to the
+	// runcmd section of cloud-config bootstrap data, e.g. to apply the
+	// proxy or time settings, run before or after the commands of the
+	// bootstrap provider. The injected commands keep their relative order.
+	// Restarting containerd with the proxy settings after the bootstrap
+	// commands means the images pulled by kubeadm bypass the proxy.
+	// Defaults to beforeBootstrap.
+	// +optional
+	InjectedCommandsOrder InjectedCommandsOrder `json:"injectedCommandsOrder,omitempty"`
+	// Files is the list of files which are written to the guest from keys of
+	// Secrets in the namespace of the machine, e.g. certificates or config
+	// files which are independent of the bootstrap provider. They are added
+	// to the bootstrap data when the virtual machine is created, after the
+	// files of the bootstrap provider and in the order of the list, so they
+	// take precedence for the same path. The total size of their contents
+	// must not exceed 64 KiB.
+	// +optional
+	// +listType=map
+	// +listMapKey=path
+	Files []SecretFile `json:"files,omitempty"`
+	// PerformanceOptions configures the performance counters of the virtual
+	// machine which are available to vCenter and the guest.
+	// Drift of the configured options is reconciled.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	PerformanceOptions *VirtualMachinePerformanceOptions `json:"performanceOptions,omitempty"`
+	// ToolsUpgradePolicy is the upgrade policy of the VMware Tools of the
+	// virtual machine.
+	// Drift of the policy is reconciled.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	ToolsUpgradePolicy ToolsUpgradePolicy `json:"toolsUpgradePolicy,omitempty"`
+	// ToolsSyncTimeWithHost enables or disables the periodic synchronization
+	// of the time of the guest with the host by the VMware Tools, e.g. to
+	// rely on NTP only.
+	// Drift of the setting is reconciled.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	ToolsSyncTimeWithHost *bool `json:"toolsSyncTimeWithHost,omitempty"`
+	// SerialPorts is the list of serial ports added to the virtual machine,
+	// e.g. to capture its console output.
+	// +optional
+	SerialPorts []SerialPortSpec `json:"serialPorts,omitempty"`
+	// CDROMs defines the media of the CD-ROM drives of the virtual machine,
+	// e.g. to mount an ISO image with drivers. The entries map to the CD-ROM
+	// drives of the virtual machine in order; missing drives are added while
+	// the virtual machine is powered off.
+	// Drift of the media is reconciled.
+	// +optional
+	CDROMs []CDROMSpec `json:"cdroms,omitempty"`
+	// GuestIPWaitPolicy defines whether the provisioning of the virtual machine
+	// waits for the guest to report its IP addresses, e.g. to let worker
+	// machines become ready sooner than control plane machines.
+	// Defaults to wait.
+	// +optional
+	GuestIPWaitPolicy GuestIPWaitPolicy `json:"guestIPWaitPolicy,omitempty"`
+	// PowerOnAfterClone defines whether the virtual machine is powered on once
+	// it is provisioned. If false, the virtual machine is left powered off and
+	// reported as provisioned without waiting for its IP addresses, e.g. to
+	// pre-warm capacity until an external orchestrator powers it on. CAPV does
+	// not power on the virtual machine while false; changing it to true powers
+	// the virtual machine on.
+	// Defaults to true.
+	// +optional
+	PowerOnAfterClone *bool `json:"powerOnAfterClone,omitempty"`
+	// QuestionPolicy defines how questions of the virtual machine which block
+	// its operation, e.g. powering it on, are answered. Questions which are not
+	// answered are reported in the VMQuestionAnswered condition of the VSphereVM.
+	// Defaults to autoAnswer.
+	// +optional
+	QuestionPolicy VirtualMachineQuestionPolicy `json:"questionPolicy,omitempty"`
+	// StorageAffinity keeps the virtual machine on the hosts which store its
+	// data on vSAN/HCI clusters. It requires StoragePolicyName to be set to a
+	// vSAN storage policy.
+	// +optional
+	StorageAffinity *StorageAffinitySpec `json:"storageAffinity,omitempty"`
+	// LoggingOptions defines the logging of the virtual machine to vmware.log
+	// files on its datastore.
+	// Drift of the configured options is reconciled.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned.
+	// +optional
+	LoggingOptions *VirtualMachineLoggingOptions `json:"loggingOptions,omitempty"`
+	// MemoryBacking defines the backing of the memory of the virtual machine by
+	// huge pages of its host, e.g. for DPDK workloads.
+	// Drift of the backing is reconciled. It takes effect when the virtual
+	// machine is powered on again.
+	// +optional
+	MemoryBacking *VirtualMachineMemoryBacking `json:"memoryBacking,omitempty"`
+	// NUMANodeAffinity constrains the virtual machine to the NUMA nodes of its
+	// host with the given indices, e.g. for the memory locality of large
+	// databases. Every host the virtual machine may run on must have the NUMA
+	// nodes.
+	// NOTE: The affinity breaks the vMotion compatibility of the virtual
+	// machine, as hosts do not guarantee the same NUMA topology, so DRS does
+	// not migrate it and it must be powered off to be moved to another host.
+	// Drift of the affinity is reconciled while the virtual machine is powered
+	// off.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Minimum=0
+	NUMANodeAffinity []int32 `json:"numaNodeAffinity,omitempty"`
+	// SwapPlacement defines where the swap file of the virtual machine is
+	// stored, e.g. on the local SSD of its host for performance. Every host the
+	// virtual machine may run on must have a local swap datastore for
+	// hostLocal.
+	// Changes are only applied while the virtual machine is powered off.
+	// Defaults to the eponymous property value in the template from which the
+	// virtual machine is cloned, which is usually inherit.
+	// +optional
+	SwapPlacement SwapPlacement `json:"swapPlacement,omitempty"`
+	// Isolation defines the isolation of the virtual machine from its remote
+	// console and its host, e.g. to disable copy and paste.
+	// Drift of the configured settings is reconciled. It takes effect when the
+	// virtual machine is powered on again.
+	// Settings which are not defined default to the eponymous property value in
+	// the template from which the virtual machine is cloned, or to hardened
+	// values if the controller manager is started with --harden-vm-isolation.
+	// +optional
+	Isolation *VirtualMachineIsolation `json:"isolation,omitempty"`
+	// OVFEnvironment defines the OVF environment delivered to the guest on
+	// first boot, e.g. for appliance templates which are configured by OVF
+	// properties.
+	// The vApp options of the template are removed if unset.
+	// +optional
+	OVFEnvironment *OVFEnvironmentSpec `json:"ovfEnvironment,omitempty"`
+	// ReadinessProbe defines a probe of the readiness of the guest, which gates
+	// the readiness of the VM in addition to its network.
+	// +optional
+	ReadinessProbe *GuestReadinessProbe `json:"readinessProbe,omitempty"`
+	// MaxProvisioningTime is the maximum time from the creation of the virtual
+	// machine, or the start of its redeploy, until it becomes ready, e.g. to
+	// detect stuck boots. The provisioning time of control plane and worker
+	// machines is configured by their templates.
+	// +optional
+	MaxProvisioningTime *metav1.Duration `json:"maxProvisioningTime,omitempty"`
+	// ProvisioningStalledPolicy defines what happens to the virtual machine
+	// once it exceeds the MaxProvisioningTime.
+	// Defaults to report.
+	// +optional
+	ProvisioningStalledPolicy ProvisioningStalledPolicy `json:"provisioningStalledPolicy,omitempty"`
+	// Hostname defines how the hostname of the guest, which determines the
+	// name of its Kubernetes node, is derived from the IP address of the
+	// virtual machine, e.g. to match the names in DNS.
+	// It is not supported with the NoCloud cloud-init datasource.
+	// Defaults to the name of the object.
+	// +optional
+	Hostname *HostnameSpec `json:"hostname,omitempty"`
+}
+
+// StorageAffinitySpec defines the DRS groups which keep a virtual machine
+// on the hosts which store its data.
+type StorageAffinitySpec struct {
+	// ComputeCluster is the name or inventory path of the compute cluster
+	// which defines the DRS groups.
+	// +kubebuilder:validation:MinLength=1
+	ComputeCluster string `json:"computeCluster"`
+	// VMGroupName is the name of the DRS VM group the virtual machine is
+	// added to.
+	// +kubebuilder:validation:MinLength=1
+	VMGroupName string `json:"vmGroupName"`
+	// HostGroupName is the name of the DRS host group of the hosts which store
+	// the data of the virtual machine. The VM group must be affine to the host
+	// group by a VM-Host rule.
+	// +kubebuilder:validation:MinLength=1
+	HostGroupName string `json:"hostGroupName"`
+}
+
+// CDROMSpec defines the media of a CD-ROM drive of a virtual machine.
+type CDROMSpec struct {
+	// ISOPath is the datastore path of the ISO image inserted into the
+	// CD-ROM drive, e.g. "[datastore1] isos/drivers.iso".
+	// The CD-ROM drive is emptied if unset.
+	// +optional
+	ISOPath string `json:"isoPath,omitempty"`
+}
+
+// SerialPortSpec defines the backing of a serial port of a virtual machine.
+// Exactly one of URI and File must be set.
+type SerialPortSpec struct {
+	// URI is the URI of the network service the serial port connects to,
+	// e.g. "telnet://logs.example.com:13370". When ProxyURI is set, it is
+	// the service name passed to the virtual serial port concentrator.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// ProxyURI is the URI of the virtual serial port concentrator (vSPC)
+	// the serial port connects through, e.g. "telnets://vspc.example.com:13370".
+	// +optional
+	ProxyURI string `json:"proxyURI,omitempty"`
+
+	// File is the datastore path of the file the output of the serial port
+	// is written to, e.g. "[datastore1] logs/console.log".
+	// +optional
+	File string `json:"file,omitempty"`
+}
+
+// SerialPortStatus describes a serial port of a virtual machine.
+type SerialPortStatus struct {
+	// Label is the label of the serial port, e.g. "Serial port 1".
+	Label string `json:"label"`
+
+	SerialPortSpec `json:",inline"`
+}
+
+// VirtualMachineDiskStatus describes a disk of a virtual machine and its place on
+// the controllers of the virtual machine.
+type VirtualMachineDiskStatus struct {
+	// Label is the label of the disk, e.g. "Hard disk 1".
+	Label string `json:"label"`
+
+	// Controller is the label of the controller of the disk, e.g.
+	// "SCSI controller 0".
+	// +optional
+	Controller string `json:"controller,omitempty"`
+
+	// UnitNumber is the unit number of the disk on its controller.
+	// +optional
+	UnitNumber *int32 `json:"unitNumber,omitempty"`
+
+	// CapacityGiB is the capacity of the disk, in GiB.
+	// +optional
+	CapacityGiB int64 `json:"capacityGiB,omitempty"`
+
+	// UUID is the UUID of the disk. The guest sees it as the serial number of
+	// the disk if DiskEnableUUID is set.
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+}
+
+// GuestDiskUsage describes the usage of the filesystems of the guest of a virtual
+// machine as reported by VMware Tools.
+type GuestDiskUsage struct {
+	// Filesystems is the list of the filesystems of the guest and their usage.
+	// +optional
+	Filesystems []GuestFilesystemUsage `json:"filesystems,omitempty"`
+
+	// LastRefreshTime is the time the usage was last read from VMware Tools.
+	// +optional
+	LastRefreshTime metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+// GuestFilesystemUsage describes the usage of a filesystem of the guest of a
+// virtual machine.
+type GuestFilesystemUsage struct {
+	// Path is the mount point of the filesystem in the guest, e.g. "/" or "C:\".
+	Path string `json:"path"`
+
+	// FilesystemType is the type of the filesystem, e.g. "ext4" or "NTFS".
+	// +optional
+	FilesystemType string `json:"filesystemType,omitempty"`
+
+	// CapacityBytes is the total size of the filesystem in bytes.
+	CapacityBytes int64 `json:"capacityBytes"`
+
+	// FreeBytes is the free space of the filesystem in bytes.
+	FreeBytes int64 `json:"freeBytes"`
+}
+
+// MigrationInitiator is the initiator of a migration of a virtual machine.
+// +kubebuilder:validation:Enum=DRS;Manual
+type MigrationInitiator string
+
+const (
+	// MigrationInitiatorDRS is a migration initiated by DRS, e.g. to balance the
+	// load of the hosts of a cluster or to evacuate a host entering maintenance mode.
+	MigrationInitiatorDRS MigrationInitiator = "DRS"
+
+	// MigrationInitiatorManual is a migration initiated by a user or a client of
+	// vCenter other than DRS.
+	MigrationInitiatorManual MigrationInitiator = "Manual"
+)
+
+// MigrationHistory describes the migrations of a virtual machine between ESXi
+// hosts as observed in the events of vCenter.
+type MigrationHistory struct {
+	// Migrations are the most recent migrations of the virtual machine, oldest
+	// first.
+	// +optional
+	Migrations []VirtualMachineMigration `json:"migrations,omitempty"`
+
+	// LastRefreshTime is the time the events were last read from vCenter.
+	// +optional
+	LastRefreshTime metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+// VirtualMachineMigration describes a migration of a virtual machine from one
+// ESXi host to another.
+type VirtualMachineMigration struct {
+	// Time is the time vCenter recorded the migration.
+	Time metav1.Time `json:"time"`
+
+	// SourceHost is the name of the host the virtual machine was migrated from.
+	// +optional
+	SourceHost string `json:"sourceHost,omitempty"`
+
+	// TargetHost is the name of the host the virtual machine was migrated to.
+	// +optional
+	TargetHost string `json:"targetHost,omitempty"`
+
+	// Initiator is the initiator of the migration.
+	Initiator MigrationInitiator `json:"initiator"`
+
+	// User is the vCenter user who initiated the migration.
+	// +optional
+	User string `json:"user,omitempty"`
+}
+
+// GuestNetworkStatus describes the network configuration of the guest of a
+// virtual machine as observed by VMware Tools.
+type GuestNetworkStatus struct {
+	// Devices are the network devices of the guest.
+	// +optional
+	Devices []GuestNetworkDeviceStatus `json:"devices,omitempty"`
+
+	// Gateways are the default gateways of the guest.
+	// +optional
+	Gateways []string `json:"gateways,omitempty"`
+
+	// Nameservers are the DNS servers of the guest.
+	// +optional
+	Nameservers []string `json:"nameservers,omitempty"`
+
+	// SearchDomains are the DNS search domains of the guest.
+	// +optional
+	SearchDomains []string `json:"searchDomains,omitempty"`
+
+	// Hostname is the hostname of the guest.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// GuestNetworkDeviceStatus describes a network device of the guest of a
+// virtual machine as observed by VMware Tools.
+type GuestNetworkDeviceStatus struct {
+	// MACAddr is the MAC address of the network device.
+	MACAddr string `json:"macAddr"`
+
+	// Connected indicates whether the network device is connected.
+	// +optional
+	Connected bool `json:"connected,omitempty"`
+
+	// NetworkName is the name of the network the device is attached to.
+	// +optional
+	NetworkName string `json:"networkName,omitempty"`
+
+	// IPAddrs are the IP addresses of the network device in CIDR notation,
+	// like the IPAddrs of a NetworkDeviceSpec, e.g. "192.168.4.21/24".
+	// +optional
+	IPAddrs []string `json:"ipAddrs,omitempty"`
+}
+
+// OVASource describes an OVA the template of a virtual machine is imported from.
+type OVASource struct {
+	// URL is the HTTP or HTTPS URL the OVA is downloaded from.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Checksum is the SHA-256 checksum of the OVA, encoded as hex string.
+	// When set, the import fails if the checksum of the downloaded OVA
+	// does not match.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// VirtualMachineBootOptions defines the boot-time behavior of a virtual machine.
+type VirtualMachineBootOptions struct {
+	// BootDelay is the delay in milliseconds before starting the boot sequence.
+	// +optional
+	BootDelay *int64 `json:"bootDelay,omitempty"`
+
+	// BootRetryEnabled indicates whether the virtual machine should retry
+	// booting if no boot device is found.
+	// +optional
+	BootRetryEnabled *bool `json:"bootRetryEnabled,omitempty"`
+
+	// BootRetryDelay is the delay in milliseconds before a boot retry is
+	// attempted. This field is only used when BootRetryEnabled is true.
+	// +optional
+	BootRetryDelay *int64 `json:"bootRetryDelay,omitempty"`
+
+	// NetworkBoot boots the virtual machine from the network, e.g. for
+	// network-boot golden images chainloading iPXE.
+	// +optional
+	NetworkBoot *VirtualMachineNetworkBoot `json:"networkBoot,omitempty"`
+}
+
+// VirtualMachineNetworkBoot defines the network boot of a virtual machine.
+// The network device is placed first in the boot order of the virtual machine,
+// followed by its first disk, while the virtual machine is powered off. The
+// virtual machine is not powered on unless the network device is connected,
+// and, if defined, attached to the provisioning network.
+type VirtualMachineNetworkBoot struct {
+	// DeviceIndex is the index of the network device, in the network devices
+	// of the virtual machine, which boots from the network.
+	// Defaults to the first network device.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeviceIndex int32 `json:"deviceIndex,omitempty"`
+
+	// ProvisioningNetworkName is the name of the provisioning network, e.g.
+	// the port group of the provisioning VLAN, which the network device must
+	// be attached to.
+	// +optional
+	ProvisioningNetworkName string `json:"provisioningNetworkName,omitempty"`
+
+	// IPXEScript is an iPXE script which is run instead of booting from the
+	// boot file of the DHCP server, e.g.
+	// "chain http://boot.example.com/${net0/mac}.ipxe". It is delivered as
+	// the guestinfo.ipxe.scriptlet setting, and requires an iPXE build with
+	// support for VMware GuestInfo settings.
+	// +optional
+	IPXEScript string `json:"ipxeScript,omitempty"`
+
+	// IPXESettings are iPXE settings delivered as guestinfo.ipxe.<name>
+	// settings, e.g. "filename" and "next-server" to override the DHCP
+	// options. They require an iPXE build with support for VMware GuestInfo
+	// settings.
+	// +optional
+	IPXESettings map[string]string `json:"ipxeSettings,omitempty"`
+}
+
+// AdditionalDisksControllerSpec defines the SCSI controller of the additional disks of
+// a virtual machine.
+type AdditionalDisksControllerSpec struct {
+	// BusSharing is the bus sharing mode of the SCSI controller of the
+	// additional disks, which lets clustered applications share the disks
+	// between virtual machines. The mode is applied to the SCSI controllers of
+	// the additional disks when the virtual machine is cloned.
+	// Sharing the bus requires a full clone, additional disks which are
+	// provisioned eagerZeroedThick and which are not attached to the SCSI
+	// controller of the first disk of the template.
+	// Defaults to the bus sharing mode of the SCSI controllers in the template.
+	// +optional
+	// +kubebuilder:validation:Enum=none;physical;virtual
+	BusSharing SCSIBusSharing `json:"busSharing,omitempty"`
+}
+
+// SCSIBusSharing is the bus sharing mode of a SCSI controller.
+type SCSIBusSharing string
+
+const (
+	// SCSIBusSharingNone does not share the SCSI bus.
+	SCSIBusSharingNone SCSIBusSharing = "none"
+
+	// SCSIBusSharingVirtual shares the SCSI bus with virtual machines on the
+	// same host.
+	SCSIBusSharingVirtual SCSIBusSharing = "virtual"
+
+	// SCSIBusSharingPhysical shares the SCSI bus with virtual machines on any
+	// host.
+	SCSIBusSharingPhysical SCSIBusSharing = "physical"
+)
+
+// VirtualMachineResourceAllocation defines the CPU and memory resource allocation
+// of a virtual machine.
+type VirtualMachineResourceAllocation struct {
+	// CPU is the resource allocation of the CPU of the virtual machine, the
+	// reservation and limit are in MHz.
+	// +optional
+	CPU *ResourceAllocationSpec `json:"cpu,omitempty"`
+
+	// Memory is the resource allocation of the memory of the virtual machine,
+	// the reservation and limit are in MiB.
+	// +optional
+	Memory *ResourceAllocationSpec `json:"memory,omitempty"`
+
+	// CPUReservationPercent is the CPU reservation of the virtual machine as
+	// percentage of its CPUs. It is converted to MHz by the CPU frequency of the
+	// host of the virtual machine when the virtual machine is cloned, or by the
+	// lowest CPU frequency of the hosts of its resource pool if the host is
+	// selected by DRS. It is mutually exclusive with the reservation of the CPU.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CPUReservationPercent *int32 `json:"cpuReservationPercent,omitempty"`
+}
+
+// ResourceAllocationSpec defines the reservation, limit and shares of a resource.
+type ResourceAllocationSpec struct {
+	// Reservation is the amount of the resource guaranteed to be available.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Reservation *int64 `json:"reservation,omitempty"`
+
+	// Limit is the upper bound of the resource utilization, -1 means unlimited.
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	Limit *int64 `json:"limit,omitempty"`
+
+	// Shares define the relative priority of the virtual machine when competing
+	// with other virtual machines for the resource.
+	// +optional
+	Shares *ResourceShares `json:"shares,omitempty"`
+}
+
+// VirtualDiskSharing is the sharing mode of a virtual disk.
+// +kubebuilder:validation:Enum=multi-writer
+type VirtualDiskSharing string
+
+const (
+	// VirtualDiskSharingMultiWriter allows multiple virtual machines to write
+	// to the virtual disk concurrently. It requires the disk to be
+	// eagerZeroedThick provisioned.
+	VirtualDiskSharingMultiWriter VirtualDiskSharing = "multi-writer"
+)
+
+// SharedDiskSpec defines a virtual disk which is attached to multiple virtual
+// machines. The disk is attached in independent persistent mode, so it is
+// excluded from snapshots of the virtual machines.
+type SharedDiskSpec struct {
+	// DiskPath is the datastore path of the VMDK of the disk, e.g.
+	// "[datastore1] shared/gfs2-data.vmdk". It identifies the disk across the
+	// virtual machines it is attached to.
+	// +kubebuilder:validation:MinLength=1
+	DiskPath string `json:"diskPath"`
+
+	// Sharing is the sharing mode of the disk.
+	Sharing VirtualDiskSharing `json:"sharing"`
+
+	// SizeGiB is the size of the disk, in GiB. If the disk does not exist, it
+	// is created eagerZeroedThick by the first virtual machine it is attached
+	// to. If not set, the disk must be created in advance.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SizeGiB int32 `json:"sizeGiB,omitempty"`
+}
+
+// DiskStorageIOAllocation defines the Storage I/O Control allocation of a virtual disk.
+type DiskStorageIOAllocation struct {
+	// Shares define the relative priority of the disk when competing with the
+	// disks of other virtual machines for the I/O of the datastore.
+	// +optional
+	Shares *ResourceShares `json:"shares,omitempty"`
+
+	// Limit is the upper bound of the IOPS of the disk, -1 means unlimited.
+	// +kubebuilder:validation:Minimum=-1
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	Limit *int64 `json:"limit,omitempty"`
+}
+
+// SharesLevel is the predefined level of the shares of a resource.
+// +kubebuilder:validation:Enum=low;normal;high;custom
+type SharesLevel string
+
+const (
+	// SharesLevelLow allocates 500 shares per virtual CPU, 5 shares per MiB of memory
+	// or 500 shares per disk.
+	SharesLevelLow SharesLevel = "low"
+
+	// SharesLevelNormal allocates 1000 shares per virtual CPU, 10 shares per MiB of memory
+	// or 1000 shares per disk.
+	SharesLevelNormal SharesLevel = "normal"
+
+	// SharesLevelHigh allocates 2000 shares per virtual CPU, 20 shares per MiB of memory
+	// or 2000 shares per disk.
+	SharesLevelHigh SharesLevel = "high"
+
+	// SharesLevelCustom allocates the number of shares defined by the user.
+	SharesLevelCustom SharesLevel = "custom"
+)
+
+// ResourceShares defines the shares of a resource.
+type ResourceShares struct {
+	// Level is the level of the shares.
+	Level SharesLevel `json:"level"`
+
+	// Shares is the number of shares. It must only be defined if the level is
+	// custom, and is set to the number of shares of the level otherwise.
+	// +optional
+	Shares int32 `json:"shares,omitempty"`
+}
+
+// SecretFile defines a file in the guest whose content is the value of a key
+// of a Secret.
+type SecretFile struct {
+	// Path is the absolute path of the file in the guest.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Permissions are the permissions of the file in octal notation, e.g. "0600".
+	// Defaults to "0644".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^0?[0-7]{3}$`
+	Permissions string `json:"permissions,omitempty"`
+
+	// Owner is the owner of the file, e.g. "root:root".
+	// Defaults to "root:root".
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Secret is the key of the Secret whose value is the content of the file.
+	Secret SecretFileSource `json:"secret"`
+}
+
+// SecretFileSource references a key of a Secret in the namespace of the machine.
+type SecretFileSource struct {
+	// Name is the name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key of the Secret whose value is the content of the file.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// ProxyConfiguration defines the HTTP proxy of virtual machines.
+type ProxyConfiguration struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a list of hostnames, domains, IP addresses and CIDRs which
+	// are accessed without the proxy.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// RegistryMirror defines the mirrors and the CA certificate of a container image registry.
+type RegistryMirror struct {
+	// Registry is the host, with an optional port, of the registry whose
+	// images are pulled from the mirrors, e.g. "docker.io".
+	// +kubebuilder:validation:MinLength=1
+	Registry string `json:"registry"`
+
+	// Endpoints are the HTTP or HTTPS URLs of the mirrors of the registry,
+	// which are tried in order before the registry itself.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// CASecretName is the name of the secret in the namespace of the cluster
+	// with the PEM encoded CA certificate of the registry and its mirrors,
+	// stored in the "ca.crt" key.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+}
+
+// VirtualMachinePerformanceOptions defines the performance counters of a virtual machine.
+type VirtualMachinePerformanceOptions struct {
+	// VirtualCPUPerformanceCountersEnabled indicates whether the virtual CPU
+	// performance counters (vPMC) are exposed to the guest.
+	// The setting is only changed while the virtual machine is powered off.
+	// +optional
+	VirtualCPUPerformanceCountersEnabled *bool `json:"virtualCPUPerformanceCountersEnabled,omitempty"`
+
+	// ExtraConfig is a dictionary of advanced VMX performance options, e.g.
+	// "monitor_control.pseudo_perfctr".
+	// +optional
+	ExtraConfig map[string]string `json:"extraConfig,omitempty"`
+}
+
+// HostnameSource is the source of the hostname of the guest.
+// +kubebuilder:validation:Enum=template;reverseDNS
+type HostnameSource string
+
+const (
+	// HostnameSourceTemplate renders the hostname from a template referencing
+	// the IP address of the virtual machine.
+	HostnameSourceTemplate HostnameSource = "template"
+
+	// HostnameSourceReverseDNS looks up the hostname by the reverse DNS lookup
+	// of the IP address of the virtual machine by the controller manager.
+	HostnameSourceReverseDNS HostnameSource = "reverseDNS"
+)
+
+// HostnameSpec defines how the hostname of the guest is derived from the IP
+// address of the virtual machine. The first IPv4 address of its network
+// devices is used, which must either be static or allocated from an IP pool.
+// The resulting hostname must be a valid DNS subdomain name.
+type HostnameSpec struct {
+	// Source is the source of the hostname.
+	Source HostnameSource `json:"source"`
+
+	// Template is a Go template of the hostname, which is required if the
+	// Source is template. It is rendered with the fields Name, the name of the
+	// object, IP, the IP address, and Octets, the four octets of the IP
+	// address, e.g. "node-{{ index .Octets 2 }}-{{ index .Octets 3 }}.example.com".
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// GuestReadinessProbe defines a probe of the readiness of the guest, which is run
+// by VMware Tools guest operations.
+// Exactly one of FilePath and Command must be set.
+type GuestReadinessProbe struct {
+	// CredentialsSecretName is the name of the secret in the namespace of the
+	// VSphereVM with the username and password of the guest user running the
+	// probe, stored in the "username" and "password" keys.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+
+	// FilePath is the absolute path of a file in the guest, whose existence
+	// signals the readiness of the guest.
+	// +optional
+	FilePath string `json:"filePath,omitempty"`
+
+	// Command is a command run in the guest, whose exit code zero signals the
+	// readiness of the guest.
+	// +optional
+	Command *GuestProbeCommand `json:"command,omitempty"`
+}
+
+// GuestProbeCommand defines a command run in the guest.
+type GuestProbeCommand struct {
+	// Path is the absolute path of the program in the guest.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Arguments are the arguments passed to the program.
+	// +optional
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// OVFEnvironmentTransport is the transport through which the OVF environment
+// is delivered to the guest.
+// +kubebuilder:validation:Enum=iso;com.vmware.guestInfo
+type OVFEnvironmentTransport string
+
+const (
+	// OVFEnvironmentTransportISO delivers the OVF environment on an ISO image
+	// attached to a CD-ROM drive of the virtual machine.
+	OVFEnvironmentTransportISO OVFEnvironmentTransport = "iso"
+
+	// OVFEnvironmentTransportGuestInfo delivers the OVF environment through
+	// the guestinfo.ovfEnv variable of VMware Tools.
+	OVFEnvironmentTransportGuestInfo OVFEnvironmentTransport = "com.vmware.guestInfo"
+)
+
+// OVFEnvironmentSpec defines the OVF environment of a virtual machine.
+type OVFEnvironmentSpec struct {
+	// Transport is the transport through which the OVF environment is
+	// delivered to the guest.
+	Transport OVFEnvironmentTransport `json:"transport"`
+
+	// Properties is a dictionary of the values of the OVF properties, keyed
+	// by their ID. The properties must be defined in the OVF descriptor of
+	// the template.
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// VirtualMachineLoggingOptions defines the logging of a virtual machine.
+type VirtualMachineLoggingOptions struct {
+	// Enabled indicates whether the virtual machine writes vmware.log files.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// RotateSize is the size in bytes at which the vmware.log file is rotated.
+	// Zero disables the size based rotation.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RotateSize *int64 `json:"rotateSize,omitempty"`
+
+	// KeepOld is the number of rotated vmware.log files which are kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepOld *int32 `json:"keepOld,omitempty"`
+}
+
+// HugePageSize is the size of the huge pages of a host.
+// +kubebuilder:validation:Enum="2Mi";"1Gi"
+type HugePageSize string
+
+const (
+	// HugePageSize2Mi backs the memory by 2 MiB huge pages.
+	HugePageSize2Mi HugePageSize = "2Mi"
+
+	// HugePageSize1Gi backs the memory by 1 GiB huge pages.
+	HugePageSize1Gi HugePageSize = "1Gi"
+)
+
+// VirtualMachineMemoryBacking defines the backing of the memory of a virtual machine.
+type VirtualMachineMemoryBacking struct {
+	// HugePageSize is the size of the huge pages of the host which back the
+	// memory of the virtual machine.
+	// 1Gi huge pages require the memory of the virtual machine to be reserved,
+	// so its memory reservation is locked to its memory size.
+	// The hosts are checked to allocate huge pages for virtual machines and,
+	// for 1Gi, to have CPUs supporting 1 GiB pages. The check is best-effort,
+	// as vSphere does not report whether a host has enough free huge pages, so
+	// the virtual machine may still fail to power on.
+	HugePageSize HugePageSize `json:"hugePageSize"`
+}
+
+// VirtualMachineIsolation defines the isolation of a virtual machine.
+type VirtualMachineIsolation struct {
+	// CopyPasteDisabled indicates whether copy, paste and drag and drop
+	// between the guest and the remote console are disabled.
+	// +optional
+	CopyPasteDisabled *bool `json:"copyPasteDisabled,omitempty"`
+
+	// DeviceConnectDisabled indicates whether users and processes of the guest
+	// are prevented from connecting, disconnecting and modifying devices, e.g.
+	// CD-ROMs and network adapters.
+	// +optional
+	DeviceConnectDisabled *bool `json:"deviceConnectDisabled,omitempty"`
+
+	// VMCIRestricted indicates whether the VMCI communication of the virtual
+	// machine is restricted to its host.
+	// +optional
+	VMCIRestricted *bool `json:"vmciRestricted,omitempty"`
 }
 
 // VSphereMachineTemplateResource describes the data needed to create a VSphereMachine from a template.
@@ -376,8 +1680,59 @@ type NetworkDeviceSpec struct {
 	// If true, CAPV will not verify IP address allocation.
 	// +optional
 	SkipIPAllocation bool `json:"skipIPAllocation,omitempty"`
+
+	// Primary marks the device as the primary interface of the machine.
+	// The IP addresses of the primary device are reported first in the
+	// addresses of the machine, so they are preferred e.g. as node address.
+	// At most one device may be marked as primary.
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+
+	// AdapterType is the type of the virtual network adapter of the device.
+	// Defaults to vmxnet3.
+	// +optional
+	AdapterType NetworkAdapterType `json:"adapterType,omitempty"`
+
+	// PhysicalFunction is the PCI ID of the SR-IOV physical function backing
+	// the device, e.g. 0000:3b:00.1, or the name of its physical adapter on
+	// the hosts, e.g. vmnic4.
+	// It is required if the adapter type is sriov, and ignored otherwise.
+	// +optional
+	PhysicalFunction string `json:"physicalFunction,omitempty"`
+
+	// WakeOnLanEnabled enables waking up the VM by a Wake-on-LAN packet
+	// received by the device.
+	// Defaults to the vSphere default for new network adapters.
+	// +optional
+	WakeOnLanEnabled *bool `json:"wakeOnLanEnabled,omitempty"`
+
+	// AllowGuestControl allows the guest to connect and disconnect the
+	// device, e.g. by an in-guest agent managing the network.
+	// Defaults to the vSphere default for new network adapters.
+	// +optional
+	AllowGuestControl *bool `json:"allowGuestControl,omitempty"`
 }
 
+// NetworkAdapterType is the type of the virtual network adapter of a network device.
+// +kubebuilder:validation:Enum=vmxnet3;e1000;e1000e;sriov
+type NetworkAdapterType string
+
+const (
+	// NetworkAdapterTypeVmxnet3 is the paravirtualized vmxnet3 adapter, which
+	// performs best but requires a driver in the guest.
+	NetworkAdapterTypeVmxnet3 NetworkAdapterType = "vmxnet3"
+
+	// NetworkAdapterTypeE1000 is the emulated Intel 82545EM adapter.
+	NetworkAdapterTypeE1000 NetworkAdapterType = "e1000"
+
+	// NetworkAdapterTypeE1000e is the emulated Intel 82574 adapter.
+	NetworkAdapterTypeE1000e NetworkAdapterType = "e1000e"
+
+	// NetworkAdapterTypeSriov is a virtual function of a SR-IOV capable
+	// physical adapter of the host, which is passed through to the guest.
+	NetworkAdapterTypeSriov NetworkAdapterType = "sriov"
+)
+
 // DHCPOverrides allows for the control over several DHCP behaviors.
 // Overrides will only be applied when the corresponding DHCP flag is set.
 // Only configured values will be sent, omitted values will default to
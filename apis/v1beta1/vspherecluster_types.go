@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -26,6 +27,17 @@ const (
 	// resources associated with VSphereCluster before removing it from the
 	// API server.
 	ClusterFinalizer = "vspherecluster.infrastructure.cluster.x-k8s.io"
+
+	// RefreshVMsAnnotation on a VSphereCluster requeues all VSphereVMs of the cluster when
+	// its value changes, e.g. to refresh their status after a vCenter outage. The value is
+	// arbitrary, a timestamp is recommended.
+	RefreshVMsAnnotation = "vspherecluster.infrastructure.cluster.x-k8s.io/refresh-vms"
+
+	// DrainingHostsAnnotation on a VSphereCluster lists the names of ESXi hosts, separated by
+	// commas, which are being decommissioned. The worker Machines whose VMs run on these hosts
+	// get the delete-machine annotation of Cluster API, so they are deleted first when their
+	// MachineSet scales down.
+	DrainingHostsAnnotation = "vspherecluster.infrastructure.cluster.x-k8s.io/draining-hosts"
 )
 
 // VCenterVersion conveys the API version of the vCenter instance.
@@ -66,6 +78,101 @@ type VSphereClusterSpec struct {
 	// A valid selector will select all failure domains which match the selector.
 	// +optional
 	FailureDomainSelector *metav1.LabelSelector `json:"failureDomainSelector,omitempty"`
+
+	// ResourcePool configures a dedicated resource pool which is created for
+	// the cluster and into which all the virtual machines of the cluster are
+	// placed. It takes precedence over the resource pool defined on the
+	// machines or their failure domains.
+	// The resource pool is deleted together with the cluster.
+	// +optional
+	ResourcePool *ClusterResourcePoolSpec `json:"resourcePool,omitempty"`
+
+	// SSHAuthorizedKeys is a list of additional SSH public keys which are
+	// authorized to log in to all the virtual machines of the cluster.
+	// The keys are added to the users defined in the bootstrap data, in
+	// addition to the keys provided by the bootstrap provider.
+	// The keys are only applied to virtual machines created after they have
+	// been set.
+	// +optional
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+
+	// Proxy configures the HTTP proxy of all the virtual machines of the
+	// cluster. The proxy environment variables are added to /etc/environment
+	// and to the environment of systemd and containerd by the bootstrap data.
+	// The CIDRs of the pods and services of the cluster and the host of the
+	// control plane endpoint are added to the no proxy list.
+	// The proxy is only applied to virtual machines created after it has
+	// been set.
+	// +optional
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+
+	// MinHostFreeMemoryMiB is the minimum free memory in MiB of the hosts on
+	// which the virtual machines of the cluster are placed. Hosts with less
+	// free memory, e.g. hosts which are about to swap, are excluded from the
+	// placement. The free memory is the memory of a host minus its memory
+	// usage. The threshold is only applied to virtual machines created after
+	// it has been set.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinHostFreeMemoryMiB int64 `json:"minHostFreeMemoryMiB,omitempty"`
+
+	// DrainingDatastores are the names of the datastores which are drained,
+	// e.g. as they are decommissioned. The virtual machines of the cluster
+	// whose disks or files are on one of them are relocated to another
+	// datastore by storage vMotion, which is reported by the DatastoresDrained
+	// condition of the VSphereVMs. New virtual machines should not be placed
+	// on these datastores.
+	// +optional
+	DrainingDatastores []string `json:"drainingDatastores,omitempty"`
+
+	// RegistryMirrors configures the mirrors and CA certificates of the
+	// container image registries of all the virtual machines of the cluster,
+	// e.g. of air-gapped clusters. They are added to the containerd
+	// configuration by the bootstrap data. The registry mirrors are only
+	// applied to virtual machines created after they have been set.
+	// +optional
+	// +listType=map
+	// +listMapKey=registry
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
+	// ControlPlaneEndpointAddressFromPool is a reference to an IP address pool
+	// from which the address of the control plane endpoint is claimed, e.g.
+	// for an external load balancer. The claimed address is set as the host
+	// of ControlPlaneEndpoint, which must not be set together with it. The
+	// IPAddressClaim is released when the VSphereCluster is deleted.
+	// +optional
+	ControlPlaneEndpointAddressFromPool *corev1.TypedLocalObjectReference `json:"controlPlaneEndpointAddressFromPool,omitempty"`
+}
+
+// ClusterResourcePoolSpec defines the resource pool created for a cluster.
+type ClusterResourcePoolSpec struct {
+	// Parent is the name or inventory path of the resource pool under which
+	// the resource pool of the cluster is created.
+	// +kubebuilder:validation:MinLength=1
+	Parent string `json:"parent"`
+
+	// Datacenter is the name or inventory path of the datacenter in which
+	// the parent resource pool is located.
+	// Required if the vCenter has more than one datacenter and Parent is not
+	// an absolute inventory path.
+	// +optional
+	Datacenter string `json:"datacenter,omitempty"`
+
+	// Name is the name of the resource pool created for the cluster.
+	// Defaults to the namespace and name of the VSphereCluster joined by
+	// a dash.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// CPUReservationMHz is the amount of CPU in MHz guaranteed to the
+	// resource pool.
+	// +optional
+	CPUReservationMHz *int64 `json:"cpuReservationMHz,omitempty"`
+
+	// MemoryReservationMiB is the amount of memory in MiB guaranteed to the
+	// resource pool.
+	// +optional
+	MemoryReservationMiB *int64 `json:"memoryReservationMiB,omitempty"`
 }
 
 // ClusterModule holds the anti affinity construct `ClusterModule` identifier
@@ -98,6 +205,11 @@ type VSphereClusterStatus struct {
 
 	// VCenterVersion defines the version of the vCenter server defined in the spec.
 	VCenterVersion VCenterVersion `json:"vCenterVersion,omitempty"`
+
+	// ResourcePool is the inventory path of the resource pool created for
+	// the cluster.
+	// +optional
+	ResourcePool string `json:"resourcePool,omitempty"`
 }
 
 // +kubebuilder:object:root=true
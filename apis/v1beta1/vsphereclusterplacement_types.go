@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VSphereClusterPlacementStatus summarizes the placement and health of the VMs of a
+// cluster.
+type VSphereClusterPlacementStatus struct {
+	// Replicas is the number of VSphereVMs of the cluster.
+	// +optional
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of ready VSphereVMs of the cluster.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Machines is the placement and health of the VSphereVMs of the cluster,
+	// sorted by their name.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Machines []VSphereVMPlacement `json:"machines,omitempty"`
+}
+
+// VSphereVMPlacement is the placement and health of a VSphereVM.
+type VSphereVMPlacement struct {
+	// Name is the name of the VSphereVM.
+	Name string `json:"name"`
+
+	// Machine is the name of the Machine of the VSphereVM.
+	// +optional
+	Machine string `json:"machine,omitempty"`
+
+	// FailureDomain is the failure domain of the Machine of the VSphereVM.
+	// +optional
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	// Host is the host the VM of the VSphereVM runs on.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Datastores are the names of the datastores of the files and disks of
+	// the VM of the VSphereVM.
+	// +optional
+	Datastores []string `json:"datastores,omitempty"`
+
+	// Ready is true when the VSphereVM is ready.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Reason is the reason of the Ready condition of the VSphereVM if it is
+	// not ready.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=vsphereclusterplacements,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Number of VSphereVMs of the cluster"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="Number of ready VSphereVMs of the cluster"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of VSphereClusterPlacement"
+
+// VSphereClusterPlacement is the Schema for the vsphereclusterplacements API. It
+// summarizes where the VMs of a VSphereCluster of the same name run, and is maintained
+// by the VSphereClusterPlacement controller from the cached VSphereVMs and Machines of
+// the cluster, so it is eventually consistent with them.
+type VSphereClusterPlacement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status VSphereClusterPlacementStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VSphereClusterPlacementList contains a list of VSphereClusterPlacement.
+type VSphereClusterPlacementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VSphereClusterPlacement `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &VSphereClusterPlacement{}, &VSphereClusterPlacementList{})
+}
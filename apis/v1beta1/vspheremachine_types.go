@@ -27,6 +27,17 @@ const (
 	// resources associated with VSphereMachine before removing it from the
 	// API Server.
 	MachineFinalizer = "vspheremachine.infrastructure.cluster.x-k8s.io"
+
+	// PlacementProfileLabel is the label of a Machine which selects the placement profile of its
+	// VSphereVM, e.g. set in the template of a MachineDeployment. The value is the name of a
+	// ConfigMap in the namespace of the Machine which defines the placement of the VSphereVM.
+	PlacementProfileLabel = "vspheremachine.infrastructure.cluster.x-k8s.io/placement-profile"
+
+	// TemplateKubernetesVersionAnnotation is the annotation of a VSphereMachine with the Kubernetes
+	// version baked into the template it is cloned from, e.g. set in the template of a
+	// VSphereMachineTemplate. The webhook warns if it differs from the version of the Machine,
+	// or of the MachineDeployment or Cluster topology before a Machine owns the VSphereMachine.
+	TemplateKubernetesVersionAnnotation = "vspheremachine.infrastructure.cluster.x-k8s.io/template-kubernetes-version"
 )
 
 // VSphereMachineSpec defines the desired state of VSphereMachine.
@@ -68,6 +79,13 @@ type VSphereMachineSpec struct {
 	//
 	// +optional
 	GuestSoftPowerOffTimeout *metav1.Duration `json:"guestSoftPowerOffTimeout,omitempty"`
+
+	// ClassName is the name of the VSphereMachineClass which defines the
+	// NumCPUs, NumCoresPerSocket, MemoryMiB, MemoryReservationLockedToMax and
+	// ResourceAllocation of the virtual machine. These fields must not be set
+	// if a class is referenced.
+	// +optional
+	ClassName string `json:"className,omitempty"`
 }
 
 // VSphereMachineStatus defines the observed state of VSphereMachine.
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VSphereMachineClassSpec defines the CPU and memory of the virtual machines of a
+// VSphereMachineClass.
+type VSphereMachineClassSpec struct {
+	// NumCPUs is the number of virtual processors in a virtual machine.
+	// +kubebuilder:validation:Minimum=1
+	NumCPUs int32 `json:"numCPUs"`
+
+	// NumCoresPerSocket is the number of cores among which to distribute CPUs
+	// in a virtual machine.
+	// Defaults to NumCPUs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumCoresPerSocket int32 `json:"numCoresPerSocket,omitempty"`
+
+	// MemoryMiB is the size of a virtual machine's memory, in MiB.
+	// +kubebuilder:validation:Minimum=1
+	MemoryMiB int64 `json:"memoryMiB"`
+
+	// MemoryReservationLockedToMax locks the memory reservation of a virtual
+	// machine to its memory size.
+	// +optional
+	MemoryReservationLockedToMax *bool `json:"memoryReservationLockedToMax,omitempty"`
+
+	// ResourceAllocation defines the reservation, limit and shares of the CPU
+	// and memory of a virtual machine.
+	// +optional
+	ResourceAllocation *VirtualMachineResourceAllocation `json:"resourceAllocation,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=vspheremachineclasses,scope=Cluster,categories=cluster-api
+// +kubebuilder:printcolumn:name="CPUs",type="integer",JSONPath=".spec.numCPUs",description="Number of virtual processors"
+// +kubebuilder:printcolumn:name="Memory",type="integer",JSONPath=".spec.memoryMiB",description="Memory in MiB"
+
+// VSphereMachineClass is the Schema for the vspheremachineclasses API. It defines a
+// named size of virtual machines, which VSphereMachines reference by their ClassName
+// rather than defining the CPU and memory themselves.
+type VSphereMachineClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VSphereMachineClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VSphereMachineClassList contains a list of VSphereMachineClass.
+type VSphereMachineClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VSphereMachineClass `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &VSphereMachineClass{}, &VSphereMachineClassList{})
+}
@@ -34,10 +34,38 @@ const (
 	// IPAddressClaim that is in use.
 	IPAddressClaimFinalizer = "vspherevm.infrastructure.cluster.x-k8s.io/ip-claim-protection"
 
+	// MarkAsTemplateAnnotation is the annotation of a VSphereVM which marks its VM as a
+	// template once the VSphereVM is ready and the VM is powered off, e.g. to build golden
+	// images. Removing the annotation marks the template as a virtual machine again.
+	MarkAsTemplateAnnotation = "vspherevm.infrastructure.cluster.x-k8s.io/mark-as-template"
+
+	// RedeployAnnotation is the annotation of a VSphereVM which allows changing its template,
+	// and redeploys the VM once the template differs from the one it was cloned from: the VM
+	// is powered off, destroyed and cloned from the new template, keeping its name, MAC
+	// addresses and BIOS UUID where possible.
+	RedeployAnnotation = "vspherevm.infrastructure.cluster.x-k8s.io/redeploy"
+
+	// HostMaintenanceTaint is the key of the NoSchedule taint of the node of a VSphereVM
+	// while the ESXi host of its VM enters or is in maintenance mode.
+	HostMaintenanceTaint = "vspherevm.infrastructure.cluster.x-k8s.io/host-maintenance"
+
+	// NodeCapacityRefreshAnnotation is the annotation of a node cordoned by CAPV until the
+	// kubelet reports the capacity of the reconfigured VM of its VSphereVM. Its value is the
+	// boot ID of the node when it was cordoned, before the VM was reconfigured.
+	NodeCapacityRefreshAnnotation = "vspherevm.infrastructure.cluster.x-k8s.io/node-capacity-refresh"
+
+	// NodeCapacityAnnotation is the annotation of a node cordoned by CAPV with the CPU and
+	// memory capacity the node reported when it was cordoned, e.g. "cpu=4,memory=8148012Ki".
+	NodeCapacityAnnotation = "vspherevm.infrastructure.cluster.x-k8s.io/node-capacity"
+
 	// GuestSoftPowerOffDefaultTimeout is the default timeout to wait for
 	// shutdown finishes in the guest VM before powering off the VM forcibly
 	// Only effective when the powerOffMode is set to trySoft.
 	GuestSoftPowerOffDefaultTimeout = 5 * time.Minute
+
+	// SecureBootFailureDefaultTimeout is the default time a VM may run with Secure Boot
+	// without a guest heartbeat before its boot is reported to have likely failed.
+	SecureBootFailureDefaultTimeout = 10 * time.Minute
 )
 
 // VSphereVMSpec defines the desired state of VSphereVM.
@@ -84,6 +112,50 @@ type VSphereVMSpec struct {
 	//
 	// +optional
 	GuestSoftPowerOffTimeout *metav1.Duration `json:"guestSoftPowerOffTimeout,omitempty"`
+
+	// DesiredPowerState is the power state the VM is reconciled to, e.g. to
+	// power off the VM temporarily without deleting the machine. The VM is
+	// powered off according to the PowerOffMode. It takes precedence over
+	// PowerOnAfterClone.
+	//
+	// If omitted, the VM is powered on unless PowerOnAfterClone is false.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=poweredOn;poweredOff
+	DesiredPowerState VirtualMachinePowerState `json:"desiredPowerState,omitempty"`
+
+	// SSHAuthorizedKeys is a list of additional SSH public keys which are
+	// added to the users defined in the bootstrap data when the VM is
+	// created. It is set from the SSHAuthorizedKeys of the VSphereCluster.
+	// +optional
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+
+	// Proxy is the HTTP proxy which is added to the bootstrap data when the VM
+	// is created. It is set from the Proxy of the VSphereCluster, with the
+	// networks of the cluster added to its no proxy list.
+	// +optional
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+
+	// MinHostFreeMemoryMiB is the minimum free memory in MiB of the host on
+	// which the VM is placed when it is created. It is set from the
+	// MinHostFreeMemoryMiB of the VSphereCluster.
+	// +optional
+	MinHostFreeMemoryMiB int64 `json:"minHostFreeMemoryMiB,omitempty"`
+
+	// DrainingDatastores are the names of the datastores which are drained,
+	// e.g. as they are decommissioned. The VM is relocated to another datastore
+	// if its disks or files are on one of them. It is set from the
+	// DrainingDatastores of the VSphereCluster.
+	// +optional
+	DrainingDatastores []string `json:"drainingDatastores,omitempty"`
+
+	// RegistryMirrors are the mirrors and CA certificates of the container
+	// image registries which are added to the bootstrap data when the VM is
+	// created. They are set from the RegistryMirrors of the VSphereCluster.
+	// +optional
+	// +listType=map
+	// +listMapKey=registry
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
 }
 
 // VSphereVMStatus defines the observed state of VSphereVM.
@@ -117,10 +189,41 @@ type VSphereVMStatus struct {
 	// +optional
 	Snapshot string `json:"snapshot,omitempty"`
 
+	// AdditionalDisksBusSharing is the bus sharing mode of the SCSI controller
+	// of the additional disks of the VM, if AdditionalDisksController is set.
+	// +optional
+	AdditionalDisksBusSharing SCSIBusSharing `json:"additionalDisksBusSharing,omitempty"`
+
 	// RetryAfter tracks the time we can retry queueing a task
 	// +optional
 	RetryAfter metav1.Time `json:"retryAfter,omitempty"`
 
+	// ExcludedDatastores is the list of the names of the datastores which ran
+	// out of space while cloning the VM. They are not used for the placement of
+	// the VM as long as other datastores compatible with its storage policy are
+	// available.
+	// +optional
+	ExcludedDatastores []string `json:"excludedDatastores,omitempty"`
+
+	// ExcludedHosts is the list of the names of the hosts which entered
+	// maintenance mode or disconnected while cloning the VM. They are not used
+	// for the placement of the VM as long as other hosts of its resource pool
+	// are available.
+	// +optional
+	ExcludedHosts []string `json:"excludedHosts,omitempty"`
+
+	// VMName is the name of the VM in vCenter if it differs from the name of
+	// the VSphereVM, because another VM had the name of the VSphereVM when it
+	// was cloned with the suffix CloneConflictPolicy.
+	// +optional
+	VMName string `json:"vmName,omitempty"`
+
+	// DeployedTemplate is the template the VM was cloned from. The VM of a
+	// VSphereVM with the RedeployAnnotation is redeployed once the template of
+	// the VSphereVM differs from it.
+	// +optional
+	DeployedTemplate string `json:"deployedTemplate,omitempty"`
+
 	// TaskRef is a managed object reference to a Task related to the machine.
 	// This value is set automatically at runtime and should not be set or
 	// modified by users.
@@ -132,6 +235,52 @@ type VSphereVMStatus struct {
 	// +optional
 	Network []NetworkStatus `json:"network,omitempty"`
 
+	// SerialPorts is the list of serial ports of the VM and their backing,
+	// i.e. where the console output of the VM can be found.
+	// +optional
+	SerialPorts []SerialPortStatus `json:"serialPorts,omitempty"`
+
+	// Disks is the list of disks of the VM and the controllers they are
+	// attached to.
+	// +optional
+	Disks []VirtualMachineDiskStatus `json:"disks,omitempty"`
+
+	// Datastores are the names of the datastores of the files and disks of
+	// the VM.
+	// +optional
+	Datastores []string `json:"datastores,omitempty"`
+
+	// CPUShares are the effective shares of the CPU of the VM.
+	// +optional
+	CPUShares *ResourceShares `json:"cpuShares,omitempty"`
+
+	// MemoryShares are the effective shares of the memory of the VM.
+	// +optional
+	MemoryShares *ResourceShares `json:"memoryShares,omitempty"`
+
+	// GuestDiskUsage is the usage of the filesystems of the guest as reported
+	// by VMware Tools. It is refreshed at the guest disk usage refresh interval
+	// of the controller manager, and omitted if the guest does not report the
+	// usage, e.g. because VMware Tools are not installed.
+	// +optional
+	GuestDiskUsage *GuestDiskUsage `json:"guestDiskUsage,omitempty"`
+
+	// GuestNetwork is the network configuration of the guest as observed by
+	// VMware Tools, e.g. to compare the IP addresses, gateways and DNS servers
+	// the guest received with its network devices in the spec. It is
+	// refreshed on every reconcile, and omitted if the guest does not report
+	// its network, e.g. because VMware Tools are not installed.
+	// +optional
+	GuestNetwork *GuestNetworkStatus `json:"guestNetwork,omitempty"`
+
+	// MigrationHistory is the history of the migrations of the VM between ESXi
+	// hosts, e.g. by vMotion, to correlate disruptions of the node with vSphere
+	// activity. It is refreshed from the events of vCenter at the migration
+	// history refresh interval of the controller manager, and omitted if the
+	// refresh is disabled.
+	// +optional
+	MigrationHistory *MigrationHistory `json:"migrationHistory,omitempty"`
+
 	// FailureReason will be set in the event that there is a terminal problem
 	// reconciling the vspherevm and will contain a succinct value suitable
 	// for vm interpretation.
@@ -21,8 +21,8 @@ limitations under the License.
 package v1beta1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/errors"
@@ -43,6 +43,21 @@ func (in *APIEndpoint) DeepCopy() *APIEndpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalDisksControllerSpec) DeepCopyInto(out *AdditionalDisksControllerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalDisksControllerSpec.
+func (in *AdditionalDisksControllerSpec) DeepCopy() *AdditionalDisksControllerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalDisksControllerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
 	*out = *in
@@ -59,6 +74,21 @@ func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CDROMSpec) DeepCopyInto(out *CDROMSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CDROMSpec.
+func (in *CDROMSpec) DeepCopy() *CDROMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CDROMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterModule) DeepCopyInto(out *ClusterModule) {
 	*out = *in
@@ -74,6 +104,31 @@ func (in *ClusterModule) DeepCopy() *ClusterModule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterResourcePoolSpec) DeepCopyInto(out *ClusterResourcePoolSpec) {
+	*out = *in
+	if in.CPUReservationMHz != nil {
+		in, out := &in.CPUReservationMHz, &out.CPUReservationMHz
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryReservationMiB != nil {
+		in, out := &in.MemoryReservationMiB, &out.MemoryReservationMiB
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterResourcePoolSpec.
+func (in *ClusterResourcePoolSpec) DeepCopy() *ClusterResourcePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterResourcePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DHCPOverrides) DeepCopyInto(out *DHCPOverrides) {
 	*out = *in
@@ -134,6 +189,31 @@ func (in *DHCPOverrides) DeepCopy() *DHCPOverrides {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskStorageIOAllocation) DeepCopyInto(out *DiskStorageIOAllocation) {
+	*out = *in
+	if in.Shares != nil {
+		in, out := &in.Shares, &out.Shares
+		*out = new(ResourceShares)
+		**out = **in
+	}
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskStorageIOAllocation.
+func (in *DiskStorageIOAllocation) DeepCopy() *DiskStorageIOAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskStorageIOAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FailureDomain) DeepCopyInto(out *FailureDomain) {
 	*out = *in
@@ -169,6 +249,172 @@ func (in *FailureDomainHosts) DeepCopy() *FailureDomainHosts {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestDiskUsage) DeepCopyInto(out *GuestDiskUsage) {
+	*out = *in
+	if in.Filesystems != nil {
+		in, out := &in.Filesystems, &out.Filesystems
+		*out = make([]GuestFilesystemUsage, len(*in))
+		copy(*out, *in)
+	}
+	in.LastRefreshTime.DeepCopyInto(&out.LastRefreshTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestDiskUsage.
+func (in *GuestDiskUsage) DeepCopy() *GuestDiskUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestDiskUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestFilesystemUsage) DeepCopyInto(out *GuestFilesystemUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestFilesystemUsage.
+func (in *GuestFilesystemUsage) DeepCopy() *GuestFilesystemUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestFilesystemUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestNetworkDeviceStatus) DeepCopyInto(out *GuestNetworkDeviceStatus) {
+	*out = *in
+	if in.IPAddrs != nil {
+		in, out := &in.IPAddrs, &out.IPAddrs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestNetworkDeviceStatus.
+func (in *GuestNetworkDeviceStatus) DeepCopy() *GuestNetworkDeviceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestNetworkDeviceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestNetworkStatus) DeepCopyInto(out *GuestNetworkStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]GuestNetworkDeviceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SearchDomains != nil {
+		in, out := &in.SearchDomains, &out.SearchDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestNetworkStatus.
+func (in *GuestNetworkStatus) DeepCopy() *GuestNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestProbeCommand) DeepCopyInto(out *GuestProbeCommand) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestProbeCommand.
+func (in *GuestProbeCommand) DeepCopy() *GuestProbeCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestProbeCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestReadinessProbe) DeepCopyInto(out *GuestReadinessProbe) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = new(GuestProbeCommand)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestReadinessProbe.
+func (in *GuestReadinessProbe) DeepCopy() *GuestReadinessProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestReadinessProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameSpec) DeepCopyInto(out *HostnameSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameSpec.
+func (in *HostnameSpec) DeepCopy() *HostnameSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationHistory) DeepCopyInto(out *MigrationHistory) {
+	*out = *in
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]VirtualMachineMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastRefreshTime.DeepCopyInto(&out.LastRefreshTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationHistory.
+func (in *MigrationHistory) DeepCopy() *MigrationHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -224,7 +470,7 @@ func (in *NetworkDeviceSpec) DeepCopyInto(out *NetworkDeviceSpec) {
 	}
 	if in.AddressesFromPools != nil {
 		in, out := &in.AddressesFromPools, &out.AddressesFromPools
-		*out = make([]v1.TypedLocalObjectReference, len(*in))
+		*out = make([]corev1.TypedLocalObjectReference, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -239,6 +485,16 @@ func (in *NetworkDeviceSpec) DeepCopyInto(out *NetworkDeviceSpec) {
 		*out = new(DHCPOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.WakeOnLanEnabled != nil {
+		in, out := &in.WakeOnLanEnabled, &out.WakeOnLanEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowGuestControl != nil {
+		in, out := &in.AllowGuestControl, &out.AllowGuestControl
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDeviceSpec.
@@ -313,6 +569,43 @@ func (in *NetworkStatus) DeepCopy() *NetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVASource) DeepCopyInto(out *OVASource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVASource.
+func (in *OVASource) DeepCopy() *OVASource {
+	if in == nil {
+		return nil
+	}
+	out := new(OVASource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVFEnvironmentSpec) DeepCopyInto(out *OVFEnvironmentSpec) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVFEnvironmentSpec.
+func (in *OVFEnvironmentSpec) DeepCopy() *OVFEnvironmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVFEnvironmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PCIDeviceSpec) DeepCopyInto(out *PCIDeviceSpec) {
 	*out = *in
@@ -354,86 +647,263 @@ func (in *PlacementConstraint) DeepCopy() *PlacementConstraint {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SSHUser) DeepCopyInto(out *SSHUser) {
+func (in *ProxyConfiguration) DeepCopyInto(out *ProxyConfiguration) {
 	*out = *in
-	if in.AuthorizedKeys != nil {
-		in, out := &in.AuthorizedKeys, &out.AuthorizedKeys
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHUser.
-func (in *SSHUser) DeepCopy() *SSHUser {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfiguration.
+func (in *ProxyConfiguration) DeepCopy() *ProxyConfiguration {
 	if in == nil {
 		return nil
 	}
-	out := new(SSHUser)
+	out := new(ProxyConfiguration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Topology) DeepCopyInto(out *Topology) {
+func (in *RegistryMirror) DeepCopyInto(out *RegistryMirror) {
 	*out = *in
-	if in.ComputeCluster != nil {
-		in, out := &in.ComputeCluster, &out.ComputeCluster
-		*out = new(string)
-		**out = **in
-	}
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = new(FailureDomainHosts)
-		**out = **in
-	}
-	if in.Networks != nil {
-		in, out := &in.Networks, &out.Networks
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
-func (in *Topology) DeepCopy() *Topology {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryMirror.
+func (in *RegistryMirror) DeepCopy() *RegistryMirror {
 	if in == nil {
 		return nil
 	}
-	out := new(Topology)
+	out := new(RegistryMirror)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VSphereCluster) DeepCopyInto(out *VSphereCluster) {
+func (in *ResourceAllocationSpec) DeepCopyInto(out *ResourceAllocationSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Reservation != nil {
+		in, out := &in.Reservation, &out.Reservation
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Shares != nil {
+		in, out := &in.Shares, &out.Shares
+		*out = new(ResourceShares)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereCluster.
-func (in *VSphereCluster) DeepCopy() *VSphereCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAllocationSpec.
+func (in *ResourceAllocationSpec) DeepCopy() *ResourceAllocationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VSphereCluster)
+	out := new(ResourceAllocationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VSphereCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VSphereClusterIdentity) DeepCopyInto(out *VSphereClusterIdentity) {
+func (in *ResourceShares) DeepCopyInto(out *ResourceShares) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceShares.
+func (in *ResourceShares) DeepCopy() *ResourceShares {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceShares)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHUser) DeepCopyInto(out *SSHUser) {
+	*out = *in
+	if in.AuthorizedKeys != nil {
+		in, out := &in.AuthorizedKeys, &out.AuthorizedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHUser.
+func (in *SSHUser) DeepCopy() *SSHUser {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretFile) DeepCopyInto(out *SecretFile) {
+	*out = *in
+	out.Secret = in.Secret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretFile.
+func (in *SecretFile) DeepCopy() *SecretFile {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretFileSource) DeepCopyInto(out *SecretFileSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretFileSource.
+func (in *SecretFileSource) DeepCopy() *SecretFileSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretFileSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialPortSpec) DeepCopyInto(out *SerialPortSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialPortSpec.
+func (in *SerialPortSpec) DeepCopy() *SerialPortSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialPortSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialPortStatus) DeepCopyInto(out *SerialPortStatus) {
+	*out = *in
+	out.SerialPortSpec = in.SerialPortSpec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialPortStatus.
+func (in *SerialPortStatus) DeepCopy() *SerialPortStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialPortStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedDiskSpec) DeepCopyInto(out *SharedDiskSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedDiskSpec.
+func (in *SharedDiskSpec) DeepCopy() *SharedDiskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedDiskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAffinitySpec) DeepCopyInto(out *StorageAffinitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAffinitySpec.
+func (in *StorageAffinitySpec) DeepCopy() *StorageAffinitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAffinitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Topology) DeepCopyInto(out *Topology) {
+	*out = *in
+	if in.ComputeCluster != nil {
+		in, out := &in.ComputeCluster, &out.ComputeCluster
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = new(FailureDomainHosts)
+		**out = **in
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
+func (in *Topology) DeepCopy() *Topology {
+	if in == nil {
+		return nil
+	}
+	out := new(Topology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereCluster) DeepCopyInto(out *VSphereCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereCluster.
+func (in *VSphereCluster) DeepCopy() *VSphereCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VSphereCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereClusterIdentity) DeepCopyInto(out *VSphereClusterIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
@@ -563,6 +1033,86 @@ func (in *VSphereClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereClusterPlacement) DeepCopyInto(out *VSphereClusterPlacement) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereClusterPlacement.
+func (in *VSphereClusterPlacement) DeepCopy() *VSphereClusterPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereClusterPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VSphereClusterPlacement) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereClusterPlacementList) DeepCopyInto(out *VSphereClusterPlacementList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VSphereClusterPlacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereClusterPlacementList.
+func (in *VSphereClusterPlacementList) DeepCopy() *VSphereClusterPlacementList {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereClusterPlacementList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VSphereClusterPlacementList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereClusterPlacementStatus) DeepCopyInto(out *VSphereClusterPlacementStatus) {
+	*out = *in
+	if in.Machines != nil {
+		in, out := &in.Machines, &out.Machines
+		*out = make([]VSphereVMPlacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereClusterPlacementStatus.
+func (in *VSphereClusterPlacementStatus) DeepCopy() *VSphereClusterPlacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereClusterPlacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VSphereClusterSpec) DeepCopyInto(out *VSphereClusterSpec) {
 	*out = *in
@@ -579,7 +1129,39 @@ func (in *VSphereClusterSpec) DeepCopyInto(out *VSphereClusterSpec) {
 	}
 	if in.FailureDomainSelector != nil {
 		in, out := &in.FailureDomainSelector, &out.FailureDomainSelector
-		*out = new(metav1.LabelSelector)
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourcePool != nil {
+		in, out := &in.ResourcePool, &out.ResourcePool
+		*out = new(ClusterResourcePoolSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSHAuthorizedKeys != nil {
+		in, out := &in.SSHAuthorizedKeys, &out.SSHAuthorizedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainingDatastores != nil {
+		in, out := &in.DrainingDatastores, &out.DrainingDatastores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ControlPlaneEndpointAddressFromPool != nil {
+		in, out := &in.ControlPlaneEndpointAddressFromPool, &out.ControlPlaneEndpointAddressFromPool
+		*out = new(corev1.TypedLocalObjectReference)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -939,31 +1521,25 @@ func (in *VSphereMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VSphereMachineList) DeepCopyInto(out *VSphereMachineList) {
+func (in *VSphereMachineClass) DeepCopyInto(out *VSphereMachineClass) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]VSphereMachine, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereMachineList.
-func (in *VSphereMachineList) DeepCopy() *VSphereMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereMachineClass.
+func (in *VSphereMachineClass) DeepCopy() *VSphereMachineClass {
 	if in == nil {
 		return nil
 	}
-	out := new(VSphereMachineList)
+	out := new(VSphereMachineClass)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VSphereMachineList) DeepCopyObject() runtime.Object {
+func (in *VSphereMachineClass) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -971,14 +1547,103 @@ func (in *VSphereMachineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VSphereMachineSpec) DeepCopyInto(out *VSphereMachineSpec) {
+func (in *VSphereMachineClassList) DeepCopyInto(out *VSphereMachineClassList) {
 	*out = *in
-	in.VirtualMachineCloneSpec.DeepCopyInto(&out.VirtualMachineCloneSpec)
-	if in.ProviderID != nil {
-		in, out := &in.ProviderID, &out.ProviderID
-		*out = new(string)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VSphereMachineClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereMachineClassList.
+func (in *VSphereMachineClassList) DeepCopy() *VSphereMachineClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereMachineClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VSphereMachineClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereMachineClassSpec) DeepCopyInto(out *VSphereMachineClassSpec) {
+	*out = *in
+	if in.MemoryReservationLockedToMax != nil {
+		in, out := &in.MemoryReservationLockedToMax, &out.MemoryReservationLockedToMax
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResourceAllocation != nil {
+		in, out := &in.ResourceAllocation, &out.ResourceAllocation
+		*out = new(VirtualMachineResourceAllocation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereMachineClassSpec.
+func (in *VSphereMachineClassSpec) DeepCopy() *VSphereMachineClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereMachineClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereMachineList) DeepCopyInto(out *VSphereMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VSphereMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereMachineList.
+func (in *VSphereMachineList) DeepCopy() *VSphereMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VSphereMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereMachineSpec) DeepCopyInto(out *VSphereMachineSpec) {
+	*out = *in
+	in.VirtualMachineCloneSpec.DeepCopyInto(&out.VirtualMachineCloneSpec)
+	if in.ProviderID != nil {
+		in, out := &in.ProviderID, &out.ProviderID
+		*out = new(string)
+		**out = **in
+	}
 	if in.FailureDomain != nil {
 		in, out := &in.FailureDomain, &out.FailureDomain
 		*out = new(string)
@@ -986,7 +1651,7 @@ func (in *VSphereMachineSpec) DeepCopyInto(out *VSphereMachineSpec) {
 	}
 	if in.GuestSoftPowerOffTimeout != nil {
 		in, out := &in.GuestSoftPowerOffTimeout, &out.GuestSoftPowerOffTimeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
@@ -1195,20 +1860,62 @@ func (in *VSphereVMList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VSphereVMPlacement) DeepCopyInto(out *VSphereVMPlacement) {
+	*out = *in
+	if in.Datastores != nil {
+		in, out := &in.Datastores, &out.Datastores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereVMPlacement.
+func (in *VSphereVMPlacement) DeepCopy() *VSphereVMPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(VSphereVMPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VSphereVMSpec) DeepCopyInto(out *VSphereVMSpec) {
 	*out = *in
 	in.VirtualMachineCloneSpec.DeepCopyInto(&out.VirtualMachineCloneSpec)
 	if in.BootstrapRef != nil {
 		in, out := &in.BootstrapRef, &out.BootstrapRef
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.GuestSoftPowerOffTimeout != nil {
 		in, out := &in.GuestSoftPowerOffTimeout, &out.GuestSoftPowerOffTimeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.SSHAuthorizedKeys != nil {
+		in, out := &in.SSHAuthorizedKeys, &out.SSHAuthorizedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainingDatastores != nil {
+		in, out := &in.DrainingDatastores, &out.DrainingDatastores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereVMSpec.
@@ -1230,6 +1937,16 @@ func (in *VSphereVMStatus) DeepCopyInto(out *VSphereVMStatus) {
 		copy(*out, *in)
 	}
 	in.RetryAfter.DeepCopyInto(&out.RetryAfter)
+	if in.ExcludedDatastores != nil {
+		in, out := &in.ExcludedDatastores, &out.ExcludedDatastores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedHosts != nil {
+		in, out := &in.ExcludedHosts, &out.ExcludedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Network != nil {
 		in, out := &in.Network, &out.Network
 		*out = make([]NetworkStatus, len(*in))
@@ -1237,6 +1954,48 @@ func (in *VSphereVMStatus) DeepCopyInto(out *VSphereVMStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SerialPorts != nil {
+		in, out := &in.SerialPorts, &out.SerialPorts
+		*out = make([]SerialPortStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]VirtualMachineDiskStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Datastores != nil {
+		in, out := &in.Datastores, &out.Datastores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CPUShares != nil {
+		in, out := &in.CPUShares, &out.CPUShares
+		*out = new(ResourceShares)
+		**out = **in
+	}
+	if in.MemoryShares != nil {
+		in, out := &in.MemoryShares, &out.MemoryShares
+		*out = new(ResourceShares)
+		**out = **in
+	}
+	if in.GuestDiskUsage != nil {
+		in, out := &in.GuestDiskUsage, &out.GuestDiskUsage
+		*out = new(GuestDiskUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GuestNetwork != nil {
+		in, out := &in.GuestNetwork, &out.GuestNetwork
+		*out = new(GuestNetworkStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MigrationHistory != nil {
+		in, out := &in.MigrationHistory, &out.MigrationHistory
+		*out = new(MigrationHistory)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -1293,15 +2052,75 @@ func (in *VirtualMachine) DeepCopy() *VirtualMachine {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBootOptions) DeepCopyInto(out *VirtualMachineBootOptions) {
+	*out = *in
+	if in.BootDelay != nil {
+		in, out := &in.BootDelay, &out.BootDelay
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BootRetryEnabled != nil {
+		in, out := &in.BootRetryEnabled, &out.BootRetryEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BootRetryDelay != nil {
+		in, out := &in.BootRetryDelay, &out.BootRetryDelay
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NetworkBoot != nil {
+		in, out := &in.NetworkBoot, &out.NetworkBoot
+		*out = new(VirtualMachineNetworkBoot)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBootOptions.
+func (in *VirtualMachineBootOptions) DeepCopy() *VirtualMachineBootOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBootOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineCloneSpec) DeepCopyInto(out *VirtualMachineCloneSpec) {
 	*out = *in
+	if in.FallbackTemplates != nil {
+		in, out := &in.FallbackTemplates, &out.FallbackTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Network.DeepCopyInto(&out.Network)
 	if in.AdditionalDisksGiB != nil {
 		in, out := &in.AdditionalDisksGiB, &out.AdditionalDisksGiB
 		*out = make([]int32, len(*in))
 		copy(*out, *in)
 	}
+	if in.AdditionalDisksController != nil {
+		in, out := &in.AdditionalDisksController, &out.AdditionalDisksController
+		*out = new(AdditionalDisksControllerSpec)
+		**out = **in
+	}
+	if in.DiskEnableUUID != nil {
+		in, out := &in.DiskEnableUUID, &out.DiskEnableUUID
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DiskStorageIOAllocation != nil {
+		in, out := &in.DiskStorageIOAllocation, &out.DiskStorageIOAllocation
+		*out = new(DiskStorageIOAllocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedDisks != nil {
+		in, out := &in.SharedDisks, &out.SharedDisks
+		*out = make([]SharedDiskSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.CustomVMXKeys != nil {
 		in, out := &in.CustomVMXKeys, &out.CustomVMXKeys
 		*out = make(map[string]string, len(*in))
@@ -1314,6 +2133,13 @@ func (in *VirtualMachineCloneSpec) DeepCopyInto(out *VirtualMachineCloneSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.CustomAttributes != nil {
+		in, out := &in.CustomAttributes, &out.CustomAttributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.PciDevices != nil {
 		in, out := &in.PciDevices, &out.PciDevices
 		*out = make([]PCIDeviceSpec, len(*in))
@@ -1321,6 +2147,136 @@ func (in *VirtualMachineCloneSpec) DeepCopyInto(out *VirtualMachineCloneSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MemoryReservationLockedToMax != nil {
+		in, out := &in.MemoryReservationLockedToMax, &out.MemoryReservationLockedToMax
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResourceAllocation != nil {
+		in, out := &in.ResourceAllocation, &out.ResourceAllocation
+		*out = new(VirtualMachineResourceAllocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootOptions != nil {
+		in, out := &in.BootOptions, &out.BootOptions
+		*out = new(VirtualMachineBootOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NestedHardwareVirtualization != nil {
+		in, out := &in.NestedHardwareVirtualization, &out.NestedHardwareVirtualization
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SecureBoot != nil {
+		in, out := &in.SecureBoot, &out.SecureBoot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VirtualTPM != nil {
+		in, out := &in.VirtualTPM, &out.VirtualTPM
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TrustedLaunch != nil {
+		in, out := &in.TrustedLaunch, &out.TrustedLaunch
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SecureBootFailureTimeout != nil {
+		in, out := &in.SecureBootFailureTimeout, &out.SecureBootFailureTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.OVA != nil {
+		in, out := &in.OVA, &out.OVA
+		*out = new(OVASource)
+		**out = **in
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KernelArgs != nil {
+		in, out := &in.KernelArgs, &out.KernelArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]SecretFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.PerformanceOptions != nil {
+		in, out := &in.PerformanceOptions, &out.PerformanceOptions
+		*out = new(VirtualMachinePerformanceOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolsSyncTimeWithHost != nil {
+		in, out := &in.ToolsSyncTimeWithHost, &out.ToolsSyncTimeWithHost
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SerialPorts != nil {
+		in, out := &in.SerialPorts, &out.SerialPorts
+		*out = make([]SerialPortSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.CDROMs != nil {
+		in, out := &in.CDROMs, &out.CDROMs
+		*out = make([]CDROMSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerOnAfterClone != nil {
+		in, out := &in.PowerOnAfterClone, &out.PowerOnAfterClone
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StorageAffinity != nil {
+		in, out := &in.StorageAffinity, &out.StorageAffinity
+		*out = new(StorageAffinitySpec)
+		**out = **in
+	}
+	if in.LoggingOptions != nil {
+		in, out := &in.LoggingOptions, &out.LoggingOptions
+		*out = new(VirtualMachineLoggingOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryBacking != nil {
+		in, out := &in.MemoryBacking, &out.MemoryBacking
+		*out = new(VirtualMachineMemoryBacking)
+		**out = **in
+	}
+	if in.NUMANodeAffinity != nil {
+		in, out := &in.NUMANodeAffinity, &out.NUMANodeAffinity
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Isolation != nil {
+		in, out := &in.Isolation, &out.Isolation
+		*out = new(VirtualMachineIsolation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OVFEnvironment != nil {
+		in, out := &in.OVFEnvironment, &out.OVFEnvironment
+		*out = new(OVFEnvironmentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(GuestReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxProvisioningTime != nil {
+		in, out := &in.MaxProvisioningTime, &out.MaxProvisioningTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(HostnameSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineCloneSpec.
@@ -1332,3 +2288,193 @@ func (in *VirtualMachineCloneSpec) DeepCopy() *VirtualMachineCloneSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineDiskStatus) DeepCopyInto(out *VirtualMachineDiskStatus) {
+	*out = *in
+	if in.UnitNumber != nil {
+		in, out := &in.UnitNumber, &out.UnitNumber
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineDiskStatus.
+func (in *VirtualMachineDiskStatus) DeepCopy() *VirtualMachineDiskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineDiskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineIsolation) DeepCopyInto(out *VirtualMachineIsolation) {
+	*out = *in
+	if in.CopyPasteDisabled != nil {
+		in, out := &in.CopyPasteDisabled, &out.CopyPasteDisabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeviceConnectDisabled != nil {
+		in, out := &in.DeviceConnectDisabled, &out.DeviceConnectDisabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VMCIRestricted != nil {
+		in, out := &in.VMCIRestricted, &out.VMCIRestricted
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineIsolation.
+func (in *VirtualMachineIsolation) DeepCopy() *VirtualMachineIsolation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineIsolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineLoggingOptions) DeepCopyInto(out *VirtualMachineLoggingOptions) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RotateSize != nil {
+		in, out := &in.RotateSize, &out.RotateSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.KeepOld != nil {
+		in, out := &in.KeepOld, &out.KeepOld
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineLoggingOptions.
+func (in *VirtualMachineLoggingOptions) DeepCopy() *VirtualMachineLoggingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineLoggingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMemoryBacking) DeepCopyInto(out *VirtualMachineMemoryBacking) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMemoryBacking.
+func (in *VirtualMachineMemoryBacking) DeepCopy() *VirtualMachineMemoryBacking {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMemoryBacking)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigration) DeepCopyInto(out *VirtualMachineMigration) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigration.
+func (in *VirtualMachineMigration) DeepCopy() *VirtualMachineMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkBoot) DeepCopyInto(out *VirtualMachineNetworkBoot) {
+	*out = *in
+	if in.IPXESettings != nil {
+		in, out := &in.IPXESettings, &out.IPXESettings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkBoot.
+func (in *VirtualMachineNetworkBoot) DeepCopy() *VirtualMachineNetworkBoot {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkBoot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePerformanceOptions) DeepCopyInto(out *VirtualMachinePerformanceOptions) {
+	*out = *in
+	if in.VirtualCPUPerformanceCountersEnabled != nil {
+		in, out := &in.VirtualCPUPerformanceCountersEnabled, &out.VirtualCPUPerformanceCountersEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExtraConfig != nil {
+		in, out := &in.ExtraConfig, &out.ExtraConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePerformanceOptions.
+func (in *VirtualMachinePerformanceOptions) DeepCopy() *VirtualMachinePerformanceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePerformanceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineResourceAllocation) DeepCopyInto(out *VirtualMachineResourceAllocation) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(ResourceAllocationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(ResourceAllocationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CPUReservationPercent != nil {
+		in, out := &in.CPUReservationPercent, &out.CPUReservationPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineResourceAllocation.
+func (in *VirtualMachineResourceAllocation) DeepCopy() *VirtualMachineResourceAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineResourceAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
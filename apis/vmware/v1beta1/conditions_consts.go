@@ -70,6 +70,11 @@ const (
 	WaitingForBIOSUUIDReason = "WaitingForBIOSUUID"
 )
 
+// VMOperatorConditionPrefix is the prefix of the condition types of a VM Operator
+// VirtualMachine which are mirrored onto the VSphereMachine, e.g. the VirtualMachinePrereqReady
+// condition is mirrored as VMOperatorVirtualMachinePrereqReady.
+const VMOperatorConditionPrefix = "VMOperator"
+
 const (
 	// ProviderServiceAccountsReadyCondition documents the status of provider service accounts
 	// and related Roles, RoleBindings and Secrets are created.
@@ -55,6 +55,7 @@ import (
 	vmwarev1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/vmware/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	vmwarecontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context/vmware"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 )
 
 const (
@@ -134,7 +135,7 @@ func AddServiceDiscoveryControllerToManager(ctx context.Context, controllerManag
 			}),
 		).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), controllerManagerCtx.WatchFilterValue)).
-		Complete(r)
+		Complete(tracing.Reconciler("servicediscovery", r))
 }
 
 type serviceDiscoveryReconciler struct {
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	pkgerrors "github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	clusterutilv1 "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/constants"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	infrautilv1 "sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+// reconcileControlPlaneEndpointAddress ensures that a VSphereCluster configured with
+// .spec.controlPlaneEndpointAddressFromPool has an IPAddressClaim and sets the claimed
+// address as the host of its control plane endpoint. It returns false while the IPAM
+// provider has not allocated an address yet.
+func (r *clusterReconciler) reconcileControlPlaneEndpointAddress(ctx context.Context, clusterCtx *capvcontext.ClusterContext) (bool, error) {
+	vsphereCluster := clusterCtx.VSphereCluster
+	poolRef := vsphereCluster.Spec.ControlPlaneEndpointAddressFromPool
+	if poolRef == nil {
+		conditions.Delete(vsphereCluster, infrav1.IPAddressClaimedCondition)
+		return true, nil
+	}
+
+	claim := &ipamv1.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infrautilv1.ControlPlaneEndpointIPAddressClaimName(vsphereCluster.Name),
+			Namespace: vsphereCluster.Namespace,
+		},
+	}
+	log := ctrl.LoggerFrom(ctx).WithValues("IPAddressClaim", klog.KObj(claim))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	result, err := ctrlutil.CreateOrPatch(ctx, r.Client, claim, func() error {
+		claim.SetOwnerReferences(clusterutilv1.EnsureOwnerRef(
+			claim.OwnerReferences,
+			metav1.OwnerReference{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "VSphereCluster",
+				Name:       vsphereCluster.Name,
+				UID:        vsphereCluster.UID,
+			}))
+
+		ctrlutil.AddFinalizer(claim, infrav1.IPAddressClaimFinalizer)
+
+		if claim.Labels == nil {
+			claim.Labels = make(map[string]string)
+		}
+		claim.Labels[clusterv1.ClusterNameLabel] = clusterCtx.Cluster.Name
+
+		claim.Spec.PoolRef.APIGroup = poolRef.APIGroup
+		claim.Spec.PoolRef.Kind = poolRef.Kind
+		claim.Spec.PoolRef.Name = poolRef.Name
+		return nil
+	})
+	if err != nil {
+		conditions.MarkFalse(vsphereCluster, infrav1.IPAddressClaimedCondition, infrav1.IPAddressClaimNotFoundReason, clusterv1.ConditionSeverityError, err.Error())
+		return false, pkgerrors.Wrap(err, "failed to CreateOrPatch IPAddressClaim")
+	}
+	switch result {
+	case ctrlutil.OperationResultCreated:
+		log.Info("Created IPAddressClaim")
+		conditions.MarkFalse(vsphereCluster, infrav1.IPAddressClaimedCondition, infrav1.IPAddressClaimsBeingCreatedReason, clusterv1.ConditionSeverityInfo, "")
+		return false, nil
+	case ctrlutil.OperationResultUpdated:
+		log.Info("Updated IPAddressClaim")
+	case ctrlutil.OperationResultNone, ctrlutil.OperationResultUpdatedStatus, ctrlutil.OperationResultUpdatedStatusOnly:
+		log.V(3).Info("No change required for IPAddressClaim", "operationResult", result)
+	}
+
+	if claim.Status.AddressRef.Name == "" {
+		conditions.MarkFalse(vsphereCluster, infrav1.IPAddressClaimedCondition, infrav1.WaitingForIPAddressReason, clusterv1.ConditionSeverityInfo,
+			"waiting for an IP address from %s %s", poolRef.Kind, poolRef.Name)
+		return false, nil
+	}
+
+	ipAddress := &ipamv1.IPAddress{}
+	ipAddressKey := client.ObjectKey{
+		Namespace: claim.Namespace,
+		Name:      claim.Status.AddressRef.Name,
+	}
+	if err := r.Client.Get(ctx, ipAddressKey, ipAddress); err != nil {
+		return false, pkgerrors.Wrapf(err, "failed to get IPAddress %s", klog.KRef(ipAddressKey.Namespace, ipAddressKey.Name))
+	}
+	addr, err := netip.ParseAddr(ipAddress.Spec.Address)
+	if err != nil {
+		conditions.MarkFalse(vsphereCluster, infrav1.IPAddressClaimedCondition, infrav1.IPAddressInvalidReason, clusterv1.ConditionSeverityError,
+			"IPAddress %s has an invalid address %q", klog.KObj(ipAddress), ipAddress.Spec.Address)
+		return false, pkgerrors.Wrapf(err, "IPAddress %s has an invalid address %q", klog.KObj(ipAddress), ipAddress.Spec.Address)
+	}
+
+	if vsphereCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		vsphereCluster.Spec.ControlPlaneEndpoint.Host = addr.String()
+		log.Info(fmt.Sprintf("Setting the host of the control plane endpoint to %s", addr))
+	}
+	if vsphereCluster.Spec.ControlPlaneEndpoint.Port == 0 {
+		vsphereCluster.Spec.ControlPlaneEndpoint.Port = constants.DefaultBindPort
+	}
+	conditions.MarkTrue(vsphereCluster, infrav1.IPAddressClaimedCondition)
+
+	return true, nil
+}
+
+// reconcileControlPlaneEndpointAddressDelete removes the finalizer from the IPAddressClaim
+// of the control plane endpoint, thus freeing it up for garbage collection.
+func (r *clusterReconciler) reconcileControlPlaneEndpointAddressDelete(ctx context.Context, clusterCtx *capvcontext.ClusterContext) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	claim := &ipamv1.IPAddressClaim{}
+	claimKey := client.ObjectKey{
+		Namespace: clusterCtx.VSphereCluster.Namespace,
+		Name:      infrautilv1.ControlPlaneEndpointIPAddressClaimName(clusterCtx.VSphereCluster.Name),
+	}
+	if err := r.Client.Get(ctx, claimKey, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerrors.Wrapf(err, "failed to get IPAddressClaim %s to remove the finalizer", klog.KRef(claimKey.Namespace, claimKey.Name))
+	}
+
+	if ctrlutil.RemoveFinalizer(claim, infrav1.IPAddressClaimFinalizer) {
+		log.Info(fmt.Sprintf("Removing finalizer %s", infrav1.IPAddressClaimFinalizer), "IPAddressClaim", klog.KObj(claim))
+		if err := r.Client.Update(ctx, claim); err != nil {
+			return pkgerrors.Wrapf(err, "failed to update IPAddressClaim %s", klog.KObj(claim))
+		}
+	}
+	return nil
+}
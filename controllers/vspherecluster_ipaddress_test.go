@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/context/fake"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+func Test_clusterReconciler_reconcileControlPlaneEndpointAddress(t *testing.T) {
+	name, namespace := "test-cluster", "my-namespace"
+	claimName := util.ControlPlaneEndpointIPAddressClaimName(name)
+	setup := func(initObjects ...client.Object) (*clusterReconciler, *capvcontext.ClusterContext) {
+		controllerManagerCtx := fake.NewControllerManagerContext(initObjects...)
+		clusterCtx := &capvcontext.ClusterContext{
+			Cluster: &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: namespace},
+			},
+			VSphereCluster: &infrav1.VSphereCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec: infrav1.VSphereClusterSpec{
+					ControlPlaneEndpointAddressFromPool: ptr.To(poolRef("my-pool")),
+				},
+			},
+		}
+		return &clusterReconciler{ControllerManagerContext: controllerManagerCtx, Client: controllerManagerCtx.Client}, clusterCtx
+	}
+	ctx := context.Background()
+
+	t.Run("creates the IPAddressClaim", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		r, clusterCtx := setup()
+		ok, err := r.reconcileControlPlaneEndpointAddress(ctx, clusterCtx)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(ok).To(gomega.BeFalse())
+
+		claim := &ipamv1.IPAddressClaim{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: claimName}, claim)).To(gomega.Succeed())
+		g.Expect(ctrlutil.ContainsFinalizer(claim, infrav1.IPAddressClaimFinalizer)).To(gomega.BeTrue())
+		g.Expect(claim.OwnerReferences).To(gomega.HaveLen(1))
+		g.Expect(claim.OwnerReferences[0].Kind).To(gomega.Equal("VSphereCluster"))
+		g.Expect(claim.Labels).To(gomega.HaveKeyWithValue(clusterv1.ClusterNameLabel, "my-cluster"))
+		g.Expect(claim.Spec.PoolRef.Name).To(gomega.Equal("my-pool"))
+
+		claimedCondition := conditions.Get(clusterCtx.VSphereCluster, infrav1.IPAddressClaimedCondition)
+		g.Expect(claimedCondition).NotTo(gomega.BeNil())
+		g.Expect(claimedCondition.Status).To(gomega.Equal(corev1.ConditionFalse))
+		g.Expect(claimedCondition.Reason).To(gomega.Equal(infrav1.IPAddressClaimsBeingCreatedReason))
+		g.Expect(clusterCtx.VSphereCluster.Spec.ControlPlaneEndpoint.IsZero()).To(gomega.BeTrue())
+	})
+
+	t.Run("waits for the IP address", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		r, clusterCtx := setup(&ipamv1.IPAddressClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: claimName, Namespace: namespace},
+			Spec:       ipamv1.IPAddressClaimSpec{PoolRef: poolRef("my-pool")},
+		})
+		ok, err := r.reconcileControlPlaneEndpointAddress(ctx, clusterCtx)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(ok).To(gomega.BeFalse())
+
+		claimedCondition := conditions.Get(clusterCtx.VSphereCluster, infrav1.IPAddressClaimedCondition)
+		g.Expect(claimedCondition).NotTo(gomega.BeNil())
+		g.Expect(claimedCondition.Reason).To(gomega.Equal(infrav1.WaitingForIPAddressReason))
+	})
+
+	t.Run("sets the control plane endpoint from the IP address", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		r, clusterCtx := setup(
+			&ipamv1.IPAddressClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: claimName, Namespace: namespace},
+				Spec:       ipamv1.IPAddressClaimSpec{PoolRef: poolRef("my-pool")},
+				Status:     ipamv1.IPAddressClaimStatus{AddressRef: corev1.LocalObjectReference{Name: claimName}},
+			},
+			&ipamv1.IPAddress{
+				ObjectMeta: metav1.ObjectMeta{Name: claimName, Namespace: namespace},
+				Spec: ipamv1.IPAddressSpec{
+					ClaimRef: corev1.LocalObjectReference{Name: claimName},
+					PoolRef:  poolRef("my-pool"),
+					Address:  "10.0.0.50",
+					Prefix:   24,
+				},
+			},
+		)
+		ok, err := r.reconcileControlPlaneEndpointAddress(ctx, clusterCtx)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(ok).To(gomega.BeTrue())
+		g.Expect(clusterCtx.VSphereCluster.Spec.ControlPlaneEndpoint).To(gomega.Equal(infrav1.APIEndpoint{Host: "10.0.0.50", Port: 6443}))
+		g.Expect(conditions.IsTrue(clusterCtx.VSphereCluster, infrav1.IPAddressClaimedCondition)).To(gomega.BeTrue())
+	})
+
+	t.Run("releases the IPAddressClaim", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		r, clusterCtx := setup(&ipamv1.IPAddressClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       claimName,
+				Namespace:  namespace,
+				Finalizers: []string{infrav1.IPAddressClaimFinalizer},
+			},
+			Spec: ipamv1.IPAddressClaimSpec{PoolRef: poolRef("my-pool")},
+		})
+		g.Expect(r.reconcileControlPlaneEndpointAddressDelete(ctx, clusterCtx)).To(gomega.Succeed())
+
+		claim := &ipamv1.IPAddressClaim{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: claimName}, claim)).To(gomega.Succeed())
+		g.Expect(claim.Finalizers).To(gomega.BeEmpty())
+	})
+}
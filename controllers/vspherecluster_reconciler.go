@@ -147,6 +147,18 @@ func (r *clusterReconciler) reconcileDelete(ctx context.Context, clusterCtx *cap
 		return affinityReconcileResult, err
 	}
 
+	// The resource pool needs to be deleted before the secret deletion
+	// since it needs access to the vCenter instance.
+	if err := r.reconcileResourcePoolDelete(ctx, clusterCtx); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Release the address of the control plane endpoint once all the machines,
+	// which may still be reachable through it, are gone.
+	if err := r.reconcileControlPlaneEndpointAddressDelete(ctx, clusterCtx); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Remove finalizer on Identity Secret
 	if identity.IsSecretIdentity(clusterCtx.VSphereCluster) {
 		secret := &corev1.Secret{}
@@ -214,12 +226,27 @@ func (r *clusterReconciler) reconcileNormal(ctx context.Context, clusterCtx *cap
 		log.Error(err, "could not reconcile vCenter version")
 	}
 
+	if err := r.reconcileResourcePool(ctx, clusterCtx, vcenterSession); err != nil {
+		return reconcile.Result{}, pkgerrors.Wrapf(err,
+			"failed to reconcile resource pool for %s", clusterCtx)
+	}
+
 	affinityReconcileResult, err := r.reconcileClusterModules(ctx, clusterCtx)
 	if err != nil {
 		conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ClusterModulesAvailableCondition, infrav1.ClusterModuleSetupFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return affinityReconcileResult, err
 	}
 
+	ok, err = r.reconcileControlPlaneEndpointAddress(ctx, clusterCtx)
+	if err != nil {
+		return reconcile.Result{}, pkgerrors.Wrapf(err,
+			"failed to reconcile control plane endpoint address for %s", clusterCtx)
+	}
+	if !ok {
+		log.Info("Waiting for the IP address of the control plane endpoint to be allocated")
+		return reconcile.Result{}, nil
+	}
+
 	clusterCtx.VSphereCluster.Status.Ready = true
 
 	return reconcile.Result{}, nil
@@ -287,7 +314,7 @@ func (r *clusterReconciler) reconcileVCenterConnectivity(ctx context.Context, cl
 			return nil, pkgerrors.Wrap(err, "failed to get credentials from IdentityRef")
 		}
 
-		params = params.WithUserInfo(creds.Username, creds.Password)
+		params = params.WithUserInfo(creds.Username, creds.Password).WithCABundle(creds.CABundle)
 		return session.GetOrCreate(ctx, params)
 	}
 
@@ -24,7 +24,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/mo"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,8 +38,10 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-vsphere/internal/test/helpers/vcsim"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/context/fake"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/identity"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
 )
 
 const (
@@ -639,6 +643,85 @@ func TestClusterReconciler_ReconcileDeploymentZones(t *testing.T) {
 	})
 }
 
+func TestClusterReconciler_ReconcileResourcePool(t *testing.T) {
+	simr, err := vcsim.NewBuilder().Build()
+	if err != nil {
+		t.Fatalf("failed to create VC simulator")
+	}
+	t.Cleanup(simr.Destroy)
+
+	newReconciler := func(g *WithT) (*clusterReconciler, *capvcontext.ClusterContext, *session.Session) {
+		controllerManagerContext := fake.NewControllerManagerContext()
+		controllerManagerContext.Username = simr.Username()
+		controllerManagerContext.Password = simr.Password()
+
+		clusterCtx := fake.NewClusterContext(ctx, controllerManagerContext)
+		clusterCtx.VSphereCluster.Spec.Server = simr.ServerURL().Host
+
+		r := &clusterReconciler{
+			ControllerManagerContext: controllerManagerContext,
+			Client:                   controllerManagerContext.Client,
+		}
+		s, err := r.reconcileVCenterConnectivity(ctx, clusterCtx)
+		g.Expect(err).NotTo(HaveOccurred())
+		return r, clusterCtx, s
+	}
+
+	t.Run("without a resource pool", func(t *testing.T) {
+		g := NewWithT(t)
+		r, clusterCtx, s := newReconciler(g)
+
+		g.Expect(r.reconcileResourcePool(ctx, clusterCtx, s)).To(Succeed())
+		g.Expect(clusterCtx.VSphereCluster.Status.ResourcePool).To(BeEmpty())
+		g.Expect(conditions.Has(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition)).To(BeFalse())
+	})
+
+	t.Run("with a parent resource pool which does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		r, clusterCtx, s := newReconciler(g)
+		clusterCtx.VSphereCluster.Spec.ResourcePool = &infrav1.ClusterResourcePoolSpec{
+			Parent: "/DC0/host/DC0_C0/Resources/missing",
+		}
+
+		g.Expect(r.reconcileResourcePool(ctx, clusterCtx, s)).NotTo(Succeed())
+		g.Expect(clusterCtx.VSphereCluster.Status.ResourcePool).To(BeEmpty())
+		g.Expect(conditions.GetReason(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition)).To(Equal(infrav1.ParentResourcePoolNotFoundReason))
+	})
+
+	t.Run("creates, updates and deletes the resource pool", func(t *testing.T) {
+		g := NewWithT(t)
+		r, clusterCtx, s := newReconciler(g)
+		clusterCtx.VSphereCluster.Spec.ResourcePool = &infrav1.ClusterResourcePoolSpec{
+			Parent:            "/DC0/host/DC0_C0/Resources",
+			Name:              "my-cluster",
+			CPUReservationMHz: ptr.To[int64](100),
+		}
+		inventoryPath := "/DC0/host/DC0_C0/Resources/my-cluster"
+
+		getReservation := func() int64 {
+			pool, err := find.NewFinder(s.Client.Client).ResourcePool(ctx, inventoryPath)
+			g.Expect(err).NotTo(HaveOccurred())
+			var obj mo.ResourcePool
+			g.Expect(pool.Properties(ctx, pool.Reference(), []string{"config"}, &obj)).To(Succeed())
+			return ptr.Deref(obj.Config.CpuAllocation.Reservation, 0)
+		}
+
+		g.Expect(r.reconcileResourcePool(ctx, clusterCtx, s)).To(Succeed())
+		g.Expect(clusterCtx.VSphereCluster.Status.ResourcePool).To(Equal(inventoryPath))
+		g.Expect(conditions.IsTrue(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition)).To(BeTrue())
+		g.Expect(getReservation()).To(Equal(int64(100)))
+
+		clusterCtx.VSphereCluster.Spec.ResourcePool.CPUReservationMHz = ptr.To[int64](200)
+		g.Expect(r.reconcileResourcePool(ctx, clusterCtx, s)).To(Succeed())
+		g.Expect(getReservation()).To(Equal(int64(200)))
+
+		g.Expect(r.reconcileResourcePoolDelete(ctx, clusterCtx)).To(Succeed())
+		g.Expect(clusterCtx.VSphereCluster.Status.ResourcePool).To(BeEmpty())
+		_, err := find.NewFinder(s.Client.Client).ResourcePool(ctx, inventoryPath)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
 func deploymentZone(server, fdName string, cp, ready *bool) *infrav1.VSphereDeploymentZone {
 	return &infrav1.VSphereDeploymentZone{
 		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("zone-%s", fdName)},
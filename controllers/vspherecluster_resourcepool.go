@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+// resourcePoolPrivileges are the privileges required on the parent resource pool
+// to manage the resource pool of a cluster.
+var resourcePoolPrivileges = []string{"Resource.CreatePool", "Resource.DeletePool"}
+
+// reconcileResourcePool ensures the resource pool defined in the VSphereCluster spec
+// exists under its parent resource pool and has the desired reservations.
+func (r *clusterReconciler) reconcileResourcePool(ctx context.Context, clusterCtx *capvcontext.ClusterContext, s *session.Session) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	spec := clusterCtx.VSphereCluster.Spec.ResourcePool
+	if spec == nil {
+		conditions.Delete(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition)
+		return nil
+	}
+
+	finder := find.NewFinder(s.Client.Client, false)
+	if spec.Datacenter != "" {
+		dc, err := finder.Datacenter(ctx, spec.Datacenter)
+		if err != nil {
+			conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition, infrav1.ParentResourcePoolNotFoundReason, clusterv1.ConditionSeverityError, "datacenter %s not found", spec.Datacenter)
+			return pkgerrors.Wrapf(err, "unable to find datacenter %s", spec.Datacenter)
+		}
+		finder.SetDatacenter(dc)
+	}
+
+	parent, err := finder.ResourcePool(ctx, spec.Parent)
+	if err != nil {
+		conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition, infrav1.ParentResourcePoolNotFoundReason, clusterv1.ConditionSeverityError, "parent resource pool %s not found", spec.Parent)
+		return pkgerrors.Wrapf(err, "unable to find parent resource pool %s", spec.Parent)
+	}
+
+	if err := checkResourcePoolPrivileges(ctx, s, parent); err != nil {
+		conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition, infrav1.ResourcePoolPrivilegesMissingReason, clusterv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	config := resourcePoolConfigSpec(spec)
+	inventoryPath := path.Join(parent.InventoryPath, resourcePoolName(clusterCtx.VSphereCluster))
+	pool, err := finder.ResourcePool(ctx, inventoryPath)
+	switch {
+	case err == nil:
+		var obj mo.ResourcePool
+		if err := pool.Properties(ctx, pool.Reference(), []string{"config"}, &obj); err != nil {
+			return pkgerrors.Wrapf(err, "unable to get config of resource pool %s", inventoryPath)
+		}
+		if resourcePoolConfigMatches(obj.Config, config) {
+			break
+		}
+		log.Info("Updating resource pool", "resourcePool", inventoryPath)
+		if err := pool.UpdateConfig(ctx, "", &config); err != nil {
+			conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition, infrav1.ResourcePoolCreationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return pkgerrors.Wrapf(err, "unable to update resource pool %s", inventoryPath)
+		}
+	case isFinderNotFound(err):
+		log.Info("Creating resource pool", "resourcePool", inventoryPath)
+		if _, err := parent.Create(ctx, path.Base(inventoryPath), config); err != nil {
+			conditions.MarkFalse(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition, infrav1.ResourcePoolCreationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return pkgerrors.Wrapf(err, "unable to create resource pool %s", inventoryPath)
+		}
+	default:
+		return pkgerrors.Wrapf(err, "unable to find resource pool %s", inventoryPath)
+	}
+
+	clusterCtx.VSphereCluster.Status.ResourcePool = inventoryPath
+	conditions.MarkTrue(clusterCtx.VSphereCluster, infrav1.ResourcePoolReadyCondition)
+	return nil
+}
+
+// reconcileResourcePoolDelete deletes the resource pool created for the cluster.
+func (r *clusterReconciler) reconcileResourcePoolDelete(ctx context.Context, clusterCtx *capvcontext.ClusterContext) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	inventoryPath := clusterCtx.VSphereCluster.Status.ResourcePool
+	if inventoryPath == "" {
+		return nil
+	}
+
+	s, err := r.reconcileVCenterConnectivity(ctx, clusterCtx)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "unexpected error while probing vcenter for %s", clusterCtx)
+	}
+
+	pool, err := find.NewFinder(s.Client.Client, false).ResourcePool(ctx, inventoryPath)
+	if err != nil {
+		if !isFinderNotFound(err) {
+			return pkgerrors.Wrapf(err, "unable to find resource pool %s", inventoryPath)
+		}
+	} else {
+		log.Info("Deleting resource pool", "resourcePool", inventoryPath)
+		task, err := pool.Destroy(ctx)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "unable to delete resource pool %s", inventoryPath)
+		}
+		if err := task.Wait(ctx); err != nil {
+			return pkgerrors.Wrapf(err, "unable to delete resource pool %s", inventoryPath)
+		}
+	}
+
+	clusterCtx.VSphereCluster.Status.ResourcePool = ""
+	return nil
+}
+
+// checkResourcePoolPrivileges returns an error if the principal of the session is not
+// allowed to create and delete resource pools under the given parent resource pool.
+func checkResourcePoolPrivileges(ctx context.Context, s *session.Session, parent *object.ResourcePool) error {
+	userSession, err := s.SessionManager.UserSession(ctx)
+	if err != nil {
+		return pkgerrors.Wrap(err, "unable to get user session")
+	}
+	if userSession == nil {
+		return errors.New("user session not found")
+	}
+
+	granted, err := object.NewAuthorizationManager(s.Client.Client).HasPrivilegeOnEntity(ctx, parent.Reference(), userSession.Key, resourcePoolPrivileges)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "unable to check privileges on resource pool %s", parent.InventoryPath)
+	}
+	for i, ok := range granted {
+		if !ok {
+			return fmt.Errorf("user %s is missing privilege %s on resource pool %s", userSession.UserName, resourcePoolPrivileges[i], parent.InventoryPath)
+		}
+	}
+	return nil
+}
+
+// resourcePoolName returns the name of the resource pool created for the cluster.
+func resourcePoolName(vsphereCluster *infrav1.VSphereCluster) string {
+	if name := vsphereCluster.Spec.ResourcePool.Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", vsphereCluster.Namespace, vsphereCluster.Name)
+}
+
+// resourcePoolConfigSpec returns the config of the resource pool created for the cluster.
+func resourcePoolConfigSpec(spec *infrav1.ClusterResourcePoolSpec) types.ResourceConfigSpec {
+	config := types.DefaultResourceConfigSpec()
+	config.CpuAllocation.Reservation = ptr.To(ptr.Deref(spec.CPUReservationMHz, 0))
+	config.MemoryAllocation.Reservation = ptr.To(ptr.Deref(spec.MemoryReservationMiB, 0))
+	return config
+}
+
+// resourcePoolConfigMatches returns true if the reservations of the current config
+// match the desired ones.
+func resourcePoolConfigMatches(current, desired types.ResourceConfigSpec) bool {
+	return ptr.Deref(current.CpuAllocation.Reservation, 0) == ptr.Deref(desired.CpuAllocation.Reservation, 0) &&
+		ptr.Deref(current.MemoryAllocation.Reservation, 0) == ptr.Deref(desired.MemoryAllocation.Reservation, 0)
+}
+
+func isFinderNotFound(err error) bool {
+	var notFoundErr *find.NotFoundError
+	return errors.As(err, &notFoundErr)
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterutilv1 "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
+)
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vsphereclusterplacements,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vsphereclusterplacements/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vsphereclusters;vspherevms,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machines,verbs=get;list;watch
+
+// AddClusterPlacementControllerToManager adds the VSphereClusterPlacement controller to the
+// provided manager. It maintains a VSphereClusterPlacement for every VSphereCluster from the
+// cached VSphereVMs and Machines of the cluster.
+func AddClusterPlacementControllerToManager(ctx context.Context, controllerManagerCtx *capvcontext.ControllerManagerContext, mgr manager.Manager, options controller.Options) error {
+	reconciler := &clusterPlacementReconciler{
+		ControllerManagerContext: controllerManagerCtx,
+		Client:                   controllerManagerCtx.Client,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("vsphereclusterplacement").
+		For(&infrav1.VSphereCluster{}).
+		WithOptions(options).
+		Owns(&infrav1.VSphereClusterPlacement{}).
+		Watches(
+			&infrav1.VSphereVM{},
+			handler.EnqueueRequestsFromMapFunc(reconciler.vsphereVMToVSphereCluster),
+		).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), controllerManagerCtx.WatchFilterValue)).
+		Complete(tracing.Reconciler("vsphereclusterplacement", reconciler))
+}
+
+type clusterPlacementReconciler struct {
+	*capvcontext.ControllerManagerContext
+	Client client.Client
+}
+
+// Reconcile ensures the VSphereClusterPlacement of the VSphereCluster summarizes the
+// placement and health of the VSphereVMs of the cluster.
+func (r *clusterPlacementReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	vsphereCluster := &infrav1.VSphereCluster{}
+	if err := r.Client.Get(ctx, req.NamespacedName, vsphereCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	// The VSphereClusterPlacement is garbage collected with the VSphereCluster.
+	if !vsphereCluster.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	cluster, err := clusterutilv1.GetOwnerCluster(ctx, r.Client, vsphereCluster.ObjectMeta)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster controller to set OwnerRef on VSphereCluster")
+		return reconcile.Result{}, nil
+	}
+	log = log.WithValues("Cluster", klog.KObj(cluster))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	status, err := r.placementStatus(ctx, cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	placement := &infrav1.VSphereClusterPlacement{}
+	if err := r.Client.Get(ctx, req.NamespacedName, placement); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		placement = &infrav1.VSphereClusterPlacement{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      vsphereCluster.Name,
+				Namespace: vsphereCluster.Namespace,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "VSphereCluster",
+					Name:       vsphereCluster.Name,
+					UID:        vsphereCluster.UID,
+					Controller: ptr.To(true),
+				}},
+			},
+		}
+		if err := r.Client.Create(ctx, placement); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to create VSphereClusterPlacement %s", klog.KObj(placement))
+		}
+		log.Info("Created VSphereClusterPlacement", "VSphereClusterPlacement", klog.KObj(placement))
+	}
+
+	patchHelper, err := patch.NewHelper(placement, r.Client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	placement.Status = status
+	if err := patchHelper.Patch(ctx, placement); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to patch VSphereClusterPlacement %s", klog.KObj(placement))
+	}
+	return reconcile.Result{}, nil
+}
+
+// placementStatus returns the placement and health of the VSphereVMs of the cluster, with
+// the failure domains of their Machines.
+func (r *clusterPlacementReconciler) placementStatus(ctx context.Context, cluster *clusterv1.Cluster) (infrav1.VSphereClusterPlacementStatus, error) {
+	status := infrav1.VSphereClusterPlacementStatus{}
+	clusterLabels := client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}
+
+	vms := &infrav1.VSphereVMList{}
+	if err := r.Client.List(ctx, vms, client.InNamespace(cluster.Namespace), clusterLabels); err != nil {
+		return status, errors.Wrapf(err, "failed to list VSphereVMs of Cluster %s", klog.KObj(cluster))
+	}
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(cluster.Namespace), clusterLabels); err != nil {
+		return status, errors.Wrapf(err, "failed to list Machines of Cluster %s", klog.KObj(cluster))
+	}
+	// The Machines are indexed by the name of their VSphereMachine, which owns the VSphereVM.
+	machinesByInfraName := make(map[string]*clusterv1.Machine, len(machines.Items))
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		machinesByInfraName[machine.Spec.InfrastructureRef.Name] = machine
+	}
+
+	for i := range vms.Items {
+		vm := &vms.Items[i]
+		vmPlacement := infrav1.VSphereVMPlacement{
+			Name:       vm.Name,
+			Host:       vm.Status.Host,
+			Datastores: vm.Status.Datastores,
+			Ready:      vm.Status.Ready,
+		}
+		if !vm.Status.Ready {
+			vmPlacement.Reason = conditions.GetReason(vm, clusterv1.ReadyCondition)
+		}
+		for _, ref := range vm.OwnerReferences {
+			if ref.Kind != "VSphereMachine" {
+				continue
+			}
+			if machine, ok := machinesByInfraName[ref.Name]; ok {
+				vmPlacement.Machine = machine.Name
+				if machine.Spec.FailureDomain != nil {
+					vmPlacement.FailureDomain = *machine.Spec.FailureDomain
+				}
+			}
+		}
+		status.Machines = append(status.Machines, vmPlacement)
+		status.Replicas++
+		if vm.Status.Ready {
+			status.ReadyReplicas++
+		}
+	}
+	sort.Slice(status.Machines, func(i, j int) bool {
+		return status.Machines[i].Name < status.Machines[j].Name
+	})
+	return status, nil
+}
+
+// vsphereVMToVSphereCluster maps a VSphereVM to the VSphereCluster of its cluster.
+func (r *clusterPlacementReconciler) vsphereVMToVSphereCluster(ctx context.Context, o client.Object) []reconcile.Request {
+	clusterName, ok := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: o.GetNamespace(), Name: clusterName}, cluster); err != nil {
+		return nil
+	}
+	infraRef := cluster.Spec.InfrastructureRef
+	if infraRef == nil || infraRef.Kind != "VSphereCluster" || infraRef.GroupVersionKind().Group != infrav1.GroupVersion.Group {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: cluster.Namespace, Name: infraRef.Name}}}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/context/fake"
+)
+
+func Test_clusterPlacementReconciler_Reconcile(t *testing.T) {
+	namespace := "my-namespace"
+	clusterLabels := map[string]string{clusterv1.ClusterNameLabel: "my-cluster"}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: namespace},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "VSphereCluster",
+				Name:       "my-vsphere-cluster",
+			},
+		},
+	}
+	vsphereCluster := &infrav1.VSphereCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-vsphere-cluster",
+			Namespace: namespace,
+			UID:       "vsphere-cluster-uid",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       "my-cluster",
+			}},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-0", Namespace: namespace, Labels: clusterLabels},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "my-cluster",
+			InfrastructureRef: corev1.ObjectReference{Kind: "VSphereMachine", Name: "vsphere-machine-0"},
+			FailureDomain:     ptr.To("zone-a"),
+		},
+	}
+	readyVM := &infrav1.VSphereVM{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "vm-0",
+			Namespace:       namespace,
+			Labels:          clusterLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "VSphereMachine", Name: "vsphere-machine-0"}},
+		},
+		Status: infrav1.VSphereVMStatus{
+			Host:       "esxi-01",
+			Datastores: []string{"ds-1"},
+			Ready:      true,
+		},
+	}
+	provisioningVM := &infrav1.VSphereVM{
+		ObjectMeta: metav1.ObjectMeta{Name: "vm-1", Namespace: namespace, Labels: clusterLabels},
+	}
+	conditions.MarkFalse(provisioningVM, clusterv1.ReadyCondition, infrav1.CloningReason, clusterv1.ConditionSeverityInfo, "")
+	otherClusterVM := &infrav1.VSphereVM{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm-2",
+			Namespace: namespace,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "other-cluster"},
+		},
+	}
+
+	g := gomega.NewWithT(t)
+	ctx := context.Background()
+	controllerManagerCtx := fake.NewControllerManagerContext(cluster, vsphereCluster, machine, readyVM, provisioningVM, otherClusterVM)
+	r := &clusterPlacementReconciler{ControllerManagerContext: controllerManagerCtx, Client: controllerManagerCtx.Client}
+
+	key := client.ObjectKeyFromObject(vsphereCluster)
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+
+	placement := &infrav1.VSphereClusterPlacement{}
+	g.Expect(r.Client.Get(ctx, key, placement)).To(gomega.Succeed())
+	g.Expect(placement.OwnerReferences).To(gomega.HaveLen(1))
+	g.Expect(placement.OwnerReferences[0].Kind).To(gomega.Equal("VSphereCluster"))
+	g.Expect(placement.Labels).To(gomega.HaveKeyWithValue(clusterv1.ClusterNameLabel, "my-cluster"))
+	g.Expect(placement.Status).To(gomega.Equal(infrav1.VSphereClusterPlacementStatus{
+		Replicas:      2,
+		ReadyReplicas: 1,
+		Machines: []infrav1.VSphereVMPlacement{
+			{
+				Name:          "vm-0",
+				Machine:       "machine-0",
+				FailureDomain: "zone-a",
+				Host:          "esxi-01",
+				Datastores:    []string{"ds-1"},
+				Ready:         true,
+			},
+			{
+				Name:   "vm-1",
+				Reason: infrav1.CloningReason,
+			},
+		},
+	}))
+
+	g.Expect(r.vsphereVMToVSphereCluster(ctx, readyVM)).To(gomega.Equal([]ctrl.Request{{NamespacedName: key}}))
+	g.Expect(r.vsphereVMToVSphereCluster(ctx, otherClusterVM)).To(gomega.BeEmpty())
+}
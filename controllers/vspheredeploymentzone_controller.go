@@ -47,6 +47,7 @@ import (
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/identity"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
@@ -76,7 +77,7 @@ func AddVSphereDeploymentZoneControllerToManager(ctx context.Context, controller
 			&handler.EnqueueRequestForObject{},
 		).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), controllerManagerCtx.WatchFilterValue)).
-		Complete(reconciler)
+		Complete(tracing.Reconciler("vspheredeploymentzone", reconciler))
 }
 
 type vsphereDeploymentZoneReconciler struct {
@@ -219,7 +220,7 @@ func (r vsphereDeploymentZoneReconciler) getVCenterSession(ctx context.Context,
 			continue
 		}
 		log.V(4).Info("Using credentials from VSphereCluster IdentityRef to create the authenticated session")
-		params = params.WithUserInfo(creds.Username, creds.Password)
+		params = params.WithUserInfo(creds.Username, creds.Password).WithCABundle(creds.CABundle)
 		return session.GetOrCreate(ctx, params)
 	}
 
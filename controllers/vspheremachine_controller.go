@@ -31,6 +31,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterutilv1 "sigs.k8s.io/cluster-api/util"
@@ -59,6 +60,7 @@ import (
 	inframanager "sigs.k8s.io/cluster-api-provider-vsphere/pkg/manager"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/vmoperator"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
@@ -68,6 +70,7 @@ const (
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vspheremachineclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=vspheremachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=vspheremachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=vspheremachinetemplates,verbs=get;list;watch;create;update;patch;delete
@@ -125,7 +128,7 @@ func AddMachineControllerToManager(ctx context.Context, controllerManagerContext
 			WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), controllerManagerContext.WatchFilterValue)).
 			// Watch any VirtualMachine resources owned by this VSphereMachine
 			Owns(&vmoprv1.VirtualMachine{}).
-			Complete(r)
+			Complete(tracing.Reconciler("vspheremachine", r))
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -165,7 +168,11 @@ func AddMachineControllerToManager(ctx context.Context, controllerManagerContext
 			ctrlbldr.WithPredicates(
 				predicates.ClusterUnpausedAndInfrastructureReady(ctrl.LoggerFrom(ctx)),
 			),
-		).Complete(r)
+		).
+		Watches(
+			&infrav1.VSphereCluster{},
+			handler.Funcs{UpdateFunc: r.drainingHostsChanged},
+		).Complete(tracing.Reconciler("vspheremachine", r))
 }
 
 type machineReconciler struct {
@@ -385,6 +392,8 @@ func (r *machineReconciler) reconcileNormal(ctx context.Context, machineCtx capv
 // patchMachineLabelsWithHostInfo adds the ESXi host information as a label to the Machine object.
 // The ESXi host information is added with the CAPI node label prefix
 // which would be added onto the node by the CAPI controllers.
+// Worker Machines on a host listed by the DrainingHostsAnnotation of the VSphereCluster also
+// get the delete-machine annotation, so they are deleted first on scale down.
 func (r *machineReconciler) patchMachineLabelsWithHostInfo(ctx context.Context, machineCtx capvcontext.MachineContext) error {
 	hostInfo, err := r.VMService.GetHostInfo(ctx, machineCtx)
 	if err != nil {
@@ -407,6 +416,13 @@ func (r *machineReconciler) patchMachineLabelsWithHostInfo(ctx context.Context,
 	labels[constants.ESXiHostInfoLabel] = info
 	machine.Labels = labels
 
+	if vimMachineCtx, ok := machineCtx.(*capvcontext.VIMMachineContext); ok && vimMachineCtx.VSphereCluster != nil {
+		if util.SetDrainingHostDeleteAnnotation(machine, vimMachineCtx.VSphereCluster, hostInfo) {
+			_, deleteMachine := machine.Annotations[clusterv1.DeleteMachineAnnotation]
+			ctrl.LoggerFrom(ctx).Info("Updated delete-machine annotation of Machine for draining hosts", "host", hostInfo, "deleteMachine", deleteMachine)
+		}
+	}
+
 	return patchHelper.Patch(ctx, machine)
 }
 
@@ -434,6 +450,27 @@ func (r *machineReconciler) setVMModifiers(ctx context.Context, machineCtx capvc
 
 // enqueueClusterToMachineRequests returns a list of VSphereMachine reconcile requests
 // belonging to the cluster.
+// drainingHostsChanged requeues all VSphereMachines of a VSphereCluster when the value of
+// its DrainingHostsAnnotation changed, to update the delete-machine annotation of their Machines.
+func (r *machineReconciler) drainingHostsChanged(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newValue := e.ObjectNew.GetAnnotations()[infrav1.DrainingHostsAnnotation]
+	if newValue == e.ObjectOld.GetAnnotations()[infrav1.DrainingHostsAnnotation] {
+		return
+	}
+	clusterName, ok := e.ObjectNew.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return
+	}
+	machines, err := r.VMService.GetMachinesInCluster(ctx, e.ObjectNew.GetNamespace(), clusterName)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list VSphereMachines of VSphereCluster", "VSphereCluster", klog.KObj(e.ObjectNew))
+		return
+	}
+	for _, m := range machines {
+		q.Add(reconcile.Request{NamespacedName: apitypes.NamespacedName{Name: m.GetName(), Namespace: m.GetNamespace()}})
+	}
+}
+
 func (r *machineReconciler) enqueueClusterToMachineRequests(ctx context.Context, a client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 	machines, err := r.VMService.GetMachinesInCluster(ctx, a.GetNamespace(), a.GetName())
@@ -19,9 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +31,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -60,6 +63,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
@@ -125,11 +129,15 @@ func AddVMControllerToManager(ctx context.Context, controllerManagerCtx *capvcon
 					GenericFunc: func(event.GenericEvent) bool { return false },
 				}),
 		).
+		Watches(
+			&infrav1.VSphereCluster{},
+			handler.Funcs{UpdateFunc: r.refreshVSphereVMs},
+		).
 		Watches(
 			&ipamv1.IPAddressClaim{},
 			handler.EnqueueRequestsFromMapFunc(r.ipAddressClaimToVSphereVM),
 		).
-		Complete(r)
+		Complete(tracing.Reconciler("vspherevm", r))
 }
 
 type vmReconciler struct {
@@ -244,6 +252,7 @@ func (r vmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.R
 		ControllerManagerContext: r.ControllerManagerContext,
 		VSphereVM:                vsphereVM,
 		VSphereFailureDomain:     vsphereFailureDomain,
+		MachineName:              machine.Name,
 		Session:                  authSession,
 		PatchHelper:              patchHelper,
 	}
@@ -252,6 +261,10 @@ func (r vmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.R
 	log.V(4).Info("VSphereVM.Status.TaskRef OnEntry", "taskRef", vmContext.VSphereVM.Status.TaskRef)
 	defer func() {
 		log.V(4).Info("VSphereVM.Status.TaskRef OnExit", "taskRef", vmContext.VSphereVM.Status.TaskRef)
+		trace.SpanFromContext(ctx).SetAttributes(
+			tracing.VMRefKey.String(vmContext.VSphereVM.Status.VMRef),
+			tracing.TaskRefKey.String(vmContext.VSphereVM.Status.TaskRef),
+		)
 	}()
 	originalTaskRef := vmContext.VSphereVM.Status.TaskRef
 
@@ -324,16 +337,39 @@ func (r vmReconciler) reconcile(ctx context.Context, vmCtx *capvcontext.VMContex
 
 	// Handle deleted machines
 	if !vmCtx.VSphereVM.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, vmCtx)
+		return r.reconcileDelete(ctx, vmCtx, input.Machine)
 	}
 
 	// Handle non-deleted machines
-	return r.reconcileNormal(ctx, vmCtx)
+	result, err := r.reconcileNormal(ctx, vmCtx)
+	// Requeue once the VSphereVM exceeds its maximum provisioning time, as no event signals it.
+	if remaining := r.reconcileProvisioningTime(ctx, vmCtx); remaining > 0 && (result.RequeueAfter == 0 || remaining < result.RequeueAfter) {
+		result.RequeueAfter = remaining
+	}
+	// Taint the node while the host of the VM enters or is in maintenance mode.
+	retryAfter, taintErr := r.reconcileHostMaintenanceTaint(ctx, vmCtx, input.Machine)
+	if retryAfter > 0 && (result.RequeueAfter == 0 || retryAfter < result.RequeueAfter) {
+		result.RequeueAfter = retryAfter
+	}
+	// Cordon the node while the CPUs or memory of the VM are reconfigured or hot-added.
+	retryAfter, cordonErr := r.reconcileNodeCapacityRefresh(ctx, vmCtx, input.Machine)
+	if retryAfter > 0 && (result.RequeueAfter == 0 || retryAfter < result.RequeueAfter) {
+		result.RequeueAfter = retryAfter
+	}
+	return result, kerrors.NewAggregate([]error{err, taintErr, cordonErr})
 }
 
-func (r vmReconciler) reconcileDelete(ctx context.Context, vmCtx *capvcontext.VMContext) (reconcile.Result, error) {
+func (r vmReconciler) reconcileDelete(ctx context.Context, vmCtx *capvcontext.VMContext, machine *clusterv1.Machine) (reconcile.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	// Delay the power off and deletion of the VM until the pre-terminate hooks of the Machine
+	// are cleared, e.g. once the etcd member of a control plane VM is removed.
+	if machine != nil && annotations.HasWithPrefix(clusterv1.PreTerminateDeleteHookAnnotationPrefix, machine.GetAnnotations()) {
+		log.Info("Waiting for the pre-terminate hooks of the Machine to be cleared before deleting the VM")
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForPreTerminateHookReason, clusterv1.ConditionSeverityInfo, "")
+		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
 	conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "")
 	result, vm, err := r.VMService.DestroyVM(ctx, vmCtx)
 	if err != nil {
@@ -422,7 +458,44 @@ func (r vmReconciler) reconcileNormal(ctx context.Context, vmCtx *capvcontext.VM
 	}
 
 	// Get or create the VM.
+	datastoreFullMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.DatastoreFullReason)
+	hostUnavailableMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostUnavailableReason)
+	reconfiguringMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ReconfiguringReason)
+	movingToFolderMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.MovingToFolderReason)
+	renamingMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenamingReason)
+	renameFailedMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenameFailedReason)
+	noGuestHeartbeatMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.NoGuestHeartbeatReason)
+	disablingSecureBootMessage := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.DisablingSecureBootReason)
+	wasRelocating := conditions.GetReason(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition) == infrav1.RelocatingReason
 	vm, err := r.VMService.ReconcileVM(ctx, vmCtx)
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.DatastoreFullReason); message != "" && message != datastoreFullMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeWarning, infrav1.DatastoreFullReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostUnavailableReason); message != "" && message != hostUnavailableMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeWarning, infrav1.HostUnavailableReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ReconfiguringReason); message != "" && message != reconfiguringMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeNormal, infrav1.ReconfiguringReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.MovingToFolderReason); message != "" && message != movingToFolderMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeNormal, infrav1.MovingToFolderReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenamingReason); message != "" && message != renamingMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeNormal, infrav1.RenamingReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenameFailedReason); message != "" && message != renameFailedMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeWarning, infrav1.RenameFailedReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.NoGuestHeartbeatReason); message != "" && message != noGuestHeartbeatMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeWarning, infrav1.NoGuestHeartbeatReason, message)
+	}
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.DisablingSecureBootReason); message != "" && message != disablingSecureBootMessage {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeWarning, infrav1.DisablingSecureBootReason, message)
+	}
+	// The message of a relocation reports its progress, so only its start is recorded.
+	if message := conditionMessageWithReason(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocatingReason); message != "" && !wasRelocating {
+		r.Recorder.Event(vmCtx.VSphereVM, corev1.EventTypeNormal, infrav1.RelocatingReason, message)
+	}
 	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "failed to reconcile VM")
 	}
@@ -430,6 +503,13 @@ func (r vmReconciler) reconcileNormal(ctx context.Context, vmCtx *capvcontext.VM
 	// Do not proceed until the backend VM is marked ready.
 	if vm.State != infrav1.VirtualMachineStateReady {
 		log.Info(fmt.Sprintf("VM state is %q, waiting for %q", vm.State, infrav1.VirtualMachineStateReady))
+		// No task signals the guest becoming ready or shutting down, or the clones of higher
+		// priority starting, so these are polled.
+		if conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition) == infrav1.WaitingForReadinessProbeReason ||
+			conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition) == infrav1.WaitingForProvisioningPriorityReason ||
+			conditions.GetReason(vmCtx.VSphereVM, infrav1.GuestSoftPowerOffSucceededCondition) == infrav1.GuestSoftPowerOffInProgressReason {
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
 		return reconcile.Result{}, nil
 	}
 
@@ -454,16 +534,62 @@ func (r vmReconciler) reconcileNormal(ctx context.Context, vmCtx *capvcontext.VM
 	r.reconcileNetwork(vmCtx, vm)
 
 	// we didn't get any addresses, requeue
+	var result reconcile.Result
 	if len(vmCtx.VSphereVM.Status.Addresses) == 0 {
-		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForIPAllocationReason, clusterv1.ConditionSeverityInfo, "")
-		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		// A VM which is kept powered off does not report addresses.
+		if vmCtx.VSphereVM.Spec.GuestIPWaitPolicy != infrav1.GuestIPWaitPolicySkip && !util.IsKeptPoweredOff(vmCtx.VSphereVM) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForIPAllocationReason, clusterv1.ConditionSeverityInfo, "")
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		// The VM is considered ready without addresses, but keep requeueing
+		// to report them once the guest network is online.
+		log.Info("VM has no IP addresses yet, skip waiting for them")
+		result.RequeueAfter = 10 * time.Second
+	}
+
+	// Poll the storage policy compliance, as no event signals a change of it.
+	if interval := r.StorageComplianceCheckInterval; interval > 0 && vmCtx.VSphereVM.Spec.StoragePolicyName != "" {
+		if result.RequeueAfter == 0 || interval < result.RequeueAfter {
+			result.RequeueAfter = interval
+		}
+	}
+
+	// Poll the usage of the guest filesystems, as no event signals a change of it.
+	if interval := r.GuestDiskUsageRefreshInterval; interval > 0 {
+		if result.RequeueAfter == 0 || interval < result.RequeueAfter {
+			result.RequeueAfter = interval
+		}
+	}
+
+	// Poll the maintenance mode of the host, as no event of the VM signals a change of it.
+	if interval := r.HostMaintenanceCheckInterval; interval > 0 {
+		if result.RequeueAfter == 0 || interval < result.RequeueAfter {
+			result.RequeueAfter = interval
+		}
+	}
+
+	// Poll the events of vCenter for migrations, as a migration does not always change the VM
+	// in a way which triggers a reconcile.
+	if interval := r.MigrationHistoryRefreshInterval; interval > 0 {
+		if result.RequeueAfter == 0 || interval < result.RequeueAfter {
+			result.RequeueAfter = interval
+		}
 	}
 
 	// Once the network is online the VM is considered ready.
 	vmCtx.VSphereVM.Status.Ready = true
 	conditions.MarkTrue(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)
 	log.Info("VSphereVM is ready")
-	return reconcile.Result{}, nil
+	return result, nil
+}
+
+// conditionMessageWithReason returns the message of the given condition if it has the given
+// reason, otherwise an empty string.
+func conditionMessageWithReason(vsphereVM *infrav1.VSphereVM, conditionType clusterv1.ConditionType, reason string) string {
+	if conditions.GetReason(vsphereVM, conditionType) != reason {
+		return ""
+	}
+	return conditions.GetMessage(vsphereVM, conditionType)
 }
 
 // isWaitingForStaticIPAllocation checks whether the VM should wait for a static IP
@@ -494,13 +620,45 @@ func (r vmReconciler) isWaitingForStaticIPAllocation(vmCtx *capvcontext.VMContex
 
 func (r vmReconciler) reconcileNetwork(vmCtx *capvcontext.VMContext, vm infrav1.VirtualMachine) {
 	vmCtx.VSphereVM.Status.Network = vm.Network
+	primary := primaryNetworkStatusIndex(vmCtx.VSphereVM.Spec.Network.Devices, vm.Network)
 	ipAddrs := make([]string, 0, len(vm.Network))
-	for _, netStatus := range vmCtx.VSphereVM.Status.Network {
+	if primary >= 0 {
+		ipAddrs = append(ipAddrs, vm.Network[primary].IPAddrs...)
+	}
+	for i, netStatus := range vmCtx.VSphereVM.Status.Network {
+		if i == primary {
+			continue
+		}
 		ipAddrs = append(ipAddrs, netStatus.IPAddrs...)
 	}
 	vmCtx.VSphereVM.Status.Addresses = ipAddrs
 }
 
+// primaryNetworkStatusIndex returns the index of the network status of the device
+// marked as primary, or -1 if no device is marked as primary.
+// The status is matched by MAC address if the device defines one, otherwise by the
+// position of the device, as the network devices are added to the VM in order.
+func primaryNetworkStatusIndex(devices []infrav1.NetworkDeviceSpec, network []infrav1.NetworkStatus) int {
+	for i, device := range devices {
+		if !device.Primary {
+			continue
+		}
+		if device.MACAddr != "" {
+			for j, netStatus := range network {
+				if strings.EqualFold(netStatus.MACAddr, device.MACAddr) {
+					return j
+				}
+			}
+			return -1
+		}
+		if i < len(network) {
+			return i
+		}
+		return -1
+	}
+	return -1
+}
+
 func (r vmReconciler) clusterToVSphereVMs(ctx context.Context, a ctrlclient.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 	vms := &infrav1.VSphereVMList{}
@@ -556,6 +714,21 @@ func (r vmReconciler) vsphereClusterToVSphereVMs(ctx context.Context, a ctrlclie
 	return requests
 }
 
+// refreshVSphereVMs requeues all VSphereVMs of a VSphereCluster when the value of its
+// RefreshVMsAnnotation changed. The requests are added rate limited, so refreshing the VMs
+// of large clusters does not overwhelm vCenter.
+func (r vmReconciler) refreshVSphereVMs(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newValue, ok := e.ObjectNew.GetAnnotations()[infrav1.RefreshVMsAnnotation]
+	if !ok || newValue == e.ObjectOld.GetAnnotations()[infrav1.RefreshVMsAnnotation] {
+		return
+	}
+	requests := r.vsphereClusterToVSphereVMs(ctx, e.ObjectNew)
+	ctrl.LoggerFrom(ctx).Info("Refreshing VSphereVMs of VSphereCluster", "VSphereCluster", klog.KObj(e.ObjectNew), "count", len(requests))
+	for _, req := range requests {
+		q.AddRateLimited(req)
+	}
+}
+
 func (r vmReconciler) ipAddressClaimToVSphereVM(_ context.Context, a ctrlclient.Object) []reconcile.Request {
 	ipAddressClaim, ok := a.(*ipamv1.IPAddressClaim)
 	if !ok {
@@ -617,7 +790,7 @@ func (r vmReconciler) retrieveVcenterSession(ctx context.Context, vsphereVM *inf
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get credentials from IdentityRef")
 		}
-		params = params.WithUserInfo(creds.Username, creds.Password)
+		params = params.WithUserInfo(creds.Username, creds.Password).WithCABundle(creds.CABundle)
 		return session.GetOrCreate(ctx, params)
 	}
 
@@ -29,6 +29,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apirecord "k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
@@ -37,6 +39,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
@@ -270,6 +273,71 @@ func TestReconcileNormal_WaitingForIPAddrAllocation(t *testing.T) {
 		g.Expect(vmProvisionCondition.Reason).To(Equal(infrav1.WaitingForIPAllocationReason))
 	})
 
+	t.Run("Skip waiting for IP addr allocation", func(t *testing.T) {
+		create(infrav1.NetworkSpec{
+			Devices: []infrav1.NetworkDeviceSpec{
+				{NetworkName: "nw-1", DHCP4: true},
+			},
+		})()
+		vsphereVM.Spec.GuestIPWaitPolicy = infrav1.GuestIPWaitPolicySkip
+		fakeVMSvc := new(fake_svc.VMService)
+		fakeVMSvc.On("ReconcileVM", mock.Anything).Return(infrav1.VirtualMachine{
+			Name:     vsphereVM.Name,
+			BiosUUID: "265104de-1472-547c-b873-6dc7883fb6cb",
+			State:    infrav1.VirtualMachineStateReady,
+			Network: []infrav1.NetworkStatus{{
+				Connected:   true,
+				IPAddrs:     []string{}, // empty array to show the guest has no IP address yet
+				MACAddr:     "blah-mac",
+				NetworkName: vsphereVM.Spec.Network.Devices[0].NetworkName,
+			}},
+		}, nil)
+		r := setupReconciler(fakeVMSvc)
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: util.ObjectKey(vsphereVM)})
+		g := NewWithT(t)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).NotTo(BeZero())
+
+		vm := &infrav1.VSphereVM{}
+		vmKey := util.ObjectKey(vsphereVM)
+		g.Expect(r.Client.Get(context.Background(), vmKey, vm)).NotTo(HaveOccurred())
+
+		g.Expect(vm.Status.Ready).To(BeTrue())
+		g.Expect(conditions.IsTrue(vm, infrav1.VMProvisionedCondition)).To(BeTrue())
+	})
+
+	t.Run("Skip waiting for IP addr allocation when the VM is left powered off", func(t *testing.T) {
+		create(infrav1.NetworkSpec{
+			Devices: []infrav1.NetworkDeviceSpec{
+				{NetworkName: "nw-1", DHCP4: true},
+			},
+		})()
+		vsphereVM.Spec.PowerOnAfterClone = ptr.To(false)
+		fakeVMSvc := new(fake_svc.VMService)
+		fakeVMSvc.On("ReconcileVM", mock.Anything).Return(infrav1.VirtualMachine{
+			Name:     vsphereVM.Name,
+			BiosUUID: "265104de-1472-547c-b873-6dc7883fb6cb",
+			State:    infrav1.VirtualMachineStateReady,
+			Network: []infrav1.NetworkStatus{{
+				Connected:   false,
+				IPAddrs:     []string{}, // empty array as the powered off VM has no IP address
+				MACAddr:     "blah-mac",
+				NetworkName: vsphereVM.Spec.Network.Devices[0].NetworkName,
+			}},
+		}, nil)
+		r := setupReconciler(fakeVMSvc)
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: util.ObjectKey(vsphereVM)})
+		g := NewWithT(t)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		vm := &infrav1.VSphereVM{}
+		vmKey := util.ObjectKey(vsphereVM)
+		g.Expect(r.Client.Get(context.Background(), vmKey, vm)).NotTo(HaveOccurred())
+
+		g.Expect(vm.Status.Ready).To(BeTrue())
+		g.Expect(conditions.IsTrue(vm, infrav1.VMProvisionedCondition)).To(BeTrue())
+	})
+
 	t.Run("Deleting a VM with IPAddressClaims", func(t *testing.T) {
 		create(infrav1.NetworkSpec{
 			Devices: []infrav1.NetworkDeviceSpec{
@@ -697,9 +765,101 @@ func Test_reconcile(t *testing.T) {
 			// Assertion to verify that cluster module info is not mandatory
 			g.Expect(err).NotTo(HaveOccurred())
 		})
+
+		t.Run("when the Machine has a pre-terminate hook", func(t *testing.T) {
+			hookedMachine := machine.DeepCopy()
+			hookedMachine.Annotations = map[string]string{
+				clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/remove-etcd-member": "etcd-member-removal",
+			}
+			hookedVM := deletedVM.DeepCopy()
+
+			hookVMSvc := new(fake_svc.VMService)
+			r := setupReconciler(hookVMSvc, vsphereCluster, hookedMachine, hookedVM)
+			result, err := r.reconcile(ctx, &capvcontext.VMContext{
+				ControllerManagerContext: r.ControllerManagerContext,
+				VSphereVM:                hookedVM,
+			}, fetchClusterModuleInput{
+				VSphereCluster: vsphereCluster,
+				Machine:        hookedMachine,
+			})
+
+			g := NewWithT(t)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).NotTo(BeZero())
+			hookVMSvc.AssertNotCalled(t, "DestroyVM", mock.Anything)
+			g.Expect(conditions.GetReason(hookedVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.WaitingForPreTerminateHookReason))
+			g.Expect(hookedVM.Finalizers).To(ContainElement(infrav1.VMFinalizer))
+
+			// The VM is destroyed once the hook is cleared.
+			hookedMachine.Annotations = nil
+			hookVMSvc.On("DestroyVM", mock.Anything).Return(reconcile.Result{}, infrav1.VirtualMachine{
+				Name:  hookedVM.Name,
+				State: infrav1.VirtualMachineStateNotFound,
+			}, nil)
+			_, err = r.reconcile(ctx, &capvcontext.VMContext{
+				ControllerManagerContext: r.ControllerManagerContext,
+				VSphereVM:                hookedVM,
+			}, fetchClusterModuleInput{
+				VSphereCluster: vsphereCluster,
+				Machine:        hookedMachine,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			hookVMSvc.AssertCalled(t, "DestroyVM", mock.Anything)
+		})
 	})
 }
 
+func Test_reconcileNetwork(t *testing.T) {
+	network := []infrav1.NetworkStatus{
+		{MACAddr: "00:50:56:00:00:01", IPAddrs: []string{"192.168.1.10"}},
+		{MACAddr: "00:50:56:00:00:02", IPAddrs: []string{"10.0.0.10", "fd00::10"}},
+	}
+
+	tests := []struct {
+		name      string
+		devices   []infrav1.NetworkDeviceSpec
+		addresses []string
+	}{
+		{
+			name:      "without primary device",
+			devices:   []infrav1.NetworkDeviceSpec{{NetworkName: "nw-1"}, {NetworkName: "nw-2"}},
+			addresses: []string{"192.168.1.10", "10.0.0.10", "fd00::10"},
+		},
+		{
+			name:      "with primary device",
+			devices:   []infrav1.NetworkDeviceSpec{{NetworkName: "nw-1"}, {NetworkName: "nw-2", Primary: true}},
+			addresses: []string{"10.0.0.10", "fd00::10", "192.168.1.10"},
+		},
+		{
+			name:      "with primary device matched by MAC address",
+			devices:   []infrav1.NetworkDeviceSpec{{NetworkName: "nw-2", MACAddr: "00:50:56:00:00:02", Primary: true}},
+			addresses: []string{"10.0.0.10", "fd00::10", "192.168.1.10"},
+		},
+		{
+			name:      "with primary device without status",
+			devices:   []infrav1.NetworkDeviceSpec{{NetworkName: "nw-1"}, {NetworkName: "nw-2"}, {NetworkName: "nw-3", Primary: true}},
+			addresses: []string{"192.168.1.10", "10.0.0.10", "fd00::10"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			vmCtx := &capvcontext.VMContext{
+				VSphereVM: &infrav1.VSphereVM{
+					Spec: infrav1.VSphereVMSpec{
+						VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+							Network: infrav1.NetworkSpec{Devices: tt.devices},
+						},
+					},
+				},
+			}
+			vmReconciler{}.reconcileNetwork(vmCtx, infrav1.VirtualMachine{Network: network})
+			g.Expect(vmCtx.VSphereVM.Status.Network).To(Equal(network))
+			g.Expect(vmCtx.VSphereVM.Status.Addresses).To(Equal(tt.addresses))
+		})
+	}
+}
+
 func createMachineOwnerHierarchy(machine *clusterv1.Machine) []client.Object {
 	machine.OwnerReferences = []metav1.OwnerReference{
 		{
@@ -743,3 +903,79 @@ func createMachineOwnerHierarchy(machine *clusterv1.Machine) []client.Object {
 		})
 	return objs
 }
+
+func Test_refreshVSphereVMs(t *testing.T) {
+	ns := "test"
+	vsphereCluster := &infrav1.VSphereCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "valid-vsphere-cluster",
+			Namespace: ns,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: "valid-cluster",
+			},
+		},
+	}
+	newVSphereVM := func(name, clusterName string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel: clusterName,
+				},
+			},
+		}
+	}
+	withAnnotation := func(value string) *infrav1.VSphereCluster {
+		c := vsphereCluster.DeepCopy()
+		c.Annotations = map[string]string{infrav1.RefreshVMsAnnotation: value}
+		return c
+	}
+
+	tests := []struct {
+		name             string
+		oldCluster       *infrav1.VSphereCluster
+		newCluster       *infrav1.VSphereCluster
+		expectedRequests int
+	}{
+		{
+			name:       "without annotation",
+			oldCluster: vsphereCluster,
+			newCluster: vsphereCluster,
+		},
+		{
+			name:             "when the annotation is added",
+			oldCluster:       vsphereCluster,
+			newCluster:       withAnnotation("2024-01-01T00:00:00Z"),
+			expectedRequests: 2,
+		},
+		{
+			name:             "when the annotation changed",
+			oldCluster:       withAnnotation("2024-01-01T00:00:00Z"),
+			newCluster:       withAnnotation("2024-01-02T00:00:00Z"),
+			expectedRequests: 2,
+		},
+		{
+			name:       "when the annotation did not change",
+			oldCluster: withAnnotation("2024-01-01T00:00:00Z"),
+			newCluster: withAnnotation("2024-01-01T00:00:00Z"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r := vmReconciler{
+				ControllerManagerContext: fake.NewControllerManagerContext(
+					newVSphereVM("foo", "valid-cluster"),
+					newVSphereVM("bar", "valid-cluster"),
+					newVSphereVM("baz", "other-cluster"),
+				),
+			}
+			q := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0))
+			defer q.ShutDown()
+
+			r.refreshVSphereVMs(ctx, event.UpdateEvent{ObjectOld: tt.oldCluster, ObjectNew: tt.newCluster}, q)
+			g.Eventually(q.Len).Should(Equal(tt.expectedRequests))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	clusterutilv1 "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// reconcileHostMaintenanceTaint taints the node of the VSphereVM with the HostMaintenanceTaint
+// while the HostAvailableCondition reports the ESXi host of its VM entering or being in
+// maintenance mode, so workloads are no longer scheduled onto the node while DRS evacuates
+// the host. Once the host is available again, the taint and the condition are removed.
+// It returns the time after which to retry if the workload cluster client is locked.
+func (r vmReconciler) reconcileHostMaintenanceTaint(ctx context.Context, vmCtx *capvcontext.VMContext, machine *clusterv1.Machine) (time.Duration, error) {
+	vsphereVM := vmCtx.VSphereVM
+	if !conditions.Has(vsphereVM, infrav1.HostAvailableCondition) {
+		return 0, nil
+	}
+
+	// A VM without a node has no taint to remove.
+	if machine == nil || machine.Status.NodeRef == nil {
+		if conditions.IsTrue(vsphereVM, infrav1.HostAvailableCondition) {
+			conditions.Delete(vsphereVM, infrav1.HostAvailableCondition)
+		}
+		return 0, nil
+	}
+
+	cluster, err := clusterutilv1.GetClusterFromMetadata(ctx, r.Client, vsphereVM.ObjectMeta)
+	if err != nil {
+		return 0, err
+	}
+	clusterClient, err := r.remoteClusterCacheTracker.GetClient(ctx, ctrlclient.ObjectKeyFromObject(cluster))
+	if err != nil {
+		if errors.Is(err, remote.ErrClusterLocked) {
+			ctrl.LoggerFrom(ctx).V(5).Info("Requeuing because another worker has the lock on the ClusterCacheTracker")
+			return time.Minute, nil
+		}
+		return 0, err
+	}
+	return 0, reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, clusterClient, machine.Status.NodeRef.Name)
+}
+
+// reconcileNodeHostMaintenanceTaint adds the HostMaintenanceTaint to the node with the given
+// name if the HostAvailableCondition of the VSphereVM is false, and otherwise removes the
+// taint and the condition.
+func reconcileNodeHostMaintenanceTaint(ctx context.Context, vsphereVM *infrav1.VSphereVM, clusterClient ctrlclient.Client, nodeName string) error {
+	log := ctrl.LoggerFrom(ctx).WithValues("Node", nodeName)
+	available := conditions.IsTrue(vsphereVM, infrav1.HostAvailableCondition)
+
+	node := &corev1.Node{}
+	if err := clusterClient.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get node %s", nodeName)
+		}
+		if available {
+			conditions.Delete(vsphereVM, infrav1.HostAvailableCondition)
+		}
+		return nil
+	}
+
+	original := node.DeepCopy()
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != infrav1.HostMaintenanceTaint {
+			taints = append(taints, taint)
+		}
+	}
+	tainted := len(taints) != len(node.Spec.Taints)
+
+	switch {
+	case !available && !tainted:
+		log.Info("Tainting node as the host of the VM enters maintenance mode")
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+			Key:    infrav1.HostMaintenanceTaint,
+			Effect: corev1.TaintEffectNoSchedule,
+		})
+	case available && tainted:
+		log.Info("Removing taint from node as the host of the VM exited maintenance mode")
+		node.Spec.Taints = taints
+	}
+
+	if len(node.Spec.Taints) != len(original.Spec.Taints) {
+		// The optimistic lock keeps taints which are changed concurrently by others.
+		if err := clusterClient.Patch(ctx, node, ctrlclient.MergeFromWithOptions(original, ctrlclient.MergeFromWithOptimisticLock{})); err != nil {
+			return errors.Wrapf(err, "failed to patch taints of node %s", nodeName)
+		}
+	}
+	if available {
+		conditions.Delete(vsphereVM, infrav1.HostAvailableCondition)
+	}
+	return nil
+}
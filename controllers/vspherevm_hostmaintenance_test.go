@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+func Test_vmReconciler_reconcileHostMaintenanceTaint(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("when the VSphereVM has no host available condition", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		vmCtx := &capvcontext.VMContext{VSphereVM: &infrav1.VSphereVM{}}
+
+		retryAfter, err := vmReconciler{}.reconcileHostMaintenanceTaint(ctx, vmCtx, &clusterv1.Machine{})
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+	})
+
+	t.Run("when the Machine has no node", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		vmCtx := &capvcontext.VMContext{VSphereVM: &infrav1.VSphereVM{}}
+
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.HostAvailableCondition, infrav1.InMaintenanceModeReason, clusterv1.ConditionSeverityInfo, "")
+		_, err := vmReconciler{}.reconcileHostMaintenanceTaint(ctx, vmCtx, &clusterv1.Machine{})
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(gomega.BeTrue())
+
+		conditions.MarkTrue(vmCtx.VSphereVM, infrav1.HostAvailableCondition)
+		_, err = vmReconciler{}.reconcileHostMaintenanceTaint(ctx, vmCtx, &clusterv1.Machine{})
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(gomega.BeFalse())
+	})
+}
+
+func Test_reconcileNodeHostMaintenanceTaint(t *testing.T) {
+	ctx := context.Background()
+	otherTaint := corev1.Taint{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule}
+
+	getTaints := func(g *gomega.WithT, c ctrlclient.Client) []corev1.Taint {
+		node := &corev1.Node{}
+		g.Expect(c.Get(ctx, ctrlclient.ObjectKey{Name: "node-1"}, node)).To(gomega.Succeed())
+		return node.Spec.Taints
+	}
+
+	t.Run("when the host enters and exits maintenance mode", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{otherTaint}},
+		}).Build()
+		vsphereVM := &infrav1.VSphereVM{}
+
+		conditions.MarkFalse(vsphereVM, infrav1.HostAvailableCondition, infrav1.EnteringMaintenanceModeReason, clusterv1.ConditionSeverityInfo, "")
+		g.Expect(reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")).To(gomega.Succeed())
+		taints := getTaints(g, c)
+		g.Expect(taints).To(gomega.HaveLen(2))
+		g.Expect(taints[1].Key).To(gomega.Equal(infrav1.HostMaintenanceTaint))
+		g.Expect(taints[1].Effect).To(gomega.Equal(corev1.TaintEffectNoSchedule))
+
+		// The taint is only added once.
+		conditions.MarkFalse(vsphereVM, infrav1.HostAvailableCondition, infrav1.InMaintenanceModeReason, clusterv1.ConditionSeverityInfo, "")
+		g.Expect(reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")).To(gomega.Succeed())
+		g.Expect(getTaints(g, c)).To(gomega.HaveLen(2))
+		g.Expect(conditions.Has(vsphereVM, infrav1.HostAvailableCondition)).To(gomega.BeTrue())
+
+		// The taint and the condition are removed once the host exits maintenance mode.
+		conditions.MarkTrue(vsphereVM, infrav1.HostAvailableCondition)
+		g.Expect(reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")).To(gomega.Succeed())
+		g.Expect(getTaints(g, c)).To(gomega.ConsistOf(otherTaint))
+		g.Expect(conditions.Has(vsphereVM, infrav1.HostAvailableCondition)).To(gomega.BeFalse())
+	})
+
+	t.Run("when the taints of the node change concurrently", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		concurrentTaint := corev1.Taint{Key: "example.com/concurrent", Effect: corev1.TaintEffectNoSchedule}
+		changed := false
+		c := fake.NewClientBuilder().WithObjects(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{otherTaint}},
+		}).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, patch ctrlclient.Patch, opts ...ctrlclient.PatchOption) error {
+				// Another client changes the taints of the node between the Get and the Patch.
+				if !changed {
+					changed = true
+					node := &corev1.Node{}
+					g.Expect(c.Get(ctx, ctrlclient.ObjectKeyFromObject(obj), node)).To(gomega.Succeed())
+					node.Spec.Taints = append(node.Spec.Taints, concurrentTaint)
+					g.Expect(c.Update(ctx, node)).To(gomega.Succeed())
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+		vsphereVM := &infrav1.VSphereVM{}
+
+		// The patch conflicts instead of dropping the concurrently added taint.
+		conditions.MarkFalse(vsphereVM, infrav1.HostAvailableCondition, infrav1.EnteringMaintenanceModeReason, clusterv1.ConditionSeverityInfo, "")
+		err := reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")
+		g.Expect(apierrors.IsConflict(errors.Cause(err))).To(gomega.BeTrue())
+		g.Expect(getTaints(g, c)).To(gomega.ConsistOf(otherTaint, concurrentTaint))
+
+		// The next reconcile taints the node and keeps the concurrently added taint.
+		g.Expect(reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")).To(gomega.Succeed())
+		g.Expect(getTaints(g, c)).To(gomega.ConsistOf(otherTaint, concurrentTaint,
+			corev1.Taint{Key: infrav1.HostMaintenanceTaint, Effect: corev1.TaintEffectNoSchedule}))
+	})
+
+	t.Run("when the node does not exist", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().Build()
+		vsphereVM := &infrav1.VSphereVM{}
+
+		conditions.MarkTrue(vsphereVM, infrav1.HostAvailableCondition)
+		g.Expect(reconcileNodeHostMaintenanceTaint(ctx, vsphereVM, c, "node-1")).To(gomega.Succeed())
+		g.Expect(conditions.Has(vsphereVM, infrav1.HostAvailableCondition)).To(gomega.BeFalse())
+	})
+}
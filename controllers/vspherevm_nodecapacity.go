@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	clusterutilv1 "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// nodeCapacityRefreshPollInterval is the interval at which a cordoned node is checked for the
+// kubelet having restarted on the reconfigured VM, as no event of the VSphereVM signals it.
+const nodeCapacityRefreshPollInterval = 30 * time.Second
+
+// reconcileNodeCapacityRefresh cordons the node of the VSphereVM while its
+// NodeCapacityRefreshedCondition waits for the kubelet to restart on the reconfigured VM, and
+// uncordons it once the kubelet restarted or the node capacity refresh timeout passed. The
+// condition is set before the VM is reconfigured, so the node is cordoned before.
+// It returns the time after which to check the node again.
+func (r vmReconciler) reconcileNodeCapacityRefresh(ctx context.Context, vmCtx *capvcontext.VMContext, machine *clusterv1.Machine) (time.Duration, error) {
+	vsphereVM := vmCtx.VSphereVM
+	if conditions.GetReason(vsphereVM, infrav1.NodeCapacityRefreshedCondition) != infrav1.WaitingForNodeCapacityReason {
+		return 0, nil
+	}
+
+	// A VM without a node has no node whose capacity is refreshed.
+	if machine == nil || machine.Status.NodeRef == nil {
+		conditions.Delete(vsphereVM, infrav1.NodeCapacityRefreshedCondition)
+		return 0, nil
+	}
+
+	cluster, err := clusterutilv1.GetClusterFromMetadata(ctx, r.Client, vsphereVM.ObjectMeta)
+	if err != nil {
+		return 0, err
+	}
+	clusterClient, err := r.remoteClusterCacheTracker.GetClient(ctx, ctrlclient.ObjectKeyFromObject(cluster))
+	if err != nil {
+		if errors.Is(err, remote.ErrClusterLocked) {
+			ctrl.LoggerFrom(ctx).V(5).Info("Requeuing because another worker has the lock on the ClusterCacheTracker")
+			return time.Minute, nil
+		}
+		return 0, err
+	}
+	return reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, clusterClient, machine.Status.NodeRef.Name, r.NodeCapacityRefreshTimeout)
+}
+
+// reconcileNodeCapacityRefreshCordon cordons the node with the given name and records its boot
+// ID and capacity in the NodeCapacityRefreshAnnotation and NodeCapacityAnnotation. Once the
+// node is ready with another boot ID or capacity, i.e. the kubelet restarted on the VM which
+// was powered off or had CPUs or memory hot-added, or the timeout passed, the node is
+// uncordoned. Nodes which are cordoned by others are left alone.
+func reconcileNodeCapacityRefreshCordon(ctx context.Context, vsphereVM *infrav1.VSphereVM, clusterClient ctrlclient.Client, nodeName string, timeout time.Duration) (time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("Node", nodeName)
+
+	node := &corev1.Node{}
+	if err := clusterClient.Get(ctx, ctrlclient.ObjectKey{Name: nodeName}, node); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, errors.Wrapf(err, "failed to get node %s", nodeName)
+		}
+		conditions.Delete(vsphereVM, infrav1.NodeCapacityRefreshedCondition)
+		return 0, nil
+	}
+
+	original := node.DeepCopy()
+	bootID, cordoned := node.Annotations[infrav1.NodeCapacityRefreshAnnotation]
+	var retryAfter time.Duration
+	switch {
+	case !cordoned && node.Spec.Unschedulable:
+		log.Info("Node is already cordoned, skipping cordon until the kubelet restarted")
+		conditions.Delete(vsphereVM, infrav1.NodeCapacityRefreshedCondition)
+	case !cordoned:
+		log.Info("Cordoning node until the kubelet restarted on the reconfigured VM")
+		node.Spec.Unschedulable = true
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[infrav1.NodeCapacityRefreshAnnotation] = node.Status.NodeInfo.BootID
+		node.Annotations[infrav1.NodeCapacityAnnotation] = nodeCapacity(node)
+		retryAfter = nodeCapacityRefreshPollInterval
+	case isNodeRestarted(node, bootID) && isNodeReady(node):
+		log.Info("Uncordoning node as the kubelet restarted on the reconfigured VM")
+		uncordonNode(node)
+		conditions.Delete(vsphereVM, infrav1.NodeCapacityRefreshedCondition)
+	case timeout > 0 && time.Since(conditions.Get(vsphereVM, infrav1.NodeCapacityRefreshedCondition).LastTransitionTime.Time) > timeout:
+		log.Info("Uncordoning node as the kubelet did not restart within the timeout", "timeout", timeout)
+		uncordonNode(node)
+		conditions.MarkFalse(vsphereVM, infrav1.NodeCapacityRefreshedCondition, infrav1.NodeCapacityRefreshTimedOutReason, clusterv1.ConditionSeverityWarning,
+			"the kubelet did not restart within %s", timeout)
+	default:
+		retryAfter = nodeCapacityRefreshPollInterval
+	}
+
+	if node.Spec.Unschedulable != original.Spec.Unschedulable {
+		// The optimistic lock keeps changes of the node by others, e.g. a drain.
+		if err := clusterClient.Patch(ctx, node, ctrlclient.MergeFromWithOptions(original, ctrlclient.MergeFromWithOptimisticLock{})); err != nil {
+			return 0, errors.Wrapf(err, "failed to patch node %s", nodeName)
+		}
+	}
+	return retryAfter, nil
+}
+
+// uncordonNode marks the node schedulable and removes the NodeCapacityRefreshAnnotation and
+// NodeCapacityAnnotation.
+func uncordonNode(node *corev1.Node) {
+	node.Spec.Unschedulable = false
+	delete(node.Annotations, infrav1.NodeCapacityRefreshAnnotation)
+	delete(node.Annotations, infrav1.NodeCapacityAnnotation)
+}
+
+// isNodeRestarted returns whether the kubelet restarted since the node was cordoned, i.e. the
+// node has another boot ID, or another capacity, as a hot-add does not reboot the VM.
+func isNodeRestarted(node *corev1.Node, bootID string) bool {
+	if node.Status.NodeInfo.BootID != bootID {
+		return true
+	}
+	capacity, ok := node.Annotations[infrav1.NodeCapacityAnnotation]
+	return ok && capacity != nodeCapacity(node)
+}
+
+// nodeCapacity returns the CPU and memory capacity of the node, e.g. "cpu=4,memory=8148012Ki".
+func nodeCapacity(node *corev1.Node) string {
+	return fmt.Sprintf("cpu=%s,memory=%s", node.Status.Capacity.Cpu(), node.Status.Capacity.Memory())
+}
+
+// isNodeReady returns whether the node has a true Ready condition.
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+func Test_vmReconciler_reconcileNodeCapacityRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("when the VSphereVM does not wait for the node capacity", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		vmCtx := &capvcontext.VMContext{VSphereVM: &infrav1.VSphereVM{}}
+
+		retryAfter, err := vmReconciler{}.reconcileNodeCapacityRefresh(ctx, vmCtx, &clusterv1.Machine{})
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+	})
+
+	t.Run("when the Machine has no node", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		vmCtx := &capvcontext.VMContext{VSphereVM: &infrav1.VSphereVM{}}
+
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition, infrav1.WaitingForNodeCapacityReason, clusterv1.ConditionSeverityInfo, "")
+		_, err := vmReconciler{}.reconcileNodeCapacityRefresh(ctx, vmCtx, &clusterv1.Machine{})
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.BeFalse())
+	})
+}
+
+func Test_reconcileNodeCapacityRefreshCordon(t *testing.T) {
+	ctx := context.Background()
+
+	newNode := func(unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{BootID: "boot-1"},
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	getNode := func(g *gomega.WithT, c ctrlclient.Client) *corev1.Node {
+		node := &corev1.Node{}
+		g.Expect(c.Get(ctx, ctrlclient.ObjectKey{Name: "node-1"}, node)).To(gomega.Succeed())
+		return node
+	}
+	waitingVM := func() *infrav1.VSphereVM {
+		vsphereVM := &infrav1.VSphereVM{}
+		conditions.MarkFalse(vsphereVM, infrav1.NodeCapacityRefreshedCondition, infrav1.WaitingForNodeCapacityReason, clusterv1.ConditionSeverityInfo, "")
+		return vsphereVM
+	}
+
+	t.Run("when the kubelet restarts on the reconfigured VM", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(newNode(false)).Build()
+		vsphereVM := waitingVM()
+
+		retryAfter, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.Equal(nodeCapacityRefreshPollInterval))
+		node := getNode(g, c)
+		g.Expect(node.Spec.Unschedulable).To(gomega.BeTrue())
+		g.Expect(node.Annotations).To(gomega.HaveKeyWithValue(infrav1.NodeCapacityRefreshAnnotation, "boot-1"))
+		g.Expect(node.Annotations).To(gomega.HaveKeyWithValue(infrav1.NodeCapacityAnnotation, "cpu=2,memory=4Gi"))
+
+		// The node stays cordoned until the kubelet restarted and the node is ready.
+		node.Status.NodeInfo.BootID = "boot-2"
+		node.Status.Conditions[0].Status = corev1.ConditionUnknown
+		g.Expect(c.Status().Update(ctx, node)).To(gomega.Succeed())
+		retryAfter, err = reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.Equal(nodeCapacityRefreshPollInterval))
+		g.Expect(getNode(g, c).Spec.Unschedulable).To(gomega.BeTrue())
+
+		node = getNode(g, c)
+		node.Status.Conditions[0].Status = corev1.ConditionTrue
+		g.Expect(c.Status().Update(ctx, node)).To(gomega.Succeed())
+		retryAfter, err = reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+		node = getNode(g, c)
+		g.Expect(node.Spec.Unschedulable).To(gomega.BeFalse())
+		g.Expect(node.Annotations).ToNot(gomega.HaveKey(infrav1.NodeCapacityRefreshAnnotation))
+		g.Expect(node.Annotations).ToNot(gomega.HaveKey(infrav1.NodeCapacityAnnotation))
+		g.Expect(conditions.Has(vsphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.BeFalse())
+	})
+
+	t.Run("when the kubelet restarts after CPUs were hot-added", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(newNode(false)).Build()
+		vsphereVM := waitingVM()
+
+		_, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(getNode(g, c).Spec.Unschedulable).To(gomega.BeTrue())
+
+		// The boot ID is kept, as a hot-add does not reboot the VM.
+		node := getNode(g, c)
+		node.Status.Capacity[corev1.ResourceCPU] = resource.MustParse("4")
+		g.Expect(c.Status().Update(ctx, node)).To(gomega.Succeed())
+		retryAfter, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+		g.Expect(getNode(g, c).Spec.Unschedulable).To(gomega.BeFalse())
+		g.Expect(conditions.Has(vsphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.BeFalse())
+	})
+
+	t.Run("when the kubelet does not restart within the timeout", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(newNode(false)).Build()
+		vsphereVM := waitingVM()
+
+		_, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Minute)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(getNode(g, c).Spec.Unschedulable).To(gomega.BeTrue())
+
+		vsphereVM.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+		retryAfter, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Minute)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+		g.Expect(getNode(g, c).Spec.Unschedulable).To(gomega.BeFalse())
+		g.Expect(conditions.GetReason(vsphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.Equal(infrav1.NodeCapacityRefreshTimedOutReason))
+	})
+
+	t.Run("when the node is already cordoned", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		c := fake.NewClientBuilder().WithObjects(newNode(true)).Build()
+		vsphereVM := waitingVM()
+
+		retryAfter, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, c, "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(retryAfter).To(gomega.BeZero())
+		node := getNode(g, c)
+		g.Expect(node.Spec.Unschedulable).To(gomega.BeTrue())
+		g.Expect(node.Annotations).ToNot(gomega.HaveKey(infrav1.NodeCapacityRefreshAnnotation))
+		g.Expect(conditions.Has(vsphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.BeFalse())
+	})
+
+	t.Run("when the node does not exist", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		vsphereVM := waitingVM()
+
+		_, err := reconcileNodeCapacityRefreshCordon(ctx, vsphereVM, fake.NewClientBuilder().Build(), "node-1", time.Hour)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(conditions.Has(vsphereVM, infrav1.NodeCapacityRefreshedCondition)).To(gomega.BeFalse())
+	})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// reconcileProvisioningTime reports a VSphereVM which is not ready within its maximum
+// provisioning time by the ProvisioningStalled condition, and marks it as failed if its
+// provisioning stalled policy is fail, so a MachineHealthCheck remediates its Machine.
+// It returns the time left until the VSphereVM exceeds its maximum provisioning time, or
+// zero if it is ready, has no maximum provisioning time or already exceeded it.
+func (r vmReconciler) reconcileProvisioningTime(ctx context.Context, vmCtx *capvcontext.VMContext) time.Duration {
+	vsphereVM := vmCtx.VSphereVM
+	maxProvisioningTime := vsphereVM.Spec.MaxProvisioningTime
+	if maxProvisioningTime == nil || vsphereVM.Status.Ready {
+		conditions.Delete(vsphereVM, infrav1.ProvisioningStalledCondition)
+		return 0
+	}
+
+	if remaining := time.Until(provisioningStartTime(vsphereVM).Add(maxProvisioningTime.Duration)); remaining > 0 {
+		return remaining
+	}
+
+	message := fmt.Sprintf("VSphereVM is not ready within the maximum provisioning time of %s", maxProvisioningTime.Duration)
+	if !conditions.Has(vsphereVM, infrav1.ProvisioningStalledCondition) {
+		ctrl.LoggerFrom(ctx).Info("VSphereVM exceeded its maximum provisioning time", "maxProvisioningTime", maxProvisioningTime.Duration)
+		r.Recorder.Event(vsphereVM, corev1.EventTypeWarning, infrav1.MaxProvisioningTimeExceededReason, message)
+	}
+	conditions.MarkFalse(vsphereVM, infrav1.ProvisioningStalledCondition, infrav1.MaxProvisioningTimeExceededReason, clusterv1.ConditionSeverityWarning, message)
+
+	if vsphereVM.Spec.ProvisioningStalledPolicy == infrav1.ProvisioningStalledPolicyFail && vsphereVM.Status.FailureReason == nil {
+		vsphereVM.Status.FailureReason = ptr.To(capierrors.CreateMachineError)
+		vsphereVM.Status.FailureMessage = ptr.To(message)
+	}
+	return 0
+}
+
+// provisioningStartTime returns the time the provisioning of the VSphereVM started, which
+// is its creation or, if its VM was redeployed, the start of the latest redeploy.
+func provisioningStartTime(vsphereVM *infrav1.VSphereVM) metav1.Time {
+	start := vsphereVM.CreationTimestamp
+	if redeployed := conditions.Get(vsphereVM, infrav1.VMRedeployedCondition); redeployed != nil && start.Before(&redeployed.LastTransitionTime) {
+		start = redeployed.LastTransitionTime
+	}
+	return start
+}
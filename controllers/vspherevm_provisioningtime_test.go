@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+func Test_vmReconciler_reconcileProvisioningTime(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(age time.Duration, policy infrav1.ProvisioningStalledPolicy) (vmReconciler, *record.FakeRecorder, *capvcontext.VMContext) {
+		recorder := record.NewFakeRecorder(10)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-vm",
+					Namespace:         "my-namespace",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						MaxProvisioningTime:       &metav1.Duration{Duration: 30 * time.Minute},
+						ProvisioningStalledPolicy: policy,
+					},
+				},
+			},
+		}
+		return vmReconciler{Recorder: recorder}, recorder, vmCtx
+	}
+
+	t.Run("when the VSphereVM is within its maximum provisioning time", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		r, recorder, vmCtx := setup(10*time.Minute, "")
+
+		remaining := r.reconcileProvisioningTime(ctx, vmCtx)
+		g.Expect(remaining).To(gomega.BeNumerically("~", 20*time.Minute, time.Minute))
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ProvisioningStalledCondition)).To(gomega.BeFalse())
+		g.Expect(recorder.Events).To(gomega.BeEmpty())
+	})
+
+	t.Run("when the VSphereVM exceeds its maximum provisioning time", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		r, recorder, vmCtx := setup(time.Hour, "")
+
+		g.Expect(r.reconcileProvisioningTime(ctx, vmCtx)).To(gomega.BeZero())
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ProvisioningStalledCondition)).To(gomega.Equal(infrav1.MaxProvisioningTimeExceededReason))
+		g.Expect(vmCtx.VSphereVM.Status.FailureReason).To(gomega.BeNil())
+		g.Expect(recorder.Events).To(gomega.HaveLen(1))
+
+		// The event is only recorded once.
+		r.reconcileProvisioningTime(ctx, vmCtx)
+		g.Expect(recorder.Events).To(gomega.HaveLen(1))
+
+		// The condition is removed once the VSphereVM is ready.
+		vmCtx.VSphereVM.Status.Ready = true
+		r.reconcileProvisioningTime(ctx, vmCtx)
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ProvisioningStalledCondition)).To(gomega.BeFalse())
+	})
+
+	t.Run("when the VSphereVM exceeds its maximum provisioning time with the fail policy", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		r, _, vmCtx := setup(time.Hour, infrav1.ProvisioningStalledPolicyFail)
+
+		r.reconcileProvisioningTime(ctx, vmCtx)
+		g.Expect(vmCtx.VSphereVM.Status.FailureReason).ToNot(gomega.BeNil())
+		g.Expect(*vmCtx.VSphereVM.Status.FailureReason).To(gomega.Equal(capierrors.CreateMachineError))
+		g.Expect(vmCtx.VSphereVM.Status.FailureMessage).ToNot(gomega.BeNil())
+	})
+
+	t.Run("when the VM of the VSphereVM was redeployed", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		r, _, vmCtx := setup(time.Hour, "")
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployCloningReason, clusterv1.ConditionSeverityInfo, "")
+
+		g.Expect(r.reconcileProvisioningTime(ctx, vmCtx)).To(gomega.BeNumerically("~", 30*time.Minute, time.Minute))
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ProvisioningStalledCondition)).To(gomega.BeFalse())
+	})
+}
@@ -33,6 +33,47 @@ const (
 	//
 	// alpha: v1.4
 	NodeAntiAffinity featuregate.Feature = "NodeAntiAffinity"
+
+	// GuestNetworkReconfiguration is a feature gate which reboots the guest of a provisioned
+	// VSphereVM once its network metadata changed, so cloud-init reconfigures the network of
+	// the guest instead of the VM being recreated. Rebooting the guest is disruptive.
+	//
+	// alpha: v1.10
+	GuestNetworkReconfiguration featuregate.Feature = "GuestNetworkReconfiguration"
+
+	// VMFolderMove is a feature gate which allows changing the folder of existing
+	// VSphereMachines and VSphereVMs, which moves their VMs into the new folder.
+	//
+	// alpha: v1.10
+	VMFolderMove featuregate.Feature = "VMFolderMove"
+
+	// NoCloudSeedDetach is a feature gate which detaches the NoCloud seed CD-ROM with the
+	// bootstrap data from the VM of a VSphereVM once it is ready, so the bootstrap data is
+	// not exposed to the guest for the whole life of the node.
+	//
+	// alpha: v1.10
+	NoCloudSeedDetach featuregate.Feature = "NoCloudSeedDetach"
+
+	// NodeCapacityRefresh is a feature gate which cordons the node of a VSphereVM before the
+	// CPUs or memory of its VM are reconfigured or hot-added, and uncordons it once the kubelet
+	// reports the new capacity, so no pods are scheduled by the old capacity.
+	//
+	// alpha: v1.10
+	NodeCapacityRefresh featuregate.Feature = "NodeCapacityRefresh"
+
+	// ClusterPlacementSummary is a feature gate which maintains a VSphereClusterPlacement for
+	// every VSphereCluster, summarizing the host, datastores, failure domain and health of the
+	// VMs of the cluster.
+	//
+	// alpha: v1.10
+	ClusterPlacementSummary featuregate.Feature = "ClusterPlacementSummary"
+
+	// VMRename is a feature gate which renames the VM of a VSphereVM in vCenter if its name
+	// differs from the name of the VSphereVM, e.g. after the VSphereVM was restored under
+	// another name or the VM was renamed by another tool.
+	//
+	// alpha: v1.10
+	VMRename featuregate.Feature = "VMRename"
 )
 
 func init() {
@@ -43,5 +84,11 @@ func init() {
 // To add a new feature, define a key for it above and add it here.
 var defaultCAPVFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	// Every feature should be initiated here:
-	NodeAntiAffinity: {Default: false, PreRelease: featuregate.Alpha},
+	NodeAntiAffinity:            {Default: false, PreRelease: featuregate.Alpha},
+	GuestNetworkReconfiguration: {Default: false, PreRelease: featuregate.Alpha},
+	VMFolderMove:                {Default: false, PreRelease: featuregate.Alpha},
+	NoCloudSeedDetach:           {Default: false, PreRelease: featuregate.Alpha},
+	NodeCapacityRefresh:         {Default: false, PreRelease: featuregate.Alpha},
+	ClusterPlacementSummary:     {Default: false, PreRelease: featuregate.Alpha},
+	VMRename:                    {Default: false, PreRelease: featuregate.Alpha},
 }
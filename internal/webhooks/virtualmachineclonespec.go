@@ -0,0 +1,408 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	_ "time/tzdata" // embed the IANA time zone database to validate time zones independently of the host.
+
+	"github.com/vmware/govmomi/object"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// minDiskIOPSLimit is the lowest IOPS limit of a disk accepted by vSphere.
+const minDiskIOPSLimit = 16
+
+const (
+	// maxSCSIControllers is the highest number of SCSI controllers of a VM
+	// supported by vSphere.
+	maxSCSIControllers = 4
+
+	// disksPerSCSIController is the highest number of disks attached to a
+	// SCSI controller.
+	disksPerSCSIController = 15
+)
+
+// kernelArgRegex matches a kernel parameter, optionally assigned a value, without whitespace,
+// quotes or shell metacharacters, so it can be rendered into the boot configuration as is.
+var kernelArgRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+(=[A-Za-z0-9_.,:/@+=-]+)?$`)
+
+// ipxeSettingNameRegex matches the name of an iPXE setting, which is appended to the
+// VMware GuestInfo key prefix of iPXE.
+var ipxeSettingNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// validateVirtualMachineCloneSpec validates the fields of the VirtualMachineCloneSpec
+// which is shared by VSphereMachine, VSphereMachineTemplate and VSphereVM.
+func validateVirtualMachineCloneSpec(spec infrav1.VirtualMachineCloneSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.BootOptions != nil {
+		bootOptionsPath := fldPath.Child("bootOptions")
+		if spec.BootOptions.BootDelay != nil && *spec.BootOptions.BootDelay < 0 {
+			allErrs = append(allErrs, field.Invalid(bootOptionsPath.Child("bootDelay"), *spec.BootOptions.BootDelay, "should be greater than or equal to 0"))
+		}
+		if spec.BootOptions.BootRetryDelay != nil && *spec.BootOptions.BootRetryDelay < 0 {
+			allErrs = append(allErrs, field.Invalid(bootOptionsPath.Child("bootRetryDelay"), *spec.BootOptions.BootRetryDelay, "should be greater than or equal to 0"))
+		}
+	}
+
+	if spec.SecureBoot != nil && *spec.SecureBoot && spec.Firmware == infrav1.VirtualMachineFirmwareBIOS {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("secureBoot"), *spec.SecureBoot, "secure boot requires the efi firmware"))
+	}
+	if spec.TrustedLaunch != nil && *spec.TrustedLaunch {
+		if spec.Firmware != "" && spec.Firmware != infrav1.VirtualMachineFirmwareEFI {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("firmware"), spec.Firmware, "should be efi or unset when trustedLaunch is true"))
+		}
+		if spec.SecureBoot != nil && !*spec.SecureBoot {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secureBoot"), *spec.SecureBoot, "should be true or unset when trustedLaunch is true"))
+		}
+		if spec.VirtualTPM != nil && !*spec.VirtualTPM {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("virtualTPM"), *spec.VirtualTPM, "should be true or unset when trustedLaunch is true"))
+		}
+	}
+
+	for name := range spec.CustomAttributes {
+		switch {
+		case strings.TrimSpace(name) != name || name == "":
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("customAttributes").Key(name), name, "should not be empty or have leading or trailing whitespace"))
+		case isNumeric(name):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("customAttributes").Key(name), name, "should not be a number, which vCenter tools interpret as key of the attribute"))
+		}
+	}
+
+	if spec.OVA != nil {
+		ovaPath := fldPath.Child("ova")
+		if u, err := url.Parse(spec.OVA.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(ovaPath.Child("url"), spec.OVA.URL, "should be a valid HTTP or HTTPS URL"))
+		}
+		if spec.OVA.Checksum != "" {
+			if checksum, err := hex.DecodeString(spec.OVA.Checksum); err != nil || len(checksum) != sha256.Size {
+				allErrs = append(allErrs, field.Invalid(ovaPath.Child("checksum"), spec.OVA.Checksum, "should be a hex encoded SHA-256 checksum"))
+			}
+		}
+	}
+
+	if spec.TimeZone != "" {
+		if _, err := time.LoadLocation(spec.TimeZone); err != nil || spec.TimeZone == "Local" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), spec.TimeZone, "should be a valid IANA time zone"))
+		}
+	}
+	for i, server := range spec.NTPServers {
+		if net.ParseIP(server) == nil && len(validation.IsDNS1123Subdomain(server)) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ntpServers").Index(i), server, "should be a valid hostname or IP address"))
+		}
+	}
+
+	if spec.AdditionalDisksController != nil {
+		busSharing := spec.AdditionalDisksController.BusSharing
+		if busSharing != "" && busSharing != infrav1.SCSIBusSharingNone && spec.CloneMode != infrav1.FullClone {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("additionalDisksController", "busSharing"), busSharing, "bus sharing requires cloneMode fullClone"))
+		}
+	}
+	for i, arg := range spec.KernelArgs {
+		if !kernelArgRegex.MatchString(arg) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("kernelArgs").Index(i), arg, "should be a kernel parameter, optionally followed by = and a value, without whitespace or quotes"))
+		}
+	}
+	if len(spec.KernelArgs) > 0 && spec.OS == infrav1.Windows {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kernelArgs"), "kernel arguments are not supported for Windows guests"))
+	}
+
+	if spec.PerformanceOptions != nil {
+		for k := range spec.PerformanceOptions.ExtraConfig {
+			if strings.HasPrefix(k, "guestinfo.") {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("performanceOptions", "extraConfig").Key(k), k, "guestinfo keys are reserved for the bootstrap data"))
+			}
+		}
+	}
+
+	for i, serialPort := range spec.SerialPorts {
+		allErrs = append(allErrs, validateSerialPort(serialPort, fldPath.Child("serialPorts").Index(i))...)
+	}
+	for i, cdrom := range spec.CDROMs {
+		if cdrom.ISOPath == "" {
+			continue
+		}
+		var datastorePath object.DatastorePath
+		if !datastorePath.FromString(cdrom.ISOPath) || datastorePath.Path == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cdroms").Index(i).Child("isoPath"), cdrom.ISOPath, "should be a datastore path, e.g. \"[datastore1] isos/drivers.iso\""))
+		}
+	}
+
+	sharedDiskPaths := map[string]bool{}
+	for i, sharedDisk := range spec.SharedDisks {
+		var datastorePath object.DatastorePath
+		if !datastorePath.FromString(sharedDisk.DiskPath) || !strings.HasSuffix(datastorePath.Path, ".vmdk") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sharedDisks").Index(i).Child("diskPath"), sharedDisk.DiskPath, "should be a datastore path of a VMDK, e.g. \"[datastore1] shared/data.vmdk\""))
+		}
+		if sharedDiskPaths[sharedDisk.DiskPath] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("sharedDisks").Index(i).Child("diskPath"), sharedDisk.DiskPath))
+		}
+		sharedDiskPaths[sharedDisk.DiskPath] = true
+	}
+
+	for i, file := range spec.Files {
+		if !path.IsAbs(file.Path) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("files").Index(i).Child("path"), file.Path, "should be an absolute path"))
+		}
+	}
+
+	if spec.OVFEnvironment != nil {
+		for id := range spec.OVFEnvironment.Properties {
+			if id == "" {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ovfEnvironment", "properties"), id, "property IDs must not be empty"))
+			}
+		}
+	}
+
+	if probe := spec.ReadinessProbe; probe != nil && (probe.FilePath == "") == (probe.Command == nil) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("readinessProbe"), probe, "exactly one of filePath and command must be set"))
+	}
+
+	if spec.CloudInitDatasource == infrav1.CloudInitDatasourceNoCloud && spec.CloneMode == infrav1.InstantClone {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cloudInitDatasource"), spec.CloudInitDatasource, "the NoCloud datasource is not supported with clone mode instantClone, use the VMware datasource instead"))
+	}
+
+	if spec.BootOptions != nil && spec.BootOptions.NetworkBoot != nil {
+		allErrs = append(allErrs, validateNetworkBoot(spec.BootOptions.NetworkBoot, spec.Network.Devices, fldPath.Child("bootOptions", "networkBoot"))...)
+	}
+
+	if hostname := spec.Hostname; hostname != nil {
+		allErrs = append(allErrs, validateHostname(hostname, spec.CloudInitDatasource, fldPath.Child("hostname"))...)
+	}
+
+	if spec.StorageAffinity != nil && spec.StoragePolicyName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("storagePolicyName"), "a vSAN storage policy is required when storageAffinity is set"))
+	}
+
+	if spec.MaxProvisioningTime != nil && spec.MaxProvisioningTime.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxProvisioningTime"), spec.MaxProvisioningTime, "should be greater than 0"))
+	}
+	if spec.ProvisioningStalledPolicy != "" && spec.MaxProvisioningTime == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxProvisioningTime"), "a maximum provisioning time is required when provisioningStalledPolicy is set"))
+	}
+	if spec.SecureBootFailureTimeout != nil && spec.SecureBootFailureTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("secureBootFailureTimeout"), spec.SecureBootFailureTimeout, "should be greater than 0"))
+	}
+	if spec.SecureBootFailurePolicy == infrav1.SecureBootFailurePolicyDisable && spec.TrustedLaunch != nil && *spec.TrustedLaunch {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("secureBootFailurePolicy"), spec.SecureBootFailurePolicy, "secure boot cannot be disabled when trustedLaunch is true"))
+	}
+
+	numaNodes := map[int32]bool{}
+	for i, node := range spec.NUMANodeAffinity {
+		if node < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("numaNodeAffinity").Index(i), node, "should be greater than or equal to 0"))
+		}
+		if numaNodes[node] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("numaNodeAffinity").Index(i), node))
+		}
+		numaNodes[node] = true
+	}
+
+	for i, device := range spec.Network.Devices {
+		if device.AdapterType == infrav1.NetworkAdapterTypeSriov && device.PhysicalFunction == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("network", "devices").Index(i).Child("physicalFunction"), "a physical function is required when adapterType is sriov"))
+		}
+		if device.AdapterType == infrav1.NetworkAdapterTypeSriov && spec.MemoryReservationLockedToMax != nil && !*spec.MemoryReservationLockedToMax {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryReservationLockedToMax"), *spec.MemoryReservationLockedToMax, "memory reservation must be locked to max when SR-IOV network adapters are defined"))
+		}
+		if device.AdapterType != infrav1.NetworkAdapterTypeSriov && device.PhysicalFunction != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("network", "devices").Index(i).Child("physicalFunction"), device.PhysicalFunction, "physicalFunction can only be set when adapterType is sriov"))
+		}
+	}
+
+	if spec.ResourceAllocation != nil {
+		resourceAllocationPath := fldPath.Child("resourceAllocation")
+		if spec.ResourceAllocation.CPU != nil {
+			allErrs = append(allErrs, validateResourceAllocation(*spec.ResourceAllocation.CPU, resourceAllocationPath.Child("cpu"))...)
+		}
+		if spec.ResourceAllocation.Memory != nil {
+			allErrs = append(allErrs, validateResourceAllocation(*spec.ResourceAllocation.Memory, resourceAllocationPath.Child("memory"))...)
+		}
+		if percent := spec.ResourceAllocation.CPUReservationPercent; percent != nil {
+			if *percent < 0 || *percent > 100 {
+				allErrs = append(allErrs, field.Invalid(resourceAllocationPath.Child("cpuReservationPercent"), *percent, "should be between 0 and 100"))
+			}
+			if spec.ResourceAllocation.CPU != nil && spec.ResourceAllocation.CPU.Reservation != nil {
+				allErrs = append(allErrs, field.Forbidden(resourceAllocationPath.Child("cpuReservationPercent"), "cpuReservationPercent and cpu.reservation are mutually exclusive"))
+			}
+		}
+	}
+
+	if count := spec.SCSIControllerCount; count != 0 && (count < 1 || count > maxSCSIControllers) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scsiControllerCount"), count, fmt.Sprintf("should be between 1 and %d", maxSCSIControllers)))
+	}
+	// The primary disk is attached to a SCSI controller as well.
+	maxDisks := maxSCSIControllers * disksPerSCSIController
+	if spec.SCSIControllerCount > 0 {
+		maxDisks = int(spec.SCSIControllerCount) * disksPerSCSIController
+	}
+	if disks := len(spec.AdditionalDisksGiB) + 1; disks > maxDisks {
+		allErrs = append(allErrs, field.TooMany(fldPath.Child("additionalDisksGiB"), len(spec.AdditionalDisksGiB), maxDisks-1))
+	}
+
+	if spec.DiskStorageIOAllocation != nil {
+		diskStorageIOAllocationPath := fldPath.Child("diskStorageIOAllocation")
+		if limit := spec.DiskStorageIOAllocation.Limit; limit != nil && *limit != -1 && *limit < minDiskIOPSLimit {
+			allErrs = append(allErrs, field.Invalid(diskStorageIOAllocationPath.Child("limit"), *limit, fmt.Sprintf("should be -1 (unlimited) or greater than or equal to %d", minDiskIOPSLimit)))
+		}
+		if spec.DiskStorageIOAllocation.Shares != nil {
+			allErrs = append(allErrs, validateResourceShares(*spec.DiskStorageIOAllocation.Shares, diskStorageIOAllocationPath.Child("shares"))...)
+		}
+	}
+
+	primary := -1
+	for i, device := range spec.Network.Devices {
+		if !device.Primary {
+			continue
+		}
+		if primary >= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("network", "devices").Index(i).Child("primary"), device.Primary, fmt.Sprintf("only one device can be marked as primary, devices[%d] is already primary", primary)))
+			continue
+		}
+		primary = i
+	}
+
+	return allErrs
+}
+
+func validateResourceAllocation(allocation infrav1.ResourceAllocationSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if allocation.Reservation != nil && allocation.Limit != nil && *allocation.Limit >= 0 && *allocation.Limit < *allocation.Reservation {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("limit"), *allocation.Limit, "should be greater than or equal to the reservation"))
+	}
+	if allocation.Shares != nil {
+		allErrs = append(allErrs, validateResourceShares(*allocation.Shares, fldPath.Child("shares"))...)
+	}
+
+	return allErrs
+}
+
+func validateResourceShares(shares infrav1.ResourceShares, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if shares.Level == infrav1.SharesLevelCustom && shares.Shares <= 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("shares"), "a positive number of shares is required when level is custom"))
+	}
+	if shares.Level != infrav1.SharesLevelCustom && shares.Shares != 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("shares"), shares.Shares, "shares can only be set when level is custom"))
+	}
+
+	return allErrs
+}
+
+func validateSerialPort(serialPort infrav1.SerialPortSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if (serialPort.URI == "") == (serialPort.File == "") {
+		return append(allErrs, field.Invalid(fldPath, serialPort, "exactly one of uri and file must be set"))
+	}
+	if serialPort.File != "" {
+		if serialPort.ProxyURI != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("proxyURI"), "proxyURI can only be set together with uri"))
+		}
+		var datastorePath object.DatastorePath
+		if !datastorePath.FromString(serialPort.File) || datastorePath.Path == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("file"), serialPort.File, "should be a datastore path, e.g. \"[datastore1] logs/console.log\""))
+		}
+		return allErrs
+	}
+	// With a vSPC the URI is only the service name passed to the concentrator.
+	if serialPort.ProxyURI != "" {
+		if !isNetworkURI(serialPort.ProxyURI) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("proxyURI"), serialPort.ProxyURI, "should be a URI with scheme and host, e.g. \"telnets://vspc.example.com:13370\""))
+		}
+	} else if !isNetworkURI(serialPort.URI) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), serialPort.URI, "should be a URI with scheme and host, e.g. \"telnet://logs.example.com:13370\""))
+	}
+	return allErrs
+}
+
+func isNetworkURI(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// isNumeric returns true if the name is parsed as integer, like vCenter tools parse the
+// keys of custom attributes.
+func isNumeric(name string) bool {
+	_, err := strconv.Atoi(name)
+	return err == nil
+}
+
+func validateHostname(hostname *infrav1.HostnameSpec, datasource infrav1.CloudInitDatasource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if datasource == infrav1.CloudInitDatasourceNoCloud {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "hostname is not supported with the NoCloud cloud-init datasource"))
+	}
+	switch hostname.Source {
+	case infrav1.HostnameSourceTemplate:
+		if hostname.Template == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("template"), "a template is required when source is template"))
+		} else if _, err := template.New("hostname").Parse(hostname.Template); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("template"), hostname.Template, err.Error()))
+		}
+	default:
+		if hostname.Template != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("template"), hostname.Template, "template can only be set when source is template"))
+		}
+	}
+	return allErrs
+}
+
+func validateNetworkBoot(networkBoot *infrav1.VirtualMachineNetworkBoot, devices []infrav1.NetworkDeviceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if int(networkBoot.DeviceIndex) >= len(devices) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("deviceIndex"), networkBoot.DeviceIndex, "should be the index of a network device"))
+	} else if name := networkBoot.ProvisioningNetworkName; name != "" && devices[networkBoot.DeviceIndex].NetworkName != name {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("provisioningNetworkName"), name, fmt.Sprintf("network device %d should be attached to the provisioning network", networkBoot.DeviceIndex)))
+	}
+	for name := range networkBoot.IPXESettings {
+		if !ipxeSettingNameRegex.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ipxeSettings").Key(name), name, "should be the name of an iPXE setting"))
+		}
+		if name == "scriptlet" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipxeSettings").Key(name), "use ipxeScript to set the iPXE script"))
+		}
+	}
+	return allErrs
+}
+
+// virtualMachineCloneSpecWarnings returns warnings about fields of the VirtualMachineCloneSpec
+// which are valid, but restrict the operation of the VM.
+func virtualMachineCloneSpecWarnings(spec infrav1.VirtualMachineCloneSpec) admission.Warnings {
+	var warnings admission.Warnings
+	if len(spec.NUMANodeAffinity) > 0 {
+		warnings = append(warnings, "numaNodeAffinity breaks the vMotion compatibility of the VM, so DRS does not migrate it and it must be powered off to be moved to another host")
+	}
+	return warnings
+}
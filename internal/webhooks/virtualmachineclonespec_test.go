@@ -0,0 +1,825 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func TestValidateVirtualMachineCloneSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    infrav1.VirtualMachineCloneSpec
+		wantErr bool
+	}{
+		{
+			name: "empty spec",
+			spec: infrav1.VirtualMachineCloneSpec{},
+		},
+		{
+			name: "additional disks on the SCSI controllers",
+			spec: infrav1.VirtualMachineCloneSpec{
+				AdditionalDisksGiB:  make([]int32, 29),
+				SCSIControllerCount: 2,
+			},
+		},
+		{
+			name: "additional disks exceeding the SCSI controllers",
+			spec: infrav1.VirtualMachineCloneSpec{
+				AdditionalDisksGiB:  make([]int32, 30),
+				SCSIControllerCount: 2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "additional disks exceeding the SCSI controllers of a VM",
+			spec: infrav1.VirtualMachineCloneSpec{
+				AdditionalDisksGiB: make([]int32, 60),
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many SCSI controllers",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SCSIControllerCount: 5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid boot options",
+			spec: infrav1.VirtualMachineCloneSpec{
+				BootOptions: &infrav1.VirtualMachineBootOptions{
+					BootDelay:        ptr.To[int64](5000),
+					BootRetryEnabled: ptr.To(true),
+					BootRetryDelay:   ptr.To[int64](10000),
+				},
+			},
+		},
+		{
+			name: "negative boot delay",
+			spec: infrav1.VirtualMachineCloneSpec{
+				BootOptions: &infrav1.VirtualMachineBootOptions{
+					BootDelay: ptr.To[int64](-1),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative boot retry delay",
+			spec: infrav1.VirtualMachineCloneSpec{
+				BootOptions: &infrav1.VirtualMachineBootOptions{
+					BootRetryEnabled: ptr.To(true),
+					BootRetryDelay:   ptr.To[int64](-1),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid OVA",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OVA: &infrav1.OVASource{
+					URL:      "https://example.com/ubuntu-2204-kube-v1.29.0.ova",
+					Checksum: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+				},
+			},
+		},
+		{
+			name: "OVA with invalid URL",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OVA: &infrav1.OVASource{
+					URL: "ftp://example.com/ubuntu-2204-kube-v1.29.0.ova",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "OVA with invalid checksum",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OVA: &infrav1.OVASource{
+					URL:      "https://example.com/ubuntu-2204-kube-v1.29.0.ova",
+					Checksum: "e3b0c44298fc1c14",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid time settings",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TimeZone:   "America/New_York",
+				NTPServers: []string{"time.example.com", "10.0.0.1"},
+			},
+		},
+		{
+			name: "invalid time zone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TimeZone: "Mars/Olympus_Mons",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid NTP server",
+			spec: infrav1.VirtualMachineCloneSpec{
+				NTPServers: []string{"time example com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid kernel arguments",
+			spec: infrav1.VirtualMachineCloneSpec{
+				KernelArgs: []string{"isolcpus=2-7", "hugepagesz=1G", "hugepages=16", "nosmt", "console=ttyS0,115200n8"},
+			},
+		},
+		{
+			name: "kernel argument with whitespace",
+			spec: infrav1.VirtualMachineCloneSpec{
+				KernelArgs: []string{"isolcpus=2-7 nosmt"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kernel argument with quotes",
+			spec: infrav1.VirtualMachineCloneSpec{
+				KernelArgs: []string{`dyndbg="file foo.c +p"`},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kernel arguments for Windows",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OS:         infrav1.Windows,
+				KernelArgs: []string{"nosmt"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid performance options",
+			spec: infrav1.VirtualMachineCloneSpec{
+				PerformanceOptions: &infrav1.VirtualMachinePerformanceOptions{
+					VirtualCPUPerformanceCountersEnabled: ptr.To(true),
+					ExtraConfig:                          map[string]string{"monitor_control.pseudo_perfctr": "TRUE"},
+				},
+			},
+		},
+		{
+			name: "performance options with guestinfo key",
+			spec: infrav1.VirtualMachineCloneSpec{
+				PerformanceOptions: &infrav1.VirtualMachinePerformanceOptions{
+					ExtraConfig: map[string]string{"guestinfo.userdata": ""},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bus sharing of the additional disks with full clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloneMode:                 infrav1.FullClone,
+				AdditionalDisksController: &infrav1.AdditionalDisksControllerSpec{BusSharing: infrav1.SCSIBusSharingPhysical},
+			},
+		},
+		{
+			name: "no bus sharing of the additional disks with linked clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				AdditionalDisksController: &infrav1.AdditionalDisksControllerSpec{BusSharing: infrav1.SCSIBusSharingNone},
+			},
+		},
+		{
+			name: "bus sharing of the additional disks with linked clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloneMode:                 infrav1.LinkedClone,
+				AdditionalDisksController: &infrav1.AdditionalDisksControllerSpec{BusSharing: infrav1.SCSIBusSharingVirtual},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid serial ports",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SerialPorts: []infrav1.SerialPortSpec{
+					{URI: "telnet://logs.example.com:13370"},
+					{URI: "vSPC.py", ProxyURI: "telnets://vspc.example.com:13370"},
+					{File: "[datastore1] logs/console.log"},
+				},
+			},
+		},
+		{
+			name: "serial port without backing",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SerialPorts: []infrav1.SerialPortSpec{{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "serial port with URI and file",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SerialPorts: []infrav1.SerialPortSpec{
+					{URI: "telnet://logs.example.com:13370", File: "[datastore1] logs/console.log"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "serial port with invalid URI",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SerialPorts: []infrav1.SerialPortSpec{{URI: "logs.example.com"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "serial port with invalid file",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SerialPorts: []infrav1.SerialPortSpec{{File: "logs/console.log"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid CD-ROMs",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CDROMs: []infrav1.CDROMSpec{
+					{ISOPath: "[datastore1] isos/drivers.iso"},
+					{},
+				},
+			},
+		},
+		{
+			name: "CD-ROM with invalid ISO path",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CDROMs: []infrav1.CDROMSpec{{ISOPath: "isos/drivers.iso"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid files",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Files: []infrav1.SecretFile{{
+					Path:        "/etc/pki/tls/certs/ca.pem",
+					Permissions: "0600",
+					Secret:      infrav1.SecretFileSource{Name: "ca", Key: "ca.pem"},
+				}},
+			},
+		},
+		{
+			name: "file with relative path",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Files: []infrav1.SecretFile{{
+					Path:   "etc/app.conf",
+					Secret: infrav1.SecretFileSource{Name: "app", Key: "app.conf"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "OVF environment",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OVFEnvironment: &infrav1.OVFEnvironmentSpec{
+					Transport:  infrav1.OVFEnvironmentTransportGuestInfo,
+					Properties: map[string]string{"hostname": "appliance"},
+				},
+			},
+		},
+		{
+			name: "OVF environment with empty property ID",
+			spec: infrav1.VirtualMachineCloneSpec{
+				OVFEnvironment: &infrav1.OVFEnvironmentSpec{
+					Transport:  infrav1.OVFEnvironmentTransportISO,
+					Properties: map[string]string{"": "appliance"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "readiness probe with file path",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ReadinessProbe: &infrav1.GuestReadinessProbe{
+					CredentialsSecretName: "guest-credentials",
+					FilePath:              "/var/run/appliance-ready",
+				},
+			},
+		},
+		{
+			name: "readiness probe with file path and command",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ReadinessProbe: &infrav1.GuestReadinessProbe{
+					CredentialsSecretName: "guest-credentials",
+					FilePath:              "/var/run/appliance-ready",
+					Command:               &infrav1.GuestProbeCommand{Path: "/usr/bin/systemctl", Arguments: "is-active appliance"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "readiness probe without file path and command",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ReadinessProbe: &infrav1.GuestReadinessProbe{
+					CredentialsSecretName: "guest-credentials",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multi-writer shared disk",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SharedDisks: []infrav1.SharedDiskSpec{
+					{DiskPath: "[datastore1] shared/gfs2-data.vmdk", Sharing: infrav1.VirtualDiskSharingMultiWriter, SizeGiB: 10},
+				},
+			},
+		},
+		{
+			name: "shared disk with path which is not a VMDK",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SharedDisks: []infrav1.SharedDiskSpec{
+					{DiskPath: "shared/gfs2-data", Sharing: infrav1.VirtualDiskSharingMultiWriter},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate shared disks",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SharedDisks: []infrav1.SharedDiskSpec{
+					{DiskPath: "[datastore1] shared/gfs2-data.vmdk", Sharing: infrav1.VirtualDiskSharingMultiWriter},
+					{DiskPath: "[datastore1] shared/gfs2-data.vmdk", Sharing: infrav1.VirtualDiskSharingMultiWriter},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "network boot from the provisioning network",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "provisioning"}}},
+				BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: &infrav1.VirtualMachineNetworkBoot{
+					ProvisioningNetworkName: "provisioning",
+					IPXEScript:              "chain http://boot.example.com/${net0/mac}.ipxe",
+					IPXESettings:            map[string]string{"next-server": "10.0.0.1"},
+				}},
+			},
+		},
+		{
+			name: "network boot from a missing network device",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network:     infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "provisioning"}}},
+				BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: &infrav1.VirtualMachineNetworkBoot{DeviceIndex: 1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "network boot from a network device not attached to the provisioning network",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network:     infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "workloads"}}},
+				BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: &infrav1.VirtualMachineNetworkBoot{ProvisioningNetworkName: "provisioning"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "network boot with iPXE scriptlet setting",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "provisioning"}}},
+				BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: &infrav1.VirtualMachineNetworkBoot{
+					IPXESettings: map[string]string{"scriptlet": "chain http://boot.example.com/boot.ipxe"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "network boot with invalid iPXE setting name",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "provisioning"}}},
+				BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: &infrav1.VirtualMachineNetworkBoot{
+					IPXESettings: map[string]string{"next server": "10.0.0.1"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hostname from template",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Hostname: &infrav1.HostnameSpec{Source: infrav1.HostnameSourceTemplate, Template: "node-{{ index .Octets 3 }}.example.com"},
+			},
+		},
+		{
+			name: "hostname from template without template",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Hostname: &infrav1.HostnameSpec{Source: infrav1.HostnameSourceTemplate},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hostname from invalid template",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Hostname: &infrav1.HostnameSpec{Source: infrav1.HostnameSourceTemplate, Template: "node-{{ .IP "},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hostname from reverse DNS",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Hostname: &infrav1.HostnameSpec{Source: infrav1.HostnameSourceReverseDNS},
+			},
+		},
+		{
+			name: "hostname from reverse DNS with template",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Hostname: &infrav1.HostnameSpec{Source: infrav1.HostnameSourceReverseDNS, Template: "node-{{ .IP }}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hostname with NoCloud datasource",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloudInitDatasource: infrav1.CloudInitDatasourceNoCloud,
+				Hostname:            &infrav1.HostnameSpec{Source: infrav1.HostnameSourceReverseDNS},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sriov adapter with physical function",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{
+					{NetworkName: "sriov-net", AdapterType: infrav1.NetworkAdapterTypeSriov, PhysicalFunction: "0000:3b:00.1"},
+				}},
+			},
+		},
+		{
+			name: "sriov adapter without memory reservation locked to max",
+			spec: infrav1.VirtualMachineCloneSpec{
+				MemoryReservationLockedToMax: ptr.To(false),
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{
+					{NetworkName: "sriov-net", AdapterType: infrav1.NetworkAdapterTypeSriov, PhysicalFunction: "vmnic4"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sriov adapter without physical function",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{
+					{NetworkName: "sriov-net", AdapterType: infrav1.NetworkAdapterTypeSriov},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "physical function without sriov adapter",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{
+					{NetworkName: "vm-net", AdapterType: infrav1.NetworkAdapterTypeE1000e, PhysicalFunction: "0000:3b:00.1"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid resource allocation",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPU: &infrav1.ResourceAllocationSpec{
+						Reservation: ptr.To[int64](1000),
+						Limit:       ptr.To[int64](-1),
+						Shares:      &infrav1.ResourceShares{Level: infrav1.SharesLevelHigh},
+					},
+					Memory: &infrav1.ResourceAllocationSpec{
+						Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelCustom, Shares: 40960},
+					},
+				},
+			},
+		},
+		{
+			name: "resource allocation with limit less than reservation",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					Memory: &infrav1.ResourceAllocationSpec{
+						Reservation: ptr.To[int64](4096),
+						Limit:       ptr.To[int64](2048),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom shares without number of shares",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPU: &infrav1.ResourceAllocationSpec{
+						Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelCustom},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "number of shares without custom level",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPU: &infrav1.ResourceAllocationSpec{
+						Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelLow, Shares: 500},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid CPU reservation percentage",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPU:                   &infrav1.ResourceAllocationSpec{Limit: ptr.To[int64](-1)},
+					CPUReservationPercent: ptr.To[int32](50),
+				},
+			},
+		},
+		{
+			name: "CPU reservation percentage above 100",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPUReservationPercent: ptr.To[int32](150),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CPU reservation percentage with CPU reservation",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					CPU:                   &infrav1.ResourceAllocationSpec{Reservation: ptr.To[int64](1000)},
+					CPUReservationPercent: ptr.To[int32](50),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid disk storage I/O allocation",
+			spec: infrav1.VirtualMachineCloneSpec{
+				DiskStorageIOAllocation: &infrav1.DiskStorageIOAllocation{
+					Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelHigh},
+					Limit:  ptr.To[int64](5000),
+				},
+			},
+		},
+		{
+			name: "disk storage I/O allocation with limit below minimum",
+			spec: infrav1.VirtualMachineCloneSpec{
+				DiskStorageIOAllocation: &infrav1.DiskStorageIOAllocation{
+					Limit: ptr.To[int64](8),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "disk storage I/O allocation with custom shares without number of shares",
+			spec: infrav1.VirtualMachineCloneSpec{
+				DiskStorageIOAllocation: &infrav1.DiskStorageIOAllocation{
+					Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelCustom},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "NoCloud datasource with linked clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloudInitDatasource: infrav1.CloudInitDatasourceNoCloud,
+				CloneMode:           infrav1.LinkedClone,
+			},
+		},
+		{
+			name: "NoCloud datasource with instant clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloudInitDatasource: infrav1.CloudInitDatasourceNoCloud,
+				CloneMode:           infrav1.InstantClone,
+			},
+			wantErr: true,
+		},
+		{
+			name: "VMware datasource with instant clone",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CloudInitDatasource: infrav1.CloudInitDatasourceVMware,
+				CloneMode:           infrav1.InstantClone,
+			},
+		},
+		{
+			name: "secure boot with efi firmware",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Firmware:   infrav1.VirtualMachineFirmwareEFI,
+				SecureBoot: ptr.To(true),
+			},
+		},
+		{
+			name: "secure boot with bios firmware",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Firmware:   infrav1.VirtualMachineFirmwareBIOS,
+				SecureBoot: ptr.To(true),
+			},
+			wantErr: true,
+		},
+		{
+			name: "trusted launch with consistent individual settings",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch: ptr.To(true),
+				Firmware:      infrav1.VirtualMachineFirmwareEFI,
+				VirtualTPM:    ptr.To(true),
+			},
+		},
+		{
+			name: "trusted launch with bios firmware",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch: ptr.To(true),
+				Firmware:      infrav1.VirtualMachineFirmwareBIOS,
+			},
+			wantErr: true,
+		},
+		{
+			name: "trusted launch with secure boot disabled",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch: ptr.To(true),
+				SecureBoot:    ptr.To(false),
+			},
+			wantErr: true,
+		},
+		{
+			name: "trusted launch with virtual TPM disabled",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch: ptr.To(true),
+				VirtualTPM:    ptr.To(false),
+			},
+			wantErr: true,
+		},
+		{
+			name: "trusted launch disabled with individual settings",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch: ptr.To(false),
+				Firmware:      infrav1.VirtualMachineFirmwareBIOS,
+				VirtualTPM:    ptr.To(true),
+			},
+		},
+		{
+			name: "valid custom attributes",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CustomAttributes: map[string]string{"owner": "team-a", "cost center": ""},
+			},
+		},
+		{
+			name: "custom attribute with empty name",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CustomAttributes: map[string]string{"": "team-a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom attribute with leading whitespace",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CustomAttributes: map[string]string{" owner": "team-a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom attribute with numeric name",
+			spec: infrav1.VirtualMachineCloneSpec{
+				CustomAttributes: map[string]string{"101": "team-a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "storage affinity with storage policy",
+			spec: infrav1.VirtualMachineCloneSpec{
+				StoragePolicyName: "vSAN Default Storage Policy",
+				StorageAffinity: &infrav1.StorageAffinitySpec{
+					ComputeCluster: "cluster0",
+					VMGroupName:    "vm-group",
+					HostGroupName:  "host-group",
+				},
+			},
+		},
+		{
+			name: "storage affinity without storage policy",
+			spec: infrav1.VirtualMachineCloneSpec{
+				StorageAffinity: &infrav1.StorageAffinitySpec{
+					ComputeCluster: "cluster0",
+					VMGroupName:    "vm-group",
+					HostGroupName:  "host-group",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "one primary network device",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{
+					Devices: []infrav1.NetworkDeviceSpec{
+						{NetworkName: "nw-1", DHCP4: true},
+						{NetworkName: "nw-2", DHCP4: true, Primary: true},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple primary network devices",
+			spec: infrav1.VirtualMachineCloneSpec{
+				Network: infrav1.NetworkSpec{
+					Devices: []infrav1.NetworkDeviceSpec{
+						{NetworkName: "nw-1", DHCP4: true, Primary: true},
+						{NetworkName: "nw-2", DHCP4: true, Primary: true},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "maximum provisioning time",
+			spec: infrav1.VirtualMachineCloneSpec{
+				MaxProvisioningTime:       &metav1.Duration{Duration: 30 * time.Minute},
+				ProvisioningStalledPolicy: infrav1.ProvisioningStalledPolicyFail,
+			},
+		},
+		{
+			name: "zero maximum provisioning time",
+			spec: infrav1.VirtualMachineCloneSpec{
+				MaxProvisioningTime: &metav1.Duration{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "provisioning stalled policy without maximum provisioning time",
+			spec: infrav1.VirtualMachineCloneSpec{
+				ProvisioningStalledPolicy: infrav1.ProvisioningStalledPolicyFail,
+			},
+			wantErr: true,
+		},
+		{
+			name: "secure boot failure policy",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SecureBoot:               ptr.To(true),
+				SecureBootFailureTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+				SecureBootFailurePolicy:  infrav1.SecureBootFailurePolicyDisable,
+			},
+		},
+		{
+			name: "zero secure boot failure timeout",
+			spec: infrav1.VirtualMachineCloneSpec{
+				SecureBootFailureTimeout: &metav1.Duration{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "secure boot failure policy disable with trusted launch",
+			spec: infrav1.VirtualMachineCloneSpec{
+				TrustedLaunch:           ptr.To(true),
+				SecureBootFailurePolicy: infrav1.SecureBootFailurePolicyDisable,
+			},
+			wantErr: true,
+		},
+		{
+			name: "NUMA node affinity",
+			spec: infrav1.VirtualMachineCloneSpec{
+				NUMANodeAffinity: []int32{0, 1},
+			},
+		},
+		{
+			name: "negative NUMA node",
+			spec: infrav1.VirtualMachineCloneSpec{
+				NUMANodeAffinity: []int32{-1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate NUMA nodes",
+			spec: infrav1.VirtualMachineCloneSpec{
+				NUMANodeAffinity: []int32{1, 1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			errs := validateVirtualMachineCloneSpec(tc.spec, field.NewPath("spec"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestVirtualMachineCloneSpecWarnings(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(virtualMachineCloneSpecWarnings(infrav1.VirtualMachineCloneSpec{})).To(BeEmpty())
+	g.Expect(virtualMachineCloneSpecWarnings(infrav1.VirtualMachineCloneSpec{NUMANodeAffinity: []int32{0}})).To(ConsistOf(ContainSubstring("vMotion")))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-vspherecluster,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=vsphereclusters,versions=v1beta1,name=validation.vspherecluster.infrastructure.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+// VSphereClusterWebhook implements a validation webhook for VSphereCluster.
+type VSphereClusterWebhook struct{}
+
+var _ webhook.CustomValidator = &VSphereClusterWebhook{}
+
+func (webhook *VSphereClusterWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&infrav1.VSphereCluster{}).
+		WithValidator(webhook).
+		Complete()
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (webhook *VSphereClusterWebhook) ValidateCreate(_ context.Context, raw runtime.Object) (admission.Warnings, error) {
+	obj, ok := raw.(*infrav1.VSphereCluster)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a VSphereCluster but got a %T", raw))
+	}
+
+	allErrs := validateVSphereClusterSpec(obj.Spec, field.NewPath("spec"))
+	if obj.Spec.ControlPlaneEndpointAddressFromPool != nil && obj.Spec.ControlPlaneEndpoint.Host != "" {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "controlPlaneEndpoint", "host"), "cannot be set together with controlPlaneEndpointAddressFromPool"))
+	}
+	return nil, aggregateObjErrors(obj.GroupVersionKind().GroupKind(), obj.Name, allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (webhook *VSphereClusterWebhook) ValidateUpdate(_ context.Context, oldRaw runtime.Object, newRaw runtime.Object) (admission.Warnings, error) {
+	oldTyped, ok := oldRaw.(*infrav1.VSphereCluster)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a VSphereCluster but got a %T", oldRaw))
+	}
+	newTyped, ok := newRaw.(*infrav1.VSphereCluster)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a VSphereCluster but got a %T", newRaw))
+	}
+
+	allErrs := validateVSphereClusterSpec(newTyped.Spec, field.NewPath("spec"))
+	// The host of the control plane endpoint is set from the IPAddressClaim,
+	// so the pool it is claimed from cannot be changed afterwards.
+	if !apiequality.Semantic.DeepEqual(oldTyped.Spec.ControlPlaneEndpointAddressFromPool, newTyped.Spec.ControlPlaneEndpointAddressFromPool) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "controlPlaneEndpointAddressFromPool"), "cannot be modified"))
+	}
+	return nil, aggregateObjErrors(newTyped.GroupVersionKind().GroupKind(), newTyped.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (webhook *VSphereClusterWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateVSphereClusterSpec validates the fields of the VSphereClusterSpec
+// which is shared by VSphereCluster and VSphereClusterTemplate.
+func validateVSphereClusterSpec(spec infrav1.VSphereClusterSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, key := range spec.SSHAuthorizedKeys {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key)); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sshAuthorizedKeys").Index(i), key, "should be a valid SSH authorized key"))
+		}
+	}
+
+	if spec.Proxy != nil {
+		proxyPath := fldPath.Child("proxy")
+		if spec.Proxy.HTTPProxy != "" && !isProxyURL(spec.Proxy.HTTPProxy) {
+			allErrs = append(allErrs, field.Invalid(proxyPath.Child("httpProxy"), spec.Proxy.HTTPProxy, "should be a valid HTTP or HTTPS URL"))
+		}
+		if spec.Proxy.HTTPSProxy != "" && !isProxyURL(spec.Proxy.HTTPSProxy) {
+			allErrs = append(allErrs, field.Invalid(proxyPath.Child("httpsProxy"), spec.Proxy.HTTPSProxy, "should be a valid HTTP or HTTPS URL"))
+		}
+		for i, noProxy := range spec.Proxy.NoProxy {
+			if noProxy == "" || strings.ContainsAny(noProxy, ", \t\n\"") {
+				allErrs = append(allErrs, field.Invalid(proxyPath.Child("noProxy").Index(i), noProxy, "should be a hostname, domain, IP address or CIDR"))
+			}
+		}
+	}
+
+	for i, mirror := range spec.RegistryMirrors {
+		allErrs = append(allErrs, validateRegistryMirror(mirror, fldPath.Child("registryMirrors").Index(i))...)
+	}
+
+	if poolRef := spec.ControlPlaneEndpointAddressFromPool; poolRef != nil {
+		poolPath := fldPath.Child("controlPlaneEndpointAddressFromPool")
+		if poolRef.APIGroup == nil || *poolRef.APIGroup == "" {
+			allErrs = append(allErrs, field.Required(poolPath.Child("apiGroup"), "should be the API group of the IP address pool"))
+		}
+		if poolRef.Kind == "" {
+			allErrs = append(allErrs, field.Required(poolPath.Child("kind"), "should be the kind of the IP address pool"))
+		}
+		if poolRef.Name == "" {
+			allErrs = append(allErrs, field.Required(poolPath.Child("name"), "should be the name of the IP address pool"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRegistryMirror validates the registry, the URLs of the mirrors and the reference
+// to the CA certificate of a registry mirror.
+func validateRegistryMirror(mirror infrav1.RegistryMirror, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if u, err := url.Parse("//" + mirror.Registry); err != nil || u.Host != mirror.Registry || u.Hostname() == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("registry"), mirror.Registry, "should be a host with an optional port, e.g. docker.io"))
+	}
+	for i, endpoint := range mirror.Endpoints {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("endpoints").Index(i), endpoint, "should be a valid HTTP or HTTPS URL"))
+		}
+	}
+	if mirror.CASecretName != "" {
+		if errs := validation.IsDNS1123Subdomain(mirror.CASecretName); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("caSecretName"), mirror.CASecretName, strings.Join(errs, ", ")))
+		}
+	}
+	if len(mirror.Endpoints) == 0 && mirror.CASecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath, "should define endpoints or a caSecretName"))
+	}
+
+	return allErrs
+}
+
+// isProxyURL returns true if the given URL is a valid URL of an HTTP proxy.
+func isProxyURL(proxyURL string) bool {
+	u, err := url.Parse(proxyURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
@@ -22,22 +22,33 @@ import (
 	"net"
 	"reflect"
 
+	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-vspheremachine,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines,versions=v1beta1,name=validation.vspheremachine.infrastructure.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-vspheremachine,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines,versions=v1beta1,name=default.vspheremachine.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
 
 // VSphereMachineWebhook implements a validation and defaulting webhook for VSphereMachine.
-type VSphereMachineWebhook struct{}
+type VSphereMachineWebhook struct {
+	// Client is used to get the Machine, MachineDeployment or Cluster of a VSphereMachine and
+	// the VSphereMachineClass it references. The Kubernetes version of the template and the
+	// existence of the class are not validated without it.
+	Client client.Client
+}
 
 var _ webhook.CustomValidator = &VSphereMachineWebhook{}
 var _ webhook.CustomDefaulter = &VSphereMachineWebhook{}
@@ -63,7 +74,7 @@ func (webhook *VSphereMachineWebhook) Default(_ context.Context, obj runtime.Obj
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (webhook *VSphereMachineWebhook) ValidateCreate(_ context.Context, raw runtime.Object) (admission.Warnings, error) {
+func (webhook *VSphereMachineWebhook) ValidateCreate(ctx context.Context, raw runtime.Object) (admission.Warnings, error) {
 	var allErrs field.ErrorList
 
 	obj, ok := raw.(*infrav1.VSphereMachine)
@@ -93,11 +104,15 @@ func (webhook *VSphereMachineWebhook) ValidateCreate(_ context.Context, raw runt
 		}
 	}
 
-	return nil, aggregateObjErrors(obj.GroupVersionKind().GroupKind(), obj.Name, allErrs)
+	allErrs = append(allErrs, validateVirtualMachineCloneSpec(spec.VirtualMachineCloneSpec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateClassName(ctx, webhook.Client, spec, field.NewPath("spec"))...)
+
+	warnings := append(webhook.templateKubernetesVersionWarnings(ctx, obj), virtualMachineCloneSpecWarnings(spec.VirtualMachineCloneSpec)...)
+	return warnings, aggregateObjErrors(obj.GroupVersionKind().GroupKind(), obj.Name, allErrs)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (webhook *VSphereMachineWebhook) ValidateUpdate(_ context.Context, oldRaw runtime.Object, newRaw runtime.Object) (admission.Warnings, error) {
+func (webhook *VSphereMachineWebhook) ValidateUpdate(ctx context.Context, oldRaw runtime.Object, newRaw runtime.Object) (admission.Warnings, error) {
 	var allErrs field.ErrorList
 
 	newTyped, ok := newRaw.(*infrav1.VSphereMachine)
@@ -126,7 +141,15 @@ func (webhook *VSphereMachineWebhook) ValidateUpdate(_ context.Context, oldRaw r
 	newVSphereMachineSpec := newVSphereMachine["spec"].(map[string]interface{})
 	oldVSphereMachineSpec := oldVSphereMachine["spec"].(map[string]interface{})
 
-	allowChangeKeys := []string{"providerID", "powerOffMode", "guestSoftPowerOffTimeout"}
+	allowChangeKeys := []string{"providerID", "powerOffMode", "guestSoftPowerOffTimeout", "reconfigurePolicy", "customAttributes", "powerOnAfterClone", "questionPolicy", "resourceDriftPolicy"}
+	// Allow changes to the CPUs and memory if they are applied by the reconfigure policy.
+	if newTyped.Spec.ReconfigurePolicy != "" {
+		allowChangeKeys = append(allowChangeKeys, "numCPUs", "numCoresPerSocket", "memoryMiB")
+	}
+	// Allow changes to the folder if the VMs are moved into the new folder.
+	if feature.Gates.Enabled(feature.VMFolderMove) {
+		allowChangeKeys = append(allowChangeKeys, "folder")
+	}
 	for _, key := range allowChangeKeys {
 		delete(oldVSphereMachineSpec, key)
 		delete(newVSphereMachineSpec, key)
@@ -153,10 +176,104 @@ func (webhook *VSphereMachineWebhook) ValidateUpdate(_ context.Context, oldRaw r
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "cannot be modified"))
 	}
 
-	return nil, aggregateObjErrors(newTyped.GroupVersionKind().GroupKind(), newTyped.Name, allErrs)
+	return webhook.templateKubernetesVersionWarnings(ctx, newTyped), aggregateObjErrors(newTyped.GroupVersionKind().GroupKind(), newTyped.Name, allErrs)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
 func (webhook *VSphereMachineWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
+
+// validateClassName validates that the VSphereMachineClass referenced by the spec exists and the
+// spec does not define the CPU and memory of the class. The existence of the class is not
+// validated without a client.
+func validateClassName(ctx context.Context, c client.Client, spec infrav1.VSphereMachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.ClassName == "" {
+		return allErrs
+	}
+
+	const msg = "cannot be set if className is set"
+	if spec.NumCPUs != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("numCPUs"), msg))
+	}
+	if spec.NumCoresPerSocket != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("numCoresPerSocket"), msg))
+	}
+	if spec.MemoryMiB != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("memoryMiB"), msg))
+	}
+	if spec.MemoryReservationLockedToMax != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("memoryReservationLockedToMax"), msg))
+	}
+	if spec.ResourceAllocation != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("resourceAllocation"), msg))
+	}
+
+	if c == nil {
+		return allErrs
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: spec.ClassName}, &infrav1.VSphereMachineClass{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("className"), spec.ClassName))
+		} else {
+			allErrs = append(allErrs, field.InternalError(fldPath.Child("className"), err))
+		}
+	}
+	return allErrs
+}
+
+// templateKubernetesVersionWarnings returns a warning if the Kubernetes version the VSphereMachine
+// is annotated with differs from the version it is created for, as the node likely fails to join
+// the cluster. It is a warning rather than an error to allow intentional version skew.
+func (webhook *VSphereMachineWebhook) templateKubernetesVersionWarnings(ctx context.Context, vsphereMachine *infrav1.VSphereMachine) admission.Warnings {
+	templateVersion, ok := vsphereMachine.Annotations[infrav1.TemplateKubernetesVersionAnnotation]
+	if !ok || webhook.Client == nil {
+		return nil
+	}
+	parsedTemplateVersion, err := semver.ParseTolerant(templateVersion)
+	if err != nil {
+		return admission.Warnings{fmt.Sprintf("annotation %s has an invalid Kubernetes version %q", infrav1.TemplateKubernetesVersionAnnotation, templateVersion)}
+	}
+
+	version, source := webhook.kubernetesVersion(ctx, vsphereMachine)
+	if version == "" {
+		return nil
+	}
+	parsedVersion, err := semver.ParseTolerant(version)
+	if err != nil {
+		return nil
+	}
+
+	if parsedTemplateVersion.Major != parsedVersion.Major || parsedTemplateVersion.Minor != parsedVersion.Minor || parsedTemplateVersion.Patch != parsedVersion.Patch {
+		return admission.Warnings{fmt.Sprintf("the Kubernetes version %s of the template differs from the version %s of %s, the node may fail to join the cluster", templateVersion, version, source)}
+	}
+	return nil
+}
+
+// kubernetesVersion returns the Kubernetes version the VSphereMachine is created for and the kind
+// and name of the object defining it. VSphereMachines are created before the Machine which owns
+// them, so until then the version is taken from the MachineDeployment or the topology of the
+// Cluster the VSphereMachine is labeled with. The version is empty if it is not known, e.g. for
+// control plane machines of clusters without a topology.
+func (webhook *VSphereMachineWebhook) kubernetesVersion(ctx context.Context, vsphereMachine *infrav1.VSphereMachine) (string, string) {
+	machine, err := util.GetOwnerMachine(ctx, webhook.Client, vsphereMachine.ObjectMeta)
+	if err == nil && machine != nil {
+		return ptr.Deref(machine.Spec.Version, ""), "Machine " + machine.Name
+	}
+
+	if name, ok := vsphereMachine.Labels[clusterv1.MachineDeploymentNameLabel]; ok {
+		machineDeployment := &clusterv1.MachineDeployment{}
+		if err := webhook.Client.Get(ctx, client.ObjectKey{Namespace: vsphereMachine.Namespace, Name: name}, machineDeployment); err == nil {
+			return ptr.Deref(machineDeployment.Spec.Template.Spec.Version, ""), "MachineDeployment " + machineDeployment.Name
+		}
+	}
+
+	if name, ok := vsphereMachine.Labels[clusterv1.ClusterNameLabel]; ok {
+		cluster := &clusterv1.Cluster{}
+		if err := webhook.Client.Get(ctx, client.ObjectKey{Namespace: vsphereMachine.Namespace, Name: name}, cluster); err == nil && cluster.Spec.Topology != nil {
+			return cluster.Spec.Topology.Version, "Cluster " + cluster.Name
+		}
+	}
+	return "", ""
+}
@@ -22,8 +22,13 @@ import (
 
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
 )
 
 var someProviderID = "vsphere://42305f0b-dad7-1d3d-5727-0eaffffffffc"
@@ -161,6 +166,30 @@ func TestVSphereMachine_ValidateUpdate(t *testing.T) {
 			vsphereMachine:    createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeHard, nil),
 			wantErr:           false,
 		},
+		{
+			name:              "CPUs and memory can be updated with reconfigure policy deferredUntilPowerOff",
+			oldVSphereMachine: withMachineHardware(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), "", 2, 4096),
+			vsphereMachine:    withMachineHardware(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), infrav1.ReconfigurePolicyDeferredUntilPowerOff, 4, 8192),
+			wantErr:           false,
+		},
+		{
+			name:              "CPUs and memory cannot be updated without reconfigure policy",
+			oldVSphereMachine: withMachineHardware(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), "", 2, 4096),
+			vsphereMachine:    withMachineHardware(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), "", 4, 8192),
+			wantErr:           true,
+		},
+		{
+			name:              "custom attributes can be updated",
+			oldVSphereMachine: createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil),
+			vsphereMachine:    withMachineCustomAttributes(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), map[string]string{"owner": "team-a"}),
+			wantErr:           false,
+		},
+		{
+			name:              "powerOnAfterClone can be updated",
+			oldVSphereMachine: withMachinePowerOnAfterClone(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), false),
+			vsphereMachine:    withMachinePowerOnAfterClone(createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeSoft, nil), true),
+			wantErr:           false,
+		},
 		{
 			name:              "powerOffMode can be updated to soft",
 			oldVSphereMachine: createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeTrySoft, &metav1.Duration{Duration: infrav1.GuestSoftPowerOffDefaultTimeout}),
@@ -181,6 +210,22 @@ func TestVSphereMachine_ValidateUpdate(t *testing.T) {
 	}
 }
 
+func TestVSphereMachine_ValidateUpdate_Folder(t *testing.T) {
+	g := NewWithT(t)
+	webhook := &VSphereMachineWebhook{}
+	oldVSphereMachine := createVSphereMachine("foo.com", &someProviderID, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeTrySoft, nil)
+	vsphereMachine := oldVSphereMachine.DeepCopy()
+	vsphereMachine.Spec.Folder = "/DC0/vm/moved"
+
+	_, err := webhook.ValidateUpdate(context.Background(), oldVSphereMachine, vsphereMachine)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(feature.MutableGates.Set("VMFolderMove=true")).To(Succeed())
+	t.Cleanup(func() { _ = feature.MutableGates.Set("VMFolderMove=false") })
+	_, err = webhook.ValidateUpdate(context.Background(), oldVSphereMachine, vsphereMachine)
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
 func createVSphereMachine(server string, providerID *string, preferredAPIServerCIDR string, ips []string, powerOffMode infrav1.VirtualMachinePowerOpMode, guestSoftPowerOffTimeout *metav1.Duration) *infrav1.VSphereMachine {
 	VSphereMachine := &infrav1.VSphereMachine{
 		Spec: infrav1.VSphereMachineSpec{
@@ -203,3 +248,123 @@ func createVSphereMachine(server string, providerID *string, preferredAPIServerC
 	}
 	return VSphereMachine
 }
+
+func withMachineHardware(machine *infrav1.VSphereMachine, reconfigurePolicy infrav1.VirtualMachineReconfigurePolicy, numCPUs int32, memoryMiB int64) *infrav1.VSphereMachine {
+	machine.Spec.ReconfigurePolicy = reconfigurePolicy
+	machine.Spec.NumCPUs = numCPUs
+	machine.Spec.MemoryMiB = memoryMiB
+	return machine
+}
+
+func withMachinePowerOnAfterClone(machine *infrav1.VSphereMachine, powerOnAfterClone bool) *infrav1.VSphereMachine {
+	machine.Spec.PowerOnAfterClone = &powerOnAfterClone
+	return machine
+}
+
+func withMachineCustomAttributes(machine *infrav1.VSphereMachine, customAttributes map[string]string) *infrav1.VSphereMachine {
+	machine.Spec.CustomAttributes = customAttributes
+	return machine
+}
+
+func TestVSphereMachine_TemplateKubernetesVersionWarnings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+		Spec:       clusterv1.MachineSpec{Version: ptr.To("v1.29.0")},
+	}
+	machineDeployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "md-1", Namespace: "default"},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{Version: ptr.To("v1.29.0")}},
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{Version: "v1.29.0"},
+		},
+	}
+	newVSphereMachine := func(templateVersion string, owned bool, labels map[string]string) *infrav1.VSphereMachine {
+		vsphereMachine := createVSphereMachine("foo.com", nil, "", []string{"192.168.0.1/32"}, infrav1.VirtualMachinePowerOpModeHard, nil)
+		vsphereMachine.Namespace = "default"
+		if templateVersion != "" {
+			vsphereMachine.Annotations = map[string]string{infrav1.TemplateKubernetesVersionAnnotation: templateVersion}
+		}
+		if owned {
+			vsphereMachine.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       machine.Name,
+			}}
+		}
+		vsphereMachine.Labels = labels
+		return vsphereMachine
+	}
+
+	tests := []struct {
+		name           string
+		vsphereMachine *infrav1.VSphereMachine
+		wantWarning    bool
+	}{
+		{
+			name:           "without annotation",
+			vsphereMachine: newVSphereMachine("", true, nil),
+		},
+		{
+			name:           "without owner Machine",
+			vsphereMachine: newVSphereMachine("v1.28.3", false, nil),
+		},
+		{
+			name:           "matching version",
+			vsphereMachine: newVSphereMachine("v1.29.0+vmware.1", true, nil),
+		},
+		{
+			name:           "mismatching version",
+			vsphereMachine: newVSphereMachine("v1.28.3", true, nil),
+			wantWarning:    true,
+		},
+		{
+			name:           "invalid version",
+			vsphereMachine: newVSphereMachine("latest", false, nil),
+			wantWarning:    true,
+		},
+		{
+			name:           "mismatching version of the MachineDeployment before a Machine owns the VSphereMachine",
+			vsphereMachine: newVSphereMachine("v1.28.3", false, map[string]string{clusterv1.MachineDeploymentNameLabel: machineDeployment.Name}),
+			wantWarning:    true,
+		},
+		{
+			name:           "matching version of the MachineDeployment before a Machine owns the VSphereMachine",
+			vsphereMachine: newVSphereMachine("v1.29.0", false, map[string]string{clusterv1.MachineDeploymentNameLabel: machineDeployment.Name}),
+		},
+		{
+			name:           "mismatching version of the Cluster topology before a Machine owns the VSphereMachine",
+			vsphereMachine: newVSphereMachine("v1.28.3", false, map[string]string{clusterv1.ClusterNameLabel: cluster.Name}),
+			wantWarning:    true,
+		},
+		{
+			name:           "without the MachineDeployment the VSphereMachine is labeled with",
+			vsphereMachine: newVSphereMachine("v1.28.3", false, map[string]string{clusterv1.MachineDeploymentNameLabel: "md-2"}),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			webhook := &VSphereMachineWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine.DeepCopy(), machineDeployment.DeepCopy(), cluster.DeepCopy()).Build()}
+
+			warnings, err := webhook.ValidateCreate(context.Background(), tc.vsphereMachine)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.wantWarning {
+				g.Expect(warnings).To(HaveLen(1))
+			} else {
+				g.Expect(warnings).To(BeEmpty())
+			}
+
+			updateWarnings, err := webhook.ValidateUpdate(context.Background(), tc.vsphereMachine, tc.vsphereMachine)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(updateWarnings).To(Equal(warnings))
+		})
+	}
+}
@@ -22,7 +22,10 @@ import (
 
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
@@ -145,6 +148,55 @@ func TestVSphereMachineTemplate_ValidateUpdate(t *testing.T) {
 	}
 }
 
+func TestVSphereMachineTemplate_ValidateCreate_ClassName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	machineClass := &infrav1.VSphereMachineClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "medium"},
+		Spec:       infrav1.VSphereMachineClassSpec{NumCPUs: 4, MemoryMiB: 8192},
+	}
+
+	withClass := func(className string, numCPUs int32) *infrav1.VSphereMachineTemplate {
+		template := createVSphereMachineTemplate("foo.com", "", nil, "", []string{})
+		template.Spec.Template.Spec.ClassName = className
+		template.Spec.Template.Spec.NumCPUs = numCPUs
+		return template
+	}
+
+	tests := []struct {
+		name     string
+		template *infrav1.VSphereMachineTemplate
+		wantErr  string
+	}{
+		{
+			name:     "existing class",
+			template: withClass("medium", 0),
+		},
+		{
+			name:     "missing class",
+			template: withClass("large", 0),
+			wantErr:  "spec.template.spec.className: Not found: \"large\"",
+		},
+		{
+			name:     "class with numCPUs",
+			template: withClass("medium", 4),
+			wantErr:  "spec.template.spec.numCPUs: Forbidden: cannot be set if className is set",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			webhook := &VSphereMachineTemplateWebhook{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(machineClass.DeepCopy()).Build()}
+			_, err := webhook.ValidateCreate(context.Background(), tc.template)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func createVSphereMachineTemplate(server, hwVersion string, providerID *string, preferredAPIServerCIDR string, ips []string) *infrav1.VSphereMachineTemplate {
 	vsphereMachineTemplate := &infrav1.VSphereMachineTemplate{
 		Spec: infrav1.VSphereMachineTemplateSpec{
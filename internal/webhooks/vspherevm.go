@@ -31,6 +31,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-vspherevm,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=vspherevms,versions=v1beta1,name=validation.vspherevm.infrastructure.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
@@ -87,6 +89,9 @@ func (webhook *VSphereVMWebhook) ValidateCreate(_ context.Context, raw runtime.O
 	if objValue.Spec.OS == infrav1.Windows && len(objValue.Name) > 15 {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("name"), objValue.Name, "name has to be less than 16 characters for Windows VM"))
 	}
+	if spec.CloneConflictPolicy == infrav1.CloneConflictPolicySuffix && len(objValue.Name)+infrav1.CloneConflictSuffixLength > infrav1.MaxVirtualMachineNameLength {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("name"), objValue.Name, fmt.Sprintf("name has to be less than %d characters when the cloneConflictPolicy is suffix", infrav1.MaxVirtualMachineNameLength-infrav1.CloneConflictSuffixLength+1)))
+	}
 	if spec.GuestSoftPowerOffTimeout != nil {
 		if spec.PowerOffMode != infrav1.VirtualMachinePowerOpModeTrySoft {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "guestSoftPowerOffTimeout"), spec.GuestSoftPowerOffTimeout, "should not be set in templates unless the powerOffMode is trySoft"))
@@ -95,7 +100,11 @@ func (webhook *VSphereVMWebhook) ValidateCreate(_ context.Context, raw runtime.O
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "guestSoftPowerOffTimeout"), spec.GuestSoftPowerOffTimeout, "should be greater than 0"))
 		}
 	}
-	return nil, aggregateObjErrors(objValue.GroupVersionKind().GroupKind(), objValue.Name, allErrs)
+
+	allErrs = append(allErrs, validateVirtualMachineCloneSpec(spec.VirtualMachineCloneSpec, field.NewPath("spec"))...)
+
+	warnings := append(powerOffWarnings(nil, objValue), virtualMachineCloneSpecWarnings(spec.VirtualMachineCloneSpec)...)
+	return warnings, aggregateObjErrors(objValue.GroupVersionKind().GroupKind(), objValue.Name, allErrs)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
@@ -131,8 +140,16 @@ func (webhook *VSphereVMWebhook) ValidateUpdate(_ context.Context, oldRaw runtim
 	newVSphereVMSpec := newVSphereVM["spec"].(map[string]interface{})
 	oldVSphereVMSpec := oldVSphereVM["spec"].(map[string]interface{})
 
-	// Allow changes to bootstrapRef, thumbprint, powerOffMode, guestSoftPowerOffTimeout.
-	keys := []string{"bootstrapRef", "thumbprint", "powerOffMode", "guestSoftPowerOffTimeout"}
+	// Allow changes to bootstrapRef, thumbprint, powerOffMode, guestSoftPowerOffTimeout, reconfigurePolicy, customAttributes, powerOnAfterClone, questionPolicy, desiredPowerState, resourceDriftPolicy.
+	keys := []string{"bootstrapRef", "thumbprint", "powerOffMode", "guestSoftPowerOffTimeout", "reconfigurePolicy", "customAttributes", "powerOnAfterClone", "questionPolicy", "desiredPowerState", "resourceDriftPolicy", "drainingDatastores"}
+	// Allow changes to the CPUs and memory if they are applied by the reconfigure policy.
+	if newTyped.Spec.ReconfigurePolicy != "" {
+		keys = append(keys, "numCPUs", "numCoresPerSocket", "memoryMiB")
+	}
+	// Allow changes to the folder if the VM is moved into the new folder.
+	if feature.Gates.Enabled(feature.VMFolderMove) {
+		keys = append(keys, "folder")
+	}
 	// Allow changes to os only if the old spec has empty OS field.
 	if oldTyped.Spec.OS == "" {
 		keys = append(keys, "os")
@@ -141,6 +158,11 @@ func (webhook *VSphereVMWebhook) ValidateUpdate(_ context.Context, oldRaw runtim
 	if oldTyped.Spec.BiosUUID == "" {
 		keys = append(keys, "biosUUID")
 	}
+	// Allow changes to the template if the VM is redeployed from the new template. The clone
+	// of the new template may get a new biosUUID.
+	if _, ok := newTyped.Annotations[infrav1.RedeployAnnotation]; ok {
+		keys = append(keys, "template", "biosUUID")
+	}
 	webhook.deleteSpecKeys(oldVSphereVMSpec, keys)
 	webhook.deleteSpecKeys(newVSphereVMSpec, keys)
 
@@ -156,7 +178,7 @@ func (webhook *VSphereVMWebhook) ValidateUpdate(_ context.Context, oldRaw runtim
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "cannot be modified"))
 	}
 
-	return nil, aggregateObjErrors(newTyped.GroupVersionKind().GroupKind(), newTyped.Name, allErrs)
+	return powerOffWarnings(oldTyped, newTyped), aggregateObjErrors(newTyped.GroupVersionKind().GroupKind(), newTyped.Name, allErrs)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -164,6 +186,18 @@ func (webhook *VSphereVMWebhook) ValidateDelete(_ context.Context, _ runtime.Obj
 	return nil, nil
 }
 
+// powerOffWarnings returns a warning if the desired power state of the VM of a control plane
+// machine is changed to poweredOff.
+func powerOffWarnings(oldVSphereVM, newVSphereVM *infrav1.VSphereVM) admission.Warnings {
+	if newVSphereVM.Spec.DesiredPowerState != infrav1.VirtualMachinePowerStatePoweredOff || !util.IsControlPlaneMachine(newVSphereVM) {
+		return nil
+	}
+	if oldVSphereVM != nil && oldVSphereVM.Spec.DesiredPowerState == infrav1.VirtualMachinePowerStatePoweredOff {
+		return nil
+	}
+	return admission.Warnings{"powering off the VM of a control plane machine reduces the availability of the control plane"}
+}
+
 func (webhook *VSphereVMWebhook) deleteSpecKeys(spec map[string]interface{}, keys []string) {
 	if len(spec) == 0 || len(keys) == 0 {
 		return
@@ -18,13 +18,16 @@ package webhooks
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
 )
 
 const (
@@ -99,6 +102,16 @@ func TestVSphereVM_ValidateCreate(t *testing.T) {
 			vSphereVM: createVSphereVM(linuxVMName, "foo.com", "", "", "", []string{"192.168.0.1/32", "192.168.0.3/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil),
 			wantErr:   false,
 		},
+		{
+			name:      "no error with suffix clone conflict policy",
+			vSphereVM: withCloneConflictPolicy(createVSphereVM(linuxVMName, "foo.com", "", "", "", nil, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.CloneConflictPolicySuffix),
+			wantErr:   false,
+		},
+		{
+			name:      "name too long for suffix clone conflict policy",
+			vSphereVM: withCloneConflictPolicy(createVSphereVM(strings.Repeat("a", infrav1.MaxVirtualMachineNameLength-infrav1.CloneConflictSuffixLength+1), "foo.com", "", "", "", nil, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.CloneConflictPolicySuffix),
+			wantErr:   true,
+		},
 		{
 			name:      "guestSoftPowerOffTimeout should not be set with powerOffMode set to hard",
 			vSphereVM: createVSphereVM(linuxVMName, "foo.com", "", "", "", []string{"192.168.0.1/32", "192.168.0.3/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeHard, &metav1.Duration{Duration: infrav1.GuestSoftPowerOffDefaultTimeout}),
@@ -191,6 +204,30 @@ func TestVSphereVM_ValidateUpdate(t *testing.T) {
 			vSphereVM:    createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "BB:CC:DD:EE:FF", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeHard, nil),
 			wantErr:      false,
 		},
+		{
+			name:         "CPUs and memory can be updated with reconfigure policy deferredUntilPowerOff",
+			oldVSphereVM: withHardware(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "", 2, 4096),
+			vSphereVM:    withHardware(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.ReconfigurePolicyDeferredUntilPowerOff, 4, 8192),
+			wantErr:      false,
+		},
+		{
+			name:         "CPUs and memory cannot be updated without reconfigure policy",
+			oldVSphereVM: withHardware(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "", 2, 4096),
+			vSphereVM:    withHardware(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "", 4, 8192),
+			wantErr:      true,
+		},
+		{
+			name:         "custom attributes can be updated",
+			oldVSphereVM: withCustomAttributes(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), map[string]string{"owner": "team-a"}),
+			vSphereVM:    withCustomAttributes(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), map[string]string{"owner": "team-b"}),
+			wantErr:      false,
+		},
+		{
+			name:         "desiredPowerState can be updated",
+			oldVSphereVM: createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil),
+			vSphereVM:    withDesiredPowerState(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.VirtualMachinePowerStatePoweredOff),
+			wantErr:      false,
+		},
 		{
 			name:         "powerOffMode can be updated to soft",
 			oldVSphereVM: createVSphereVM("vsphere-vm-1", "foo.com", "", "", "AA:BB:CC:DD:EE", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, &metav1.Duration{Duration: infrav1.GuestSoftPowerOffDefaultTimeout}),
@@ -209,6 +246,18 @@ func TestVSphereVM_ValidateUpdate(t *testing.T) {
 			vSphereVM:    createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "AA:BB:CC:DD:EE", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil),
 			wantErr:      true,
 		},
+		{
+			name:         "template and biosUUID can be updated with the redeploy annotation",
+			oldVSphereVM: withTemplate(createVSphereVM("vsphere-vm-1", "foo.com", "old-uuid", "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "ubuntu-2204-kube-v1.28.0", false),
+			vSphereVM:    withTemplate(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "ubuntu-2204-kube-v1.29.0", true),
+			wantErr:      false,
+		},
+		{
+			name:         "template cannot be updated without the redeploy annotation",
+			oldVSphereVM: withTemplate(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "ubuntu-2204-kube-v1.28.0", false),
+			vSphereVM:    withTemplate(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), "ubuntu-2204-kube-v1.29.0", false),
+			wantErr:      true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(*testing.T) {
@@ -223,6 +272,68 @@ func TestVSphereVM_ValidateUpdate(t *testing.T) {
 	}
 }
 
+func TestVSphereVM_ValidateUpdate_PowerOffWarnings(t *testing.T) {
+	controlPlane := func(vm *infrav1.VSphereVM) *infrav1.VSphereVM {
+		vm.Labels = map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+		return vm
+	}
+
+	tests := []struct {
+		name         string
+		oldVSphereVM *infrav1.VSphereVM
+		vSphereVM    *infrav1.VSphereVM
+		wantWarnings bool
+	}{
+		{
+			name:         "warns when the VM of a control plane machine is powered off",
+			oldVSphereVM: controlPlane(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil)),
+			vSphereVM:    controlPlane(withDesiredPowerState(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.VirtualMachinePowerStatePoweredOff)),
+			wantWarnings: true,
+		},
+		{
+			name:         "does not warn again when the VM of a control plane machine is kept powered off",
+			oldVSphereVM: controlPlane(withDesiredPowerState(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.VirtualMachinePowerStatePoweredOff)),
+			vSphereVM:    controlPlane(withDesiredPowerState(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.VirtualMachinePowerStatePoweredOff)),
+			wantWarnings: false,
+		},
+		{
+			name:         "does not warn when the VM of a worker machine is powered off",
+			oldVSphereVM: createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil),
+			vSphereVM:    withDesiredPowerState(createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil), infrav1.VirtualMachinePowerStatePoweredOff),
+			wantWarnings: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			webhook := &VSphereVMWebhook{}
+			warnings, err := webhook.ValidateUpdate(context.Background(), tc.oldVSphereVM, tc.vSphereVM)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.wantWarnings {
+				g.Expect(warnings).To(HaveLen(1))
+			} else {
+				g.Expect(warnings).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestVSphereVM_ValidateUpdate_Folder(t *testing.T) {
+	g := NewWithT(t)
+	webhook := &VSphereVMWebhook{}
+	oldVSphereVM := createVSphereVM("vsphere-vm-1", "foo.com", biosUUID, "", "", []string{"192.168.0.1/32"}, nil, infrav1.Linux, infrav1.VirtualMachinePowerOpModeTrySoft, nil)
+	vSphereVM := oldVSphereVM.DeepCopy()
+	vSphereVM.Spec.Folder = "/DC0/vm/moved"
+
+	_, err := webhook.ValidateUpdate(context.Background(), oldVSphereVM, vSphereVM)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(feature.MutableGates.Set("VMFolderMove=true")).To(Succeed())
+	t.Cleanup(func() { _ = feature.MutableGates.Set("VMFolderMove=false") })
+	_, err = webhook.ValidateUpdate(context.Background(), oldVSphereVM, vSphereVM)
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
 func createVSphereVM(name, server, biosUUID, preferredAPIServerCIDR, thumbprint string, ips []string, bootstrapRef *corev1.ObjectReference, os infrav1.OS, powerOffMode infrav1.VirtualMachinePowerOpMode, guestSoftPowerOffTimeout *metav1.Duration) *infrav1.VSphereVM {
 	VSphereVM := &infrav1.VSphereVM{
 		ObjectMeta: metav1.ObjectMeta{
@@ -254,3 +365,33 @@ func createVSphereVM(name, server, biosUUID, preferredAPIServerCIDR, thumbprint
 	}
 	return VSphereVM
 }
+
+func withHardware(vm *infrav1.VSphereVM, reconfigurePolicy infrav1.VirtualMachineReconfigurePolicy, numCPUs int32, memoryMiB int64) *infrav1.VSphereVM {
+	vm.Spec.ReconfigurePolicy = reconfigurePolicy
+	vm.Spec.NumCPUs = numCPUs
+	vm.Spec.MemoryMiB = memoryMiB
+	return vm
+}
+
+func withDesiredPowerState(vm *infrav1.VSphereVM, desiredPowerState infrav1.VirtualMachinePowerState) *infrav1.VSphereVM {
+	vm.Spec.DesiredPowerState = desiredPowerState
+	return vm
+}
+
+func withCustomAttributes(vm *infrav1.VSphereVM, customAttributes map[string]string) *infrav1.VSphereVM {
+	vm.Spec.CustomAttributes = customAttributes
+	return vm
+}
+
+func withTemplate(vm *infrav1.VSphereVM, template string, redeploy bool) *infrav1.VSphereVM {
+	vm.Spec.Template = template
+	if redeploy {
+		vm.Annotations = map[string]string{infrav1.RedeployAnnotation: ""}
+	}
+	return vm
+}
+
+func withCloneConflictPolicy(vm *infrav1.VSphereVM, policy infrav1.CloneConflictPolicy) *infrav1.VSphereVM {
+	vm.Spec.CloneConflictPolicy = policy
+	return vm
+}
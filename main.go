@@ -56,6 +56,7 @@ import (
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/manager"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/version"
 )
 
@@ -84,6 +85,7 @@ var (
 	vSphereClusterIdentityConcurrency int
 	vSphereDeploymentZoneConcurrency  int
 
+	tracingOpts        tracing.Options
 	tlsOptions         = capiflags.TLSOptions{}
 	diagnosticsOptions = capiflags.DiagnosticsOptions{}
 
@@ -94,6 +96,7 @@ var (
 	defaultWebhookPort       = manager.DefaultWebhookServiceContainerPort
 	defaultEnableKeepAlive   = constants.DefaultEnableKeepAlive
 	defaultKeepAliveDuration = constants.DefaultKeepAliveDuration
+	defaultInventoryCacheTTL = constants.DefaultInventoryCacheTTL
 )
 
 // InitFlags initializes the flags.
@@ -142,6 +145,12 @@ func InitFlags(fs *pflag.FlagSet) {
 		"/etc/capv/credentials.yaml",
 		"path to CAPV's credentials file",
 	)
+	fs.StringVar(
+		&managerOpts.VCenterCABundleFile,
+		"vcenter-ca-bundle",
+		"",
+		"path to a PEM encoded bundle of CA certificates used to verify the certificate of vCenters whose identity secret has no caBundle key. Verification falls back to the thumbprint of the VSphereCluster if set.",
+	)
 	fs.BoolVar(
 		&managerOpts.EnableKeepAlive,
 		"enable-keep-alive",
@@ -153,6 +162,54 @@ func InitFlags(fs *pflag.FlagSet) {
 		defaultKeepAliveDuration,
 		"idle time interval(minutes) in between send() requests in keepalive handler",
 	)
+	fs.DurationVar(
+		&managerOpts.InventoryCacheTTL,
+		"inventory-cache-ttl",
+		defaultInventoryCacheTTL,
+		"time to live of cached vSphere inventory objects, e.g. folders and resource pools. Set to 0 to disable the cache.",
+	)
+	fs.DurationVar(
+		&managerOpts.StorageComplianceCheckInterval,
+		"storage-compliance-check-interval",
+		0,
+		"interval at which the storage policy compliance of VMs with a storage policy is polled and reported by their StorageCompliant condition. Set to 0 to disable the check.",
+	)
+	fs.DurationVar(
+		&managerOpts.GuestDiskUsageRefreshInterval,
+		"guest-disk-usage-refresh-interval",
+		0,
+		"interval at which the usage of the guest filesystems of VMs, as reported by VMware Tools, is refreshed in their status. Set to 0 to disable the reporting.",
+	)
+	fs.DurationVar(
+		&managerOpts.HostMaintenanceCheckInterval,
+		"host-maintenance-check-interval",
+		0,
+		"interval at which the ESXi hosts of VMs are checked for maintenance mode. Nodes of VMs whose host enters maintenance mode are tainted with NoSchedule until it exits maintenance mode. Set to 0 to disable the check.",
+	)
+	fs.DurationVar(
+		&managerOpts.MigrationHistoryRefreshInterval,
+		"migration-history-refresh-interval",
+		0,
+		"interval at which the migrations of VMs between ESXi hosts, e.g. by vMotion, are read from the events of vCenter and reported in their status. Set to 0 to disable the reporting.",
+	)
+	fs.DurationVar(
+		&managerOpts.NodeCapacityRefreshTimeout,
+		"node-capacity-refresh-timeout",
+		10*time.Minute,
+		"maximum time the node of a VM stays cordoned while the CPUs or memory of the VM are reconfigured or hot-added, until the kubelet reports the new capacity. Only used if the NodeCapacityRefresh feature gate is enabled.",
+	)
+	fs.BoolVar(
+		&managerOpts.EnableManagedBy,
+		"enable-managed-by",
+		false,
+		"marks the VMs cloned by CAPV as managed by its vCenter extension, so the vSphere UI shows them as managed by CAPV. The extension is registered with the vCenter if the account has the Extension.Register privilege.",
+	)
+	fs.BoolVar(
+		&managerOpts.HardenVMIsolation,
+		"harden-vm-isolation",
+		false,
+		"defaults the isolation settings of VMs which are not defined in their spec to hardened values, i.e. copy and paste and device connects are disabled and VMCI is restricted.",
+	)
 	fs.StringVar(
 		&managerOpts.NetworkProvider,
 		"network-provider",
@@ -160,6 +217,19 @@ func InitFlags(fs *pflag.FlagSet) {
 		"network provider to be used by Supervisor based clusters.",
 	)
 
+	fs.StringVar(
+		&tracingOpts.Endpoint,
+		"tracing-endpoint",
+		"",
+		"host:port of the OTLP gRPC collector to export traces of reconciles and vCenter calls to. Traces are not exported if empty.",
+	)
+	fs.BoolVar(
+		&tracingOpts.Insecure,
+		"tracing-insecure",
+		false,
+		"disables TLS for the connection to the OTLP gRPC collector.",
+	)
+
 	// Flags common between CAPI and CAPV
 
 	logsv1.AddFlags(logOptions, fs)
@@ -313,6 +383,17 @@ func main() {
 	// Set up the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Setup(ctx, tracingOpts, controllerName)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "failed to shut down tracing")
+		}
+	}()
+
 	mgr, err := manager.New(ctx, managerOpts)
 	if err != nil {
 		setupLog.Error(err, "Error creating manager")
@@ -340,15 +421,19 @@ func main() {
 }
 
 func setupVAPIControllers(ctx context.Context, controllerCtx *capvcontext.ControllerManagerContext, mgr ctrlmgr.Manager, tracker *remote.ClusterCacheTracker) error {
+	if err := (&webhooks.VSphereClusterWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+
 	if err := (&webhooks.VSphereClusterTemplateWebhook{}).SetupWebhookWithManager(mgr); err != nil {
 		return err
 	}
 
-	if err := (&webhooks.VSphereMachineWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&webhooks.VSphereMachineWebhook{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
 		return err
 	}
 
-	if err := (&webhooks.VSphereMachineTemplateWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&webhooks.VSphereMachineTemplateWebhook{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
 		return err
 	}
 
@@ -376,6 +461,11 @@ func setupVAPIControllers(ctx context.Context, controllerCtx *capvcontext.Contro
 	if err := controllers.AddVsphereClusterIdentityControllerToManager(ctx, controllerCtx, mgr, concurrency(vSphereClusterIdentityConcurrency)); err != nil {
 		return err
 	}
+	if feature.Gates.Enabled(feature.ClusterPlacementSummary) {
+		if err := controllers.AddClusterPlacementControllerToManager(ctx, controllerCtx, mgr, concurrency(vSphereClusterConcurrency)); err != nil {
+			return err
+		}
+	}
 
 	return controllers.AddVSphereDeploymentZoneControllerToManager(ctx, controllerCtx, mgr, concurrency(vSphereDeploymentZoneConcurrency))
 }
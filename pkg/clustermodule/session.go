@@ -56,7 +56,7 @@ func (s *service) fetchSession(ctx context.Context, clusterCtx *capvcontext.Clus
 			return nil, errors.Wrap(err, "failed to get credentials from IdentityRef")
 		}
 
-		params = params.WithUserInfo(creds.Username, creds.Password)
+		params = params.WithUserInfo(creds.Username, creds.Password).WithCABundle(creds.CABundle)
 		return session.GetOrCreate(ctx, params)
 	}
 
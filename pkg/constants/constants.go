@@ -58,6 +58,9 @@ const (
 	// DefaultKeepAliveDuration unit minutes.
 	DefaultKeepAliveDuration = time.Minute * 5
 
+	// DefaultInventoryCacheTTL is the default time to live of cached vSphere inventory objects.
+	DefaultInventoryCacheTTL = time.Second * 30
+
 	// NodeLabelPrefix is the prefix for node labels.
 	NodeLabelPrefix = "node.cluster.x-k8s.io"
 
@@ -45,6 +45,8 @@ func (c *ClusterContext) Patch(ctx context.Context) error {
 	conditions.SetSummary(c.VSphereCluster,
 		conditions.WithConditions(
 			infrav1.VCenterAvailableCondition,
+			infrav1.ResourcePoolReadyCondition,
+			infrav1.IPAddressClaimedCondition,
 		),
 	)
 
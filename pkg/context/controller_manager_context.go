@@ -75,6 +75,33 @@ type ControllerManagerContext struct {
 	// in keepalive handler
 	KeepAliveDuration time.Duration
 
+	// EnableManagedBy marks the VMs cloned by CAPV as managed by its vCenter extension.
+	EnableManagedBy bool
+
+	// HardenVMIsolation defaults the isolation settings of VMs, which are not defined
+	// in their spec, to hardened values.
+	HardenVMIsolation bool
+
+	// StorageComplianceCheckInterval is the interval at which the storage policy compliance
+	// of VMs with a storage policy is polled. Polling is disabled if it is zero.
+	StorageComplianceCheckInterval time.Duration
+
+	// GuestDiskUsageRefreshInterval is the interval at which the usage of the guest filesystems
+	// of VMs is refreshed in their status. Reporting is disabled if it is zero.
+	GuestDiskUsageRefreshInterval time.Duration
+
+	// HostMaintenanceCheckInterval is the interval at which the maintenance mode of the ESXi
+	// hosts of VMs is polled to taint their nodes. Polling is disabled if it is zero.
+	HostMaintenanceCheckInterval time.Duration
+
+	// MigrationHistoryRefreshInterval is the interval at which the migrations of VMs are read
+	// from the events of vCenter into their status. Reporting is disabled if it is zero.
+	MigrationHistoryRefreshInterval time.Duration
+
+	// NodeCapacityRefreshTimeout is the maximum time the node of a VM stays cordoned while the
+	// CPUs or memory of the VM are reconfigured or hot-added, until the kubelet reports them.
+	NodeCapacityRefreshTimeout time.Duration
+
 	// NetworkProvider is the network provider used by Supervisor based clusters
 	NetworkProvider string
 
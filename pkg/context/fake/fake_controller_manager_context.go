@@ -48,6 +48,7 @@ func NewControllerManagerContext(initObjects ...client.Object) *capvcontext.Cont
 
 	clientWithObjects := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(
 		&infrav1.VSphereVM{},
+		&infrav1.VSphereClusterPlacement{},
 		&vmwarev1.VSphereCluster{},
 	).WithObjects(initObjects...).Build()
 
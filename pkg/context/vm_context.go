@@ -35,6 +35,10 @@ type VMContext struct {
 	PatchHelper          *patch.Helper
 	Session              *session.Session
 	VSphereFailureDomain *infrav1.VSphereFailureDomain
+
+	// MachineName is the name of the CAPI Machine which owns the VSphereVM,
+	// if known.
+	MachineName string
 }
 
 // String returns VSphereVMGroupVersionKind VSphereVMNamespace/VSphereVMName.
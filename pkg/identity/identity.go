@@ -36,12 +36,16 @@ const (
 	UsernameKey = "username"
 	// PasswordKey is the key used for the password.
 	PasswordKey = "password"
+	// CABundleKey is the key used for the PEM encoded bundle of CA certificates
+	// which is used to verify the certificate of the VCenter.
+	CABundleKey = "caBundle"
 )
 
 // Credentials are the user credentials used with the VSphere API.
 type Credentials struct {
 	Username string
 	Password string
+	CABundle []byte
 }
 
 // GetCredentials returns the VCenter credentials for the VSphereCluster.
@@ -108,6 +112,7 @@ func GetCredentials(ctx context.Context, c client.Client, cluster *infrav1.VSphe
 	credentials := &Credentials{
 		Username: getData(secret, UsernameKey),
 		Password: getData(secret, PasswordKey),
+		CABundle: secret.Data[CABundleKey],
 	}
 
 	return credentials, nil
@@ -89,6 +89,23 @@ var _ = Describe("GetCredentials", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(creds.Username).To(Equal(getData(credentialSecret, UsernameKey)))
 			Expect(creds.Password).To(Equal(getData(credentialSecret, PasswordKey)))
+			Expect(creds.CABundle).To(BeEmpty())
+		})
+
+		It("should return the CA bundle of the secret", func() {
+			credentialSecret := createSecret(cluster.Namespace)
+			credentialSecret.Data[CABundleKey] = []byte("ca-bundle")
+			Expect(k8sclient.Update(ctx, credentialSecret)).To(Succeed())
+			cluster.Spec = infrav1.VSphereClusterSpec{
+				IdentityRef: &infrav1.VSphereIdentityReference{
+					Kind: infrav1.SecretKind,
+					Name: credentialSecret.Name,
+				},
+			}
+			Expect(k8sclient.Update(ctx, cluster)).To(Succeed())
+			creds, err := GetCredentials(ctx, k8sclient, cluster, manager.DefaultPodNamespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.CABundle).To(Equal([]byte("ca-bundle")))
 		})
 
 		It("should error if secret is not in the same namespace as the cluster", func() {
@@ -19,6 +19,7 @@ package manager
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/pkg/errors"
 	netopv1 "github.com/vmware-tanzu/net-operator-api/api/v1alpha1"
@@ -38,6 +39,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	vmwarev1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/vmware/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
 )
 
 // Manager is a CAPV controller manager.
@@ -73,22 +75,42 @@ func New(ctx context.Context, opts Options) (Manager, error) {
 		return nil, errors.Wrap(err, "unable to create manager")
 	}
 
+	session.SetInventoryCacheTTL(opts.InventoryCacheTTL)
+
+	if opts.VCenterCABundleFile != "" {
+		caBundle, err := os.ReadFile(opts.VCenterCABundleFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read vCenter CA bundle %s", opts.VCenterCABundleFile)
+		}
+		if _, err := session.ParseCABundle(caBundle); err != nil {
+			return nil, errors.Wrapf(err, "invalid vCenter CA bundle %s", opts.VCenterCABundleFile)
+		}
+		session.SetDefaultCABundle(caBundle)
+	}
+
 	// Build the controller manager context.
 	controllerManagerContext := &capvcontext.ControllerManagerContext{
-		WatchNamespaces:         opts.Cache.DefaultNamespaces,
-		Namespace:               opts.PodNamespace,
-		Name:                    opts.PodName,
-		LeaderElectionID:        opts.LeaderElectionID,
-		LeaderElectionNamespace: opts.LeaderElectionNamespace,
-		Client:                  mgr.GetClient(),
-		Logger:                  opts.Logger,
-		Scheme:                  opts.Scheme,
-		Username:                opts.Username,
-		Password:                opts.Password,
-		EnableKeepAlive:         opts.EnableKeepAlive,
-		KeepAliveDuration:       opts.KeepAliveDuration,
-		NetworkProvider:         opts.NetworkProvider,
-		WatchFilterValue:        opts.WatchFilterValue,
+		WatchNamespaces:                 opts.Cache.DefaultNamespaces,
+		Namespace:                       opts.PodNamespace,
+		Name:                            opts.PodName,
+		LeaderElectionID:                opts.LeaderElectionID,
+		LeaderElectionNamespace:         opts.LeaderElectionNamespace,
+		Client:                          mgr.GetClient(),
+		Logger:                          opts.Logger,
+		Scheme:                          opts.Scheme,
+		Username:                        opts.Username,
+		Password:                        opts.Password,
+		EnableKeepAlive:                 opts.EnableKeepAlive,
+		KeepAliveDuration:               opts.KeepAliveDuration,
+		EnableManagedBy:                 opts.EnableManagedBy,
+		HardenVMIsolation:               opts.HardenVMIsolation,
+		StorageComplianceCheckInterval:  opts.StorageComplianceCheckInterval,
+		GuestDiskUsageRefreshInterval:   opts.GuestDiskUsageRefreshInterval,
+		HostMaintenanceCheckInterval:    opts.HostMaintenanceCheckInterval,
+		MigrationHistoryRefreshInterval: opts.MigrationHistoryRefreshInterval,
+		NodeCapacityRefreshTimeout:      opts.NodeCapacityRefreshTimeout,
+		NetworkProvider:                 opts.NetworkProvider,
+		WatchFilterValue:                opts.WatchFilterValue,
 	}
 
 	// Add the requested items to the manager.
@@ -68,9 +68,52 @@ type Options struct {
 	// in keepalive handler
 	KeepAliveDuration time.Duration
 
+	// InventoryCacheTTL is the time to live of cached vSphere inventory objects,
+	// e.g. folders and resource pools. Caching is disabled if it is zero.
+	InventoryCacheTTL time.Duration
+
+	// EnableManagedBy marks the VMs cloned by CAPV as managed by its vCenter
+	// extension, which is registered with the vCenter if privileges permit.
+	EnableManagedBy bool
+
+	// HardenVMIsolation defaults the isolation settings of VMs, which are not
+	// defined in their spec, to hardened values.
+	HardenVMIsolation bool
+
+	// StorageComplianceCheckInterval is the interval at which the storage policy
+	// compliance of VMs with a storage policy is polled. Polling is disabled if it
+	// is zero.
+	StorageComplianceCheckInterval time.Duration
+
+	// GuestDiskUsageRefreshInterval is the interval at which the usage of the guest
+	// filesystems of VMs is refreshed in their status. Reporting is disabled if it
+	// is zero.
+	GuestDiskUsageRefreshInterval time.Duration
+
+	// HostMaintenanceCheckInterval is the interval at which the maintenance mode of
+	// the ESXi hosts of VMs is polled. Nodes of VMs whose host enters maintenance
+	// mode are tainted. Polling is disabled if it is zero.
+	HostMaintenanceCheckInterval time.Duration
+
+	// MigrationHistoryRefreshInterval is the interval at which the migrations of VMs
+	// between ESXi hosts are read from the events of vCenter and reported in their
+	// status. Reporting is disabled if it is zero.
+	MigrationHistoryRefreshInterval time.Duration
+
+	// NodeCapacityRefreshTimeout is the maximum time the node of a VM stays cordoned
+	// while the CPUs or memory of the VM are reconfigured or hot-added, until the kubelet
+	// reports the new capacity. Only used if the NodeCapacityRefresh feature
+	// gate is enabled.
+	NodeCapacityRefreshTimeout time.Duration
+
 	// CredentialsFile is the file that contains credentials of CAPV
 	CredentialsFile string
 
+	// VCenterCABundleFile is the file that contains the PEM encoded bundle of CA
+	// certificates used to verify the certificate of vCenters whose identity
+	// has no CA bundle.
+	VCenterCABundleFile string
+
 	KubeConfig *rest.Config
 
 	// AddToManager is a function that can be optionally specified with
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// unmarshalCloudConfig parses the cloud-config and returns its leading comments
+// separately, e.g. "#cloud-config" and "## template: jinja", which would otherwise
+// be lost when marshalling the config again.
+func unmarshalCloudConfig(data []byte) ([]byte, map[string]interface{}, error) {
+	var header []byte
+	body := data
+	for bytes.HasPrefix(body, []byte("#")) {
+		i := bytes.IndexByte(body, '\n')
+		if i < 0 {
+			i = len(body) - 1
+		}
+		header = append(header, body[:i+1]...)
+		body = body[i+1:]
+	}
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal(body, &config); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse cloud-config")
+	}
+	return header, config, nil
+}
+
+// marshalCloudConfig marshals the cloud-config and prepends the given leading comments.
+func marshalCloudConfig(header []byte, config map[string]interface{}) ([]byte, error) {
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cloud-config")
+	}
+	return append(header, out...), nil
+}
+
+// unmarshalIgnitionConfig parses the Ignition config.
+func unmarshalIgnitionConfig(data []byte) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Ignition config")
+	}
+	return config, nil
+}
+
+// marshalIgnitionConfig marshals the Ignition config.
+func marshalIgnitionConfig(config map[string]interface{}) ([]byte, error) {
+	out, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal Ignition config")
+	}
+	return out, nil
+}
+
+// isIgnitionV2 returns true if the Ignition config is of version 2 of the specification,
+// whose nodes must define the filesystem.
+func isIgnitionV2(config map[string]interface{}) bool {
+	ignition, _ := config["ignition"].(map[string]interface{})
+	version, _ := ignition["version"].(string)
+	return strings.HasPrefix(version, "2.")
+}
+
+// ignitionFile returns the Ignition file with the given mode and source URL of its contents,
+// which are appended to the file instead of replacing it if appendContents is true.
+func ignitionFile(v2 bool, path string, mode int, source string, appendContents bool) map[string]interface{} {
+	contents := map[string]interface{}{"source": source}
+	f := map[string]interface{}{"path": path, "mode": mode}
+	switch {
+	case v2:
+		f["filesystem"] = "root"
+		f["contents"] = contents
+		if appendContents {
+			f["append"] = true
+		}
+	case appendContents:
+		f["append"] = []interface{}{contents}
+	default:
+		f["overwrite"] = true
+		f["contents"] = contents
+	}
+	return f
+}
+
+// orderInjectedCommands places the commands injected by CAPV into the runcmd section
+// of the cloud-config relative to those of the original bootstrap data. The injected
+// commands are appended when they are added to the bootstrap data, so they are moved
+// in front of the bootstrap commands unless the order is afterBootstrap. The injected
+// commands keep their relative order either way.
+func orderInjectedCommands(bootstrapData, data []byte, format bootstrapv1.Format, order infrav1.InjectedCommandsOrder) ([]byte, error) {
+	if format != bootstrapv1.CloudConfig || order == infrav1.InjectedCommandsOrderAfterBootstrap || bytes.Equal(bootstrapData, data) {
+		return data, nil
+	}
+	_, bootstrapConfig, err := unmarshalCloudConfig(bootstrapData)
+	if err != nil {
+		return nil, err
+	}
+	bootstrapCommands, _ := bootstrapConfig["runcmd"].([]interface{})
+
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	runcmd, _ := config["runcmd"].([]interface{})
+	n := len(bootstrapCommands)
+	if n == 0 || len(runcmd) <= n {
+		return data, nil
+	}
+	ordered := make([]interface{}, 0, len(runcmd))
+	ordered = append(ordered, runcmd[n:]...)
+	config["runcmd"] = append(ordered, runcmd[:n]...)
+	return marshalCloudConfig(header, config)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_orderInjectedCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		bootstrapData string
+		data          string
+		format        bootstrapv1.Format
+		order         infrav1.InjectedCommandsOrder
+		expected      string
+	}{
+		{
+			name:          "without injected commands",
+			bootstrapData: "#!/bin/sh\necho\n",
+			data:          "#!/bin/sh\necho\n",
+			format:        bootstrapv1.CloudConfig,
+			expected:      "#!/bin/sh\necho\n",
+		},
+		{
+			name:          "without bootstrap commands",
+			bootstrapData: "#cloud-config\n",
+			data:          "#cloud-config\nruncmd:\n- systemctl daemon-reload\n",
+			format:        bootstrapv1.CloudConfig,
+			expected:      "#cloud-config\nruncmd:\n- systemctl daemon-reload\n",
+		},
+		{
+			name:          "before bootstrap by default",
+			bootstrapData: "## template: jinja\n#cloud-config\nruncmd:\n- kubeadm init\n- echo done\n",
+			data:          "## template: jinja\n#cloud-config\nruncmd:\n- kubeadm init\n- echo done\n- systemctl daemon-reload\n- systemctl restart containerd\n",
+			format:        bootstrapv1.CloudConfig,
+			expected:      "## template: jinja\n#cloud-config\nruncmd:\n- systemctl daemon-reload\n- systemctl restart containerd\n- kubeadm init\n- echo done\n",
+		},
+		{
+			name:          "after bootstrap",
+			bootstrapData: "#cloud-config\nruncmd:\n- kubeadm init\n",
+			data:          "#cloud-config\nruncmd:\n- kubeadm init\n- systemctl daemon-reload\n",
+			format:        bootstrapv1.CloudConfig,
+			order:         infrav1.InjectedCommandsOrderAfterBootstrap,
+			expected:      "#cloud-config\nruncmd:\n- kubeadm init\n- systemctl daemon-reload\n",
+		},
+		{
+			name:          "ignition",
+			bootstrapData: `{"ignition":{"version":"3.1.0"}}`,
+			data:          `{"ignition":{"version":"3.1.0"},"storage":{}}`,
+			format:        bootstrapv1.Ignition,
+			expected:      `{"ignition":{"version":"3.1.0"},"storage":{}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := orderInjectedCommands([]byte(tt.bootstrapData), []byte(tt.data), tt.format, tt.order)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func Test_getBootstrapData_injectedCommandsOrder(t *testing.T) {
+	proxy := &infrav1.ProxyConfiguration{HTTPProxy: "http://proxy:3128"}
+	ntpServers := []string{"0.pool.ntp.org"}
+	ntpCommands := []interface{}{
+		"w32tm /config /manualpeerlist:\"0.pool.ntp.org\" /syncfromflags:manual /update",
+		"w32tm /resync /force",
+	}
+	proxyCommands := []interface{}{"systemctl daemon-reload", "systemctl restart containerd"}
+	bootstrapCommands := []interface{}{"kubeadm join", "echo done"}
+
+	tests := []struct {
+		name     string
+		os       infrav1.OS
+		order    infrav1.InjectedCommandsOrder
+		expected []interface{}
+	}{
+		{
+			name:     "proxy before bootstrap",
+			expected: append(append([]interface{}{}, proxyCommands...), bootstrapCommands...),
+		},
+		{
+			name:     "proxy after bootstrap",
+			order:    infrav1.InjectedCommandsOrderAfterBootstrap,
+			expected: append(append([]interface{}{}, bootstrapCommands...), proxyCommands...),
+		},
+		{
+			name:     "time settings of Windows before bootstrap",
+			os:       infrav1.Windows,
+			order:    infrav1.InjectedCommandsOrderBeforeBootstrap,
+			expected: append(append([]interface{}{}, ntpCommands...), bootstrapCommands...),
+		},
+		{
+			name:     "time settings of Windows after bootstrap",
+			os:       infrav1.Windows,
+			order:    infrav1.InjectedCommandsOrderAfterBootstrap,
+			expected: append(append([]interface{}{}, bootstrapCommands...), ntpCommands...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bootstrap-data",
+					Namespace: "my-namespace",
+				},
+				Data: map[string][]byte{
+					"value": []byte("#cloud-config\nruncmd:\n- kubeadm join\n- echo done\n"),
+				},
+			}).Build()
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				Spec: infrav1.VSphereVMSpec{
+					BootstrapRef: &corev1.ObjectReference{
+						Name:      "bootstrap-data",
+						Namespace: "my-namespace",
+					},
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						OS:                    tt.os,
+						NTPServers:            ntpServers,
+						InjectedCommandsOrder: tt.order,
+					},
+					Proxy: proxy,
+				},
+			}
+
+			out, format, err := (&VMService{}).getBootstrapData(context.Background(), &vmCtx.VMContext)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(format).To(Equal(bootstrapv1.CloudConfig))
+
+			config := map[string]interface{}{}
+			g.Expect(yaml.Unmarshal(out, &config)).To(Succeed())
+			g.Expect(config["runcmd"]).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileCDROMs ensures the media of the CD-ROM drives of the VM match the ones
+// defined in the spec. Missing CD-ROM drives are only added while the VM is powered off.
+func (vms *VMService) reconcileCDROMs(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	cdroms := virtualMachineCtx.VSphereVM.Spec.CDROMs
+	if len(cdroms) == 0 {
+		log.V(5).Info("CD-ROMs not defined. skipping reconcile CD-ROMs")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var devices object.VirtualDeviceList
+	if virtualMachine.Config != nil {
+		devices = virtualMachine.Config.Hardware.Device
+	}
+	drives := devices.SelectByType((*types.VirtualCdrom)(nil))
+
+	var deviceChange []types.BaseVirtualDeviceConfigSpec
+	for i, cdrom := range cdroms {
+		operation := types.VirtualDeviceConfigSpecOperationEdit
+		var drive *types.VirtualCdrom
+		if i < len(drives) {
+			drive = drives[i].(*types.VirtualCdrom)
+			if cdromMatches(drive, cdrom) {
+				continue
+			}
+		} else {
+			if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+				log.V(5).Info("VM is not powered off. skipping adding CD-ROM drive")
+				continue
+			}
+			controller, err := devices.FindIDEController("")
+			if err != nil {
+				return false, errors.Wrapf(err, "unable to find controller for CD-ROM drive of vm %s", ctx)
+			}
+			drive, err = devices.CreateCdrom(controller)
+			if err != nil {
+				return false, errors.Wrapf(err, "unable to create CD-ROM drive for vm %s", ctx)
+			}
+			drive.Key = devices.NewKey()
+			// The device list is extended so the next CD-ROM drive gets the next unit number.
+			devices = append(devices, drive)
+			operation = types.VirtualDeviceConfigSpecOperationAdd
+		}
+
+		if cdrom.ISOPath != "" {
+			if err := vms.checkISOExists(ctx, virtualMachineCtx, cdrom.ISOPath); err != nil {
+				conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.ISONotFoundReason, clusterv1.ConditionSeverityWarning, err.Error())
+				return false, err
+			}
+			devices.InsertIso(drive, cdrom.ISOPath)
+			drive.Connectable = &types.VirtualDeviceConnectInfo{
+				AllowGuestControl: true,
+				Connected:         true,
+				StartConnected:    true,
+			}
+		} else {
+			devices.EjectIso(drive)
+			drive.Connectable = &types.VirtualDeviceConnectInfo{
+				AllowGuestControl: true,
+			}
+		}
+		deviceChange = append(deviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: operation,
+			Device:    drive,
+		})
+	}
+	if len(deviceChange) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM CD-ROMs")
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		DeviceChange: deviceChange,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to set CD-ROMs on vm %s", ctx)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM CD-ROMs to be updated")
+	return false, nil
+}
+
+// checkISOExists returns an error if the ISO image at the given datastore path does not exist.
+func (vms *VMService) checkISOExists(ctx context.Context, virtualMachineCtx *virtualMachineContext, isoPath string) error {
+	var datastorePath object.DatastorePath
+	if !datastorePath.FromString(isoPath) {
+		return errors.Errorf("invalid datastore path %q of ISO image", isoPath)
+	}
+	datastore, err := virtualMachineCtx.Session.DatastoreOrDefault(ctx, datastorePath.Datastore)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find datastore of ISO image %s", isoPath)
+	}
+	if _, err := datastore.Stat(ctx, datastorePath.Path); err != nil {
+		var notFoundErr object.DatastoreNoSuchFileError
+		if errors.As(err, &notFoundErr) {
+			return errors.Errorf("ISO image %s not found", isoPath)
+		}
+		return errors.Wrapf(err, "unable to check ISO image %s", isoPath)
+	}
+	return nil
+}
+
+// cdromMatches returns true if the media of the CD-ROM drive matches the given spec.
+func cdromMatches(drive *types.VirtualCdrom, cdrom infrav1.CDROMSpec) bool {
+	backing, ok := drive.Backing.(*types.VirtualCdromIsoBackingInfo)
+	if cdrom.ISOPath == "" {
+		return !ok
+	}
+	return ok && backing.FileName == cdrom.ISOPath && drive.Connectable != nil && drive.Connectable.StartConnected
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileCDROMs(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(cdroms ...infrav1.CDROMSpec) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CDROMs: cdroms,
+				},
+			},
+		}
+	}
+
+	newSession := func(ctx context.Context, c *vim25.Client) *session.Session {
+		finder := find.NewFinder(c)
+		dc, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(dc)
+
+		ds, err := finder.Datastore(ctx, "LocalDS_0")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ds.Upload(ctx, strings.NewReader("iso"), "drivers.iso", &soap.DefaultUpload)).To(Succeed())
+		return &session.Session{Finder: finder}
+	}
+
+	t.Run("when CD-ROMs are not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM()
+		ok, err := vms.reconcileCDROMs(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when powered off VM is missing CD-ROMs", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CDROMSpec{ISOPath: "[LocalDS_0] drivers.iso"}, infrav1.CDROMSpec{})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCDROMs(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			devices, err := vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			drives := devices.SelectByType((*types.VirtualCdrom)(nil))
+			g.Expect(drives).To(HaveLen(2))
+			backing, ok := drives[0].GetVirtualDevice().Backing.(*types.VirtualCdromIsoBackingInfo)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(backing.FileName).To(Equal("[LocalDS_0] drivers.iso"))
+
+			// A second reconcile is a no-op once the CD-ROMs match.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileCDROMs(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the ISO image does not exist", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CDROMSpec{ISOPath: "[LocalDS_0] missing.iso"})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCDROMs(ctx, vmCtx)
+			g.Expect(err).To(MatchError(ContainSubstring("not found")))
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.ISONotFoundReason))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
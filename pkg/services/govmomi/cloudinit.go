@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"bytes"
+	"context"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/nocloud"
+)
+
+// noCloudSeedFileName is the name of the seed ISO image of the NoCloud datasource,
+// which is stored next to the configuration file of the VM.
+const noCloudSeedFileName = "cidata.iso"
+
+// reconcileNoCloudSeed attaches the seed ISO image with the bootstrap data to a new
+// CD-ROM drive of the VM, if the cloud-init datasource is NoCloud. The seed is only
+// attached while the VM is powered off, i.e. before its first boot. If the CD-ROM drive
+// of the seed was removed from the VM, e.g. by another tool, it is attached again before
+// the VM is powered on and the BootstrapDataAvailable condition records the remediation.
+// A seed detached by reconcileNoCloudSeedDetach is attached again the same way, so the
+// guest finds the bootstrap data when the VM is powered on again.
+func (vms *VMService) reconcileNoCloudSeed(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if virtualMachineCtx.VSphereVM.Spec.CloudInitDatasource != infrav1.CloudInitDatasourceNoCloud {
+		log.V(5).Info("Cloud-init datasource is not NoCloud. skipping reconcile NoCloud seed")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get config of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	seedPath, err := noCloudSeedPath(virtualMachine.Config.Files.VmPathName)
+	if err != nil {
+		return false, err
+	}
+
+	devices := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device)
+	if noCloudSeedDrive(devices, seedPath) != nil {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping attaching NoCloud seed")
+		return true, nil
+	}
+	if conditions.IsTrue(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition) {
+		log.Info("NoCloud seed is missing from VM. attaching it again", "seedPath", seedPath.String())
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition, infrav1.BootstrapDataMissingReason, clusterv1.ConditionSeverityWarning,
+			"CD-ROM drive of NoCloud seed %s was removed from the VM, attaching it again", seedPath.String())
+	}
+
+	if err := vms.uploadNoCloudSeed(ctx, virtualMachineCtx, seedPath); err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.NoCloudSeedFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	controller, err := devices.FindIDEController("")
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to find controller for CD-ROM drive of NoCloud seed of vm %s", ctx)
+	}
+	drive, err := devices.CreateCdrom(controller)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to create CD-ROM drive for NoCloud seed of vm %s", ctx)
+	}
+	drive.Key = devices.NewKey()
+	devices.InsertIso(drive, seedPath.String())
+	drive.Connectable = &types.VirtualDeviceConnectInfo{
+		Connected:      true,
+		StartConnected: true,
+	}
+
+	log.Info("Attaching NoCloud seed to VM", "seedPath", seedPath.String())
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    drive,
+			},
+		},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to attach NoCloud seed to vm %s", ctx)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM NoCloud seed to be attached")
+	return false, nil
+}
+
+// reconcileNoCloudSeedDetach detaches the CD-ROM drive of the NoCloud seed from the powered on
+// VM once the VSphereVM is ready, if the NoCloudSeedDetach feature gate is enabled. The seed
+// ISO image is kept on the datastore, so it can be attached again before the VM is powered on.
+func (vms *VMService) reconcileNoCloudSeedDetach(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !feature.Gates.Enabled(feature.NoCloudSeedDetach) ||
+		virtualMachineCtx.VSphereVM.Spec.CloudInitDatasource != infrav1.CloudInitDatasourceNoCloud ||
+		!virtualMachineCtx.VSphereVM.Status.Ready {
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		log.V(5).Info("VM is not powered on. skipping detaching NoCloud seed")
+		return true, nil
+	}
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get config of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	seedPath, err := noCloudSeedPath(virtualMachine.Config.Files.VmPathName)
+	if err != nil {
+		return false, err
+	}
+	drive := noCloudSeedDrive(virtualMachine.Config.Hardware.Device, seedPath)
+	if drive == nil {
+		return true, nil
+	}
+
+	log.Info("Detaching NoCloud seed from VM", "seedPath", seedPath.String())
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    drive,
+			},
+		},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to detach NoCloud seed from vm %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition, infrav1.BootstrapDataDetachedReason, clusterv1.ConditionSeverityInfo,
+		"CD-ROM drive of NoCloud seed %s was detached from the ready VM", seedPath.String())
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM NoCloud seed to be detached")
+	return false, nil
+}
+
+// noCloudSeedDrive returns the CD-ROM drive with the seed ISO image at the given datastore
+// path, or nil if the seed is not attached.
+func noCloudSeedDrive(devices object.VirtualDeviceList, seedPath object.DatastorePath) types.BaseVirtualDevice {
+	for _, device := range devices.SelectByType((*types.VirtualCdrom)(nil)) {
+		if backing, ok := device.GetVirtualDevice().Backing.(*types.VirtualCdromIsoBackingInfo); ok && backing.FileName == seedPath.String() {
+			return device
+		}
+	}
+	return nil
+}
+
+// uploadNoCloudSeed uploads the seed ISO image with the bootstrap data and the metadata
+// of the VM to the given datastore path.
+func (vms *VMService) uploadNoCloudSeed(ctx context.Context, virtualMachineCtx *virtualMachineContext, seedPath object.DatastorePath) error {
+	bootstrapData, format, err := vms.getBootstrapData(ctx, &virtualMachineCtx.VMContext)
+	if err != nil {
+		return err
+	}
+	if format != bootstrapv1.CloudConfig {
+		return errors.Errorf("cloud-init datasource NoCloud requires bootstrap data format %s, got %s", bootstrapv1.CloudConfig, format)
+	}
+
+	datastore, err := virtualMachineCtx.Session.DatastoreOrDefault(ctx, seedPath.Datastore)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find datastore of NoCloud seed %s", seedPath.String())
+	}
+	seed := nocloud.SeedISO(bootstrapData, nocloud.Metadata(string(virtualMachineCtx.VSphereVM.UID), virtualMachineCtx.VSphereVM.Name))
+	upload := soap.DefaultUpload
+	upload.ContentLength = int64(len(seed))
+	if err := datastore.Upload(ctx, bytes.NewReader(seed), seedPath.Path, &upload); err != nil {
+		return errors.Wrapf(err, "unable to upload NoCloud seed %s", seedPath.String())
+	}
+	return nil
+}
+
+// deleteNoCloudSeed deletes the seed ISO image of the NoCloud datasource of the VM, which
+// is not deleted together with the VM.
+func deleteNoCloudSeed(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	var virtualMachine mo.VirtualMachine
+	if err := virtualMachineCtx.Obj.Properties(ctx, virtualMachineCtx.Obj.Reference(), []string{"config.files.vmPathName"}, &virtualMachine); err != nil {
+		return errors.Wrapf(err, "error getting files of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	if virtualMachine.Config == nil {
+		return errors.Errorf("unable to get config of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	seedPath, err := noCloudSeedPath(virtualMachine.Config.Files.VmPathName)
+	if err != nil {
+		return err
+	}
+	datacenter, err := virtualMachineCtx.Session.Finder.DatacenterOrDefault(ctx, virtualMachineCtx.VSphereVM.Spec.Datacenter)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find datacenter of NoCloud seed %s", seedPath.String())
+	}
+	task, err := object.NewFileManager(virtualMachineCtx.Obj.Client()).DeleteDatastoreFile(ctx, seedPath.String(), datacenter)
+	if err != nil {
+		return errors.Wrapf(err, "unable to delete NoCloud seed %s", seedPath.String())
+	}
+	return errors.Wrapf(task.Wait(ctx), "unable to delete NoCloud seed %s", seedPath.String())
+}
+
+// noCloudSeedPath returns the datastore path of the seed ISO image in the directory of
+// the configuration file of the VM at the given datastore path.
+func noCloudSeedPath(vmPathName string) (object.DatastorePath, error) {
+	var seedPath object.DatastorePath
+	if !seedPath.FromString(vmPathName) {
+		return seedPath, errors.Errorf("invalid datastore path %q of VM config", vmPathName)
+	}
+	seedPath.Path = path.Join(path.Dir(seedPath.Path), noCloudSeedFileName)
+	return seedPath, nil
+}
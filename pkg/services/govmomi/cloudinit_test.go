@@ -0,0 +1,319 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileNoCloudSeed(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func(format string) {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bootstrap-data",
+				Namespace: "my-namespace",
+			},
+			Data: map[string][]byte{
+				"format": []byte(format),
+				"value":  []byte("#cloud-config\nruncmd: []\n"),
+			},
+		}).Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(datasource infrav1.CloudInitDatasource) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+				UID:       "3f9fd1e1-0e0a-4b3d-9a3b-16d1f2b6c1aa",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				BootstrapRef: &corev1.ObjectReference{
+					Name:      "bootstrap-data",
+					Namespace: "my-namespace",
+				},
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CloudInitDatasource: datasource,
+				},
+			},
+		}
+	}
+
+	newSession := func(ctx context.Context, c *vim25.Client) *session.Session {
+		finder := find.NewFinder(c)
+		dc, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(dc)
+		return &session.Session{Finder: finder}
+	}
+
+	// makeVMDirectory creates the directory of the VM, as the simulator does not create the
+	// directories of its inventory VMs, and returns the datastore path of the seed.
+	makeVMDirectory := func(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) object.DatastorePath {
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.files.vmPathName"}, &virtualMachine)).To(Succeed())
+		seedPath, err := noCloudSeedPath(virtualMachine.Config.Files.VmPathName)
+		g.Expect(err).ToNot(HaveOccurred())
+		dc, err := vmCtx.Session.Finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		vmDirectory := object.DatastorePath{Datastore: seedPath.Datastore, Path: path.Dir(seedPath.Path)}
+		g.Expect(object.NewFileManager(c).MakeDirectory(ctx, vmDirectory.String(), dc, true)).To(Succeed())
+		return seedPath
+	}
+
+	waitForTask := func(ctx context.Context, c *vim25.Client) {
+		task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+		g.Expect(task.Wait(ctx)).To(Succeed())
+		vmCtx.VSphereVM.Status.TaskRef = ""
+	}
+
+	t.Run("when the cloud-init datasource is VMware", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+		vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceVMware)
+		ok, err := vms.reconcileNoCloudSeed(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the seed is not attached to the powered off VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceNoCloud)
+			seedPath := makeVMDirectory(ctx, c, vm)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			devices, err := vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			drives := devices.SelectByType((*types.VirtualCdrom)(nil))
+			g.Expect(drives).ToNot(BeEmpty())
+			backing, ok := drives[len(drives)-1].GetVirtualDevice().Backing.(*types.VirtualCdromIsoBackingInfo)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(backing.FileName).To(Equal(seedPath.String()))
+
+			ds, err := vmCtx.Session.Finder.Datastore(ctx, seedPath.Datastore)
+			g.Expect(err).ToNot(HaveOccurred())
+			_, err = ds.Stat(ctx, seedPath.Path)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			// A second reconcile is a no-op once the seed is attached.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+
+			// The seed is deleted separately from the VM.
+			g.Expect(deleteNoCloudSeed(ctx, vmCtx)).To(Succeed())
+			_, err = ds.Stat(ctx, seedPath.Path)
+			g.Expect(err).To(HaveOccurred())
+			return nil
+		})
+	})
+
+	t.Run("when the seed CD-ROM drive was removed from the VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceNoCloud)
+			seedPath := makeVMDirectory(ctx, c, vm)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			waitForTask(ctx, c)
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(BeTrue())
+
+			// Strip the CD-ROM drive of the seed from the VM.
+			devices, err := vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			drives := devices.SelectByType((*types.VirtualCdrom)(nil))
+			g.Expect(vm.RemoveDevice(ctx, false, drives[len(drives)-1])).To(Succeed())
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(Equal(infrav1.BootstrapDataMissingReason))
+			waitForTask(ctx, c)
+
+			devices, err = vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			drives = devices.SelectByType((*types.VirtualCdrom)(nil))
+			backing, ok := drives[len(drives)-1].GetVirtualDevice().Backing.(*types.VirtualCdromIsoBackingInfo)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(backing.FileName).To(Equal(seedPath.String()))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(BeTrue())
+			return nil
+		})
+	})
+
+	t.Run("when the seed is detached from the ready VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceNoCloud)
+			seedPath := makeVMDirectory(ctx, c, vm)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			waitForTask(ctx, c)
+			task, err := vm.PowerOn(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			vmCtx.VSphereVM.Status.Ready = true
+
+			// The seed is kept unless the feature gate is enabled.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeedDetach(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+
+			g.Expect(feature.MutableGates.Set("NoCloudSeedDetach=true")).To(Succeed())
+			t.Cleanup(func() { _ = feature.MutableGates.Set("NoCloudSeedDetach=false") })
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeedDetach(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(Equal(infrav1.BootstrapDataDetachedReason))
+			waitForTask(ctx, c)
+
+			devices, err := vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(noCloudSeedDrive(devices, seedPath)).To(BeNil())
+
+			// The detached seed is not attached to the powered on VM again.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeedDetach(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+
+			// The seed is attached again before the VM is powered on again.
+			task, err = vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(Equal(infrav1.BootstrapDataDetachedReason))
+			waitForTask(ctx, c)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(BeTrue())
+			return nil
+		})
+	})
+
+	t.Run("when the bootstrap data format is not cloud-config", func(t *testing.T) {
+		g = NewWithT(t)
+		before("ignition")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vmCtx.Session = newSession(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceNoCloud)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNoCloudSeed(ctx, vmCtx)
+			g.Expect(err).To(MatchError(ContainSubstring("requires bootstrap data format cloud-config")))
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.NoCloudSeedFailedReason))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+)
+
+// DRSVMOverride represents the DRS override of a VM in a compute cluster.
+type DRSVMOverride struct {
+	*object.ClusterComputeResource
+
+	// VM is the reference of the VM the override applies to.
+	VM types.ManagedObjectReference
+
+	// Info is the current override, nil if the VM has no override.
+	Info *types.ClusterDrsVmConfigInfo
+}
+
+// GetDRSVMOverride returns the DRS override of the VM in the compute cluster it is placed in.
+// An error is returned if the VM is not placed in a compute cluster or if DRS is not enabled
+// on the compute cluster.
+func GetDRSVMOverride(ctx context.Context, vm *object.VirtualMachine) (*DRSVMOverride, error) {
+	pool, err := vm.ResourcePool(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get resource pool of VM")
+	}
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get owner of resource pool")
+	}
+	ccr, ok := owner.(*object.ClusterComputeResource)
+	if !ok {
+		return nil, errors.New("VM is not placed in a compute cluster")
+	}
+
+	clusterConfigInfoEx, err := ccr.Configuration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ptr.Deref(clusterConfigInfoEx.DrsConfig.Enabled, false) {
+		return nil, errors.Errorf("DRS is not enabled on compute cluster %s", ccr.Reference().Value)
+	}
+
+	override := &DRSVMOverride{ClusterComputeResource: ccr, VM: vm.Reference()}
+	for i := range clusterConfigInfoEx.DrsVmConfig {
+		if clusterConfigInfoEx.DrsVmConfig[i].Key == override.VM {
+			override.Info = &clusterConfigInfoEx.DrsVmConfig[i]
+			break
+		}
+	}
+	return override, nil
+}
+
+// Matches returns whether the current override has the given values.
+func (o DRSVMOverride) Matches(enabled bool, behavior types.DrsBehavior) bool {
+	if o.Info == nil {
+		return false
+	}
+	return ptr.Deref(o.Info.Enabled, true) == enabled && (!enabled || o.Info.Behavior == behavior)
+}
+
+// Set adds or updates the override of the VM.
+func (o DRSVMOverride) Set(ctx context.Context, enabled bool, behavior types.DrsBehavior) (*object.Task, error) {
+	operation := types.ArrayUpdateOperationAdd
+	if o.Info != nil {
+		operation = types.ArrayUpdateOperationEdit
+	}
+
+	spec := &types.ClusterConfigSpecEx{
+		DrsVmConfigSpec: []types.ClusterDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: operation,
+				},
+				Info: &types.ClusterDrsVmConfigInfo{
+					Key:      o.VM,
+					Enabled:  ptr.To(enabled),
+					Behavior: behavior,
+				},
+			},
+		},
+	}
+	return o.ClusterComputeResource.Reconfigure(ctx, spec, true)
+}
+
+// Remove removes the override of the VM.
+func (o DRSVMOverride) Remove(ctx context.Context) (*object.Task, error) {
+	spec := &types.ClusterConfigSpecEx{
+		DrsVmConfigSpec: []types.ClusterDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: o.VM,
+				},
+			},
+		},
+	}
+	return o.ClusterComputeResource.Reconfigure(ctx, spec, true)
+}
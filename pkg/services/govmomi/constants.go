@@ -17,10 +17,18 @@ limitations under the License.
 // Package govmomi contains tools for interacting with vSphere APIs.
 package govmomi
 
+import "time"
+
 const (
 	morefTypeTask = "Task"
 )
 
+const (
+	// datastoreFullRetryInterval is the time to wait before retrying a task which failed
+	// because the datastore of the VM ran out of space.
+	datastoreFullRetryInterval = 5 * time.Minute
+)
+
 const (
 	guestInfoKeyMetadata = "guestinfo.metadata"
 )
@@ -18,6 +18,7 @@ package govmomi
 
 import (
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
@@ -30,6 +31,23 @@ type virtualMachineContext struct {
 	Obj       *object.VirtualMachine
 	State     *infrav1.VirtualMachine
 	IPAMState map[string]infrav1.NetworkDeviceSpec
+
+	// Properties are the properties of the VM retrieved at the start of the reconcile, which
+	// are shared by its steps. Most steps which change the VM end the reconcile; the others
+	// set PropertiesOutdated, so the properties are retrieved again before the next step.
+	Properties mo.VirtualMachine
+
+	// PropertiesOutdated is set by steps which issued a task or changed the VM without
+	// ending the reconcile.
+	PropertiesOutdated bool
+
+	// ConfigChange batches the changes which correct the drift of the VM, so they are
+	// applied by a single reconfigure task.
+	ConfigChange configChange
+
+	// ResourceDrift lists the resource allocation attributes of the VM which drifted
+	// from the spec.
+	ResourceDrift []string
 }
 
 func (c *virtualMachineContext) String() string {
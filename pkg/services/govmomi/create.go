@@ -18,11 +18,14 @@ package govmomi
 
 import (
 	"context"
+	"path"
+	"strings"
 
 	"github.com/pkg/errors"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/template"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
 )
 
@@ -34,3 +37,41 @@ func createVM(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData [
 	}
 	return vcenter.Clone(ctx, vmCtx, bootstrapData, format)
 }
+
+// importTemplate imports the template of the VM from the OVA defined in the VMContext
+// passed, unless the template exists already. This method waits for the import to complete.
+func importTemplate(ctx context.Context, vmCtx *capvcontext.VMContext) error {
+	ova := vmCtx.VSphereVM.Spec.OVA
+	if ova == nil {
+		return nil
+	}
+
+	name := vmCtx.VSphereVM.Spec.Template
+	if _, err := template.FindTemplate(ctx, vmCtx.Session, name); err == nil {
+		return nil
+	} else if !isVirtualMachineNotFound(errors.Cause(err)) {
+		return err
+	}
+
+	// Import the template into the folder of its inventory path, if any,
+	// otherwise into the folder of the VM.
+	folderPath := vmCtx.VSphereVM.Spec.Folder
+	if strings.Contains(name, "/") {
+		folderPath = path.Dir(name)
+	}
+	folder, err := vmCtx.Session.FolderOrDefault(ctx, folderPath)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get folder for template %s", name)
+	}
+	pool, err := vmCtx.Session.ResourcePoolOrDefault(ctx, vmCtx.VSphereVM.Spec.ResourcePool)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get resource pool for template %s", name)
+	}
+	datastore, err := vmCtx.Session.DatastoreOrDefault(ctx, vmCtx.VSphereVM.Spec.Datastore)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get datastore for template %s", name)
+	}
+
+	_, err = template.ImportOVA(ctx, vmCtx.Session, name, *ova, folder, pool, datastore)
+	return err
+}
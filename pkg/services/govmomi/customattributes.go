@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// virtualMachineMoType is the managed object type of the custom attribute definitions
+// created for VMs.
+const virtualMachineMoType = "VirtualMachine"
+
+// reconcileCustomAttributes ensures the custom attributes of the VM have the values defined
+// in the VSphereVM spec. Missing attribute definitions are created; attributes which are not
+// defined in the spec are left untouched.
+func (vms *VMService) reconcileCustomAttributes(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	customAttributes := virtualMachineCtx.VSphereVM.Spec.CustomAttributes
+	if len(customAttributes) == 0 {
+		log.V(5).Info("No custom attributes defined. skipping custom attributes reconciliation")
+		return nil
+	}
+
+	manager, err := object.GetCustomFieldsManager(virtualMachineCtx.Obj.Client())
+	if err != nil {
+		return errors.Wrapf(err, "unable to get custom attributes manager for VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	keys, err := customAttributeKeys(ctx, manager)
+	if err != nil {
+		return err
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	current := map[int32]string{}
+	for _, customValue := range virtualMachine.CustomValue {
+		if value, ok := customValue.(*types.CustomFieldStringValue); ok {
+			current[value.Key] = value.Value
+		}
+	}
+
+	names := make([]string, 0, len(customAttributes))
+	for name := range customAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key, ok := keys[name]
+		if !ok {
+			log.Info("Creating custom attribute definition", "name", name)
+			definition, err := manager.Add(ctx, name, virtualMachineMoType, nil, nil)
+			switch {
+			case isDuplicateName(err):
+				// The definition was created concurrently, or exists for another type.
+				if keys, err = customAttributeKeys(ctx, manager); err != nil {
+					return err
+				}
+				if key, ok = keys[name]; !ok {
+					return errors.Errorf("custom attribute %s is not defined for VMs", name)
+				}
+			case err != nil:
+				return errors.Wrapf(err, "unable to create custom attribute definition %s", name)
+			default:
+				key = definition.Key
+			}
+		}
+
+		if value, ok := current[key]; ok && value == customAttributes[name] {
+			continue
+		}
+		log.Info("Setting custom attribute of VM", "name", name)
+		if err := manager.Set(ctx, virtualMachineCtx.Obj.Reference(), key, customAttributes[name]); err != nil {
+			return errors.Wrapf(err, "unable to set custom attribute %s of VM %s", name, virtualMachineCtx.VSphereVM.Name)
+		}
+	}
+	return nil
+}
+
+// customAttributeKeys returns the keys of the custom attribute definitions which apply to
+// VMs by their name, i.e. the ones defined for VMs or for all types.
+func customAttributeKeys(ctx context.Context, manager *object.CustomFieldsManager) (map[string]int32, error) {
+	definitions, err := manager.Field(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get custom attribute definitions")
+	}
+	keys := map[string]int32{}
+	for _, definition := range definitions {
+		if definition.ManagedObjectType == virtualMachineMoType || definition.ManagedObjectType == "" {
+			keys[definition.Name] = definition.Key
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileCustomAttributes(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(customAttributes map[string]string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CustomAttributes: customAttributes,
+				},
+			},
+		}
+	}
+
+	// getCustomAttributes returns the custom attributes of the VM by their name.
+	getCustomAttributes := func(ctx context.Context, manager *object.CustomFieldsManager, vm *object.VirtualMachine) map[string]string {
+		definitions, err := manager.Field(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"customValue"}, &virtualMachine)).To(Succeed())
+		customAttributes := map[string]string{}
+		for _, customValue := range virtualMachine.CustomValue {
+			value := customValue.(*types.CustomFieldStringValue)
+			customAttributes[definitions.ByKey(value.Key).Name] = value.Value
+		}
+		return customAttributes
+	}
+
+	t.Run("when no custom attributes are defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(nil)
+		g.Expect(vms.reconcileCustomAttributes(context.Background(), vmCtx)).To(Succeed())
+	})
+
+	t.Run("when custom attributes are defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+
+			manager, err := object.GetCustomFieldsManager(c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			// A global definition which exists already and an attribute set by another tool.
+			_, err = manager.Add(ctx, "owner", "", nil, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			external, err := manager.Add(ctx, "backup-policy", virtualMachineMoType, nil, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(manager.Set(ctx, vm.Reference(), external.Key, "daily")).To(Succeed())
+
+			vmCtx.VSphereVM = newVSphereVM(map[string]string{"owner": "team-a", "cost-center": "1234"})
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			g.Expect(vms.reconcileCustomAttributes(ctx, vmCtx)).To(Succeed())
+			g.Expect(getCustomAttributes(ctx, manager, vm)).To(Equal(map[string]string{
+				"owner":         "team-a",
+				"cost-center":   "1234",
+				"backup-policy": "daily",
+			}))
+
+			definitions, err := manager.Field(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(definitions).To(HaveLen(3))
+
+			// Drift of the values is reconciled.
+			vmCtx.VSphereVM = newVSphereVM(map[string]string{"owner": "team-b"})
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			g.Expect(vms.reconcileCustomAttributes(ctx, vmCtx)).To(Succeed())
+			g.Expect(getCustomAttributes(ctx, manager, vm)).To(Equal(map[string]string{
+				"owner":         "team-b",
+				"cost-center":   "1234",
+				"backup-policy": "daily",
+			}))
+			return nil
+		})
+	})
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// relocationProgressSeparator separates the progress of the relocation from the message of
+// the DatastoresDrainedCondition.
+const relocationProgressSeparator = ", progress "
+
+// reconcileDatastoreDrain relocates the VM to another datastore by storage vMotion if its
+// disks or files are on one of the draining datastores of the spec.
+func (vms *VMService) reconcileDatastoreDrain(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	drainingDatastores := virtualMachineCtx.VSphereVM.Spec.DrainingDatastores
+	if len(drainingDatastores) == 0 {
+		if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition) {
+			conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition)
+		}
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var drained []string
+	for _, name := range vmDatastoreNames(*virtualMachine) {
+		if slices.Contains(drainingDatastores, name) {
+			drained = append(drained, name)
+		}
+	}
+	if len(drained) == 0 {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition)
+		return true, nil
+	}
+
+	target, err := vms.selectRelocationDatastore(ctx, virtualMachineCtx, *virtualMachine)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	log.Info("Relocating VM off draining datastores", "datastores", drained, "datastore", target.Name)
+	spec := types.VirtualMachineRelocateSpec{Datastore: types.NewReference(target.Reference())}
+	if storageProfileID := target.storageProfileID; storageProfileID != "" {
+		spec.Profile = []types.BaseVirtualMachineProfileSpec{
+			&types.VirtualMachineDefinedProfileSpec{ProfileId: storageProfileID},
+		}
+	}
+	task, err := virtualMachineCtx.Obj.Relocate(ctx, spec, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to relocate VM %s to datastore %s", virtualMachineCtx.VSphereVM.Name, target.Name)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocatingReason, clusterv1.ConditionSeverityInfo,
+		"relocating VM from datastores %s to %s", strings.Join(drained, ", "), target.Name)
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be relocated")
+	return false, nil
+}
+
+// relocationDatastore is a datastore the VM is relocated to, with the storage policy the
+// disks and files of the VM comply with.
+type relocationDatastore struct {
+	types.ManagedObjectReference
+	Name             string
+	storageProfileID string
+}
+
+// selectRelocationDatastore returns the datastore of the spec unless it is draining. Otherwise
+// it returns the datastore of the host of the VM with the most free space which is not draining
+// and compatible with the storage policy of the VM.
+func (vms *VMService) selectRelocationDatastore(ctx context.Context, virtualMachineCtx *virtualMachineContext, virtualMachine mo.VirtualMachine) (*relocationDatastore, error) {
+	vsphereVM := virtualMachineCtx.VSphereVM
+	target := &relocationDatastore{}
+	var pbmClient *pbm.Client
+	if vsphereVM.Spec.StoragePolicyName != "" {
+		var err error
+		if pbmClient, err = pbm.NewClient(ctx, virtualMachineCtx.Session.Client.Client); err != nil {
+			return nil, errors.Wrap(err, "unable to create pbm client")
+		}
+		if target.storageProfileID, err = pbmClient.ProfileIDByName(ctx, vsphereVM.Spec.StoragePolicyName); err != nil {
+			return nil, errors.Wrapf(err, "unable to get storage profile ID of storage policy %s", vsphereVM.Spec.StoragePolicyName)
+		}
+	}
+
+	if vsphereVM.Spec.Datastore != "" {
+		datastore, err := virtualMachineCtx.Session.Finder.Datastore(ctx, vsphereVM.Spec.Datastore)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get datastore %s", vsphereVM.Spec.Datastore)
+		}
+		if name := datastore.Name(); !slices.Contains(vsphereVM.Spec.DrainingDatastores, name) {
+			target.ManagedObjectReference = datastore.Reference()
+			target.Name = name
+			return target, nil
+		}
+	}
+
+	if virtualMachine.Runtime.Host == nil {
+		return nil, errors.Errorf("unable to get host of VM %s", vsphereVM.Name)
+	}
+	var host mo.HostSystem
+	pc := property.DefaultCollector(virtualMachineCtx.Session.Client.Client)
+	if err := pc.RetrieveOne(ctx, *virtualMachine.Runtime.Host, []string{"datastore"}, &host); err != nil {
+		return nil, errors.Wrapf(err, "unable to get datastores of host of VM %s", vsphereVM.Name)
+	}
+	var datastores []mo.Datastore
+	if len(host.Datastore) > 0 {
+		if err := pc.Retrieve(ctx, host.Datastore, []string{"name", "summary"}, &datastores); err != nil {
+			return nil, errors.Wrapf(err, "unable to get datastores of host of VM %s", vsphereVM.Name)
+		}
+	}
+	candidates := relocationCandidates(datastores, vsphereVM.Spec.DrainingDatastores)
+
+	if pbmClient != nil && len(candidates) > 0 {
+		compatible, err := compatibleDatastores(ctx, pbmClient, candidates, target.storageProfileID)
+		if err != nil {
+			return nil, err
+		}
+		candidates = slices.DeleteFunc(candidates, func(ds mo.Datastore) bool {
+			return !compatible[ds.Reference().Value]
+		})
+	}
+	if len(candidates) == 0 {
+		return nil, errors.Errorf("no datastore available to relocate VM %s off draining datastores %v", vsphereVM.Name, vsphereVM.Spec.DrainingDatastores)
+	}
+
+	target.ManagedObjectReference = candidates[0].Reference()
+	target.Name = candidates[0].Name
+	return target, nil
+}
+
+// compatibleDatastores returns the references of the datastores compatible with the storage
+// profile, keyed by their value.
+func compatibleDatastores(ctx context.Context, pbmClient *pbm.Client, datastores []mo.Datastore, storageProfileID string) (map[string]bool, error) {
+	hubs := make([]pbmTypes.PbmPlacementHub, 0, len(datastores))
+	for _, ds := range datastores {
+		hubs = append(hubs, pbmTypes.PbmPlacementHub{HubType: ds.Reference().Type, HubId: ds.Reference().Value})
+	}
+	constraints := []pbmTypes.BasePbmPlacementRequirement{
+		&pbmTypes.PbmPlacementCapabilityProfileRequirement{ProfileId: pbmTypes.PbmProfileId{UniqueId: storageProfileID}},
+	}
+	result, err := pbmClient.CheckRequirements(ctx, hubs, nil, constraints)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check requirements for storage policy")
+	}
+	compatible := map[string]bool{}
+	for _, hub := range result.CompatibleDatastores() {
+		compatible[hub.HubId] = true
+	}
+	return compatible, nil
+}
+
+// relocationCandidates returns the accessible datastores which are not draining, sorted by
+// their free space in descending order.
+func relocationCandidates(datastores []mo.Datastore, drainingDatastores []string) []mo.Datastore {
+	var candidates []mo.Datastore
+	for _, ds := range datastores {
+		if !ds.Summary.Accessible || slices.Contains(drainingDatastores, ds.Name) {
+			continue
+		}
+		candidates = append(candidates, ds)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Summary.FreeSpace > candidates[j].Summary.FreeSpace
+	})
+	return candidates
+}
+
+// vmDatastoreNames returns the names of the datastores of the files and disks of the VM. The
+// datastores of other devices, e.g. of the ISO images of CD-ROMs, are not relocated.
+func vmDatastoreNames(virtualMachine mo.VirtualMachine) []string {
+	if virtualMachine.Config == nil {
+		return nil
+	}
+	var names []string
+	add := func(fileName string) {
+		var path object.DatastorePath
+		if path.FromString(fileName) && !slices.Contains(names, path.Datastore) {
+			names = append(names, path.Datastore)
+		}
+	}
+	add(virtualMachine.Config.Files.VmPathName)
+	for _, device := range virtualMachine.Config.Hardware.Device {
+		disk, ok := device.(*types.VirtualDisk)
+		if !ok {
+			continue
+		}
+		if backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			add(backing.GetVirtualDeviceFileBackingInfo().FileName)
+		}
+	}
+	return names
+}
+
+// reportRelocationProgress adds the progress of the relocate task to the message of the
+// DatastoresDrainedCondition while the VM is relocated.
+func reportRelocationProgress(vsphereVM *infrav1.VSphereVM, progress int32) {
+	if conditions.GetReason(vsphereVM, infrav1.DatastoresDrainedCondition) != infrav1.RelocatingReason {
+		return
+	}
+	message, _, _ := strings.Cut(conditions.GetMessage(vsphereVM, infrav1.DatastoresDrainedCondition), relocationProgressSeparator)
+	conditions.MarkFalse(vsphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocatingReason, clusterv1.ConditionSeverityInfo,
+		"%s%s%d%%", message, relocationProgressSeparator, progress)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_vmDatastoreNames(t *testing.T) {
+	g := NewWithT(t)
+
+	disk := func(fileName string) types.BaseVirtualDevice {
+		return &types.VirtualDisk{VirtualDevice: types.VirtualDevice{
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: fileName},
+			},
+		}}
+	}
+	virtualMachine := mo.VirtualMachine{Config: &types.VirtualMachineConfigInfo{
+		Files: types.VirtualMachineFileInfo{VmPathName: "[ds-1] vm/vm.vmx"},
+		Hardware: types.VirtualHardware{Device: []types.BaseVirtualDevice{
+			disk("[ds-1] vm/vm.vmdk"),
+			disk("[ds-2] vm/vm_1.vmdk"),
+			&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{
+				Backing: &types.VirtualCdromIsoBackingInfo{
+					VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: "[ds-3] drivers.iso"},
+				},
+			}},
+		}},
+	}}
+
+	g.Expect(vmDatastoreNames(virtualMachine)).To(Equal([]string{"ds-1", "ds-2"}))
+	g.Expect(vmDatastoreNames(mo.VirtualMachine{})).To(BeEmpty())
+}
+
+func Test_relocationCandidates(t *testing.T) {
+	g := NewWithT(t)
+
+	datastore := func(name string, accessible bool, freeSpace int64) mo.Datastore {
+		return mo.Datastore{
+			ManagedEntity: mo.ManagedEntity{Name: name},
+			Summary:       types.DatastoreSummary{Accessible: accessible, FreeSpace: freeSpace},
+		}
+	}
+	candidates := relocationCandidates([]mo.Datastore{
+		datastore("ds-1", true, 10),
+		datastore("ds-2", true, 30),
+		datastore("ds-3", false, 50),
+		datastore("ds-4", true, 40),
+		datastore("ds-5", true, 20),
+	}, []string{"ds-4"})
+
+	names := make([]string, 0, len(candidates))
+	for _, ds := range candidates {
+		names = append(names, ds.Name)
+	}
+	g.Expect(names).To(Equal([]string{"ds-2", "ds-5", "ds-1"}))
+}
+
+func Test_reportRelocationProgress(t *testing.T) {
+	g := NewWithT(t)
+
+	vsphereVM := &infrav1.VSphereVM{}
+	reportRelocationProgress(vsphereVM, 10)
+	g.Expect(conditions.Has(vsphereVM, infrav1.DatastoresDrainedCondition)).To(BeFalse())
+
+	conditions.MarkFalse(vsphereVM, infrav1.DatastoresDrainedCondition, infrav1.RelocatingReason, clusterv1.ConditionSeverityInfo,
+		"relocating VM from datastores %s to %s", "ds-1", "ds-2")
+	reportRelocationProgress(vsphereVM, 10)
+	g.Expect(conditions.GetMessage(vsphereVM, infrav1.DatastoresDrainedCondition)).To(Equal("relocating VM from datastores ds-1 to ds-2, progress 10%"))
+	reportRelocationProgress(vsphereVM, 55)
+	g.Expect(conditions.GetMessage(vsphereVM, infrav1.DatastoresDrainedCondition)).To(Equal("relocating VM from datastores ds-1 to ds-2, progress 55%"))
+}
+
+func Test_reconcileDatastoreDrain(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	vms := &VMService{}
+
+	newVSphereVM := func(drainingDatastores ...string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{Spec: infrav1.VSphereVMSpec{DrainingDatastores: drainingDatastores}}
+	}
+
+	run := func(f func(ctx context.Context)) {
+		model := simulator.VPX()
+		model.Datastore = 2
+		g.Expect(model.Run(func(ctx context.Context, c *vim25.Client) error {
+			finder := find.NewFinder(c)
+			dc, err := finder.DefaultDatacenter(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			finder.SetDatacenter(dc)
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}, Finder: finder}
+			vmCtx.Obj, err = finder.VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			f(ctx)
+			return nil
+		})).To(Succeed())
+	}
+
+	t.Run("when no datastore is draining", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM()
+
+		ok, err := vms.reconcileDatastoreDrain(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition)).To(BeFalse())
+	})
+
+	t.Run("when the datastores of the VM are not draining", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("LocalDS_1")
+
+		run(func(ctx context.Context) {
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDatastoreDrain(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition)).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		})
+	})
+
+	t.Run("when a datastore of the VM is draining", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("LocalDS_0")
+
+		run(func(ctx context.Context) {
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDatastoreDrain(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition)).To(Equal(infrav1.RelocatingReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition)).To(Equal("relocating VM from datastores LocalDS_0 to LocalDS_1"))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+		})
+	})
+
+	t.Run("when all datastores are draining", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("LocalDS_0", "LocalDS_1")
+
+		run(func(ctx context.Context) {
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDatastoreDrain(ctx, vmCtx)
+			g.Expect(err).To(MatchError(ContainSubstring("no datastore available")))
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.DatastoresDrainedCondition)).To(Equal(infrav1.RelocationFailedReason))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		})
+	})
+}
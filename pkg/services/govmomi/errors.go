@@ -20,6 +20,8 @@ import (
 	"fmt"
 
 	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // errNotFound is returned by the findVM function when a VM is not found.
@@ -62,6 +64,48 @@ func isVirtualMachineNotFound(err error) bool {
 	}
 }
 
+// isManagedObjectNotFound returns true if vCenter reported the managed object of the
+// request as not found, e.g. because the VM was deleted in the meantime. Errors which
+// are not reported by vCenter, e.g. connectivity errors, are not considered.
+func isManagedObjectNotFound(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(types.ManagedObjectNotFound)
+		return ok
+	}
+	if soap.IsVimFault(err) {
+		_, ok := soap.ToVimFault(err).(*types.ManagedObjectNotFound)
+		return ok
+	}
+	return false
+}
+
+// isDuplicateName returns true if vCenter reported the name of the object to create
+// already exists, e.g. because it was created concurrently.
+func isDuplicateName(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(types.DuplicateName)
+		return ok
+	}
+	if soap.IsVimFault(err) {
+		_, ok := soap.ToVimFault(err).(*types.DuplicateName)
+		return ok
+	}
+	return false
+}
+
+// isFileAlreadyExists returns true if vCenter reported the file to create to exist already.
+func isFileAlreadyExists(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(types.FileAlreadyExists)
+		return ok
+	}
+	if soap.IsVimFault(err) {
+		_, ok := soap.ToVimFault(err).(*types.FileAlreadyExists)
+		return ok
+	}
+	return false
+}
+
 func wasNotFoundByBIOSUUID(err error) bool {
 	switch err.(type) {
 	case errNotFound, *errNotFound:
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+// minEVCModeHardwareVersion is the lowest hardware version which supports a per-VM EVC mode.
+const minEVCModeHardwareVersion = "vmx-14"
+
+// reconcileEVCMode applies the per-VM EVC mode defined in the spec to a powered off VM. The
+// EVC mode cannot be set by the clone spec, so it is applied before the VM is powered on.
+func (vms *VMService) reconcileEVCMode(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	key := virtualMachineCtx.VSphereVM.Spec.EVCMode
+	if key == "" {
+		log.V(5).Info("EVC mode not defined. skipping reconcile EVC mode")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get hardware version of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	mode, err := vcenter.EVCMode(ctx, virtualMachineCtx.Session.Client.Client, key)
+	if err != nil {
+		if errors.Is(err, vcenter.ErrEVCModeNotSupported) {
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.EVCModeNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		}
+		return false, err
+	}
+	if featureMasksMatch(virtualMachine.Runtime.FeatureMask, mode.FeatureMask) {
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(4).Info("VM is not powered off. skipping reconcile EVC mode")
+		return true, nil
+	}
+
+	tooOld, err := util.LessThan(virtualMachine.Config.Version, minEVCModeHardwareVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse hardware version")
+	}
+	if tooOld {
+		err := errors.Errorf("hardware version %s of VM %s does not support a per-VM EVC mode, %s or later is required", virtualMachine.Config.Version, virtualMachineCtx.VSphereVM.Name, minEVCModeHardwareVersion)
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.EVCModeNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	log.Info("Applying VM EVC mode", "evcMode", key)
+	res, err := methods.ApplyEvcModeVM_Task(ctx, virtualMachineCtx.Session.Client.Client, &types.ApplyEvcModeVM_Task{
+		This:          virtualMachineCtx.Obj.Reference(),
+		Mask:          mode.FeatureMask,
+		CompleteMasks: ptr.To(true),
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to apply EVC mode %s to VM %s", key, virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = res.Returnval.Value
+	log.Info("Wait for VM EVC mode to be applied")
+	return false, nil
+}
+
+// featureMasksMatch returns true if the feature masks applied to a VM are the ones of the
+// EVC mode, regardless of their order.
+func featureMasksMatch(current, desired []types.HostFeatureMask) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	values := make(map[string]string, len(current))
+	for _, mask := range current {
+		values[mask.Key] = mask.Value
+	}
+	for _, mask := range desired {
+		if value, ok := values[mask.Key]; !ok || value != mask.Value {
+			return false
+		}
+	}
+	return true
+}
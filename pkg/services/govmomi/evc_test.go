@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+// evcVirtualMachine is a simulated VM to which a per-VM EVC mode can be applied, which the
+// simulator does not support.
+type evcVirtualMachine struct {
+	mo.VirtualMachine
+}
+
+func (vm *evcVirtualMachine) ApplyEvcModeVMTask(ctx *simulator.Context, req *types.ApplyEvcModeVM_Task) soap.HasFault {
+	task := simulator.CreateTask(vm, "applyEvcModeVM", func(*simulator.Task) (types.AnyType, types.BaseMethodFault) {
+		vm.Runtime.FeatureMask = req.Mask
+		return nil, nil
+	})
+	return &methods.ApplyEvcModeVM_TaskBody{
+		Res: &types.ApplyEvcModeVM_TaskResponse{Returnval: task.Run(ctx)},
+	}
+}
+
+func Test_reconcileEVCMode(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(evcMode string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					EVCMode: evcMode,
+				},
+			},
+		}
+	}
+
+	// setup registers the intel-broadwell EVC mode and returns the powered off VM to which
+	// an EVC mode can be applied.
+	setup := func(ctx context.Context, c *vim25.Client) *evcVirtualMachine {
+		simulator.Map.Get(vim25.ServiceInstance).(*simulator.ServiceInstance).Capability.SupportedEVCMode = []types.EVCMode{{
+			ElementDescription: types.ElementDescription{Key: "intel-broadwell"},
+			Vendor:             "intel",
+			VendorTier:         6,
+			FeatureMask:        []types.HostFeatureMask{{Key: "cpuid.AVX2", FeatureName: "cpuid.AVX2", Value: "Val:1"}},
+		}}
+
+		vm, err := getPoweredoffVM(ctx, c)
+		g.Expect(err).ToNot(HaveOccurred())
+		evcVM := &evcVirtualMachine{VirtualMachine: simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine).VirtualMachine}
+		simulator.Map.Put(evcVM)
+
+		vmCtx.Obj = vm
+		vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+		return evcVM
+	}
+
+	t.Run("when the EVC mode is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM("")
+
+		ok, err := vms.reconcileEVCMode(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the EVC mode is unknown", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			setup(ctx, c)
+			vmCtx.VSphereVM = newVSphereVM("intel-unknown")
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileEVCMode(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.EVCModeNotSupportedReason))
+			return nil
+		})
+	})
+
+	t.Run("when the EVC mode is applied to a powered off VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			evcVM := setup(ctx, c)
+			vmCtx.VSphereVM = newVSphereVM("intel-broadwell")
+
+			// The hardware version vmx-13 of the VM does not support a per-VM EVC mode.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileEVCMode(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.EVCModeNotSupportedReason))
+
+			evcVM.Config.Version = "vmx-15"
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileEVCMode(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			// A second reconcile is a no-op once the EVC mode is applied.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileEVCMode(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
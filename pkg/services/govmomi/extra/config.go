@@ -31,6 +31,22 @@ const (
 	guestInfoIgnitionEncoding  = "guestinfo.ignition.config.data.encoding"
 	guestInfoCloudInitData     = "guestinfo.userdata"
 	guestInfoCloudInitEncoding = "guestinfo.userdata.encoding"
+
+	// OwnerUIDKey is the key used to track the UID of the VSphereVM
+	// a VM was cloned for.
+	OwnerUIDKey = "capv.vspherevm.uid"
+
+	// ClusterNameKey is the key used to track the name of the Cluster
+	// a VM belongs to.
+	ClusterNameKey = "capv.cluster"
+
+	// MachineNameKey is the key used to track the name of the Machine
+	// a VM was cloned for.
+	MachineNameKey = "capv.machine"
+
+	// NamespaceKey is the key used to track the namespace of the Cluster
+	// and Machine a VM belongs to.
+	NamespaceKey = "capv.namespace"
 )
 
 // SetCustomVMXKeys sets the custom VMX keys as
@@ -45,6 +61,15 @@ func (e *Config) SetCustomVMXKeys(customKeys map[string]string) error {
 	return nil
 }
 
+// SetOwnerUID sets the UID of the VSphereVM which owns the VM at the key
+// "capv.vspherevm.uid".
+func (e *Config) SetOwnerUID(uid string) {
+	*e = append(*e, &types.OptionValue{
+		Key:   OwnerUIDKey,
+		Value: uid,
+	})
+}
+
 // SetCloudInitUserData sets the cloud init user data at the key
 // "guestinfo.userdata" as a base64-encoded string.
 func (e *Config) SetCloudInitUserData(data []byte) {
@@ -72,6 +97,20 @@ func (e *Config) SetIgnitionUserData(data []byte) {
 	e.setUserData(guestInfoIgnitionData, guestInfoIgnitionEncoding, data)
 }
 
+// HasUserData returns true if the cloud init or ignition user data is set.
+func (e Config) HasUserData() bool {
+	for _, option := range e {
+		optVal := option.GetOptionValue()
+		if optVal == nil || (optVal.Key != guestInfoCloudInitData && optVal.Key != guestInfoIgnitionData) {
+			continue
+		}
+		if value, ok := optVal.Value.(string); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // setUserData sets the user data at the provided key
 // as a base64-encoded string.
 func (e *Config) setUserData(userdataKey, encodingKey string, data []byte) {
@@ -85,6 +85,31 @@ var _ = Describe("Config_SetCloudInitMetadata", func() {
 	)
 })
 
+var _ = Describe("Config_HasUserData", func() {
+	It("returns false if no user data is set", func() {
+		var config Config
+		config.SetCloudInitMetadata([]byte("some metadata"))
+		Expect(config.HasUserData()).To(BeFalse())
+	})
+
+	It("returns false if the user data is empty", func() {
+		config := Config{&types.OptionValue{Key: "guestinfo.userdata", Value: ""}}
+		Expect(config.HasUserData()).To(BeFalse())
+	})
+
+	It("returns true if the cloud init user data is set", func() {
+		var config Config
+		config.SetCloudInitUserData([]byte("some user data"))
+		Expect(config.HasUserData()).To(BeTrue())
+	})
+
+	It("returns true if the ignition user data is set", func() {
+		var config Config
+		config.SetIgnitionUserData([]byte("some user data"))
+		Expect(config.HasUserData()).To(BeTrue())
+	})
+})
+
 func base64Encode(s string) string {
 	return base64.StdEncoding.EncodeToString([]byte(s))
 }
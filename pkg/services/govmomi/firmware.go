@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+// minVirtualTPMHardwareVersion is the lowest hardware version which supports a virtual TPM.
+const minVirtualTPMHardwareVersion = "vmx-14"
+
+// reconcileFirmware ensures the firmware, Secure Boot and virtual TPM of a powered off VM
+// match the ones defined in the VSphereVM spec, or implied by TrustedLaunch. Secure Boot is
+// disabled if the VM likely failed to boot with it and the policy disables it. If TrustedLaunch
+// is true, the outcome is reported by the TrustedLaunchReady condition, otherwise failures are
+// reported by the VMProvisioned condition.
+func (vms *VMService) reconcileFirmware(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	trustedLaunch := ptr.Deref(virtualMachineCtx.VSphereVM.Spec.TrustedLaunch, false)
+	if !trustedLaunch {
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.TrustedLaunchCondition)
+	}
+	markFalse := func(reason string, severity clusterv1.ConditionSeverity, messageFormat string, messageArgs ...interface{}) {
+		conditionType := infrav1.VMProvisionedCondition
+		if trustedLaunch {
+			conditionType = infrav1.TrustedLaunchCondition
+		}
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, conditionType, reason, severity, messageFormat, messageArgs...)
+	}
+
+	firmware, secureBoot, virtualTPM := firmwareSettings(virtualMachineCtx.VSphereVM.Spec.VirtualMachineCloneSpec)
+	if isSecureBootDisabledOnFailure(virtualMachineCtx.VSphereVM) {
+		secureBoot = ptr.To(false)
+	}
+	if firmware == "" && secureBoot == nil && virtualTPM == nil {
+		log.V(5).Info("Firmware not defined. skipping reconcile firmware")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get firmware of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	var (
+		spec    types.VirtualMachineConfigSpec
+		changes []string
+	)
+	if firmware != "" && string(firmware) != virtualMachine.Config.Firmware {
+		spec.Firmware = string(firmware)
+		changes = append(changes, fmt.Sprintf("firmware %s -> %s", virtualMachine.Config.Firmware, firmware))
+	}
+	var currentSecureBoot bool
+	if virtualMachine.Config.BootOptions != nil {
+		currentSecureBoot = ptr.Deref(virtualMachine.Config.BootOptions.EfiSecureBootEnabled, false)
+	}
+	if secureBoot != nil && *secureBoot != currentSecureBoot {
+		spec.BootOptions = &types.VirtualMachineBootOptions{EfiSecureBootEnabled: secureBoot}
+		changes = append(changes, fmt.Sprintf("secureBoot %t -> %t", currentSecureBoot, *secureBoot))
+	}
+	tpms := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device).SelectByType((*types.VirtualTPM)(nil))
+	switch {
+	case virtualTPM == nil:
+	case *virtualTPM && len(tpms) == 0:
+		tooOld, err := util.LessThan(virtualMachine.Config.Version, minVirtualTPMHardwareVersion)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse hardware version")
+		}
+		if tooOld {
+			err := errors.Errorf("hardware version %s of VM %s does not support a virtual TPM, %s or later is required", virtualMachine.Config.Version, virtualMachineCtx.VSphereVM.Name, minVirtualTPMHardwareVersion)
+			markFalse(infrav1.TrustedLaunchPrerequisitesNotMetReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+		spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    &types.VirtualTPM{VirtualDevice: types.VirtualDevice{Key: -1}},
+		})
+		changes = append(changes, "virtualTPM false -> true")
+	case !*virtualTPM && len(tpms) > 0:
+		for _, tpm := range tpms {
+			spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    tpm,
+			})
+		}
+		changes = append(changes, "virtualTPM true -> false")
+	}
+
+	if len(changes) == 0 {
+		if trustedLaunch {
+			conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.TrustedLaunchCondition)
+		}
+		return true, nil
+	}
+
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(4).Info("VM is not powered off. skipping reconcile firmware", "changes", changes)
+		if trustedLaunch {
+			markFalse(infrav1.TrustedLaunchPendingReason, clusterv1.ConditionSeverityInfo, "%s will be applied when the VM is powered off", strings.Join(changes, ", "))
+		}
+		return true, nil
+	}
+
+	log.Info("Updating VM firmware", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(spec, changes...)
+	virtualMachineCtx.ConfigChange.addFailureHandler(func(err error) {
+		markFalse(infrav1.FirmwareReconfigureFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	})
+	return true, nil
+}
+
+// firmwareSettings returns the firmware, Secure Boot and virtual TPM settings of the clone
+// spec. TrustedLaunch implies the efi firmware, Secure Boot and a virtual TPM; the webhooks
+// reject conflicting individual settings.
+func firmwareSettings(spec infrav1.VirtualMachineCloneSpec) (infrav1.VirtualMachineFirmware, *bool, *bool) {
+	if ptr.Deref(spec.TrustedLaunch, false) {
+		return infrav1.VirtualMachineFirmwareEFI, ptr.To(true), ptr.To(true)
+	}
+	return spec.Firmware, spec.SecureBoot, spec.VirtualTPM
+}
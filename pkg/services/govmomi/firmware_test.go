@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileFirmware(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(cloneSpec infrav1.VirtualMachineCloneSpec) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: cloneSpec,
+			},
+		}
+	}
+
+	t.Run("when neither firmware nor trusted launch are set", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(infrav1.VirtualMachineCloneSpec{})
+		ok, err := vms.reconcileFirmware(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(BeFalse())
+	})
+
+	t.Run("when trusted launch is pending until the VM is powered off", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VirtualMachineCloneSpec{TrustedLaunch: ptr.To(true)})
+
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			task, err = vm.UpgradeVM(ctx, "vmx-15")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			task, err = vm.PowerOn(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(Equal(infrav1.TrustedLaunchPendingReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(ContainSubstring("secureBoot false -> true"))
+			return nil
+		})
+	})
+
+	t.Run("when trusted launch is applied to a powered off VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VirtualMachineCloneSpec{TrustedLaunch: ptr.To(true)})
+
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			// The hardware version vmx-13 of the VM does not support a virtual TPM.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(Equal(infrav1.TrustedLaunchPrerequisitesNotMetReason))
+			g.Expect(conditions.GetSeverity(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(Equal(ptr.To(clusterv1.ConditionSeverityWarning)))
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(BeFalse())
+
+			task, err = vm.UpgradeVM(ctx, "vmx-15")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.firmware", "config.bootOptions", "config.hardware.device"}, &virtualMachine)).To(Succeed())
+			g.Expect(virtualMachine.Config.Firmware).To(Equal(string(types.GuestOsDescriptorFirmwareTypeEfi)))
+			g.Expect(virtualMachine.Config.BootOptions.EfiSecureBootEnabled).To(Equal(ptr.To(true)))
+			g.Expect(object.VirtualDeviceList(virtualMachine.Config.Hardware.Device).SelectByType((*types.VirtualTPM)(nil))).To(HaveLen(1))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(BeTrue())
+			return nil
+		})
+	})
+
+	t.Run("when the virtual TPM is removed without trusted launch", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			task, err = vm.UpgradeVM(ctx, "vmx-15")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VirtualMachineCloneSpec{VirtualTPM: ptr.To(true)})
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VirtualMachineCloneSpec{VirtualTPM: ptr.To(false)})
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &virtualMachine)).To(Succeed())
+			g.Expect(object.VirtualDeviceList(virtualMachine.Config.Hardware.Device).SelectByType((*types.VirtualTPM)(nil))).To(BeEmpty())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.TrustedLaunchCondition)).To(BeFalse())
+			return nil
+		})
+	})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+// reconcileFolder moves the VM into the folder of the spec if the VMFolderMove feature
+// gate is enabled and the folder changed. A missing folder is created first.
+func (vms *VMService) reconcileFolder(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !feature.Gates.Enabled(feature.VMFolderMove) {
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	folder, err := ensureFolder(ctx, virtualMachineCtx.Session, virtualMachineCtx.VSphereVM.Spec.Folder)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get folder for VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	if virtualMachine.Parent != nil && *virtualMachine.Parent == folder.Reference() {
+		return true, nil
+	}
+
+	log.Info("Moving VM into folder", "folder", folder.InventoryPath)
+	task, err := folder.MoveInto(ctx, []types.ManagedObjectReference{virtualMachineCtx.Obj.Reference()})
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ReconfigureFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to move VM %s into folder %s", virtualMachineCtx.VSphereVM.Name, folder.InventoryPath)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.MovingToFolderReason, clusterv1.ConditionSeverityInfo,
+		"moving VM into folder %s", folder.InventoryPath)
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be moved into folder")
+	return false, nil
+}
+
+// ensureFolder returns the folder at the given inventory path, or the default folder if
+// the path is empty. The folder and its missing parent folders are created if needed.
+func ensureFolder(ctx context.Context, s *session.Session, folderPath string) (*object.Folder, error) {
+	folder, err := s.FolderOrDefault(ctx, folderPath)
+	if err == nil || !isFolderNotFound(err) {
+		return folder, err
+	}
+
+	parentPath := path.Dir(folderPath)
+	if parentPath == folderPath {
+		return nil, err
+	}
+	if parentPath == "." {
+		parentPath = ""
+	}
+	parent, err := ensureFolder(ctx, s, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := path.Base(folderPath)
+	folder, err = parent.CreateFolder(ctx, name)
+	if err != nil {
+		// The folder may have been created concurrently for another VM.
+		if isDuplicateName(err) {
+			return s.FolderOrDefault(ctx, folderPath)
+		}
+		return nil, errors.Wrapf(err, "unable to create folder %s", folderPath)
+	}
+	folder.InventoryPath = path.Join(parent.InventoryPath, name)
+	return folder, nil
+}
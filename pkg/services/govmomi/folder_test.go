@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileFolder(t *testing.T) {
+	var (
+		g     *WithT
+		vmCtx *virtualMachineContext
+		vms   *VMService
+	)
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+		vms = &VMService{}
+	}
+
+	setup := func(ctx context.Context, c *vim25.Client) {
+		finder := find.NewFinder(c)
+		datacenter, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(datacenter)
+
+		vm, err := finder.VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+		vmCtx.Obj = vm
+		vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}, Finder: finder}
+	}
+
+	// waitForTask waits for the task referenced by the status of the VSphereVM.
+	waitForTask := func(ctx context.Context, c *vim25.Client) {
+		task := object.NewTask(c, types.ManagedObjectReference{Type: "Task", Value: vmCtx.VSphereVM.Status.TaskRef})
+		g.Expect(task.Wait(ctx)).To(Succeed())
+		vmCtx.VSphereVM.Status.TaskRef = ""
+	}
+
+	parentFolder := func(ctx context.Context) string {
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vmCtx.Obj.Properties(ctx, vmCtx.Obj.Reference(), []string{"parent"}, &virtualMachine)).To(Succeed())
+		folder := object.NewFolder(vmCtx.Obj.Client(), *virtualMachine.Parent)
+		name, err := folder.ObjectName(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		return name
+	}
+
+	t.Run("when the feature gate is disabled", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			setup(ctx, c)
+			vmCtx.VSphereVM.Spec.Folder = "/DC0/vm/moved"
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileFolder(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the feature gate is enabled", func(t *testing.T) {
+		g = NewWithT(t)
+		g.Expect(feature.MutableGates.Set("VMFolderMove=true")).To(Succeed())
+		t.Cleanup(func() { _ = feature.MutableGates.Set("VMFolderMove=false") })
+
+		t.Run("the VM in the folder of the spec is not moved", func(t *testing.T) {
+			g = NewWithT(t)
+			before()
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+				vmCtx.VSphereVM.Spec.Folder = "/DC0/vm"
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileFolder(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				return nil
+			})
+		})
+
+		t.Run("the VM is moved into the missing folder of the spec", func(t *testing.T) {
+			g = NewWithT(t)
+			before()
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+				vmCtx.VSphereVM.Spec.Folder = "/DC0/vm/team-a/nodes"
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileFolder(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeFalse())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(Equal(infrav1.MovingToFolderReason))
+				g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(Equal("moving VM into folder /DC0/vm/team-a/nodes"))
+				waitForTask(ctx, c)
+				g.Expect(parentFolder(ctx)).To(Equal("nodes"))
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err = vms.reconcileFolder(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				return nil
+			})
+		})
+
+		t.Run("the VM is moved into an existing relative folder of the spec", func(t *testing.T) {
+			g = NewWithT(t)
+			before()
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+				defaultFolder, err := vmCtx.Session.Finder.DefaultFolder(ctx)
+				g.Expect(err).ToNot(HaveOccurred())
+				_, err = defaultFolder.CreateFolder(ctx, "existing")
+				g.Expect(err).ToNot(HaveOccurred())
+				vmCtx.VSphereVM.Spec.Folder = "existing"
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileFolder(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeFalse())
+				waitForTask(ctx, c)
+				g.Expect(parentFolder(ctx)).To(Equal("existing"))
+				return nil
+			})
+		})
+	})
+}
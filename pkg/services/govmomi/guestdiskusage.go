@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileGuestDiskUsage reports the usage of the guest filesystems of the VM, as reported by
+// VMware Tools, in the status of the VSphereVM. The usage is only refreshed if the guest disk
+// usage refresh interval is not zero and passed since the last refresh, so changes of the free
+// space do not cause reconciles on their own.
+func (vms *VMService) reconcileGuestDiskUsage(virtualMachineCtx *virtualMachineContext) {
+	interval := virtualMachineCtx.GuestDiskUsageRefreshInterval
+	if interval == 0 {
+		virtualMachineCtx.VSphereVM.Status.GuestDiskUsage = nil
+		return
+	}
+	if usage := virtualMachineCtx.VSphereVM.Status.GuestDiskUsage; usage != nil && time.Since(usage.LastRefreshTime.Time) < interval {
+		return
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	// Guests without VMware Tools do not report their disks, so the usage is omitted.
+	if virtualMachine.Guest == nil || len(virtualMachine.Guest.Disk) == 0 {
+		virtualMachineCtx.VSphereVM.Status.GuestDiskUsage = nil
+		return
+	}
+	virtualMachineCtx.VSphereVM.Status.GuestDiskUsage = &infrav1.GuestDiskUsage{
+		Filesystems:     guestFilesystemUsage(virtualMachine.Guest.Disk),
+		LastRefreshTime: metav1.Now(),
+	}
+}
+
+// guestFilesystemUsage returns the usage of the given guest disks.
+func guestFilesystemUsage(disks []types.GuestDiskInfo) []infrav1.GuestFilesystemUsage {
+	filesystems := make([]infrav1.GuestFilesystemUsage, 0, len(disks))
+	for _, disk := range disks {
+		filesystems = append(filesystems, infrav1.GuestFilesystemUsage{
+			Path:           disk.DiskPath,
+			FilesystemType: disk.FilesystemType,
+			CapacityBytes:  disk.Capacity,
+			FreeBytes:      disk.FreeSpace,
+		})
+	}
+	return filesystems
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileGuestDiskUsage(t *testing.T) {
+	rootDisk := types.GuestDiskInfo{DiskPath: "/", FilesystemType: "ext4", Capacity: 20 << 30, FreeSpace: 5 << 30}
+
+	t.Run("when the reporting is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{
+			GuestDiskUsage: &infrav1.GuestDiskUsage{Filesystems: []infrav1.GuestFilesystemUsage{{Path: "/"}}},
+		}}
+
+		(&VMService{}).reconcileGuestDiskUsage(vmCtx)
+		g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage).To(BeNil())
+	})
+
+	t.Run("when the guest does not run VMware Tools", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine).Guest.Disk = nil
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.GuestDiskUsageRefreshInterval = time.Minute
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			(&VMService{}).reconcileGuestDiskUsage(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage).To(BeNil())
+			return nil
+		})
+	})
+
+	t.Run("when the guest reports its disks", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+			simVM.Guest.Disk = []types.GuestDiskInfo{rootDisk}
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.GuestDiskUsageRefreshInterval = time.Minute
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+			vms := &VMService{}
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			vms.reconcileGuestDiskUsage(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage).ToNot(BeNil())
+			g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage.Filesystems).To(Equal([]infrav1.GuestFilesystemUsage{
+				{Path: "/", FilesystemType: "ext4", CapacityBytes: 20 << 30, FreeBytes: 5 << 30},
+			}))
+
+			// The usage is not refreshed before the interval passed.
+			simVM.Guest.Disk[0].FreeSpace = 1 << 30
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			vms.reconcileGuestDiskUsage(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage.Filesystems[0].FreeBytes).To(Equal(int64(5 << 30)))
+
+			vmCtx.VSphereVM.Status.GuestDiskUsage.LastRefreshTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			vms.reconcileGuestDiskUsage(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestDiskUsage.Filesystems[0].FreeBytes).To(Equal(int64(1 << 30)))
+			return nil
+		})
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/extra"
+)
+
+// reconcileGuestInfoBootstrapData ensures the bootstrap data is set in the guestinfo
+// variables of a powered off VM, unless the cloud-init datasource is NoCloud. The bootstrap
+// data is set when the VM is cloned; if it was removed from the VM, e.g. by another tool, it
+// is set again before the VM is powered on and the BootstrapDataAvailable condition records
+// the remediation.
+func (vms *VMService) reconcileGuestInfoBootstrapData(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if virtualMachineCtx.VSphereVM.Spec.CloudInitDatasource == infrav1.CloudInitDatasourceNoCloud {
+		log.V(5).Info("Cloud-init datasource is NoCloud. skipping reconcile guestinfo bootstrap data")
+		return true, nil
+	}
+	if virtualMachineCtx.VSphereVM.Spec.BootstrapRef == nil {
+		log.V(5).Info("VM has no bootstrap data. skipping reconcile guestinfo bootstrap data")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping reconcile guestinfo bootstrap data")
+		return true, nil
+	}
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get config of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	if extra.Config(virtualMachine.Config.ExtraConfig).HasUserData() {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)
+		return true, nil
+	}
+
+	bootstrapData, format, err := vms.getBootstrapData(ctx, &virtualMachineCtx.VMContext)
+	if err != nil {
+		return false, err
+	}
+	var extraConfig extra.Config
+	switch {
+	case len(bootstrapData) == 0:
+		return true, nil
+	case format == bootstrapv1.CloudConfig:
+		extraConfig.SetCloudInitUserData(bootstrapData)
+	case format == bootstrapv1.Ignition:
+		extraConfig.SetIgnitionUserData(bootstrapData)
+	default:
+		return true, nil
+	}
+
+	log.Info("Bootstrap data is missing from VM. setting it again")
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition, infrav1.BootstrapDataMissingReason, clusterv1.ConditionSeverityWarning,
+		"bootstrap data was removed from the guestinfo variables of the VM, setting it again")
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		ExtraConfig: extraConfig,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to set bootstrap data on vm %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM bootstrap data to be set")
+	return false, nil
+}
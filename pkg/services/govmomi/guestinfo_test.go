@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileGuestInfoBootstrapData(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func(format string) {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bootstrap-data",
+				Namespace: "my-namespace",
+			},
+			Data: map[string][]byte{
+				"format": []byte(format),
+				"value":  []byte("some bootstrap data"),
+			},
+		}).Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(datasource infrav1.CloudInitDatasource) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				BootstrapRef: &corev1.ObjectReference{
+					Name:      "bootstrap-data",
+					Namespace: "my-namespace",
+				},
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CloudInitDatasource: datasource,
+				},
+			},
+		}
+	}
+
+	getExtraConfig := func(ctx context.Context, vm *object.VirtualMachine) map[string]string {
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.extraConfig"}, &virtualMachine)).To(Succeed())
+		extraConfig := map[string]string{}
+		for _, option := range virtualMachine.Config.ExtraConfig {
+			if optVal := option.GetOptionValue(); optVal != nil {
+				extraConfig[optVal.Key], _ = optVal.Value.(string)
+			}
+		}
+		return extraConfig
+	}
+
+	t.Run("when the cloud-init datasource is NoCloud", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+		vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceNoCloud)
+		ok, err := vms.reconcileGuestInfoBootstrapData(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the bootstrap data is missing from a powered on VM", func(t *testing.T) {
+		g = NewWithT(t)
+		before("cloud-config")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloudInitDatasourceVMware)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileGuestInfoBootstrapData(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	for _, tt := range []struct {
+		format  string
+		dataKey string
+	}{
+		{format: "cloud-config", dataKey: "guestinfo.userdata"},
+		{format: "ignition", dataKey: "guestinfo.ignition.config.data"},
+	} {
+		t.Run("when the "+tt.format+" bootstrap data was removed from a powered off VM", func(t *testing.T) {
+			g = NewWithT(t)
+			before(tt.format)
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				vm, err := getPoweredoffVM(ctx, c)
+				g.Expect(err).ToNot(HaveOccurred())
+				vmCtx.Obj = vm
+				vmCtx.VSphereVM = newVSphereVM("")
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileGuestInfoBootstrapData(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeFalse())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(Equal(infrav1.BootstrapDataMissingReason))
+
+				task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+				g.Expect(task.Wait(ctx)).To(Succeed())
+				extraConfig := getExtraConfig(ctx, vm)
+				g.Expect(extraConfig).To(HaveKeyWithValue(tt.dataKey, "c29tZSBib290c3RyYXAgZGF0YQ=="))
+				g.Expect(extraConfig).To(HaveKeyWithValue(tt.dataKey+".encoding", "base64"))
+
+				// The condition is true once the bootstrap data is set again.
+				vmCtx.VSphereVM.Status.TaskRef = ""
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err = vms.reconcileGuestInfoBootstrapData(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.BootstrapDataAvailableCondition)).To(BeTrue())
+				return nil
+			})
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+)
+
+// guestNetworkReconfigurationEnabled returns true if the guest of the VM reconfigures its
+// network when the network metadata changes. Only the cloud-init of Linux guests applies
+// the network metadata on boot.
+func guestNetworkReconfigurationEnabled(vsphereVM *infrav1.VSphereVM) bool {
+	return feature.Gates.Enabled(feature.GuestNetworkReconfiguration) && vsphereVM.Spec.OS != infrav1.Windows
+}
+
+// addNetworkUpdateEvents configures cloud-init to apply the network metadata on every boot
+// rather than only on the first boot of the instance, so a reboot of the guest applies
+// changed network metadata.
+func addNetworkUpdateEvents(data []byte, format bootstrapv1.Format) ([]byte, error) {
+	if format != bootstrapv1.CloudConfig || len(data) == 0 {
+		return data, nil
+	}
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	config["updates"] = map[string]interface{}{
+		"network": map[string]interface{}{
+			"when": []interface{}{"boot-new-instance", "boot"},
+		},
+	}
+	return marshalCloudConfig(header, config)
+}
+
+// reconcileGuestNetwork reboots the guest once the network metadata of a provisioned VM was
+// updated, so cloud-init applies it. The guest of a powered off VM applies it on power on.
+func (vms *VMService) reconcileGuestNetwork(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	switch conditions.GetReason(virtualMachineCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition) {
+	case infrav1.GuestRebootPendingReason, infrav1.GuestRebootFailedReason:
+	default:
+		return true, nil
+	}
+
+	powerState, err := vms.getPowerState(ctx, virtualMachineCtx)
+	if err != nil {
+		return false, err
+	}
+	if powerState != infrav1.VirtualMachinePowerStatePoweredOn {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition)
+		return true, nil
+	}
+
+	log.Info("Rebooting guest to apply the network metadata")
+	if err := virtualMachineCtx.Obj.RebootGuest(ctx); err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition, infrav1.GuestRebootFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "failed to reboot guest of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition)
+	return true, nil
+}
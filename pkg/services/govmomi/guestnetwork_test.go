@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+)
+
+func Test_guestNetworkReconfigurationEnabled(t *testing.T) {
+	g := NewWithT(t)
+	linuxVM := &infrav1.VSphereVM{Spec: infrav1.VSphereVMSpec{VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{OS: infrav1.Linux}}}
+	windowsVM := &infrav1.VSphereVM{Spec: infrav1.VSphereVMSpec{VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{OS: infrav1.Windows}}}
+
+	g.Expect(guestNetworkReconfigurationEnabled(linuxVM)).To(BeFalse())
+
+	g.Expect(feature.MutableGates.Set("GuestNetworkReconfiguration=true")).To(Succeed())
+	t.Cleanup(func() { _ = feature.MutableGates.Set("GuestNetworkReconfiguration=false") })
+	g.Expect(guestNetworkReconfigurationEnabled(linuxVM)).To(BeTrue())
+	g.Expect(guestNetworkReconfigurationEnabled(windowsVM)).To(BeFalse())
+}
+
+func Test_addNetworkUpdateEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		format   bootstrapv1.Format
+		expected string
+	}{
+		{
+			name:     "cloud-config",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- echo\nupdates:\n  network:\n    when:\n    - boot-new-instance\n    - boot\n",
+		},
+		{
+			name:     "ignition",
+			data:     `{"ignition":{"version":"3.1.0"}}`,
+			format:   bootstrapv1.Ignition,
+			expected: `{"ignition":{"version":"3.1.0"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addNetworkUpdateEvents([]byte(tt.data), tt.format)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func Test_reconcileGuestNetwork(t *testing.T) {
+	tests := []struct {
+		name           string
+		reason         string
+		powerOff       bool
+		toolsRunning   bool
+		expectedReason string
+		wantErr        bool
+	}{
+		{
+			name: "without pending reboot",
+		},
+		{
+			name:     "with pending reboot of a powered off VM",
+			reason:   infrav1.GuestRebootPendingReason,
+			powerOff: true,
+		},
+		{
+			name:         "with pending reboot of a powered on VM",
+			reason:       infrav1.GuestRebootPendingReason,
+			toolsRunning: true,
+		},
+		{
+			name:         "with failed reboot of a powered on VM",
+			reason:       infrav1.GuestRebootFailedReason,
+			toolsRunning: true,
+		},
+		{
+			name:           "when the guest tools are not running",
+			reason:         infrav1.GuestRebootPendingReason,
+			expectedReason: infrav1.GuestRebootFailedReason,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			simulator.Test(func(ctx context.Context, c *vim25.Client) {
+				vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+				g.Expect(err).ToNot(HaveOccurred())
+				if tt.powerOff {
+					task, err := vm.PowerOff(ctx)
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(task.Wait(ctx)).To(Succeed())
+				}
+				if tt.toolsRunning {
+					simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+					simVM.Guest.ToolsRunningStatus = string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)
+				}
+
+				vmCtx := emptyVirtualMachineContext()
+				vmCtx.Obj = vm
+				vmCtx.VSphereVM = &infrav1.VSphereVM{}
+				if tt.reason != "" {
+					conditions.MarkFalse(vmCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition, tt.reason, clusterv1.ConditionSeverityInfo, "")
+				}
+
+				ok, err := (&VMService{}).reconcileGuestNetwork(ctx, vmCtx)
+				switch {
+				case tt.wantErr:
+					g.Expect(err).To(HaveOccurred())
+					g.Expect(ok).To(BeFalse())
+					g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition)).To(Equal(tt.expectedReason))
+				case tt.reason == "":
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(ok).To(BeTrue())
+					g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition)).To(BeFalse())
+				default:
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(ok).To(BeTrue())
+					g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition)).To(BeTrue())
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	govmominet "sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/net"
+)
+
+// reconcileGuestNetworkStatus reports the network configuration of the guest, as observed
+// by VMware Tools, in the status of the VSphereVM.
+func (vms *VMService) reconcileGuestNetworkStatus(virtualMachineCtx *virtualMachineContext) {
+	virtualMachine := &virtualMachineCtx.Properties
+	// Guests without VMware Tools do not report their network, so the configuration is omitted.
+	if virtualMachine.Guest == nil || len(virtualMachine.Guest.Net) == 0 {
+		virtualMachineCtx.VSphereVM.Status.GuestNetwork = nil
+		return
+	}
+	virtualMachineCtx.VSphereVM.Status.GuestNetwork = guestNetworkStatus(virtualMachine.Guest)
+}
+
+// guestNetworkStatus returns the network configuration reported by the given guest.
+// Addresses which are only reachable from the guest, e.g. link-local addresses, are
+// omitted like in the network status of the VM.
+func guestNetworkStatus(guest *types.GuestInfo) *infrav1.GuestNetworkStatus {
+	status := &infrav1.GuestNetworkStatus{}
+	for _, nic := range guest.Net {
+		device := infrav1.GuestNetworkDeviceStatus{
+			MACAddr:     nic.MacAddress,
+			Connected:   nic.Connected,
+			NetworkName: nic.Network,
+		}
+		if nic.IpConfig != nil {
+			for _, addr := range nic.IpConfig.IpAddress {
+				if govmominet.ErrOnLocalOnlyIPAddr(addr.IpAddress) != nil {
+					continue
+				}
+				device.IPAddrs = append(device.IPAddrs, fmt.Sprintf("%s/%d", addr.IpAddress, addr.PrefixLength))
+			}
+		}
+		status.Devices = append(status.Devices, device)
+	}
+
+	for _, stack := range guest.IpStack {
+		if stack.IpRouteConfig != nil {
+			for _, route := range stack.IpRouteConfig.IpRoute {
+				if route.PrefixLength != 0 || route.Gateway.IpAddress == "" {
+					continue
+				}
+				if ip := net.ParseIP(route.Network); ip == nil || !ip.IsUnspecified() {
+					continue
+				}
+				status.Gateways = append(status.Gateways, route.Gateway.IpAddress)
+			}
+		}
+		if dns := stack.DnsConfig; dns != nil {
+			status.Nameservers = append(status.Nameservers, dns.IpAddress...)
+			status.SearchDomains = append(status.SearchDomains, dns.SearchDomain...)
+			if status.Hostname == "" {
+				status.Hostname = dns.HostName
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileGuestNetworkStatus(t *testing.T) {
+	t.Run("when the guest does not run VMware Tools", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine).Guest.Net = nil
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{
+				GuestNetwork: &infrav1.GuestNetworkStatus{Hostname: "stale"},
+			}}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			(&VMService{}).reconcileGuestNetworkStatus(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestNetwork).To(BeNil())
+			return nil
+		})
+	})
+
+	t.Run("when the guest reports its network", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+			simVM.Guest.Net = []types.GuestNicInfo{{
+				MacAddress: "00:50:56:00:00:01",
+				Connected:  true,
+				Network:    "VM Network",
+				IpConfig: &types.NetIpConfigInfo{IpAddress: []types.NetIpConfigInfoIpAddress{
+					{IpAddress: "192.168.4.21", PrefixLength: 24},
+				}},
+			}}
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			(&VMService{}).reconcileGuestNetworkStatus(vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.GuestNetwork).ToNot(BeNil())
+			g.Expect(vmCtx.VSphereVM.Status.GuestNetwork.Devices).To(Equal([]infrav1.GuestNetworkDeviceStatus{
+				{MACAddr: "00:50:56:00:00:01", Connected: true, NetworkName: "VM Network", IPAddrs: []string{"192.168.4.21/24"}},
+			}))
+			return nil
+		})
+	})
+}
+
+func Test_guestNetworkStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	status := guestNetworkStatus(&types.GuestInfo{
+		Net: []types.GuestNicInfo{{
+			MacAddress: "00:50:56:00:00:01",
+			Connected:  true,
+			Network:    "VM Network",
+			IpConfig: &types.NetIpConfigInfo{IpAddress: []types.NetIpConfigInfoIpAddress{
+				{IpAddress: "192.168.4.21", PrefixLength: 24},
+				{IpAddress: "fe80::250:56ff:fe00:1", PrefixLength: 64},
+				{IpAddress: "fd00::21", PrefixLength: 64},
+			}},
+		}},
+		IpStack: []types.GuestStackInfo{{
+			DnsConfig: &types.NetDnsConfigInfo{
+				HostName:     "worker-abcde",
+				IpAddress:    []string{"192.168.4.1"},
+				SearchDomain: []string{"example.com"},
+			},
+			IpRouteConfig: &types.NetIpRouteConfigInfo{IpRoute: []types.NetIpRouteConfigInfoIpRoute{
+				{Network: "0.0.0.0", PrefixLength: 0, Gateway: types.NetIpRouteConfigInfoGateway{IpAddress: "192.168.4.1"}},
+				{Network: "192.168.4.0", PrefixLength: 24, Gateway: types.NetIpRouteConfigInfoGateway{}},
+				{Network: "::", PrefixLength: 0, Gateway: types.NetIpRouteConfigInfoGateway{IpAddress: "fd00::1"}},
+			}},
+		}},
+	})
+
+	g.Expect(status).To(Equal(&infrav1.GuestNetworkStatus{
+		Devices: []infrav1.GuestNetworkDeviceStatus{{
+			MACAddr:     "00:50:56:00:00:01",
+			Connected:   true,
+			NetworkName: "VM Network",
+			IPAddrs:     []string{"192.168.4.21/24", "fd00::21/64"},
+		}},
+		Gateways:      []string{"192.168.4.1", "fd00::1"},
+		Nameservers:   []string{"192.168.4.1"},
+		SearchDomains: []string{"example.com"},
+		Hostname:      "worker-abcde",
+	}))
+}
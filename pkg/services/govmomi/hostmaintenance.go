@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// enterMaintenanceModeTaskDescriptionID is the description ID of tasks entering an ESXi host
+// into maintenance mode.
+const enterMaintenanceModeTaskDescriptionID = "HostSystem.enterMaintenanceMode"
+
+// reconcileHostMaintenance reports an ESXi host of the VM which enters or is in maintenance
+// mode by the HostAvailableCondition, which the VSphereVM controller uses to taint the node
+// of the VSphereVM. The host is only checked if the host maintenance check interval is not
+// zero; otherwise an existing condition is marked true, so the taint is removed. A failed
+// check does not block the reconcile and keeps the condition.
+func (vms *VMService) reconcileHostMaintenance(ctx context.Context, virtualMachineCtx *virtualMachineContext) {
+	log := ctrl.LoggerFrom(ctx)
+	vsphereVM := virtualMachineCtx.VSphereVM
+
+	if virtualMachineCtx.HostMaintenanceCheckInterval == 0 {
+		if conditions.Has(vsphereVM, infrav1.HostAvailableCondition) {
+			conditions.MarkTrue(vsphereVM, infrav1.HostAvailableCondition)
+		}
+		return
+	}
+
+	host, inMaintenanceMode, enteringMaintenanceMode, err := vms.getHostMaintenanceMode(ctx, virtualMachineCtx)
+	if err != nil {
+		log.Error(err, "Failed to get maintenance mode of host")
+		return
+	}
+	switch {
+	case inMaintenanceMode:
+		conditions.MarkFalse(vsphereVM, infrav1.HostAvailableCondition, infrav1.InMaintenanceModeReason, clusterv1.ConditionSeverityInfo,
+			"host %s is in maintenance mode", host)
+	case enteringMaintenanceMode:
+		conditions.MarkFalse(vsphereVM, infrav1.HostAvailableCondition, infrav1.EnteringMaintenanceModeReason, clusterv1.ConditionSeverityInfo,
+			"host %s is entering maintenance mode", host)
+	case conditions.Has(vsphereVM, infrav1.HostAvailableCondition):
+		conditions.MarkTrue(vsphereVM, infrav1.HostAvailableCondition)
+	}
+}
+
+// getHostMaintenanceMode returns the name of the ESXi host of the VM, whether it is in
+// maintenance mode and whether a recent task of the host enters it into maintenance mode.
+func (vms *VMService) getHostMaintenanceMode(ctx context.Context, virtualMachineCtx *virtualMachineContext) (string, bool, bool, error) {
+	host, err := virtualMachineCtx.Obj.HostSystem(ctx)
+	if err != nil {
+		return "", false, false, errors.Wrapf(err, "unable to get host of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	var hostSystem mo.HostSystem
+	if err := host.Properties(ctx, host.Reference(), []string{"name", "runtime.inMaintenanceMode", "recentTask"}, &hostSystem); err != nil {
+		return "", false, false, errors.Wrapf(err, "unable to get maintenance mode of host %s", host.Reference().Value)
+	}
+	if hostSystem.Runtime.InMaintenanceMode || len(hostSystem.RecentTask) == 0 {
+		return hostSystem.Name, hostSystem.Runtime.InMaintenanceMode, false, nil
+	}
+
+	var tasks []mo.Task
+	if err := property.DefaultCollector(host.Client()).Retrieve(ctx, hostSystem.RecentTask, []string{"info"}, &tasks); err != nil {
+		return "", false, false, errors.Wrapf(err, "unable to get recent tasks of host %s", hostSystem.Name)
+	}
+	for _, task := range tasks {
+		if task.Info.DescriptionId != enterMaintenanceModeTaskDescriptionID {
+			continue
+		}
+		if task.Info.State == types.TaskInfoStateQueued || task.Info.State == types.TaskInfoStateRunning {
+			return hostSystem.Name, false, true, nil
+		}
+	}
+	return hostSystem.Name, false, false, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileHostMaintenance(t *testing.T) {
+	t.Run("when the check is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		vms := &VMService{}
+		vms.reconcileHostMaintenance(context.Background(), vmCtx)
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(BeFalse())
+
+		// An existing condition is marked true, so the node is untainted.
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.HostAvailableCondition, infrav1.InMaintenanceModeReason, "", "")
+		vms.reconcileHostMaintenance(context.Background(), vmCtx)
+		g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(BeTrue())
+	})
+
+	t.Run("when the host enters and exits maintenance mode", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			host, err := vm.HostSystem(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			simHost := simulator.Map.Get(host.Reference()).(*simulator.HostSystem)
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.HostMaintenanceCheckInterval = time.Minute
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+			vms := &VMService{}
+			vms.reconcileHostMaintenance(ctx, vmCtx)
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(BeFalse())
+
+			// A queued task enters the host into maintenance mode.
+			task := simulator.CreateTask(simHost, "enterMaintenanceMode", func(*simulator.Task) (types.AnyType, types.BaseMethodFault) {
+				return nil, nil
+			})
+			simHost.RecentTask = []types.ManagedObjectReference{task.Self}
+			vms.reconcileHostMaintenance(ctx, vmCtx)
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(Equal(infrav1.EnteringMaintenanceModeReason))
+
+			simHost.RecentTask = nil
+			simHost.Runtime.InMaintenanceMode = true
+			vms.reconcileHostMaintenance(ctx, vmCtx)
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(Equal(infrav1.InMaintenanceModeReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(ContainSubstring(simHost.Name))
+
+			simHost.Runtime.InMaintenanceMode = false
+			vms.reconcileHostMaintenance(ctx, vmCtx)
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.HostAvailableCondition)).To(BeTrue())
+			return nil
+		})
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+var defaultLookupAddr = net.DefaultResolver.LookupAddr
+
+// lookupAddr performs the reverse DNS lookup of an IP address. It is a variable
+// so tests can replace the resolver.
+var lookupAddr = defaultLookupAddr
+
+// guestHostname returns the hostname of the guest, which is the name of the VSphereVM
+// unless the hostname is derived from the IP address of the VM.
+func guestHostname(ctx context.Context, virtualMachineCtx *virtualMachineContext) (string, error) {
+	vsphereVM := virtualMachineCtx.VSphereVM
+	spec := vsphereVM.Spec.Hostname
+	if spec == nil {
+		return vsphereVM.Name, nil
+	}
+
+	ip := hostnameIP(virtualMachineCtx)
+	if ip == nil {
+		return "", errors.Errorf("no static or allocated IPv4 address to derive the hostname of VSphereVM %s from", vsphereVM.Name)
+	}
+
+	var hostname string
+	switch spec.Source {
+	case infrav1.HostnameSourceReverseDNS:
+		names, err := lookupAddr(ctx, ip.String())
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to look up the hostname of VSphereVM %s by IP address %s", vsphereVM.Name, ip)
+		}
+		if len(names) == 0 {
+			return "", errors.Errorf("no hostname found for IP address %s of VSphereVM %s", ip, vsphereVM.Name)
+		}
+		hostname = strings.TrimSuffix(names[0], ".")
+	default:
+		var err error
+		if hostname, err = renderHostname(spec.Template, vsphereVM.Name, ip); err != nil {
+			return "", errors.Wrapf(err, "unable to render the hostname of VSphereVM %s", vsphereVM.Name)
+		}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return "", errors.Errorf("hostname %q of VSphereVM %s is invalid: %s", hostname, vsphereVM.Name, strings.Join(errs, ", "))
+	}
+	return hostname, nil
+}
+
+// hostnameIP returns the first IPv4 address of the network devices of the VM, either
+// static or allocated from an IP pool, or nil if there is none.
+func hostnameIP(virtualMachineCtx *virtualMachineContext) net.IP {
+	for i, device := range virtualMachineCtx.VSphereVM.Spec.Network.Devices {
+		addrs := device.IPAddrs
+		if virtualMachineCtx.State != nil && len(virtualMachineCtx.State.Network) > i {
+			if state, ok := virtualMachineCtx.IPAMState[virtualMachineCtx.State.Network[i].MACAddr]; ok {
+				addrs = append(append([]string{}, addrs...), state.IPAddrs...)
+			}
+		}
+		for _, addr := range addrs {
+			if ip, _, err := net.ParseCIDR(addr); err == nil && ip.To4() != nil {
+				return ip.To4()
+			}
+		}
+	}
+	return nil
+}
+
+// renderHostname renders the hostname template with the name of the VSphereVM and the
+// given IPv4 address.
+func renderHostname(text, name string, ip net.IP) (string, error) {
+	tpl, err := template.New("hostname").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	octets := make([]string, 0, net.IPv4len)
+	for _, octet := range ip.To4() {
+		octets = append(octets, strconv.Itoa(int(octet)))
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, struct {
+		Name   string
+		IP     string
+		Octets []string
+	}{
+		Name:   name,
+		IP:     ip.String(),
+		Octets: octets,
+	}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
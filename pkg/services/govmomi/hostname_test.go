@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_guestHostname(t *testing.T) {
+	lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		switch addr {
+		case "10.0.1.23":
+			return []string{"node-1-23.example.com."}, nil
+		case "10.0.1.24":
+			return nil, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	t.Cleanup(func() { lookupAddr = defaultLookupAddr })
+
+	newVMContext := func(hostname *infrav1.HostnameSpec, ipAddrs ...string) *virtualMachineContext {
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.State = &infrav1.VirtualMachine{}
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					Hostname: hostname,
+					Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{
+						{IPAddrs: ipAddrs},
+					}},
+				},
+			},
+		}
+		return vmCtx
+	}
+	template := func(text string) *infrav1.HostnameSpec {
+		return &infrav1.HostnameSpec{Source: infrav1.HostnameSourceTemplate, Template: text}
+	}
+	reverseDNS := &infrav1.HostnameSpec{Source: infrav1.HostnameSourceReverseDNS}
+
+	tests := []struct {
+		name         string
+		vmCtx        *virtualMachineContext
+		wantHostname string
+		wantErr      bool
+	}{
+		{
+			name:         "defaults to the name of the VSphereVM",
+			vmCtx:        newVMContext(nil, "10.0.1.23/24"),
+			wantHostname: "worker-abcde",
+		},
+		{
+			name:         "renders the template with the octets of the IP address",
+			vmCtx:        newVMContext(template("node-{{ index .Octets 2 }}-{{ index .Octets 3 }}.example.com"), "fd00::1/64", "10.0.1.23/24"),
+			wantHostname: "node-1-23.example.com",
+		},
+		{
+			name:         "renders the template with the name and the IP address",
+			vmCtx:        newVMContext(template("{{ .Name }}.{{ .IP }}.example.com"), "10.0.1.23/24"),
+			wantHostname: "worker-abcde.10.0.1.23.example.com",
+		},
+		{
+			name:    "fails if the rendered hostname is invalid",
+			vmCtx:   newVMContext(template("Node_{{ .IP }}"), "10.0.1.23/24"),
+			wantErr: true,
+		},
+		{
+			name:    "fails if the template references unknown fields",
+			vmCtx:   newVMContext(template("{{ .Zone }}"), "10.0.1.23/24"),
+			wantErr: true,
+		},
+		{
+			name:    "fails without an IPv4 address",
+			vmCtx:   newVMContext(template("node-{{ .IP }}"), "fd00::1/64"),
+			wantErr: true,
+		},
+		{
+			name:         "looks up the hostname by reverse DNS",
+			vmCtx:        newVMContext(reverseDNS, "10.0.1.23/24"),
+			wantHostname: "node-1-23.example.com",
+		},
+		{
+			name:    "fails if reverse DNS returns no names",
+			vmCtx:   newVMContext(reverseDNS, "10.0.1.24/24"),
+			wantErr: true,
+		},
+		{
+			name:    "fails if reverse DNS fails",
+			vmCtx:   newVMContext(reverseDNS, "10.0.1.25/24"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			hostname, err := guestHostname(context.Background(), tt.vmCtx)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(hostname).To(Equal(tt.wantHostname))
+		})
+	}
+
+	t.Run("uses the IP address allocated from an IP pool", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := newVMContext(template("node-{{ index .Octets 3 }}"))
+		vmCtx.State.Network = []infrav1.NetworkStatus{{MACAddr: "00:50:56:00:00:01"}}
+		vmCtx.IPAMState = map[string]infrav1.NetworkDeviceSpec{
+			"00:50:56:00:00:01": {IPAddrs: []string{"10.0.1.42/24"}},
+		}
+
+		hostname, err := guestHostname(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(hostname).To(Equal("node-42"))
+	})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+)
+
+// reconcileHostPinning migrates the VM back to the host of the spec by vMotion if it was
+// moved to another host, e.g. manually or when the host entered maintenance mode. The VM
+// is left on its current host while the host of the spec is unavailable.
+func (vms *VMService) reconcileHostPinning(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	hostName := virtualMachineCtx.VSphereVM.Spec.Host
+	if hostName == "" {
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.HostPinnedCondition)
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.ResourcePool == nil {
+		return false, errors.Errorf("unable to get resource pool of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	pool := object.NewResourcePool(virtualMachineCtx.Session.Client.Client, *virtualMachine.ResourcePool)
+	host, err := vcenter.PinnedHost(ctx, &virtualMachineCtx.VMContext, pool)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.HostPinnedCondition, infrav1.HostPinningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		if errors.Is(err, vcenter.ErrHostsUnavailable) {
+			log.Info("Leaving VM on its current host as the host of the spec is unavailable", "host", hostName, "reason", err.Error())
+			return true, nil
+		}
+		return false, err
+	}
+	if virtualMachine.Runtime.Host != nil && *virtualMachine.Runtime.Host == *host {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.HostPinnedCondition)
+		return true, nil
+	}
+
+	log.Info("Migrating VM back to the host of the spec", "host", hostName)
+	task, err := virtualMachineCtx.Obj.Relocate(ctx, types.VirtualMachineRelocateSpec{Host: host}, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.HostPinnedCondition, infrav1.HostPinningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to migrate VM %s to host %s", virtualMachineCtx.VSphereVM.Name, hostName)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.HostPinnedCondition, infrav1.MigratingToHostReason, clusterv1.ConditionSeverityInfo,
+		"migrating VM to host %s", hostName)
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be migrated")
+	return false, nil
+}
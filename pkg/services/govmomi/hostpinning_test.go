@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileHostPinning(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	vms := &VMService{}
+
+	// run runs f against the simulator with a VM of the cluster DC0_C0, passing the name
+	// of its host and of another host of the cluster.
+	run := func(f func(ctx context.Context, currentHost, otherHost string)) {
+		g.Expect(simulator.VPX().Run(func(ctx context.Context, c *vim25.Client) error {
+			finder := find.NewFinder(c)
+			dc, err := finder.DefaultDatacenter(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			finder.SetDatacenter(dc)
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}, Finder: finder}
+			vmCtx.Obj, err = finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var vm mo.VirtualMachine
+			g.Expect(vmCtx.Obj.Properties(ctx, vmCtx.Obj.Reference(), []string{"runtime.host"}, &vm)).To(Succeed())
+			var host mo.HostSystem
+			g.Expect(vmCtx.Obj.Properties(ctx, *vm.Runtime.Host, []string{"name"}, &host)).To(Succeed())
+			otherHost := "DC0_C0_H0"
+			if host.Name == otherHost {
+				otherHost = "DC0_C0_H1"
+			}
+			f(ctx, host.Name, otherHost)
+			return nil
+		})).To(Succeed())
+	}
+
+	t.Run("when the spec has no host", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		ok, err := vms.reconcileHostPinning(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(BeFalse())
+	})
+
+	t.Run("when the VM runs on the host of the spec", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		run(func(ctx context.Context, currentHost, _ string) {
+			vmCtx.VSphereVM.Spec.Host = currentHost
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileHostPinning(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		})
+	})
+
+	t.Run("when the VM was moved to another host", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		run(func(ctx context.Context, _, otherHost string) {
+			vmCtx.VSphereVM.Spec.Host = otherHost
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileHostPinning(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(Equal(infrav1.MigratingToHostReason))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+		})
+	})
+
+	t.Run("when the host of the spec is in maintenance mode", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		run(func(ctx context.Context, _, otherHost string) {
+			host, err := vmCtx.Session.Finder.HostSystem(ctx, otherHost)
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := host.EnterMaintenanceMode(ctx, 0, false, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.VSphereVM.Spec.Host = otherHost
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileHostPinning(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(Equal(infrav1.HostPinningFailedReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(ContainSubstring("maintenance mode"))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		})
+	})
+
+	t.Run("when the host of the spec is not in the cluster of the VM", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		run(func(ctx context.Context, _, _ string) {
+			vmCtx.VSphereVM.Spec.Host = "DC0_H0"
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileHostPinning(ctx, vmCtx)
+			g.Expect(err).To(MatchError(ContainSubstring("is not a host of the compute resource")))
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.HostPinnedCondition)).To(Equal(infrav1.HostPinningFailedReason))
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		})
+	})
+}
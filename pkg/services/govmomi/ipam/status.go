@@ -102,6 +102,10 @@ func BuildState(ctx context.Context, vmCtx capvcontext.VMContext, networkStatus
 		}
 	}
 
+	if err := validateDefaultGateway(vmCtx.VSphereVM, ipamDeviceConfigs, state); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		var msgs []string
 		for _, err := range errs {
@@ -113,6 +117,42 @@ func BuildState(ctx context.Context, vmCtx capvcontext.VMContext, networkStatus
 	return state, nil
 }
 
+// validateDefaultGateway checks that the VM has a default gateway if its network
+// is configured from IPAM. If no device has DHCP, a gateway or routes, the
+// IPAddresses of the primary device, or of the first device with IPAddresses,
+// must provide the gateway, otherwise the node is unreachable from outside of
+// its subnets.
+func validateDefaultGateway(vsphereVM *infrav1.VSphereVM, ipamDeviceConfigs []ipamDeviceConfig, state map[string]infrav1.NetworkDeviceSpec) error {
+	var gatewayDevice *ipamDeviceConfig
+	for i := range ipamDeviceConfigs {
+		device := vsphereVM.Spec.Network.Devices[ipamDeviceConfigs[i].DeviceIndex]
+		if device.DHCP4 || device.DHCP6 || device.Gateway4 != "" || device.Gateway6 != "" || len(device.Routes) > 0 {
+			return nil
+		}
+
+		deviceState, ok := state[ipamDeviceConfigs[i].MACAddress]
+		if !ok {
+			continue
+		}
+		if deviceState.Gateway4 != "" || deviceState.Gateway6 != "" {
+			return nil
+		}
+		if gatewayDevice == nil || device.Primary {
+			gatewayDevice = &ipamDeviceConfigs[i]
+		}
+	}
+
+	if gatewayDevice == nil || len(gatewayDevice.IPAMAddresses) == 0 {
+		return nil
+	}
+	ipamAddress := gatewayDevice.IPAMAddresses[0]
+	return fmt.Errorf("IPAddress %s/%s has no gateway, but device (index %d) requires one as no device is configured with DHCP, a gateway or routes",
+		ipamAddress.Namespace,
+		ipamAddress.Name,
+		gatewayDevice.DeviceIndex,
+	)
+}
+
 // buildIPAMDeviceConfigs checks that all the IPAddressClaims have been satisfied.
 // If each IPAddressClaim has an associated IPAddress, a slice of ipamDeviceConfig
 // is returned, one for each device with addressesFromPools.
@@ -863,6 +863,45 @@ func Test_BuildState(t *testing.T) {
 				gomega.ContainSubstring("IPAddress my-namespace/vsphereVM1-1-0 has invalid gateway: \"12.12.12.12.12\"")))
 		})
 
+		t.Run("when no IPAddress provides a gateway", func(_ *testing.T) {
+			beforeWithClaimsAndAddressCreated()
+
+			address1.Spec.Gateway = ""
+			address2.Spec.Gateway = ""
+			address3.Spec.Gateway = ""
+			g.Expect(vmCtx.Client.Update(ctx, address1)).NotTo(gomega.HaveOccurred())
+			g.Expect(vmCtx.Client.Update(ctx, address2)).NotTo(gomega.HaveOccurred())
+			g.Expect(vmCtx.Client.Update(ctx, address3)).NotTo(gomega.HaveOccurred())
+
+			_, err := BuildState(ctx, vmCtx, networkStatus)
+			g.Expect(err).To(gomega.MatchError("IPAddress my-namespace/vsphereVM1-0-0 has no gateway, but device (index 0) requires one as no device is configured with DHCP, a gateway or routes"))
+
+			// The primary device requires the gateway.
+			vmCtx.VSphereVM.Spec.Network.Devices[1].Primary = true
+			_, err = BuildState(ctx, vmCtx, networkStatus)
+			g.Expect(err).To(gomega.MatchError("IPAddress my-namespace/vsphereVM1-1-0 has no gateway, but device (index 1) requires one as no device is configured with DHCP, a gateway or routes"))
+
+			// A device with DHCP provides the default gateway.
+			vmCtx.VSphereVM.Spec.Network.Devices[1].DHCP4 = true
+			_, err = BuildState(ctx, vmCtx, networkStatus)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+
+		t.Run("when the IPAddresses of one device provide a gateway", func(_ *testing.T) {
+			beforeWithClaimsAndAddressCreated()
+
+			address1.Spec.Gateway = ""
+			address2.Spec.Gateway = ""
+			g.Expect(vmCtx.Client.Update(ctx, address1)).NotTo(gomega.HaveOccurred())
+			g.Expect(vmCtx.Client.Update(ctx, address2)).NotTo(gomega.HaveOccurred())
+
+			state, err := BuildState(ctx, vmCtx, networkStatus)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(state[devMAC0].Gateway4).To(gomega.BeEmpty())
+			g.Expect(state[devMAC1].Gateway4).To(gomega.Equal("11.0.0.1"))
+			g.Expect(state[devMAC1].IPAddrs).To(gomega.Equal([]string{"11.0.1.50/24"}))
+		})
+
 		t.Run("when there are duplicate IPAddresses", func(_ *testing.T) {
 			beforeWithClaimsAndAddressCreated()
 
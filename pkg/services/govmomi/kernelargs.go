@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// kernelArgsGrubDropInPath is the path of the drop-in of the GRUB defaults of Debian based
+// guests which appends the kernel arguments to the kernel command line.
+const kernelArgsGrubDropInPath = "/etc/default/grub.d/99-capv-kernel-args.cfg"
+
+// minIgnitionKernelArgsVersion is the first version of the Ignition specification which
+// supports the kernelArguments section.
+var minIgnitionKernelArgsVersion = utilversion.MajorMinor(3, 3)
+
+// addKernelArgs adds the kernel arguments to the boot configuration of Linux guests. The
+// bootstrap data is left unchanged if no kernel arguments are set or the guest is Windows.
+func addKernelArgs(data []byte, format bootstrapv1.Format, os infrav1.OS, kernelArgs []string) ([]byte, error) {
+	if len(kernelArgs) == 0 || os == infrav1.Windows || len(data) == 0 {
+		return data, nil
+	}
+
+	switch format {
+	case bootstrapv1.CloudConfig:
+		return addCloudConfigKernelArgs(data, kernelArgs)
+	case bootstrapv1.Ignition:
+		return addIgnitionKernelArgs(data, kernelArgs)
+	default:
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// addCloudConfigKernelArgs adds the kernel arguments to the GRUB configuration, by grubby
+// on RHEL based guests and by a drop-in of the GRUB defaults on Debian based guests. The
+// guest is rebooted once cloud-init finished, so the node runs with the kernel arguments,
+// unless the bootstrap data already defines the power state. The command is appended and
+// placed by orderInjectedCommands.
+func addCloudConfigKernelArgs(data []byte, kernelArgs []string) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	args := strings.Join(kernelArgs, " ")
+	writeFiles, _ := config["write_files"].([]interface{})
+	config["write_files"] = append(writeFiles, map[string]interface{}{
+		"path":        kernelArgsGrubDropInPath,
+		"permissions": "0644",
+		"content":     fmt.Sprintf("GRUB_CMDLINE_LINUX=\"$GRUB_CMDLINE_LINUX %s\"\n", args),
+	})
+
+	runcmd, _ := config["runcmd"].([]interface{})
+	config["runcmd"] = append(runcmd,
+		fmt.Sprintf("if command -v grubby >/dev/null 2>&1; then grubby --update-kernel=ALL --args=%q; else update-grub; fi", args))
+
+	if _, ok := config["power_state"]; !ok {
+		config["power_state"] = map[string]interface{}{
+			"mode":    "reboot",
+			"message": "Rebooting to apply the kernel arguments",
+		}
+	}
+
+	return marshalCloudConfig(header, config)
+}
+
+// addIgnitionKernelArgs adds the kernel arguments to the kernelArguments section of the
+// Ignition config, which reboots the guest to apply them on first boot.
+func addIgnitionKernelArgs(data []byte, kernelArgs []string) ([]byte, error) {
+	config, err := unmarshalIgnitionConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ignition, _ := config["ignition"].(map[string]interface{})
+	version, _ := ignition["version"].(string)
+	if v, err := utilversion.ParseGeneric(version); err != nil || !v.AtLeast(minIgnitionKernelArgsVersion) {
+		return nil, errors.Errorf("kernel arguments require an Ignition config of version %s or later, got %q", minIgnitionKernelArgsVersion, version)
+	}
+
+	kernelArguments, _ := config["kernelArguments"].(map[string]interface{})
+	if kernelArguments == nil {
+		kernelArguments = map[string]interface{}{}
+		config["kernelArguments"] = kernelArguments
+	}
+	shouldExist, _ := kernelArguments["shouldExist"].([]interface{})
+	for _, arg := range kernelArgs {
+		shouldExist = append(shouldExist, arg)
+	}
+	kernelArguments["shouldExist"] = shouldExist
+
+	return marshalIgnitionConfig(config)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_addKernelArgs(t *testing.T) {
+	kernelArgs := []string{"isolcpus=2-7", "hugepages=1024"}
+
+	tests := []struct {
+		name       string
+		data       string
+		format     bootstrapv1.Format
+		os         infrav1.OS
+		kernelArgs []string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:     "without kernel arguments",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:       "cloud-config",
+			data:       "#cloud-config\nruncmd:\n- echo\n",
+			format:     bootstrapv1.CloudConfig,
+			kernelArgs: kernelArgs,
+			expected: "#cloud-config\npower_state:\n  message: Rebooting to apply the kernel arguments\n  mode: reboot\n" +
+				"runcmd:\n- echo\n" +
+				"- if command -v grubby >/dev/null 2>&1; then grubby --update-kernel=ALL --args=\"isolcpus=2-7\n  hugepages=1024\"; else update-grub; fi\n" +
+				"write_files:\n- content: |\n    GRUB_CMDLINE_LINUX=\"$GRUB_CMDLINE_LINUX isolcpus=2-7 hugepages=1024\"\n" +
+				"  path: /etc/default/grub.d/99-capv-kernel-args.cfg\n  permissions: \"0644\"\n",
+		},
+		{
+			name:       "cloud-config with power state",
+			data:       "#cloud-config\npower_state:\n  mode: poweroff\n",
+			format:     bootstrapv1.CloudConfig,
+			kernelArgs: []string{"nosmt"},
+			expected: "#cloud-config\npower_state:\n  mode: poweroff\n" +
+				"runcmd:\n- if command -v grubby >/dev/null 2>&1; then grubby --update-kernel=ALL --args=\"nosmt\";\n  else update-grub; fi\n" +
+				"write_files:\n- content: |\n    GRUB_CMDLINE_LINUX=\"$GRUB_CMDLINE_LINUX nosmt\"\n" +
+				"  path: /etc/default/grub.d/99-capv-kernel-args.cfg\n  permissions: \"0644\"\n",
+		},
+		{
+			name:       "cloud-config of Windows",
+			data:       "#cloud-config\nruncmd:\n- echo\n",
+			format:     bootstrapv1.CloudConfig,
+			os:         infrav1.Windows,
+			kernelArgs: kernelArgs,
+			expected:   "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:       "ignition v3.3",
+			data:       `{"ignition":{"version":"3.3.0"},"kernelArguments":{"shouldExist":["quiet"]}}`,
+			format:     bootstrapv1.Ignition,
+			kernelArgs: kernelArgs,
+			expected:   `{"ignition":{"version":"3.3.0"},"kernelArguments":{"shouldExist":["quiet","isolcpus=2-7","hugepages=1024"]}}`,
+		},
+		{
+			name:       "ignition v3.1",
+			data:       `{"ignition":{"version":"3.1.0"}}`,
+			format:     bootstrapv1.Ignition,
+			kernelArgs: kernelArgs,
+			wantErr:    true,
+		},
+		{
+			name:       "ignition v2",
+			data:       `{"ignition":{"version":"2.3.0"}}`,
+			format:     bootstrapv1.Ignition,
+			kernelArgs: kernelArgs,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addKernelArgs([]byte(tt.data), tt.format, tt.os, tt.kernelArgs)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// maxMigrationHistory is the number of the most recent migrations of a VM kept in its status.
+const maxMigrationHistory = 10
+
+// migrationEventTypeIDs are the types of the events vCenter posts when a VM was migrated to
+// another host. DrsVmMigratedEvent is a subtype of VmMigratedEvent, but the filter of vCenter
+// only matches the exact types of events.
+var migrationEventTypeIDs = []string{"VmMigratedEvent", "DrsVmMigratedEvent"}
+
+// reconcileMigrationHistory reports the migrations of the VM between ESXi hosts, as observed in
+// the events of vCenter, in the status of the VSphereVM. The events are only read if the
+// migration history refresh interval is not zero and passed since the last refresh, and only
+// the events since the last refresh are read. A failed refresh does not block the reconcile,
+// as the migrations are only reported.
+func (vms *VMService) reconcileMigrationHistory(ctx context.Context, virtualMachineCtx *virtualMachineContext) {
+	log := ctrl.LoggerFrom(ctx)
+
+	interval := virtualMachineCtx.MigrationHistoryRefreshInterval
+	if interval == 0 {
+		virtualMachineCtx.VSphereVM.Status.MigrationHistory = nil
+		return
+	}
+	history := virtualMachineCtx.VSphereVM.Status.MigrationHistory
+	if history != nil && time.Since(history.LastRefreshTime.Time) < interval {
+		return
+	}
+
+	filter := types.EventFilterSpec{
+		Entity: &types.EventFilterSpecByEntity{
+			Entity:    virtualMachineCtx.Obj.Reference(),
+			Recursion: types.EventFilterSpecRecursionOptionSelf,
+		},
+		EventTypeId: migrationEventTypeIDs,
+	}
+	var migrations []infrav1.VirtualMachineMigration
+	if history != nil {
+		filter.Time = &types.EventFilterSpecByTime{BeginTime: &history.LastRefreshTime.Time}
+		migrations = history.Migrations
+	}
+	// The refresh time is taken before reading the events, so events posted while reading
+	// them are read by the next refresh.
+	refreshTime := metav1.Now()
+	events, err := event.NewManager(virtualMachineCtx.Session.Client.Client).QueryEvents(ctx, filter)
+	if err != nil {
+		log.Error(err, "Failed to get migration events")
+		return
+	}
+
+	for _, migration := range newMigrations(migrations, events) {
+		log.Info("Observed migration of VM", "sourceHost", migration.SourceHost, "targetHost", migration.TargetHost,
+			"initiator", migration.Initiator, "time", migration.Time)
+		migrations = append(migrations, migration)
+	}
+	if len(migrations) > maxMigrationHistory {
+		migrations = migrations[len(migrations)-maxMigrationHistory:]
+	}
+	virtualMachineCtx.VSphereVM.Status.MigrationHistory = &infrav1.MigrationHistory{
+		Migrations:      migrations,
+		LastRefreshTime: refreshTime,
+	}
+}
+
+// newMigrations returns the migrations of the given migration events which are more recent
+// than the last of the given migrations, oldest first. The events since the last refresh are
+// read again if the last refresh time was truncated to seconds by the API server, so
+// migrations of the same second as the last one are skipped.
+func newMigrations(migrations []infrav1.VirtualMachineMigration, events []types.BaseEvent) []infrav1.VirtualMachineMigration {
+	var result []infrav1.VirtualMachineMigration
+	for _, e := range events {
+		var migration infrav1.VirtualMachineMigration
+		switch e := e.(type) {
+		case *types.DrsVmMigratedEvent:
+			migration = vmMigration(&e.VmMigratedEvent, infrav1.MigrationInitiatorDRS)
+		case *types.VmMigratedEvent:
+			migration = vmMigration(e, infrav1.MigrationInitiatorManual)
+		default:
+			continue
+		}
+		if len(migrations) > 0 && !migration.Time.After(migrations[len(migrations)-1].Time.Time) {
+			continue
+		}
+		result = append(result, migration)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(&result[j].Time)
+	})
+	return result
+}
+
+// vmMigration returns the migration of the given migration event.
+func vmMigration(e *types.VmMigratedEvent, initiator infrav1.MigrationInitiator) infrav1.VirtualMachineMigration {
+	migration := infrav1.VirtualMachineMigration{
+		// The time is truncated to seconds as by the API server, so it compares equal after
+		// the status was written.
+		Time:       metav1.NewTime(e.CreatedTime).Rfc3339Copy(),
+		SourceHost: e.SourceHost.Name,
+		Initiator:  initiator,
+		User:       e.UserName,
+	}
+	if e.Host != nil {
+		migration.TargetHost = e.Host.Name
+	}
+	return migration
+}
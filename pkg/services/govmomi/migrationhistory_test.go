@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileMigrationHistory(t *testing.T) {
+	// migratedEvent returns a migration event of the VM from the source to the target host.
+	migratedEvent := func(vm types.ManagedObjectReference, source, target string) types.VmMigratedEvent {
+		return types.VmMigratedEvent{
+			VmEvent: types.VmEvent{Event: types.Event{
+				Vm:   &types.VmEventArgument{Vm: vm},
+				Host: &types.HostEventArgument{EntityEventArgument: types.EntityEventArgument{Name: target}},
+			}},
+			SourceHost: types.HostEventArgument{EntityEventArgument: types.EntityEventArgument{Name: source}},
+		}
+	}
+
+	// run runs f against the simulator with the context of a VM whose migration history is
+	// refreshed every minute.
+	run := func(g *WithT, f func(ctx context.Context, vmCtx *virtualMachineContext, events *event.Manager)) {
+		g.Expect(simulator.VPX().Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.MigrationHistoryRefreshInterval = time.Minute
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{}
+			f(ctx, vmCtx, event.NewManager(c))
+			return nil
+		})).To(Succeed())
+	}
+
+	t.Run("when the reporting is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{
+			MigrationHistory: &infrav1.MigrationHistory{Migrations: []infrav1.VirtualMachineMigration{{SourceHost: "esx-1"}}},
+		}}
+
+		(&VMService{}).reconcileMigrationHistory(context.Background(), vmCtx)
+		g.Expect(vmCtx.VSphereVM.Status.MigrationHistory).To(BeNil())
+	})
+
+	t.Run("when the VM was migrated", func(t *testing.T) {
+		g := NewWithT(t)
+		run(g, func(ctx context.Context, vmCtx *virtualMachineContext, events *event.Manager) {
+			ref := vmCtx.Obj.Reference()
+			manual := migratedEvent(ref, "DC0_C0_H0", "DC0_C0_H1")
+			g.Expect(events.PostEvent(ctx, &manual)).To(Succeed())
+
+			vms := &VMService{}
+			vms.reconcileMigrationHistory(ctx, vmCtx)
+			history := vmCtx.VSphereVM.Status.MigrationHistory
+			g.Expect(history).ToNot(BeNil())
+			g.Expect(history.LastRefreshTime.IsZero()).To(BeFalse())
+			g.Expect(history.Migrations).To(HaveLen(1))
+			g.Expect(history.Migrations[0].SourceHost).To(Equal("DC0_C0_H0"))
+			g.Expect(history.Migrations[0].TargetHost).To(Equal("DC0_C0_H1"))
+			g.Expect(history.Migrations[0].Initiator).To(Equal(infrav1.MigrationInitiatorManual))
+
+			// The events are not read before the interval passed.
+			drs := &types.DrsVmMigratedEvent{VmMigratedEvent: migratedEvent(ref, "DC0_C0_H1", "DC0_C0_H2")}
+			g.Expect(events.PostEvent(ctx, drs)).To(Succeed())
+			vms.reconcileMigrationHistory(ctx, vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.MigrationHistory.Migrations).To(HaveLen(1))
+
+			// Migrations are not recorded again when the events since the last refresh are read,
+			// e.g. in the same second as the last migration.
+			vmCtx.VSphereVM.Status.MigrationHistory.LastRefreshTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+			vms.reconcileMigrationHistory(ctx, vmCtx)
+			g.Expect(vmCtx.VSphereVM.Status.MigrationHistory.Migrations).To(HaveLen(1))
+		})
+	})
+
+	t.Run("when the history is full", func(t *testing.T) {
+		g := NewWithT(t)
+		run(g, func(ctx context.Context, vmCtx *virtualMachineContext, events *event.Manager) {
+			migrations := make([]infrav1.VirtualMachineMigration, maxMigrationHistory)
+			for i := range migrations {
+				migrations[i] = infrav1.VirtualMachineMigration{
+					Time:      metav1.NewTime(time.Now().Add(time.Duration(i-maxMigrationHistory) * time.Hour)),
+					Initiator: infrav1.MigrationInitiatorManual,
+				}
+			}
+			vmCtx.VSphereVM.Status.MigrationHistory = &infrav1.MigrationHistory{
+				Migrations:      migrations,
+				LastRefreshTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+			}
+			drs := &types.DrsVmMigratedEvent{VmMigratedEvent: migratedEvent(vmCtx.Obj.Reference(), "DC0_C0_H0", "DC0_C0_H1")}
+			g.Expect(events.PostEvent(ctx, drs)).To(Succeed())
+
+			(&VMService{}).reconcileMigrationHistory(ctx, vmCtx)
+			history := vmCtx.VSphereVM.Status.MigrationHistory
+			g.Expect(history.Migrations).To(HaveLen(maxMigrationHistory))
+			g.Expect(history.Migrations[0].Time).To(Equal(migrations[1].Time))
+			g.Expect(history.Migrations[maxMigrationHistory-1].Initiator).To(Equal(infrav1.MigrationInitiatorDRS))
+		})
+	})
+}
+
+func Test_newMigrations(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now().Truncate(time.Second)
+	migratedEvent := func(created time.Time, target string) types.VmMigratedEvent {
+		return types.VmMigratedEvent{
+			VmEvent: types.VmEvent{Event: types.Event{
+				CreatedTime: created,
+				UserName:    "administrator@vsphere.local",
+				Host:        &types.HostEventArgument{EntityEventArgument: types.EntityEventArgument{Name: target}},
+			}},
+			SourceHost: types.HostEventArgument{EntityEventArgument: types.EntityEventArgument{Name: "esx-0"}},
+		}
+	}
+	manual := migratedEvent(now.Add(2*time.Second), "esx-2")
+	drs := &types.DrsVmMigratedEvent{VmMigratedEvent: migratedEvent(now.Add(time.Second), "esx-1")}
+	recorded := migratedEvent(now.Add(500*time.Millisecond), "esx-3")
+
+	// The events are ordered by time and migrations recorded in the same second as the last
+	// migration are skipped.
+	migrations := newMigrations(
+		[]infrav1.VirtualMachineMigration{{Time: metav1.NewTime(now), Initiator: infrav1.MigrationInitiatorManual}},
+		[]types.BaseEvent{&manual, drs, &recorded, &types.VmPoweredOnEvent{}},
+	)
+	g.Expect(migrations).To(Equal([]infrav1.VirtualMachineMigration{
+		{
+			Time:       metav1.NewTime(now.Add(time.Second)).Rfc3339Copy(),
+			SourceHost: "esx-0",
+			TargetHost: "esx-1",
+			Initiator:  infrav1.MigrationInitiatorDRS,
+			User:       "administrator@vsphere.local",
+		},
+		{
+			Time:       metav1.NewTime(now.Add(2 * time.Second)).Rfc3339Copy(),
+			SourceHost: "esx-0",
+			TargetHost: "esx-2",
+			Initiator:  infrav1.MigrationInitiatorManual,
+			User:       "administrator@vsphere.local",
+		},
+	}))
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+const (
+	// ipxeGuestInfoPrefix is the prefix of the VMware GuestInfo keys iPXE reads its
+	// settings from.
+	ipxeGuestInfoPrefix = "guestinfo.ipxe."
+
+	// ipxeScriptletSetting is the iPXE setting whose script is run instead of booting
+	// from the boot file of the DHCP server.
+	ipxeScriptletSetting = "scriptlet"
+)
+
+// reconcileNetworkBoot ensures the network device booting the VM from the network is
+// connected and attached to the provisioning network, places it first in the boot order
+// of a powered off VM and delivers the iPXE script and settings in the VMware GuestInfo.
+// A VM whose network device can not boot from the network is not powered on.
+func (vms *VMService) reconcileNetworkBoot(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	bootOptions := virtualMachineCtx.VSphereVM.Spec.BootOptions
+	if bootOptions == nil || bootOptions.NetworkBoot == nil {
+		log.V(5).Info("Network boot not defined. skipping reconcile network boot")
+		return true, nil
+	}
+	networkBoot := bootOptions.NetworkBoot
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get network boot configuration of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	poweredOff := virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff
+
+	devices := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device)
+	nic, err := networkBootDevice(ctx, virtualMachineCtx, devices, poweredOff)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.NetworkBootFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	// The boot order can only be changed while the VM is powered off.
+	if poweredOff {
+		var current []types.BaseVirtualMachineBootOptionsBootableDevice
+		if virtualMachine.Config.BootOptions != nil {
+			current = virtualMachine.Config.BootOptions.BootOrder
+		}
+		if bootOrder, changed := networkBootOrder(current, nic.Key, devices); changed {
+			desired.BootOptions = &types.VirtualMachineBootOptions{BootOrder: bootOrder}
+			changes = append(changes, fmt.Sprintf("bootOrder network device %d first", networkBoot.DeviceIndex))
+		}
+	}
+
+	current := map[string]string{}
+	for _, ec := range virtualMachine.Config.ExtraConfig {
+		if optionValue := ec.GetOptionValue(); optionValue != nil {
+			current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+		}
+	}
+	extraConfig := ipxeExtraConfig(networkBoot)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; current[k] != v {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM network boot configuration", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// networkBootDevice returns the network device of the VM which boots from the network.
+// It returns an error if the device is missing, not connected or not attached to the
+// provisioning network.
+func networkBootDevice(ctx context.Context, virtualMachineCtx *virtualMachineContext, devices object.VirtualDeviceList, poweredOff bool) (*types.VirtualEthernetCard, error) {
+	networkBoot := virtualMachineCtx.VSphereVM.Spec.BootOptions.NetworkBoot
+
+	nics := devices.SelectByType((*types.VirtualEthernetCard)(nil))
+	if int(networkBoot.DeviceIndex) >= len(nics) {
+		return nil, errors.Errorf("VM %s has no network device %d to boot from the network", virtualMachineCtx.VSphereVM.Name, networkBoot.DeviceIndex)
+	}
+	nic := nics[networkBoot.DeviceIndex].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+
+	// A powered off VM connects the device when it is powered on.
+	connected := nic.Connectable != nil && (nic.Connectable.Connected || poweredOff && nic.Connectable.StartConnected)
+	if !connected {
+		return nil, errors.Errorf("network device %d of VM %s booting from the network is not connected", networkBoot.DeviceIndex, virtualMachineCtx.VSphereVM.Name)
+	}
+
+	if networkBoot.ProvisioningNetworkName != "" {
+		network, err := virtualMachineCtx.Session.Finder.Network(ctx, networkBoot.ProvisioningNetworkName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find provisioning network %q", networkBoot.ProvisioningNetworkName)
+		}
+		backing, err := network.EthernetCardBackingInfo(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get backing of provisioning network %q", networkBoot.ProvisioningNetworkName)
+		}
+		if !sameNetworkBacking(backing, nic.Backing) {
+			return nil, errors.Errorf("network device %d of VM %s booting from the network is not attached to provisioning network %q",
+				networkBoot.DeviceIndex, virtualMachineCtx.VSphereVM.Name, networkBoot.ProvisioningNetworkName)
+		}
+	}
+	return nic, nil
+}
+
+// sameNetworkBacking returns true if the backing of a network device attaches it to the
+// network of the desired backing.
+func sameNetworkBacking(desired types.BaseVirtualDeviceBackingInfo, actual types.BaseVirtualDeviceBackingInfo) bool {
+	switch desired := desired.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		actual, ok := actual.(*types.VirtualEthernetCardNetworkBackingInfo)
+		return ok && actual.DeviceName == desired.DeviceName
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		actual, ok := actual.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+		return ok && actual.Port.PortgroupKey == desired.Port.PortgroupKey
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		actual, ok := actual.(*types.VirtualEthernetCardOpaqueNetworkBackingInfo)
+		return ok && actual.OpaqueNetworkId == desired.OpaqueNetworkId
+	}
+	return false
+}
+
+// networkBootOrder returns the boot order which places the network device with the given
+// key first, and whether it differs from the current boot order. The other bootable
+// devices keep their order, and the first disk follows the network device if the current
+// boot order is empty, so the VM still boots from its disk if the network boot fails.
+func networkBootOrder(current []types.BaseVirtualMachineBootOptionsBootableDevice, nicKey int32, devices object.VirtualDeviceList) ([]types.BaseVirtualMachineBootOptionsBootableDevice, bool) {
+	if len(current) > 0 {
+		if ethernet, ok := current[0].(*types.VirtualMachineBootOptionsBootableEthernetDevice); ok && ethernet.DeviceKey == nicKey {
+			return current, false
+		}
+	}
+
+	bootOrder := []types.BaseVirtualMachineBootOptionsBootableDevice{
+		&types.VirtualMachineBootOptionsBootableEthernetDevice{DeviceKey: nicKey},
+	}
+	for _, device := range current {
+		if ethernet, ok := device.(*types.VirtualMachineBootOptionsBootableEthernetDevice); ok && ethernet.DeviceKey == nicKey {
+			continue
+		}
+		bootOrder = append(bootOrder, device)
+	}
+	if len(current) == 0 {
+		if disks := devices.SelectByType((*types.VirtualDisk)(nil)); len(disks) > 0 {
+			bootOrder = append(bootOrder, &types.VirtualMachineBootOptionsBootableDiskDevice{DeviceKey: disks[0].GetVirtualDevice().Key})
+		}
+	}
+	return bootOrder, true
+}
+
+// ipxeExtraConfig returns the VMware GuestInfo extra config delivering the iPXE script and
+// settings of the network boot.
+func ipxeExtraConfig(networkBoot *infrav1.VirtualMachineNetworkBoot) map[string]string {
+	extraConfig := map[string]string{}
+	for name, value := range networkBoot.IPXESettings {
+		extraConfig[ipxeGuestInfoPrefix+name] = value
+	}
+	if networkBoot.IPXEScript != "" {
+		extraConfig[ipxeGuestInfoPrefix+ipxeScriptletSetting] = networkBoot.IPXEScript
+	}
+	return extraConfig
+}
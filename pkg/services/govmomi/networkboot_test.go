@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileNetworkBoot(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func(ctx context.Context, c *vim25.Client) {
+		vmCtx = emptyVirtualMachineContext()
+		finder := find.NewFinder(c)
+		dc, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(dc)
+		vmCtx.Session = &session.Session{Finder: finder}
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(networkBoot *infrav1.VirtualMachineNetworkBoot) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					BootOptions: &infrav1.VirtualMachineBootOptions{NetworkBoot: networkBoot},
+				},
+			},
+		}
+	}
+
+	t.Run("when network boot is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		ok, err := (&VMService{}).reconcileNetworkBoot(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+	})
+
+	t.Run("when the powered off VM boots from the network", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			before(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineNetworkBoot{
+				ProvisioningNetworkName: "DC0_DVPG0",
+				IPXEScript:              "chain http://boot.example.com/${net0/mac}.ipxe",
+				IPXESettings:            map[string]string{"next-server": "10.0.0.1"},
+			})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNetworkBoot(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.bootOptions", "config.extraConfig"}, &virtualMachine)).To(Succeed())
+			g.Expect(virtualMachine.Config.BootOptions.BootOrder).ToNot(BeEmpty())
+			g.Expect(virtualMachine.Config.BootOptions.BootOrder[0]).To(BeAssignableToTypeOf(&types.VirtualMachineBootOptionsBootableEthernetDevice{}))
+			extraConfig := map[string]interface{}{}
+			for _, ec := range virtualMachine.Config.ExtraConfig {
+				extraConfig[ec.GetOptionValue().Key] = ec.GetOptionValue().Value
+			}
+			g.Expect(extraConfig).To(HaveKeyWithValue("guestinfo.ipxe.scriptlet", "chain http://boot.example.com/${net0/mac}.ipxe"))
+			g.Expect(extraConfig).To(HaveKeyWithValue("guestinfo.ipxe.next-server", "10.0.0.1"))
+
+			// A second reconcile is a no-op once the network boot is configured.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNetworkBoot(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the network device is not attached to the provisioning network", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			before(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineNetworkBoot{ProvisioningNetworkName: "VM Network"})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNetworkBoot(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.NetworkBootFailedReason))
+			return nil
+		})
+	})
+
+	t.Run("when the network device does not exist", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			before(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineNetworkBoot{DeviceIndex: 1})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNetworkBoot(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.NetworkBootFailedReason))
+			return nil
+		})
+	})
+}
+
+func Test_networkBootOrder(t *testing.T) {
+	g := NewWithT(t)
+	disk := &types.VirtualMachineBootOptionsBootableDiskDevice{DeviceKey: 2000}
+	cdrom := &types.VirtualMachineBootOptionsBootableCdromDevice{}
+	nic := &types.VirtualMachineBootOptionsBootableEthernetDevice{DeviceKey: 4000}
+
+	bootOrder, changed := networkBootOrder([]types.BaseVirtualMachineBootOptionsBootableDevice{nic, disk}, 4000, nil)
+	g.Expect(changed).To(BeFalse())
+	g.Expect(bootOrder).To(Equal([]types.BaseVirtualMachineBootOptionsBootableDevice{nic, disk}))
+
+	bootOrder, changed = networkBootOrder([]types.BaseVirtualMachineBootOptionsBootableDevice{cdrom, nic, disk}, 4000, nil)
+	g.Expect(changed).To(BeTrue())
+	g.Expect(bootOrder).To(Equal([]types.BaseVirtualMachineBootOptionsBootableDevice{nic, cdrom, disk}))
+
+	bootOrder, changed = networkBootOrder(nil, 4000, []types.BaseVirtualDevice{&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2000}}})
+	g.Expect(changed).To(BeTrue())
+	g.Expect(bootOrder).To(Equal([]types.BaseVirtualMachineBootOptionsBootableDevice{nic, disk}))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+)
+
+// reconcileNetworkDeviceSettings ensures the network adapters of the VM have the Wake-on-LAN
+// and guest control settings of the network devices of the spec. The network adapters match
+// the network devices of the spec by their position, as they are added to the VM in order
+// when it is cloned.
+func (vms *VMService) reconcileNetworkDeviceSettings(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	netDevices := virtualMachineCtx.VSphereVM.Spec.Network.Devices
+	configured := false
+	for i := range netDevices {
+		if netDevices[i].WakeOnLanEnabled != nil || netDevices[i].AllowGuestControl != nil {
+			configured = true
+			break
+		}
+	}
+	if !configured {
+		return true, nil
+	}
+
+	devices, err := virtualMachineCtx.Obj.Device(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get devices of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	nics := devices.SelectByType((*types.VirtualEthernetCard)(nil))
+
+	var (
+		deviceChange []types.BaseVirtualDeviceConfigSpec
+		changes      []string
+	)
+	for i := range netDevices {
+		if i >= len(nics) {
+			break
+		}
+		nic := nics[i].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		nicChanges := vcenter.NetworkDeviceSettings(nic, &netDevices[i])
+		if len(nicChanges) == 0 {
+			continue
+		}
+		deviceChange = append(deviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    nics[i],
+		})
+		for _, change := range nicChanges {
+			changes = append(changes, fmt.Sprintf("%s %s", change, object.VirtualDeviceList(nics).Name(nics[i])))
+		}
+	}
+	if len(deviceChange) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM network adapter settings", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		DeviceChange: deviceChange,
+	}, changes...)
+	return true, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileNetworkDeviceSettings(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	vms := &VMService{}
+
+	newVSphereVM := func(devices ...infrav1.NetworkDeviceSpec) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{Spec: infrav1.VSphereVMSpec{VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+			Network: infrav1.NetworkSpec{Devices: devices},
+		}}}
+	}
+
+	t.Run("when the network devices have no settings", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM(infrav1.NetworkDeviceSpec{NetworkName: "VM Network"})
+
+		ok, err := vms.reconcileNetworkDeviceSettings(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+	})
+
+	t.Run("when the settings of a network adapter differ", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.NetworkDeviceSpec{
+				NetworkName:       "VM Network",
+				WakeOnLanEnabled:  ptr.To(false),
+				AllowGuestControl: ptr.To(false),
+			})
+
+			ok, err := vms.reconcileNetworkDeviceSettings(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("wakeOnLanEnabled ethernet-0", "allowGuestControl ethernet-0"))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			devices, err := vm.Device(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			nic := devices.SelectByType((*types.VirtualEthernetCard)(nil))[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			g.Expect(nic.WakeOnLanEnabled).To(Equal(ptr.To(false)))
+			g.Expect(nic.Connectable.AllowGuestControl).To(BeFalse())
+			g.Expect(nic.Connectable.StartConnected).To(BeTrue())
+
+			// A second reconcile is a no-op once the settings match.
+			ok, err = vms.reconcileNetworkDeviceSettings(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+}
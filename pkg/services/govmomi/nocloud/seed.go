@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nocloud contains tools to create the seed of the cloud-init NoCloud datasource.
+package nocloud
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// VolumeLabel is the label of the seed ISO image by which cloud-init
+	// finds the NoCloud datasource.
+	VolumeLabel = "cidata"
+
+	sectorSize = 2048
+
+	// The system area takes the first 16 sectors, followed by the primary volume
+	// descriptor, the volume descriptor set terminator, the path tables in little
+	// and big endian, and the root directory.
+	primaryVolumeDescriptorSector = 16
+	terminatorSector              = 17
+	lPathTableSector              = 18
+	mPathTableSector              = 19
+	rootDirectorySector           = 20
+	firstFileSector               = 21
+
+	// paddingSectors are appended to the image like mkisofs does, so readers
+	// reading ahead of the last file do not fail on such a small image.
+	paddingSectors = 150
+
+	directoryFlag = 2
+)
+
+// Metadata returns the metadata of the NoCloud datasource with the given instance ID and hostname.
+func Metadata(instanceID, hostname string) []byte {
+	return []byte(fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", instanceID, hostname))
+}
+
+// SeedISO returns an ISO 9660 image with the label cidata which contains the user data and
+// metadata as the files user-data and meta-data, i.e. the seed of the NoCloud datasource.
+// The file names are recorded as USER-DATA.;1 and META-DATA.;1, which Linux presents in
+// lower case without the version suffix.
+func SeedISO(userData, metadata []byte) []byte {
+	return writeISO(VolumeLabel, []isoFile{
+		{name: "META-DATA.;1", data: metadata},
+		{name: "USER-DATA.;1", data: userData},
+	}, time.Now().UTC())
+}
+
+type isoFile struct {
+	name string
+	data []byte
+}
+
+// writeISO returns an ISO 9660 image with the given files, which must be sorted by name,
+// in its root directory.
+func writeISO(label string, files []isoFile, recorded time.Time) []byte {
+	sectors := uint32(firstFileSector)
+	extents := make([]uint32, len(files))
+	for i, file := range files {
+		extents[i] = sectors
+		sectors += sectorCount(len(file.data))
+	}
+	sectors += paddingSectors
+
+	image := make([]byte, int(sectors)*sectorSize)
+
+	root := directoryRecord([]byte{0}, rootDirectorySector, sectorSize, directoryFlag, recorded)
+	writePrimaryVolumeDescriptor(image[primaryVolumeDescriptorSector*sectorSize:], label, sectors, root, recorded)
+
+	terminator := image[terminatorSector*sectorSize:]
+	terminator[0] = 255
+	copy(terminator[1:6], "CD001")
+	terminator[6] = 1
+
+	// The path tables only contain the root directory, whose parent is itself.
+	lPathTable := image[lPathTableSector*sectorSize:]
+	lPathTable[0] = 1
+	binary.LittleEndian.PutUint32(lPathTable[2:], rootDirectorySector)
+	binary.LittleEndian.PutUint16(lPathTable[6:], 1)
+	mPathTable := image[mPathTableSector*sectorSize:]
+	mPathTable[0] = 1
+	binary.BigEndian.PutUint32(mPathTable[2:], rootDirectorySector)
+	binary.BigEndian.PutUint16(mPathTable[6:], 1)
+
+	directory := image[rootDirectorySector*sectorSize:]
+	offset := copy(directory, root)
+	offset += copy(directory[offset:], directoryRecord([]byte{1}, rootDirectorySector, sectorSize, directoryFlag, recorded))
+	for i, file := range files {
+		offset += copy(directory[offset:], directoryRecord([]byte(file.name), extents[i], uint32(len(file.data)), 0, recorded))
+		copy(image[int(extents[i])*sectorSize:], file.data)
+	}
+
+	return image
+}
+
+func writePrimaryVolumeDescriptor(descriptor []byte, label string, sectors uint32, root []byte, recorded time.Time) {
+	descriptor[0] = 1
+	copy(descriptor[1:6], "CD001")
+	descriptor[6] = 1
+	copy(descriptor[8:40], padRight("", 32))
+	copy(descriptor[40:72], padRight(label, 32))
+	putBothEndianUint32(descriptor[80:], sectors)
+	putBothEndianUint16(descriptor[120:], 1)
+	putBothEndianUint16(descriptor[124:], 1)
+	putBothEndianUint16(descriptor[128:], sectorSize)
+	putBothEndianUint32(descriptor[132:], 10)
+	binary.LittleEndian.PutUint32(descriptor[140:], lPathTableSector)
+	binary.BigEndian.PutUint32(descriptor[148:], mPathTableSector)
+	copy(descriptor[156:190], root)
+	// Volume set, publisher, data preparer and application identifiers.
+	copy(descriptor[190:702], padRight("", 512))
+	// Copyright, abstract and bibliographic file identifiers.
+	copy(descriptor[702:813], padRight("", 111))
+	copy(descriptor[813:830], volumeDate(recorded))
+	copy(descriptor[830:847], volumeDate(recorded))
+	copy(descriptor[847:864], volumeDate(time.Time{}))
+	copy(descriptor[864:881], volumeDate(time.Time{}))
+	descriptor[881] = 1
+}
+
+// directoryRecord returns the directory record of a file or directory.
+func directoryRecord(identifier []byte, extent, size uint32, flags byte, recorded time.Time) []byte {
+	length := 33 + len(identifier)
+	if length%2 != 0 {
+		length++
+	}
+	record := make([]byte, length)
+	record[0] = byte(length)
+	putBothEndianUint32(record[2:], extent)
+	putBothEndianUint32(record[10:], size)
+	record[18] = byte(recorded.Year() - 1900)
+	record[19] = byte(recorded.Month())
+	record[20] = byte(recorded.Day())
+	record[21] = byte(recorded.Hour())
+	record[22] = byte(recorded.Minute())
+	record[23] = byte(recorded.Second())
+	record[25] = flags
+	putBothEndianUint16(record[28:], 1)
+	record[32] = byte(len(identifier))
+	copy(record[33:], identifier)
+	return record
+}
+
+// volumeDate returns the date of a volume descriptor, the zero time is recorded as not specified.
+func volumeDate(t time.Time) []byte {
+	date := []byte("0000000000000000\x00")
+	if !t.IsZero() {
+		copy(date, t.Format("20060102150405")+"00")
+	}
+	return date
+}
+
+func sectorCount(size int) uint32 {
+	return uint32((size + sectorSize - 1) / sectorSize)
+}
+
+func padRight(s string, length int) string {
+	return s + strings.Repeat(" ", length-len(s))
+}
+
+func putBothEndianUint32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b, v)
+	binary.BigEndian.PutUint32(b[4:], v)
+}
+
+func putBothEndianUint16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+	binary.BigEndian.PutUint16(b[2:], v)
+}
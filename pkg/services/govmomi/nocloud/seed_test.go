@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nocloud
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSeedISO(t *testing.T) {
+	g := NewWithT(t)
+
+	userData := []byte("#cloud-config\nhostname: vm-1\n")
+	metadata := Metadata("0a1b2c", "vm-1")
+	image := SeedISO(userData, metadata)
+
+	g.Expect(len(image) % sectorSize).To(BeZero())
+
+	descriptor := image[primaryVolumeDescriptorSector*sectorSize:]
+	g.Expect(descriptor[0]).To(Equal(byte(1)))
+	g.Expect(string(descriptor[1:6])).To(Equal("CD001"))
+	g.Expect(strings.TrimRight(string(descriptor[40:72]), " ")).To(Equal(VolumeLabel))
+	g.Expect(int(binary.LittleEndian.Uint32(descriptor[80:])) * sectorSize).To(Equal(len(image)))
+	g.Expect(binary.BigEndian.Uint32(descriptor[84:])).To(Equal(binary.LittleEndian.Uint32(descriptor[80:])))
+
+	terminator := image[terminatorSector*sectorSize:]
+	g.Expect(terminator[0]).To(Equal(byte(255)))
+	g.Expect(string(terminator[1:6])).To(Equal("CD001"))
+
+	// Walk the root directory referenced by the primary volume descriptor.
+	root := descriptor[156:190]
+	g.Expect(root[0]).To(Equal(byte(34)))
+	g.Expect(root[25]).To(Equal(byte(directoryFlag)))
+	directory := image[int(binary.LittleEndian.Uint32(root[2:]))*sectorSize:][:binary.LittleEndian.Uint32(root[10:])]
+
+	files := map[string]string{}
+	for offset := 0; offset < len(directory) && directory[offset] != 0; offset += int(directory[offset]) {
+		record := directory[offset:]
+		identifier := string(record[33 : 33+int(record[32])])
+		if record[25]&directoryFlag != 0 {
+			continue
+		}
+		extent := int(binary.LittleEndian.Uint32(record[2:]))
+		size := int(binary.LittleEndian.Uint32(record[10:]))
+		files[identifier] = string(image[extent*sectorSize:][:size])
+	}
+	g.Expect(files).To(Equal(map[string]string{
+		"META-DATA.;1": "instance-id: 0a1b2c\nlocal-hostname: vm-1\n",
+		"USER-DATA.;1": string(userData),
+	}))
+}
@@ -25,9 +25,11 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
 func (vms *VMService) getPowerState(ctx context.Context, virtualMachineCtx *virtualMachineContext) (infrav1.VirtualMachinePowerState, error) {
@@ -70,6 +72,40 @@ func (vms *VMService) isSoftPowerOffTimeoutExceeded(vm *infrav1.VSphereVM) bool
 	return timeout.Seconds() > 0 && diff.Seconds() >= timeout.Seconds()
 }
 
+// reconcileDesiredPowerOff powers off the VM of a VSphereVM whose desired power state is
+// poweredOff. The guest is shut down first according to the power off mode.
+func (vms *VMService) reconcileDesiredPowerOff(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if util.IsControlPlaneMachine(virtualMachineCtx.VSphereVM) && !conditions.Has(virtualMachineCtx.VSphereVM, infrav1.GuestSoftPowerOffSucceededCondition) {
+		log.Info("WARNING: Powering off the VM of a control plane machine reduces the availability of the control plane")
+	}
+
+	softPowerOffPending, err := vms.triggerSoftPowerOff(ctx, virtualMachineCtx)
+	if err != nil {
+		return false, err
+	}
+	if softPowerOffPending {
+		log.Info("Wait for guest of VM to shut down")
+		return false, nil
+	}
+
+	log.Info("Powering off VM")
+	task, err := virtualMachineCtx.Obj.PowerOff(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to trigger power off op for vm %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	// Update the VSphereVM.Status.TaskRef to track the power-off task.
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	if err = virtualMachineCtx.Patch(ctx); err != nil {
+		return false, errors.Wrapf(err, "failed to patch VSphereVM")
+	}
+
+	log.Info("Wait for VM to be powered off")
+	return false, nil
+}
+
 // triggerSoftPowerOff tries to trigger a soft power off for a VM to shut down the guest.
 // It returns true if the soft power off operation is pending.
 func (vms *VMService) triggerSoftPowerOff(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
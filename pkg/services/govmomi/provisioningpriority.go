@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// waitForProvisioningPriority returns whether the VSphereVM defers the clone of its VM, as a
+// VSphereVM of the same vSphere server with a higher provisioning priority waits to be cloned.
+// The workqueue of the VSphereVM controller processes the VSphereVMs in FIFO order, so deferring
+// the clone frees the worker for the VSphereVMs of higher priority.
+func waitForProvisioningPriority(ctx context.Context, vmCtx *capvcontext.VMContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	vsphereVMs := &infrav1.VSphereVMList{}
+	if err := vmCtx.Client.List(ctx, vsphereVMs); err != nil {
+		return false, errors.Wrap(err, "failed to list VSphereVMs")
+	}
+	for i := range vsphereVMs.Items {
+		other := &vsphereVMs.Items[i]
+		if other.UID == vmCtx.VSphereVM.UID || other.Spec.Server != vmCtx.VSphereVM.Spec.Server {
+			continue
+		}
+		if other.Spec.ProvisioningPriority > vmCtx.VSphereVM.Spec.ProvisioningPriority && waitsForClone(other) {
+			log.Info("Deferring clone while a VSphereVM of higher provisioning priority waits to be cloned",
+				"VSphereVM", other.Namespace+"/"+other.Name, "priority", other.Spec.ProvisioningPriority)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitsForClone returns whether the VM of the VSphereVM waits to be cloned. VSphereVMs which
+// wait for something else, e.g. an IP address, or failed to be cloned do not defer the clones
+// of other VSphereVMs.
+func waitsForClone(vm *infrav1.VSphereVM) bool {
+	if !vm.DeletionTimestamp.IsZero() || vm.Status.TaskRef != "" || vm.Spec.BiosUUID != "" ||
+		vm.Status.FailureReason != nil || annotations.HasPaused(vm) {
+		return false
+	}
+	switch conditions.GetReason(vm, infrav1.VMProvisionedCondition) {
+	case "", infrav1.CloningReason, infrav1.WaitingForProvisioningPriorityReason:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+func Test_waitForProvisioningPriority(t *testing.T) {
+	vsphereVM := func(name string, priority int32, reason string) *infrav1.VSphereVM {
+		vm := &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "my-namespace",
+				UID:       apitypes.UID(name),
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					Server:               "vcenter.example.com",
+					ProvisioningPriority: priority,
+				},
+			},
+		}
+		if reason != "" {
+			conditions.MarkFalse(vm, infrav1.VMProvisionedCondition, reason, clusterv1.ConditionSeverityInfo, "")
+		}
+		return vm
+	}
+
+	tests := []struct {
+		name     string
+		vm       *infrav1.VSphereVM
+		others   []*infrav1.VSphereVM
+		expected bool
+	}{
+		{
+			name:   "default priority of all VSphereVMs",
+			vm:     vsphereVM("worker", 0, ""),
+			others: []*infrav1.VSphereVM{vsphereVM("other-worker", 0, "")},
+		},
+		{
+			name:     "VSphereVM of higher priority waits to be cloned",
+			vm:       vsphereVM("worker", 0, ""),
+			others:   []*infrav1.VSphereVM{vsphereVM("control-plane", 100, "")},
+			expected: true,
+		},
+		{
+			name:     "VSphereVM of higher priority defers its clone as well",
+			vm:       vsphereVM("worker", 0, ""),
+			others:   []*infrav1.VSphereVM{vsphereVM("critical", 10, infrav1.WaitingForProvisioningPriorityReason)},
+			expected: true,
+		},
+		{
+			name:   "VSphereVM of lower priority waits to be cloned",
+			vm:     vsphereVM("control-plane", 100, ""),
+			others: []*infrav1.VSphereVM{vsphereVM("worker", 0, "")},
+		},
+		{
+			name: "VSphereVM of higher priority is being cloned",
+			vm:   vsphereVM("worker", 0, ""),
+			others: func() []*infrav1.VSphereVM {
+				vm := vsphereVM("control-plane", 100, infrav1.CloningReason)
+				vm.Status.TaskRef = "task-1"
+				return []*infrav1.VSphereVM{vm}
+			}(),
+		},
+		{
+			name:   "VSphereVM of higher priority waits for its IP address",
+			vm:     vsphereVM("worker", 0, ""),
+			others: []*infrav1.VSphereVM{vsphereVM("control-plane", 100, infrav1.WaitingForStaticIPAllocationReason)},
+		},
+		{
+			name: "VSphereVM of higher priority on another vSphere server",
+			vm:   vsphereVM("worker", 0, ""),
+			others: func() []*infrav1.VSphereVM {
+				vm := vsphereVM("control-plane", 100, "")
+				vm.Spec.Server = "other-vcenter.example.com"
+				return []*infrav1.VSphereVM{vm}
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.vm)
+			for _, other := range tt.others {
+				builder = builder.WithObjects(other)
+			}
+			vmCtx := &capvcontext.VMContext{
+				ControllerManagerContext: &capvcontext.ControllerManagerContext{Client: builder.Build()},
+				VSphereVM:                tt.vm,
+			}
+
+			wait, err := waitForProvisioningPriority(context.Background(), vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(wait).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+const (
+	// proxyEnvironmentPath is the path of the file the proxy environment variables
+	// are appended to, which is read by login shells and PAM.
+	proxyEnvironmentPath = "/etc/environment"
+
+	// proxySystemdConfigPath is the path of the systemd manager configuration file
+	// which adds the proxy environment variables to all systemd services.
+	proxySystemdConfigPath = "/etc/systemd/system.conf.d/capv-proxy.conf"
+
+	// proxyContainerdConfigPath is the path of the drop-in of the containerd service
+	// which adds the proxy environment variables to containerd.
+	proxyContainerdConfigPath = "/etc/systemd/system/containerd.service.d/capv-proxy.conf"
+)
+
+// addProxySettings adds the proxy environment variables to /etc/environment, systemd
+// and containerd of Linux guests. The bootstrap data is left unchanged if no proxy is
+// set or the guest is Windows.
+func addProxySettings(data []byte, format bootstrapv1.Format, os infrav1.OS, proxy *infrav1.ProxyConfiguration) ([]byte, error) {
+	if proxy == nil || os == infrav1.Windows || len(data) == 0 {
+		return data, nil
+	}
+	env := proxyEnvironment(proxy)
+	if len(env) == 0 {
+		return data, nil
+	}
+
+	switch format {
+	case bootstrapv1.CloudConfig:
+		return addCloudConfigProxySettings(data, env)
+	case bootstrapv1.Ignition:
+		return addIgnitionProxySettings(data, env)
+	default:
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// addCloudConfigProxySettings writes the proxy configuration files and restarts
+// containerd, as it is already running when cloud-init writes the files. The
+// commands are appended and placed by orderInjectedCommands.
+func addCloudConfigProxySettings(data []byte, env []string) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	writeFiles, _ := config["write_files"].([]interface{})
+	config["write_files"] = append(writeFiles,
+		map[string]interface{}{
+			"path":        proxyEnvironmentPath,
+			"permissions": "0644",
+			"append":      true,
+			"content":     environmentFileContent(env),
+		},
+		map[string]interface{}{
+			"path":        proxySystemdConfigPath,
+			"permissions": "0644",
+			"content":     systemdConfigContent(env),
+		},
+		map[string]interface{}{
+			"path":        proxyContainerdConfigPath,
+			"permissions": "0644",
+			"content":     containerdDropInContent(env),
+		},
+	)
+
+	runcmd, _ := config["runcmd"].([]interface{})
+	config["runcmd"] = append(runcmd, "systemctl daemon-reload", "systemctl restart containerd")
+
+	return marshalCloudConfig(header, config)
+}
+
+// addIgnitionProxySettings writes the proxy configuration files, which are in place
+// before systemd starts any service.
+func addIgnitionProxySettings(data []byte, env []string) ([]byte, error) {
+	config, err := unmarshalIgnitionConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v2 := isIgnitionV2(config)
+	file := func(p, contents string, appendContents bool) map[string]interface{} {
+		return ignitionFile(v2, p, 0o644, "data:,"+url.PathEscape(contents), appendContents)
+	}
+
+	storage, _ := config["storage"].(map[string]interface{})
+	if storage == nil {
+		storage = map[string]interface{}{}
+		config["storage"] = storage
+	}
+	files, _ := storage["files"].([]interface{})
+	storage["files"] = append(files,
+		file(proxyEnvironmentPath, environmentFileContent(env), true),
+		file(proxySystemdConfigPath, systemdConfigContent(env), false),
+		file(proxyContainerdConfigPath, containerdDropInContent(env), false),
+	)
+
+	return marshalIgnitionConfig(config)
+}
+
+// proxyEnvironment returns the proxy environment variables in upper and lower case,
+// as tools disagree on which one to read.
+func proxyEnvironment(proxy *infrav1.ProxyConfiguration) []string {
+	var env []string
+	add := func(name, value string) {
+		if value != "" {
+			env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(name), value), fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	add("http_proxy", proxy.HTTPProxy)
+	add("https_proxy", proxy.HTTPSProxy)
+	add("no_proxy", strings.Join(proxy.NoProxy, ","))
+	return env
+}
+
+func environmentFileContent(env []string) string {
+	return strings.Join(env, "\n") + "\n"
+}
+
+func systemdConfigContent(env []string) string {
+	return fmt.Sprintf("[Manager]\nDefaultEnvironment=%s\n", quoteEnvironment(env))
+}
+
+func containerdDropInContent(env []string) string {
+	return fmt.Sprintf("[Service]\nEnvironment=%s\n", quoteEnvironment(env))
+}
+
+// quoteEnvironment returns the environment variables as space separated list of
+// quoted assignments, as expected by systemd.
+func quoteEnvironment(env []string) string {
+	quoted := make([]string, len(env))
+	for i, e := range env {
+		quoted[i] = fmt.Sprintf("%q", e)
+	}
+	return strings.Join(quoted, " ")
+}
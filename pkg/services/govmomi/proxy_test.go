@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_addProxySettings(t *testing.T) {
+	proxy := &infrav1.ProxyConfiguration{
+		HTTPProxy: "http://proxy:3128",
+		NoProxy:   []string{"10.0.0.0/8", ".local"},
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		format   bootstrapv1.Format
+		os       infrav1.OS
+		proxy    *infrav1.ProxyConfiguration
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "without proxy",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:     "cloud-config of Windows",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			os:       infrav1.Windows,
+			proxy:    proxy,
+			expected: "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:   "cloud-config",
+			data:   "#cloud-config\nruncmd:\n- kubeadm join\n",
+			format: bootstrapv1.CloudConfig,
+			proxy:  proxy,
+			expected: "#cloud-config\nruncmd:\n- kubeadm join\n- systemctl daemon-reload\n- systemctl restart containerd\nwrite_files:\n" +
+				"- append: true\n  content: |\n    HTTP_PROXY=http://proxy:3128\n    http_proxy=http://proxy:3128\n    NO_PROXY=10.0.0.0/8,.local\n    no_proxy=10.0.0.0/8,.local\n" +
+				"  path: /etc/environment\n  permissions: \"0644\"\n" +
+				"- content: |\n    [Manager]\n    DefaultEnvironment=\"HTTP_PROXY=http://proxy:3128\" \"http_proxy=http://proxy:3128\" \"NO_PROXY=10.0.0.0/8,.local\" \"no_proxy=10.0.0.0/8,.local\"\n" +
+				"  path: /etc/systemd/system.conf.d/capv-proxy.conf\n  permissions: \"0644\"\n" +
+				"- content: |\n    [Service]\n    Environment=\"HTTP_PROXY=http://proxy:3128\" \"http_proxy=http://proxy:3128\" \"NO_PROXY=10.0.0.0/8,.local\" \"no_proxy=10.0.0.0/8,.local\"\n" +
+				"  path: /etc/systemd/system/containerd.service.d/capv-proxy.conf\n  permissions: \"0644\"\n",
+		},
+		{
+			name:   "ignition v3",
+			data:   `{"ignition":{"version":"3.1.0"}}`,
+			format: bootstrapv1.Ignition,
+			proxy:  &infrav1.ProxyConfiguration{HTTPSProxy: "http://proxy:3128"},
+			expected: `{"ignition":{"version":"3.1.0"},"storage":{"files":[` +
+				`{"append":[{"source":"data:,HTTPS_PROXY=http:%2F%2Fproxy:3128%0Ahttps_proxy=http:%2F%2Fproxy:3128%0A"}],"mode":420,"path":"/etc/environment"},` +
+				`{"contents":{"source":"data:,%5BManager%5D%0ADefaultEnvironment=%22HTTPS_PROXY=http:%2F%2Fproxy:3128%22%20%22https_proxy=http:%2F%2Fproxy:3128%22%0A"},"mode":420,"overwrite":true,"path":"/etc/systemd/system.conf.d/capv-proxy.conf"},` +
+				`{"contents":{"source":"data:,%5BService%5D%0AEnvironment=%22HTTPS_PROXY=http:%2F%2Fproxy:3128%22%20%22https_proxy=http:%2F%2Fproxy:3128%22%0A"},"mode":420,"overwrite":true,"path":"/etc/systemd/system/containerd.service.d/capv-proxy.conf"}]}}`,
+		},
+		{
+			name:   "ignition v2",
+			data:   `{"ignition":{"version":"2.3.0"}}`,
+			format: bootstrapv1.Ignition,
+			proxy:  &infrav1.ProxyConfiguration{HTTPSProxy: "http://proxy:3128"},
+			expected: `{"ignition":{"version":"2.3.0"},"storage":{"files":[` +
+				`{"append":true,"contents":{"source":"data:,HTTPS_PROXY=http:%2F%2Fproxy:3128%0Ahttps_proxy=http:%2F%2Fproxy:3128%0A"},"filesystem":"root","mode":420,"path":"/etc/environment"},` +
+				`{"contents":{"source":"data:,%5BManager%5D%0ADefaultEnvironment=%22HTTPS_PROXY=http:%2F%2Fproxy:3128%22%20%22https_proxy=http:%2F%2Fproxy:3128%22%0A"},"filesystem":"root","mode":420,"path":"/etc/systemd/system.conf.d/capv-proxy.conf"},` +
+				`{"contents":{"source":"data:,%5BService%5D%0AEnvironment=%22HTTPS_PROXY=http:%2F%2Fproxy:3128%22%20%22https_proxy=http:%2F%2Fproxy:3128%22%0A"},"filesystem":"root","mode":420,"path":"/etc/systemd/system/containerd.service.d/capv-proxy.conf"}]}}`,
+		},
+		{
+			name:    "unsupported format",
+			data:    "data",
+			format:  "unknown",
+			proxy:   proxy,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addProxySettings([]byte(tt.data), tt.format, tt.os, tt.proxy)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
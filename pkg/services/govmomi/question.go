@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// powerOnTaskDescriptionID is the description ID of the task powering on a VM.
+const powerOnTaskDescriptionID = "VirtualMachine.powerOn"
+
+// knownQuestionAnswer is the safe answer of a known question of a VM.
+type knownQuestionAnswer struct {
+	// choice is the label of the answer choice.
+	choice string
+
+	// provisioningOnly is true if the answer is only safe while the VM is provisioned,
+	// i.e. before its BIOS UUID is recorded in the VSphereVM.
+	provisioningOnly bool
+}
+
+// knownQuestionAnswers are the answers of the questions which are answered automatically by
+// their message ID.
+var knownQuestionAnswers = map[string]knownQuestionAnswer{
+	// A VM cloned by CAPV is a copy and must not share the BIOS UUID of its source. Copying
+	// generates a new BIOS UUID, which is only safe until it is recorded in the VSphereVM.
+	"msg.uuid.altered": {choice: "button.uuid.copiedTheVM", provisioningOnly: true},
+}
+
+// reconcileInFlightQuestion answers the question of the VM blocking the in-flight power-on
+// task of the VSphereVM, if any.
+func (vms *VMService) reconcileInFlightQuestion(ctx context.Context, vmCtx *capvcontext.VMContext) error {
+	task := getTask(ctx, vmCtx)
+	if task == nil || task.Info.DescriptionId != powerOnTaskDescriptionID || task.Info.Entity == nil {
+		return nil
+	}
+
+	vmRef := *task.Info.Entity
+	virtualMachineCtx := &virtualMachineContext{
+		VMContext: *vmCtx,
+		Obj:       object.NewVirtualMachine(vmCtx.Session.Client.Client, vmRef),
+		Ref:       vmRef,
+	}
+	// Only the question of the VM is read while the power-on task is in flight.
+	if err := virtualMachineCtx.Obj.Properties(ctx, vmRef, []string{"runtime.question"}, &virtualMachineCtx.Properties); err != nil {
+		return errors.Wrapf(err, "error getting question of VM %s", vmCtx.VSphereVM.Name)
+	}
+	_, err := vms.reconcileQuestion(ctx, virtualMachineCtx)
+	return err
+}
+
+// reconcileQuestion answers the question which blocks the VM according to the question policy
+// of the VSphereVM. Questions which are not answered are surfaced in the VMQuestionAnswered
+// condition and block the reconciliation until a user answers them.
+func (vms *VMService) reconcileQuestion(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	virtualMachine := &virtualMachineCtx.Properties
+	question := virtualMachine.Runtime.Question
+	if question == nil {
+		if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition) {
+			conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)
+		}
+		return true, nil
+	}
+
+	log = log.WithValues("questionID", question.Id)
+	if virtualMachineCtx.VSphereVM.Spec.QuestionPolicy == infrav1.VirtualMachineQuestionPolicyManual {
+		log.V(4).Info("Question of VM is left to be answered by a user as questionPolicy is manual")
+	} else if answer, ok := questionAnswer(question, virtualMachineCtx.VSphereVM.Spec.BiosUUID == ""); ok {
+		log.Info("Answering question of VM", "answer", answer)
+		if err := virtualMachineCtx.Obj.Answer(ctx, question.Id, answer); err != nil {
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition, infrav1.VMQuestionPendingReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, errors.Wrapf(err, "unable to answer question %s of VM %s", question.Id, virtualMachineCtx.VSphereVM.Name)
+		}
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)
+		virtualMachineCtx.PropertiesOutdated = true
+		return true, nil
+	}
+
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition, infrav1.VMQuestionPendingReason, clusterv1.ConditionSeverityWarning,
+		fmt.Sprintf("VM is waiting for question %s to be answered in vCenter: %s", question.Id, question.Text))
+	return false, nil
+}
+
+// questionAnswer returns the key of the choice answering the given question, if it is a known
+// question which is safe to answer.
+func questionAnswer(question *types.VirtualMachineQuestionInfo, provisioning bool) (string, bool) {
+	for _, message := range question.Message {
+		known, ok := knownQuestionAnswers[message.Id]
+		if !ok || (known.provisioningOnly && !provisioning) {
+			continue
+		}
+		for _, choice := range question.Choice.ChoiceInfo {
+			if description := choice.GetElementDescription(); description.Label == known.choice {
+				return description.Key, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+// answeringVirtualMachine is a simulated VM which can be asked a question and answered,
+// which the simulator does not support.
+type answeringVirtualMachine struct {
+	mo.VirtualMachine
+	answer string
+}
+
+func (vm *answeringVirtualMachine) AnswerVM(_ *simulator.Context, req *types.AnswerVM) soap.HasFault {
+	body := &methods.AnswerVMBody{}
+	if vm.Runtime.Question == nil || vm.Runtime.Question.Id != req.QuestionId {
+		body.Fault_ = simulator.Fault("", &types.InvalidArgument{InvalidProperty: "questionId"})
+		return body
+	}
+	vm.answer = req.AnswerChoice
+	vm.Runtime.Question = nil
+	body.Res = &types.AnswerVMResponse{}
+	return body
+}
+
+func Test_reconcileQuestion(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(policy infrav1.VirtualMachineQuestionPolicy, biosUUID string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				BiosUUID: biosUUID,
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					QuestionPolicy: policy,
+				},
+			},
+		}
+	}
+
+	newQuestion := func(messageID string) *types.VirtualMachineQuestionInfo {
+		choice := func(key, label string) types.BaseElementDescription {
+			return &types.ElementDescription{Key: key, Description: types.Description{Label: label}}
+		}
+		return &types.VirtualMachineQuestionInfo{
+			Id:   "_vmx1",
+			Text: "This virtual machine might have been moved or copied.",
+			Choice: types.ChoiceOption{
+				ChoiceInfo: []types.BaseElementDescription{
+					choice("0", "button.uuid.cancel"),
+					choice("1", "button.uuid.movedTheVM"),
+					choice("2", "button.uuid.copiedTheVM"),
+				},
+			},
+			Message: []types.VirtualMachineMessage{{Id: messageID}},
+		}
+	}
+
+	// askQuestion lets the VM ask the given question, which is answered by the returned VM.
+	askQuestion := func(ref types.ManagedObjectReference, question *types.VirtualMachineQuestionInfo) *answeringVirtualMachine {
+		vm := &answeringVirtualMachine{VirtualMachine: simulator.Map.Get(ref).(*simulator.VirtualMachine).VirtualMachine}
+		vm.Runtime.Question = question
+		simulator.Map.Put(vm)
+		return vm
+	}
+
+	t.Run("when the VM has no question", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM("", "")
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileQuestion(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the VM being provisioned asks whether it was moved or copied", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM("", "")
+			answering := askQuestion(vm.Reference(), newQuestion("msg.uuid.altered"))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileQuestion(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(answering.answer).To(Equal("2"))
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(BeTrue())
+			return nil
+		})
+	})
+
+	for _, tt := range []struct {
+		name      string
+		policy    infrav1.VirtualMachineQuestionPolicy
+		biosUUID  string
+		messageID string
+	}{
+		{
+			name:      "when the question policy is manual",
+			policy:    infrav1.VirtualMachineQuestionPolicyManual,
+			messageID: "msg.uuid.altered",
+		},
+		{
+			name:      "when the provisioned VM asks whether it was moved or copied",
+			policy:    infrav1.VirtualMachineQuestionPolicyAutoAnswer,
+			biosUUID:  "42305f0b-dad7-1d3d-5727-0eafffffbbbf",
+			messageID: "msg.uuid.altered",
+		},
+		{
+			name:      "when the question is unknown",
+			messageID: "msg.hbacommon.outofspace",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g = NewWithT(t)
+			before()
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+				g.Expect(err).ToNot(HaveOccurred())
+				vmCtx.Obj = vm
+				vmCtx.VSphereVM = newVSphereVM(tt.policy, tt.biosUUID)
+				answering := askQuestion(vm.Reference(), newQuestion(tt.messageID))
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileQuestion(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeFalse())
+				g.Expect(answering.answer).To(BeEmpty())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(Equal(infrav1.VMQuestionPendingReason))
+				g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(ContainSubstring("_vmx1"))
+
+				// The condition is true once the question is answered by a user.
+				answering.Runtime.Question = nil
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err = vms.reconcileQuestion(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(BeTrue())
+				return nil
+			})
+		})
+	}
+
+	t.Run("when the question blocks the in-flight power-on task", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := vm.PowerOn(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+			vmCtx.VSphereVM = newVSphereVM("", "")
+			vmCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+			answering := askQuestion(vm.Reference(), newQuestion("msg.uuid.altered"))
+
+			g.Expect(vms.reconcileInFlightQuestion(ctx, &vmCtx.VMContext)).To(Succeed())
+			g.Expect(answering.answer).To(Equal("2"))
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMQuestionAnsweredCondition)).To(BeTrue())
+			return nil
+		})
+	})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/guest"
+	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+const (
+	// readinessProbeCommandTimeout is the time a readiness probe command is given to exit.
+	readinessProbeCommandTimeout = 10 * time.Second
+
+	// readinessProbeCommandInterval is the interval in which a readiness probe command is
+	// checked for having exited.
+	readinessProbeCommandInterval = time.Second
+)
+
+// reconcileReadinessProbe runs the readiness probe of the guest, if defined, using
+// VMware Tools guest operations. The VM is not ready until the probe succeeds.
+func (vms *VMService) reconcileReadinessProbe(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	probe := virtualMachineCtx.VSphereVM.Spec.ReadinessProbe
+	if probe == nil {
+		log.V(5).Info("Readiness probe not defined. skipping reconcile readiness probe")
+		return true, nil
+	}
+
+	auth, err := getGuestAuthentication(ctx, virtualMachineCtx, probe.CredentialsSecretName)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.ReadinessProbeFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if util.IsKeptPoweredOff(virtualMachineCtx.VSphereVM) && virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is kept powered off. skipping reconcile readiness probe")
+		return true, nil
+	}
+	if virtualMachine.Guest == nil || virtualMachine.Guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForReadinessProbeReason, clusterv1.ConditionSeverityInfo, "VMware Tools are not running")
+		return false, nil
+	}
+
+	operations := guest.NewOperationsManager(virtualMachineCtx.Session.Client.Client, virtualMachineCtx.Ref)
+	if probe.FilePath != "" {
+		err = probeGuestFile(ctx, operations, auth, probe.FilePath)
+	} else {
+		err = probeGuestCommand(ctx, operations, auth, probe.Command)
+	}
+	if err != nil {
+		log.V(4).Info("Readiness probe of guest did not succeed", "reason", err.Error())
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForReadinessProbeReason, clusterv1.ConditionSeverityInfo, err.Error())
+		return false, nil
+	}
+	return true, nil
+}
+
+// getGuestAuthentication returns the authentication of the guest user stored in the secret with
+// the given name in the namespace of the VSphereVM.
+func getGuestAuthentication(ctx context.Context, virtualMachineCtx *virtualMachineContext, secretName string) (*types.NamePasswordAuthentication, error) {
+	secret := &corev1.Secret{}
+	secretKey := apitypes.NamespacedName{
+		Namespace: virtualMachineCtx.VSphereVM.Namespace,
+		Name:      secretName,
+	}
+	if err := virtualMachineCtx.Client.Get(ctx, secretKey, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get guest credentials secret %s", secretName)
+	}
+	username, ok := secret.Data["username"]
+	if !ok {
+		return nil, errors.Errorf("guest credentials secret %s is missing the username key", secretName)
+	}
+	return &types.NamePasswordAuthentication{
+		Username: string(username),
+		Password: string(secret.Data["password"]),
+	}, nil
+}
+
+// probeGuestFile returns an error if the file at the given path does not exist in the guest.
+func probeGuestFile(ctx context.Context, operations *guest.OperationsManager, auth types.BaseGuestAuthentication, filePath string) error {
+	fileManager, err := operations.FileManager(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to get guest file manager")
+	}
+	if _, err := fileManager.ListFiles(ctx, auth, filePath, 0, 1, ""); err != nil {
+		return errors.Wrapf(err, "unable to find file %s in guest", filePath)
+	}
+	return nil
+}
+
+// probeGuestCommand returns an error if the given command does not exit with exit code zero
+// in the guest within the timeout.
+func probeGuestCommand(ctx context.Context, operations *guest.OperationsManager, auth types.BaseGuestAuthentication, command *infrav1.GuestProbeCommand) error {
+	processManager, err := operations.ProcessManager(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to get guest process manager")
+	}
+	pid, err := processManager.StartProgram(ctx, auth, &types.GuestProgramSpec{
+		ProgramPath: command.Path,
+		Arguments:   command.Arguments,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to start %s in guest", command.Path)
+	}
+
+	var exitCode int32
+	err = wait.PollUntilContextTimeout(ctx, readinessProbeCommandInterval, readinessProbeCommandTimeout, true, func(ctx context.Context) (bool, error) {
+		processes, err := processManager.ListProcesses(ctx, auth, []int64{pid})
+		if err != nil {
+			return false, err
+		}
+		if len(processes) == 0 || processes[0].EndTime == nil {
+			return false, nil
+		}
+		exitCode = processes[0].ExitCode
+		return true, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s did not exit in guest", command.Path)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("%s exited with exit code %d in guest", command.Path, exitCode)
+	}
+	return nil
+}
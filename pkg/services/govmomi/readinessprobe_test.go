@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileReadinessProbe(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "guest-credentials",
+			Namespace: "my-namespace",
+		},
+		Data: map[string][]byte{
+			"username": []byte("appliance"),
+			"password": []byte("secret"),
+		},
+	}
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().WithObjects(credentials).Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(probe *infrav1.GuestReadinessProbe) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ReadinessProbe: probe,
+				},
+			},
+		}
+	}
+
+	t.Run("when readiness probe is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(nil)
+		ok, err := vms.reconcileReadinessProbe(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+	})
+
+	t.Run("when the secret with the guest credentials does not exist", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(&infrav1.GuestReadinessProbe{
+			CredentialsSecretName: "missing",
+			FilePath:              "/var/run/appliance-ready",
+		})
+		ok, err := vms.reconcileReadinessProbe(context.Background(), vmCtx)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.ReadinessProbeFailedReason))
+	})
+
+	t.Run("when the file of the readiness probe does not exist in the guest", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.GuestReadinessProbe{
+				CredentialsSecretName: credentials.Name,
+				FilePath:              "/var/run/appliance-ready",
+			})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileReadinessProbe(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.WaitingForReadinessProbeReason))
+			return nil
+		})
+	})
+	t.Run("when the VM is left powered off after clone", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}}
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.GuestReadinessProbe{
+				CredentialsSecretName: credentials.Name,
+				FilePath:              "/var/run/appliance-ready",
+			})
+			vmCtx.VSphereVM.Spec.PowerOnAfterClone = ptr.To(false)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileReadinessProbe(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(BeFalse())
+			return nil
+		})
+	})
+}
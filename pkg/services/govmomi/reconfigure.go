@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+)
+
+// configChange is a config spec which batches the changes of the reconcile steps correcting
+// the drift of the VM, so the VM is stunned by a single reconfigure task.
+type configChange struct {
+	spec      types.VirtualMachineConfigSpec
+	changes   []string
+	onFailure []func(err error)
+
+	// changeVersion is the change version of the config of the VM observed before its drift
+	// was detected, so vCenter rejects the reconfigure if the VM was changed in the meantime.
+	changeVersion string
+}
+
+// add merges the given config spec into the batch. The changes describe the attributes it
+// changes. A config spec only sets the fields it changes, so the fields set by different
+// steps are merged, while the extra config and device changes are appended.
+func (c *configChange) add(spec types.VirtualMachineConfigSpec, changes ...string) {
+	mergeConfigSpec(reflect.ValueOf(&c.spec).Elem(), reflect.ValueOf(spec))
+	c.changes = append(c.changes, changes...)
+}
+
+// addFailureHandler registers a func which is called if reconfiguring the VM fails, so a
+// step can report the failure of its changes in its own condition.
+func (c *configChange) addFailureHandler(fn func(err error)) {
+	c.onFailure = append(c.onFailure, fn)
+}
+
+// mergeConfigSpec sets the non-zero fields of src in dst. Nested structs, e.g. the boot
+// options, are merged field by field and slices are appended.
+func mergeConfigSpec(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Field(i)
+		switch {
+		case field.IsZero():
+		case field.Kind() == reflect.Slice:
+			dst.Field(i).Set(reflect.AppendSlice(dst.Field(i), field))
+		case field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct && !dst.Field(i).IsNil():
+			mergeConfigSpec(dst.Field(i).Elem(), field.Elem())
+		default:
+			dst.Field(i).Set(field)
+		}
+	}
+}
+
+// sortedKeys returns the keys of the given extra config in order, so the changes of the
+// extra config are described in a stable order.
+func sortedKeys(extraConfig map[string]string) []string {
+	keys := make([]string, 0, len(extraConfig))
+	for k := range extraConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// observeChangeVersion records the change version of the config of the VM before the reconcile
+// steps detect its drift, so correcting the drift does not clobber concurrent changes of the
+// VM by other tools.
+func (vms *VMService) observeChangeVersion(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config != nil {
+		virtualMachineCtx.ConfigChange.changeVersion = virtualMachine.Config.ChangeVersion
+	}
+	return nil
+}
+
+// reconcileConfigChange reconfigures the VM with the changes batched by the previous reconcile
+// steps, if any. The changed attributes are reported by the VMReconfigured condition.
+func (vms *VMService) reconcileConfigChange(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	change := virtualMachineCtx.ConfigChange
+	if len(change.changes) == 0 {
+		if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition) {
+			conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition)
+		}
+		return true, nil
+	}
+
+	log.Info("Reconfiguring VM", "changes", change.changes)
+	change.spec.ChangeVersion = change.changeVersion
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, change.spec)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ReconfigureFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		for _, fn := range change.onFailure {
+			fn(err)
+		}
+		return false, errors.Wrapf(err, "unable to reconfigure VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ReconfiguringReason, clusterv1.ConditionSeverityInfo,
+		"changing %s", strings.Join(change.changes, ", "))
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	virtualMachineCtx.ConfigChange = configChange{}
+	log.Info("Wait for VM to be reconfigured")
+	return false, nil
+}
+
+// reconcileDeferredReconfigure applies changes of the CPUs and memory of the VSphereVM
+// spec to the VM, if the reconfigure policy is deferredUntilPowerOff or hotAdd. While the
+// VM is not powered off, the changes are recorded in the ReconfigurePending condition, except
+// for increases which are hot-added with the hotAdd policy. If the NodeCapacityRefresh
+// feature gate is enabled, the false NodeCapacityRefreshed condition has the node of the
+// VSphereVM cordoned before the changes are applied, until the kubelet restarted on the
+// reconfigured VM.
+func (vms *VMService) reconcileDeferredReconfigure(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	policy := virtualMachineCtx.VSphereVM.Spec.ReconfigurePolicy
+	if policy == "" {
+		log.V(5).Info("Reconfigure policy is not defined. skipping reconcile deferred reconfigure")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get hardware of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	spec, changes := hardwareChange(virtualMachineCtx.VSphereVM.Spec.VirtualMachineCloneSpec, virtualMachine.Config.Hardware)
+	if len(changes) == 0 {
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.ReconfigurePendingCondition)
+		return true, nil
+	}
+
+	reason, message := infrav1.ApplyingDeferredReconfigureReason, "applying %s"
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		var hotAddSpec types.VirtualMachineConfigSpec
+		var hotAddChanges []string
+		if policy == infrav1.ReconfigurePolicyHotAdd {
+			hotAddSpec, hotAddChanges = hotAddChange(virtualMachineCtx.VSphereVM.Spec.VirtualMachineCloneSpec, *virtualMachine.Config)
+		}
+		if len(hotAddChanges) == 0 {
+			log.V(4).Info("VM is not powered off. deferring reconfigure", "changes", changes)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.ReconfigurePendingCondition, infrav1.ReconfigureDeferredUntilPowerOffReason, clusterv1.ConditionSeverityInfo,
+				"%s will be applied when the VM is powered off", strings.Join(changes, ", "))
+			return true, nil
+		}
+		spec, changes = hotAddSpec, hotAddChanges
+		reason, message = infrav1.HotAddingReason, "hot-adding %s"
+	}
+
+	// The kubelet only reports the new capacity of the node once it restarted on the
+	// reconfigured VM, so the node is cordoned until then. It is cordoned before the changes
+	// are applied, so it records the boot ID and capacity of the node before the reconfigure.
+	if conditions.GetReason(virtualMachineCtx.VSphereVM, infrav1.ReconfigurePendingCondition) != reason &&
+		feature.Gates.Enabled(feature.NodeCapacityRefresh) {
+		log.Info("Cordoning node before reconfiguring VM", "changes", changes)
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.ReconfigurePendingCondition, reason, clusterv1.ConditionSeverityInfo, message, strings.Join(changes, ", "))
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition, infrav1.WaitingForNodeCapacityReason, clusterv1.ConditionSeverityInfo,
+			"waiting for the kubelet to restart on the reconfigured VM")
+		return false, nil
+	}
+
+	log.Info("Reconfiguring VM", "changes", changes)
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.ReconfigurePendingCondition, reason, clusterv1.ConditionSeverityInfo, message, strings.Join(changes, ", "))
+	virtualMachineCtx.ConfigChange.add(spec, changes...)
+	return true, nil
+}
+
+// hardwareChange returns the config spec which changes the CPUs and memory of the VM to
+// the ones defined in the clone spec, and a description of the changes. Fields which are
+// not set in the clone spec are left untouched.
+func hardwareChange(cloneSpec infrav1.VirtualMachineCloneSpec, hardware types.VirtualHardware) (types.VirtualMachineConfigSpec, []string) {
+	var spec types.VirtualMachineConfigSpec
+	var changes []string
+	if cloneSpec.NumCPUs > 0 && cloneSpec.NumCPUs != hardware.NumCPU {
+		spec.NumCPUs = cloneSpec.NumCPUs
+		changes = append(changes, fmt.Sprintf("numCPUs %d -> %d", hardware.NumCPU, cloneSpec.NumCPUs))
+	}
+	if cloneSpec.NumCoresPerSocket > 0 && cloneSpec.NumCoresPerSocket != hardware.NumCoresPerSocket {
+		spec.NumCoresPerSocket = cloneSpec.NumCoresPerSocket
+		changes = append(changes, fmt.Sprintf("numCoresPerSocket %d -> %d", hardware.NumCoresPerSocket, cloneSpec.NumCoresPerSocket))
+	}
+	if cloneSpec.MemoryMiB > 0 && cloneSpec.MemoryMiB != int64(hardware.MemoryMB) {
+		spec.MemoryMB = cloneSpec.MemoryMiB
+		changes = append(changes, fmt.Sprintf("memoryMiB %d -> %d", hardware.MemoryMB, cloneSpec.MemoryMiB))
+	}
+	return spec, changes
+}
+
+// hotAddChange returns the config spec which hot-adds the CPUs and memory defined in the clone
+// spec to the VM, and a description of the changes. Only increases are hot-added, if hot-add is
+// enabled on the VM, and CPUs only if the number of cores per socket is kept.
+func hotAddChange(cloneSpec infrav1.VirtualMachineCloneSpec, config types.VirtualMachineConfigInfo) (types.VirtualMachineConfigSpec, []string) {
+	var spec types.VirtualMachineConfigSpec
+	var changes []string
+	hardware := config.Hardware
+	coresPerSocketKept := cloneSpec.NumCoresPerSocket == 0 || cloneSpec.NumCoresPerSocket == hardware.NumCoresPerSocket
+	if ptr.Deref(config.CpuHotAddEnabled, false) && coresPerSocketKept && cloneSpec.NumCPUs > hardware.NumCPU &&
+		(hardware.NumCoresPerSocket == 0 || cloneSpec.NumCPUs%hardware.NumCoresPerSocket == 0) {
+		spec.NumCPUs = cloneSpec.NumCPUs
+		changes = append(changes, fmt.Sprintf("numCPUs %d -> %d", hardware.NumCPU, cloneSpec.NumCPUs))
+	}
+	if ptr.Deref(config.MemoryHotAddEnabled, false) && cloneSpec.MemoryMiB > int64(hardware.MemoryMB) {
+		spec.MemoryMB = cloneSpec.MemoryMiB
+		changes = append(changes, fmt.Sprintf("memoryMiB %d -> %d", hardware.MemoryMB, cloneSpec.MemoryMiB))
+	}
+	return spec, changes
+}
@@ -0,0 +1,444 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+)
+
+// reconfigureAndWait reconfigures the VM with the changes batched by the reconcile steps and
+// waits for the reconfigure task to complete.
+func reconfigureAndWait(ctx context.Context, g *WithT, c *vim25.Client, vms *VMService, vmCtx *virtualMachineContext) {
+	ok, err := vms.reconcileConfigChange(ctx, vmCtx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+	g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+	task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+	g.Expect(task.Wait(ctx)).To(Succeed())
+	vmCtx.VSphereVM.Status.TaskRef = ""
+}
+
+// concurrentlyChangedVM replaces a simulated VM for a single reconfigure, whose task fails
+// as the VM was changed since the change version passed with the reconfigure.
+type concurrentlyChangedVM struct {
+	*simulator.VirtualMachine
+}
+
+func (vm *concurrentlyChangedVM) ReconfigVMTask(ctx *simulator.Context, _ *types.ReconfigVM_Task) soap.HasFault {
+	ctx.Map.Put(vm.VirtualMachine)
+	task := simulator.CreateTask(vm.VirtualMachine, "reconfigVm", func(*simulator.Task) (types.AnyType, types.BaseMethodFault) {
+		return nil, &types.ConcurrentAccess{}
+	})
+	return &methods.ReconfigVM_TaskBody{
+		Res: &types.ReconfigVM_TaskResponse{
+			Returnval: task.Run(ctx),
+		},
+	}
+}
+
+func Test_reconcileConfigChange(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	t.Run("when the VM has not drifted", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		ok, err := vms.reconcileConfigChange(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(BeFalse())
+	})
+
+	t.Run("when multiple attributes of the VM have drifted", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						ReconfigurePolicy: infrav1.ReconfigurePolicyDeferredUntilPowerOff,
+						NumCPUs:           4,
+						BootOptions: &infrav1.VirtualMachineBootOptions{
+							BootDelay: ptr.To[int64](5000),
+						},
+						Firmware:   infrav1.VirtualMachineFirmwareEFI,
+						SecureBoot: ptr.To(true),
+					},
+				},
+			}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			for _, reconcile := range []func(context.Context, *virtualMachineContext) (bool, error){
+				vms.reconcileDeferredReconfigure,
+				vms.reconcileBootOptions,
+				vms.reconcileFirmware,
+			} {
+				ok, err := reconcile(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+			}
+
+			// All changes are applied by a single reconfigure task.
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(Equal(infrav1.ReconfiguringReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(And(
+				ContainSubstring("numCPUs"), ContainSubstring("bootDelay"), ContainSubstring("secureBoot")))
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.hardware", "config.bootOptions"}, &virtualMachine)).To(Succeed())
+			g.Expect(virtualMachine.Config.Hardware.NumCPU).To(Equal(int32(4)))
+			g.Expect(virtualMachine.Config.BootOptions.BootDelay).To(Equal(int64(5000)))
+			g.Expect(virtualMachine.Config.BootOptions.EfiSecureBootEnabled).To(Equal(ptr.To(true)))
+
+			// The condition is true once the VM has no drift left.
+			ok, err := vms.reconcileConfigChange(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(BeTrue())
+			return nil
+		})
+	})
+
+	t.Run("when the VM is changed concurrently", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			// Like vCenter, fail the reconfigure task if the change version is outdated.
+			simulator.Map.Handler = func(simCtx *simulator.Context, m *simulator.Method) (mo.Reference, types.BaseMethodFault) {
+				if req, ok := m.Body.(*types.ReconfigVM_Task); ok && req.Spec.ChangeVersion != "" {
+					if vm := simCtx.Map.Get(m.This).(*simulator.VirtualMachine); vm.Config.ChangeVersion != req.Spec.ChangeVersion {
+						simCtx.Map.Put(&concurrentlyChangedVM{vm})
+					}
+				}
+				return nil, nil
+			}
+			editVM := func(vm *object.VirtualMachine, annotation string) {
+				task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{Annotation: annotation})
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(task.Wait(ctx)).To(Succeed())
+			}
+
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			editVM(vm, "edited by another tool")
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						LoggingOptions: &infrav1.VirtualMachineLoggingOptions{Enabled: ptr.To(false)},
+					},
+				},
+			}
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			g.Expect(vms.observeChangeVersion(ctx, vmCtx)).To(Succeed())
+			g.Expect(vmCtx.ConfigChange.changeVersion).ToNot(BeEmpty())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileLoggingOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+
+			// The VM is changed after its drift was detected, so the reconfigure task fails.
+			editVM(vm, "edited again by another tool")
+			ok, err = vms.reconcileConfigChange(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).ToNot(Succeed())
+			var taskObj mo.Task
+			g.Expect(task.Properties(ctx, task.Reference(), []string{"info"}, &taskObj)).To(Succeed())
+			ok, err = checkAndRetryTask(ctx, &vmCtx.VMContext, &taskObj)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(Equal(infrav1.ConcurrentModificationReason))
+
+			// The retry detects the drift again with the fresh state of the VM.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			g.Expect(vms.observeChangeVersion(ctx, vmCtx)).To(Succeed())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileLoggingOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.annotation", "config.extraConfig"}, &virtualMachine)).To(Succeed())
+			g.Expect(virtualMachine.Config.Annotation).To(Equal("edited again by another tool"))
+			g.Expect(virtualMachine.Config.ExtraConfig).To(ContainElement(HaveField("GetOptionValue().Value", "FALSE")))
+			return nil
+		})
+	})
+}
+
+func Test_configChange_add(t *testing.T) {
+	g := NewWithT(t)
+
+	var change configChange
+	change.add(types.VirtualMachineConfigSpec{
+		NumCPUs:     4,
+		BootOptions: &types.VirtualMachineBootOptions{BootDelay: 5000},
+		ExtraConfig: []types.BaseOptionValue{&types.OptionValue{Key: "a", Value: "1"}},
+	}, "numCPUs 2 -> 4", "bootDelay 0 -> 5000")
+	change.add(types.VirtualMachineConfigSpec{
+		BootOptions: &types.VirtualMachineBootOptions{EfiSecureBootEnabled: ptr.To(true)},
+		ExtraConfig: []types.BaseOptionValue{&types.OptionValue{Key: "b", Value: "2"}},
+	}, "secureBoot false -> true")
+
+	g.Expect(change.changes).To(Equal([]string{"numCPUs 2 -> 4", "bootDelay 0 -> 5000", "secureBoot false -> true"}))
+	g.Expect(change.spec).To(Equal(types.VirtualMachineConfigSpec{
+		NumCPUs:     4,
+		BootOptions: &types.VirtualMachineBootOptions{BootDelay: 5000, EfiSecureBootEnabled: ptr.To(true)},
+		ExtraConfig: []types.BaseOptionValue{&types.OptionValue{Key: "a", Value: "1"}, &types.OptionValue{Key: "b", Value: "2"}},
+	}))
+}
+
+func Test_reconcileDeferredReconfigure(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(policy infrav1.VirtualMachineReconfigurePolicy, numCPUs int32, memoryMiB int64) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ReconfigurePolicy: policy,
+					NumCPUs:           numCPUs,
+					MemoryMiB:         memoryMiB,
+				},
+			},
+		}
+	}
+
+	getHardware := func(ctx context.Context, vm *object.VirtualMachine) types.VirtualHardware {
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.hardware"}, &virtualMachine)).To(Succeed())
+		return virtualMachine.Config.Hardware
+	}
+
+	t.Run("when the reconfigure policy is not set", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM("", 4, 8192)
+		ok, err := vms.reconcileDeferredReconfigure(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the changes are deferred until the VM is powered off", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+
+			hardware := getHardware(ctx, vm)
+			vmCtx.VSphereVM = newVSphereVM(infrav1.ReconfigurePolicyDeferredUntilPowerOff, hardware.NumCPU*2, int64(hardware.MemoryMB)*2)
+
+			// The changes are recorded while the VM is powered on.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(Equal(infrav1.ReconfigureDeferredUntilPowerOffReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(ContainSubstring("numCPUs"))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(ContainSubstring("memoryMiB"))
+			g.Expect(getHardware(ctx, vm).NumCPU).To(Equal(hardware.NumCPU))
+
+			// The changes are applied once the VM is observed powered off.
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(Equal(infrav1.ApplyingDeferredReconfigureReason))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+			g.Expect(getHardware(ctx, vm).NumCPU).To(Equal(hardware.NumCPU * 2))
+			g.Expect(getHardware(ctx, vm).MemoryMB).To(Equal(hardware.MemoryMB * 2))
+
+			// The condition is removed once the changes are applied.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(BeFalse())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the node capacity refresh is enabled", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		g.Expect(feature.MutableGates.Set("NodeCapacityRefresh=true")).To(Succeed())
+		t.Cleanup(func() { _ = feature.MutableGates.Set("NodeCapacityRefresh=false") })
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			hardware := getHardware(ctx, vm)
+			vmCtx.VSphereVM = newVSphereVM(infrav1.ReconfigurePolicyDeferredUntilPowerOff, hardware.NumCPU*2, int64(hardware.MemoryMB))
+
+			// The node is cordoned before the changes are applied.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(Equal(infrav1.ApplyingDeferredReconfigureReason))
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition)).To(Equal(infrav1.WaitingForNodeCapacityReason))
+
+			// The changes are applied by the next reconcile.
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+			g.Expect(getHardware(ctx, vm).NumCPU).To(Equal(hardware.NumCPU * 2))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition)).To(Equal(infrav1.WaitingForNodeCapacityReason))
+			return nil
+		})
+	})
+
+	t.Run("when the changes are hot-added", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		g.Expect(feature.MutableGates.Set("NodeCapacityRefresh=true")).To(Succeed())
+		t.Cleanup(func() { _ = feature.MutableGates.Set("NodeCapacityRefresh=false") })
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{CpuHotAddEnabled: ptr.To(true)})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			hardware := getHardware(ctx, vm)
+			vmCtx.VSphereVM = newVSphereVM(infrav1.ReconfigurePolicyHotAdd, hardware.NumCPU*2, int64(hardware.MemoryMB)*2)
+
+			// The node is cordoned before the CPUs are hot-added to the powered on VM.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(Equal(infrav1.HotAddingReason))
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.NodeCapacityRefreshedCondition)).To(Equal(infrav1.WaitingForNodeCapacityReason))
+
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf(ContainSubstring("numCPUs")))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+			g.Expect(getHardware(ctx, vm).NumCPU).To(Equal(hardware.NumCPU * 2))
+
+			// The memory is deferred, as memory hot-add is not enabled on the VM.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileDeferredReconfigure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(Equal(infrav1.ReconfigureDeferredUntilPowerOffReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).To(ContainSubstring("memoryMiB"))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.ReconfigurePendingCondition)).ToNot(ContainSubstring("numCPUs"))
+			return nil
+		})
+	})
+}
+
+func Test_hardwareChange(t *testing.T) {
+	g := NewWithT(t)
+	hardware := types.VirtualHardware{NumCPU: 2, NumCoresPerSocket: 1, MemoryMB: 4096}
+
+	spec, changes := hardwareChange(infrav1.VirtualMachineCloneSpec{}, hardware)
+	g.Expect(changes).To(BeEmpty())
+	g.Expect(spec).To(Equal(types.VirtualMachineConfigSpec{}))
+
+	spec, changes = hardwareChange(infrav1.VirtualMachineCloneSpec{NumCPUs: 2, NumCoresPerSocket: 2, MemoryMiB: 8192}, hardware)
+	g.Expect(changes).To(Equal([]string{"numCoresPerSocket 1 -> 2", "memoryMiB 4096 -> 8192"}))
+	g.Expect(spec).To(Equal(types.VirtualMachineConfigSpec{NumCoresPerSocket: 2, MemoryMB: 8192}))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// reconcileRedeploy redeploys the VM of a VSphereVM with the RedeployAnnotation once its
+// template differs from the one the VM was cloned from. The VM is powered off and destroyed,
+// and the next reconciles clone it from the new template. The MAC addresses of the VM are
+// written to the network devices of the VSphereVM before, and the clone gets the BIOS UUID of
+// the destroyed VM, so the VM keeps its identity where possible. It returns false while the
+// VM is powered off or destroyed.
+func (vms *VMService) reconcileRedeploy(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+	vsphereVM := virtualMachineCtx.VSphereVM
+
+	// The VM found while it is cloned is the clone of the new template.
+	if conditions.GetReason(vsphereVM, infrav1.VMRedeployedCondition) == infrav1.RedeployCloningReason {
+		log.Info("VM was redeployed", "template", vsphereVM.Spec.Template)
+		vsphereVM.Status.DeployedTemplate = vsphereVM.Spec.Template
+		conditions.MarkTrue(vsphereVM, infrav1.VMRedeployedCondition)
+		return true, nil
+	}
+	if vsphereVM.Status.DeployedTemplate == "" {
+		vsphereVM.Status.DeployedTemplate = vsphereVM.Spec.Template
+	}
+	if _, ok := vsphereVM.Annotations[infrav1.RedeployAnnotation]; !ok || vsphereVM.Spec.Template == vsphereVM.Status.DeployedTemplate {
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config != nil {
+		preserveMACAddrs(vsphereVM, virtualMachine.Config.Hardware.Device)
+	}
+
+	if virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployPoweringOffReason, clusterv1.ConditionSeverityInfo,
+			"redeploying VM from template %s", vsphereVM.Spec.Template)
+
+		softPowerOffPending, err := vms.triggerSoftPowerOff(ctx, virtualMachineCtx)
+		if err != nil {
+			conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+		if softPowerOffPending {
+			log.Info("Wait for guest of VM to be shut down to redeploy it")
+			return false, nil
+		}
+
+		task, err := virtualMachineCtx.Obj.PowerOff(ctx)
+		if err != nil {
+			conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, errors.Wrapf(err, "unable to power off VM %s to redeploy it", vsphereVM.Name)
+		}
+		vsphereVM.Status.TaskRef = task.Reference().Value
+		log.Info("Wait for VM to be powered off to redeploy it")
+		return false, nil
+	}
+	conditions.Delete(vsphereVM, infrav1.GuestSoftPowerOffSucceededCondition)
+
+	// Shared disks are detached before destroying the VM, so they are not
+	// deleted together with the VM while other VMs still use them.
+	if len(vsphereVM.Spec.SharedDisks) > 0 {
+		task, err := detachSharedDisks(ctx, virtualMachineCtx)
+		if err != nil {
+			conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, errors.Wrapf(err, "failed to detach shared disks from VM %s", vsphereVM.Name)
+		}
+		if task != nil {
+			vsphereVM.Status.TaskRef = task.Reference().Value
+			log.Info("Wait for shared disks to be detached from VM to redeploy it")
+			return false, nil
+		}
+	}
+	if vsphereVM.Spec.CloudInitDatasource == infrav1.CloudInitDatasourceNoCloud {
+		if err := deleteNoCloudSeed(ctx, virtualMachineCtx); err != nil {
+			log.Error(err, "Failed to delete NoCloud seed of VM (best-effort)")
+		}
+	}
+
+	log.Info("Destroying VM to redeploy it", "template", vsphereVM.Spec.Template)
+	task, err := virtualMachineCtx.Obj.Destroy(ctx)
+	if err != nil {
+		conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to destroy VM %s to redeploy it", vsphereVM.Name)
+	}
+	vsphereVM.Status.TaskRef = task.Reference().Value
+	// The clone of the new template is a new managed object, and is not ready
+	// until it is provisioned again.
+	vsphereVM.Status.VMRef = ""
+	// The clone of the new template boots with Secure Boot again.
+	conditions.Delete(vsphereVM, infrav1.SecureBootLikelyFailedCondition)
+	vsphereVM.Status.Ready = false
+	conditions.MarkFalse(vsphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployDestroyingReason, clusterv1.ConditionSeverityInfo,
+		"redeploying VM from template %s", vsphereVM.Spec.Template)
+	log.Info("Wait for VM to be destroyed to redeploy it")
+	return false, nil
+}
+
+// isRedeploying returns whether the VM of the VSphereVM is destroyed or cloned to be
+// redeployed, i.e. whether the VM is expected to be missing.
+func isRedeploying(vsphereVM *infrav1.VSphereVM) bool {
+	switch conditions.GetReason(vsphereVM, infrav1.VMRedeployedCondition) {
+	case infrav1.RedeployDestroyingReason, infrav1.RedeployCloningReason:
+		return true
+	default:
+		return false
+	}
+}
+
+// findRedeployedVM finds the clone of the new template of a redeployed VM by its instance
+// UUID, as vCenter may not assign it the BIOS UUID of the destroyed VM.
+func findRedeployedVM(ctx context.Context, vmCtx *capvcontext.VMContext) (types.ManagedObjectReference, error) {
+	objRef, err := vmCtx.Session.FindByInstanceUUID(ctx, string(vmCtx.VSphereVM.UID))
+	if err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if objRef == nil {
+		return types.ManagedObjectReference{}, errNotFound{}
+	}
+	return objRef.Reference(), nil
+}
+
+// preserveMACAddrs sets the MAC addresses of the network devices of the VSphereVM without
+// one to the MAC addresses of the VM, so the clone of a redeployed VM gets the same MAC
+// addresses. The network devices of the VM match the network devices of the VSphereVM by
+// their position, as they are added to the VM in order when it is cloned.
+func preserveMACAddrs(vsphereVM *infrav1.VSphereVM, devices object.VirtualDeviceList) {
+	nics := devices.SelectByType((*types.VirtualEthernetCard)(nil))
+	for i := range vsphereVM.Spec.Network.Devices {
+		if i >= len(nics) {
+			return
+		}
+		device := &vsphereVM.Spec.Network.Devices[i]
+		if device.MACAddr == "" {
+			device.MACAddr = nics[i].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+		}
+	}
+}
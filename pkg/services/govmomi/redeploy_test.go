@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileRedeploy(t *testing.T) {
+	var g *WithT
+
+	newVSphereVM := func(template string, annotations map[string]string) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "vsphereVM1",
+				Namespace:   "my-namespace",
+				Annotations: annotations,
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					Template: template,
+					Network: infrav1.NetworkSpec{
+						Devices: []infrav1.NetworkDeviceSpec{{NetworkName: "VM Network"}},
+					},
+				},
+				PowerOffMode: infrav1.VirtualMachinePowerOpModeHard,
+			},
+			Status: infrav1.VSphereVMStatus{
+				DeployedTemplate: "ubuntu-2204-kube-v1.28.0",
+				VMRef:            "vm-1",
+				Ready:            true,
+			},
+		}
+	}
+
+	waitForTask := func(ctx context.Context, c *vim25.Client, vmCtx *virtualMachineContext) {
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+		task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+		g.Expect(task.Wait(ctx)).To(Succeed())
+		vmCtx.VSphereVM.Status.TaskRef = ""
+	}
+
+	t.Run("when the template is not changed", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("ubuntu-2204-kube-v1.28.0", map[string]string{infrav1.RedeployAnnotation: ""})
+		vmCtx.VSphereVM.Status.DeployedTemplate = ""
+
+		ok, err := (&VMService{}).reconcileRedeploy(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.DeployedTemplate).To(Equal("ubuntu-2204-kube-v1.28.0"))
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMRedeployedCondition)).To(BeFalse())
+	})
+
+	t.Run("when the template is changed without the redeploy annotation", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("ubuntu-2204-kube-v1.29.0", nil)
+
+		ok, err := (&VMService{}).reconcileRedeploy(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.DeployedTemplate).To(Equal("ubuntu-2204-kube-v1.28.0"))
+	})
+
+	t.Run("when the VM is redeployed from the new template", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+			nic := object.VirtualDeviceList(simVM.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))[0]
+			macAddr := nic.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM("ubuntu-2204-kube-v1.29.0", map[string]string{infrav1.RedeployAnnotation: ""})
+			vms := &VMService{}
+
+			// The powered on VM is powered off first.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileRedeploy(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMRedeployedCondition)).To(Equal(infrav1.RedeployPoweringOffReason))
+			g.Expect(vmCtx.VSphereVM.Spec.Network.Devices[0].MACAddr).To(Equal(macAddr))
+			waitForTask(ctx, c, vmCtx)
+
+			// The powered off VM is destroyed.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileRedeploy(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMRedeployedCondition)).To(Equal(infrav1.RedeployDestroyingReason))
+			g.Expect(isRedeploying(vmCtx.VSphereVM)).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.VMRef).To(BeEmpty())
+			g.Expect(vmCtx.VSphereVM.Status.Ready).To(BeFalse())
+			waitForTask(ctx, c, vmCtx)
+			_, err = find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).To(HaveOccurred())
+			return nil
+		})
+	})
+
+	t.Run("when the clone of the new template is found", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("ubuntu-2204-kube-v1.29.0", map[string]string{infrav1.RedeployAnnotation: ""})
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployCloningReason, clusterv1.ConditionSeverityInfo, "")
+
+		ok, err := (&VMService{}).reconcileRedeploy(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.DeployedTemplate).To(Equal("ubuntu-2204-kube-v1.29.0"))
+		g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMRedeployedCondition)).To(BeTrue())
+		g.Expect(isRedeploying(vmCtx.VSphereVM)).To(BeFalse())
+	})
+}
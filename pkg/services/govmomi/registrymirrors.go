@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+const (
+	// registryMirrorsDropInPath is the path of the drop-in of the containerd configuration
+	// which makes the CRI plugin read the configurations of the registry hosts. It is imported
+	// by the containerd configuration of the images built by image-builder.
+	registryMirrorsDropInPath = "/etc/containerd/conf.d/capv-registry-mirrors.toml"
+
+	// registryHostsDir is the directory of the configurations of the registry hosts, with one
+	// directory per registry.
+	registryHostsDir = "/etc/containerd/certs.d"
+
+	// registryCASecretKey is the key of the CA certificate in the secret of a registry mirror.
+	registryCASecretKey = "ca.crt"
+)
+
+// errRegistryMirrorsInvalid is returned if the registry mirrors of a VSphereVM cannot be added
+// to the bootstrap data.
+var errRegistryMirrorsInvalid = errors.New("registry mirrors invalid")
+
+// getRegistryMirrorFiles returns the containerd configuration files of the registry mirrors
+// of the VSphereVM, with the CA certificates read from their Secrets.
+func getRegistryMirrorFiles(ctx context.Context, vmCtx *capvcontext.VMContext) ([]secretFile, error) {
+	mirrors := vmCtx.VSphereVM.Spec.RegistryMirrors
+	if len(mirrors) == 0 || vmCtx.VSphereVM.Spec.OS == infrav1.Windows {
+		return nil, nil
+	}
+
+	files := []secretFile{registryFile(registryMirrorsDropInPath, []byte(registryMirrorsDropInContent()))}
+	for _, mirror := range mirrors {
+		var caPath string
+		if mirror.CASecretName != "" {
+			secret := &corev1.Secret{}
+			secretKey := apitypes.NamespacedName{
+				Namespace: vmCtx.VSphereVM.Namespace,
+				Name:      mirror.CASecretName,
+			}
+			if err := vmCtx.Client.Get(ctx, secretKey, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, errors.Wrapf(errRegistryMirrorsInvalid, "CA secret %s of registry %s not found", secretKey, mirror.Registry)
+				}
+				return nil, errors.Wrapf(err, "failed to get CA secret %s of registry %s", secretKey, mirror.Registry)
+			}
+			ca, ok := secret.Data[registryCASecretKey]
+			if !ok {
+				return nil, errors.Wrapf(errRegistryMirrorsInvalid, "CA secret %s of registry %s has no key %s", secretKey, mirror.Registry, registryCASecretKey)
+			}
+			caPath = path.Join(registryHostsDir, mirror.Registry, registryCASecretKey)
+			files = append(files, registryFile(caPath, ca))
+		}
+		files = append(files, registryFile(path.Join(registryHostsDir, mirror.Registry, "hosts.toml"), []byte(registryHostsContent(mirror, caPath))))
+	}
+	return files, nil
+}
+
+// addRegistryMirrors adds the containerd configuration files of the registry mirrors to the
+// bootstrap data. containerd is restarted by cloud-config, as it is already running when
+// cloud-init writes the files, while Ignition writes them before containerd is started.
+func addRegistryMirrors(data []byte, format bootstrapv1.Format, files []secretFile) ([]byte, error) {
+	if len(files) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	data, err := addSecretFiles(data, format, files)
+	if err != nil || format != bootstrapv1.CloudConfig {
+		return data, err
+	}
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	runcmd, _ := config["runcmd"].([]interface{})
+	config["runcmd"] = append(runcmd, "systemctl restart containerd")
+	return marshalCloudConfig(header, config)
+}
+
+func registryFile(p string, content []byte) secretFile {
+	return secretFile{
+		SecretFile: infrav1.SecretFile{Path: p, Permissions: "0644"},
+		content:    content,
+	}
+}
+
+func registryMirrorsDropInContent() string {
+	return fmt.Sprintf("version = 2\n\n[plugins.\"io.containerd.grpc.v1.cri\".registry]\n  config_path = %q\n", registryHostsDir)
+}
+
+// registryHostsContent returns the hosts.toml of the registry, which lists its mirrors in
+// order before the registry itself. The CA certificate, if any, is trusted for both.
+func registryHostsContent(mirror infrav1.RegistryMirror, caPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "server = %q\n", registryServer(mirror.Registry))
+	if caPath != "" {
+		fmt.Fprintf(&b, "ca = %q\n", caPath)
+	}
+	for _, endpoint := range mirror.Endpoints {
+		fmt.Fprintf(&b, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", endpoint)
+		if caPath != "" {
+			fmt.Fprintf(&b, "  ca = %q\n", caPath)
+		}
+	}
+	return b.String()
+}
+
+// registryServer returns the URL of the registry, which differs from its host for Docker Hub.
+func registryServer(registry string) string {
+	if registry == "docker.io" {
+		return "https://registry-1.docker.io"
+	}
+	return "https://" + registry
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_getRegistryMirrorFiles(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-ca",
+			Namespace: "my-namespace",
+		},
+		Data: map[string][]byte{
+			"ca.crt": []byte("certificate"),
+		},
+	}
+	dropIn := registryFile("/etc/containerd/conf.d/capv-registry-mirrors.toml",
+		[]byte("version = 2\n\n[plugins.\"io.containerd.grpc.v1.cri\".registry]\n  config_path = \"/etc/containerd/certs.d\"\n"))
+
+	tests := []struct {
+		name      string
+		os        infrav1.OS
+		mirrors   []infrav1.RegistryMirror
+		expected  []secretFile
+		wantError bool
+	}{
+		{
+			name: "without registry mirrors",
+		},
+		{
+			name:    "with a mirror of Docker Hub",
+			mirrors: []infrav1.RegistryMirror{{Registry: "docker.io", Endpoints: []string{"https://mirror.example.com", "http://10.0.0.1:5000"}}},
+			expected: []secretFile{
+				dropIn,
+				registryFile("/etc/containerd/certs.d/docker.io/hosts.toml", []byte(`server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+
+[host."http://10.0.0.1:5000"]
+  capabilities = ["pull", "resolve"]
+`)),
+			},
+		},
+		{
+			name:    "with a CA certificate",
+			mirrors: []infrav1.RegistryMirror{{Registry: "registry.example.com:5000", Endpoints: []string{"https://mirror.example.com"}, CASecretName: "registry-ca"}},
+			expected: []secretFile{
+				dropIn,
+				registryFile("/etc/containerd/certs.d/registry.example.com:5000/ca.crt", []byte("certificate")),
+				registryFile("/etc/containerd/certs.d/registry.example.com:5000/hosts.toml", []byte(`server = "https://registry.example.com:5000"
+ca = "/etc/containerd/certs.d/registry.example.com:5000/ca.crt"
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  ca = "/etc/containerd/certs.d/registry.example.com:5000/ca.crt"
+`)),
+			},
+		},
+		{
+			name:    "on Windows",
+			os:      infrav1.Windows,
+			mirrors: []infrav1.RegistryMirror{{Registry: "docker.io", Endpoints: []string{"https://mirror.example.com"}}},
+		},
+		{
+			name:      "when the CA secret does not exist",
+			mirrors:   []infrav1.RegistryMirror{{Registry: "docker.io", CASecretName: "missing"}},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Client = fake.NewClientBuilder().WithObjects(secret).Build()
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace"},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{OS: tt.os},
+					RegistryMirrors:         tt.mirrors,
+				},
+			}
+
+			files, err := getRegistryMirrorFiles(context.Background(), &vmCtx.VMContext)
+			if tt.wantError {
+				g.Expect(errors.Is(err, errRegistryMirrorsInvalid)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(files).To(Equal(tt.expected))
+		})
+	}
+}
+
+func Test_addRegistryMirrors(t *testing.T) {
+	files := []secretFile{registryFile("/etc/containerd/certs.d/docker.io/hosts.toml", []byte("server = \"https://registry-1.docker.io\"\n"))}
+
+	tests := []struct {
+		name     string
+		data     string
+		format   bootstrapv1.Format
+		files    []secretFile
+		expected string
+	}{
+		{
+			name:     "without registry mirrors",
+			data:     "#cloud-config\nruncmd:\n- kubeadm join\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- kubeadm join\n",
+		},
+		{
+			name:   "cloud-config",
+			data:   "#cloud-config\nruncmd:\n- kubeadm join\n",
+			format: bootstrapv1.CloudConfig,
+			files:  files,
+			expected: "#cloud-config\nruncmd:\n- kubeadm join\n- systemctl restart containerd\nwrite_files:\n" +
+				"- content: c2VydmVyID0gImh0dHBzOi8vcmVnaXN0cnktMS5kb2NrZXIuaW8iCg==\n  encoding: b64\n" +
+				"  path: /etc/containerd/certs.d/docker.io/hosts.toml\n  permissions: \"0644\"\n",
+		},
+		{
+			name:   "ignition",
+			data:   `{"ignition":{"version":"3.1.0"}}`,
+			format: bootstrapv1.Ignition,
+			files:  files,
+			expected: `{"ignition":{"version":"3.1.0"},"storage":{"files":[` +
+				`{"contents":{"source":"data:;base64,c2VydmVyID0gImh0dHBzOi8vcmVnaXN0cnktMS5kb2NrZXIuaW8iCg=="},"mode":420,"overwrite":true,"path":"/etc/containerd/certs.d/docker.io/hosts.toml"}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addRegistryMirrors([]byte(tt.data), tt.format, tt.files)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+// reconcileVMName renames the VM to the name of the VSphereVM if the VMRename feature gate
+// is enabled and the names differ. The VM keeps its name if the name of the VSphereVM
+// exceeds the maximum length of a VM name or another VM in its folder has that name.
+func (vms *VMService) reconcileVMName(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !feature.Gates.Enabled(feature.VMRename) {
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	name := util.GetVMName(virtualMachineCtx.VSphereVM)
+	if virtualMachine.Name == name {
+		if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition) {
+			conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition)
+		}
+		return true, nil
+	}
+
+	if len(name) > infrav1.MaxVirtualMachineNameLength {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenameFailedReason, clusterv1.ConditionSeverityWarning,
+			"unable to rename VM %s to %s, as the name exceeds %d characters", virtualMachine.Name, name, infrav1.MaxVirtualMachineNameLength)
+		return true, nil
+	}
+	if virtualMachine.Parent != nil {
+		conflicting, err := object.NewSearchIndex(virtualMachineCtx.Session.Client.Client).FindChild(ctx, *virtualMachine.Parent, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to find VM %s in the folder of VM %s", name, virtualMachineCtx.VSphereVM.Name)
+		}
+		if conflicting != nil {
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenameFailedReason, clusterv1.ConditionSeverityWarning,
+				"unable to rename VM %s to %s, as %s in its folder has that name", virtualMachine.Name, name, conflicting.Reference().Value)
+			return true, nil
+		}
+	}
+
+	log.Info("Renaming VM", "oldName", virtualMachine.Name, "newName", name)
+	task, err := virtualMachineCtx.Obj.Rename(ctx, name)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenameFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to rename VM %s to %s", virtualMachine.Name, name)
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMNameSyncedCondition, infrav1.RenamingReason, clusterv1.ConditionSeverityInfo,
+		"renaming VM %s to %s", virtualMachine.Name, name)
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be renamed")
+	return false, nil
+}
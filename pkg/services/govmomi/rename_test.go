@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/feature"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileVMName(t *testing.T) {
+	var (
+		g     *WithT
+		vmCtx *virtualMachineContext
+		vms   *VMService
+	)
+
+	before := func(name string) {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "my-namespace",
+			},
+		}
+		vms = &VMService{}
+	}
+
+	setup := func(ctx context.Context, c *vim25.Client) {
+		finder := find.NewFinder(c)
+		datacenter, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(datacenter)
+
+		vm, err := finder.VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+		vmCtx.Obj = vm
+		vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}, Finder: finder}
+	}
+
+	vmName := func(ctx context.Context) string {
+		name, err := vmCtx.Obj.ObjectName(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		return name
+	}
+
+	t.Run("when the feature gate is disabled", func(t *testing.T) {
+		g = NewWithT(t)
+		before("renamed")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			setup(ctx, c)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileVMName(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(vmName(ctx)).To(Equal("DC0_H0_VM0"))
+			return nil
+		})
+	})
+
+	t.Run("when the feature gate is enabled", func(t *testing.T) {
+		g = NewWithT(t)
+		g.Expect(feature.MutableGates.Set("VMRename=true")).To(Succeed())
+		t.Cleanup(func() { _ = feature.MutableGates.Set("VMRename=false") })
+
+		t.Run("the VM with the name of the VSphereVM is not renamed", func(t *testing.T) {
+			g = NewWithT(t)
+			before("DC0_H0_VM0")
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileVMName(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(BeFalse())
+				return nil
+			})
+		})
+
+		t.Run("the VM is renamed to the name of the VSphereVM", func(t *testing.T) {
+			g = NewWithT(t)
+			before("renamed")
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileVMName(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeFalse())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(Equal(infrav1.RenamingReason))
+				g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(Equal("renaming VM DC0_H0_VM0 to renamed"))
+
+				task := object.NewTask(c, types.ManagedObjectReference{Type: "Task", Value: vmCtx.VSphereVM.Status.TaskRef})
+				g.Expect(task.Wait(ctx)).To(Succeed())
+				vmCtx.VSphereVM.Status.TaskRef = ""
+				g.Expect(vmName(ctx)).To(Equal("renamed"))
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err = vms.reconcileVMName(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(BeTrue())
+				return nil
+			})
+		})
+
+		t.Run("the VM is not renamed if another VM in its folder has the name", func(t *testing.T) {
+			g = NewWithT(t)
+			before("DC0_H0_VM1")
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileVMName(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(Equal(infrav1.RenameFailedReason))
+				g.Expect(vmName(ctx)).To(Equal("DC0_H0_VM0"))
+				return nil
+			})
+		})
+
+		t.Run("the VM is not renamed if the name exceeds the maximum length", func(t *testing.T) {
+			g = NewWithT(t)
+			before(strings.Repeat("a", infrav1.MaxVirtualMachineNameLength+1))
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				setup(ctx, c)
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileVMName(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMNameSyncedCondition)).To(Equal(infrav1.RenameFailedReason))
+				g.Expect(vmName(ctx)).To(Equal("DC0_H0_VM0"))
+				return nil
+			})
+		})
+	})
+}
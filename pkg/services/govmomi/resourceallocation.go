@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+)
+
+// reconcileResourceAllocation reports the effective CPU and memory shares of the VM and
+// ensures its resource allocation matches the one defined in the spec. The reservation,
+// limit and shares are compared independently, so only the drifted ones are reconfigured.
+func (vms *VMService) reconcileResourceAllocation(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var cpuAllocation, memoryAllocation *types.ResourceAllocationInfo
+	if virtualMachine.Config != nil {
+		cpuAllocation = virtualMachine.Config.CpuAllocation
+		memoryAllocation = virtualMachine.Config.MemoryAllocation
+	}
+	virtualMachineCtx.VSphereVM.Status.CPUShares = resourceSharesStatus(cpuAllocation)
+	virtualMachineCtx.VSphereVM.Status.MemoryShares = resourceSharesStatus(memoryAllocation)
+
+	allocation := virtualMachineCtx.VSphereVM.Spec.ResourceAllocation
+	if allocation == nil {
+		log.V(5).Info("Resource allocation not defined. skipping reconcile resource allocation")
+		return true, nil
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		CpuAllocation:    resourceAllocationChange(cpuAllocation, allocation.CPU),
+		MemoryAllocation: resourceAllocationChange(memoryAllocation, allocation.Memory),
+	}
+	var changes []string
+	if spec.CpuAllocation != nil {
+		changes = append(changes, "resourceAllocation.cpu")
+	}
+	if spec.MemoryAllocation != nil {
+		changes = append(changes, "resourceAllocation.memory")
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+	virtualMachineCtx.ResourceDrift = append(virtualMachineCtx.ResourceDrift, changes...)
+
+	if virtualMachineCtx.VSphereVM.Spec.ResourceDriftPolicy == infrav1.ResourceDriftPolicyReport {
+		log.Info("VM resource allocation drifted from spec", "changes", changes)
+		return true, nil
+	}
+
+	log.Info("Updating VM resource allocation", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(spec, changes...)
+	return true, nil
+}
+
+// reconcileResourcesConverged reports whether the resource allocation and the disk Storage I/O
+// allocation of the VM match the spec, based on the drift detected by the previous steps.
+func (vms *VMService) reconcileResourcesConverged(virtualMachineCtx *virtualMachineContext) {
+	spec := virtualMachineCtx.VSphereVM.Spec
+	if spec.ResourceAllocation == nil && spec.DiskStorageIOAllocation == nil {
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.ResourcesConvergedCondition)
+		return
+	}
+	if len(virtualMachineCtx.ResourceDrift) == 0 {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.ResourcesConvergedCondition)
+		return
+	}
+
+	drift := strings.Join(virtualMachineCtx.ResourceDrift, ", ")
+	if spec.ResourceDriftPolicy == infrav1.ResourceDriftPolicyReport {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.ResourcesConvergedCondition, infrav1.ResourcesDriftedReason, clusterv1.ConditionSeverityWarning,
+			"%s drifted from the spec", drift)
+		return
+	}
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.ResourcesConvergedCondition, infrav1.ResourcesDriftedReason, clusterv1.ConditionSeverityInfo,
+		"%s drifted from the spec, reconfiguring the VM", drift)
+}
+
+// resourceAllocationChange returns the resource allocation which only contains the
+// reservation, limit and shares of the spec which differ from the current ones, or nil
+// if none of them differ.
+func resourceAllocationChange(current *types.ResourceAllocationInfo, desired *infrav1.ResourceAllocationSpec) *types.ResourceAllocationInfo {
+	if desired == nil {
+		return nil
+	}
+	if current == nil {
+		current = &types.ResourceAllocationInfo{}
+	}
+	desiredInfo := vcenter.ResourceAllocationInfo(desired)
+
+	var change types.ResourceAllocationInfo
+	var changed bool
+	if desiredInfo.Reservation != nil && *desiredInfo.Reservation != ptr.Deref(current.Reservation, 0) {
+		change.Reservation = desiredInfo.Reservation
+		changed = true
+	}
+	if desiredInfo.Limit != nil && *desiredInfo.Limit != ptr.Deref(current.Limit, -1) {
+		change.Limit = desiredInfo.Limit
+		changed = true
+	}
+	if desiredInfo.Shares != nil && !sharesMatch(current.Shares, desiredInfo.Shares) {
+		change.Shares = desiredInfo.Shares
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return &change
+}
+
+// sharesMatch returns true if the current shares have the desired level and, for the
+// custom level, the desired number of shares.
+func sharesMatch(current, desired *types.SharesInfo) bool {
+	if current == nil || current.Level != desired.Level {
+		return false
+	}
+	return desired.Level != types.SharesLevelCustom || current.Shares == desired.Shares
+}
+
+// resourceSharesStatus returns the shares of the given resource allocation.
+func resourceSharesStatus(allocation *types.ResourceAllocationInfo) *infrav1.ResourceShares {
+	if allocation == nil || allocation.Shares == nil {
+		return nil
+	}
+	return &infrav1.ResourceShares{
+		Level:  infrav1.SharesLevel(allocation.Shares.Level),
+		Shares: allocation.Shares.Shares,
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileResourceAllocation(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(allocation *infrav1.VirtualMachineResourceAllocation) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ResourceAllocation: allocation,
+				},
+			},
+		}
+	}
+
+	t.Run("when resource allocation is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(nil)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileResourceAllocation(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(vmCtx.VSphereVM.Status.CPUShares).ToNot(BeNil())
+			g.Expect(vmCtx.VSphereVM.Status.CPUShares.Level).To(Equal(infrav1.SharesLevelNormal))
+			return nil
+		})
+	})
+
+	t.Run("when VM has different shares", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineResourceAllocation{
+				CPU: &infrav1.ResourceAllocationSpec{
+					Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelHigh},
+				},
+				Memory: &infrav1.ResourceAllocationSpec{
+					Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelCustom, Shares: 40960},
+				},
+			})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileResourceAllocation(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once the shares match and reports them.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileResourceAllocation(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(vmCtx.VSphereVM.Status.CPUShares).To(Equal(&infrav1.ResourceShares{Level: infrav1.SharesLevelHigh}))
+			g.Expect(vmCtx.VSphereVM.Status.MemoryShares).To(Equal(&infrav1.ResourceShares{Level: infrav1.SharesLevelCustom, Shares: 40960}))
+			return nil
+		})
+	})
+
+	t.Run("when VM has different shares and the drift is only reported", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineResourceAllocation{
+				CPU: &infrav1.ResourceAllocationSpec{
+					Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelHigh},
+				},
+			})
+			vmCtx.VSphereVM.Spec.ResourceDriftPolicy = infrav1.ResourceDriftPolicyReport
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileResourceAllocation(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ResourceDrift).To(ConsistOf("resourceAllocation.cpu"))
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(vmCtx.VSphereVM.Status.CPUShares.Level).To(Equal(infrav1.SharesLevelNormal))
+			return nil
+		})
+	})
+}
+
+func Test_reconcileResourcesConverged(t *testing.T) {
+	allocation := &infrav1.VirtualMachineResourceAllocation{
+		CPU: &infrav1.ResourceAllocationSpec{
+			Shares: &infrav1.ResourceShares{Level: infrav1.SharesLevelHigh},
+		},
+	}
+	vms := &VMService{}
+
+	newVMContext := func(allocation *infrav1.VirtualMachineResourceAllocation, policy infrav1.ResourceDriftPolicy, drift ...string) *virtualMachineContext {
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ResourceAllocation:  allocation,
+					ResourceDriftPolicy: policy,
+				},
+			},
+		}
+		vmCtx.ResourceDrift = drift
+		return vmCtx
+	}
+
+	t.Run("when resource allocation is not defined", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := newVMContext(nil, "")
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.ResourcesConvergedCondition, infrav1.ResourcesDriftedReason, clusterv1.ConditionSeverityWarning, "")
+
+		vms.reconcileResourcesConverged(vmCtx)
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.ResourcesConvergedCondition)).To(BeFalse())
+	})
+
+	t.Run("when resources match the spec", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := newVMContext(allocation, "")
+
+		vms.reconcileResourcesConverged(vmCtx)
+		g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.ResourcesConvergedCondition)).To(BeTrue())
+	})
+
+	t.Run("when drift is corrected", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := newVMContext(allocation, infrav1.ResourceDriftPolicyCorrect, "resourceAllocation.cpu")
+
+		vms.reconcileResourcesConverged(vmCtx)
+		c := conditions.Get(vmCtx.VSphereVM, infrav1.ResourcesConvergedCondition)
+		g.Expect(c).ToNot(BeNil())
+		g.Expect(c.Reason).To(Equal(infrav1.ResourcesDriftedReason))
+		g.Expect(c.Severity).To(Equal(clusterv1.ConditionSeverityInfo))
+		g.Expect(c.Message).To(Equal("resourceAllocation.cpu drifted from the spec, reconfiguring the VM"))
+	})
+
+	t.Run("when drift is only reported", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := newVMContext(allocation, infrav1.ResourceDriftPolicyReport, "resourceAllocation.cpu", "diskStorageIOAllocation disk-1000-0")
+
+		vms.reconcileResourcesConverged(vmCtx)
+		c := conditions.Get(vmCtx.VSphereVM, infrav1.ResourcesConvergedCondition)
+		g.Expect(c).ToNot(BeNil())
+		g.Expect(c.Reason).To(Equal(infrav1.ResourcesDriftedReason))
+		g.Expect(c.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+		g.Expect(c.Message).To(Equal("resourceAllocation.cpu, diskStorageIOAllocation disk-1000-0 drifted from the spec"))
+	})
+}
+
+func Test_resourceAllocationChange(t *testing.T) {
+	current := &types.ResourceAllocationInfo{
+		Reservation: ptr.To[int64](1000),
+		Limit:       ptr.To[int64](-1),
+		Shares:      &types.SharesInfo{Level: types.SharesLevelNormal, Shares: 2000},
+	}
+
+	testCases := []struct {
+		name    string
+		desired *infrav1.ResourceAllocationSpec
+		want    *types.ResourceAllocationInfo
+	}{
+		{
+			name: "no change when not defined",
+		},
+		{
+			name: "no change when reservation, limit and shares match",
+			desired: &infrav1.ResourceAllocationSpec{
+				Reservation: ptr.To[int64](1000),
+				Limit:       ptr.To[int64](-1),
+				Shares:      &infrav1.ResourceShares{Level: infrav1.SharesLevelNormal},
+			},
+		},
+		{
+			name: "only shares when shares differ",
+			desired: &infrav1.ResourceAllocationSpec{
+				Reservation: ptr.To[int64](1000),
+				Shares:      &infrav1.ResourceShares{Level: infrav1.SharesLevelCustom, Shares: 4000},
+			},
+			want: &types.ResourceAllocationInfo{
+				Shares: &types.SharesInfo{Level: types.SharesLevelCustom, Shares: 4000},
+			},
+		},
+		{
+			name: "only reservation when reservation differs",
+			desired: &infrav1.ResourceAllocationSpec{
+				Reservation: ptr.To[int64](2000),
+				Shares:      &infrav1.ResourceShares{Level: infrav1.SharesLevelNormal},
+			},
+			want: &types.ResourceAllocationInfo{
+				Reservation: ptr.To[int64](2000),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(resourceAllocationChange(current, tc.desired)).To(Equal(tc.want))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+)
+
+// reconcileAdditionalDisksBusSharing reports the bus sharing mode of the SCSI controller
+// of the additional disks of the VM.
+func (vms *VMService) reconcileAdditionalDisksBusSharing(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	if virtualMachineCtx.VSphereVM.Spec.AdditionalDisksController == nil {
+		virtualMachineCtx.VSphereVM.Status.AdditionalDisksBusSharing = ""
+		return nil
+	}
+
+	devices, err := virtualMachineCtx.Obj.Device(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error getting devices of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.AdditionalDisksBusSharing = vcenter.AdditionalDisksBusSharing(devices)
+	return nil
+}
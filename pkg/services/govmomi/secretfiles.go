@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// maxFilesSize is the maximum total size of the contents of the files of a VSphereVM,
+// which keeps the bootstrap data within the size limits of the guestinfo variables.
+const maxFilesSize = 64 * 1024
+
+// errFilesInvalid is returned if the files of a VSphereVM cannot be added to the
+// bootstrap data.
+var errFilesInvalid = errors.New("files invalid")
+
+// secretFile is a file of the spec with its content read from the Secret.
+type secretFile struct {
+	infrav1.SecretFile
+	content []byte
+}
+
+// getSecretFiles reads the contents of the files of the VSphereVM from their Secrets.
+func getSecretFiles(ctx context.Context, vmCtx *capvcontext.VMContext) ([]secretFile, error) {
+	var files []secretFile
+	var size int
+	for _, file := range vmCtx.VSphereVM.Spec.Files {
+		secret := &corev1.Secret{}
+		secretKey := apitypes.NamespacedName{
+			Namespace: vmCtx.VSphereVM.Namespace,
+			Name:      file.Secret.Name,
+		}
+		if err := vmCtx.Client.Get(ctx, secretKey, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(errFilesInvalid, "secret %s of file %s not found", secretKey, file.Path)
+			}
+			return nil, errors.Wrapf(err, "failed to get secret %s of file %s", secretKey, file.Path)
+		}
+		content, ok := secret.Data[file.Secret.Key]
+		if !ok {
+			return nil, errors.Wrapf(errFilesInvalid, "secret %s of file %s has no key %s", secretKey, file.Path, file.Secret.Key)
+		}
+		size += len(content)
+		files = append(files, secretFile{SecretFile: file, content: content})
+	}
+	if size > maxFilesSize {
+		return nil, errors.Wrapf(errFilesInvalid, "the total size %d of the files exceeds the limit of %d bytes", size, maxFilesSize)
+	}
+	return files, nil
+}
+
+// addSecretFiles adds the files to the bootstrap data after the files of the bootstrap
+// provider, so they take precedence for the same path.
+func addSecretFiles(data []byte, format bootstrapv1.Format, files []secretFile) ([]byte, error) {
+	if len(files) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	switch format {
+	case bootstrapv1.CloudConfig:
+		return addCloudConfigSecretFiles(data, files)
+	case bootstrapv1.Ignition:
+		return addIgnitionSecretFiles(data, files)
+	default:
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// addCloudConfigSecretFiles appends the files to the write_files of the cloud-config. The
+// contents are base64 encoded, so binary files are written unchanged.
+func addCloudConfigSecretFiles(data []byte, files []secretFile) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	writeFiles, _ := config["write_files"].([]interface{})
+	for _, file := range files {
+		f := map[string]interface{}{
+			"path":     file.Path,
+			"encoding": "b64",
+			"content":  base64.StdEncoding.EncodeToString(file.content),
+		}
+		if file.Permissions != "" {
+			f["permissions"] = file.Permissions
+		}
+		if file.Owner != "" {
+			f["owner"] = file.Owner
+		}
+		writeFiles = append(writeFiles, f)
+	}
+	config["write_files"] = writeFiles
+
+	return marshalCloudConfig(header, config)
+}
+
+// addIgnitionSecretFiles appends the files to the storage files of the Ignition config.
+func addIgnitionSecretFiles(data []byte, files []secretFile) ([]byte, error) {
+	config, err := unmarshalIgnitionConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, _ := config["storage"].(map[string]interface{})
+	if storage == nil {
+		storage = map[string]interface{}{}
+		config["storage"] = storage
+	}
+	storageFiles, _ := storage["files"].([]interface{})
+	v2 := isIgnitionV2(config)
+	for _, file := range files {
+		mode := int64(0o644)
+		if file.Permissions != "" {
+			if mode, err = strconv.ParseInt(file.Permissions, 8, 32); err != nil {
+				return nil, errors.Wrapf(errFilesInvalid, "invalid permissions %q of file %s", file.Permissions, file.Path)
+			}
+		}
+		f := ignitionFile(v2, file.Path, int(mode), "data:;base64,"+base64.StdEncoding.EncodeToString(file.content), false)
+		if file.Owner != "" {
+			user, group, _ := strings.Cut(file.Owner, ":")
+			f["user"] = map[string]interface{}{"name": user}
+			if group != "" {
+				f["group"] = map[string]interface{}{"name": group}
+			}
+		}
+		storageFiles = append(storageFiles, f)
+	}
+	storage["files"] = storageFiles
+
+	return marshalIgnitionConfig(config)
+}
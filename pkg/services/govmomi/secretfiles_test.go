@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_getSecretFiles(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls",
+			Namespace: "my-namespace",
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte("certificate"),
+			"large":   bytes.Repeat([]byte("a"), maxFilesSize),
+		},
+	}
+	file := func(path, key string) infrav1.SecretFile {
+		return infrav1.SecretFile{Path: path, Secret: infrav1.SecretFileSource{Name: "tls", Key: key}}
+	}
+
+	tests := []struct {
+		name      string
+		files     []infrav1.SecretFile
+		expected  []secretFile
+		wantError bool
+	}{
+		{
+			name: "without files",
+		},
+		{
+			name:     "with files",
+			files:    []infrav1.SecretFile{file("/etc/tls.crt", "tls.crt")},
+			expected: []secretFile{{SecretFile: file("/etc/tls.crt", "tls.crt"), content: []byte("certificate")}},
+		},
+		{
+			name:      "when the secret does not exist",
+			files:     []infrav1.SecretFile{{Path: "/etc/tls.crt", Secret: infrav1.SecretFileSource{Name: "missing", Key: "tls.crt"}}},
+			wantError: true,
+		},
+		{
+			name:      "when the key does not exist",
+			files:     []infrav1.SecretFile{file("/etc/tls.key", "tls.key")},
+			wantError: true,
+		},
+		{
+			name:      "when the files exceed the size limit",
+			files:     []infrav1.SecretFile{file("/etc/tls.crt", "tls.crt"), file("/etc/large", "large")},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			vmCtx := emptyVirtualMachineContext()
+			vmCtx.Client = fake.NewClientBuilder().WithObjects(secret).Build()
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace"},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{Files: tt.files},
+				},
+			}
+
+			files, err := getSecretFiles(context.Background(), &vmCtx.VMContext)
+			if tt.wantError {
+				g.Expect(errors.Is(err, errFilesInvalid)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(files).To(Equal(tt.expected))
+		})
+	}
+}
+
+func Test_addSecretFiles(t *testing.T) {
+	files := []secretFile{
+		{
+			SecretFile: infrav1.SecretFile{Path: "/etc/tls.crt", Permissions: "0600", Owner: "root:ssl"},
+			content:    []byte("certificate"),
+		},
+		{
+			SecretFile: infrav1.SecretFile{Path: "/etc/app.conf"},
+			content:    []byte("key=value"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		format   bootstrapv1.Format
+		files    []secretFile
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "without files",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:   "cloud-config",
+			data:   "#cloud-config\nwrite_files:\n- content: bootstrap\n  path: /etc/app.conf\n",
+			format: bootstrapv1.CloudConfig,
+			files:  files,
+			expected: "#cloud-config\nwrite_files:\n- content: bootstrap\n  path: /etc/app.conf\n" +
+				"- content: Y2VydGlmaWNhdGU=\n  encoding: b64\n  owner: root:ssl\n  path: /etc/tls.crt\n  permissions: \"0600\"\n" +
+				"- content: a2V5PXZhbHVl\n  encoding: b64\n  path: /etc/app.conf\n",
+		},
+		{
+			name:   "ignition v3",
+			data:   `{"ignition":{"version":"3.1.0"}}`,
+			format: bootstrapv1.Ignition,
+			files:  files,
+			expected: `{"ignition":{"version":"3.1.0"},"storage":{"files":[` +
+				`{"contents":{"source":"data:;base64,Y2VydGlmaWNhdGU="},"group":{"name":"ssl"},"mode":384,"overwrite":true,"path":"/etc/tls.crt","user":{"name":"root"}},` +
+				`{"contents":{"source":"data:;base64,a2V5PXZhbHVl"},"mode":420,"overwrite":true,"path":"/etc/app.conf"}]}}`,
+		},
+		{
+			name:   "ignition v2",
+			data:   `{"ignition":{"version":"2.3.0"}}`,
+			format: bootstrapv1.Ignition,
+			files:  files[1:],
+			expected: `{"ignition":{"version":"2.3.0"},"storage":{"files":[` +
+				`{"contents":{"source":"data:;base64,a2V5PXZhbHVl"},"filesystem":"root","mode":420,"path":"/etc/app.conf"}]}}`,
+		},
+		{
+			name:    "unsupported format",
+			data:    "data",
+			format:  "unknown",
+			files:   files,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addSecretFiles([]byte(tt.data), tt.format, tt.files)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
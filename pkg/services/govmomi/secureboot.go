@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileSecureBootFailure reports a VM which runs with Secure Boot but has no guest
+// heartbeat within the Secure Boot failure timeout by the SecureBootLikelyFailed condition,
+// as its guest likely failed to boot, e.g. because the boot loader or kernel of the template
+// is not signed. If the Secure Boot failure policy is disable, the VM is powered off, so
+// reconcileFirmware disables Secure Boot and reconcilePowerState boots it again. It returns
+// false while the VM is powered off.
+func (vms *VMService) reconcileSecureBootFailure(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+	vsphereVM := virtualMachineCtx.VSphereVM
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var secureBoot bool
+	if virtualMachine.Config != nil && virtualMachine.Config.BootOptions != nil {
+		secureBoot = ptr.Deref(virtualMachine.Config.BootOptions.EfiSecureBootEnabled, false)
+	}
+	poweredOn := virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn
+
+	// The condition keeps Secure Boot disabled once the fallback was applied.
+	if isSecureBootDisabledOnFailure(vsphereVM) {
+		if !secureBoot && poweredOn {
+			conditions.MarkFalse(vsphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.SecureBootDisabledReason, clusterv1.ConditionSeverityWarning,
+				"VM likely failed to boot with Secure Boot and runs with Secure Boot disabled")
+		}
+		return true, nil
+	}
+
+	if !secureBoot || !poweredOn || virtualMachine.GuestHeartbeatStatus != types.ManagedEntityStatusGray {
+		conditions.Delete(vsphereVM, infrav1.SecureBootLikelyFailedCondition)
+		return true, nil
+	}
+	if virtualMachine.Runtime.BootTime == nil {
+		return true, nil
+	}
+
+	timeout := infrav1.SecureBootFailureDefaultTimeout
+	if vsphereVM.Spec.SecureBootFailureTimeout != nil {
+		timeout = vsphereVM.Spec.SecureBootFailureTimeout.Duration
+	}
+	if time.Since(*virtualMachine.Runtime.BootTime) < timeout {
+		return true, nil
+	}
+
+	if vsphereVM.Spec.SecureBootFailurePolicy != infrav1.SecureBootFailurePolicyDisable {
+		conditions.MarkFalse(vsphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.NoGuestHeartbeatReason, clusterv1.ConditionSeverityWarning,
+			"VM runs with Secure Boot but has no guest heartbeat %s after it was powered on, verify the boot loader and kernel of template %s are signed or disable secureBoot",
+			timeout, vsphereVM.Spec.Template)
+		return true, nil
+	}
+
+	log.Info("Powering off VM without guest heartbeat to boot it with Secure Boot disabled", "timeout", timeout)
+	task, err := virtualMachineCtx.Obj.PowerOff(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to power off VM %s to disable Secure Boot", vsphereVM.Name)
+	}
+	conditions.MarkFalse(vsphereVM, infrav1.SecureBootLikelyFailedCondition, infrav1.DisablingSecureBootReason, clusterv1.ConditionSeverityWarning,
+		"VM runs with Secure Boot but has no guest heartbeat %s after it was powered on, powering it off to boot it with Secure Boot disabled", timeout)
+	vsphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be powered off to disable Secure Boot")
+	return false, nil
+}
+
+// isSecureBootDisabledOnFailure returns true if Secure Boot is disabled for the VM of the
+// VSphereVM, as it likely failed to boot with Secure Boot.
+func isSecureBootDisabledOnFailure(vsphereVM *infrav1.VSphereVM) bool {
+	reason := conditions.GetReason(vsphereVM, infrav1.SecureBootLikelyFailedCondition)
+	return reason == infrav1.DisablingSecureBootReason || reason == infrav1.SecureBootDisabledReason
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_reconcileSecureBootFailure(t *testing.T) {
+	var (
+		g     *WithT
+		vmCtx *virtualMachineContext
+		vms   *VMService
+	)
+
+	before := func(policy infrav1.SecureBootFailurePolicy) {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					Template:                "ubuntu-2204",
+					SecureBoot:              ptr.To(true),
+					SecureBootFailurePolicy: policy,
+				},
+			},
+		}
+		vms = &VMService{}
+	}
+
+	// setup powers on the VM with Secure Boot the given time ago, with the given guest heartbeat.
+	setup := func(ctx context.Context, c *vim25.Client, bootAge time.Duration, heartbeat types.ManagedEntityStatus) *simulator.VirtualMachine {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+		vmCtx.Obj = vm
+
+		simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+		simVM.Config.BootOptions = &types.VirtualMachineBootOptions{EfiSecureBootEnabled: ptr.To(true)}
+		simVM.Runtime.BootTime = ptr.To(time.Now().Add(-bootAge))
+		simVM.GuestHeartbeatStatus = heartbeat
+		return simVM
+	}
+
+	t.Run("when the guest sends a heartbeat", func(t *testing.T) {
+		g = NewWithT(t)
+		before("")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			setup(ctx, c, time.Hour, types.ManagedEntityStatusGreen)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the guest has no heartbeat within the timeout", func(t *testing.T) {
+		g = NewWithT(t)
+		before("")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			setup(ctx, c, time.Minute, types.ManagedEntityStatusGray)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the guest has no heartbeat after the timeout", func(t *testing.T) {
+		g = NewWithT(t)
+		before("")
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			simVM := setup(ctx, c, time.Hour, types.ManagedEntityStatusGray)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(Equal(infrav1.NoGuestHeartbeatReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(ContainSubstring("ubuntu-2204"))
+
+			// The condition is removed once the guest sends a heartbeat.
+			simVM.GuestHeartbeatStatus = types.ManagedEntityStatusGreen
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the guest has no heartbeat after the timeout with the disable policy", func(t *testing.T) {
+		g = NewWithT(t)
+		before(infrav1.SecureBootFailurePolicyDisable)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			simVM := setup(ctx, c, time.Hour, types.ManagedEntityStatusGray)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(Equal(infrav1.DisablingSecureBootReason))
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: "Task", Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(simVM.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+
+			// Secure Boot is disabled although the spec enables it.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileFirmware(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("secureBoot true -> false"))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+			g.Expect(simVM.Config.BootOptions.EfiSecureBootEnabled).To(Equal(ptr.To(false)))
+
+			task, err = vmCtx.Obj.PowerOn(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileSecureBootFailure(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.SecureBootLikelyFailedCondition)).To(Equal(infrav1.SecureBootDisabledReason))
+			return nil
+		})
+	})
+}
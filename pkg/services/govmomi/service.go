@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -33,6 +34,8 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 	corev1 "k8s.io/api/core/v1"
 	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -47,6 +50,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/ipam"
 	govmominet "sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/net"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/pci"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
@@ -68,6 +72,10 @@ func (vms *VMService) ReconcileVM(ctx context.Context, vmCtx *capvcontext.VMCont
 	// If there is an in-flight task associated with this VM then do not
 	// reconcile the VM until the task is completed.
 	if inFlight, err := reconcileInFlightTask(ctx, vmCtx); err != nil || inFlight {
+		if inFlight {
+			// A power-on task does not complete while the VM waits for a question to be answered.
+			err = vms.reconcileInFlightQuestion(ctx, vmCtx)
+		}
 		return vm, err
 	}
 
@@ -79,6 +87,10 @@ func (vms *VMService) ReconcileVM(ctx context.Context, vmCtx *capvcontext.VMCont
 
 	// Before going further, we need the VM's managed object reference.
 	vmRef, err := findVM(ctx, vmCtx)
+	if err != nil && wasNotFoundByBIOSUUID(err) && isRedeploying(vmCtx.VSphereVM) {
+		// The VM is redeployed, so it is cloned again or was cloned from the new template.
+		vmRef, err = findRedeployedVM(ctx, vmCtx)
+	}
 	if err != nil {
 		if !isNotFound(err) {
 			return vm, err
@@ -100,15 +112,74 @@ func (vms *VMService) ReconcileVM(ctx context.Context, vmCtx *capvcontext.VMCont
 			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloningReason, clusterv1.ConditionSeverityInfo, "")
 		}
 
+		// Defer the clone while VSphereVMs of higher priority wait to be cloned.
+		if wait, err := waitForProvisioningPriority(ctx, vmCtx); err != nil || wait {
+			if wait {
+				conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.WaitingForProvisioningPriorityReason, clusterv1.ConditionSeverityInfo, "")
+			}
+			return vm, err
+		}
+
 		// Get the bootstrap data.
 		bootstrapData, format, err := vms.getBootstrapData(ctx, vmCtx)
+		if errors.Is(err, errFilesInvalid) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.FilesInvalidReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, errRegistryMirrorsInvalid) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.RegistryMirrorsInvalidReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
 		if err != nil {
 			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 			return vm, err
 		}
 
+		// Import the template from the OVA, if required.
+		if err := importTemplate(ctx, vmCtx); err != nil {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.OVAImportFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+
+		if isRedeploying(vmCtx.VSphereVM) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMRedeployedCondition, infrav1.RedeployCloningReason, clusterv1.ConditionSeverityInfo,
+				"redeploying VM from template %s", vmCtx.VSphereVM.Spec.Template)
+		}
+
 		// Create the VM.
 		err = createVM(ctx, vmCtx, bootstrapData, format)
+		if errors.Is(err, vcenter.ErrSriovUnavailable) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.SriovUnavailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrEVCModeNotSupported) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.EVCModeNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrDatastoresFull) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.DatastoreFullReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrInsufficientHostMemory) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.InsufficientHostMemoryReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrHostsUnavailable) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostUnavailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrHugePagesNotSupported) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HugePagesNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrNUMANodeNotAvailable) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.NUMANodeNotAvailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
+		if errors.Is(err, vcenter.ErrHostLocalSwapNotAvailable) {
+			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostLocalSwapNotAvailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return vm, err
+		}
 		if err != nil {
 			conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 			return vm, err
@@ -129,20 +200,173 @@ func (vms *VMService) ReconcileVM(ctx context.Context, vmCtx *capvcontext.VMCont
 	}
 	vm.VMRef = vmRef.String()
 
+	if err := fetchVirtualMachineProperties(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileCloneConflict(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileRedeploy(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	vms.reconcileUUID(ctx, virtualMachineCtx)
 
+	if ok, err := vms.reconcileMarkAsTemplate(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if err := refreshVirtualMachineProperties(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
 	if ok, err := vms.reconcileHardwareVersion(ctx, virtualMachineCtx); err != nil || !ok {
 		return vm, err
 	}
 
+	if ok, err := vms.reconcileEVCMode(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if err := vms.observeChangeVersion(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileDeferredReconfigure(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileBootOptions(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNetworkBoot(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNetworkDeviceSettings(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNestedHardwareVirtualization(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileCPUMMUVirtualization(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileFirmware(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcilePerformanceOptions(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileLoggingOptions(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileMemoryBacking(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNUMANodeAffinity(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileSwapPlacement(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileIsolation(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileDiskEnableUUID(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileOwnerExtraConfig(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileResourceAllocation(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileDiskStorageIOAllocation(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	vms.reconcileResourcesConverged(virtualMachineCtx)
+
+	if ok, err := vms.reconcileToolsUpgradePolicy(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileToolsSyncTime(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileFolder(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileVMName(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileDatastoreDrain(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileHostPinning(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	// The drift detected by the previous steps is corrected by a single reconfigure task.
+	if ok, err := vms.reconcileConfigChange(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileCDROMs(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileSharedDisks(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNoCloudSeed(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileGuestInfoBootstrapData(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	if err := vms.reconcilePCIDevices(ctx, virtualMachineCtx); err != nil {
 		return vm, err
 	}
 
+	if err := refreshVirtualMachineProperties(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
+	if err := vms.reconcileAdditionalDisksBusSharing(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
 	if err := vms.reconcileNetworkStatus(ctx, virtualMachineCtx); err != nil {
 		return vm, err
 	}
 
+	vms.reconcileGuestNetworkStatus(virtualMachineCtx)
+
 	if ok, err := vms.reconcileIPAddresses(ctx, virtualMachineCtx); err != nil || !ok {
 		return vm, err
 	}
@@ -151,31 +375,88 @@ func (vms *VMService) ReconcileVM(ctx context.Context, vmCtx *capvcontext.VMCont
 		return vm, err
 	}
 
+	if ok, err := vms.reconcileGuestNetwork(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	if err := vms.reconcileStoragePolicy(ctx, virtualMachineCtx); err != nil {
 		return vm, err
 	}
 
+	if err := refreshVirtualMachineProperties(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
+	vms.reconcileStorageCompliance(ctx, virtualMachineCtx)
+
+	vms.reconcileGuestDiskUsage(virtualMachineCtx)
+
 	if ok, err := vms.reconcileVMGroupInfo(ctx, virtualMachineCtx); err != nil || !ok {
 		return vm, err
 	}
 
+	if ok, err := vms.reconcileStorageAffinity(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileDRSAutomationLevel(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	if err := vms.reconcileClusterModuleMembership(ctx, virtualMachineCtx); err != nil {
 		return vm, err
 	}
 
+	if ok, err := vms.reconcileQuestion(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if err := refreshVirtualMachineProperties(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
 	if ok, err := vms.reconcilePowerState(ctx, virtualMachineCtx); err != nil || !ok {
 		return vm, err
 	}
 
+	if ok, err := vms.reconcileSecureBootFailure(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	if err := vms.reconcileHostInfo(ctx, virtualMachineCtx); err != nil {
 		return vm, err
 	}
 
+	vms.reconcileHostMaintenance(ctx, virtualMachineCtx)
+
+	vms.reconcileMigrationHistory(ctx, virtualMachineCtx)
+
+	if err := vms.reconcileSerialPortStatus(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
+	if err := vms.reconcileDiskStatus(ctx, virtualMachineCtx); err != nil {
+		return vm, err
+	}
+
 	if err := vms.reconcileTags(ctx, virtualMachineCtx); err != nil {
 		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.TagsAttachmentFailedReason, clusterv1.ConditionSeverityError, err.Error())
 		return vm, err
 	}
 
+	if err := vms.reconcileCustomAttributes(ctx, virtualMachineCtx); err != nil {
+		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CustomAttributesFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileReadinessProbe(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
+	if ok, err := vms.reconcileNoCloudSeedDetach(ctx, virtualMachineCtx); err != nil || !ok {
+		return vm, err
+	}
+
 	vm.State = infrav1.VirtualMachineStateReady
 	return vm, nil
 }
@@ -207,6 +488,7 @@ func (vms *VMService) DestroyVM(ctx context.Context, vmCtx *capvcontext.VMContex
 		// If the VM's MoRef could not be found then the VM no longer exists. This
 		// is the desired state.
 		if isNotFound(err) || isFolderNotFound(err) {
+			log.Info("VM was already deleted")
 			vm.State = infrav1.VirtualMachineStateNotFound
 			return reconcile.Result{}, vm, nil
 		}
@@ -228,6 +510,12 @@ func (vms *VMService) DestroyVM(ctx context.Context, vmCtx *capvcontext.VMContex
 	// Shut down the VM
 	powerState, err := vms.getPowerState(ctx, virtualMachineCtx)
 	if err != nil {
+		// The VM may have been deleted in vCenter since it was found.
+		if isManagedObjectNotFound(err) {
+			log.Info("VM was already deleted")
+			vm.State = infrav1.VirtualMachineStateNotFound
+			return reconcile.Result{}, vm, nil
+		}
 		return reconcile.Result{}, vm, err
 	}
 
@@ -276,11 +564,55 @@ func (vms *VMService) DestroyVM(ctx context.Context, vmCtx *capvcontext.VMContex
 		vmCtx.VSphereVM.Status.ModuleUUID = nil
 	}
 
+	// Remove the DRS override of the VM before destroying it.
+	if vmCtx.VSphereVM.Spec.DRSAutomationLevel != "" {
+		override, err := cluster.GetDRSVMOverride(ctx, virtualMachineCtx.Obj)
+		if err != nil {
+			log.Error(err, "Failed to get DRS override of VM (best-effort)")
+		} else if override.Info != nil {
+			log.Info("Removing DRS override of VM")
+			task, err := override.Remove(ctx)
+			if err != nil {
+				return reconcile.Result{}, vm, errors.Wrapf(err, "failed to remove DRS override of VM %s", vmCtx.VSphereVM.Name)
+			}
+			vmCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+			log.Info("Wait for DRS override of VM to be removed")
+			return reconcile.Result{}, vm, nil
+		}
+	}
+
+	// Shared disks are detached before destroying the VM, so they are not
+	// deleted together with the VM while other VMs still use them.
+	if len(vmCtx.VSphereVM.Spec.SharedDisks) > 0 {
+		task, err := detachSharedDisks(ctx, virtualMachineCtx)
+		if err != nil {
+			return reconcile.Result{}, vm, errors.Wrapf(err, "failed to detach shared disks from VM %s", vmCtx.VSphereVM.Name)
+		}
+		if task != nil {
+			vmCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+			log.Info("Wait for shared disks to be detached from VM")
+			return reconcile.Result{}, vm, nil
+		}
+	}
+
+	// The seed ISO image of the NoCloud datasource is not a file of the VM, so
+	// it is deleted separately.
+	if vmCtx.VSphereVM.Spec.CloudInitDatasource == infrav1.CloudInitDatasourceNoCloud {
+		if err := deleteNoCloudSeed(ctx, virtualMachineCtx); err != nil {
+			log.Error(err, "Failed to delete NoCloud seed of VM (best-effort)")
+		}
+	}
+
 	// At this point the VM is not powered on and can be destroyed. Store the
 	// destroy task's reference and return a requeue error.
 	log.Info("Destroying vm")
 	task, err := virtualMachineCtx.Obj.Destroy(ctx)
 	if err != nil {
+		if isManagedObjectNotFound(err) {
+			log.Info("VM was already deleted")
+			vm.State = infrav1.VirtualMachineStateNotFound
+			return reconcile.Result{}, vm, nil
+		}
 		return reconcile.Result{}, vm, err
 	}
 	vmCtx.VSphereVM.Status.TaskRef = task.Reference().Value
@@ -322,7 +654,13 @@ func (vms *VMService) reconcileMetadata(ctx context.Context, virtualMachineCtx *
 		return false, err
 	}
 
-	newMetadata, err := util.GetMachineMetadata(virtualMachineCtx.VSphereVM.Name, *virtualMachineCtx.VSphereVM, virtualMachineCtx.IPAMState, virtualMachineCtx.State.Network...)
+	hostname, err := guestHostname(ctx, virtualMachineCtx)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostnameFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	newMetadata, err := util.GetMachineMetadata(hostname, *virtualMachineCtx.VSphereVM, virtualMachineCtx.IPAMState, virtualMachineCtx.State.Network...)
 	if err != nil {
 		return false, err
 	}
@@ -338,6 +676,12 @@ func (vms *VMService) reconcileMetadata(ctx context.Context, virtualMachineCtx *
 		return false, errors.Wrapf(err, "unable to set metadata on vm %s", ctx)
 	}
 
+	// The guest of a provisioned VM only applies the updated metadata once it is rebooted.
+	if virtualMachineCtx.VSphereVM.Status.Ready && guestNetworkReconfigurationEnabled(virtualMachineCtx.VSphereVM) {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.GuestNetworkReconfiguredCondition, infrav1.GuestRebootPendingReason, clusterv1.ConditionSeverityInfo,
+			"network metadata updated, rebooting guest to apply it")
+	}
+
 	virtualMachineCtx.VSphereVM.Status.TaskRef = taskRef
 	log.Info("Wait for VM metadata to be updated")
 	return false, nil
@@ -352,6 +696,16 @@ func (vms *VMService) reconcilePowerState(ctx context.Context, virtualMachineCtx
 	}
 	switch powerState {
 	case infrav1.VirtualMachinePowerStatePoweredOff:
+		if util.IsKeptPoweredOff(virtualMachineCtx.VSphereVM) {
+			log.V(4).Info("VM is kept powered off")
+			// Only set the GuestPowerOffCondition to true when the guest shutdown has been initiated.
+			if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.GuestSoftPowerOffSucceededCondition) {
+				conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.GuestSoftPowerOffSucceededCondition)
+			}
+			return true, nil
+		}
+		// The guest shutdown of a previous power off must not be mistaken for the next one.
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.GuestSoftPowerOffSucceededCondition)
 		log.Info("Powering on VM")
 		task, err := virtualMachineCtx.Obj.PowerOn(ctx)
 		if err != nil {
@@ -373,6 +727,9 @@ func (vms *VMService) reconcilePowerState(ctx context.Context, virtualMachineCtx
 		log.Info("Wait for VM to be powered on")
 		return false, nil
 	case infrav1.VirtualMachinePowerStatePoweredOn:
+		if virtualMachineCtx.VSphereVM.Spec.DesiredPowerState == infrav1.VirtualMachinePowerStatePoweredOff {
+			return vms.reconcileDesiredPowerOff(ctx, virtualMachineCtx)
+		}
 		log.Info("VM is powered on")
 		return true, nil
 	default:
@@ -468,58 +825,803 @@ func (vms *VMService) reconcileStoragePolicy(ctx context.Context, virtualMachine
 			return errors.Wrapf(err, "unable to set storagePolicy on vm %s", ctx)
 		}
 		virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+		virtualMachineCtx.PropertiesOutdated = true
 	}
 	return nil
 }
 
-func (vms *VMService) reconcileUUID(ctx context.Context, virtualMachineCtx *virtualMachineContext) {
-	virtualMachineCtx.State.BiosUUID = virtualMachineCtx.Obj.UUID(ctx)
+// virtualMachineProperties are the properties of the VM read by the steps of ReconcileVM.
+var virtualMachineProperties = []string{
+	"config.bootOptions",
+	"config.changeVersion",
+	"config.cpuAllocation",
+	"config.cpuHotAddEnabled",
+	"config.extraConfig",
+	"config.files",
+	"config.firmware",
+	"config.flags",
+	"config.hardware.device",
+	"config.hardware.memoryMB",
+	"config.hardware.numCPU",
+	"config.hardware.numCoresPerSocket",
+	"config.instanceUuid",
+	"config.memoryAllocation",
+	"config.memoryHotAddEnabled",
+	"config.memoryReservationLockedToMax",
+	"config.nestedHVEnabled",
+	"config.swapPlacement",
+	"config.template",
+	"config.tools",
+	"config.vPMCEnabled",
+	"config.version",
+	"customValue",
+	"datastore",
+	"guest.disk",
+	"guest.ipStack",
+	"guest.net",
+	"guest.toolsRunningStatus",
+	"guestHeartbeatStatus",
+	"name",
+	"parent",
+	"resourcePool",
+	"runtime.bootTime",
+	"runtime.featureMask",
+	"runtime.host",
+	"runtime.powerState",
+	"runtime.question",
 }
 
-func (vms *VMService) reconcileHardwareVersion(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
-	log := ctrl.LoggerFrom(ctx)
+// fetchVirtualMachineProperties retrieves the properties of the VM read by the steps of
+// ReconcileVM into the context, so the VM is read once per reconcile.
+func fetchVirtualMachineProperties(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	virtualMachineCtx.Properties = mo.VirtualMachine{}
+	virtualMachineCtx.PropertiesOutdated = false
+	if err := virtualMachineCtx.Obj.Properties(ctx, virtualMachineCtx.Obj.Reference(), virtualMachineProperties, &virtualMachineCtx.Properties); err != nil {
+		return errors.Wrapf(err, "error getting properties of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	return nil
+}
 
-	if virtualMachineCtx.VSphereVM.Spec.HardwareVersion != "" {
-		var virtualMachine mo.VirtualMachine
-		if err := virtualMachineCtx.Obj.Properties(ctx, virtualMachineCtx.Obj.Reference(), []string{"config.version"}, &virtualMachine); err != nil {
-			return false, errors.Wrapf(err, "error getting guestInfo version information from VM %s", virtualMachineCtx.VSphereVM.Name)
-		}
-		toUpgrade, err := util.LessThan(virtualMachine.Config.Version, virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
-		if err != nil {
-			return false, errors.Wrapf(err, "failed to parse hardware version")
-		}
-		if toUpgrade {
-			log.Info("Upgrading hardware version", "fromVersion", virtualMachine.Config.Version, "toVersion", virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
-			task, err := virtualMachineCtx.Obj.UpgradeVM(ctx, virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
-			if err != nil {
-				return false, errors.Wrapf(err, "error trigging upgrade op for machine %s", ctx)
-			}
-			virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
-			return false, nil
-		}
+// refreshVirtualMachineProperties retrieves the properties of the VM again if the previous
+// step issued a task or changed the VM without ending the reconcile.
+func refreshVirtualMachineProperties(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	if !virtualMachineCtx.PropertiesOutdated {
+		return nil
 	}
-	return true, nil
+	return fetchVirtualMachineProperties(ctx, virtualMachineCtx)
 }
 
-func (vms *VMService) reconcilePCIDevices(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+// reconcileCloneConflict handles a VM which has the name of the VSphereVM but
+// was not provisioned for it, e.g. the remains of a clone task which failed
+// partway. Depending on the CloneConflictPolicy the VM is either adopted,
+// reported as an error, left alone while the VM is cloned with a suffixed name
+// or, if it was created by an earlier clone attempt for this VSphereVM, deleted
+// so the clone can be retried.
+func (vms *VMService) reconcileCloneConflict(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	if expectedPciDevices := virtualMachineCtx.VSphereVM.Spec.VirtualMachineCloneSpec.PciDevices; len(expectedPciDevices) != 0 {
-		specsToBeAdded, err := pci.CalculateDevicesToBeAdded(ctx, virtualMachineCtx.Obj, expectedPciDevices)
-		if err != nil {
-			return err
-		}
+	// Once the BIOS UUID is known the VM is looked up by it and there is
+	// nothing left to check.
+	if virtualMachineCtx.VSphereVM.Spec.BiosUUID != "" {
+		return true, nil
+	}
 
-		if len(specsToBeAdded) == 0 {
-			if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.PCIDevicesDetachedCondition) {
-				conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.PCIDevicesDetachedCondition)
+	virtualMachine := &virtualMachineCtx.Properties
+
+	uid := string(virtualMachineCtx.VSphereVM.UID)
+	var instanceUUID, ownerUID string
+	if virtualMachine.Config != nil {
+		instanceUUID = virtualMachine.Config.InstanceUuid
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optVal := ec.GetOptionValue(); optVal != nil && optVal.Key == extra.OwnerUIDKey {
+				if v, ok := optVal.Value.(string); ok {
+					ownerUID = v
+				}
 			}
-			log.V(5).Info("No new PCI devices to be added")
-			return nil
 		}
+	}
 
-		powerState, err := virtualMachineCtx.Obj.PowerState(ctx)
-		if err != nil {
+	// The VM was cloned for this VSphereVM.
+	if instanceUUID == uid {
+		return true, nil
+	}
+
+	switch virtualMachineCtx.VSphereVM.Spec.CloneConflictPolicy {
+	case infrav1.CloneConflictPolicyDelete:
+	case infrav1.CloneConflictPolicyFail:
+		err := errors.Errorf("VM %s has the name of VSphereVM %s but was not created for it", virtualMachineCtx.Ref.Value, virtualMachineCtx.VSphereVM.Name)
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	case infrav1.CloneConflictPolicySuffix:
+		return false, suffixVMName(ctx, virtualMachineCtx)
+	default:
+		// VMs created for another VSphereVM are not adopted, as both VSphereVMs
+		// would manage the same VM.
+		if ownerUID != "" && ownerUID != uid {
+			err := errors.Errorf("VM %s has the name of VSphereVM %s but was created for another VSphereVM", virtualMachineCtx.Ref.Value, virtualMachineCtx.VSphereVM.Name)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+		log.Info("Adopting existing VM with conflicting name", "instanceUUID", instanceUUID, "ownerUID", ownerUID)
+		return true, nil
+	}
+
+	// Only clean up VMs which were created by an earlier clone attempt for
+	// this VSphereVM.
+	if ownerUID != uid {
+		err := errors.Errorf("VM %s has the name of VSphereVM %s but was not created for it", virtualMachineCtx.Ref.Value, virtualMachineCtx.VSphereVM.Name)
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityInfo, "Deleting partially provisioned VM")
+
+	if virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		log.Info("Powering off partially provisioned VM")
+		task, err := virtualMachineCtx.Obj.PowerOff(ctx)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to power off partially provisioned vm %s", ctx)
+		}
+		virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+		return false, nil
+	}
+
+	log.Info("Deleting partially provisioned VM")
+	task, err := virtualMachineCtx.Obj.Destroy(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to delete partially provisioned vm %s", ctx)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for partially provisioned VM to be deleted")
+	return false, nil
+}
+
+// suffixVMName sets the name of the VM of the VSphereVM to its name followed by a random
+// suffix, so the VM is cloned with that name instead of the name of the existing VM.
+func suffixVMName(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	name := fmt.Sprintf("%s-%s", virtualMachineCtx.VSphereVM.Name, rand.String(infrav1.CloneConflictSuffixLength-1))
+	if len(name) > infrav1.MaxVirtualMachineNameLength {
+		err := errors.Errorf("VM %s has the name of VSphereVM %s and the name with a suffix exceeds %d characters", virtualMachineCtx.Ref.Value, virtualMachineCtx.VSphereVM.Name, infrav1.MaxVirtualMachineNameLength)
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return err
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Cloning VM with suffixed name, as another VM has the name of the VSphereVM", "conflictingVMRef", virtualMachineCtx.Ref.Value, "vmName", name)
+	conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CloneConflictReason, clusterv1.ConditionSeverityInfo,
+		"VM %s has the name of the VSphereVM, cloning VM as %s", virtualMachineCtx.Ref.Value, name)
+	virtualMachineCtx.VSphereVM.Status.VMName = name
+	return nil
+}
+
+func (vms *VMService) reconcileUUID(ctx context.Context, virtualMachineCtx *virtualMachineContext) {
+	virtualMachineCtx.State.BiosUUID = virtualMachineCtx.Obj.UUID(ctx)
+}
+
+func (vms *VMService) reconcileHardwareVersion(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if virtualMachineCtx.VSphereVM.Spec.HardwareVersion != "" {
+		virtualMachine := &virtualMachineCtx.Properties
+		toUpgrade, err := util.LessThan(virtualMachine.Config.Version, virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse hardware version")
+		}
+		if toUpgrade {
+			log.Info("Upgrading hardware version", "fromVersion", virtualMachine.Config.Version, "toVersion", virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
+			task, err := virtualMachineCtx.Obj.UpgradeVM(ctx, virtualMachineCtx.VSphereVM.Spec.HardwareVersion)
+			if err != nil {
+				return false, errors.Wrapf(err, "error trigging upgrade op for machine %s", ctx)
+			}
+			virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reconcileBootOptions ensures the boot options of a powered off VM match the
+// ones defined in the VSphereVM spec. Fields which are not set in the spec are
+// left untouched.
+func (vms *VMService) reconcileBootOptions(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	bootOptions := virtualMachineCtx.VSphereVM.Spec.BootOptions
+	if bootOptions == nil {
+		log.V(5).Info("Boot options not defined. skipping reconcile boot options")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping reconcile boot options")
+		return true, nil
+	}
+
+	var current types.VirtualMachineBootOptions
+	if virtualMachine.Config != nil && virtualMachine.Config.BootOptions != nil {
+		current = *virtualMachine.Config.BootOptions
+	}
+
+	var (
+		desired types.VirtualMachineBootOptions
+		changes []string
+	)
+	if bootOptions.BootDelay != nil && *bootOptions.BootDelay != current.BootDelay {
+		desired.BootDelay = *bootOptions.BootDelay
+		changes = append(changes, fmt.Sprintf("bootDelay %d -> %d", current.BootDelay, *bootOptions.BootDelay))
+	}
+	if bootOptions.BootRetryEnabled != nil && *bootOptions.BootRetryEnabled != ptr.Deref(current.BootRetryEnabled, false) {
+		desired.BootRetryEnabled = bootOptions.BootRetryEnabled
+		changes = append(changes, fmt.Sprintf("bootRetryEnabled %t -> %t", ptr.Deref(current.BootRetryEnabled, false), *bootOptions.BootRetryEnabled))
+	}
+	if bootOptions.BootRetryDelay != nil && *bootOptions.BootRetryDelay != current.BootRetryDelay {
+		desired.BootRetryDelay = *bootOptions.BootRetryDelay
+		changes = append(changes, fmt.Sprintf("bootRetryDelay %d -> %d", current.BootRetryDelay, *bootOptions.BootRetryDelay))
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM boot options", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		BootOptions: &desired,
+	}, changes...)
+	return true, nil
+}
+
+// reconcileNestedHardwareVirtualization ensures nested hardware virtualization of a powered
+// off VM is enabled or disabled as defined in the spec. It is only enabled if the host of the
+// VM supports it.
+func (vms *VMService) reconcileNestedHardwareVirtualization(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	nestedHV := virtualMachineCtx.VSphereVM.Spec.NestedHardwareVirtualization
+	if nestedHV == nil {
+		log.V(5).Info("Nested hardware virtualization not defined. skipping reconcile nested hardware virtualization")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var current bool
+	if virtualMachine.Config != nil {
+		current = ptr.Deref(virtualMachine.Config.NestedHVEnabled, false)
+	}
+	if *nestedHV == current {
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping reconcile nested hardware virtualization")
+		return true, nil
+	}
+
+	if *nestedHV && virtualMachine.Runtime.Host != nil {
+		var host mo.HostSystem
+		if err := virtualMachineCtx.Obj.Properties(ctx, *virtualMachine.Runtime.Host, []string{"name", "capability.nestedHVSupported"}, &host); err != nil {
+			return false, errors.Wrapf(err, "error getting capabilities of host of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		if host.Capability == nil || !ptr.Deref(host.Capability.NestedHVSupported, false) {
+			err := errors.Errorf("host %s of VM %s does not support nested hardware virtualization", host.Name, virtualMachineCtx.VSphereVM.Name)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.NestedHardwareVirtualizationNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+
+	log.Info("Updating VM nested hardware virtualization", "enabled", *nestedHV)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		NestedHVEnabled: nestedHV,
+	}, fmt.Sprintf("nestedHardwareVirtualization %t -> %t", current, *nestedHV))
+	return true, nil
+}
+
+// reconcileCPUMMUVirtualization ensures the CPU and MMU of a powered off VM are virtualized in
+// the mode defined in the spec. The mode is only changed if the host of the VM supports it.
+func (vms *VMService) reconcileCPUMMUVirtualization(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	mode := virtualMachineCtx.VSphereVM.Spec.CPUMMUVirtualization
+	if mode == "" {
+		log.V(5).Info("CPU/MMU virtualization not defined. skipping reconcile CPU/MMU virtualization")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	// Unset flags default to the automatic mode.
+	currentExecUsage, currentMmuUsage := vcenter.CPUMMUVirtualizationFlags(infrav1.CPUMMUVirtualizationModeAutomatic)
+	if virtualMachine.Config != nil {
+		if virtualMachine.Config.Flags.VirtualExecUsage != "" {
+			currentExecUsage = virtualMachine.Config.Flags.VirtualExecUsage
+		}
+		if virtualMachine.Config.Flags.VirtualMmuUsage != "" {
+			currentMmuUsage = virtualMachine.Config.Flags.VirtualMmuUsage
+		}
+	}
+	execUsage, mmuUsage := vcenter.CPUMMUVirtualizationFlags(mode)
+	if execUsage == currentExecUsage && mmuUsage == currentMmuUsage {
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping reconcile CPU/MMU virtualization")
+		return true, nil
+	}
+
+	if virtualMachine.Runtime.Host != nil {
+		var host mo.HostSystem
+		if err := virtualMachineCtx.Obj.Properties(ctx, *virtualMachine.Runtime.Host, vcenter.CPUMMUVirtualizationHostProperties, &host); err != nil {
+			return false, errors.Wrapf(err, "error getting capabilities of host of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		if err := vcenter.CheckCPUMMUVirtualizationSupported(host, mode); err != nil {
+			err = errors.Wrapf(err, "unable to virtualize CPU/MMU of VM %s in mode %s", virtualMachineCtx.VSphereVM.Name, mode)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.CPUMMUVirtualizationNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+
+	log.Info("Updating VM CPU/MMU virtualization", "mode", mode)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		Flags: &types.VirtualMachineFlagInfo{
+			VirtualExecUsage: execUsage,
+			VirtualMmuUsage:  mmuUsage,
+		},
+	}, fmt.Sprintf("virtualExecUsage %s -> %s", currentExecUsage, execUsage), fmt.Sprintf("virtualMmuUsage %s -> %s", currentMmuUsage, mmuUsage))
+	return true, nil
+}
+
+// reconcileSwapPlacement ensures the swap file placement of a powered off VM matches the one
+// defined in the spec. Host local swap is only applied if the host of the VM has a local swap
+// datastore.
+func (vms *VMService) reconcileSwapPlacement(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	swapPlacement := virtualMachineCtx.VSphereVM.Spec.SwapPlacement
+	if swapPlacement == "" {
+		log.V(5).Info("Swap placement not defined. skipping reconcile swap placement")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	// An unset swap placement is inherited.
+	current := string(infrav1.SwapPlacementInherit)
+	if virtualMachine.Config != nil && virtualMachine.Config.SwapPlacement != "" {
+		current = virtualMachine.Config.SwapPlacement
+	}
+	if current == string(swapPlacement) {
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(5).Info("VM is not powered off. skipping reconcile swap placement")
+		return true, nil
+	}
+
+	if swapPlacement == infrav1.SwapPlacementHostLocal && virtualMachine.Runtime.Host != nil {
+		var host mo.HostSystem
+		if err := virtualMachineCtx.Obj.Properties(ctx, *virtualMachine.Runtime.Host, vcenter.HostLocalSwapProperties, &host); err != nil {
+			return false, errors.Wrapf(err, "error getting local swap datastore of host of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		if !vcenter.HasHostLocalSwap(host) {
+			err := errors.Errorf("unable to store swap file of VM %s on its host, as host %s has no local swap datastore", virtualMachineCtx.VSphereVM.Name, host.Name)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostLocalSwapNotAvailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+
+	log.Info("Updating VM swap placement", "swapPlacement", swapPlacement)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		SwapPlacement: string(swapPlacement),
+	}, fmt.Sprintf("swapPlacement %s -> %s", current, swapPlacement))
+	return true, nil
+}
+
+// reconcilePerformanceOptions ensures the performance options of the VM match the
+// ones defined in the spec. The virtual CPU performance counters are only updated
+// while the VM is powered off.
+func (vms *VMService) reconcilePerformanceOptions(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	perf := virtualMachineCtx.VSphereVM.Spec.PerformanceOptions
+	if perf == nil {
+		log.V(5).Info("Performance options not defined. skipping reconcile performance options")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	var currentVPMCEnabled bool
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+		currentVPMCEnabled = ptr.Deref(virtualMachine.Config.VPMCEnabled, false)
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	for _, k := range sortedKeys(perf.ExtraConfig) {
+		if v := perf.ExtraConfig[k]; current[k] != v {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if perf.VirtualCPUPerformanceCountersEnabled != nil && *perf.VirtualCPUPerformanceCountersEnabled != currentVPMCEnabled {
+		if virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff {
+			desired.VPMCEnabled = perf.VirtualCPUPerformanceCountersEnabled
+			changes = append(changes, fmt.Sprintf("virtualCPUPerformanceCountersEnabled %t -> %t", currentVPMCEnabled, *perf.VirtualCPUPerformanceCountersEnabled))
+		} else {
+			log.V(5).Info("VM is not powered off. skipping reconcile virtual CPU performance counters")
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM performance options", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileLoggingOptions ensures the logging options of the VM match the ones
+// defined in the spec.
+func (vms *VMService) reconcileLoggingOptions(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	logging := virtualMachineCtx.VSphereVM.Spec.LoggingOptions
+	if logging == nil {
+		log.V(5).Info("Logging options not defined. skipping reconcile logging options")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.LoggingOptionsExtraConfig(logging)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; !strings.EqualFold(current[k], v) {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM logging options", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileIsolation ensures the isolation settings of the VM match the ones defined
+// in the spec, or the hardened defaults if enabled.
+func (vms *VMService) reconcileIsolation(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	isolation := vcenter.EffectiveIsolation(virtualMachineCtx.VSphereVM.Spec.Isolation, virtualMachineCtx.HardenVMIsolation)
+	if isolation == nil {
+		log.V(5).Info("Isolation not defined. skipping reconcile isolation")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.IsolationExtraConfig(isolation)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; !strings.EqualFold(current[k], v) {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM isolation settings", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileDiskEnableUUID ensures disk.EnableUUID of the VM matches the spec. The change
+// takes effect when the VM is next powered on.
+func (vms *VMService) reconcileDiskEnableUUID(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	enableUUID := virtualMachineCtx.VSphereVM.Spec.DiskEnableUUID
+	if enableUUID == nil {
+		log.V(5).Info("Disk UUID setting not defined. skipping reconcile disk UUID setting")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.DiskEnableUUIDExtraConfig(*enableUUID)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; !strings.EqualFold(current[k], v) {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM disk UUID setting", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileOwnerExtraConfig ensures the extra config identifying the cluster, Machine
+// and namespace of the VM matches its VSphereVM, e.g. for VMs cloned before it was set.
+func (vms *VMService) reconcileOwnerExtraConfig(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.OwnerExtraConfig(virtualMachineCtx.VSphereVM, virtualMachineCtx.MachineName)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; current[k] != v {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	log.Info("Updating VM owner extra config", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileMemoryBacking ensures the memory of the VM is backed by the huge pages defined in
+// the spec, which requires the host of the VM to back the memory of VMs by huge pages. The
+// backing takes effect when the VM is powered on again.
+func (vms *VMService) reconcileMemoryBacking(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	backing := virtualMachineCtx.VSphereVM.Spec.MemoryBacking
+	if backing == nil {
+		log.V(5).Info("Memory backing not defined. skipping reconcile memory backing")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	var currentLockedToMax bool
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+		currentLockedToMax = ptr.Deref(virtualMachine.Config.MemoryReservationLockedToMax, false)
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.MemoryBackingExtraConfig(backing)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; !strings.EqualFold(current[k], v) {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if vcenter.HugePagesRequireReservation(backing) && !currentLockedToMax {
+		desired.MemoryReservationLockedToMax = ptr.To(true)
+		changes = append(changes, "memoryReservationLockedToMax false -> true")
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	if virtualMachine.Runtime.Host != nil {
+		var host mo.HostSystem
+		if err := virtualMachineCtx.Obj.Properties(ctx, *virtualMachine.Runtime.Host, vcenter.HugePagesHostProperties, &host); err != nil {
+			return false, errors.Wrapf(err, "error getting huge page support of host of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		if !vcenter.HostSupportsHugePages(host, backing.HugePageSize) {
+			err := errors.Errorf("host %s of VM %s does not back the memory of VMs by %s huge pages", host.Name, virtualMachineCtx.VSphereVM.Name, backing.HugePageSize)
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HugePagesNotSupportedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+
+	log.Info("Updating VM memory backing", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileNUMANodeAffinity ensures the VM is constrained to the NUMA nodes defined in the
+// spec, which the host of the VM must have. The affinity is only changed while the VM is
+// powered off, as it cannot take effect before the VM is powered on again.
+func (vms *VMService) reconcileNUMANodeAffinity(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	nodes := virtualMachineCtx.VSphereVM.Spec.NUMANodeAffinity
+	if len(nodes) == 0 {
+		log.V(5).Info("NUMA node affinity not defined. skipping reconcile NUMA node affinity")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+
+	current := map[string]string{}
+	if virtualMachine.Config != nil {
+		for _, ec := range virtualMachine.Config.ExtraConfig {
+			if optionValue := ec.GetOptionValue(); optionValue != nil {
+				current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+			}
+		}
+	}
+
+	var (
+		desired types.VirtualMachineConfigSpec
+		changes []string
+	)
+	extraConfig := vcenter.NUMANodeAffinityExtraConfig(nodes)
+	for _, k := range sortedKeys(extraConfig) {
+		if v := extraConfig[k]; current[k] != v {
+			desired.ExtraConfig = append(desired.ExtraConfig, &types.OptionValue{Key: k, Value: v})
+			changes = append(changes, fmt.Sprintf("extraConfig %s", k))
+		}
+	}
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	if virtualMachine.Runtime.Host != nil {
+		var host mo.HostSystem
+		if err := virtualMachineCtx.Obj.Properties(ctx, *virtualMachine.Runtime.Host, []string{"name", "hardware.numaInfo"}, &host); err != nil {
+			return false, errors.Wrapf(err, "error getting NUMA topology of host of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		if !vcenter.HostHasNUMANodes(host, nodes) {
+			err := errors.Errorf("host %s of VM %s does not have NUMA nodes %s", host.Name, virtualMachineCtx.VSphereVM.Name, vcenter.NUMANodeAffinityValue(nodes))
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.NUMANodeNotAvailableReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		log.V(4).Info("VM is not powered off. skipping reconcile NUMA node affinity")
+		return true, nil
+	}
+
+	log.Info("Updating VM NUMA node affinity", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(desired, changes...)
+	return true, nil
+}
+
+// reconcileToolsUpgradePolicy ensures the VMware Tools upgrade policy of the VM
+// matches the one defined in the spec.
+func (vms *VMService) reconcileToolsUpgradePolicy(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	toolsUpgradePolicy := virtualMachineCtx.VSphereVM.Spec.ToolsUpgradePolicy
+	if toolsUpgradePolicy == "" {
+		log.V(5).Info("Tools upgrade policy not defined. skipping reconcile tools upgrade policy")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var current string
+	if virtualMachine.Config != nil && virtualMachine.Config.Tools != nil {
+		current = virtualMachine.Config.Tools.ToolsUpgradePolicy
+	}
+	if current == string(toolsUpgradePolicy) {
+		return true, nil
+	}
+
+	log.Info("Updating VM tools upgrade policy", "toolsUpgradePolicy", toolsUpgradePolicy)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		Tools: &types.ToolsConfigInfo{
+			ToolsUpgradePolicy: string(toolsUpgradePolicy),
+		},
+	}, fmt.Sprintf("toolsUpgradePolicy %s -> %s", current, toolsUpgradePolicy))
+	return true, nil
+}
+
+// reconcileToolsSyncTime ensures the VMware Tools of the VM synchronize the time of the
+// guest with the host as defined in the spec.
+func (vms *VMService) reconcileToolsSyncTime(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	syncTime := virtualMachineCtx.VSphereVM.Spec.ToolsSyncTimeWithHost
+	if syncTime == nil {
+		log.V(5).Info("Tools time sync not defined. skipping reconcile tools time sync")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var current bool
+	if virtualMachine.Config != nil && virtualMachine.Config.Tools != nil {
+		current = ptr.Deref(virtualMachine.Config.Tools.SyncTimeWithHost, false)
+	}
+	if current == *syncTime {
+		return true, nil
+	}
+
+	log.Info("Updating VM tools time sync", "syncTimeWithHost", *syncTime)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		Tools: &types.ToolsConfigInfo{
+			SyncTimeWithHost: syncTime,
+		},
+	}, fmt.Sprintf("toolsSyncTimeWithHost %t -> %t", current, *syncTime))
+	return true, nil
+}
+
+func (vms *VMService) reconcilePCIDevices(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if expectedPciDevices := virtualMachineCtx.VSphereVM.Spec.VirtualMachineCloneSpec.PciDevices; len(expectedPciDevices) != 0 {
+		specsToBeAdded, err := pci.CalculateDevicesToBeAdded(ctx, virtualMachineCtx.Obj, expectedPciDevices)
+		if err != nil {
+			return err
+		}
+
+		if len(specsToBeAdded) == 0 {
+			if conditions.Has(virtualMachineCtx.VSphereVM, infrav1.PCIDevicesDetachedCondition) {
+				conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.PCIDevicesDetachedCondition)
+			}
+			log.V(5).Info("No new PCI devices to be added")
+			return nil
+		}
+
+		powerState, err := virtualMachineCtx.Obj.PowerState(ctx)
+		if err != nil {
 			return err
 		}
 		if powerState == types.VirtualMachinePowerStatePoweredOn {
@@ -537,6 +1639,7 @@ func (vms *VMService) reconcilePCIDevices(ctx context.Context, virtualMachineCtx
 		if err := virtualMachineCtx.Obj.AddDevice(ctx, pci.ConstructDeviceSpecs(specsToBeAdded)...); err != nil {
 			return errors.Wrapf(err, "error adding pci devices for %q", ctx)
 		}
+		virtualMachineCtx.PropertiesOutdated = true
 	}
 	return nil
 }
@@ -590,6 +1693,68 @@ func (vms *VMService) reconcileHostInfo(ctx context.Context, virtualMachineCtx *
 	return nil
 }
 
+// reconcileSerialPortStatus reports the serial ports of the VM and their backing.
+func (vms *VMService) reconcileSerialPortStatus(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	devices, err := virtualMachineCtx.Obj.Device(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get devices of vm %s", ctx)
+	}
+
+	var serialPorts []infrav1.SerialPortStatus
+	for _, device := range devices.SelectByType((*types.VirtualSerialPort)(nil)) {
+		status := infrav1.SerialPortStatus{
+			Label: devices.Name(device),
+		}
+		if info := device.GetVirtualDevice().DeviceInfo; info != nil {
+			status.Label = info.GetDescription().Label
+		}
+		switch backing := device.GetVirtualDevice().Backing.(type) {
+		case *types.VirtualSerialPortURIBackingInfo:
+			status.URI = backing.ServiceURI
+			status.ProxyURI = backing.ProxyURI
+		case *types.VirtualSerialPortFileBackingInfo:
+			status.File = backing.FileName
+		}
+		serialPorts = append(serialPorts, status)
+	}
+	virtualMachineCtx.VSphereVM.Status.SerialPorts = serialPorts
+	return nil
+}
+
+// reconcileDiskStatus reports the disks of the VM, the controllers they are attached to and
+// the datastores of the files and disks of the VM.
+func (vms *VMService) reconcileDiskStatus(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return errors.Errorf("unable to get devices of vm %s", ctx)
+	}
+	devices := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device)
+
+	var disks []infrav1.VirtualMachineDiskStatus
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+		status := infrav1.VirtualMachineDiskStatus{
+			Label:       devices.Name(device),
+			UnitNumber:  disk.UnitNumber,
+			CapacityGiB: disk.CapacityInKB / (1024 * 1024),
+			UUID:        vcenter.DiskUUID(disk),
+		}
+		if info := disk.DeviceInfo; info != nil {
+			status.Label = info.GetDescription().Label
+		}
+		if controller := devices.FindByKey(disk.ControllerKey); controller != nil {
+			status.Controller = devices.Name(controller)
+			if info := controller.GetVirtualDevice().DeviceInfo; info != nil {
+				status.Controller = info.GetDescription().Label
+			}
+		}
+		disks = append(disks, status)
+	}
+	virtualMachineCtx.VSphereVM.Status.Disks = disks
+	virtualMachineCtx.VSphereVM.Status.Datastores = vmDatastoreNames(*virtualMachine)
+	return nil
+}
+
 func (vms *VMService) setMetadata(ctx context.Context, virtualMachineCtx *virtualMachineContext, metadata []byte) (string, error) {
 	var extraConfig extra.Config
 
@@ -655,6 +1820,57 @@ func (vms *VMService) getBootstrapData(ctx context.Context, vmCtx *capvcontext.V
 		return nil, "", errors.New("error retrieving bootstrap data: secret value key is missing")
 	}
 
+	bootstrapData := value
+	value, err := addSSHAuthorizedKeys(value, bootstrapv1.Format(format), vmCtx.VSphereVM.Spec.SSHAuthorizedKeys)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add SSH authorized keys to bootstrap data for %s", ctx)
+	}
+
+	value, err = addTimeSettings(value, bootstrapv1.Format(format), vmCtx.VSphereVM.Spec.OS, vmCtx.VSphereVM.Spec.TimeZone, vmCtx.VSphereVM.Spec.NTPServers)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add time settings to bootstrap data for %s", ctx)
+	}
+
+	value, err = addProxySettings(value, bootstrapv1.Format(format), vmCtx.VSphereVM.Spec.OS, vmCtx.VSphereVM.Spec.Proxy)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add proxy settings to bootstrap data for %s", ctx)
+	}
+
+	value, err = addKernelArgs(value, bootstrapv1.Format(format), vmCtx.VSphereVM.Spec.OS, vmCtx.VSphereVM.Spec.KernelArgs)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add kernel arguments to bootstrap data for %s", ctx)
+	}
+
+	registryMirrorFiles, err := getRegistryMirrorFiles(ctx, vmCtx)
+	if err != nil {
+		return nil, "", err
+	}
+	value, err = addRegistryMirrors(value, bootstrapv1.Format(format), registryMirrorFiles)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add registry mirrors to bootstrap data for %s", ctx)
+	}
+
+	if guestNetworkReconfigurationEnabled(vmCtx.VSphereVM) {
+		value, err = addNetworkUpdateEvents(value, bootstrapv1.Format(format))
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to add network update events to bootstrap data for %s", ctx)
+		}
+	}
+
+	files, err := getSecretFiles(ctx, vmCtx)
+	if err != nil {
+		return nil, "", err
+	}
+	value, err = addSecretFiles(value, bootstrapv1.Format(format), files)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to add files to bootstrap data for %s", ctx)
+	}
+
+	value, err = orderInjectedCommands(bootstrapData, value, bootstrapv1.Format(format), vmCtx.VSphereVM.Spec.InjectedCommandsOrder)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to order injected commands of bootstrap data for %s", ctx)
+	}
+
 	return value, bootstrapv1.Format(format), nil
 }
 
@@ -689,6 +1905,56 @@ func (vms *VMService) reconcileVMGroupInfo(ctx context.Context, virtualMachineCt
 	return true, nil
 }
 
+// reconcileDRSAutomationLevel ensures the DRS override of the VM matches the
+// DRS automation level defined in the VSphereVM spec.
+func (vms *VMService) reconcileDRSAutomationLevel(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	level := virtualMachineCtx.VSphereVM.Spec.DRSAutomationLevel
+	if level == "" {
+		// The VM uses the automation level of the compute cluster, so an override
+		// left over from a previously defined level is removed. VMs which are not
+		// placed in a compute cluster with DRS enabled have no override.
+		override, err := cluster.GetDRSVMOverride(ctx, virtualMachineCtx.Obj)
+		if err != nil {
+			log.V(5).Info("DRS automation level not defined. skipping reconcile DRS override", "reason", err.Error())
+			return true, nil
+		}
+		if override.Info == nil {
+			return true, nil
+		}
+
+		log.Info("Removing DRS override of VM")
+		task, err := override.Remove(ctx)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to remove DRS override of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+		log.Info("Wait for DRS override of VM to be removed")
+		return false, nil
+	}
+
+	override, err := cluster.GetDRSVMOverride(ctx, virtualMachineCtx.Obj)
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.DRSOverrideFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to get DRS override of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	enabled := level != infrav1.DRSAutomationLevelDisabled
+	if override.Matches(enabled, types.DrsBehaviorManual) {
+		return true, nil
+	}
+
+	log.Info("Updating DRS override of VM", "drsAutomationLevel", level)
+	task, err := override.Set(ctx, enabled, types.DrsBehaviorManual)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to set DRS override of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for DRS override of VM to be updated")
+	return false, nil
+}
+
 func (vms *VMService) reconcileTags(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -19,21 +19,30 @@ package govmomi
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	pbmsimulator "github.com/vmware/govmomi/pbm/simulator"
 	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/cluster"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/extra"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
 )
 
@@ -49,6 +58,58 @@ func emptyVirtualMachineContext() *virtualMachineContext {
 	}
 }
 
+func Test_fetchVirtualMachineProperties(t *testing.T) {
+	g := NewWithT(t)
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+		// Properties of a previous reconcile are not kept.
+		vmCtx.Properties.Summary.Config.Name = "outdated"
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		g.Expect(vmCtx.Properties.Summary.Config.Name).To(BeEmpty())
+		g.Expect(vmCtx.Properties.Name).To(Equal("DC0_H0_VM0"))
+		g.Expect(vmCtx.Properties.Config).ToNot(BeNil())
+		g.Expect(vmCtx.Properties.Config.Hardware.Device).ToNot(BeEmpty())
+		g.Expect(vmCtx.Properties.Config.Hardware.NumCPU).ToNot(BeZero())
+		g.Expect(vmCtx.Properties.Config.Hardware.MemoryMB).ToNot(BeZero())
+		g.Expect(vmCtx.Properties.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+		g.Expect(vmCtx.Properties.Runtime.Host).ToNot(BeNil())
+		return nil
+	})
+}
+
+func Test_refreshVirtualMachineProperties(t *testing.T) {
+	g := NewWithT(t)
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+
+		task, err := vm.PowerOff(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(task.Wait(ctx)).To(Succeed())
+
+		// The properties are kept unless a step changed the VM.
+		g.Expect(refreshVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		g.Expect(vmCtx.Properties.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+
+		vmCtx.PropertiesOutdated = true
+		g.Expect(refreshVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		g.Expect(vmCtx.Properties.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+		g.Expect(vmCtx.PropertiesOutdated).To(BeFalse())
+		return nil
+	})
+}
+
 func Test_reconcilePCIDevices(t *testing.T) {
 	var vmCtx *virtualMachineContext
 	var g *WithT
@@ -89,6 +150,7 @@ func Test_reconcilePCIDevices(t *testing.T) {
 			}
 
 			g.Expect(vms.reconcilePCIDevices(ctx, vmCtx)).ToNot(HaveOccurred())
+			g.Expect(vmCtx.PropertiesOutdated).To(BeTrue())
 
 			// get the VM's virtual device list
 			devices, err := vm.Device(ctx)
@@ -197,6 +259,1545 @@ func Test_ReconcileStoragePolicy(t *testing.T) {
 	})
 }
 
+func Test_reconcileBootOptions(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	t.Run("when boot options are not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+		ok, err := vms.reconcileBootOptions(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when powered off VM has different boot options", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						BootOptions: &infrav1.VirtualMachineBootOptions{
+							BootDelay:        ptr.To[int64](5000),
+							BootRetryEnabled: ptr.To(true),
+							BootRetryDelay:   ptr.To[int64](20000),
+						},
+					},
+				},
+			}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileBootOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once the boot options match.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileBootOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileNestedHardwareVirtualization(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(nestedHV *bool) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					NestedHardwareVirtualization: nestedHV,
+				},
+			},
+		}
+	}
+
+	t.Run("when nested hardware virtualization is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(nil)
+		ok, err := vms.reconcileNestedHardwareVirtualization(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the host does not support nested hardware virtualization", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(ptr.To(true))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNestedHardwareVirtualization(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.NestedHardwareVirtualizationNotSupportedReason))
+			return nil
+		})
+	})
+
+	t.Run("when powered off VM has nested hardware virtualization disabled", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"runtime.host"}, &virtualMachine)).To(Succeed())
+			host := simulator.Map.Get(*virtualMachine.Runtime.Host).(*simulator.HostSystem)
+			host.Capability = &types.HostCapability{NestedHVSupported: ptr.To(true)}
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(ptr.To(true))
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNestedHardwareVirtualization(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once nested hardware virtualization is enabled.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNestedHardwareVirtualization(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileCPUMMUVirtualization(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(mode infrav1.CPUMMUVirtualizationMode) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CPUMMUVirtualization: mode,
+				},
+			},
+		}
+	}
+
+	t.Run("when CPU/MMU virtualization is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM("")
+		ok, err := vms.reconcileCPUMMUVirtualization(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+	})
+
+	t.Run("when the host does not support the CPU/MMU virtualization mode", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CPUMMUVirtualizationModeSoftware)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCPUMMUVirtualization(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.CPUMMUVirtualizationNotSupportedReason))
+			return nil
+		})
+	})
+
+	t.Run("when powered off VM virtualizes the CPU/MMU in another mode", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"runtime.host"}, &virtualMachine)).To(Succeed())
+			host := simulator.Map.Get(*virtualMachine.Runtime.Host).(*simulator.HostSystem)
+			host.Capability = &types.HostCapability{VirtualExecUsageSupported: ptr.To(true)}
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CPUMMUVirtualizationModeHardware)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCPUMMUVirtualization(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.flags"}, &virtualMachine)).To(Succeed())
+			g.Expect(virtualMachine.Config.Flags.VirtualExecUsage).To(Equal(string(types.VirtualMachineFlagInfoVirtualExecUsageHvOn)))
+			g.Expect(virtualMachine.Config.Flags.VirtualMmuUsage).To(Equal(string(types.VirtualMachineFlagInfoVirtualMmuUsageOn)))
+
+			// A second reconcile is a no-op once the CPU/MMU is virtualized by hardware.
+			vmCtx.ConfigChange = configChange{}
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileCPUMMUVirtualization(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcilePerformanceOptions(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	t.Run("when performance options are not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+		ok, err := vms.reconcilePerformanceOptions(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when powered on VM has drifted performance options", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						PerformanceOptions: &infrav1.VirtualMachinePerformanceOptions{
+							VirtualCPUPerformanceCountersEnabled: ptr.To(true),
+							ExtraConfig: map[string]string{
+								"monitor_control.pseudo_perfctr": "TRUE",
+							},
+						},
+					},
+				},
+			}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcilePerformanceOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op, as the virtual CPU performance
+			// counters are not updated while the VM is powered on.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcilePerformanceOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileLoggingOptions(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	t.Run("when logging options are not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+		ok, err := vms.reconcileLoggingOptions(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when VM has drifted logging options", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						LoggingOptions: &infrav1.VirtualMachineLoggingOptions{
+							Enabled:    ptr.To(true),
+							RotateSize: ptr.To[int64](1048576),
+							KeepOld:    ptr.To[int32](3),
+						},
+					},
+				},
+			}
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileLoggingOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once the logging options match.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileLoggingOptions(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileIsolation(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	t.Run("when isolation is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+		ok, err := vms.reconcileIsolation(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	for _, tt := range []struct {
+		name      string
+		isolation *infrav1.VirtualMachineIsolation
+		hardened  bool
+	}{
+		{
+			name:      "when VM has drifted isolation settings",
+			isolation: &infrav1.VirtualMachineIsolation{CopyPasteDisabled: ptr.To(true), VMCIRestricted: ptr.To(true)},
+		},
+		{
+			name:     "when VM lacks the hardened isolation defaults",
+			hardened: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g = NewWithT(t)
+			before()
+			vmCtx.HardenVMIsolation = tt.hardened
+
+			simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+				vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+				g.Expect(err).ToNot(HaveOccurred())
+
+				vmCtx.Obj = vm
+				vmCtx.VSphereVM = &infrav1.VSphereVM{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "vsphereVM1",
+						Namespace: "my-namespace",
+					},
+					Spec: infrav1.VSphereVMSpec{
+						VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+							Isolation: tt.isolation,
+						},
+					},
+				}
+
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err := vms.reconcileIsolation(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.ConfigChange.changes).ToNot(BeEmpty())
+				reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+				// A second reconcile is a no-op once the isolation settings match.
+				vmCtx.VSphereVM.Status.TaskRef = ""
+				g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+				ok, err = vms.reconcileIsolation(ctx, vmCtx)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+				return nil
+			})
+		})
+	}
+}
+
+func Test_reconcileOwnerExtraConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	vmCtx := emptyVirtualMachineContext()
+	vmCtx.Client = fake.NewClientBuilder().Build()
+	vmCtx.MachineName = "my-machine"
+	vms := &VMService{}
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "my-cluster"},
+			},
+		}
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err := vms.reconcileOwnerExtraConfig(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(HaveLen(3))
+		reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+		var moVM mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.extraConfig"}, &moVM)).To(Succeed())
+		current := map[string]string{}
+		for _, ec := range moVM.Config.ExtraConfig {
+			optionValue := ec.GetOptionValue()
+			current[optionValue.Key] = fmt.Sprint(optionValue.Value)
+		}
+		g.Expect(current).To(HaveKeyWithValue("capv.cluster", "my-cluster"))
+		g.Expect(current).To(HaveKeyWithValue("capv.machine", "my-machine"))
+		g.Expect(current).To(HaveKeyWithValue("capv.namespace", "my-namespace"))
+
+		// A second reconcile is a no-op once the extra config matches.
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err = vms.reconcileOwnerExtraConfig(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		return nil
+	})
+}
+
+func Test_reconcileDiskStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	vmCtx := emptyVirtualMachineContext()
+	vmCtx.Client = fake.NewClientBuilder().Build()
+	vms := &VMService{}
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+		}
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		g.Expect(vms.reconcileDiskStatus(ctx, vmCtx)).To(Succeed())
+		g.Expect(vmCtx.VSphereVM.Status.Disks).To(HaveLen(1))
+		disk := vmCtx.VSphereVM.Status.Disks[0]
+		g.Expect(disk.Label).ToNot(BeEmpty())
+		g.Expect(disk.Controller).ToNot(BeEmpty())
+		g.Expect(disk.UnitNumber).ToNot(BeNil())
+		g.Expect(disk.UUID).ToNot(BeEmpty())
+		g.Expect(vmCtx.VSphereVM.Status.Datastores).To(Equal([]string{"LocalDS_0"}))
+		return nil
+	})
+}
+
+func Test_reconcileDiskEnableUUID(t *testing.T) {
+	g := NewWithT(t)
+
+	vmCtx := emptyVirtualMachineContext()
+	vmCtx.Client = fake.NewClientBuilder().Build()
+	vms := &VMService{}
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					DiskEnableUUID: ptr.To(true),
+				},
+			},
+		}
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err := vms.reconcileDiskEnableUUID(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("extraConfig disk.EnableUUID"))
+		reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+		// A second reconcile is a no-op once the setting matches.
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err = vms.reconcileDiskEnableUUID(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		return nil
+	})
+}
+
+func Test_reconcileMemoryBacking(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(backing *infrav1.VirtualMachineMemoryBacking) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					MemoryBacking: backing,
+				},
+			},
+		}
+	}
+
+	t.Run("when memory backing is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(nil)
+		ok, err := vms.reconcileMemoryBacking(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the host does not back memory by huge pages", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"runtime.host"}, &virtualMachine)).To(Succeed())
+			host := simulator.Map.Get(*virtualMachine.Runtime.Host).(*simulator.HostSystem)
+			host.Config.Option = []types.BaseOptionValue{&types.OptionValue{Key: "Mem.AllocGuestLargePage", Value: int64(0)}}
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineMemoryBacking{HugePageSize: infrav1.HugePageSize2Mi})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileMemoryBacking(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.HugePagesNotSupportedReason))
+			return nil
+		})
+	})
+
+	t.Run("when VM has drifted memory backing", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			// The CPUs of the host support 1 GiB pages.
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"runtime.host"}, &virtualMachine)).To(Succeed())
+			host := simulator.Map.Get(*virtualMachine.Runtime.Host).(*simulator.HostSystem)
+			hardware := *host.Hardware
+			hardware.CpuFeature = []types.HostCpuIdInfo{{Level: -2147483647, Edx: "0010:1100:0001:0000:0000:1000:0000:0000"}}
+			host.Hardware = &hardware
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(&infrav1.VirtualMachineMemoryBacking{HugePageSize: infrav1.HugePageSize1Gi})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileMemoryBacking(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf(
+				"extraConfig sched.mem.lpage.enable1GPage",
+				"extraConfig sched.mem.pshare.enable",
+				"memoryReservationLockedToMax false -> true",
+			))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once the memory backing matches.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileMemoryBacking(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileNUMANodeAffinity(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	vms := &VMService{}
+
+	newVSphereVM := func(nodes ...int32) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					NUMANodeAffinity: nodes,
+				},
+			},
+		}
+	}
+
+	t.Run("when NUMA node affinity is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM()
+		ok, err := vms.reconcileNUMANodeAffinity(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+	})
+
+	t.Run("when the host does not have the NUMA node", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(1)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNUMANodeAffinity(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.NUMANodeNotAvailableReason))
+			return nil
+		})
+	})
+
+	t.Run("when the VM is powered on", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(0)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNUMANodeAffinity(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when a powered off VM has drifted NUMA node affinity", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(0)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileNUMANodeAffinity(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("extraConfig numa.nodeAffinity"))
+			reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+			// A second reconcile is a no-op once the affinity matches.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileNUMANodeAffinity(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileSwapPlacement(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	vms := &VMService{}
+
+	newVSphereVM := func(swapPlacement infrav1.SwapPlacement) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					SwapPlacement: swapPlacement,
+				},
+			},
+		}
+	}
+
+	t.Run("when swap placement is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM("")
+		ok, err := vms.reconcileSwapPlacement(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+	})
+
+	t.Run("when the swap placement is inherited", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SwapPlacementInherit)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSwapPlacement(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the VM is powered on", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SwapPlacementVMDirectory)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSwapPlacement(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when a powered off VM has drifted swap placement", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SwapPlacementVMDirectory)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSwapPlacement(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("swapPlacement inherit -> vmDirectory"))
+			return nil
+		})
+	})
+
+	t.Run("when the host has no local swap datastore", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SwapPlacementHostLocal)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSwapPlacement(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.HostLocalSwapNotAvailableReason))
+
+			simVM := simulator.Map.Get(vm.Reference()).(*simulator.VirtualMachine)
+			simHost := simulator.Map.Get(*simVM.Runtime.Host).(*simulator.HostSystem)
+			simHost.Config.LocalSwapDatastore = &simHost.Datastore[0]
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileSwapPlacement(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("swapPlacement inherit -> hostLocal"))
+			return nil
+		})
+	})
+}
+
+func Test_reconcileToolsUpgradePolicy(t *testing.T) {
+	g := NewWithT(t)
+	vmCtx := emptyVirtualMachineContext()
+	vmCtx.Client = fake.NewClientBuilder().Build()
+	vms := &VMService{}
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ToolsUpgradePolicy: infrav1.ToolsUpgradePolicyUpgradeAtPowerCycle,
+				},
+			},
+		}
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err := vms.reconcileToolsUpgradePolicy(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+		// A second reconcile is a no-op once the policy matches.
+		vmCtx.VSphereVM.Status.TaskRef = ""
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err = vms.reconcileToolsUpgradePolicy(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		return nil
+	})
+}
+
+func Test_reconcileToolsSyncTime(t *testing.T) {
+	g := NewWithT(t)
+	vmCtx := emptyVirtualMachineContext()
+	vms := &VMService{}
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		vmCtx.Obj = vm
+		vmCtx.VSphereVM = &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ToolsSyncTimeWithHost: ptr.To(true),
+				},
+			},
+		}
+
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err := vms.reconcileToolsSyncTime(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(ConsistOf("toolsSyncTimeWithHost false -> true"))
+		reconfigureAndWait(ctx, g, c, vms, vmCtx)
+
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.tools"}, &virtualMachine)).To(Succeed())
+		g.Expect(virtualMachine.Config.Tools.SyncTimeWithHost).To(Equal(ptr.To(true)))
+
+		// A second reconcile is a no-op once the time sync matches.
+		vmCtx.ConfigChange = configChange{}
+		g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+		ok, err = vms.reconcileToolsSyncTime(ctx, vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.ConfigChange.changes).To(BeEmpty())
+		return nil
+	})
+}
+
+func Test_reconcilePowerState(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(spec infrav1.VSphereVMSpec) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: spec,
+		}
+	}
+
+	t.Run("when the VM is not powered on after clone", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					PowerOnAfterClone: ptr.To(false),
+				},
+			})
+
+			// The VM is left powered off.
+			ok, err := vms.reconcilePowerState(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			powerState, err := vm.PowerState(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(powerState).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+			return nil
+		})
+	})
+
+	t.Run("when the desired power state of a powered on VM is poweredOff", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.VSphereVMSpec{
+				PowerOffMode:      infrav1.VirtualMachinePowerOpModeHard,
+				DesiredPowerState: infrav1.VirtualMachinePowerStatePoweredOff,
+			})
+			scheme := runtime.NewScheme()
+			g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+			vmCtx.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(vmCtx.VSphereVM).WithStatusSubresource(&infrav1.VSphereVM{}).Build()
+			vmCtx.PatchHelper, err = patch.NewHelper(vmCtx.VSphereVM, vmCtx.Client)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			ok, err := vms.reconcilePowerState(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			powerState, err := vm.PowerState(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(powerState).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+
+			// The VM is kept powered off.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			ok, err = vms.reconcilePowerState(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileCloneConflict(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	// setOwnerUID records the given owner UID on the VM as done at clone time.
+	setOwnerUID := func(ctx context.Context, vm *object.VirtualMachine, uid string) {
+		var extraConfig extra.Config
+		extraConfig.SetOwnerUID(uid)
+		task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{ExtraConfig: extraConfig})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(task.Wait(ctx)).To(Succeed())
+	}
+
+	newVSphereVM := func(policy infrav1.CloneConflictPolicy) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+				UID:       "vsphere-vm-uid",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					CloneConflictPolicy: policy,
+				},
+			},
+		}
+	}
+
+	t.Run("when the VM was found by BIOS UUID", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+		vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicyDelete)
+		vmCtx.VSphereVM.Spec.BiosUUID = "bios-uuid"
+
+		ok, err := vms.reconcileCloneConflict(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+	})
+
+	t.Run("when the conflicting VM is adopted", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM("")
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the conflicting VM was created for another VSphereVM and is not adopted", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			setOwnerUID(ctx, vm, "another-vsphere-vm-uid")
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicyAdopt)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.CloneConflictReason))
+			return nil
+		})
+	})
+
+	t.Run("when the policy is to fail", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicyFail)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.VMName).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.CloneConflictReason))
+			return nil
+		})
+	})
+
+	t.Run("when the policy is to clone the VM with a suffixed name", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicySuffix)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.VMName).To(HavePrefix("vsphereVM1-"))
+			g.Expect(vmCtx.VSphereVM.Status.VMName).To(HaveLen(len("vsphereVM1") + infrav1.CloneConflictSuffixLength))
+
+			// The conflicting VM is left alone.
+			_, err = find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			return nil
+		})
+	})
+
+	t.Run("when the suffixed name is too long", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicySuffix)
+			vmCtx.VSphereVM.Name = strings.Repeat("a", infrav1.MaxVirtualMachineNameLength)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.VMName).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when the conflicting VM was not created for the VSphereVM", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			setOwnerUID(ctx, vm, "another-vsphere-vm-uid")
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicyDelete)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.CloneConflictReason))
+			return nil
+		})
+	})
+
+	t.Run("when the conflicting VM is left over from a failed clone", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			setOwnerUID(ctx, vm, "vsphere-vm-uid")
+
+			vmCtx.Obj = vm
+			vmCtx.Ref = vm.Reference()
+			vmCtx.VSphereVM = newVSphereVM(infrav1.CloneConflictPolicyDelete)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileCloneConflict(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			// The partial VM is gone so the clone can be retried.
+			_, err = find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).To(HaveOccurred())
+			return nil
+		})
+	})
+}
+
+func Test_reconcileDRSAutomationLevel(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.Client = fake.NewClientBuilder().Build()
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(level infrav1.DRSAutomationLevel) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					DRSAutomationLevel: level,
+				},
+			},
+		}
+	}
+
+	t.Run("when DRS automation level is not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM("")
+
+			ok, err := vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when DRS automation level is unset after an override was set", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			override, err := cluster.GetDRSVMOverride(ctx, vm)
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := override.Set(ctx, true, types.DrsBehaviorManual)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM("")
+
+			ok, err := vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task = object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			override, err = cluster.GetDRSVMOverride(ctx, vm)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(override.Info).To(BeNil())
+
+			// A second reconcile is a no-op once the override is removed.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			ok, err = vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			return nil
+		})
+	})
+
+	t.Run("when DRS automation level is set to manual", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.DRSAutomationLevelManual)
+
+			ok, err := vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+
+			task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			override, err := cluster.GetDRSVMOverride(ctx, vm)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(override.Info).ToNot(BeNil())
+			g.Expect(override.Info.Behavior).To(Equal(types.DrsBehaviorManual))
+
+			// A second reconcile is a no-op once the override matches.
+			vmCtx.VSphereVM.Status.TaskRef = ""
+			ok, err = vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+
+			// Switching to disabled updates the existing override.
+			vmCtx.VSphereVM = newVSphereVM(infrav1.DRSAutomationLevelDisabled)
+			ok, err = vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			task = object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			override, err = cluster.GetDRSVMOverride(ctx, vm)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(override.Info.Enabled).To(Equal(ptr.To(false)))
+			return nil
+		})
+	})
+
+	t.Run("when DRS is not enabled on the compute cluster", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			finder := find.NewFinder(c)
+			ccr, err := finder.ClusterComputeResource(ctx, "DC0_C0")
+			g.Expect(err).ToNot(HaveOccurred())
+			task, err := ccr.Reconfigure(ctx, &types.ClusterConfigSpecEx{
+				DrsConfig: &types.ClusterDrsConfigInfo{Enabled: ptr.To(false)},
+			}, true)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vm, err := finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.DRSAutomationLevelManual)
+
+			ok, err := vms.reconcileDRSAutomationLevel(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.DRSOverrideFailedReason))
+			return nil
+		})
+	})
+}
+
+func TestDestroyVM(t *testing.T) {
+	newVMContext := func(c *vim25.Client, biosUUID string) *capvcontext.VMContext {
+		return &capvcontext.VMContext{
+			ControllerManagerContext: &capvcontext.ControllerManagerContext{},
+			VSphereVM: &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vsphereVM1",
+					Namespace: "my-namespace",
+				},
+				Spec: infrav1.VSphereVMSpec{
+					BiosUUID: biosUUID,
+				},
+			},
+			Session: &session.Session{Client: &govmomi.Client{Client: c}, Finder: find.NewFinder(c)},
+		}
+	}
+
+	t.Run("when the VM was already deleted in vCenter", func(t *testing.T) {
+		g := NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			var moVM mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.uuid"}, &moVM)).To(Succeed())
+			task, err := vm.Destroy(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			// vCenter reports the object of the deleted VM as not found.
+			_, err = vm.PowerState(ctx)
+			g.Expect(isManagedObjectNotFound(err)).To(BeTrue())
+
+			vmCtx := newVMContext(c, moVM.Config.Uuid)
+			result, state, err := (&VMService{}).DestroyVM(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.IsZero()).To(BeTrue())
+			g.Expect(state.State).To(Equal(infrav1.VirtualMachineStateNotFound))
+			return nil
+		})
+	})
+
+	t.Run("when vCenter is not reachable", func(t *testing.T) {
+		g := NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+			var moVM mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.uuid"}, &moVM)).To(Succeed())
+
+			// Requests of a cancelled context fail like requests to an unreachable vCenter.
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err = vm.PowerState(cancelledCtx)
+			g.Expect(isManagedObjectNotFound(err)).To(BeFalse())
+
+			vmCtx := newVMContext(c, moVM.Config.Uuid)
+			_, state, err := (&VMService{}).DestroyVM(cancelledCtx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(state.State).ToNot(Equal(infrav1.VirtualMachineStateNotFound))
+			return nil
+		})
+	})
+}
+
 func getAuthSession(ctx context.Context, server string) (*session.Session, error) {
 	password, _ := simulator.DefaultLogin.Password()
 	return session.GetOrCreate(
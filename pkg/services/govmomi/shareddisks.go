@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileSharedDisks attaches the shared disks defined in the spec to a powered off VM.
+// Shared disks which do not exist are created eagerZeroedThick, one at a time, so the VMs
+// sharing a disk agree on the disk by its datastore path. Attached shared disks must be
+// eagerZeroedThick provisioned, otherwise the VM is not powered on.
+func (vms *VMService) reconcileSharedDisks(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	sharedDisks := virtualMachineCtx.VSphereVM.Spec.SharedDisks
+	if len(sharedDisks) == 0 {
+		log.V(5).Info("Shared disks not defined. skipping reconcile shared disks")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if virtualMachine.Config == nil {
+		return false, errors.Errorf("unable to get devices of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	devices := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device)
+	poweredOff := virtualMachine.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff
+
+	var deviceChange []types.BaseVirtualDeviceConfigSpec
+	for _, sharedDisk := range sharedDisks {
+		if backing := sharedDiskBacking(devices, sharedDisk.DiskPath); backing != nil {
+			if ptr.Deref(backing.ThinProvisioned, false) || !ptr.Deref(backing.EagerlyScrub, false) {
+				err := errors.Errorf("shared disk %s of VM %s is not eagerZeroedThick provisioned", sharedDisk.DiskPath, virtualMachineCtx.VSphereVM.Name)
+				conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.SharedDiskFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				return false, err
+			}
+			continue
+		}
+		if !poweredOff {
+			log.V(4).Info("VM is not powered off. skipping attaching shared disk", "diskPath", sharedDisk.DiskPath)
+			continue
+		}
+
+		var datastorePath object.DatastorePath
+		if !datastorePath.FromString(sharedDisk.DiskPath) {
+			return false, errors.Errorf("invalid datastore path %q of shared disk", sharedDisk.DiskPath)
+		}
+		datastore, err := virtualMachineCtx.Session.DatastoreOrDefault(ctx, datastorePath.Datastore)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to find datastore of shared disk %s", sharedDisk.DiskPath)
+		}
+		if _, err := datastore.Stat(ctx, datastorePath.Path); err != nil {
+			var noSuchFileErr object.DatastoreNoSuchFileError
+			var noSuchDirectoryErr object.DatastoreNoSuchDirectoryError
+			if !errors.As(err, &noSuchFileErr) && !errors.As(err, &noSuchDirectoryErr) {
+				return false, errors.Wrapf(err, "unable to check shared disk %s", sharedDisk.DiskPath)
+			}
+			if sharedDisk.SizeGiB == 0 {
+				err := errors.Errorf("shared disk %s of VM %s does not exist", sharedDisk.DiskPath, virtualMachineCtx.VSphereVM.Name)
+				conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.SharedDiskFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				return false, err
+			}
+			return false, vms.createSharedDisk(ctx, virtualMachineCtx, datastorePath, sharedDisk.SizeGiB)
+		}
+
+		controller, err := devices.FindSCSIController("")
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to find SCSI controller to attach shared disk %s", sharedDisk.DiskPath)
+		}
+		disk := devices.CreateDisk(controller, datastore.Reference(), sharedDisk.DiskPath)
+		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		backing.DiskMode = string(types.VirtualDiskModeIndependent_persistent)
+		backing.ThinProvisioned = ptr.To(false)
+		backing.EagerlyScrub = ptr.To(true)
+		backing.Sharing = string(types.VirtualDiskSharingSharingMultiWriter)
+
+		// The device list is extended so the next shared disk gets the next unit number.
+		devices = append(devices, disk)
+		deviceChange = append(deviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    disk,
+		})
+	}
+	if len(deviceChange) == 0 {
+		return true, nil
+	}
+
+	log.Info("Attaching shared disks to VM")
+	task, err := virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		DeviceChange: deviceChange,
+	})
+	if err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.SharedDiskFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to attach shared disks to VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for shared disks to be attached to VM")
+	return false, nil
+}
+
+// createSharedDisk creates the eagerZeroedThick VMDK of a shared disk of the given size.
+// Zeroing the disk may take a while, the VM waits for the task like for any other task.
+func (vms *VMService) createSharedDisk(ctx context.Context, virtualMachineCtx *virtualMachineContext, datastorePath object.DatastorePath, sizeGiB int32) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	datacenter, err := virtualMachineCtx.Session.Finder.DatacenterOrDefault(ctx, virtualMachineCtx.VSphereVM.Spec.Datacenter)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find datacenter of shared disk %s", datastorePath.String())
+	}
+
+	// The directory of the disk is created first, as creating a disk does not create its
+	// parent directories.
+	if dir := path.Dir(datastorePath.Path); dir != "." {
+		directory := object.DatastorePath{Datastore: datastorePath.Datastore, Path: dir}
+		if err := object.NewFileManager(virtualMachineCtx.Session.Client.Client).MakeDirectory(ctx, directory.String(), datacenter, true); err != nil && !isFileAlreadyExists(err) {
+			return errors.Wrapf(err, "unable to create directory of shared disk %s", datastorePath.String())
+		}
+	}
+
+	log.Info("Creating shared disk", "diskPath", datastorePath.String(), "sizeGiB", sizeGiB)
+	task, err := object.NewVirtualDiskManager(virtualMachineCtx.Session.Client.Client).CreateVirtualDisk(ctx, datastorePath.String(), datacenter, &types.FileBackedVirtualDiskSpec{
+		VirtualDiskSpec: types.VirtualDiskSpec{
+			DiskType:    string(types.VirtualDiskTypeEagerZeroedThick),
+			AdapterType: string(types.VirtualDiskAdapterTypeLsiLogic),
+		},
+		CapacityKb: int64(sizeGiB) * 1024 * 1024,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to create shared disk %s", datastorePath.String())
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for shared disk to be created")
+	return nil
+}
+
+// sharedDiskBacking returns the backing of the disk of the VM with the VMDK at the given
+// datastore path, or nil if the disk is not attached.
+func sharedDiskBacking(devices object.VirtualDeviceList, diskPath string) *types.VirtualDiskFlatVer2BackingInfo {
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		if backing, ok := device.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok && backing.FileName == diskPath {
+			return backing
+		}
+	}
+	return nil
+}
+
+// detachSharedDisks detaches the shared disks from the VM without deleting their VMDKs, so
+// destroying the VM does not delete disks still used by other VMs. It returns nil if no
+// shared disk is attached.
+func detachSharedDisks(ctx context.Context, virtualMachineCtx *virtualMachineContext) (*object.Task, error) {
+	var virtualMachine mo.VirtualMachine
+	if err := virtualMachineCtx.Obj.Properties(ctx, virtualMachineCtx.Obj.Reference(), []string{"config.hardware.device"}, &virtualMachine); err != nil {
+		return nil, errors.Wrapf(err, "error getting devices from VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+	if virtualMachine.Config == nil {
+		return nil, nil
+	}
+	devices := object.VirtualDeviceList(virtualMachine.Config.Hardware.Device)
+
+	var deviceChange []types.BaseVirtualDeviceConfigSpec
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		backing, ok := device.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if !ok || backing.Sharing != string(types.VirtualDiskSharingSharingMultiWriter) {
+			continue
+		}
+		deviceChange = append(deviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationRemove,
+			Device:    device,
+		})
+	}
+	if len(deviceChange) == 0 {
+		return nil, nil
+	}
+	return virtualMachineCtx.Obj.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: deviceChange})
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileSharedDisks(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func(ctx context.Context, c *vim25.Client) {
+		vmCtx = emptyVirtualMachineContext()
+		finder := find.NewFinder(c)
+		dc, err := finder.DefaultDatacenter(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		finder.SetDatacenter(dc)
+		vmCtx.Session = &session.Session{Client: &govmomi.Client{Client: c}, Finder: finder}
+
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(sharedDisks ...infrav1.SharedDiskSpec) *infrav1.VSphereVM {
+		return &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vsphereVM1",
+				Namespace: "my-namespace",
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					SharedDisks: sharedDisks,
+				},
+			},
+		}
+	}
+
+	waitForTask := func(ctx context.Context, c *vim25.Client) {
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).ToNot(BeEmpty())
+		task := object.NewTask(c, types.ManagedObjectReference{Type: morefTypeTask, Value: vmCtx.VSphereVM.Status.TaskRef})
+		g.Expect(task.Wait(ctx)).To(Succeed())
+		vmCtx.VSphereVM.Status.TaskRef = ""
+	}
+
+	t.Run("when shared disks are not defined", func(t *testing.T) {
+		g = NewWithT(t)
+		vmCtx = emptyVirtualMachineContext()
+		vmCtx.VSphereVM = newVSphereVM()
+
+		ok, err := (&VMService{}).reconcileSharedDisks(context.Background(), vmCtx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeTrue())
+	})
+
+	t.Run("when the shared disk is created and attached", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			before(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SharedDiskSpec{
+				DiskPath: "[LocalDS_0] shared/gfs2-data.vmdk",
+				Sharing:  infrav1.VirtualDiskSharingMultiWriter,
+				SizeGiB:  1,
+			})
+
+			// The disk is created first.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSharedDisks(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			waitForTask(ctx, c)
+
+			// The disk is attached once it exists.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileSharedDisks(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			waitForTask(ctx, c)
+
+			var virtualMachine mo.VirtualMachine
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &virtualMachine)).To(Succeed())
+			backing := sharedDiskBacking(virtualMachine.Config.Hardware.Device, "[LocalDS_0] shared/gfs2-data.vmdk")
+			g.Expect(backing).ToNot(BeNil())
+			g.Expect(backing.Sharing).To(Equal(string(types.VirtualDiskSharingSharingMultiWriter)))
+			g.Expect(backing.DiskMode).To(Equal(string(types.VirtualDiskModeIndependent_persistent)))
+
+			// A second reconcile is a no-op once the disk is attached.
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileSharedDisks(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+
+			// The shared disk is detached, not deleted, before the VM is destroyed.
+			task, err := detachSharedDisks(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task).ToNot(BeNil())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &virtualMachine)).To(Succeed())
+			g.Expect(sharedDiskBacking(virtualMachine.Config.Hardware.Device, "[LocalDS_0] shared/gfs2-data.vmdk")).To(BeNil())
+
+			datastore, err := vmCtx.Session.Finder.Datastore(ctx, "LocalDS_0")
+			g.Expect(err).ToNot(HaveOccurred())
+			_, err = datastore.Stat(ctx, "shared/gfs2-data.vmdk")
+			g.Expect(err).ToNot(HaveOccurred())
+			return nil
+		})
+	})
+
+	t.Run("when the shared disk does not exist and has no size", func(t *testing.T) {
+		g = NewWithT(t)
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			before(ctx, c)
+			vm, err := getPoweredoffVM(ctx, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(infrav1.SharedDiskSpec{
+				DiskPath: "[LocalDS_0] shared/missing.vmdk",
+				Sharing:  infrav1.VirtualDiskSharingMultiWriter,
+			})
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileSharedDisks(ctx, vmCtx)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.SharedDiskFailedReason))
+			return nil
+		})
+	})
+}
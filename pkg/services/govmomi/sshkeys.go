@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+)
+
+const (
+	// ignitionDefaultUser is the user the SSH authorized keys are added to
+	// if the Ignition config does not define any user.
+	ignitionDefaultUser = "core"
+
+	// cloudConfigDefaultUser is the entry of the cloud-config users list
+	// which refers to the default user of the distribution.
+	cloudConfigDefaultUser = "default"
+)
+
+// addSSHAuthorizedKeys adds the given SSH authorized keys to the users defined
+// in the bootstrap data. The keys already defined in the bootstrap data are
+// preserved.
+func addSSHAuthorizedKeys(data []byte, format bootstrapv1.Format, keys []string) ([]byte, error) {
+	if len(keys) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	switch format {
+	case bootstrapv1.CloudConfig:
+		return addCloudConfigSSHAuthorizedKeys(data, keys)
+	case bootstrapv1.Ignition:
+		return addIgnitionSSHAuthorizedKeys(data, keys)
+	default:
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// addCloudConfigSSHAuthorizedKeys adds the keys to every user of the cloud-config
+// as well as to the default user, if it is enabled.
+func addCloudConfigSSHAuthorizedKeys(data []byte, keys []string) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The default user is enabled unless a users list without the
+	// default entry is defined.
+	defaultUser := true
+	if users, ok := config["users"].([]interface{}); ok {
+		defaultUser = false
+		for _, u := range users {
+			switch user := u.(type) {
+			case string:
+				if user == cloudConfigDefaultUser {
+					defaultUser = true
+				}
+			case map[string]interface{}:
+				user["ssh_authorized_keys"] = appendSSHAuthorizedKeys(user["ssh_authorized_keys"], keys)
+			}
+		}
+	}
+	if defaultUser {
+		config["ssh_authorized_keys"] = appendSSHAuthorizedKeys(config["ssh_authorized_keys"], keys)
+	}
+
+	return marshalCloudConfig(header, config)
+}
+
+// addIgnitionSSHAuthorizedKeys adds the keys to every user of the Ignition config,
+// or to the default user if no user is defined.
+func addIgnitionSSHAuthorizedKeys(data []byte, keys []string) ([]byte, error) {
+	config, err := unmarshalIgnitionConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	passwd, _ := config["passwd"].(map[string]interface{})
+	if passwd == nil {
+		passwd = map[string]interface{}{}
+		config["passwd"] = passwd
+	}
+	users, _ := passwd["users"].([]interface{})
+	if len(users) == 0 {
+		users = []interface{}{map[string]interface{}{"name": ignitionDefaultUser}}
+		passwd["users"] = users
+	}
+	for _, u := range users {
+		if user, ok := u.(map[string]interface{}); ok {
+			user["sshAuthorizedKeys"] = appendSSHAuthorizedKeys(user["sshAuthorizedKeys"], keys)
+		}
+	}
+
+	return marshalIgnitionConfig(config)
+}
+
+// appendSSHAuthorizedKeys appends the keys which are not yet part of the existing ones.
+func appendSSHAuthorizedKeys(existing interface{}, keys []string) []interface{} {
+	result, _ := existing.([]interface{})
+	seen := map[string]bool{}
+	for _, k := range result {
+		if s, ok := k.(string); ok {
+			seen[s] = true
+		}
+	}
+	for _, k := range keys {
+		if !seen[k] {
+			result = append(result, k)
+			seen[k] = true
+		}
+	}
+	return result
+}
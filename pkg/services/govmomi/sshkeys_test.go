@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+)
+
+func Test_addSSHAuthorizedKeys(t *testing.T) {
+	keys := []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass"}
+
+	tests := []struct {
+		name     string
+		data     string
+		format   bootstrapv1.Format
+		keys     []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "without keys",
+			data:     "#cloud-config\nusers:\n- name: capv\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nusers:\n- name: capv\n",
+		},
+		{
+			name:   "cloud-config users keep their keys",
+			data:   "## template: jinja\n#cloud-config\nusers:\n- name: capv\n  ssh_authorized_keys:\n  - ssh-rsa AAAAB3 bootstrap\n",
+			format: bootstrapv1.CloudConfig,
+			keys:   keys,
+			expected: "## template: jinja\n#cloud-config\nusers:\n- name: capv\n  ssh_authorized_keys:\n  - ssh-rsa AAAAB3 bootstrap\n" +
+				"  - ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n",
+		},
+		{
+			name:   "cloud-config with default user",
+			data:   "#cloud-config\nusers:\n- default\n- name: capv\n",
+			format: bootstrapv1.CloudConfig,
+			keys:   keys,
+			expected: "#cloud-config\nssh_authorized_keys:\n- ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n" +
+				"users:\n- default\n- name: capv\n  ssh_authorized_keys:\n  - ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n",
+		},
+		{
+			name:     "cloud-config without users",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			keys:     keys,
+			expected: "#cloud-config\nruncmd:\n- echo\nssh_authorized_keys:\n- ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n",
+		},
+		{
+			name:     "cloud-config with duplicate keys",
+			data:     "#cloud-config\nssh_authorized_keys:\n- ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n",
+			format:   bootstrapv1.CloudConfig,
+			keys:     keys,
+			expected: "#cloud-config\nssh_authorized_keys:\n- ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass\n",
+		},
+		{
+			name:     "ignition users keep their keys",
+			data:     `{"ignition":{"version":"3.1.0"},"passwd":{"users":[{"name":"core","sshAuthorizedKeys":["ssh-rsa AAAAB3 bootstrap"]}]}}`,
+			format:   bootstrapv1.Ignition,
+			keys:     keys,
+			expected: `{"ignition":{"version":"3.1.0"},"passwd":{"users":[{"name":"core","sshAuthorizedKeys":["ssh-rsa AAAAB3 bootstrap","ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass"]}]}}`,
+		},
+		{
+			name:     "ignition without users",
+			data:     `{"ignition":{"version":"3.1.0"}}`,
+			format:   bootstrapv1.Ignition,
+			keys:     keys,
+			expected: `{"ignition":{"version":"3.1.0"},"passwd":{"users":[{"name":"core","sshAuthorizedKeys":["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBreakGlass break-glass"]}]}}`,
+		},
+		{
+			name:    "invalid cloud-config",
+			data:    "#cloud-config\n- users: [\n",
+			format:  bootstrapv1.CloudConfig,
+			keys:    keys,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addSSHAuthorizedKeys([]byte(tt.data), tt.format, tt.keys)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/cluster"
+)
+
+// vsanDatastoreType is the type of vSAN datastores.
+const vsanDatastoreType = "vsan"
+
+// reconcileStorageAffinity ensures the VM is kept on the hosts which store its data.
+// The storage policy of the VM must be a vSAN policy and its data stored on vSAN
+// datastores. The VM is added to the DRS VM group, which is affine to the host
+// group by a VM-Host rule.
+func (vms *VMService) reconcileStorageAffinity(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	affinity := virtualMachineCtx.VSphereVM.Spec.StorageAffinity
+	if affinity == nil {
+		log.V(5).Info("Storage affinity not defined. skipping reconcile storage affinity")
+		return true, nil
+	}
+
+	if err := vms.verifyStorageAffinity(ctx, virtualMachineCtx); err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.StorageAffinityFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, err
+	}
+
+	vmGroup, err := cluster.FindVMGroup(ctx, virtualMachineCtx, affinity.ComputeCluster, affinity.VMGroupName)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to find VM Group %s", affinity.VMGroupName)
+	}
+	hasVM, err := vmGroup.HasVM(virtualMachineCtx.Ref)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to find VM Group %s membership", affinity.VMGroupName)
+	}
+	if hasVM {
+		return true, nil
+	}
+
+	task, err := vmGroup.Add(ctx, virtualMachineCtx.Ref)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to add VM %s to VM group", virtualMachineCtx.VSphereVM.Name)
+	}
+	virtualMachineCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+	log.Info("Wait for VM to be added to group of storage affinity")
+	return false, nil
+}
+
+// verifyStorageAffinity returns an error if the storage policy of the VM is not a vSAN
+// policy, the VM is not stored on vSAN datastores or the VM-Host rule does not exist.
+func (vms *VMService) verifyStorageAffinity(ctx context.Context, virtualMachineCtx *virtualMachineContext) error {
+	affinity := virtualMachineCtx.VSphereVM.Spec.StorageAffinity
+	storagePolicyName := virtualMachineCtx.VSphereVM.Spec.StoragePolicyName
+
+	pbmClient, err := pbm.NewClient(ctx, virtualMachineCtx.Session.Client.Client)
+	if err != nil {
+		return errors.Wrap(err, "unable to create pbm client")
+	}
+	storageProfileID, err := pbmClient.ProfileIDByName(ctx, storagePolicyName)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve storage profile ID")
+	}
+	profiles, err := pbmClient.RetrieveContent(ctx, []pbmTypes.PbmProfileId{{UniqueId: storageProfileID}})
+	if err != nil {
+		return errors.Wrapf(err, "unable to retrieve storage policy %s", storagePolicyName)
+	}
+	if len(profiles) == 0 || !isVSANStoragePolicy(profiles[0]) {
+		return errors.Errorf("storage policy %s is not a vSAN storage policy", storagePolicyName)
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	if len(virtualMachine.Datastore) > 0 {
+		var datastores []mo.Datastore
+		pc := property.DefaultCollector(virtualMachineCtx.Session.Client.Client)
+		if err := pc.Retrieve(ctx, virtualMachine.Datastore, []string{"name", "summary.type"}, &datastores); err != nil {
+			return errors.Wrapf(err, "unable to get datastores of VM %s", virtualMachineCtx.VSphereVM.Name)
+		}
+		for _, datastore := range datastores {
+			if datastore.Summary.Type != vsanDatastoreType {
+				return errors.Errorf("datastore %s of VM %s is not a vSAN datastore", datastore.Name, virtualMachineCtx.VSphereVM.Name)
+			}
+		}
+	}
+
+	rule, err := cluster.VerifyAffinityRule(ctx, virtualMachineCtx, affinity.ComputeCluster, affinity.HostGroupName, affinity.VMGroupName)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find VM-Host rule of VM group %s and host group %s", affinity.VMGroupName, affinity.HostGroupName)
+	}
+	if rule.Disabled() {
+		return errors.Errorf("VM-Host rule of VM group %s and host group %s is disabled", affinity.VMGroupName, affinity.HostGroupName)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileStorageCompliance reports the storage policy compliance of the VM and its disks
+// by the StorageCompliantCondition. The compliance is only checked for VMs with a storage
+// policy if the storage compliance check interval is not zero. A failed check does not
+// block the reconcile, as the compliance is only reported.
+func (vms *VMService) reconcileStorageCompliance(ctx context.Context, virtualMachineCtx *virtualMachineContext) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if virtualMachineCtx.StorageComplianceCheckInterval == 0 || virtualMachineCtx.VSphereVM.Spec.StoragePolicyName == "" {
+		return
+	}
+
+	nonCompliant, err := vms.getNonCompliantStorageObjects(ctx, virtualMachineCtx)
+	if err != nil {
+		log.Error(err, "Failed to get storage policy compliance")
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.StorageCompliantCondition, infrav1.StorageComplianceCheckFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return
+	}
+	if len(nonCompliant) > 0 {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.StorageCompliantCondition, infrav1.StorageNonCompliantReason, clusterv1.ConditionSeverityWarning,
+			"not compliant with storage policy %s: %s", virtualMachineCtx.VSphereVM.Spec.StoragePolicyName, strings.Join(nonCompliant, ", "))
+		return
+	}
+	conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.StorageCompliantCondition)
+}
+
+// getNonCompliantStorageObjects returns the names of the VM and its disks which do not comply
+// with their storage policy, according to the last compliance check of vCenter.
+func (vms *VMService) getNonCompliantStorageObjects(ctx context.Context, virtualMachineCtx *virtualMachineContext) ([]string, error) {
+	devices, err := virtualMachineCtx.Obj.Device(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get devices of VM %s", virtualMachineCtx.VSphereVM.Name)
+	}
+
+	vmID := virtualMachineCtx.Obj.Reference().Value
+	names := map[string]string{vmID: "VM home"}
+	entities := []pbmTypes.PbmServerObjectRef{{
+		ObjectType: string(pbmTypes.PbmObjectTypeVirtualMachine),
+		Key:        vmID,
+	}}
+	for _, d := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		// entities associated with storage policy has key in the form <vm-ID>:<disk>
+		diskID := fmt.Sprintf("%s:%d", vmID, d.GetVirtualDevice().Key)
+		names[diskID] = devices.Name(d)
+		if info := d.GetVirtualDevice().DeviceInfo; info != nil && info.GetDescription().Label != "" {
+			names[diskID] = info.GetDescription().Label
+		}
+		entities = append(entities, pbmTypes.PbmServerObjectRef{
+			ObjectType: string(pbmTypes.PbmObjectTypeVirtualDiskId),
+			Key:        diskID,
+		})
+	}
+
+	pbmClient, err := pbm.NewClient(ctx, virtualMachineCtx.Session.Client.Client)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create pbm client")
+	}
+	results, err := pbmClient.FetchComplianceResult(ctx, entities)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch storage policy compliance")
+	}
+	return nonCompliantStorageObjects(results, names), nil
+}
+
+// nonCompliantStorageObjects returns the names of the entities of the compliance results which
+// are not compliant. Entities without a name are reported by their key.
+func nonCompliantStorageObjects(results []pbmTypes.PbmComplianceResult, names map[string]string) []string {
+	var nonCompliant []string
+	for _, result := range results {
+		if result.ComplianceStatus != string(pbmTypes.PbmComplianceStatusNonCompliant) {
+			continue
+		}
+		name, ok := names[result.Entity.Key]
+		if !ok {
+			name = result.Entity.Key
+		}
+		nonCompliant = append(nonCompliant, name)
+	}
+	return nonCompliant
+}
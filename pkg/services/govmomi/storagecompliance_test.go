@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_nonCompliantStorageObjects(t *testing.T) {
+	result := func(key string, status pbmTypes.PbmComplianceStatus) pbmTypes.PbmComplianceResult {
+		return pbmTypes.PbmComplianceResult{
+			Entity:           pbmTypes.PbmServerObjectRef{Key: key},
+			ComplianceStatus: string(status),
+		}
+	}
+	names := map[string]string{
+		"vm-1":      "VM home",
+		"vm-1:2000": "Hard disk 1",
+		"vm-1:2001": "Hard disk 2",
+	}
+
+	tests := []struct {
+		name     string
+		results  []pbmTypes.PbmComplianceResult
+		expected []string
+	}{
+		{
+			name: "all objects compliant",
+			results: []pbmTypes.PbmComplianceResult{
+				result("vm-1", pbmTypes.PbmComplianceStatusCompliant),
+				result("vm-1:2000", pbmTypes.PbmComplianceStatusCompliant),
+			},
+		},
+		{
+			name: "objects without known compliance",
+			results: []pbmTypes.PbmComplianceResult{
+				result("vm-1", pbmTypes.PbmComplianceStatusUnknown),
+				result("vm-1:2000", pbmTypes.PbmComplianceStatusNotApplicable),
+			},
+		},
+		{
+			name: "non-compliant disks",
+			results: []pbmTypes.PbmComplianceResult{
+				result("vm-1", pbmTypes.PbmComplianceStatusCompliant),
+				result("vm-1:2000", pbmTypes.PbmComplianceStatusNonCompliant),
+				result("vm-1:2001", pbmTypes.PbmComplianceStatusNonCompliant),
+			},
+			expected: []string{"Hard disk 1", "Hard disk 2"},
+		},
+		{
+			name: "non-compliant object without name",
+			results: []pbmTypes.PbmComplianceResult{
+				result("vm-1:3000", pbmTypes.PbmComplianceStatusNonCompliant),
+			},
+			expected: []string{"vm-1:3000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(nonCompliantStorageObjects(tt.results, names)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func Test_reconcileStorageCompliance(t *testing.T) {
+	t.Run("when the check is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.VSphereVM = &infrav1.VSphereVM{Spec: infrav1.VSphereVMSpec{
+			VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{StoragePolicyName: "vSAN Default Storage Policy"},
+		}}
+
+		(&VMService{}).reconcileStorageCompliance(context.Background(), vmCtx)
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.StorageCompliantCondition)).To(BeFalse())
+	})
+
+	t.Run("when the VM has no storage policy", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := emptyVirtualMachineContext()
+		vmCtx.StorageComplianceCheckInterval = time.Minute
+		vmCtx.VSphereVM = &infrav1.VSphereVM{}
+
+		(&VMService{}).reconcileStorageCompliance(context.Background(), vmCtx)
+		g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.StorageCompliantCondition)).To(BeFalse())
+	})
+}
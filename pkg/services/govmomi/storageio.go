@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/vcenter"
+)
+
+// reconcileDiskStorageIOAllocation ensures the Storage I/O Control shares and IOPS limit of
+// the disks of the VM match the ones defined in the spec. Storage I/O Control must be enabled
+// on the datastores of the disks which are updated.
+func (vms *VMService) reconcileDiskStorageIOAllocation(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	allocation := virtualMachineCtx.VSphereVM.Spec.DiskStorageIOAllocation
+	if allocation == nil {
+		log.V(5).Info("Disk Storage I/O allocation not defined. skipping reconcile disk Storage I/O allocation")
+		return true, nil
+	}
+
+	virtualMachine := &virtualMachineCtx.Properties
+	var devices object.VirtualDeviceList
+	if virtualMachine.Config != nil {
+		devices = virtualMachine.Config.Hardware.Device
+	}
+
+	desired := vcenter.StorageIOAllocationInfo(allocation)
+	var deviceChange []types.BaseVirtualDeviceConfigSpec
+	var changes []string
+	var datastores []types.ManagedObjectReference
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+		if storageIOAllocationMatches(disk.StorageIOAllocation, desired) {
+			continue
+		}
+		if backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			if datastore := backing.GetVirtualDeviceFileBackingInfo().Datastore; datastore != nil && !slices.Contains(datastores, *datastore) {
+				datastores = append(datastores, *datastore)
+			}
+		}
+		disk.StorageIOAllocation = desired
+		deviceChange = append(deviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    disk,
+		})
+		changes = append(changes, fmt.Sprintf("diskStorageIOAllocation %s", devices.Name(disk)))
+	}
+	if len(deviceChange) == 0 {
+		return true, nil
+	}
+	virtualMachineCtx.ResourceDrift = append(virtualMachineCtx.ResourceDrift, changes...)
+
+	if virtualMachineCtx.VSphereVM.Spec.ResourceDriftPolicy == infrav1.ResourceDriftPolicyReport {
+		log.Info("VM disk Storage I/O allocation drifted from spec", "changes", changes)
+		return true, nil
+	}
+
+	if len(datastores) > 0 {
+		if err := vcenter.CheckStorageIOControlEnabled(ctx, virtualMachineCtx.Session.Client.Client, datastores); err != nil {
+			conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.StorageIOControlDisabledReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return false, err
+		}
+	}
+
+	log.Info("Updating VM disk Storage I/O allocation", "changes", changes)
+	virtualMachineCtx.ConfigChange.add(types.VirtualMachineConfigSpec{
+		DeviceChange: deviceChange,
+	}, changes...)
+	return true, nil
+}
+
+// storageIOAllocationMatches returns true if the current Storage I/O allocation of a disk
+// has the desired shares and limit.
+func storageIOAllocationMatches(current, desired *types.StorageIOAllocationInfo) bool {
+	if current == nil {
+		current = &types.StorageIOAllocationInfo{}
+	}
+	if desired.Limit != nil && *desired.Limit != ptr.Deref(current.Limit, -1) {
+		return false
+	}
+	return desired.Shares == nil || sharesMatch(current.Shares, desired.Shares)
+}
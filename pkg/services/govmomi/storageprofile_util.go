@@ -30,3 +30,23 @@ func isStoragePolicyIDPresent(storageProfileID string, result pbmTypes.PbmQueryP
 	}
 	return false
 }
+
+// isVSANStoragePolicy returns true if the storage policy defines vSAN capabilities.
+func isVSANStoragePolicy(profile pbmTypes.BasePbmProfile) bool {
+	capabilityProfile, ok := profile.(*pbmTypes.PbmCapabilityProfile)
+	if !ok {
+		return false
+	}
+	constraints, ok := capabilityProfile.Constraints.(*pbmTypes.PbmCapabilitySubProfileConstraints)
+	if !ok {
+		return false
+	}
+	for _, subProfile := range constraints.SubProfiles {
+		for _, capability := range subProfile.Capability {
+			if capability.Id.Namespace == "VSAN" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+// importLocks serializes the imports of a template, so machines which are
+// created concurrently do not import the same OVA multiple times.
+var importLocks sync.Map
+
+// ImportOVA imports the OVA described by source as a template with the given name
+// into the given folder, resource pool and datastore. If a template with the name
+// exists already, it is returned instead.
+func ImportOVA(ctx context.Context, s *session.Session, name string, source infrav1.OVASource, folder *object.Folder, pool *object.ResourcePool, datastore *object.Datastore) (*object.VirtualMachine, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	lock, _ := importLocks.LoadOrStore(name, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// The template may have been imported while waiting for the lock.
+	if tpl, err := s.Finder.VirtualMachine(ctx, name); err == nil {
+		return tpl, nil
+	}
+
+	log.Info("Downloading OVA", "url", source.URL)
+	ova, err := downloadOVA(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = ova.Close()
+		_ = os.Remove(ova.Name())
+	}()
+
+	descriptor, err := readOVAFile(ova, func(name string) bool { return path.Ext(name) == ".ovf" })
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read OVF descriptor from OVA %s", source.URL)
+	}
+
+	spec, err := ovf.NewManager(s.Client.Client).CreateImportSpec(ctx, string(descriptor), pool, datastore, types.OvfCreateImportSpecParams{
+		EntityName: path.Base(name),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create import spec for OVA %s", source.URL)
+	}
+	if len(spec.Error) > 0 {
+		return nil, errors.Errorf("unable to create import spec for OVA %s: %s", source.URL, spec.Error[0].LocalizedMessage)
+	}
+
+	log.Info("Importing OVA", "url", source.URL, "template", name)
+	lease, err := pool.ImportVApp(ctx, spec.ImportSpec, folder, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to import OVA %s", source.URL)
+	}
+	info, err := lease.Wait(ctx, spec.FileItem)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to import OVA %s", source.URL)
+	}
+
+	updater := lease.StartUpdater(ctx, info)
+	for _, item := range info.Items {
+		if err := uploadOVAFile(ctx, ova, lease, item); err != nil {
+			updater.Done()
+			_ = lease.Abort(ctx, nil)
+			return nil, errors.Wrapf(err, "unable to upload %s of OVA %s", item.Path, source.URL)
+		}
+	}
+	updater.Done()
+	if err := lease.Complete(ctx); err != nil {
+		return nil, errors.Wrapf(err, "unable to complete import of OVA %s", source.URL)
+	}
+
+	tpl := object.NewVirtualMachine(s.Client.Client, info.Entity)
+	if err := tpl.MarkAsTemplate(ctx); err != nil {
+		return nil, errors.Wrapf(err, "unable to mark imported VM %s as template", name)
+	}
+	return tpl, nil
+}
+
+// downloadOVA downloads the OVA to a temporary file and verifies its checksum.
+func downloadOVA(ctx context.Context, source infrav1.OVASource) (*os.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid OVA URL %s", source.URL)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to download OVA %s", source.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unable to download OVA %s: %s", source.URL, resp.Status)
+	}
+
+	f, err := os.CreateTemp("", "capv-*.ova")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temporary file for OVA")
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, errors.Wrapf(err, "unable to download OVA %s", source.URL)
+	}
+
+	if source.Checksum != "" {
+		if checksum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(checksum, source.Checksum) {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+			return nil, errors.Errorf("checksum mismatch for OVA %s: expected %s, got %s", source.URL, source.Checksum, checksum)
+		}
+	}
+	return f, nil
+}
+
+// findOVAFile positions a tar reader of the OVA at the first file matching the given function.
+func findOVAFile(ova *os.File, match func(name string) bool) (*tar.Reader, *tar.Header, error) {
+	if _, err := ova.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	r := tar.NewReader(ova)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return nil, nil, errors.New("file not found in OVA")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if match(path.Clean(header.Name)) {
+			return r, header, nil
+		}
+	}
+}
+
+// readOVAFile returns the content of the first file of the OVA matching the given function.
+func readOVAFile(ova *os.File, match func(name string) bool) ([]byte, error) {
+	r, _, err := findOVAFile(ova, match)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// uploadOVAFile uploads the file of the OVA referenced by the given lease item.
+func uploadOVAFile(ctx context.Context, ova *os.File, lease *nfc.Lease, item nfc.FileItem) error {
+	r, header, err := findOVAFile(ova, func(name string) bool { return name == path.Clean(item.Path) })
+	if err != nil {
+		return err
+	}
+	return lease.Upload(ctx, item, r, soap.Upload{ContentLength: header.Size})
+}
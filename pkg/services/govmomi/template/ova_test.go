@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	_ "github.com/vmware/govmomi/vapi/simulator" // run init func to register the tagging API endpoints.
+	"github.com/vmware/govmomi/vim25/mo"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+const testOVF = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1"
+          xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1"
+          xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData"
+          xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData">
+  <References/>
+  <VirtualSystem ovf:id="vm">
+    <Info>A virtual machine</Info>
+    <Name>test</Name>
+    <VirtualHardwareSection>
+      <Info>Virtual hardware requirements</Info>
+      <System>
+        <vssd:ElementName>Virtual Hardware Family</vssd:ElementName>
+        <vssd:InstanceID>0</vssd:InstanceID>
+        <vssd:VirtualSystemType>vmx-13</vssd:VirtualSystemType>
+      </System>
+      <Item>
+        <rasd:AllocationUnits>hertz * 10^6</rasd:AllocationUnits>
+        <rasd:ElementName>1 virtual CPU(s)</rasd:ElementName>
+        <rasd:InstanceID>1</rasd:InstanceID>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>1</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>byte * 2^20</rasd:AllocationUnits>
+        <rasd:ElementName>32MB of memory</rasd:ElementName>
+        <rasd:InstanceID>2</rasd:InstanceID>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>32</rasd:VirtualQuantity>
+      </Item>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>
+`
+
+func TestImportOVA(t *testing.T) {
+	ova := newTestOVA(t)
+	sum := sha256.Sum256(ova)
+	checksum := hex.EncodeToString(sum[:])
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write(ova)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		source  infrav1.OVASource
+		wantErr string
+	}{
+		{
+			name:   "without checksum",
+			source: infrav1.OVASource{URL: server.URL},
+		},
+		{
+			name:   "with matching checksum",
+			source: infrav1.OVASource{URL: server.URL, Checksum: checksum},
+		},
+		{
+			name:    "with mismatching checksum",
+			source:  infrav1.OVASource{URL: server.URL, Checksum: hex.EncodeToString(make([]byte, sha256.Size))},
+			wantErr: "checksum mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			downloads.Store(0)
+
+			model, s, simr := initSimulator(t)
+			t.Cleanup(model.Remove)
+			t.Cleanup(simr.Close)
+
+			ctx := context.Background()
+			folder, err := s.Finder.FolderOrDefault(ctx, "")
+			g.Expect(err).ToNot(HaveOccurred())
+			pool, err := s.Finder.ResourcePoolOrDefault(ctx, "/DC0/host/DC0_C0/Resources")
+			g.Expect(err).ToNot(HaveOccurred())
+			datastore, err := s.Finder.DatastoreOrDefault(ctx, "")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			tpl, err := ImportOVA(ctx, s, "ova-template", tt.source, folder, pool, datastore)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				_, err = s.Finder.VirtualMachine(ctx, "ova-template")
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var obj mo.VirtualMachine
+			g.Expect(tpl.Properties(ctx, tpl.Reference(), []string{"config.template"}, &obj)).To(Succeed())
+			g.Expect(obj.Config.Template).To(BeTrue())
+
+			// The template is not imported again once it exists.
+			_, err = ImportOVA(ctx, s, "ova-template", tt.source, folder, pool, datastore)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(downloads.Load()).To(Equal(int32(1)))
+		})
+	}
+}
+
+func newTestOVA(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	if err := w.WriteHeader(&tar.Header{Name: "test.ovf", Mode: 0o600, Size: int64(len(testOVF))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(testOVF)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func initSimulator(t *testing.T) (*simulator.Model, *session.Session, *simulator.Server) {
+	t.Helper()
+
+	model := simulator.VPX()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	model.Service.RegisterEndpoints = true
+
+	server := model.Service.NewServer()
+	pass, _ := server.URL.User.Password()
+
+	authSession, err := session.GetOrCreate(
+		context.TODO(),
+		session.NewParams().
+			WithServer(server.URL.Host).
+			WithUserInfo(server.URL.User.Username(), pass).
+			WithDatacenter("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return model, authSession, server
+}
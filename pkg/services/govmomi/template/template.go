@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -40,6 +41,29 @@ func FindTemplate(ctx context.Context, session *session.Session, templateID stri
 	return findTemplateByName(ctx, session, templateID)
 }
 
+// FindTemplateWithFallbacks finds the first of the template and its fallback
+// templates which exists. It returns the template found together with its name.
+func FindTemplateWithFallbacks(ctx context.Context, session *session.Session, templateID string, fallbackIDs []string) (*object.VirtualMachine, string, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	tpl, err := FindTemplate(ctx, session, templateID)
+	if err == nil || len(fallbackIDs) == 0 || !isNotFound(err) {
+		return tpl, templateID, err
+	}
+
+	for _, fallbackID := range fallbackIDs {
+		fallback, fallbackErr := FindTemplate(ctx, session, fallbackID)
+		if fallbackErr == nil {
+			log.Info("Template not found, using fallback template", "template", templateID, "fallbackTemplate", fallbackID)
+			return fallback, fallbackID, nil
+		}
+		if !isNotFound(fallbackErr) {
+			return nil, fallbackID, fallbackErr
+		}
+	}
+	return nil, templateID, errors.Wrapf(err, "none of the fallback templates %v found", fallbackIDs)
+}
+
 func findTemplateByInstanceUUID(ctx context.Context, session *session.Session, templateID string) (*object.VirtualMachine, error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -67,6 +91,12 @@ func findTemplateByName(ctx context.Context, session *session.Session, templateI
 	return tpl, nil
 }
 
+// isNotFound returns true if the template was not found.
+func isNotFound(err error) bool {
+	var notFound *find.NotFoundError
+	return errors.As(err, &notFound)
+}
+
 func isValidUUID(str string) bool {
 	_, err := uuid.Parse(str)
 	return err == nil
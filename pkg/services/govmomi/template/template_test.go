@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFindTemplateWithFallbacks(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name             string
+		template         string
+		fallbacks        []string
+		expectedTemplate string
+		wantErr          bool
+	}{
+		{
+			name:             "when the template exists",
+			template:         "DC0_H0_VM0",
+			fallbacks:        []string{"DC0_H0_VM1"},
+			expectedTemplate: "DC0_H0_VM0",
+		},
+		{
+			name:             "when the template is missing",
+			template:         "missing",
+			fallbacks:        []string{"also-missing", "DC0_H0_VM1", "DC0_H0_VM0"},
+			expectedTemplate: "DC0_H0_VM1",
+		},
+		{
+			name:     "when the template is missing without fallbacks",
+			template: "missing",
+			wantErr:  true,
+		},
+		{
+			name:      "when all templates are missing",
+			template:  "missing",
+			fallbacks: []string{"also-missing"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			tpl, name, err := FindTemplateWithFallbacks(ctx, session, tt.template, tt.fallbacks)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(isNotFound(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(name).To(Equal(tt.expectedTemplate))
+			tplName, err := tpl.ObjectName(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(tplName).To(Equal(tt.expectedTemplate))
+		})
+	}
+}
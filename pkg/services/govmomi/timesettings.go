@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+const (
+	// ignitionLocalTimePath is the path of the link to the time zone file of the guest.
+	ignitionLocalTimePath = "/etc/localtime"
+
+	// ignitionTimesyncdConfigPath is the path of the systemd-timesyncd configuration
+	// file which defines the NTP servers of the guest.
+	ignitionTimesyncdConfigPath = "/etc/systemd/timesyncd.conf.d/capv.conf"
+)
+
+// addTimeSettings adds the time zone and NTP servers to the bootstrap data.
+// The settings of the bootstrap data are left unchanged if neither is set.
+func addTimeSettings(data []byte, format bootstrapv1.Format, os infrav1.OS, timeZone string, ntpServers []string) ([]byte, error) {
+	if (timeZone == "" && len(ntpServers) == 0) || len(data) == 0 {
+		return data, nil
+	}
+
+	switch format {
+	case bootstrapv1.CloudConfig:
+		if os == infrav1.Windows {
+			return addCloudbaseInitTimeSettings(data, timeZone, ntpServers)
+		}
+		return addCloudConfigTimeSettings(data, timeZone, ntpServers)
+	case bootstrapv1.Ignition:
+		return addIgnitionTimeSettings(data, timeZone, ntpServers)
+	default:
+		return nil, errors.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// addCloudConfigTimeSettings configures the timezone and ntp modules of cloud-init.
+func addCloudConfigTimeSettings(data []byte, timeZone string, ntpServers []string) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeZone != "" {
+		config["timezone"] = timeZone
+	}
+	if len(ntpServers) > 0 {
+		ntp, _ := config["ntp"].(map[string]interface{})
+		if ntp == nil {
+			ntp = map[string]interface{}{}
+			config["ntp"] = ntp
+		}
+		ntp["enabled"] = true
+		ntp["servers"] = ntpServers
+	}
+
+	return marshalCloudConfig(header, config)
+}
+
+// addCloudbaseInitTimeSettings configures the time zone and NTP servers of Windows
+// guests, which are bootstrapped by cloudbase-init.
+func addCloudbaseInitTimeSettings(data []byte, timeZone string, ntpServers []string) ([]byte, error) {
+	header, config, err := unmarshalCloudConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeZone != "" {
+		config["set_timezone"] = timeZone
+	}
+	if len(ntpServers) > 0 {
+		runcmd, _ := config["runcmd"].([]interface{})
+		config["runcmd"] = append(runcmd,
+			fmt.Sprintf(`w32tm /config /manualpeerlist:"%s" /syncfromflags:manual /update`, strings.Join(ntpServers, " ")),
+			"w32tm /resync /force",
+		)
+	}
+
+	return marshalCloudConfig(header, config)
+}
+
+// addIgnitionTimeSettings links the time zone file of the guest and configures the
+// NTP servers of systemd-timesyncd.
+func addIgnitionTimeSettings(data []byte, timeZone string, ntpServers []string) ([]byte, error) {
+	config, err := unmarshalIgnitionConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	node := func(p string) map[string]interface{} {
+		n := map[string]interface{}{"path": p, "overwrite": true}
+		if isIgnitionV2(config) {
+			n["filesystem"] = "root"
+		}
+		return n
+	}
+
+	storage, _ := config["storage"].(map[string]interface{})
+	if storage == nil {
+		storage = map[string]interface{}{}
+		config["storage"] = storage
+	}
+	if timeZone != "" {
+		link := node(ignitionLocalTimePath)
+		link["target"] = path.Join("/usr/share/zoneinfo", timeZone)
+		links, _ := storage["links"].([]interface{})
+		storage["links"] = append(links, link)
+	}
+	if len(ntpServers) > 0 {
+		file := node(ignitionTimesyncdConfigPath)
+		file["mode"] = 0o644
+		file["contents"] = map[string]interface{}{
+			"source": "data:," + url.PathEscape(fmt.Sprintf("[Time]\nNTP=%s\n", strings.Join(ntpServers, " "))),
+		}
+		files, _ := storage["files"].([]interface{})
+		storage["files"] = append(files, file)
+	}
+
+	return marshalIgnitionConfig(config)
+}
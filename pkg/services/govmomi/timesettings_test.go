@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+func Test_addTimeSettings(t *testing.T) {
+	ntpServers := []string{"0.pool.ntp.org", "1.pool.ntp.org"}
+
+	tests := []struct {
+		name       string
+		data       string
+		format     bootstrapv1.Format
+		os         infrav1.OS
+		timeZone   string
+		ntpServers []string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:     "without time settings",
+			data:     "#cloud-config\nruncmd:\n- echo\n",
+			format:   bootstrapv1.CloudConfig,
+			expected: "#cloud-config\nruncmd:\n- echo\n",
+		},
+		{
+			name:       "cloud-config",
+			data:       "#cloud-config\nntp:\n  ntp_client: chrony\n",
+			format:     bootstrapv1.CloudConfig,
+			timeZone:   "Europe/Berlin",
+			ntpServers: ntpServers,
+			expected:   "#cloud-config\nntp:\n  enabled: true\n  ntp_client: chrony\n  servers:\n  - 0.pool.ntp.org\n  - 1.pool.ntp.org\ntimezone: Europe/Berlin\n",
+		},
+		{
+			name:       "cloud-config of Windows",
+			data:       "#cloud-config\nruncmd:\n- echo\n",
+			format:     bootstrapv1.CloudConfig,
+			os:         infrav1.Windows,
+			timeZone:   "Europe/Berlin",
+			ntpServers: ntpServers,
+			expected: "#cloud-config\nruncmd:\n- echo\n" +
+				"- w32tm /config /manualpeerlist:\"0.pool.ntp.org 1.pool.ntp.org\" /syncfromflags:manual\n  /update\n" +
+				"- w32tm /resync /force\nset_timezone: Europe/Berlin\n",
+		},
+		{
+			name:       "ignition v3",
+			data:       `{"ignition":{"version":"3.1.0"}}`,
+			format:     bootstrapv1.Ignition,
+			timeZone:   "Europe/Berlin",
+			ntpServers: ntpServers,
+			expected: `{"ignition":{"version":"3.1.0"},"storage":{` +
+				`"files":[{"contents":{"source":"data:,%5BTime%5D%0ANTP=0.pool.ntp.org%201.pool.ntp.org%0A"},"mode":420,"overwrite":true,"path":"/etc/systemd/timesyncd.conf.d/capv.conf"}],` +
+				`"links":[{"overwrite":true,"path":"/etc/localtime","target":"/usr/share/zoneinfo/Europe/Berlin"}]}}`,
+		},
+		{
+			name:     "ignition v2",
+			data:     `{"ignition":{"version":"2.3.0"},"storage":{"files":[{"filesystem":"root","path":"/etc/hostname"}]}}`,
+			format:   bootstrapv1.Ignition,
+			timeZone: "UTC",
+			expected: `{"ignition":{"version":"2.3.0"},"storage":{"files":[{"filesystem":"root","path":"/etc/hostname"}],` +
+				`"links":[{"filesystem":"root","overwrite":true,"path":"/etc/localtime","target":"/usr/share/zoneinfo/UTC"}]}}`,
+		},
+		{
+			name:     "invalid ignition",
+			data:     `{"ignition":`,
+			format:   bootstrapv1.Ignition,
+			timeZone: "UTC",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			out, err := addTimeSettings([]byte(tt.data), tt.format, tt.os, tt.timeZone, tt.ntpServers)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(out)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -18,8 +18,10 @@ package govmomi
 
 import (
 	"context"
+	"fmt"
 	gonet "net"
 	"path"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +38,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/net"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
 func sanitizeIPAddrs(ctx context.Context, ipAddrs []string) []string {
@@ -84,11 +87,11 @@ func findVM(ctx context.Context, vmCtx *capvcontext.VMContext) (types.ManagedObj
 	}
 	if objRef == nil {
 		// fallback to use inventory paths
-		folder, err := vmCtx.Session.Finder.FolderOrDefault(ctx, vmCtx.VSphereVM.Spec.Folder)
+		folder, err := vmCtx.Session.FolderOrDefault(ctx, vmCtx.VSphereVM.Spec.Folder)
 		if err != nil {
 			return types.ManagedObjectReference{}, err
 		}
-		inventoryPath := path.Join(folder.InventoryPath, vmCtx.VSphereVM.Name)
+		inventoryPath := path.Join(folder.InventoryPath, util.GetVMName(vmCtx.VSphereVM))
 		log.Info("Using inventory path to find VM", "inventoryPath", inventoryPath)
 		vm, err := vmCtx.Session.Finder.VirtualMachine(ctx, inventoryPath)
 		if err != nil {
@@ -120,6 +123,9 @@ func getTask(ctx context.Context, vmCtx *capvcontext.VMContext) *mo.Task {
 	return &obj
 }
 
+// cloneTaskDescriptionID is the description ID of tasks cloning a VM.
+const cloneTaskDescriptionID = "VirtualMachine.clone"
+
 // reconcileInFlightTask determines if a task associated to the VSphereVM object
 // is in flight or not.
 func reconcileInFlightTask(ctx context.Context, vmCtx *capvcontext.VMContext) (bool, error) {
@@ -155,6 +161,9 @@ func checkAndRetryTask(ctx context.Context, vmCtx *capvcontext.VMContext, task *
 		return true, nil
 	case types.TaskInfoStateRunning:
 		log.Info("Task found: Task is still running")
+		if task.Info.DescriptionId == "VirtualMachine.relocate" {
+			reportRelocationProgress(vmCtx.VSphereVM, task.Info.Progress)
+		}
 		return true, nil
 	case types.TaskInfoStateSuccess:
 		log.Info("Task found: Task is a success")
@@ -169,6 +178,29 @@ func checkAndRetryTask(ctx context.Context, vmCtx *capvcontext.VMContext, task *
 
 		if task.Info.Error != nil {
 			errorMessage = task.Info.Error.LocalizedMessage
+			// Stop serving the object from the inventory cache if it was removed from vCenter.
+			if fault, ok := task.Info.Error.Fault.(*types.ManagedObjectNotFound); ok && vmCtx.Session != nil {
+				vmCtx.Session.InvalidateInventoryObject(fault.Obj)
+			}
+			// Only the clone places the VM, so the datastore or host of an existing VM, e.g.
+			// running out of space or entering maintenance mode while reconfiguring or powering
+			// on the VM, is not excluded.
+			isClone := task.Info.DescriptionId == cloneTaskDescriptionID
+			if isClone && isDatastoreFullFault(task.Info.Error.Fault) {
+				return checkAndRetryDatastoreFull(ctx, vmCtx, task.Info.Error), nil
+			}
+			if isClone && isHostUnavailableFault(task.Info.Error.Fault) {
+				return checkAndRetryHostUnavailable(ctx, vmCtx, task.Info.Error), nil
+			}
+			// A reconfigure rejected as the VM was changed concurrently is retried right away,
+			// as the drift is detected again with the fresh state of the VM.
+			if _, ok := task.Info.Error.Fault.(*types.ConcurrentAccess); ok {
+				log.Info("VM was changed concurrently, retrying reconfigure")
+				conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition, infrav1.ConcurrentModificationReason, clusterv1.ConditionSeverityInfo, errorMessage)
+				vmCtx.VSphereVM.Status.TaskRef = ""
+				vmCtx.VSphereVM.Status.RetryAfter = metav1.Time{}
+				return false, nil
+			}
 		}
 		conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.TaskFailure, clusterv1.ConditionSeverityInfo, errorMessage)
 
@@ -186,6 +218,100 @@ func checkAndRetryTask(ctx context.Context, vmCtx *capvcontext.VMContext, task *
 	}
 }
 
+// isDatastoreFullFault returns true if the fault reports a datastore running out of space.
+func isDatastoreFullFault(fault types.BaseMethodFault) bool {
+	switch fault.(type) {
+	case *types.NoDiskSpace, *types.InsufficientStorageSpace:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAndRetryDatastoreFull handles a task which failed because the datastore of the VM ran
+// out of space. If the VM may be placed on another datastore compatible with its storage policy,
+// the datastore is excluded and the task is retried right away, otherwise the task is retried
+// after a back off. It returns true if the task is not retried yet.
+func checkAndRetryDatastoreFull(ctx context.Context, vmCtx *capvcontext.VMContext, taskError *types.LocalizedMethodFault) bool {
+	log := ctrl.LoggerFrom(ctx)
+
+	var datastore string
+	message := taskError.LocalizedMessage
+	if fault, ok := taskError.Fault.(*types.NoDiskSpace); ok {
+		datastore = fault.Datastore
+		message = fmt.Sprintf("datastore %s ran out of space: %s", datastore, taskError.LocalizedMessage)
+	}
+	conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.DatastoreFullReason, clusterv1.ConditionSeverityWarning, message)
+
+	if datastore != "" && vmCtx.VSphereVM.Spec.StoragePolicyName != "" && vmCtx.VSphereVM.Spec.Datastore == "" {
+		if !slices.Contains(vmCtx.VSphereVM.Status.ExcludedDatastores, datastore) {
+			vmCtx.VSphereVM.Status.ExcludedDatastores = append(vmCtx.VSphereVM.Status.ExcludedDatastores, datastore)
+		}
+		log.Info("Datastore ran out of space, retrying on another datastore", "datastore", datastore)
+		vmCtx.VSphereVM.Status.TaskRef = ""
+		vmCtx.VSphereVM.Status.RetryAfter = metav1.Time{}
+		return false
+	}
+
+	log.Info("Datastore ran out of space, retrying later", "datastore", datastore)
+	if vmCtx.VSphereVM.Status.RetryAfter.IsZero() {
+		vmCtx.VSphereVM.Status.RetryAfter = metav1.Time{Time: time.Now().Add(datastoreFullRetryInterval)}
+	} else {
+		vmCtx.VSphereVM.Status.TaskRef = ""
+		vmCtx.VSphereVM.Status.RetryAfter = metav1.Time{}
+	}
+	return true
+}
+
+// isHostUnavailableFault returns true if the fault reports the host of the VM entering
+// maintenance mode or disconnecting.
+func isHostUnavailableFault(fault types.BaseMethodFault) bool {
+	switch fault.(type) {
+	case *types.InvalidHostState, *types.InvalidHostConnectionState, *types.HostNotConnected:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAndRetryHostUnavailable handles a task which failed because the host of the VM entered
+// maintenance mode or disconnected. The host is excluded from the placement of the VM and the
+// task is retried right away, as DRS places the VM on another host of the resource pool.
+func checkAndRetryHostUnavailable(ctx context.Context, vmCtx *capvcontext.VMContext, taskError *types.LocalizedMethodFault) bool {
+	log := ctrl.LoggerFrom(ctx)
+
+	var hostRef *types.ManagedObjectReference
+	switch fault := taskError.Fault.(type) {
+	case *types.InvalidHostState:
+		hostRef = fault.Host
+	case *types.InvalidHostConnectionState:
+		hostRef = fault.Host
+	}
+
+	var host string
+	if hostRef != nil && vmCtx.Session != nil {
+		name, err := object.NewHostSystem(vmCtx.Session.Client.Client, *hostRef).ObjectName(ctx)
+		if err != nil {
+			log.Error(err, "Failed to get name of unavailable host", "hostRef", hostRef.Value)
+		}
+		host = name
+	}
+
+	message := taskError.LocalizedMessage
+	if host != "" {
+		if !slices.Contains(vmCtx.VSphereVM.Status.ExcludedHosts, host) {
+			vmCtx.VSphereVM.Status.ExcludedHosts = append(vmCtx.VSphereVM.Status.ExcludedHosts, host)
+		}
+		message = fmt.Sprintf("host %s is unavailable, retrying on another host: %s", host, taskError.LocalizedMessage)
+	}
+	conditions.MarkFalse(vmCtx.VSphereVM, infrav1.VMProvisionedCondition, infrav1.HostUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+
+	log.Info("Host is unavailable, retrying on another host", "host", host)
+	vmCtx.VSphereVM.Status.TaskRef = ""
+	vmCtx.VSphereVM.Status.RetryAfter = metav1.Time{}
+	return false
+}
+
 func reconcileVSphereVMWhenNetworkIsReady(ctx context.Context, virtualMachineCtx *virtualMachineContext, powerOnTask *object.Task) {
 	reconcileVSphereVMOnChannel(
 		ctx,
@@ -23,6 +23,10 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,6 +34,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
 )
 
 func Test_ShouldRetryTask(t *testing.T) {
@@ -110,6 +115,27 @@ func Test_ShouldRetryTask(t *testing.T) {
 		})
 	})
 
+	t.Run("when a reconfigure is rejected as the VM was changed concurrently", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{
+				TaskRef: "task-123",
+			}},
+		}
+		task := baseTask(types.TaskInfoStateError, "")
+		task.Info.Error = &types.LocalizedMethodFault{
+			Fault:            &types.ConcurrentAccess{},
+			LocalizedMessage: "Cannot complete operation due to concurrent modification by another operation.",
+		}
+
+		reconciled, err := checkAndRetryTask(ctx, vmCtx, &task)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reconciled).To(BeFalse())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		g.Expect(vmCtx.VSphereVM.Status.RetryAfter.IsZero()).To(BeTrue())
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMReconfiguredCondition)).To(Equal(infrav1.ConcurrentModificationReason))
+	})
+
 	t.Run("when failed task was previously not checked", func(t *testing.T) {
 		g := NewWithT(t)
 		vmCtx := &capvcontext.VMContext{
@@ -128,6 +154,166 @@ func Test_ShouldRetryTask(t *testing.T) {
 	})
 }
 
+func Test_checkAndRetryDatastoreFull(t *testing.T) {
+	ctx := context.Background()
+
+	fullDatastoreTask := func() mo.Task {
+		task := baseTask(types.TaskInfoStateError, "clone failed")
+		task.Info.DescriptionId = cloneTaskDescriptionID
+		task.Info.Error = &types.LocalizedMethodFault{
+			Fault:            &types.NoDiskSpace{Datastore: "LocalDS_0"},
+			LocalizedMessage: "Insufficient disk space on datastore 'LocalDS_0'.",
+		}
+		return task
+	}
+
+	t.Run("when the VM may be placed on another datastore", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{StoragePolicyName: "vSAN Default Storage Policy"},
+				},
+				Status: infrav1.VSphereVMStatus{TaskRef: "task-123"},
+			},
+		}
+		task := fullDatastoreTask()
+
+		inFlight, err := checkAndRetryTask(ctx, vmCtx, &task)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inFlight).To(BeFalse())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		g.Expect(vmCtx.VSphereVM.Status.RetryAfter.IsZero()).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.ExcludedDatastores).To(ConsistOf("LocalDS_0"))
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.DatastoreFullReason))
+		g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(ContainSubstring("datastore LocalDS_0 ran out of space"))
+	})
+
+	t.Run("when the VM is placed on a single datastore", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{Datastore: "LocalDS_0"},
+				},
+				Status: infrav1.VSphereVMStatus{TaskRef: "task-123"},
+			},
+		}
+		task := fullDatastoreTask()
+
+		inFlight, err := checkAndRetryTask(ctx, vmCtx, &task)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inFlight).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).NotTo(BeEmpty())
+		g.Expect(vmCtx.VSphereVM.Status.RetryAfter.Time).To(BeTemporally("~", time.Now().Add(datastoreFullRetryInterval), time.Minute))
+		g.Expect(vmCtx.VSphereVM.Status.ExcludedDatastores).To(BeEmpty())
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.DatastoreFullReason))
+	})
+
+	t.Run("when a task other than the clone fails", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{StoragePolicyName: "vSAN Default Storage Policy"},
+				},
+				Status: infrav1.VSphereVMStatus{TaskRef: "task-123", ExcludedDatastores: []string{"LocalDS_1"}},
+			},
+		}
+		task := fullDatastoreTask()
+		task.Info.DescriptionId = "VirtualMachine.reconfigure"
+
+		inFlight, err := checkAndRetryTask(ctx, vmCtx, &task)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inFlight).To(BeTrue())
+		g.Expect(vmCtx.VSphereVM.Status.ExcludedDatastores).To(ConsistOf("LocalDS_1"))
+		g.Expect(vmCtx.VSphereVM.Status.RetryAfter.Time).To(BeTemporally("~", time.Now().Add(time.Minute), time.Minute))
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.TaskFailure))
+	})
+}
+
+func Test_checkAndRetryHostUnavailable(t *testing.T) {
+	t.Run("when the host enters maintenance mode while cloning the VM", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			host, err := find.NewFinder(c).HostSystem(ctx, "DC0_H0")
+			g.Expect(err).NotTo(HaveOccurred())
+			task, err := host.EnterMaintenanceMode(ctx, 0, false, nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+
+			vmCtx := &capvcontext.VMContext{
+				Session:   &session.Session{Client: &govmomi.Client{Client: c}},
+				VSphereVM: &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{TaskRef: "task-123"}},
+			}
+			cloneTask := baseTask(types.TaskInfoStateError, "clone failed")
+			cloneTask.Info.DescriptionId = cloneTaskDescriptionID
+			hostRef := host.Reference()
+			cloneTask.Info.Error = &types.LocalizedMethodFault{
+				Fault:            &types.InvalidHostState{Host: &hostRef},
+				LocalizedMessage: "The operation is not allowed in the current state of the host.",
+			}
+
+			inFlight, err := checkAndRetryTask(ctx, vmCtx, &cloneTask)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(inFlight).To(BeFalse())
+			g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+			g.Expect(vmCtx.VSphereVM.Status.RetryAfter.IsZero()).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.ExcludedHosts).To(ConsistOf("DC0_H0"))
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.HostUnavailableReason))
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(ContainSubstring("host DC0_H0 is unavailable"))
+			return nil
+		})
+	})
+
+	t.Run("when the fault does not report the host", func(t *testing.T) {
+		g := NewWithT(t)
+		vmCtx := &capvcontext.VMContext{
+			VSphereVM: &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{TaskRef: "task-123"}},
+		}
+		task := baseTask(types.TaskInfoStateError, "clone failed")
+		task.Info.DescriptionId = cloneTaskDescriptionID
+		task.Info.Error = &types.LocalizedMethodFault{
+			Fault:            &types.HostNotConnected{},
+			LocalizedMessage: "Unable to communicate with the remote host, since it is disconnected.",
+		}
+
+		inFlight, err := checkAndRetryTask(context.Background(), vmCtx, &task)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inFlight).To(BeFalse())
+		g.Expect(vmCtx.VSphereVM.Status.TaskRef).To(BeEmpty())
+		g.Expect(vmCtx.VSphereVM.Status.ExcludedHosts).To(BeEmpty())
+		g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.HostUnavailableReason))
+	})
+
+	t.Run("when a task other than the clone fails", func(t *testing.T) {
+		g := NewWithT(t)
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			host, err := find.NewFinder(c).HostSystem(ctx, "DC0_H0")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			vmCtx := &capvcontext.VMContext{
+				Session:   &session.Session{Client: &govmomi.Client{Client: c}},
+				VSphereVM: &infrav1.VSphereVM{Status: infrav1.VSphereVMStatus{TaskRef: "task-123"}},
+			}
+			powerOnTask := baseTask(types.TaskInfoStateError, "power on failed")
+			powerOnTask.Info.DescriptionId = powerOnTaskDescriptionID
+			hostRef := host.Reference()
+			powerOnTask.Info.Error = &types.LocalizedMethodFault{
+				Fault:            &types.InvalidHostState{Host: &hostRef},
+				LocalizedMessage: "The operation is not allowed in the current state of the host.",
+			}
+
+			inFlight, err := checkAndRetryTask(ctx, vmCtx, &powerOnTask)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(inFlight).To(BeTrue())
+			g.Expect(vmCtx.VSphereVM.Status.ExcludedHosts).To(BeEmpty())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.VMProvisionedCondition)).To(Equal(infrav1.TaskFailure))
+			return nil
+		})
+	})
+}
+
 func baseTask(state types.TaskInfoState, errorDescription string) mo.Task {
 	t := mo.Task{
 		ExtensibleManagedObject: mo.ExtensibleManagedObject{
@@ -21,15 +21,22 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/pbm"
 	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -37,8 +44,41 @@ import (
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/extra"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/template"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
 )
 
+// ErrSriovUnavailable is returned by Clone when no SR-IOV virtual function of the physical
+// function of a network device is available.
+var ErrSriovUnavailable = errors.New("no SR-IOV virtual function available")
+
+// ErrDatastoresFull is returned by Clone when all datastores compatible with the storage
+// policy of the VM ran out of space.
+var ErrDatastoresFull = errors.New("all compatible datastores ran out of space")
+
+// ErrInsufficientHostMemory is returned by Clone when no host of the resource pool of the VM
+// has the minimum free memory required for placing the VM.
+var ErrInsufficientHostMemory = errors.New("no host has the minimum free memory")
+
+// ErrHostsUnavailable is returned by Clone when all hosts of the resource pool of the VM
+// are in maintenance mode or were excluded from the placement of the VM.
+var ErrHostsUnavailable = errors.New("no host available")
+
+// ErrEVCModeNotSupported is returned when the EVC mode of the VM is unknown or not supported
+// by the cluster of the VM.
+var ErrEVCModeNotSupported = errors.New("EVC mode not supported")
+
+// ErrHugePagesNotSupported is returned by Clone when a host of the resource pool of the VM
+// does not back the memory of VMs by huge pages.
+var ErrHugePagesNotSupported = errors.New("huge pages not supported")
+
+// ErrNUMANodeNotAvailable is returned by Clone when a host of the resource pool of the VM
+// does not have a NUMA node of the NUMA node affinity of the VM.
+var ErrNUMANodeNotAvailable = errors.New("NUMA node not available")
+
+// ErrHostLocalSwapNotAvailable is returned by Clone when a host of the resource pool of the
+// VM has no local swap datastore for the host local swap file of the VM.
+var ErrHostLocalSwapNotAvailable = errors.New("host local swap not available")
+
 const (
 	fullCloneDiskMoveType = types.VirtualMachineRelocateDiskMoveOptionsMoveAllDiskBackingsAndConsolidate
 	linkCloneDiskMoveType = types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking
@@ -55,17 +95,30 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 		VSphereVM:                vmCtx.VSphereVM,
 		Session:                  vmCtx.Session,
 		PatchHelper:              vmCtx.PatchHelper,
+		MachineName:              vmCtx.MachineName,
 	}
 	log.Info("Starting clone process")
 
 	var extraConfig extra.Config
+	extraConfig.SetOwnerUID(string(vmCtx.VSphereVM.UID))
+	if err := extraConfig.SetCustomVMXKeys(OwnerExtraConfig(vmCtx.VSphereVM, vmCtx.MachineName)); err != nil {
+		return err
+	}
 	if len(bootstrapData) > 0 {
-		log.Info("Applied bootstrap data to VM clone spec")
-		switch format {
-		case bootstrapv1.CloudConfig:
-			extraConfig.SetCloudInitUserData(bootstrapData)
-		case bootstrapv1.Ignition:
-			extraConfig.SetIgnitionUserData(bootstrapData)
+		if vmCtx.VSphereVM.Spec.CloudInitDatasource == infrav1.CloudInitDatasourceNoCloud {
+			// The bootstrap data of the NoCloud datasource is attached as seed ISO
+			// image once the VM is created.
+			if format != bootstrapv1.CloudConfig {
+				return errors.Errorf("cloud-init datasource NoCloud requires bootstrap data format %s, got %s", bootstrapv1.CloudConfig, format)
+			}
+		} else {
+			log.Info("Applied bootstrap data to VM clone spec")
+			switch format {
+			case bootstrapv1.CloudConfig:
+				extraConfig.SetCloudInitUserData(bootstrapData)
+			case bootstrapv1.Ignition:
+				extraConfig.SetIgnitionUserData(bootstrapData)
+			}
 		}
 	}
 	if vmCtx.VSphereVM.Spec.CustomVMXKeys != nil {
@@ -74,11 +127,51 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 			return err
 		}
 	}
-	tpl, err := template.FindTemplate(ctx, vmCtx.GetSession(), vmCtx.VSphereVM.Spec.Template)
+	if perf := vmCtx.VSphereVM.Spec.PerformanceOptions; perf != nil && perf.ExtraConfig != nil {
+		log.Info("Applied performance options to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(perf.ExtraConfig); err != nil {
+			return err
+		}
+	}
+	if logging := vmCtx.VSphereVM.Spec.LoggingOptions; logging != nil {
+		log.Info("Applied logging options to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(LoggingOptionsExtraConfig(logging)); err != nil {
+			return err
+		}
+	}
+	if backing := vmCtx.VSphereVM.Spec.MemoryBacking; backing != nil {
+		log.Info("Applied memory backing to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(MemoryBackingExtraConfig(backing)); err != nil {
+			return err
+		}
+	}
+	if nodes := vmCtx.VSphereVM.Spec.NUMANodeAffinity; len(nodes) > 0 {
+		log.Info("Applied NUMA node affinity to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(NUMANodeAffinityExtraConfig(nodes)); err != nil {
+			return err
+		}
+	}
+	if enableUUID := vmCtx.VSphereVM.Spec.DiskEnableUUID; enableUUID != nil {
+		log.Info("Applied disk UUID setting to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(DiskEnableUUIDExtraConfig(*enableUUID)); err != nil {
+			return err
+		}
+	}
+	if isolation := EffectiveIsolation(vmCtx.VSphereVM.Spec.Isolation, vmCtx.HardenVMIsolation); isolation != nil {
+		log.Info("Applied isolation to VM clone spec")
+		if err := extraConfig.SetCustomVMXKeys(IsolationExtraConfig(isolation)); err != nil {
+			return err
+		}
+	}
+	tpl, templateName, err := template.FindTemplateWithFallbacks(ctx, vmCtx.GetSession(), vmCtx.VSphereVM.Spec.Template, vmCtx.VSphereVM.Spec.FallbackTemplates)
 	if err != nil {
 		return err
 	}
 
+	if vmCtx.VSphereVM.Spec.CloneMode == infrav1.InstantClone {
+		return instantClone(ctx, vmCtx, tpl, extraConfig)
+	}
+
 	// If a linked clone is requested then a MoRef for a snapshot must be
 	// found with which to perform the linked clone.
 	var snapshotRef *types.ManagedObjectReference
@@ -91,7 +184,7 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 			log.Info("Searching for current snapshot")
 			var vm mo.VirtualMachine
 			if err := tpl.Properties(ctx, tpl.Reference(), []string{"snapshot"}, &vm); err != nil {
-				return errors.Wrapf(err, "error getting snapshot information for template %s", vmCtx.VSphereVM.Spec.Template)
+				return errors.Wrapf(err, "error getting snapshot information for template %s", templateName)
 			}
 			if vm.Snapshot != nil {
 				snapshotRef = vm.Snapshot.CurrentSnapshot
@@ -118,16 +211,24 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 		diskMoveType = linkCloneDiskMoveType
 	}
 
-	folder, err := vmCtx.Session.Finder.FolderOrDefault(ctx, vmCtx.VSphereVM.Spec.Folder)
+	folder, err := vmCtx.Session.FolderOrDefault(ctx, vmCtx.VSphereVM.Spec.Folder)
 	if err != nil {
 		return errors.Wrapf(err, "unable to get folder for %q", ctx)
 	}
 
-	pool, err := vmCtx.Session.Finder.ResourcePoolOrDefault(ctx, vmCtx.VSphereVM.Spec.ResourcePool)
+	pool, err := vmCtx.Session.ResourcePoolOrDefault(ctx, vmCtx.VSphereVM.Spec.ResourcePool)
 	if err != nil {
 		return errors.Wrapf(err, "unable to get resource pool for %q", ctx)
 	}
 
+	// The hosts the VM may be placed on are the hosts of the compute resource owning the
+	// resource pool, so the compute cluster is constrained by validating that it owns the pool.
+	if computeCluster := vmCtx.VSphereVM.Spec.ComputeCluster; computeCluster != "" {
+		if err := checkComputeCluster(ctx, vmCtx, pool, computeCluster); err != nil {
+			return err
+		}
+	}
+
 	devices, err := tpl.Device(ctx)
 	if err != nil {
 		return errors.Wrapf(err, "error getting devices for %q", ctx)
@@ -143,8 +244,20 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 			return errors.Wrapf(err, "error getting disk spec for %q", ctx)
 		}
 		deviceSpecs = append(deviceSpecs, diskSpecs...)
+
+		controllerSpecs, err := getAdditionalDisksControllerSpecs(vmCtx, devices)
+		if err != nil {
+			return errors.Wrapf(err, "error getting additional disks controller spec for %q", ctx)
+		}
+		deviceSpecs = append(deviceSpecs, controllerSpecs...)
 	}
 
+	additionalDiskSpecs, err := getAdditionalDiskSpecs(vmCtx.VSphereVM.Spec.VirtualMachineCloneSpec, devices)
+	if err != nil {
+		return errors.Wrapf(err, "error getting additional disk specs for %q", ctx)
+	}
+	deviceSpecs = append(deviceSpecs, additionalDiskSpecs...)
+
 	networkSpecs, err := getNetworkSpecs(ctx, vmCtx, devices)
 	if err != nil {
 		return errors.Wrapf(err, "error getting network specs for %q", ctx)
@@ -152,6 +265,13 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 
 	deviceSpecs = append(deviceSpecs, networkSpecs...)
 
+	serialPortSpecs, err := getSerialPortSpecs(devices, vmCtx.VSphereVM.Spec.SerialPorts)
+	if err != nil {
+		return errors.Wrapf(err, "error getting serial port specs for %q", ctx)
+	}
+
+	deviceSpecs = append(deviceSpecs, serialPortSpecs...)
+
 	if err != nil {
 		return errors.Wrapf(err, "error getting network specs for %q", ctx)
 	}
@@ -170,15 +290,36 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 	}
 
 	// Disable the vAppConfig during VM creation to ensure Cloud-Init inside of the guest does not
-	// activate and prefer the OVF datasource over the VMware datasource.
+	// activate and prefer the OVF datasource over the VMware datasource, unless an OVF environment
+	// is explicitly requested.
+	var vAppConfig *types.VmConfigSpec
 	vappConfigRemoved := true
+	if ovfEnv := vmCtx.VSphereVM.Spec.OVFEnvironment; ovfEnv != nil {
+		var vm mo.VirtualMachine
+		if err := tpl.Properties(ctx, tpl.Reference(), []string{"config.vAppConfig"}, &vm); err != nil {
+			return errors.Wrapf(err, "error getting vApp configuration of template %s", templateName)
+		}
+		var templateVAppConfig types.BaseVmConfigInfo
+		if vm.Config != nil {
+			templateVAppConfig = vm.Config.VAppConfig
+		}
+		vAppConfig, err = getVAppConfigSpec(templateVAppConfig, ovfEnv)
+		if err != nil {
+			return errors.Wrapf(err, "invalid OVF environment for template %s", templateName)
+		}
+		vappConfigRemoved = false
+		log.Info("Applied OVF environment to VM clone spec", "transport", ovfEnv.Transport)
+	}
 
 	spec := types.VirtualMachineCloneSpec{
 		Config: &types.VirtualMachineConfigSpec{
 			// Assign the clone's InstanceUUID the value of the Kubernetes Machine
 			// object's UID. This allows lookup of the cloned VM prior to knowing
 			// the VM's UUID.
-			InstanceUuid:      string(vmCtx.VSphereVM.UID),
+			InstanceUuid: string(vmCtx.VSphereVM.UID),
+			// The BIOS UUID is only set if the VM is redeployed, so the clone of
+			// the new template keeps the BIOS UUID of the destroyed VM.
+			Uuid:              vmCtx.VSphereVM.Spec.BiosUUID,
 			Flags:             newVMFlagInfo(),
 			DeviceChange:      deviceSpecs,
 			ExtraConfig:       extraConfig,
@@ -186,6 +327,7 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 			NumCoresPerSocket: numCoresPerSocket,
 			MemoryMB:          memMiB,
 			VAppConfigRemoved: &vappConfigRemoved,
+			VAppConfig:        vAppConfig,
 		},
 		Location: types.VirtualMachineRelocateSpec{
 			DiskMoveType: string(diskMoveType),
@@ -203,13 +345,125 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 	// For PCI devices, the memory for the VM needs to be reserved
 	// We can replace this once we have another way of reserving memory option
 	// exposed via the API types.
-	if len(vmCtx.VSphereVM.Spec.PciDevices) > 0 {
+	// The same applies to memory backed by 1Gi huge pages.
+	if len(vmCtx.VSphereVM.Spec.PciDevices) > 0 || hasSriovNetworkDevice(vmCtx.VSphereVM.Spec.Network.Devices) || HugePagesRequireReservation(vmCtx.VSphereVM.Spec.MemoryBacking) {
 		spec.Config.MemoryReservationLockedToMax = ptr.To(true)
+	} else if vmCtx.VSphereVM.Spec.MemoryReservationLockedToMax != nil {
+		spec.Config.MemoryReservationLockedToMax = vmCtx.VSphereVM.Spec.MemoryReservationLockedToMax
+	}
+
+	if allocation := vmCtx.VSphereVM.Spec.ResourceAllocation; allocation != nil {
+		if allocation.CPU != nil {
+			spec.Config.CpuAllocation = ResourceAllocationInfo(allocation.CPU)
+		}
+		if allocation.Memory != nil {
+			spec.Config.MemoryAllocation = ResourceAllocationInfo(allocation.Memory)
+		}
+	}
+
+	if bootOptions := vmCtx.VSphereVM.Spec.BootOptions; bootOptions != nil {
+		spec.Config.BootOptions = &types.VirtualMachineBootOptions{
+			BootDelay:        ptr.Deref(bootOptions.BootDelay, 0),
+			BootRetryEnabled: bootOptions.BootRetryEnabled,
+			BootRetryDelay:   ptr.Deref(bootOptions.BootRetryDelay, 0),
+		}
+	}
+
+	if perf := vmCtx.VSphereVM.Spec.PerformanceOptions; perf != nil {
+		spec.Config.VPMCEnabled = perf.VirtualCPUPerformanceCountersEnabled
+	}
+
+	if vmCtx.VSphereVM.Spec.MemoryBacking != nil {
+		if err := checkHugePagesSupported(ctx, vmCtx, pool); err != nil {
+			return err
+		}
+	}
+
+	if nodes := vmCtx.VSphereVM.Spec.NUMANodeAffinity; len(nodes) > 0 {
+		if err := checkNUMANodesAvailable(ctx, pool, nodes); err != nil {
+			return err
+		}
+	}
+
+	if swapPlacement := vmCtx.VSphereVM.Spec.SwapPlacement; swapPlacement != "" {
+		if swapPlacement == infrav1.SwapPlacementHostLocal {
+			if err := checkHostLocalSwapAvailable(ctx, pool); err != nil {
+				return err
+			}
+		}
+		spec.Config.SwapPlacement = string(swapPlacement)
+	}
+
+	if nestedHV := vmCtx.VSphereVM.Spec.NestedHardwareVirtualization; nestedHV != nil {
+		if *nestedHV {
+			if err := checkNestedHVSupported(ctx, pool); err != nil {
+				return err
+			}
+		}
+		spec.Config.NestedHVEnabled = nestedHV
+	}
+
+	if mode := vmCtx.VSphereVM.Spec.CPUMMUVirtualization; mode != "" {
+		if err := checkCPUMMUVirtualizationSupported(ctx, pool, mode); err != nil {
+			return err
+		}
+		spec.Config.Flags.VirtualExecUsage, spec.Config.Flags.VirtualMmuUsage = CPUMMUVirtualizationFlags(mode)
+	}
+
+	// The per-VM EVC mode cannot be set by the clone spec and is applied before the VM is
+	// powered on, so it is only validated to fail early.
+	if evcMode := vmCtx.VSphereVM.Spec.EVCMode; evcMode != "" {
+		if err := checkEVCModeSupported(ctx, vmCtx, pool, evcMode); err != nil {
+			return err
+		}
+	}
+
+	if vmCtx.VSphereVM.Spec.Host != "" {
+		host, err := PinnedHost(ctx, vmCtx, pool)
+		if err != nil {
+			return err
+		}
+		spec.Location.Host = host
+	} else if minFreeMemMiB := vmCtx.VSphereVM.Spec.MinHostFreeMemoryMiB; minFreeMemMiB > 0 || len(vmCtx.VSphereVM.Status.ExcludedHosts) > 0 {
+		host, err := selectHost(ctx, vmCtx, pool, minFreeMemMiB)
+		if err != nil {
+			return err
+		}
+		spec.Location.Host = host
+	}
+
+	if allocation := vmCtx.VSphereVM.Spec.ResourceAllocation; allocation != nil && allocation.CPUReservationPercent != nil {
+		cpuMhz, err := hostCPUMhz(ctx, vmCtx, pool, spec.Location.Host)
+		if err != nil {
+			return err
+		}
+		if spec.Config.CpuAllocation == nil {
+			spec.Config.CpuAllocation = &types.ResourceAllocationInfo{}
+		}
+		reservation := cpuReservationMhz(numCPUs, cpuMhz, *allocation.CPUReservationPercent)
+		spec.Config.CpuAllocation.Reservation = ptr.To(reservation)
+		log.Info("Applied CPU reservation percentage to VM clone spec", "percent", *allocation.CPUReservationPercent, "reservationMHz", reservation)
+	}
+
+	if vmCtx.EnableManagedBy {
+		spec.Config.ManagedBy = managedByInfo(ctx, vmCtx.Session.Client.Client)
+	}
+
+	if toolsUpgradePolicy := vmCtx.VSphereVM.Spec.ToolsUpgradePolicy; toolsUpgradePolicy != "" {
+		spec.Config.Tools = &types.ToolsConfigInfo{
+			ToolsUpgradePolicy: string(toolsUpgradePolicy),
+		}
+	}
+	if syncTime := vmCtx.VSphereVM.Spec.ToolsSyncTimeWithHost; syncTime != nil {
+		if spec.Config.Tools == nil {
+			spec.Config.Tools = &types.ToolsConfigInfo{}
+		}
+		spec.Config.Tools.SyncTimeWithHost = syncTime
 	}
 
 	var datastoreRef *types.ManagedObjectReference
 	if vmCtx.VSphereVM.Spec.Datastore != "" {
-		datastore, err := vmCtx.Session.Finder.Datastore(ctx, vmCtx.VSphereVM.Spec.Datastore)
+		datastore, err := vmCtx.Session.DatastoreOrDefault(ctx, vmCtx.VSphereVM.Spec.Datastore)
 		if err != nil {
 			return errors.Wrapf(err, "unable to get datastore %s for %q", vmCtx.VSphereVM.Spec.Datastore, ctx)
 		}
@@ -271,9 +525,18 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 		// select one of the datastores of the owning cluster of the resource pool that matched the
 		// requirements of the storage policy.
 		if datastoreRef == nil {
+			candidates, err := excludeFullDatastores(ctx, vmCtx, result.CompatibleDatastores())
+			if err != nil {
+				return err
+			}
+			if len(candidates) == 0 {
+				// Start over with all compatible datastores on the next attempt,
+				// as space may have been freed in the meantime.
+				vmCtx.VSphereVM.Status.ExcludedDatastores = nil
+				return errors.Wrapf(ErrDatastoresFull, "storage policy %s", vmCtx.VSphereVM.Spec.StoragePolicyName)
+			}
 			r := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // We won't need cryptographically secure randomness here.
-			ds := result.CompatibleDatastores()[r.Intn(len(result.CompatibleDatastores()))]
-			datastoreRef = &types.ManagedObjectReference{Type: ds.HubType, Value: ds.HubId}
+			datastoreRef = &candidates[r.Intn(len(candidates))]
 		}
 	}
 
@@ -288,13 +551,19 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 		datastoreRef = types.NewReference(datastore.Reference())
 	}
 
+	if vmCtx.VSphereVM.Spec.DiskStorageIOAllocation != nil {
+		if err := CheckStorageIOControlEnabled(ctx, vmCtx.Session.Client.Client, []types.ManagedObjectReference{*datastoreRef}); err != nil {
+			return err
+		}
+	}
+
 	disks := devices.SelectByType((*types.VirtualDisk)(nil))
 	isLinkedClone := snapshotRef != nil
 	spec.Location.Disk = getDiskLocators(disks, *datastoreRef, isLinkedClone)
 	spec.Location.Datastore = datastoreRef
 
 	log.Info(fmt.Sprintf("Cloning Machine with clone mode %s", vmCtx.VSphereVM.Status.CloneMode))
-	task, err := tpl.Clone(ctx, folder, vmCtx.VSphereVM.Name, spec)
+	task, err := tpl.Clone(ctx, folder, util.GetVMName(vmCtx.VSphereVM), spec)
 	if err != nil {
 		return errors.Wrapf(err, "error trigging clone op for machine %s", ctx)
 	}
@@ -310,6 +579,31 @@ func Clone(ctx context.Context, vmCtx *capvcontext.VMContext, bootstrapData []by
 	return nil
 }
 
+// excludeFullDatastores returns the references of the given datastores, except the ones
+// excluded from the placement of the VM because they ran out of space.
+func excludeFullDatastores(ctx context.Context, vmCtx *capvcontext.VMContext, hubs []pbmTypes.PbmPlacementHub) ([]types.ManagedObjectReference, error) {
+	refs := make([]types.ManagedObjectReference, 0, len(hubs))
+	for _, hub := range hubs {
+		refs = append(refs, types.ManagedObjectReference{Type: hub.HubType, Value: hub.HubId})
+	}
+	if len(vmCtx.VSphereVM.Status.ExcludedDatastores) == 0 {
+		return refs, nil
+	}
+
+	var datastores []mo.Datastore
+	pc := property.DefaultCollector(vmCtx.Session.Client.Client)
+	if err := pc.Retrieve(ctx, refs, []string{"name"}, &datastores); err != nil {
+		return nil, errors.Wrapf(err, "unable to get names of datastores compatible with storage policy")
+	}
+	candidates := make([]types.ManagedObjectReference, 0, len(datastores))
+	for _, datastore := range datastores {
+		if !slices.Contains(vmCtx.VSphereVM.Status.ExcludedDatastores, datastore.Name) {
+			candidates = append(candidates, datastore.Reference())
+		}
+	}
+	return candidates, nil
+}
+
 func newVMFlagInfo() *types.VirtualMachineFlagInfo {
 	diskUUIDEnabled := true
 	return &types.VirtualMachineFlagInfo{
@@ -317,6 +611,78 @@ func newVMFlagInfo() *types.VirtualMachineFlagInfo {
 	}
 }
 
+// getAdditionalDisksControllerSpecs returns the specs which set the bus sharing mode of
+// the SCSI controllers of the additional disks, i.e. of the disks of the template except
+// its first disk.
+func getAdditionalDisksControllerSpecs(vmCtx *capvcontext.VMContext, devices object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	controllerSpec := vmCtx.VSphereVM.Spec.AdditionalDisksController
+	if controllerSpec == nil || controllerSpec.BusSharing == "" {
+		return nil, nil
+	}
+	sharedBus := scsiSharedBus[controllerSpec.BusSharing]
+
+	disks := devices.SelectByType((*types.VirtualDisk)(nil))
+	if len(disks) < 2 {
+		return nil, errors.Errorf("bus sharing %s requires additional disks in the template", controllerSpec.BusSharing)
+	}
+	primaryControllerKey := disks[0].GetVirtualDevice().ControllerKey
+
+	var controllerSpecs []types.BaseVirtualDeviceConfigSpec
+	edited := map[int32]bool{}
+	for _, disk := range disks[1:] {
+		name := devices.Name(disk)
+		controllerKey := disk.GetVirtualDevice().ControllerKey
+		if sharedBus != types.VirtualSCSISharingNoSharing {
+			if controllerKey == primaryControllerKey {
+				return nil, errors.Errorf("additional disk %s is attached to the SCSI controller of the first disk, which cannot share its bus", name)
+			}
+			if backing, ok := disk.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo); !ok || !ptr.Deref(backing.EagerlyScrub, false) || ptr.Deref(backing.ThinProvisioned, false) {
+				return nil, errors.Errorf("additional disk %s must be provisioned eagerZeroedThick to share its bus", name)
+			}
+		}
+		if edited[controllerKey] {
+			continue
+		}
+		controller, ok := devices.FindByKey(controllerKey).(types.BaseVirtualSCSIController)
+		if !ok {
+			return nil, errors.Errorf("additional disk %s is not attached to a SCSI controller", name)
+		}
+		controller.GetVirtualSCSIController().SharedBus = sharedBus
+		controllerSpecs = append(controllerSpecs, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    controller.(types.BaseVirtualDevice),
+		})
+		edited[controllerKey] = true
+	}
+	return controllerSpecs, nil
+}
+
+// AdditionalDisksBusSharing returns the bus sharing mode of the SCSI controller of the
+// first additional disk of the given devices, or an empty string if there is none.
+func AdditionalDisksBusSharing(devices object.VirtualDeviceList) infrav1.SCSIBusSharing {
+	disks := devices.SelectByType((*types.VirtualDisk)(nil))
+	if len(disks) < 2 {
+		return ""
+	}
+	controller, ok := devices.FindByKey(disks[1].GetVirtualDevice().ControllerKey).(types.BaseVirtualSCSIController)
+	if !ok {
+		return ""
+	}
+	for busSharing, sharedBus := range scsiSharedBus {
+		if controller.GetVirtualSCSIController().SharedBus == sharedBus {
+			return busSharing
+		}
+	}
+	return ""
+}
+
+// scsiSharedBus maps the bus sharing modes of the API to those of vSphere.
+var scsiSharedBus = map[infrav1.SCSIBusSharing]types.VirtualSCSISharing{
+	infrav1.SCSIBusSharingNone:     types.VirtualSCSISharingNoSharing,
+	infrav1.SCSIBusSharingVirtual:  types.VirtualSCSISharingVirtualSharing,
+	infrav1.SCSIBusSharingPhysical: types.VirtualSCSISharingPhysicalSharing,
+}
+
 func getDiskLocators(disks object.VirtualDeviceList, datastoreRef types.ManagedObjectReference, isLinkedClone bool) []types.VirtualMachineRelocateSpecDiskLocator {
 	diskLocators := make([]types.VirtualMachineRelocateSpecDiskLocator, 0, len(disks))
 	for _, disk := range disks {
@@ -346,6 +712,11 @@ func getDiskSpec(vmCtx *capvcontext.VMContext, devices object.VirtualDeviceList)
 
 	// There is at least one disk
 	var diskSpecs []types.BaseVirtualDeviceConfigSpec
+	if allocation := vmCtx.VSphereVM.Spec.DiskStorageIOAllocation; allocation != nil {
+		for _, disk := range disks {
+			disk.(*types.VirtualDisk).StorageIOAllocation = StorageIOAllocationInfo(allocation)
+		}
+	}
 	primaryDisk := disks[0].(*types.VirtualDisk)
 	primaryCloneCapacityKB := int64(vmCtx.VSphereVM.Spec.DiskGiB) * 1024 * 1024
 	primaryDiskConfigSpec, err := getDiskConfigSpec(primaryDisk, primaryCloneCapacityKB)
@@ -376,6 +747,180 @@ func getDiskSpec(vmCtx *capvcontext.VMContext, devices object.VirtualDeviceList)
 	return diskSpecs, nil
 }
 
+// getAdditionalDiskSpecs returns the specs to create the additional disks which are not in
+// the template, and the SCSI controllers they require. The disks are distributed across the
+// SCSI controllers round-robin in the order of their bus numbers, each at the lowest free
+// unit number of its controller.
+func getAdditionalDiskSpecs(cloneSpec infrav1.VirtualMachineCloneSpec, devices object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	// The additional disks of the clone spec start with the second disk of the template.
+	templateDisks := len(devices.SelectByType((*types.VirtualDisk)(nil)))
+	var newDisksGiB []int32
+	if offset := max(templateDisks-1, 0); len(cloneSpec.AdditionalDisksGiB) > offset {
+		newDisksGiB = cloneSpec.AdditionalDisksGiB[offset:]
+	}
+
+	var controllers []scsiControllerSlots
+	free := 0
+	for _, device := range devices.SelectByType((*types.VirtualSCSIController)(nil)) {
+		slots := scsiControllerSlots{
+			key:         device.GetVirtualDevice().Key,
+			busNumber:   device.(types.BaseVirtualController).GetVirtualController().BusNumber,
+			unitNumbers: freeSCSIUnitNumbers(devices, device.GetVirtualDevice().Key),
+			device:      device,
+		}
+		controllers = append(controllers, slots)
+		free += len(slots.unitNumbers)
+	}
+	sort.Slice(controllers, func(i, j int) bool { return controllers[i].busNumber < controllers[j].busNumber })
+
+	if cloneSpec.SCSIControllerCount > 0 && int(cloneSpec.SCSIControllerCount) < len(controllers) {
+		return nil, errors.Errorf("scsiControllerCount is %d, but the template has %d SCSI controllers", cloneSpec.SCSIControllerCount, len(controllers))
+	}
+	count := len(controllers)
+	if missing := len(newDisksGiB) - free; missing > 0 {
+		count += (missing + disksPerSCSIController - 1) / disksPerSCSIController
+	}
+	if cloneSpec.SCSIControllerCount > 0 {
+		if count > int(cloneSpec.SCSIControllerCount) {
+			return nil, errors.Errorf("%d additional disks require %d SCSI controllers, but scsiControllerCount is %d", len(newDisksGiB), count, cloneSpec.SCSIControllerCount)
+		}
+		count = int(cloneSpec.SCSIControllerCount)
+	}
+	if count > maxSCSIControllers {
+		return nil, errors.Errorf("%d additional disks require %d SCSI controllers, but a VM supports at most %d", len(newDisksGiB), count, maxSCSIControllers)
+	}
+
+	var deviceSpecs []types.BaseVirtualDeviceConfigSpec
+	key := int32(-300)
+	if missing := count - len(controllers); missing > 0 {
+		var template types.BaseVirtualDevice
+		if len(controllers) > 0 {
+			template = controllers[0].device
+		}
+		inUse := map[int32]bool{}
+		for _, controller := range controllers {
+			inUse[controller.busNumber] = true
+		}
+		for busNumber := int32(0); missing > 0; busNumber++ {
+			if inUse[busNumber] {
+				continue
+			}
+			controller := newSCSIController(template, key, busNumber)
+			controllers = append(controllers, scsiControllerSlots{
+				key:         key,
+				busNumber:   busNumber,
+				unitNumbers: freeSCSIUnitNumbers(nil, key),
+				device:      controller,
+			})
+			deviceSpecs = append(deviceSpecs, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    controller,
+			})
+			key--
+			missing--
+		}
+		sort.Slice(controllers, func(i, j int) bool { return controllers[i].busNumber < controllers[j].busNumber })
+	}
+
+	next := 0
+	for i, sizeGiB := range newDisksGiB {
+		if sizeGiB <= 0 {
+			return nil, errors.Errorf("additional disk %d is not in the template and requires a size", templateDisks+i)
+		}
+		// Pick the next controller round-robin which has a free unit number.
+		for len(controllers[next%len(controllers)].unitNumbers) == 0 {
+			next++
+		}
+		controller := &controllers[next%len(controllers)]
+		next++
+
+		unitNumber := controller.unitNumbers[0]
+		controller.unitNumbers = controller.unitNumbers[1:]
+		disk := &types.VirtualDisk{
+			VirtualDevice: types.VirtualDevice{
+				Key:           key,
+				ControllerKey: controller.key,
+				UnitNumber:    ptr.To(unitNumber),
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					DiskMode:        string(types.VirtualDiskModePersistent),
+					ThinProvisioned: ptr.To(true),
+				},
+			},
+			CapacityInKB: int64(sizeGiB) * 1024 * 1024,
+		}
+		key--
+		if allocation := cloneSpec.DiskStorageIOAllocation; allocation != nil {
+			disk.StorageIOAllocation = StorageIOAllocationInfo(allocation)
+		}
+		deviceSpecs = append(deviceSpecs, &types.VirtualDeviceConfigSpec{
+			Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+			FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+			Device:        disk,
+		})
+	}
+	return deviceSpecs, nil
+}
+
+// scsiControllerSlots tracks the free unit numbers of a SCSI controller while the
+// additional disks are distributed across the SCSI controllers.
+type scsiControllerSlots struct {
+	key         int32
+	busNumber   int32
+	unitNumbers []int32
+	device      types.BaseVirtualDevice
+}
+
+const (
+	// maxSCSIControllers is the maximum number of SCSI controllers of a VM.
+	maxSCSIControllers = 4
+
+	// disksPerSCSIController is the number of disks a SCSI controller can attach. Of its
+	// unit numbers 0 to 15, unit number 7 is reserved for the controller itself.
+	disksPerSCSIController   = 15
+	scsiControllerUnitNumber = 7
+)
+
+// freeSCSIUnitNumbers returns the unit numbers of the SCSI controller with the given key
+// which are not used by one of the devices, in ascending order.
+func freeSCSIUnitNumbers(devices object.VirtualDeviceList, controllerKey int32) []int32 {
+	used := map[int32]bool{scsiControllerUnitNumber: true}
+	for _, device := range devices {
+		if d := device.GetVirtualDevice(); d.ControllerKey == controllerKey && d.UnitNumber != nil {
+			used[*d.UnitNumber] = true
+		}
+	}
+	var unitNumbers []int32
+	for unitNumber := int32(0); unitNumber <= disksPerSCSIController; unitNumber++ {
+		if !used[unitNumber] {
+			unitNumbers = append(unitNumbers, unitNumber)
+		}
+	}
+	return unitNumbers
+}
+
+// newSCSIController returns a new SCSI controller of the type of the given controller, or
+// a VMware paravirtual SCSI controller if none is given.
+func newSCSIController(template types.BaseVirtualDevice, key, busNumber int32) types.BaseVirtualDevice {
+	controller := types.VirtualSCSIController{
+		VirtualController: types.VirtualController{
+			VirtualDevice: types.VirtualDevice{Key: key},
+			BusNumber:     busNumber,
+		},
+		SharedBus:          types.VirtualSCSISharingNoSharing,
+		ScsiCtlrUnitNumber: scsiControllerUnitNumber,
+	}
+	switch template.(type) {
+	case *types.VirtualLsiLogicController:
+		return &types.VirtualLsiLogicController{VirtualSCSIController: controller}
+	case *types.VirtualLsiLogicSASController:
+		return &types.VirtualLsiLogicSASController{VirtualSCSIController: controller}
+	case *types.VirtualBusLogicController:
+		return &types.VirtualBusLogicController{VirtualSCSIController: controller}
+	default:
+		return &types.ParaVirtualSCSIController{VirtualSCSIController: controller}
+	}
+}
+
 func getDiskConfigSpec(disk *types.VirtualDisk, diskCloneCapacityKB int64) (types.BaseVirtualDeviceConfigSpec, error) {
 	switch {
 	case diskCloneCapacityKB == 0:
@@ -394,6 +939,35 @@ func getDiskConfigSpec(disk *types.VirtualDisk, diskCloneCapacityKB int64) (type
 	}, nil
 }
 
+// getSerialPortSpecs returns the specs to add the given serial ports to the VM.
+func getSerialPortSpecs(devices object.VirtualDeviceList, serialPorts []infrav1.SerialPortSpec) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var deviceSpecs []types.BaseVirtualDeviceConfigSpec
+	key := int32(-200)
+	for _, serialPort := range serialPorts {
+		device, err := devices.CreateSerialPort()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create serial port")
+		}
+		device.Key = key
+		key--
+
+		if serialPort.File != "" {
+			devices.ConnectSerialPort(device, serialPort.File, false, "")
+		} else {
+			// The VM connects to the network service, or the virtual serial port concentrator.
+			devices.ConnectSerialPort(device, serialPort.URI, true, serialPort.ProxyURI)
+		}
+
+		// The device list is extended so the next serial port gets the next unit number.
+		devices = append(devices, device)
+		deviceSpecs = append(deviceSpecs, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    device,
+		})
+	}
+	return deviceSpecs, nil
+}
+
 const ethCardType = "vmxnet3"
 
 func getNetworkSpecs(ctx context.Context, vmCtx *capvcontext.VMContext, devices object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
@@ -421,9 +995,19 @@ func getNetworkSpecs(ctx context.Context, vmCtx *capvcontext.VMContext, devices
 		if err != nil {
 			return nil, errors.Wrapf(err, "unable to create new ethernet card backing info for network %q on %q", netSpec.NetworkName, ctx)
 		}
-		dev, err := object.EthernetCardTypes().CreateEthernetCard(ethCardType, backing)
+		adapterType := ethCardType
+		if netSpec.AdapterType != "" {
+			adapterType = string(netSpec.AdapterType)
+		}
+		dev, err := object.EthernetCardTypes().CreateEthernetCard(adapterType, backing)
 		if err != nil {
-			return nil, errors.Wrapf(err, "unable to create new ethernet card %q for network %q on %q", ethCardType, netSpec.NetworkName, ctx)
+			return nil, errors.Wrapf(err, "unable to create new ethernet card %q for network %q on %q", adapterType, netSpec.NetworkName, ctx)
+		}
+		if sriovCard, ok := dev.(*types.VirtualSriovEthernetCard); ok {
+			sriovCard.SriovBacking, err = getSriovBacking(ctx, vmCtx, netSpec.PhysicalFunction)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create SR-IOV backing for network %q", netSpec.NetworkName)
+			}
 		}
 
 		// Get the actual NIC object. This is safe to assert without a check
@@ -438,6 +1022,7 @@ func getNetworkSpecs(ctx context.Context, vmCtx *capvcontext.VMContext, devices
 			nic.AddressType = string(types.VirtualEthernetCardMacTypeManual)
 			log.V(4).Info("Configured manual MAC address", "macAddress", nic.MacAddress)
 		}
+		NetworkDeviceSettings(nic, netSpec)
 
 		// Assign a temporary device key to ensure that a unique one will be
 		// generated when the device is created.
@@ -447,9 +1032,700 @@ func getNetworkSpecs(ctx context.Context, vmCtx *capvcontext.VMContext, devices
 			Device:    dev,
 			Operation: types.VirtualDeviceConfigSpecOperationAdd,
 		})
-		log.V(4).Info("Created network device", "ethCardType", ethCardType, "networkSpec", netSpec)
+		log.V(4).Info("Created network device", "ethCardType", adapterType, "networkSpec", netSpec)
 		key--
 	}
 
 	return deviceSpecs, nil
 }
+
+// NetworkDeviceSettings applies the Wake-on-LAN and guest control settings of the network
+// device spec to the network adapter. Settings which are not set in the spec are left
+// unchanged. It returns the names of the settings which changed.
+func NetworkDeviceSettings(nic *types.VirtualEthernetCard, netSpec *infrav1.NetworkDeviceSpec) []string {
+	var changes []string
+	if wakeOnLan := netSpec.WakeOnLanEnabled; wakeOnLan != nil && ptr.Deref(nic.WakeOnLanEnabled, false) != *wakeOnLan {
+		nic.WakeOnLanEnabled = ptr.To(*wakeOnLan)
+		changes = append(changes, "wakeOnLanEnabled")
+	}
+	if allowGuestControl := netSpec.AllowGuestControl; allowGuestControl != nil {
+		switch {
+		case nic.Connectable == nil:
+			// A new adapter must still be connected when the VM is powered on.
+			nic.Connectable = &types.VirtualDeviceConnectInfo{StartConnected: true, AllowGuestControl: *allowGuestControl}
+			changes = append(changes, "allowGuestControl")
+		case nic.Connectable.AllowGuestControl != *allowGuestControl:
+			nic.Connectable.AllowGuestControl = *allowGuestControl
+			changes = append(changes, "allowGuestControl")
+		}
+	}
+	return changes
+}
+
+// getSriovBacking returns the backing of a SR-IOV network adapter by the given physical function,
+// identified by its PCI ID or the name of its physical adapter. The physical function must be a
+// SR-IOV capable adapter with active virtual functions on a host of the compute resource of the
+// resource pool of the VM.
+func getSriovBacking(ctx context.Context, vmCtx *capvcontext.VMContext, physicalFunction string) (*types.VirtualSriovEthernetCardSriovBackingInfo, error) {
+	pool, err := vmCtx.Session.ResourcePoolOrDefault(ctx, vmCtx.VSphereVM.Spec.ResourcePool)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get resource pool for %q", ctx)
+	}
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get owning compute resource of resource pool %q", pool)
+	}
+	var computeResource mo.ComputeResource
+	if err := pool.Properties(ctx, owner.Reference(), []string{"environmentBrowser", "host"}, &computeResource); err != nil {
+		return nil, errors.Wrapf(err, "unable to get environment browser of compute resource of resource pool %q", pool)
+	}
+	if computeResource.EnvironmentBrowser == nil {
+		return nil, errors.Errorf("compute resource of resource pool %q has no environment browser", pool)
+	}
+	configTarget, err := object.NewEnvironmentBrowser(vmCtx.Session.Client.Client, *computeResource.EnvironmentBrowser).QueryConfigTarget(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to query config target of compute resource of resource pool %q", pool)
+	}
+
+	for _, sriov := range configTarget.Sriov {
+		if sriov.VirtualFunction || (sriov.PciDevice.Id != physicalFunction && sriov.Pnic != physicalFunction) {
+			continue
+		}
+		var hosts []mo.HostSystem
+		if len(computeResource.Host) > 0 {
+			pc := property.DefaultCollector(vmCtx.Session.Client.Client)
+			if err := pc.Retrieve(ctx, computeResource.Host, []string{"config.pciPassthruInfo"}, &hosts); err != nil {
+				return nil, errors.Wrapf(err, "unable to get PCI passthrough information of hosts of resource pool %q", pool)
+			}
+		}
+		if !hasActiveVirtualFunctions(hosts, sriov.PciDevice.Id) {
+			return nil, errors.Wrapf(ErrSriovUnavailable, "physical function %s has no active virtual functions", physicalFunction)
+		}
+		return &types.VirtualSriovEthernetCardSriovBackingInfo{
+			PhysicalFunctionBacking: &types.VirtualPCIPassthroughDeviceBackingInfo{
+				Id:       sriov.PciDevice.Id,
+				DeviceId: fmt.Sprintf("%04x", uint16(sriov.PciDevice.DeviceId)),
+				SystemId: sriov.SystemId,
+				VendorId: sriov.PciDevice.VendorId,
+			},
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrSriovUnavailable, "physical function %s is not a SR-IOV capable adapter of the hosts of resource pool %q", physicalFunction, pool)
+}
+
+// hasActiveVirtualFunctions returns true if SR-IOV is active with virtual functions for the
+// physical function with the given PCI ID on any of the hosts.
+func hasActiveVirtualFunctions(hosts []mo.HostSystem, id string) bool {
+	for _, host := range hosts {
+		if host.Config == nil {
+			continue
+		}
+		for _, info := range host.Config.PciPassthruInfo {
+			if sriovInfo, ok := info.(*types.HostSriovInfo); ok && sriovInfo.Id == id && sriovInfo.SriovActive && sriovInfo.NumVirtualFunction > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkComputeCluster returns an error if the compute cluster does not own the resource pool.
+func checkComputeCluster(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool, computeCluster string) error {
+	ccr, err := vmCtx.Session.Finder.ClusterComputeResource(ctx, computeCluster)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find compute cluster %q", computeCluster)
+	}
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get owning compute resource of resource pool %q", pool)
+	}
+	if owner.Reference() != ccr.Reference() {
+		return errors.Errorf("compute cluster %q does not own resource pool %q", computeCluster, pool)
+	}
+	return nil
+}
+
+// resourcePoolHosts returns the given properties of the hosts of the compute resource owning
+// the resource pool. It returns no hosts if the compute resource has none, and an error if the
+// resource pool has no owning compute resource.
+func resourcePoolHosts(ctx context.Context, pool *object.ResourcePool, props ...string) ([]mo.HostSystem, error) {
+	var rp mo.ResourcePool
+	if err := pool.Properties(ctx, pool.Reference(), []string{"owner"}, &rp); err != nil {
+		return nil, errors.Wrap(err, "failed to get owning compute resource")
+	}
+	// The owner is not set for resource pools which are not part of the inventory anymore.
+	if rp.Owner.Value == "" {
+		return nil, errors.New("no owning compute resource")
+	}
+	var computeResource mo.ComputeResource
+	if err := pool.Properties(ctx, rp.Owner, []string{"host"}, &computeResource); err != nil {
+		return nil, errors.Wrap(err, "failed to get hosts of owning compute resource")
+	}
+	if len(computeResource.Host) == 0 {
+		return nil, nil
+	}
+	var hosts []mo.HostSystem
+	if err := property.DefaultCollector(pool.Client()).Retrieve(ctx, computeResource.Host, props, &hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// checkNestedHVSupported returns an error if any host of the compute resource of the resource
+// pool does not support nested hardware virtualization, as the VM may be placed on any of them.
+func checkNestedHVSupported(ctx context.Context, pool *object.ResourcePool) error {
+	hosts, err := resourcePoolHosts(ctx, pool, "name", "capability.nestedHVSupported")
+	if err != nil {
+		return errors.Wrapf(err, "unable to get capabilities of hosts of resource pool %q", pool)
+	}
+	for _, host := range hosts {
+		if host.Capability == nil || !ptr.Deref(host.Capability.NestedHVSupported, false) {
+			return errors.Errorf("host %s of resource pool %q does not support nested hardware virtualization", host.Name, pool)
+		}
+	}
+	return nil
+}
+
+// checkCPUMMUVirtualizationSupported returns an error if any host of the compute resource of
+// the resource pool does not support the CPU/MMU virtualization mode, as the VM may be placed
+// on any of them.
+func checkCPUMMUVirtualizationSupported(ctx context.Context, pool *object.ResourcePool, mode infrav1.CPUMMUVirtualizationMode) error {
+	hosts, err := resourcePoolHosts(ctx, pool, CPUMMUVirtualizationHostProperties...)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get capabilities of hosts of resource pool %q", pool)
+	}
+	for _, host := range hosts {
+		if err := CheckCPUMMUVirtualizationSupported(host, mode); err != nil {
+			return errors.Wrapf(err, "resource pool %q", pool)
+		}
+	}
+	return nil
+}
+
+// CPUMMUVirtualizationHostProperties are the properties of a host required to check whether
+// it supports a CPU/MMU virtualization mode.
+var CPUMMUVirtualizationHostProperties = []string{
+	"name",
+	"capability.virtualExecUsageSupported",
+	"capability.virtualExecUsageIgnored",
+	"capability.virtualMmuUsageIgnored",
+}
+
+// CheckCPUMMUVirtualizationSupported returns an error if the host does not support the CPU/MMU
+// virtualization mode. Hosts which ignore the mode always virtualize the CPU and MMU by
+// hardware, so they do not support the software mode.
+func CheckCPUMMUVirtualizationSupported(host mo.HostSystem, mode infrav1.CPUMMUVirtualizationMode) error {
+	if mode == "" || mode == infrav1.CPUMMUVirtualizationModeAutomatic {
+		return nil
+	}
+	if host.Capability == nil || !ptr.Deref(host.Capability.VirtualExecUsageSupported, false) {
+		return errors.Errorf("host %s does not support configuring the CPU/MMU virtualization", host.Name)
+	}
+	if mode == infrav1.CPUMMUVirtualizationModeSoftware &&
+		(ptr.Deref(host.Capability.VirtualExecUsageIgnored, false) || ptr.Deref(host.Capability.VirtualMmuUsageIgnored, false)) {
+		return errors.Errorf("host %s does not support software CPU/MMU virtualization", host.Name)
+	}
+	return nil
+}
+
+// CPUMMUVirtualizationFlags returns the virtualExecUsage and virtualMmuUsage flags of a VM which
+// virtualize its CPU and MMU in the given mode.
+func CPUMMUVirtualizationFlags(mode infrav1.CPUMMUVirtualizationMode) (string, string) {
+	switch mode {
+	case infrav1.CPUMMUVirtualizationModeSoftware:
+		return string(types.VirtualMachineFlagInfoVirtualExecUsageHvOff), string(types.VirtualMachineFlagInfoVirtualMmuUsageOff)
+	case infrav1.CPUMMUVirtualizationModeHardware:
+		return string(types.VirtualMachineFlagInfoVirtualExecUsageHvOn), string(types.VirtualMachineFlagInfoVirtualMmuUsageOn)
+	default:
+		return string(types.VirtualMachineFlagInfoVirtualExecUsageHvAuto), string(types.VirtualMachineFlagInfoVirtualMmuUsageAutomatic)
+	}
+}
+
+// checkHugePagesSupported returns an ErrHugePagesNotSupported error if any host of the compute
+// resource of the resource pool does not back the memory of VMs by huge pages of the size of the
+// memory backing of the VM. Whether a host has enough free huge pages is not reported by vSphere,
+// so the VM may still fail to power on.
+func checkHugePagesSupported(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool) error {
+	hosts, err := resourcePoolHosts(ctx, pool, HugePagesHostProperties...)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get huge page support of hosts of resource pool %q", pool)
+	}
+	size := vmCtx.VSphereVM.Spec.MemoryBacking.HugePageSize
+	for _, host := range hosts {
+		if !HostSupportsHugePages(host, size) {
+			return errors.Wrapf(ErrHugePagesNotSupported, "host %s of resource pool %q does not back the memory of VMs by %s huge pages", host.Name, pool, size)
+		}
+	}
+	return nil
+}
+
+const (
+	// extendedFeaturesCPUIDLevel is the CPUID level 0x80000001 of the extended processor features.
+	extendedFeaturesCPUIDLevel = -2147483647
+
+	// pdpe1gbCPUIDBit is the bit of the EDX register of the extended processor features which
+	// reports the support of 1 GiB pages.
+	pdpe1gbCPUIDBit = 26
+)
+
+// HugePagesHostProperties are the properties of a host HostSupportsHugePages requires.
+var HugePagesHostProperties = []string{"name", "config.option", "hardware.cpuFeature"}
+
+// HostSupportsHugePages returns whether the host backs the memory of VMs by huge pages of the
+// given size. Huge pages are allocated by default unless the Mem.AllocGuestLargePage advanced
+// option is disabled, and 1 GiB pages additionally require CPUs with the pdpe1gb feature.
+// Hosts which do not report their advanced options or CPU features are assumed to support
+// huge pages.
+func HostSupportsHugePages(host mo.HostSystem, size infrav1.HugePageSize) bool {
+	if host.Config != nil {
+		for _, option := range host.Config.Option {
+			if ov := option.GetOptionValue(); ov != nil && ov.Key == "Mem.AllocGuestLargePage" && fmt.Sprint(ov.Value) == "0" {
+				return false
+			}
+		}
+	}
+	if size != infrav1.HugePageSize1Gi || host.Hardware == nil {
+		return true
+	}
+	for _, feature := range host.Hardware.CpuFeature {
+		if feature.Level != extendedFeaturesCPUIDLevel {
+			continue
+		}
+		// The register is reported as 32 bits from the highest to the lowest bit, grouped by
+		// colons, e.g. "0010:1000:0001:0000:0000:1000:0000:0000".
+		bits := strings.ReplaceAll(feature.Edx, ":", "")
+		if len(bits) != 32 {
+			return true
+		}
+		return bits[31-pdpe1gbCPUIDBit] != '0'
+	}
+	return true
+}
+
+// checkNUMANodesAvailable returns an ErrNUMANodeNotAvailable error if any host of the compute
+// resource of the resource pool does not have all NUMA nodes of the NUMA node affinity.
+func checkNUMANodesAvailable(ctx context.Context, pool *object.ResourcePool, nodes []int32) error {
+	hosts, err := resourcePoolHosts(ctx, pool, "name", "hardware.numaInfo")
+	if err != nil {
+		return errors.Wrapf(err, "unable to get NUMA topology of hosts of resource pool %q", pool)
+	}
+	for _, host := range hosts {
+		if !HostHasNUMANodes(host, nodes) {
+			return errors.Wrapf(ErrNUMANodeNotAvailable, "host %s of resource pool %q has %d NUMA nodes, which do not include NUMA nodes %s", host.Name, pool, hostNUMANodes(host), NUMANodeAffinityValue(nodes))
+		}
+	}
+	return nil
+}
+
+// checkHostLocalSwapAvailable returns an ErrHostLocalSwapNotAvailable error if any host of the
+// compute resource of the resource pool has no local swap datastore. vSphere stores the swap
+// file of the VM in its directory on such a host instead.
+func checkHostLocalSwapAvailable(ctx context.Context, pool *object.ResourcePool) error {
+	hosts, err := resourcePoolHosts(ctx, pool, HostLocalSwapProperties...)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get local swap datastores of hosts of resource pool %q", pool)
+	}
+	for _, host := range hosts {
+		if !HasHostLocalSwap(host) {
+			return errors.Wrapf(ErrHostLocalSwapNotAvailable, "host %s of resource pool %q has no local swap datastore", host.Name, pool)
+		}
+	}
+	return nil
+}
+
+// HostLocalSwapProperties are the properties of a host required to check whether it has a
+// local swap datastore.
+var HostLocalSwapProperties = []string{
+	"name",
+	"config.localSwapDatastore",
+}
+
+// HasHostLocalSwap returns whether the host has a local swap datastore for the swap files of
+// VMs with host local swap placement.
+func HasHostLocalSwap(host mo.HostSystem) bool {
+	return host.Config != nil && host.Config.LocalSwapDatastore != nil
+}
+
+// HostHasNUMANodes returns whether the host has all NUMA nodes of the NUMA node affinity. The
+// NUMA nodes of a host are indexed from 0. A host without NUMA topology has a single node.
+func HostHasNUMANodes(host mo.HostSystem, nodes []int32) bool {
+	numNodes := hostNUMANodes(host)
+	for _, node := range nodes {
+		if node >= numNodes {
+			return false
+		}
+	}
+	return true
+}
+
+func hostNUMANodes(host mo.HostSystem) int32 {
+	if host.Hardware == nil || host.Hardware.NumaInfo == nil || host.Hardware.NumaInfo.NumNodes == 0 {
+		return 1
+	}
+	return host.Hardware.NumaInfo.NumNodes
+}
+
+// selectHost returns the host of the compute resource of the resource pool with the most
+// free memory if other hosts have less than the minimum free memory, are in maintenance mode
+// or were excluded from the placement of the VM. It returns nil if all hosts are available,
+// which leaves the placement to DRS.
+func selectHost(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool, minFreeMemMiB int64) (*types.ManagedObjectReference, error) {
+	hosts, err := resourcePoolHosts(ctx, pool, "name", "runtime.inMaintenanceMode", "summary.hardware.memorySize", "summary.quickStats.overallMemoryUsage")
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get memory usage of hosts of resource pool %q", pool)
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	available := filterAvailableHosts(hosts, vmCtx.VSphereVM.Status.ExcludedHosts)
+	if len(available) == 0 {
+		// Start over with all hosts on the next attempt, as hosts may have
+		// exited maintenance mode in the meantime.
+		vmCtx.VSphereVM.Status.ExcludedHosts = nil
+		return nil, errors.Wrapf(ErrHostsUnavailable, "hosts of resource pool %q", pool)
+	}
+	candidates := filterHostsByFreeMemory(available, minFreeMemMiB)
+	if len(candidates) == 0 {
+		return nil, errors.Wrapf(ErrInsufficientHostMemory, "%d MiB required on hosts of resource pool %q", minFreeMemMiB, pool)
+	}
+	if len(candidates) == len(hosts) {
+		return nil, nil
+	}
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Placing VM on host with most free memory", "host", candidates[0].Name, "excludedHosts", len(hosts)-len(candidates))
+	return types.NewReference(candidates[0].Reference()), nil
+}
+
+// PinnedHost returns the host of the spec of the VM. It returns an error wrapping
+// ErrHostsUnavailable if the host is not connected or in maintenance mode, and an error
+// if the host is not a host of the compute resource of the resource pool.
+func PinnedHost(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool) (*types.ManagedObjectReference, error) {
+	hostName := vmCtx.VSphereVM.Spec.Host
+	hostSystem, err := vmCtx.Session.Finder.HostSystem(ctx, hostName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find host %q", hostName)
+	}
+	var host mo.HostSystem
+	if err := hostSystem.Properties(ctx, hostSystem.Reference(), []string{"parent", "runtime.connectionState", "runtime.inMaintenanceMode"}, &host); err != nil {
+		return nil, errors.Wrapf(err, "unable to get state of host %q", hostName)
+	}
+	if host.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+		return nil, errors.Wrapf(ErrHostsUnavailable, "host %q is %s", hostName, host.Runtime.ConnectionState)
+	}
+	if host.Runtime.InMaintenanceMode {
+		return nil, errors.Wrapf(ErrHostsUnavailable, "host %q is in maintenance mode", hostName)
+	}
+
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get owning compute resource of resource pool %q", pool)
+	}
+	if host.Parent == nil || *host.Parent != owner.Reference() {
+		return nil, errors.Errorf("host %q is not a host of the compute resource of resource pool %q", hostName, pool)
+	}
+	return types.NewReference(hostSystem.Reference()), nil
+}
+
+// hostCPUMhz returns the CPU frequency of the given host in MHz, or the lowest CPU frequency
+// of the hosts of the compute resource of the resource pool if no host is given, as DRS may
+// place the VM on any of them.
+func hostCPUMhz(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool, hostRef *types.ManagedObjectReference) (int32, error) {
+	props := []string{"name", "summary.hardware.cpuMhz"}
+	var hosts []mo.HostSystem
+	if hostRef != nil {
+		pc := property.DefaultCollector(vmCtx.Session.Client.Client)
+		if err := pc.Retrieve(ctx, []types.ManagedObjectReference{*hostRef}, props, &hosts); err != nil {
+			return 0, errors.Wrapf(err, "unable to get CPU frequency of host %s", hostRef.Value)
+		}
+	} else {
+		var err error
+		if hosts, err = resourcePoolHosts(ctx, pool, props...); err != nil {
+			return 0, errors.Wrapf(err, "unable to get CPU frequency of hosts of resource pool %q", pool)
+		}
+	}
+	if len(hosts) == 0 {
+		return 0, errors.Errorf("no host found for resource pool %q", pool)
+	}
+	var cpuMhz int32
+	for _, host := range hosts {
+		if host.Summary.Hardware == nil || host.Summary.Hardware.CpuMhz <= 0 {
+			return 0, errors.Errorf("host %s does not report its CPU frequency", host.Name)
+		}
+		if cpuMhz == 0 || host.Summary.Hardware.CpuMhz < cpuMhz {
+			cpuMhz = host.Summary.Hardware.CpuMhz
+		}
+	}
+	return cpuMhz, nil
+}
+
+// cpuReservationMhz returns the CPU reservation in MHz for the given percentage of the CPUs
+// of a VM running at the given frequency.
+func cpuReservationMhz(numCPUs, cpuMhz, percent int32) int64 {
+	return int64(numCPUs) * int64(cpuMhz) * int64(percent) / 100
+}
+
+// filterAvailableHosts returns the hosts which are not in maintenance mode and not excluded
+// from the placement of the VM.
+func filterAvailableHosts(hosts []mo.HostSystem, excludedHosts []string) []mo.HostSystem {
+	var available []mo.HostSystem
+	for _, host := range hosts {
+		if host.Runtime.InMaintenanceMode || slices.Contains(excludedHosts, host.Name) {
+			continue
+		}
+		available = append(available, host)
+	}
+	return available
+}
+
+// filterHostsByFreeMemory returns the hosts with at least the minimum free memory, sorted
+// by their free memory in descending order.
+func filterHostsByFreeMemory(hosts []mo.HostSystem, minFreeMemMiB int64) []mo.HostSystem {
+	var candidates []mo.HostSystem
+	for _, host := range hosts {
+		if hostFreeMemoryMiB(host) >= minFreeMemMiB {
+			candidates = append(candidates, host)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return hostFreeMemoryMiB(candidates[i]) > hostFreeMemoryMiB(candidates[j])
+	})
+	return candidates
+}
+
+// hostFreeMemoryMiB returns the memory of the host which is not used, in MiB.
+func hostFreeMemoryMiB(host mo.HostSystem) int64 {
+	if host.Summary.Hardware == nil {
+		return 0
+	}
+	return host.Summary.Hardware.MemorySize/1024/1024 - int64(host.Summary.QuickStats.OverallMemoryUsage)
+}
+
+// hasSriovNetworkDevice returns true if any of the network devices is a SR-IOV adapter,
+// which requires the memory of the VM to be reserved like PCI devices.
+func hasSriovNetworkDevice(devices []infrav1.NetworkDeviceSpec) bool {
+	for _, device := range devices {
+		if device.AdapterType == infrav1.NetworkAdapterTypeSriov {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnerExtraConfig returns the VMX keys identifying the cluster, Machine and namespace of
+// a VM, so that tools without access to the management cluster can map the VM back to
+// its owner. Keys whose value is not known are omitted.
+func OwnerExtraConfig(vsphereVM *infrav1.VSphereVM, machineName string) map[string]string {
+	extraConfig := map[string]string{
+		extra.NamespaceKey: vsphereVM.Namespace,
+	}
+	if clusterName := vsphereVM.Labels[clusterv1.ClusterNameLabel]; clusterName != "" {
+		extraConfig[extra.ClusterNameKey] = clusterName
+	}
+	if machineName != "" {
+		extraConfig[extra.MachineNameKey] = machineName
+	}
+	return extraConfig
+}
+
+// LoggingOptionsExtraConfig returns the VMX keys of the logging options of a VM.
+func LoggingOptionsExtraConfig(logging *infrav1.VirtualMachineLoggingOptions) map[string]string {
+	extraConfig := map[string]string{}
+	if logging.Enabled != nil {
+		extraConfig["logging"] = strings.ToUpper(strconv.FormatBool(*logging.Enabled))
+	}
+	if logging.RotateSize != nil {
+		extraConfig["log.rotateSize"] = strconv.FormatInt(*logging.RotateSize, 10)
+	}
+	if logging.KeepOld != nil {
+		extraConfig["log.keepOld"] = strconv.FormatInt(int64(*logging.KeepOld), 10)
+	}
+	return extraConfig
+}
+
+// MemoryBackingExtraConfig returns the VMX keys of the memory backing of a VM. Page sharing
+// is disabled, as it breaks up the huge pages backing the memory.
+func MemoryBackingExtraConfig(backing *infrav1.VirtualMachineMemoryBacking) map[string]string {
+	return map[string]string{
+		"sched.mem.pshare.enable":      "FALSE",
+		"sched.mem.lpage.enable1GPage": strings.ToUpper(strconv.FormatBool(backing.HugePageSize == infrav1.HugePageSize1Gi)),
+	}
+}
+
+// HugePagesRequireReservation returns whether the memory backing requires the memory of the
+// VM to be reserved, which is the case for 1Gi huge pages.
+func HugePagesRequireReservation(backing *infrav1.VirtualMachineMemoryBacking) bool {
+	return backing != nil && backing.HugePageSize == infrav1.HugePageSize1Gi
+}
+
+// EffectiveIsolation returns the isolation of a VM. If hardened, the settings which are not
+// defined default to the most restrictive value.
+func EffectiveIsolation(isolation *infrav1.VirtualMachineIsolation, hardened bool) *infrav1.VirtualMachineIsolation {
+	if !hardened {
+		return isolation
+	}
+	effective := &infrav1.VirtualMachineIsolation{}
+	if isolation != nil {
+		effective = isolation.DeepCopy()
+	}
+	for _, setting := range []**bool{&effective.CopyPasteDisabled, &effective.DeviceConnectDisabled, &effective.VMCIRestricted} {
+		if *setting == nil {
+			*setting = ptr.To(true)
+		}
+	}
+	return effective
+}
+
+// NUMANodeAffinityExtraConfig returns the VMX key which constrains a VM to the NUMA nodes of
+// its host.
+func NUMANodeAffinityExtraConfig(nodes []int32) map[string]string {
+	return map[string]string{
+		"numa.nodeAffinity": NUMANodeAffinityValue(nodes),
+	}
+}
+
+// NUMANodeAffinityValue returns the NUMA nodes as the comma-separated list of the
+// numa.nodeAffinity VMX key, in ascending order.
+func NUMANodeAffinityValue(nodes []int32) string {
+	sorted := slices.Clone(nodes)
+	slices.Sort(sorted)
+	values := make([]string, 0, len(sorted))
+	for _, node := range sorted {
+		values = append(values, strconv.FormatInt(int64(node), 10))
+	}
+	return strings.Join(values, ",")
+}
+
+// DiskEnableUUIDExtraConfig returns the VMX key which exposes the UUIDs of the disks of a
+// VM to the guest.
+func DiskEnableUUIDExtraConfig(enabled bool) map[string]string {
+	return map[string]string{
+		"disk.EnableUUID": strings.ToUpper(strconv.FormatBool(enabled)),
+	}
+}
+
+// DiskUUID returns the UUID of the backing of a disk, or an empty string if the backing
+// has no UUID.
+func DiskUUID(disk *types.VirtualDisk) string {
+	switch backing := disk.Backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		return backing.Uuid
+	case *types.VirtualDiskSeSparseBackingInfo:
+		return backing.Uuid
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		return backing.Uuid
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		return backing.Uuid
+	default:
+		return ""
+	}
+}
+
+// IsolationExtraConfig returns the VMX keys of the isolation of a VM.
+func IsolationExtraConfig(isolation *infrav1.VirtualMachineIsolation) map[string]string {
+	extraConfig := map[string]string{}
+	if isolation.CopyPasteDisabled != nil {
+		disabled := strings.ToUpper(strconv.FormatBool(*isolation.CopyPasteDisabled))
+		extraConfig["isolation.tools.copy.disable"] = disabled
+		extraConfig["isolation.tools.paste.disable"] = disabled
+		extraConfig["isolation.tools.dnd.disable"] = disabled
+		extraConfig["isolation.tools.setGUIOptions.enable"] = strings.ToUpper(strconv.FormatBool(!*isolation.CopyPasteDisabled))
+	}
+	if isolation.DeviceConnectDisabled != nil {
+		disabled := strings.ToUpper(strconv.FormatBool(*isolation.DeviceConnectDisabled))
+		extraConfig["isolation.device.connectable.disable"] = disabled
+		extraConfig["isolation.device.edit.disable"] = disabled
+	}
+	if isolation.VMCIRestricted != nil {
+		extraConfig["vmci0.unrestricted"] = strings.ToUpper(strconv.FormatBool(!*isolation.VMCIRestricted))
+	}
+	return extraConfig
+}
+
+// ResourceAllocationInfo returns the resource allocation of a VM for the given spec.
+func ResourceAllocationInfo(allocation *infrav1.ResourceAllocationSpec) *types.ResourceAllocationInfo {
+	info := &types.ResourceAllocationInfo{
+		Reservation: allocation.Reservation,
+		Limit:       allocation.Limit,
+	}
+	if allocation.Shares != nil {
+		info.Shares = &types.SharesInfo{
+			Level:  types.SharesLevel(allocation.Shares.Level),
+			Shares: allocation.Shares.Shares,
+		}
+	}
+	return info
+}
+
+// StorageIOAllocationInfo returns the Storage I/O Control allocation of a disk for the given spec.
+func StorageIOAllocationInfo(allocation *infrav1.DiskStorageIOAllocation) *types.StorageIOAllocationInfo {
+	info := &types.StorageIOAllocationInfo{
+		Limit: allocation.Limit,
+	}
+	if allocation.Shares != nil {
+		info.Shares = &types.SharesInfo{
+			Level:  types.SharesLevel(allocation.Shares.Level),
+			Shares: allocation.Shares.Shares,
+		}
+	}
+	return info
+}
+
+// CheckStorageIOControlEnabled returns an error if Storage I/O Control is not enabled on
+// any of the given datastores, as the shares and limits of the disks on them are not enforced.
+func CheckStorageIOControlEnabled(ctx context.Context, client *vim25.Client, datastoreRefs []types.ManagedObjectReference) error {
+	var datastores []mo.Datastore
+	pc := property.DefaultCollector(client)
+	if err := pc.Retrieve(ctx, datastoreRefs, []string{"name", "iormConfiguration"}, &datastores); err != nil {
+		return errors.Wrap(err, "unable to get Storage I/O Control configuration of datastores")
+	}
+	for _, datastore := range datastores {
+		if datastore.IormConfiguration == nil || !datastore.IormConfiguration.Enabled {
+			return errors.Errorf("Storage I/O Control is not enabled on datastore %s", datastore.Name)
+		}
+	}
+	return nil
+}
+
+// getVAppConfigSpec returns the vApp configuration which delivers the OVF environment
+// through its transport. The properties of the OVF environment must be defined in the
+// vApp configuration of the template, i.e. in its OVF descriptor.
+func getVAppConfigSpec(templateVAppConfig types.BaseVmConfigInfo, ovfEnv *infrav1.OVFEnvironmentSpec) (*types.VmConfigSpec, error) {
+	spec := &types.VmConfigSpec{
+		OvfEnvironmentTransport: []string{string(ovfEnv.Transport)},
+	}
+	if len(ovfEnv.Properties) == 0 {
+		return spec, nil
+	}
+	if templateVAppConfig == nil {
+		return nil, errors.New("template does not define OVF properties")
+	}
+
+	templateProperties := map[string]types.VAppPropertyInfo{}
+	for _, property := range templateVAppConfig.GetVmConfigInfo().Property {
+		templateProperties[property.Id] = property
+	}
+	ids := make([]string, 0, len(ovfEnv.Properties))
+	for id := range ovfEnv.Properties {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		property, ok := templateProperties[id]
+		if !ok {
+			return nil, errors.Errorf("OVF property %q is not defined by the template", id)
+		}
+		if !ptr.Deref(property.UserConfigurable, false) {
+			return nil, errors.Errorf("OVF property %q is not user configurable", id)
+		}
+		spec.Property = append(spec.Property, types.VAppPropertySpec{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationEdit},
+			Info: &types.VAppPropertyInfo{
+				Key:   property.Key,
+				Id:    property.Id,
+				Value: ovfEnv.Properties[id],
+			},
+		})
+	}
+	return spec, nil
+}
@@ -19,12 +19,20 @@ package vcenter
 import (
 	ctx "context"
 	"crypto/tls"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
 	"github.com/vmware/govmomi/simulator"
 	_ "github.com/vmware/govmomi/vapi/simulator" // run init func to register the tagging API endpoints.
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
@@ -140,6 +148,815 @@ func TestGetDiskSpec(t *testing.T) {
 	}
 }
 
+func TestGetAdditionalDisksControllerSpecs(t *testing.T) {
+	newDevices := func(additionalDiskController int32, eagerlyScrub bool) object.VirtualDeviceList {
+		return object.VirtualDeviceList{
+			&types.ParaVirtualSCSIController{VirtualSCSIController: types.VirtualSCSIController{
+				VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 1000}},
+				SharedBus:         types.VirtualSCSISharingNoSharing,
+			}},
+			&types.ParaVirtualSCSIController{VirtualSCSIController: types.VirtualSCSIController{
+				VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 1001}, BusNumber: 1},
+				SharedBus:         types.VirtualSCSISharingNoSharing,
+			}},
+			&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2000, ControllerKey: 1000}},
+			&types.VirtualDisk{VirtualDevice: types.VirtualDevice{
+				Key:           2001,
+				ControllerKey: additionalDiskController,
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					EagerlyScrub:    &eagerlyScrub,
+					ThinProvisioned: ptr.To(false),
+				},
+			}},
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		busSharing        infrav1.SCSIBusSharing
+		devices           object.VirtualDeviceList
+		expectedSharedBus types.VirtualSCSISharing
+		err               string
+	}{
+		{
+			name:    "Leaves the SCSI controllers unchanged without bus sharing",
+			devices: newDevices(1001, true),
+		},
+		{
+			name:              "Shares the bus of the SCSI controller of the additional disks",
+			busSharing:        infrav1.SCSIBusSharingPhysical,
+			devices:           newDevices(1001, true),
+			expectedSharedBus: types.VirtualSCSISharingPhysicalSharing,
+		},
+		{
+			name:              "Does not share the bus of the SCSI controller of the first disk",
+			busSharing:        infrav1.SCSIBusSharingNone,
+			devices:           newDevices(1000, false),
+			expectedSharedBus: types.VirtualSCSISharingNoSharing,
+		},
+		{
+			name:       "Fails to share the bus of the SCSI controller of the first disk",
+			busSharing: infrav1.SCSIBusSharingVirtual,
+			devices:    newDevices(1000, true),
+			err:        "additional disk disk-1000-0 is attached to the SCSI controller of the first disk, which cannot share its bus",
+		},
+		{
+			name:       "Fails to share the bus of additional disks which are not eagerZeroedThick",
+			busSharing: infrav1.SCSIBusSharingVirtual,
+			devices:    newDevices(1001, false),
+			err:        "additional disk disk-1001-0 must be provisioned eagerZeroedThick to share its bus",
+		},
+		{
+			name:       "Fails to share the bus without additional disks",
+			busSharing: infrav1.SCSIBusSharingVirtual,
+			devices:    newDevices(1001, true)[:3],
+			err:        "bus sharing virtual requires additional disks in the template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vmContext := &capvcontext.VMContext{VSphereVM: &infrav1.VSphereVM{}}
+			if tc.busSharing != "" {
+				vmContext.VSphereVM.Spec.AdditionalDisksController = &infrav1.AdditionalDisksControllerSpec{BusSharing: tc.busSharing}
+			}
+			specs, err := getAdditionalDisksControllerSpecs(vmContext, tc.devices)
+			if (tc.err != "" && err == nil) || (tc.err == "" && err != nil) || (err != nil && tc.err != err.Error()) {
+				t.Fatalf("Expected to get '%v' error from getAdditionalDisksControllerSpecs, got: '%v'", tc.err, err)
+			}
+			if tc.expectedSharedBus == "" {
+				if len(specs) != 0 {
+					t.Fatalf("Expected no controller specs, got: '%#v'", specs)
+				}
+				return
+			}
+			if len(specs) != 1 {
+				t.Fatalf("Expected one controller spec, got: '%#v'", specs)
+			}
+			controller := specs[0].GetVirtualDeviceConfigSpec().Device.(types.BaseVirtualSCSIController).GetVirtualSCSIController()
+			if controller.SharedBus != tc.expectedSharedBus {
+				t.Errorf("Shared bus does not match: expected %s, got %s", tc.expectedSharedBus, controller.SharedBus)
+			}
+			if busSharing := AdditionalDisksBusSharing(tc.devices); busSharing != tc.busSharing {
+				t.Errorf("Bus sharing does not match: expected %s, got %s", tc.busSharing, busSharing)
+			}
+		})
+	}
+}
+
+func TestGetAdditionalDiskSpecs(t *testing.T) {
+	templateDevices := func(controller types.BaseVirtualDevice) object.VirtualDeviceList {
+		controller.GetVirtualDevice().Key = 1000
+		return object.VirtualDeviceList{
+			controller,
+			&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2000, ControllerKey: 1000, UnitNumber: ptr.To[int32](0)}},
+		}
+	}
+	disksGiB := func(n int) []int32 {
+		// The first entry is the size of the second disk, which is not in the template.
+		sizes := make([]int32, n)
+		for i := range sizes {
+			sizes[i] = 10
+		}
+		return sizes
+	}
+	type placement struct {
+		controllerKey int32
+		unitNumber    int32
+	}
+
+	tests := []struct {
+		name               string
+		cloneSpec          infrav1.VirtualMachineCloneSpec
+		controller         types.BaseVirtualDevice
+		expectedBusNumbers []int32
+		expectedDisks      []placement
+		expectedErr        string
+	}{
+		{
+			name:       "no additional disks",
+			cloneSpec:  infrav1.VirtualMachineCloneSpec{},
+			controller: &types.ParaVirtualSCSIController{},
+		},
+		{
+			name:          "additional disks on the controller of the template",
+			cloneSpec:     infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: disksGiB(2)},
+			controller:    &types.ParaVirtualSCSIController{},
+			expectedDisks: []placement{{1000, 1}, {1000, 2}},
+		},
+		{
+			name:               "additional disks exceeding the controller of the template",
+			cloneSpec:          infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: disksGiB(16)},
+			controller:         &types.VirtualLsiLogicController{},
+			expectedBusNumbers: []int32{1},
+			expectedDisks: []placement{
+				{1000, 1}, {-300, 0}, {1000, 2}, {-300, 1}, {1000, 3}, {-300, 2}, {1000, 4}, {-300, 3},
+				{1000, 5}, {-300, 4}, {1000, 6}, {-300, 5}, {1000, 8}, {-300, 6}, {1000, 9}, {-300, 8},
+			},
+		},
+		{
+			name:               "additional disks across an explicit number of controllers",
+			cloneSpec:          infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: disksGiB(3), SCSIControllerCount: 4},
+			controller:         &types.ParaVirtualSCSIController{},
+			expectedBusNumbers: []int32{1, 2, 3},
+			expectedDisks:      []placement{{1000, 1}, {-300, 0}, {-301, 0}},
+		},
+		{
+			name:        "additional disks exceeding the explicit number of controllers",
+			cloneSpec:   infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: disksGiB(15), SCSIControllerCount: 1},
+			controller:  &types.ParaVirtualSCSIController{},
+			expectedErr: "15 additional disks require 2 SCSI controllers, but scsiControllerCount is 1",
+		},
+		{
+			name:        "additional disks exceeding the controllers of a VM",
+			cloneSpec:   infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: disksGiB(60)},
+			controller:  &types.ParaVirtualSCSIController{},
+			expectedErr: "60 additional disks require 5 SCSI controllers, but a VM supports at most 4",
+		},
+		{
+			name:        "additional disk without size",
+			cloneSpec:   infrav1.VirtualMachineCloneSpec{AdditionalDisksGiB: []int32{0}},
+			controller:  &types.ParaVirtualSCSIController{},
+			expectedErr: "additional disk 1 is not in the template and requires a size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := getAdditionalDiskSpecs(tt.cloneSpec, templateDevices(tt.controller))
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var busNumbers []int32
+			var disks []placement
+			for _, spec := range specs {
+				switch device := spec.GetVirtualDeviceConfigSpec().Device.(type) {
+				case *types.VirtualDisk:
+					disks = append(disks, placement{device.ControllerKey, *device.UnitNumber})
+					if device.CapacityInKB != 10*1024*1024 {
+						t.Errorf("Expected a capacity of 10GiB, got %dKiB", device.CapacityInKB)
+					}
+				case types.BaseVirtualController:
+					if reflect.TypeOf(device) != reflect.TypeOf(tt.controller) {
+						t.Errorf("Expected a controller of type %T, got %T", tt.controller, device)
+					}
+					busNumbers = append(busNumbers, device.GetVirtualController().BusNumber)
+				}
+			}
+			if !reflect.DeepEqual(busNumbers, tt.expectedBusNumbers) {
+				t.Errorf("Expected controllers with bus numbers %v, got %v", tt.expectedBusNumbers, busNumbers)
+			}
+			if !reflect.DeepEqual(disks, tt.expectedDisks) {
+				t.Errorf("Expected disks %v, got %v", tt.expectedDisks, disks)
+			}
+		})
+	}
+}
+
+func TestGetSerialPortSpecs(t *testing.T) {
+	devices := object.VirtualDeviceList{
+		&types.VirtualSIOController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 400}}},
+	}
+	serialPorts := []infrav1.SerialPortSpec{
+		{URI: "vSPC.py", ProxyURI: "telnets://vspc.example.com:13370"},
+		{File: "[datastore1] logs/console.log"},
+	}
+
+	deviceSpecs, err := getSerialPortSpecs(devices, serialPorts)
+	if err != nil {
+		t.Fatalf("Unexpected error from getSerialPortSpecs: %v", err)
+	}
+	if len(deviceSpecs) != len(serialPorts) {
+		t.Fatalf("Expected %d serial port specs, got %d", len(serialPorts), len(deviceSpecs))
+	}
+
+	unitNumbers := map[int32]bool{}
+	for _, deviceSpec := range deviceSpecs {
+		spec := deviceSpec.GetVirtualDeviceConfigSpec()
+		if spec.Operation != types.VirtualDeviceConfigSpecOperationAdd {
+			t.Errorf("Serial port operation does not match '%s', got: %s", types.VirtualDeviceConfigSpecOperationAdd, spec.Operation)
+		}
+		device := spec.Device.GetVirtualDevice()
+		if device.ControllerKey != 400 {
+			t.Errorf("Serial port is not attached to the SIO controller, got controller key %d", device.ControllerKey)
+		}
+		unitNumbers[*device.UnitNumber] = true
+	}
+	if len(unitNumbers) != len(serialPorts) {
+		t.Errorf("Expected serial ports to have distinct unit numbers, got %v", unitNumbers)
+	}
+
+	uriBacking, ok := deviceSpecs[0].GetVirtualDeviceConfigSpec().Device.GetVirtualDevice().Backing.(*types.VirtualSerialPortURIBackingInfo)
+	if !ok || uriBacking.ProxyURI != serialPorts[0].ProxyURI || uriBacking.Direction != string(types.VirtualDeviceURIBackingOptionDirectionClient) {
+		t.Errorf("Unexpected backing of network serial port: %#v", deviceSpecs[0].GetVirtualDeviceConfigSpec().Device.GetVirtualDevice().Backing)
+	}
+	fileBacking, ok := deviceSpecs[1].GetVirtualDeviceConfigSpec().Device.GetVirtualDevice().Backing.(*types.VirtualSerialPortFileBackingInfo)
+	if !ok || fileBacking.FileName != serialPorts[1].File {
+		t.Errorf("Unexpected backing of file serial port: %#v", deviceSpecs[1].GetVirtualDeviceConfigSpec().Device.GetVirtualDevice().Backing)
+	}
+}
+
+func TestGetVAppConfigSpec(t *testing.T) {
+	templateVAppConfig := &types.VmConfigInfo{
+		Property: []types.VAppPropertyInfo{
+			{Key: 1, Id: "hostname", UserConfigurable: ptr.To(true)},
+			{Key: 2, Id: "version", UserConfigurable: ptr.To(false)},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		templateVAppConfig types.BaseVmConfigInfo
+		properties         map[string]string
+		err                string
+	}{
+		{
+			name:               "Successfully set user configurable property",
+			templateVAppConfig: templateVAppConfig,
+			properties:         map[string]string{"hostname": "appliance"},
+		},
+		{
+			name: "Successfully set transport of template without properties",
+		},
+		{
+			name:               "Fail to set property which is not defined by the template",
+			templateVAppConfig: templateVAppConfig,
+			properties:         map[string]string{"domain": "example.com"},
+			err:                `OVF property "domain" is not defined by the template`,
+		},
+		{
+			name:               "Fail to set property which is not user configurable",
+			templateVAppConfig: templateVAppConfig,
+			properties:         map[string]string{"version": "2"},
+			err:                `OVF property "version" is not user configurable`,
+		},
+		{
+			name:       "Fail to set property of template without vApp configuration",
+			properties: map[string]string{"hostname": "appliance"},
+			err:        "template does not define OVF properties",
+		},
+	}
+
+	for _, test := range testCases {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			ovfEnv := &infrav1.OVFEnvironmentSpec{
+				Transport:  infrav1.OVFEnvironmentTransportGuestInfo,
+				Properties: tc.properties,
+			}
+			spec, err := getVAppConfigSpec(tc.templateVAppConfig, ovfEnv)
+			if (tc.err != "" && err == nil) || (tc.err == "" && err != nil) || (err != nil && tc.err != err.Error()) {
+				t.Fatalf("Expected to get '%v' error from getVAppConfigSpec, got: '%v'", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(spec.OvfEnvironmentTransport) != 1 || spec.OvfEnvironmentTransport[0] != string(infrav1.OVFEnvironmentTransportGuestInfo) {
+				t.Errorf("Unexpected OVF environment transport, got: %v", spec.OvfEnvironmentTransport)
+			}
+			if len(spec.Property) != len(tc.properties) {
+				t.Fatalf("Expected %d property specs, got %d", len(tc.properties), len(spec.Property))
+			}
+			for _, property := range spec.Property {
+				if property.Operation != types.ArrayUpdateOperationEdit {
+					t.Errorf("Property operation does not match '%s', got: %s", types.ArrayUpdateOperationEdit, property.Operation)
+				}
+				if property.Info.Key != 1 || property.Info.Value != tc.properties[property.Info.Id] {
+					t.Errorf("Unexpected property spec: %#v", property.Info)
+				}
+			}
+		})
+	}
+}
+
+func TestExcludeFullDatastores(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	var hubs []pbmTypes.PbmPlacementHub
+	var fullDatastore string
+	for _, obj := range simulator.Map.All("Datastore") {
+		datastore := obj.(*simulator.Datastore)
+		hubs = append(hubs, pbmTypes.PbmPlacementHub{HubType: datastore.Self.Type, HubId: datastore.Self.Value})
+		fullDatastore = datastore.Name
+	}
+	if len(hubs) < 2 {
+		t.Fatalf("Expected multiple datastores, got %d", len(hubs))
+	}
+
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+	candidates, err := excludeFullDatastores(ctx.TODO(), vmContext, hubs)
+	if err != nil {
+		t.Fatalf("Unexpected error from excludeFullDatastores: %v", err)
+	}
+	if len(candidates) != len(hubs) {
+		t.Errorf("Expected %d candidates without full datastores, got %d", len(hubs), len(candidates))
+	}
+
+	// Simulate one of the datastores running out of space.
+	vmContext.VSphereVM.Status.ExcludedDatastores = []string{fullDatastore}
+	candidates, err = excludeFullDatastores(ctx.TODO(), vmContext, hubs)
+	if err != nil {
+		t.Fatalf("Unexpected error from excludeFullDatastores: %v", err)
+	}
+	if len(candidates) != len(hubs)-1 {
+		t.Errorf("Expected %d candidates with a full datastore, got %d", len(hubs)-1, len(candidates))
+	}
+	for _, candidate := range candidates {
+		if ds := simulator.Map.Get(candidate).(*simulator.Datastore); ds.Name == fullDatastore {
+			t.Errorf("Expected full datastore %s to be excluded", fullDatastore)
+		}
+	}
+}
+
+func TestFilterHostsByFreeMemory(t *testing.T) {
+	host := func(name string, memorySizeMiB int64, usageMiB int32) mo.HostSystem {
+		h := mo.HostSystem{}
+		h.Name = name
+		h.Summary.Hardware = &types.HostHardwareSummary{MemorySize: memorySizeMiB * 1024 * 1024}
+		h.Summary.QuickStats.OverallMemoryUsage = usageMiB
+		return h
+	}
+	hosts := []mo.HostSystem{
+		host("almost-full", 4096, 3584),
+		host("half-full", 4096, 2048),
+		host("empty", 4096, 0),
+		host("unknown", 0, 0),
+	}
+	hosts[3].Summary.Hardware = nil
+
+	tests := []struct {
+		name          string
+		minFreeMemMiB int64
+		expected      []string
+	}{
+		{
+			name:          "all hosts with memory",
+			minFreeMemMiB: 512,
+			expected:      []string{"empty", "half-full", "almost-full"},
+		},
+		{
+			name:          "hosts with enough free memory",
+			minFreeMemMiB: 1024,
+			expected:      []string{"empty", "half-full"},
+		},
+		{
+			name:          "no host with enough free memory",
+			minFreeMemMiB: 8192,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, candidate := range filterHostsByFreeMemory(hosts, tt.minFreeMemMiB) {
+				names = append(names, candidate.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected hosts %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSelectHostWithFreeMemory(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hosts []*simulator.HostSystem
+	for _, obj := range simulator.Map.All("HostSystem") {
+		if host := obj.(*simulator.HostSystem); strings.HasPrefix(host.Name, "DC0_C0_") {
+			host.Summary.Hardware.MemorySize = 4096 * 1024 * 1024
+			host.Summary.QuickStats.OverallMemoryUsage = 0
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) < 2 {
+		t.Fatalf("Expected multiple hosts in the cluster, got %d", len(hosts))
+	}
+
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+
+	// All hosts have enough free memory, so the placement is left to DRS.
+	hostRef, err := selectHost(ctx.TODO(), vmContext, pool, 1024)
+	if err != nil {
+		t.Fatalf("Unexpected error from selectHost: %v", err)
+	}
+	if hostRef != nil {
+		t.Errorf("Expected no host, got %v", hostRef)
+	}
+
+	// Simulate all hosts but one running low on memory.
+	for _, host := range hosts[1:] {
+		host.Summary.QuickStats.OverallMemoryUsage = 3584
+	}
+	hostRef, err = selectHost(ctx.TODO(), vmContext, pool, 1024)
+	if err != nil {
+		t.Fatalf("Unexpected error from selectHost: %v", err)
+	}
+	if hostRef == nil || *hostRef != hosts[0].Self {
+		t.Errorf("Expected host %v, got %v", hosts[0].Self, hostRef)
+	}
+
+	// Simulate all hosts running low on memory.
+	hosts[0].Summary.QuickStats.OverallMemoryUsage = 3584
+	_, err = selectHost(ctx.TODO(), vmContext, pool, 1024)
+	if !errors.Is(err, ErrInsufficientHostMemory) {
+		t.Errorf("Expected ErrInsufficientHostMemory, got %v", err)
+	}
+}
+
+func TestSelectHostExcludesUnavailableHosts(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hosts []*simulator.HostSystem
+	for _, obj := range simulator.Map.All("HostSystem") {
+		if host := obj.(*simulator.HostSystem); strings.HasPrefix(host.Name, "DC0_C0_") {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) < 3 {
+		t.Fatalf("Expected at least three hosts in the cluster, got %d", len(hosts))
+	}
+
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+
+	// Simulate a host entering maintenance mode while cloning the VM and
+	// another one being in maintenance mode already.
+	vmContext.VSphereVM.Status.ExcludedHosts = []string{hosts[0].Name}
+	hosts[1].Runtime.InMaintenanceMode = true
+	hostRef, err := selectHost(ctx.TODO(), vmContext, pool, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from selectHost: %v", err)
+	}
+	if hostRef == nil || *hostRef == hosts[0].Self || *hostRef == hosts[1].Self {
+		t.Errorf("Expected a host other than %s and %s, got %v", hosts[0].Name, hosts[1].Name, hostRef)
+	}
+
+	// Simulate all hosts being unavailable.
+	for _, host := range hosts {
+		host.Runtime.InMaintenanceMode = true
+	}
+	_, err = selectHost(ctx.TODO(), vmContext, pool, 0)
+	if !errors.Is(err, ErrHostsUnavailable) {
+		t.Errorf("Expected ErrHostsUnavailable, got %v", err)
+	}
+	if len(vmContext.VSphereVM.Status.ExcludedHosts) != 0 {
+		t.Errorf("Expected excluded hosts to be reset, got %v", vmContext.VSphereVM.Status.ExcludedHosts)
+	}
+}
+
+func TestResourcePoolHosts(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	dc, err := session.Finder.DefaultDatacenter(ctx.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders, err := dc.Folders(ctx.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := folders.HostFolder.CreateCluster(ctx.TODO(), "DC0_C1", types.ClusterConfigSpecEx{}); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := resourcePoolHosts(ctx.TODO(), pool, "name")
+	if err != nil {
+		t.Fatalf("Unexpected error from resourcePoolHosts: %v", err)
+	}
+	if len(hosts) == 0 {
+		t.Fatal("Expected the hosts of the cluster, got none")
+	}
+	for _, host := range hosts {
+		if !strings.HasPrefix(host.Name, "DC0_C0_") {
+			t.Errorf("Expected only hosts of the cluster of the resource pool, got %s", host.Name)
+		}
+	}
+
+	// The hosts of a compute resource without hosts are empty.
+	pool, err = session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C1/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err = resourcePoolHosts(ctx.TODO(), pool, "name")
+	if err != nil {
+		t.Fatalf("Unexpected error from resourcePoolHosts: %v", err)
+	}
+	if len(hosts) != 0 {
+		t.Errorf("Expected no hosts, got %d", len(hosts))
+	}
+}
+
+func TestHostCPUMhz(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hosts []*simulator.HostSystem
+	for _, obj := range simulator.Map.All("HostSystem") {
+		if host := obj.(*simulator.HostSystem); strings.HasPrefix(host.Name, "DC0_C0_") {
+			host.Summary.Hardware.CpuMhz = 2600
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) < 2 {
+		t.Fatalf("Expected multiple hosts in the cluster, got %d", len(hosts))
+	}
+	hosts[1].Summary.Hardware.CpuMhz = 2000
+
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+
+	// The host selected for the VM determines the frequency.
+	cpuMhz, err := hostCPUMhz(ctx.TODO(), vmContext, pool, &hosts[0].Self)
+	if err != nil {
+		t.Fatalf("Unexpected error from hostCPUMhz: %v", err)
+	}
+	if cpuMhz != 2600 {
+		t.Errorf("Expected 2600 MHz of the selected host, got %d", cpuMhz)
+	}
+
+	// Without a selected host, the lowest frequency of the hosts is used.
+	cpuMhz, err = hostCPUMhz(ctx.TODO(), vmContext, pool, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error from hostCPUMhz: %v", err)
+	}
+	if cpuMhz != 2000 {
+		t.Errorf("Expected 2000 MHz of the slowest host, got %d", cpuMhz)
+	}
+}
+
+func TestCheckComputeCluster(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc, err := session.Finder.DefaultDatacenter(ctx.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders, err := dc.Folders(ctx.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := folders.HostFolder.CreateCluster(ctx.TODO(), "DC0_C1", types.ClusterConfigSpecEx{}); err != nil {
+		t.Fatal(err)
+	}
+
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+	tests := []struct {
+		name           string
+		computeCluster string
+		wantErr        bool
+	}{
+		{
+			name:           "compute cluster owning the resource pool",
+			computeCluster: "DC0_C0",
+		},
+		{
+			name:           "compute cluster owning the resource pool by inventory path",
+			computeCluster: "/DC0/host/DC0_C0",
+		},
+		{
+			name:           "compute cluster not owning the resource pool",
+			computeCluster: "DC0_C1",
+			wantErr:        true,
+		},
+		{
+			name:           "compute cluster which does not exist",
+			computeCluster: "DC0_C2",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkComputeCluster(ctx.TODO(), vmContext, pool, tt.computeCluster)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCPUReservationMhz(t *testing.T) {
+	tests := []struct {
+		name     string
+		numCPUs  int32
+		cpuMhz   int32
+		percent  int32
+		expected int64
+	}{
+		{name: "no reservation", numCPUs: 4, cpuMhz: 2600, percent: 0, expected: 0},
+		{name: "half of the CPUs", numCPUs: 4, cpuMhz: 2600, percent: 50, expected: 5200},
+		{name: "all of the CPUs", numCPUs: 2, cpuMhz: 2000, percent: 100, expected: 4000},
+		{name: "fraction of a MHz is truncated", numCPUs: 3, cpuMhz: 2001, percent: 33, expected: 1980},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuReservationMhz(tt.numCPUs, tt.cpuMhz, tt.percent); got != tt.expected {
+				t.Errorf("Expected %d MHz, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetNetworkSpecs(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name        string
+		adapterType infrav1.NetworkAdapterType
+		expectType  types.BaseVirtualDevice
+		err         string
+	}{
+		{
+			name:       "Successfully create vmxnet3 adapter by default",
+			expectType: &types.VirtualVmxnet3{},
+		},
+		{
+			name:        "Successfully create e1000e adapter",
+			adapterType: infrav1.NetworkAdapterTypeE1000e,
+			expectType:  &types.VirtualE1000e{},
+		},
+		{
+			name:        "Fail to create sriov adapter for physical function not supported by the hosts",
+			adapterType: infrav1.NetworkAdapterTypeSriov,
+			err:         `unable to create SR-IOV backing for network "VM Network": physical function 0000:3b:00.1 is not a SR-IOV capable adapter of the hosts of resource pool`,
+		},
+	}
+
+	for _, test := range testCases {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			device := infrav1.NetworkDeviceSpec{NetworkName: "VM Network", AdapterType: tc.adapterType}
+			if tc.adapterType == infrav1.NetworkAdapterTypeSriov {
+				device.PhysicalFunction = "0000:3b:00.1"
+			}
+			vmContext := &capvcontext.VMContext{
+				Session: session,
+				VSphereVM: &infrav1.VSphereVM{
+					Spec: infrav1.VSphereVMSpec{
+						VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+							Network: infrav1.NetworkSpec{Devices: []infrav1.NetworkDeviceSpec{device}},
+						},
+					},
+				},
+			}
+			deviceSpecs, err := getNetworkSpecs(ctx.TODO(), vmContext, object.VirtualDeviceList{})
+			if tc.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.err) || !errors.Is(err, ErrSriovUnavailable) {
+					t.Fatalf("Expected to get '%v' error from getNetworkSpecs, got: '%v'", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error from getNetworkSpecs: %v", err)
+			}
+			if len(deviceSpecs) != 1 {
+				t.Fatalf("Expected 1 network device spec, got %d", len(deviceSpecs))
+			}
+			if got, want := reflect.TypeOf(deviceSpecs[0].GetVirtualDeviceConfigSpec().Device), reflect.TypeOf(tc.expectType); got != want {
+				t.Errorf("Expected network adapter of type %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestHasActiveVirtualFunctions(t *testing.T) {
+	newHost := func(infos ...types.BaseHostPciPassthruInfo) mo.HostSystem {
+		return mo.HostSystem{Config: &types.HostConfigInfo{PciPassthruInfo: infos}}
+	}
+	sriovInfo := func(id string, active bool, numVirtualFunction int32) *types.HostSriovInfo {
+		return &types.HostSriovInfo{
+			HostPciPassthruInfo: types.HostPciPassthruInfo{Id: id},
+			SriovEnabled:        true,
+			SriovActive:         active,
+			NumVirtualFunction:  numVirtualFunction,
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		hosts  []mo.HostSystem
+		expect bool
+	}{
+		{
+			name:   "with active virtual functions",
+			hosts:  []mo.HostSystem{newHost(), newHost(sriovInfo("0000:3b:00.1", true, 8))},
+			expect: true,
+		},
+		{
+			name:  "with SR-IOV not active yet",
+			hosts: []mo.HostSystem{newHost(sriovInfo("0000:3b:00.1", false, 8))},
+		},
+		{
+			name:  "without virtual functions",
+			hosts: []mo.HostSystem{newHost(sriovInfo("0000:3b:00.1", true, 0))},
+		},
+		{
+			name:  "with other physical function",
+			hosts: []mo.HostSystem{newHost(sriovInfo("0000:3b:00.0", true, 8))},
+		},
+	}
+
+	for _, test := range testCases {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hasActiveVirtualFunctions(tc.hosts, "0000:3b:00.1"); got != tc.expect {
+				t.Errorf("Expected hasActiveVirtualFunctions to return %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
 func validateDiskSpec(t *testing.T, device types.BaseVirtualDeviceConfigSpec, cloneDiskSize int32) {
 	t.Helper()
 	disk := device.GetVirtualDeviceConfigSpec().Device.(*types.VirtualDisk)
@@ -161,6 +978,8 @@ func initSimulator(t *testing.T) (*simulator.Model, *session.Session, *simulator
 
 	model := simulator.VPX()
 	model.Host = 0
+	// Multiple datastores allow placing VMs on other datastores.
+	model.Datastore = 2
 	if err := model.Create(); err != nil {
 		t.Fatal(err)
 	}
@@ -182,3 +1001,383 @@ func initSimulator(t *testing.T) (*simulator.Model, *session.Session, *simulator
 
 	return model, authSession, server
 }
+
+func TestHostSupportsHugePages(t *testing.T) {
+	host := func(options ...types.BaseOptionValue) mo.HostSystem {
+		return mo.HostSystem{Config: &types.HostConfigInfo{Option: options}}
+	}
+	hostWithExtendedFeatures := func(edx string) mo.HostSystem {
+		return mo.HostSystem{Hardware: &types.HostHardwareInfo{CpuFeature: []types.HostCpuIdInfo{
+			{Level: 0, Edx: "0000:0000:0000:0000:0000:0000:0000:0000"},
+			{Level: -2147483647, Edx: edx},
+		}}}
+	}
+	tests := []struct {
+		name     string
+		host     mo.HostSystem
+		size     infrav1.HugePageSize
+		expected bool
+	}{
+		{
+			name:     "host without config",
+			host:     mo.HostSystem{},
+			size:     infrav1.HugePageSize2Mi,
+			expected: true,
+		},
+		{
+			name:     "host with default advanced options",
+			host:     host(&types.OptionValue{Key: "Mem.ShareScanGHz", Value: int64(4)}),
+			size:     infrav1.HugePageSize2Mi,
+			expected: true,
+		},
+		{
+			name:     "host allocating huge pages",
+			host:     host(&types.OptionValue{Key: "Mem.AllocGuestLargePage", Value: int64(1)}),
+			size:     infrav1.HugePageSize2Mi,
+			expected: true,
+		},
+		{
+			name: "host not allocating huge pages",
+			host: host(&types.OptionValue{Key: "Mem.AllocGuestLargePage", Value: int64(0)}),
+			size: infrav1.HugePageSize2Mi,
+		},
+		{
+			name:     "1Gi pages on host with CPUs supporting 1 GiB pages",
+			host:     hostWithExtendedFeatures("0010:1100:0001:0000:0000:1000:0000:0000"),
+			size:     infrav1.HugePageSize1Gi,
+			expected: true,
+		},
+		{
+			name: "1Gi pages on host with CPUs not supporting 1 GiB pages",
+			host: hostWithExtendedFeatures("0010:1000:0001:0000:0000:1000:0000:0000"),
+			size: infrav1.HugePageSize1Gi,
+		},
+		{
+			name:     "2Mi pages on host with CPUs not supporting 1 GiB pages",
+			host:     hostWithExtendedFeatures("0010:1000:0001:0000:0000:1000:0000:0000"),
+			size:     infrav1.HugePageSize2Mi,
+			expected: true,
+		},
+		{
+			name:     "1Gi pages on host not reporting its CPU features",
+			host:     mo.HostSystem{Hardware: &types.HostHardwareInfo{}},
+			size:     infrav1.HugePageSize1Gi,
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := HostSupportsHugePages(tt.host, tt.size); actual != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHostHasNUMANodes(t *testing.T) {
+	host := func(numNodes int32) mo.HostSystem {
+		return mo.HostSystem{Hardware: &types.HostHardwareInfo{NumaInfo: &types.HostNumaInfo{NumNodes: numNodes}}}
+	}
+	tests := []struct {
+		name     string
+		host     mo.HostSystem
+		nodes    []int32
+		expected bool
+	}{
+		{
+			name:     "host without NUMA topology",
+			host:     mo.HostSystem{},
+			nodes:    []int32{0},
+			expected: true,
+		},
+		{
+			name:  "host without NUMA topology and a second node",
+			host:  mo.HostSystem{},
+			nodes: []int32{1},
+		},
+		{
+			name:     "host with the NUMA nodes",
+			host:     host(2),
+			nodes:    []int32{1, 0},
+			expected: true,
+		},
+		{
+			name:  "host without a NUMA node",
+			host:  host(2),
+			nodes: []int32{0, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := HostHasNUMANodes(tt.host, tt.nodes); actual != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHasHostLocalSwap(t *testing.T) {
+	if HasHostLocalSwap(mo.HostSystem{}) {
+		t.Errorf("Expected host without config to have no local swap datastore")
+	}
+	if HasHostLocalSwap(mo.HostSystem{Config: &types.HostConfigInfo{}}) {
+		t.Errorf("Expected host without local swap datastore to have none")
+	}
+	if !HasHostLocalSwap(mo.HostSystem{Config: &types.HostConfigInfo{LocalSwapDatastore: &types.ManagedObjectReference{Type: "Datastore", Value: "datastore-1"}}}) {
+		t.Errorf("Expected host with local swap datastore to have one")
+	}
+}
+
+func TestNUMANodeAffinityExtraConfig(t *testing.T) {
+	expected := map[string]string{"numa.nodeAffinity": "0,1,3"}
+	if actual := NUMANodeAffinityExtraConfig([]int32{3, 0, 1}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestCheckCPUMMUVirtualizationSupported(t *testing.T) {
+	host := func(capability *types.HostCapability) mo.HostSystem {
+		return mo.HostSystem{ManagedEntity: mo.ManagedEntity{Name: "esx-1"}, Capability: capability}
+	}
+	tests := []struct {
+		name    string
+		host    mo.HostSystem
+		mode    infrav1.CPUMMUVirtualizationMode
+		wantErr bool
+	}{
+		{
+			name: "automatic mode on any host",
+			host: host(nil),
+			mode: infrav1.CPUMMUVirtualizationModeAutomatic,
+		},
+		{
+			name:    "hardware mode on host which does not support configuring the mode",
+			host:    host(&types.HostCapability{}),
+			mode:    infrav1.CPUMMUVirtualizationModeHardware,
+			wantErr: true,
+		},
+		{
+			name: "hardware mode on host which ignores the mode",
+			host: host(&types.HostCapability{VirtualExecUsageSupported: ptr.To(true), VirtualExecUsageIgnored: ptr.To(true), VirtualMmuUsageIgnored: ptr.To(true)}),
+			mode: infrav1.CPUMMUVirtualizationModeHardware,
+		},
+		{
+			name:    "software mode on host which ignores the mode",
+			host:    host(&types.HostCapability{VirtualExecUsageSupported: ptr.To(true), VirtualMmuUsageIgnored: ptr.To(true)}),
+			mode:    infrav1.CPUMMUVirtualizationModeSoftware,
+			wantErr: true,
+		},
+		{
+			name: "software mode on host which supports configuring the mode",
+			host: host(&types.HostCapability{VirtualExecUsageSupported: ptr.To(true)}),
+			mode: infrav1.CPUMMUVirtualizationModeSoftware,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCPUMMUVirtualizationSupported(tt.host, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMemoryBackingExtraConfig(t *testing.T) {
+	tests := []struct {
+		size     infrav1.HugePageSize
+		expected map[string]string
+	}{
+		{
+			size:     infrav1.HugePageSize2Mi,
+			expected: map[string]string{"sched.mem.pshare.enable": "FALSE", "sched.mem.lpage.enable1GPage": "FALSE"},
+		},
+		{
+			size:     infrav1.HugePageSize1Gi,
+			expected: map[string]string{"sched.mem.pshare.enable": "FALSE", "sched.mem.lpage.enable1GPage": "TRUE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.size), func(t *testing.T) {
+			backing := &infrav1.VirtualMachineMemoryBacking{HugePageSize: tt.size}
+			if actual := MemoryBackingExtraConfig(backing); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestOwnerExtraConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		machineName string
+		expected    map[string]string
+	}{
+		{
+			name:        "cluster and machine known",
+			labels:      map[string]string{clusterv1.ClusterNameLabel: "my-cluster"},
+			machineName: "my-machine",
+			expected: map[string]string{
+				"capv.cluster":   "my-cluster",
+				"capv.machine":   "my-machine",
+				"capv.namespace": "my-namespace",
+			},
+		},
+		{
+			name: "cluster and machine not known",
+			expected: map[string]string{
+				"capv.namespace": "my-namespace",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vsphereVM := &infrav1.VSphereVM{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-vm",
+					Namespace: "my-namespace",
+					Labels:    tt.labels,
+				},
+			}
+			if actual := OwnerExtraConfig(vsphereVM, tt.machineName); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsolationExtraConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		isolation *infrav1.VirtualMachineIsolation
+		hardened  bool
+		expected  map[string]string
+	}{
+		{
+			name:      "not defined",
+			isolation: nil,
+			expected:  nil,
+		},
+		{
+			name:      "copy and paste enabled",
+			isolation: &infrav1.VirtualMachineIsolation{CopyPasteDisabled: ptr.To(false)},
+			expected: map[string]string{
+				"isolation.tools.copy.disable":         "FALSE",
+				"isolation.tools.paste.disable":        "FALSE",
+				"isolation.tools.dnd.disable":          "FALSE",
+				"isolation.tools.setGUIOptions.enable": "TRUE",
+			},
+		},
+		{
+			name:      "device connect disabled and VMCI unrestricted",
+			isolation: &infrav1.VirtualMachineIsolation{DeviceConnectDisabled: ptr.To(true), VMCIRestricted: ptr.To(false)},
+			expected: map[string]string{
+				"isolation.device.connectable.disable": "TRUE",
+				"isolation.device.edit.disable":        "TRUE",
+				"vmci0.unrestricted":                   "TRUE",
+			},
+		},
+		{
+			name:      "hardened defaults",
+			isolation: nil,
+			hardened:  true,
+			expected: map[string]string{
+				"isolation.tools.copy.disable":         "TRUE",
+				"isolation.tools.paste.disable":        "TRUE",
+				"isolation.tools.dnd.disable":          "TRUE",
+				"isolation.tools.setGUIOptions.enable": "FALSE",
+				"isolation.device.connectable.disable": "TRUE",
+				"isolation.device.edit.disable":        "TRUE",
+				"vmci0.unrestricted":                   "FALSE",
+			},
+		},
+		{
+			name:      "hardened defaults with copy and paste enabled",
+			isolation: &infrav1.VirtualMachineIsolation{CopyPasteDisabled: ptr.To(false)},
+			hardened:  true,
+			expected: map[string]string{
+				"isolation.tools.copy.disable":         "FALSE",
+				"isolation.tools.paste.disable":        "FALSE",
+				"isolation.tools.dnd.disable":          "FALSE",
+				"isolation.tools.setGUIOptions.enable": "TRUE",
+				"isolation.device.connectable.disable": "TRUE",
+				"isolation.device.edit.disable":        "TRUE",
+				"vmci0.unrestricted":                   "FALSE",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolation := EffectiveIsolation(tt.isolation, tt.hardened)
+			if isolation == nil {
+				if tt.expected != nil {
+					t.Errorf("Expected %v, got no isolation", tt.expected)
+				}
+				return
+			}
+			if actual := IsolationExtraConfig(isolation); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNetworkDeviceSettings(t *testing.T) {
+	tests := []struct {
+		name            string
+		nic             types.VirtualEthernetCard
+		netSpec         infrav1.NetworkDeviceSpec
+		expectedChanges []string
+		expectedNIC     types.VirtualEthernetCard
+	}{
+		{
+			name: "settings not defined",
+		},
+		{
+			name: "new network adapter",
+			netSpec: infrav1.NetworkDeviceSpec{
+				WakeOnLanEnabled:  ptr.To(true),
+				AllowGuestControl: ptr.To(true),
+			},
+			expectedChanges: []string{"wakeOnLanEnabled", "allowGuestControl"},
+			expectedNIC: types.VirtualEthernetCard{
+				VirtualDevice: types.VirtualDevice{
+					Connectable: &types.VirtualDeviceConnectInfo{StartConnected: true, AllowGuestControl: true},
+				},
+				WakeOnLanEnabled: ptr.To(true),
+			},
+		},
+		{
+			name: "existing network adapter with other settings",
+			nic: types.VirtualEthernetCard{
+				VirtualDevice: types.VirtualDevice{
+					Connectable: &types.VirtualDeviceConnectInfo{StartConnected: true, Connected: true, AllowGuestControl: true},
+				},
+				WakeOnLanEnabled: ptr.To(true),
+			},
+			netSpec: infrav1.NetworkDeviceSpec{
+				WakeOnLanEnabled:  ptr.To(true),
+				AllowGuestControl: ptr.To(false),
+			},
+			expectedChanges: []string{"allowGuestControl"},
+			expectedNIC: types.VirtualEthernetCard{
+				VirtualDevice: types.VirtualDevice{
+					Connectable: &types.VirtualDeviceConnectInfo{StartConnected: true, Connected: true},
+				},
+				WakeOnLanEnabled: ptr.To(true),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nic := tt.nic
+			if changes := NetworkDeviceSettings(&nic, &tt.netSpec); !reflect.DeepEqual(changes, tt.expectedChanges) {
+				t.Errorf("Expected changes %v, got %v", tt.expectedChanges, changes)
+			}
+			if !reflect.DeepEqual(nic, tt.expectedNIC) {
+				t.Errorf("Expected network adapter %+v, got %+v", tt.expectedNIC, nic)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// supportedEVCModes returns the EVC modes supported by vCenter, indexed by their key.
+func supportedEVCModes(ctx context.Context, client *vim25.Client) (map[string]types.EVCMode, error) {
+	var serviceInstance mo.ServiceInstance
+	if err := property.DefaultCollector(client).RetrieveOne(ctx, vim25.ServiceInstance, []string{"capability.supportedEVCMode"}, &serviceInstance); err != nil {
+		return nil, errors.Wrap(err, "unable to get supported EVC modes")
+	}
+	modes := map[string]types.EVCMode{}
+	for _, mode := range serviceInstance.Capability.SupportedEVCMode {
+		modes[mode.Key] = mode
+	}
+	return modes, nil
+}
+
+// EVCMode returns the EVC mode with the given key, or an ErrEVCModeNotSupported error if
+// vCenter does not support it.
+func EVCMode(ctx context.Context, client *vim25.Client, key string) (*types.EVCMode, error) {
+	modes, err := supportedEVCModes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	mode, ok := modes[key]
+	if !ok {
+		return nil, errors.Wrapf(ErrEVCModeNotSupported, "EVC mode %s is unknown", key)
+	}
+	return &mode, nil
+}
+
+// checkEVCModeSupported returns an ErrEVCModeNotSupported error if the EVC mode exceeds the
+// EVC mode of the cluster of the resource pool or, if EVC is disabled on the cluster, the
+// maximum EVC mode of any of its hosts, as the VM may be placed on any of them.
+func checkEVCModeSupported(ctx context.Context, vmCtx *capvcontext.VMContext, pool *object.ResourcePool, key string) error {
+	client := vmCtx.Session.Client.Client
+	modes, err := supportedEVCModes(ctx, client)
+	if err != nil {
+		return err
+	}
+	mode, ok := modes[key]
+	if !ok {
+		return errors.Wrapf(ErrEVCModeNotSupported, "EVC mode %s is unknown", key)
+	}
+
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get owning compute resource of resource pool %q", pool)
+	}
+	var computeResource mo.ComputeResource
+	if err := pool.Properties(ctx, owner.Reference(), []string{"summary", "host"}, &computeResource); err != nil {
+		return errors.Wrapf(err, "unable to get EVC mode of compute resource of resource pool %q", pool)
+	}
+	if summary, ok := computeResource.Summary.(*types.ClusterComputeResourceSummary); ok && summary.CurrentEVCModeKey != "" {
+		if !evcModeCompatible(mode, modes[summary.CurrentEVCModeKey]) {
+			return errors.Wrapf(ErrEVCModeNotSupported, "EVC mode %s exceeds the EVC mode %s of the cluster of resource pool %q", key, summary.CurrentEVCModeKey, pool)
+		}
+		return nil
+	}
+
+	if len(computeResource.Host) == 0 {
+		return nil
+	}
+	var hosts []mo.HostSystem
+	if err := property.DefaultCollector(client).Retrieve(ctx, computeResource.Host, []string{"name", "summary.maxEVCModeKey"}, &hosts); err != nil {
+		return errors.Wrapf(err, "unable to get EVC modes of hosts of resource pool %q", pool)
+	}
+	for _, host := range hosts {
+		if !evcModeCompatible(mode, modes[host.Summary.MaxEVCModeKey]) {
+			return errors.Wrapf(ErrEVCModeNotSupported, "EVC mode %s exceeds the maximum EVC mode %q of host %s of resource pool %q", key, host.Summary.MaxEVCModeKey, host.Name, pool)
+		}
+	}
+	return nil
+}
+
+// evcModeCompatible returns true if the EVC mode does not exceed the baseline EVC mode, i.e.
+// both modes are of the same CPU vendor and the mode is of the same or an older tier.
+func evcModeCompatible(mode, baseline types.EVCMode) bool {
+	return mode.Vendor == baseline.Vendor && mode.VendorTier <= baseline.VendorTier
+}
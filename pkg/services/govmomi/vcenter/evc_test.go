@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+func TestCheckEVCModeSupported(t *testing.T) {
+	model, session, server := initSimulator(t)
+	t.Cleanup(model.Remove)
+	t.Cleanup(server.Close)
+
+	evcMode := func(key, vendor string, tier int32) types.EVCMode {
+		return types.EVCMode{ElementDescription: types.ElementDescription{Key: key}, Vendor: vendor, VendorTier: tier}
+	}
+	simulator.Map.Get(vim25.ServiceInstance).(*simulator.ServiceInstance).Capability.SupportedEVCMode = []types.EVCMode{
+		evcMode("intel-haswell", "intel", 5),
+		evcMode("intel-broadwell", "intel", 6),
+		evcMode("intel-skylake", "intel", 7),
+		evcMode("amd-zen", "amd", 7),
+	}
+
+	pool, err := session.Finder.ResourcePool(ctx.TODO(), "/DC0/host/DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := pool.Owner(ctx.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := simulator.Map.Get(owner.Reference()).(*simulator.ClusterComputeResource)
+	for _, host := range cluster.Host {
+		simulator.Map.Get(host).(*simulator.HostSystem).Summary.MaxEVCModeKey = "intel-broadwell"
+	}
+	vmContext := &capvcontext.VMContext{
+		Session:   session,
+		VSphereVM: &infrav1.VSphereVM{},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		clusterEVCMode  string
+		evcMode         string
+		expectSupported bool
+	}{
+		{name: "unknown EVC mode", evcMode: "intel-unknown"},
+		{name: "EVC mode supported by all hosts", evcMode: "intel-haswell", expectSupported: true},
+		{name: "EVC mode exceeding the hosts", evcMode: "intel-skylake"},
+		{name: "EVC mode of another vendor", evcMode: "amd-zen"},
+		{name: "EVC mode of the cluster", clusterEVCMode: "intel-skylake", evcMode: "intel-skylake", expectSupported: true},
+		{name: "EVC mode exceeding the cluster", clusterEVCMode: "intel-haswell", evcMode: "intel-broadwell"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster.Summary.(*types.ClusterComputeResourceSummary).CurrentEVCModeKey = tt.clusterEVCMode
+
+			err := checkEVCModeSupported(ctx.TODO(), vmContext, pool, tt.evcMode)
+			if tt.expectSupported && err != nil {
+				t.Errorf("Expected EVC mode %s to be supported, got %v", tt.evcMode, err)
+			}
+			if !tt.expectSupported && !errors.Is(err, ErrEVCModeNotSupported) {
+				t.Errorf("Expected EVC mode %s not to be supported, got %v", tt.evcMode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/services/govmomi/extra"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/util"
+)
+
+// instantCloneMinHardwareVersion is the minimum hardware version of the source
+// VM of instant clones.
+const instantCloneMinHardwareVersion = "vmx-11"
+
+// instantClone kicks off an instant clone operation on vCenter to create a new virtual
+// machine from the running and frozen source VM. Instant clones share the memory and
+// disk state of the source VM and cannot be customized by guest customization, so the
+// bootstrap data is only delivered through the guestinfo variables of the extra config.
+// This function does not wait for the virtual machine to be created.
+func instantClone(ctx context.Context, vmCtx *capvcontext.VMContext, source *object.VirtualMachine, extraConfig extra.Config) error {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Instant clone requested")
+
+	var sourceVM mo.VirtualMachine
+	if err := source.Properties(ctx, source.Reference(), []string{"name", "config.version", "runtime.powerState", "runtime.host", "runtime.instantCloneFrozen"}, &sourceVM); err != nil {
+		return errors.Wrapf(err, "error getting runtime information for source VM %s", vmCtx.VSphereVM.Spec.Template)
+	}
+	if err := validateInstantCloneSource(sourceVM); err != nil {
+		return err
+	}
+
+	folder, err := vmCtx.Session.FolderOrDefault(ctx, vmCtx.VSphereVM.Spec.Folder)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get folder for %q", ctx)
+	}
+
+	pool, err := vmCtx.Session.ResourcePoolOrDefault(ctx, vmCtx.VSphereVM.Spec.ResourcePool)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get resource pool for %q", ctx)
+	}
+
+	// Instant clones are created on the host of the source VM, which therefore
+	// must be part of the compute resource of the resource pool.
+	owner, err := pool.Owner(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get owning compute resource of resource pool %q", pool)
+	}
+	var computeResource mo.ComputeResource
+	if err := pool.Properties(ctx, owner.Reference(), []string{"host"}, &computeResource); err != nil {
+		return errors.Wrapf(err, "unable to get hosts of compute resource of resource pool %q", pool)
+	}
+	if !containsReference(computeResource.Host, *sourceVM.Runtime.Host) {
+		return errors.Errorf("resource pool %q is not on host %s of source VM %s", pool, sourceVM.Runtime.Host.Value, sourceVM.Name)
+	}
+
+	devices, err := source.Device(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error getting devices for %q", ctx)
+	}
+	networkSpecs, err := getInstantCloneNetworkSpecs(ctx, vmCtx, devices)
+	if err != nil {
+		return errors.Wrapf(err, "error getting network specs for %q", ctx)
+	}
+
+	spec := types.VirtualMachineInstantCloneSpec{
+		Name: util.GetVMName(vmCtx.VSphereVM),
+		Location: types.VirtualMachineRelocateSpec{
+			Folder:       types.NewReference(folder.Reference()),
+			Pool:         types.NewReference(pool.Reference()),
+			Host:         sourceVM.Runtime.Host,
+			DeviceChange: networkSpecs,
+		},
+		Config: extraConfig,
+	}
+
+	if vmCtx.VSphereVM.Spec.Datastore != "" {
+		datastore, err := vmCtx.Session.DatastoreOrDefault(ctx, vmCtx.VSphereVM.Spec.Datastore)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get datastore %s for %q", vmCtx.VSphereVM.Spec.Datastore, ctx)
+		}
+		spec.Location.Datastore = types.NewReference(datastore.Reference())
+	}
+
+	vmCtx.VSphereVM.Status.CloneMode = infrav1.InstantClone
+	log.Info("Cloning Machine with clone mode instantClone")
+	task, err := source.InstantClone(ctx, spec)
+	if err != nil {
+		return errors.Wrapf(err, "error trigging instant clone op for machine %s", ctx)
+	}
+
+	vmCtx.VSphereVM.Status.TaskRef = task.Reference().Value
+
+	// patch the vsphereVM early to ensure that the task is
+	// reflected in the status right away, this avoids situations
+	// of concurrent clones
+	if err := vmCtx.Patch(ctx); err != nil {
+		log.Error(err, "Failed to patch VSphereVM (best-effort)")
+	}
+	return nil
+}
+
+// validateInstantCloneSource returns an error if the VM cannot be the source of
+// instant clones, i.e. it is not running and frozen, or its hardware version is
+// too old.
+func validateInstantCloneSource(sourceVM mo.VirtualMachine) error {
+	if sourceVM.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		return errors.Errorf("source VM %s of instant clone must be powered on, got %s", sourceVM.Name, sourceVM.Runtime.PowerState)
+	}
+	if !ptr.Deref(sourceVM.Runtime.InstantCloneFrozen, false) {
+		return errors.Errorf("source VM %s of instant clone must be frozen", sourceVM.Name)
+	}
+	if sourceVM.Runtime.Host == nil {
+		return errors.Errorf("source VM %s of instant clone is not running on a host", sourceVM.Name)
+	}
+	if sourceVM.Config == nil {
+		return errors.Errorf("unable to get hardware version of source VM %s of instant clone", sourceVM.Name)
+	}
+	tooOld, err := util.LessThan(sourceVM.Config.Version, instantCloneMinHardwareVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse hardware version of source VM %s", sourceVM.Name)
+	}
+	if tooOld {
+		return errors.Errorf("hardware version %s of source VM %s of instant clone is older than %s", sourceVM.Config.Version, sourceVM.Name, instantCloneMinHardwareVersion)
+	}
+	return nil
+}
+
+// getInstantCloneNetworkSpecs returns the device specs which connect the NICs of the
+// instant clone to the networks of the machine config. Instant clones keep the NICs
+// of the source VM, so only their backings, MAC addresses and settings are changed.
+func getInstantCloneNetworkSpecs(ctx context.Context, vmCtx *capvcontext.VMContext, devices object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	nics := devices.SelectByType((*types.VirtualEthernetCard)(nil))
+	if len(vmCtx.VSphereVM.Spec.Network.Devices) > len(nics) {
+		return nil, errors.Errorf("instant clone requires %d network devices, but source VM has %d", len(vmCtx.VSphereVM.Spec.Network.Devices), len(nics))
+	}
+
+	deviceSpecs := []types.BaseVirtualDeviceConfigSpec{}
+	for i := range vmCtx.VSphereVM.Spec.Network.Devices {
+		netSpec := &vmCtx.VSphereVM.Spec.Network.Devices[i]
+		ref, err := vmCtx.Session.Finder.Network(ctx, netSpec.NetworkName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find network %q", netSpec.NetworkName)
+		}
+		backing, err := ref.EthernetCardBackingInfo(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create new ethernet card backing info for network %q on %q", netSpec.NetworkName, ctx)
+		}
+
+		nic := nics[i].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		nic.Backing = backing
+		if netSpec.MACAddr != "" {
+			nic.MacAddress = netSpec.MACAddr
+			nic.AddressType = string(types.VirtualEthernetCardMacTypeManual)
+		}
+		NetworkDeviceSettings(nic, netSpec)
+
+		deviceSpecs = append(deviceSpecs, &types.VirtualDeviceConfigSpec{
+			Device:    nics[i],
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+		})
+	}
+	return deviceSpecs, nil
+}
+
+func containsReference(refs []types.ManagedObjectReference, ref types.ManagedObjectReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
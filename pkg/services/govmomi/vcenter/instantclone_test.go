@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateInstantCloneSource(t *testing.T) {
+	newSourceVM := func(powerState types.VirtualMachinePowerState, frozen bool, version string) mo.VirtualMachine {
+		return mo.VirtualMachine{
+			ManagedEntity: mo.ManagedEntity{Name: "source"},
+			Config:        &types.VirtualMachineConfigInfo{Version: version},
+			Runtime: types.VirtualMachineRuntimeInfo{
+				PowerState:         powerState,
+				InstantCloneFrozen: ptr.To(frozen),
+				Host:               &types.ManagedObjectReference{Type: "HostSystem", Value: "host-1"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		sourceVM mo.VirtualMachine
+		err      string
+	}{
+		{
+			name:     "Successfully validate running and frozen source VM",
+			sourceVM: newSourceVM(types.VirtualMachinePowerStatePoweredOn, true, "vmx-19"),
+		},
+		{
+			name:     "Fail to validate powered off source VM",
+			sourceVM: newSourceVM(types.VirtualMachinePowerStatePoweredOff, false, "vmx-19"),
+			err:      "source VM source of instant clone must be powered on, got poweredOff",
+		},
+		{
+			name:     "Fail to validate source VM which is not frozen",
+			sourceVM: newSourceVM(types.VirtualMachinePowerStatePoweredOn, false, "vmx-19"),
+			err:      "source VM source of instant clone must be frozen",
+		},
+		{
+			name:     "Fail to validate source VM with old hardware version",
+			sourceVM: newSourceVM(types.VirtualMachinePowerStatePoweredOn, true, "vmx-10"),
+			err:      "hardware version vmx-10 of source VM source of instant clone is older than vmx-11",
+		},
+	}
+
+	for _, test := range testCases {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateInstantCloneSource(tc.sourceVM)
+			if (tc.err != "" && err == nil) || (tc.err == "" && err != nil) || (err != nil && tc.err != err.Error()) {
+				t.Fatalf("Expected to get '%v' error from validateInstantCloneSource, got: '%v'", tc.err, err)
+			}
+		})
+	}
+}
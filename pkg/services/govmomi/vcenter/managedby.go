@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/version"
+)
+
+const (
+	// managedByExtensionKey is the key of the vCenter extension which claims ownership
+	// of the VMs created by CAPV.
+	managedByExtensionKey = "io.x-k8s.cluster-api-provider-vsphere"
+
+	// managedByType is the type of the VMs managed by the CAPV extension.
+	managedByType = "Machine"
+)
+
+// registeredExtensions tracks the vCenters the CAPV extension was registered with, so
+// it is only registered once per vCenter.
+var registeredExtensions sync.Map
+
+// managedByInfo returns the managedBy info which marks a VM as managed by CAPV, so the
+// vSphere UI shows it as managed by CAPV and warns before manual operations. The CAPV
+// extension is registered with the vCenter first, unless it was already.
+func managedByInfo(ctx context.Context, c *vim25.Client) *types.ManagedByInfo {
+	log := ctrl.LoggerFrom(ctx)
+
+	server := c.URL().Host
+	if _, ok := registeredExtensions.Load(server); !ok {
+		switch err := registerExtension(ctx, c); {
+		case err == nil:
+			registeredExtensions.Store(server, true)
+		case isNoPermission(err):
+			// The VMs are still marked as managed by the extension key, the vSphere UI
+			// only lacks the description of the extension.
+			log.Info("Insufficient privileges to register the vCenter extension of CAPV", "extension", managedByExtensionKey)
+			registeredExtensions.Store(server, true)
+		default:
+			log.Error(err, "Failed to register the vCenter extension of CAPV, retrying with the next clone", "extension", managedByExtensionKey)
+		}
+	}
+
+	return &types.ManagedByInfo{
+		ExtensionKey: managedByExtensionKey,
+		Type:         managedByType,
+	}
+}
+
+// registerExtension registers the CAPV extension with the vCenter if it is not registered yet.
+func registerExtension(ctx context.Context, c *vim25.Client) error {
+	extensionManager, err := object.GetExtensionManager(c)
+	if err != nil {
+		return err
+	}
+	extension, err := extensionManager.Find(ctx, managedByExtensionKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find extension %s", managedByExtensionKey)
+	}
+	if extension != nil {
+		return nil
+	}
+
+	description := &types.Description{
+		Label:   "Cluster API Provider vSphere",
+		Summary: "Kubernetes cluster nodes managed by Cluster API Provider vSphere",
+	}
+	err = extensionManager.Register(ctx, types.Extension{
+		Key:               managedByExtensionKey,
+		Description:       description,
+		Company:           "The Kubernetes Authors",
+		Version:           version.Get().GitVersion,
+		LastHeartbeatTime: time.Now(),
+		ManagedEntityInfo: []types.ExtManagedEntityInfo{{
+			Type:        managedByType,
+			Description: "Virtual machine of a Kubernetes node created by Cluster API Provider vSphere. Use Cluster API to change or delete it.",
+		}},
+	})
+	return errors.Wrapf(err, "failed to register extension %s", managedByExtensionKey)
+}
+
+// isNoPermission returns true if vCenter denied the request for missing privileges.
+func isNoPermission(err error) bool {
+	err = errors.Cause(err)
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(types.NoPermission)
+		return ok
+	}
+	if soap.IsVimFault(err) {
+		_, ok := soap.ToVimFault(err).(*types.NoPermission)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vcenter
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// extensionManager implements the extension manager, which the simulator lacks.
+type extensionManager struct {
+	mo.ExtensionManager
+
+	registrations int
+	noPermission  bool
+}
+
+func (m *extensionManager) FindExtension(req *types.FindExtension) soap.HasFault {
+	body := &methods.FindExtensionBody{Res: &types.FindExtensionResponse{}}
+	for i := range m.ExtensionList {
+		if m.ExtensionList[i].Key == req.ExtensionKey {
+			body.Res.Returnval = &m.ExtensionList[i]
+		}
+	}
+	return body
+}
+
+func (m *extensionManager) RegisterExtension(req *types.RegisterExtension) soap.HasFault {
+	body := &methods.RegisterExtensionBody{}
+	if m.noPermission {
+		body.Fault_ = simulator.Fault("", &types.NoPermission{PrivilegeId: "Extension.Register"})
+		return body
+	}
+	m.registrations++
+	m.ExtensionList = append(m.ExtensionList, req.Extension)
+	body.Res = &types.RegisterExtensionResponse{}
+	return body
+}
+
+func TestManagedByInfo(t *testing.T) {
+	expected := types.ManagedByInfo{ExtensionKey: managedByExtensionKey, Type: managedByType}
+
+	newExtensionManager := func(t *testing.T, noPermission bool) (*extensionManager, func() *types.ManagedByInfo) {
+		t.Helper()
+		model, session, server := initSimulator(t)
+		t.Cleanup(model.Remove)
+		t.Cleanup(server.Close)
+		t.Cleanup(func() { registeredExtensions.Delete(server.URL.Host) })
+
+		m := &extensionManager{noPermission: noPermission}
+		m.Self = *session.Client.ServiceContent.ExtensionManager
+		simulator.Map.Put(m)
+		return m, func() *types.ManagedByInfo {
+			return managedByInfo(ctx.Background(), session.Client.Client)
+		}
+	}
+
+	t.Run("registers the extension once", func(t *testing.T) {
+		m, managedBy := newExtensionManager(t, false)
+		for i := 0; i < 2; i++ {
+			if info := managedBy(); *info != expected {
+				t.Fatalf("expected managedBy info %v, got %v", expected, *info)
+			}
+		}
+		if m.registrations != 1 {
+			t.Fatalf("expected the extension to be registered once, got %d registrations", m.registrations)
+		}
+		if m.ExtensionList[0].ManagedEntityInfo[0].Type != managedByType {
+			t.Errorf("expected the extension to manage entities of type %s, got %s", managedByType, m.ExtensionList[0].ManagedEntityInfo[0].Type)
+		}
+	})
+
+	t.Run("marks the VM without permission to register the extension", func(t *testing.T) {
+		m, managedBy := newExtensionManager(t, true)
+		if info := managedBy(); *info != expected {
+			t.Fatalf("expected managedBy info %v, got %v", expected, *info)
+		}
+		if m.registrations != 0 {
+			t.Fatalf("expected the extension not to be registered, got %d registrations", m.registrations)
+		}
+		// The registration is not retried.
+		m.noPermission = false
+		managedBy()
+		if m.registrations != 0 {
+			t.Errorf("expected the registration not to be retried, got %d registrations", m.registrations)
+		}
+	})
+}
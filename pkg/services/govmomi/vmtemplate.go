@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+)
+
+// reconcileMarkAsTemplate marks the VM as a template once the VSphereVM with the
+// MarkAsTemplateAnnotation is ready and the VM is powered off, and marks the template as a
+// virtual machine again once the annotation is removed. It returns false while the VM is a
+// template, as a template cannot be reconfigured or powered on.
+func (vms *VMService) reconcileMarkAsTemplate(ctx context.Context, virtualMachineCtx *virtualMachineContext) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	virtualMachine := &virtualMachineCtx.Properties
+	isTemplate := virtualMachine.Config != nil && virtualMachine.Config.Template
+
+	if _, ok := virtualMachineCtx.VSphereVM.Annotations[infrav1.MarkAsTemplateAnnotation]; !ok {
+		conditions.Delete(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)
+		if !isTemplate {
+			return true, nil
+		}
+
+		pool, err := virtualMachineCtx.Session.ResourcePoolOrDefault(ctx, virtualMachineCtx.VSphereVM.Spec.ResourcePool)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to get resource pool for %q", virtualMachineCtx.VSphereVM.Name)
+		}
+		log.Info("Marking template as virtual machine")
+		if err := virtualMachineCtx.Obj.MarkAsVirtualMachine(ctx, *pool, nil); err != nil {
+			return false, errors.Wrapf(err, "unable to mark template %s as virtual machine", virtualMachineCtx.VSphereVM.Name)
+		}
+		virtualMachineCtx.PropertiesOutdated = true
+		return true, nil
+	}
+
+	if isTemplate {
+		conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)
+		return false, nil
+	}
+	if !virtualMachineCtx.VSphereVM.Status.Ready {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition, infrav1.MarkAsTemplatePendingReason, clusterv1.ConditionSeverityInfo,
+			"VSphereVM is not ready")
+		return true, nil
+	}
+	if virtualMachine.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition, infrav1.MarkAsTemplatePendingReason, clusterv1.ConditionSeverityInfo,
+			"VM must be powered off to be marked as a template")
+		return true, nil
+	}
+
+	log.Info("Marking VM as template")
+	if err := virtualMachineCtx.Obj.MarkAsTemplate(ctx); err != nil {
+		conditions.MarkFalse(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition, infrav1.MarkAsTemplateFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return false, errors.Wrapf(err, "unable to mark VM %s as template", virtualMachineCtx.VSphereVM.Name)
+	}
+	conditions.MarkTrue(virtualMachineCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)
+	return false, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package govmomi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
+)
+
+func Test_reconcileMarkAsTemplate(t *testing.T) {
+	var vmCtx *virtualMachineContext
+	var g *WithT
+	var vms *VMService
+
+	before := func() {
+		vmCtx = emptyVirtualMachineContext()
+		vms = &VMService{}
+	}
+
+	newVSphereVM := func(markAsTemplate, ready bool) *infrav1.VSphereVM {
+		vsphereVM := &infrav1.VSphereVM{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "vsphereVM1",
+				Namespace:   "my-namespace",
+				Annotations: map[string]string{},
+			},
+			Spec: infrav1.VSphereVMSpec{
+				VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+					ResourcePool: "/DC0/host/DC0_H0/Resources",
+				},
+			},
+			Status: infrav1.VSphereVMStatus{
+				Ready: ready,
+			},
+		}
+		if markAsTemplate {
+			vsphereVM.Annotations[infrav1.MarkAsTemplateAnnotation] = ""
+		}
+		return vsphereVM
+	}
+
+	isTemplate := func(ctx context.Context, vm *object.VirtualMachine) bool {
+		var virtualMachine mo.VirtualMachine
+		g.Expect(vm.Properties(ctx, vm.Reference(), []string{"config.template"}, &virtualMachine)).To(Succeed())
+		return virtualMachine.Config.Template
+	}
+
+	t.Run("when the VM is not marked as template", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+			vmCtx.VSphereVM = newVSphereVM(false, true)
+
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileMarkAsTemplate(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)).To(BeFalse())
+			return nil
+		})
+	})
+
+	t.Run("when the VM is marked as template and back", func(t *testing.T) {
+		g = NewWithT(t)
+		before()
+
+		simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+			finder := find.NewFinder(c)
+			dc, err := finder.DefaultDatacenter(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			finder.SetDatacenter(dc)
+			vmCtx.Session = &session.Session{Finder: finder}
+
+			vm, err := finder.VirtualMachine(ctx, "DC0_H0_VM0")
+			g.Expect(err).ToNot(HaveOccurred())
+			vmCtx.Obj = vm
+
+			// The VM is not marked as template before the VSphereVM is ready.
+			vmCtx.VSphereVM = newVSphereVM(true, false)
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err := vms.reconcileMarkAsTemplate(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetReason(vmCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)).To(Equal(infrav1.MarkAsTemplatePendingReason))
+
+			// The VM is not marked as template while it is powered on.
+			vmCtx.VSphereVM.Status.Ready = true
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileMarkAsTemplate(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.GetMessage(vmCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)).To(ContainSubstring("powered off"))
+			g.Expect(isTemplate(ctx, vm)).To(BeFalse())
+
+			task, err := vm.PowerOff(ctx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(task.Wait(ctx)).To(Succeed())
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileMarkAsTemplate(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+			g.Expect(conditions.IsTrue(vmCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)).To(BeTrue())
+			g.Expect(isTemplate(ctx, vm)).To(BeTrue())
+
+			// The template is marked as virtual machine once the annotation is removed.
+			delete(vmCtx.VSphereVM.Annotations, infrav1.MarkAsTemplateAnnotation)
+			g.Expect(fetchVirtualMachineProperties(ctx, vmCtx)).To(Succeed())
+			ok, err = vms.reconcileMarkAsTemplate(ctx, vmCtx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			g.Expect(conditions.Has(vmCtx.VSphereVM, infrav1.MarkedAsTemplateCondition)).To(BeFalse())
+			g.Expect(isTemplate(ctx, vm)).To(BeFalse())
+			return nil
+		})
+	})
+}
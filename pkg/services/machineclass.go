@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// getMachineClass returns the VSphereMachineClass referenced by the VSphereMachine, or nil if
+// the VSphereMachine does not reference one.
+func (v *VimMachineService) getMachineClass(ctx context.Context, vimMachineCtx *capvcontext.VIMMachineContext) (*infrav1.VSphereMachineClass, error) {
+	name := vimMachineCtx.VSphereMachine.Spec.ClassName
+	if name == "" {
+		return nil, nil
+	}
+
+	machineClass := &infrav1.VSphereMachineClass{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: name}, machineClass); err != nil {
+		return nil, errors.Wrapf(err, "failed to get VSphereMachineClass %s", name)
+	}
+	return machineClass, nil
+}
+
+// applyMachineClass sets the CPU and memory defined by the class in the clone spec.
+func applyMachineClass(spec *infrav1.VirtualMachineCloneSpec, machineClass *infrav1.VSphereMachineClass) {
+	spec.NumCPUs = machineClass.Spec.NumCPUs
+	spec.NumCoresPerSocket = machineClass.Spec.NumCoresPerSocket
+	spec.MemoryMiB = machineClass.Spec.MemoryMiB
+	spec.MemoryReservationLockedToMax = machineClass.Spec.MemoryReservationLockedToMax
+	spec.ResourceAllocation = machineClass.Spec.ResourceAllocation.DeepCopy()
+}
+
+// copyMachineClass sets the CPU and memory of the clone spec to the ones of the existing
+// clone spec.
+func copyMachineClass(spec, existing *infrav1.VirtualMachineCloneSpec) {
+	spec.NumCPUs = existing.NumCPUs
+	spec.NumCoresPerSocket = existing.NumCoresPerSocket
+	spec.MemoryMiB = existing.MemoryMiB
+	spec.MemoryReservationLockedToMax = existing.MemoryReservationLockedToMax
+	spec.ResourceAllocation = existing.ResourceAllocation.DeepCopy()
+}
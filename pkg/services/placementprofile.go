@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	capvcontext "sigs.k8s.io/cluster-api-provider-vsphere/pkg/context"
+)
+
+// placementProfileFields are the clone spec fields set by the keys of a placement profile.
+// The compute cluster of a VM is selected by the path of its resource pool.
+var placementProfileFields = map[string]func(spec *infrav1.VirtualMachineCloneSpec) *string{
+	"server":            func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.Server },
+	"datacenter":        func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.Datacenter },
+	"folder":            func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.Folder },
+	"resourcePool":      func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.ResourcePool },
+	"datastore":         func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.Datastore },
+	"storagePolicyName": func(spec *infrav1.VirtualMachineCloneSpec) *string { return &spec.StoragePolicyName },
+}
+
+// getPlacementProfile returns the placement profile selected by the PlacementProfileLabel of
+// the Machine, or nil if the Machine does not select one.
+func (v *VimMachineService) getPlacementProfile(ctx context.Context, vimMachineCtx *capvcontext.VIMMachineContext) (map[string]string, error) {
+	name, ok := vimMachineCtx.Machine.Labels[infrav1.PlacementProfileLabel]
+	if !ok {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: vimMachineCtx.Machine.Namespace, Name: name}
+	if err := v.Client.Get(ctx, key, configMap); err != nil {
+		return nil, errors.Wrapf(err, "failed to get placement profile %s", name)
+	}
+
+	var unknownKeys []string
+	for key := range configMap.Data {
+		if _, ok := placementProfileFields[key]; !ok {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	if len(unknownKeys) > 0 {
+		sort.Strings(unknownKeys)
+		return nil, errors.Errorf("placement profile %s defines unknown keys %v", name, unknownKeys)
+	}
+	return configMap.Data, nil
+}
+
+// applyPlacementProfile sets the placement defined by the profile in the clone spec.
+func applyPlacementProfile(spec *infrav1.VirtualMachineCloneSpec, profile map[string]string) {
+	for key, value := range profile {
+		if value != "" {
+			*placementProfileFields[key](spec) = value
+		}
+	}
+}
+
+// copyPlacement sets the placement of the clone spec to the one of the existing clone spec.
+func copyPlacement(spec, existing *infrav1.VirtualMachineCloneSpec) {
+	for _, field := range placementProfileFields {
+		*field(spec) = *field(existing)
+	}
+}
@@ -20,6 +20,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -300,6 +301,29 @@ func (v *VimMachineService) reconcileNetwork(ctx context.Context, vimMachineCtx
 
 func (v *VimMachineService) createOrPatchVSphereVM(ctx context.Context, vimMachineCtx *capvcontext.VIMMachineContext, vsphereVM *infrav1.VSphereVM) (*infrav1.VSphereVM, error) {
 	log := ctrl.LoggerFrom(ctx)
+
+	// The placement profile is only resolved when the VSphereVM is created, as
+	// the placement of a VSphereVM is immutable.
+	var placementProfile map[string]string
+	if vsphereVM == nil {
+		var err error
+		if placementProfile, err = v.getPlacementProfile(ctx, vimMachineCtx); err != nil {
+			conditions.MarkFalse(vimMachineCtx.VSphereMachine, infrav1.VMProvisionedCondition, infrav1.PlacementProfileInvalidReason, clusterv1.ConditionSeverityError, err.Error())
+			return nil, err
+		}
+	}
+
+	// Like the placement profile, the machine class is only resolved when the
+	// VSphereVM is created, so changes of the class apply to new machines.
+	var machineClass *infrav1.VSphereMachineClass
+	if vsphereVM == nil {
+		var err error
+		if machineClass, err = v.getMachineClass(ctx, vimMachineCtx); err != nil {
+			conditions.MarkFalse(vimMachineCtx.VSphereMachine, infrav1.VMProvisionedCondition, infrav1.MachineClassNotFoundReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return nil, err
+		}
+	}
+
 	// Create or update the VSphereVM resource.
 	vm := &infrav1.VSphereVM{
 		ObjectMeta: metav1.ObjectMeta{
@@ -327,25 +351,30 @@ func (v *VimMachineService) createOrPatchVSphereVM(ctx context.Context, vimMachi
 			Namespace:  vimMachineCtx.Machine.ObjectMeta.Namespace,
 		}
 
-		// Initialize the VSphereVM's labels map if it is nil.
-		if vm.Labels == nil {
-			vm.Labels = map[string]string{}
-		}
-
-		// Ensure the VSphereVM has a label that can be used when searching for
-		// resources associated with the target cluster.
-		vm.Labels[clusterv1.ClusterNameLabel] = vimMachineCtx.Machine.Labels[clusterv1.ClusterNameLabel]
-
-		// For convenience, add a label that makes it easy to figure out if the
-		// VSphereVM resource is part of some control plane.
-		if val, ok := vimMachineCtx.Machine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
-			vm.Labels[clusterv1.MachineControlPlaneLabel] = val
-		}
+		vm.Labels = getVSphereVMLabels(vimMachineCtx, vm.Labels)
 
 		// Copy the VSphereMachine's VM clone spec into the VSphereVM's
 		// clone spec.
 		vimMachineCtx.VSphereMachine.Spec.VirtualMachineCloneSpec.DeepCopyInto(&vm.Spec.VirtualMachineCloneSpec)
 
+		// The placement profile selected by the Machine takes precedence over the
+		// placement in the VSphereMachine.
+		if _, ok := vimMachineCtx.Machine.Labels[infrav1.PlacementProfileLabel]; ok && vsphereVM != nil {
+			copyPlacement(&vm.Spec.VirtualMachineCloneSpec, &vsphereVM.Spec.VirtualMachineCloneSpec)
+		} else {
+			applyPlacementProfile(&vm.Spec.VirtualMachineCloneSpec, placementProfile)
+		}
+
+		// The CPU and memory of the machine class take precedence over the
+		// VSphereMachine, which must not define them if it references a class.
+		if vimMachineCtx.VSphereMachine.Spec.ClassName != "" {
+			if vsphereVM != nil {
+				copyMachineClass(&vm.Spec.VirtualMachineCloneSpec, &vsphereVM.Spec.VirtualMachineCloneSpec)
+			} else {
+				applyMachineClass(&vm.Spec.VirtualMachineCloneSpec, machineClass)
+			}
+		}
+
 		// If Failure Domain is present on CAPI machine, use that to override the vm clone spec.
 		if overrideFunc, ok := v.generateOverrideFunc(ctx, vimMachineCtx); ok {
 			overrideFunc(vm)
@@ -355,17 +384,66 @@ func (v *VimMachineService) createOrPatchVSphereVM(ctx context.Context, vimMachi
 		// from multiple places. The order is:
 		//
 		//   1. From the Machine.Spec.FailureDomain
-		//   2. From the VSphereMachine.Spec (the DeepCopyInto above)
-		//   3. From the VSphereCluster.Spec
+		//   2. From the placement profile selected by the Machine
+		//   3. From the VSphereMachine.Spec (the DeepCopyInto above)
+		//   4. From the VSphereCluster.Spec
 		if vm.Spec.Server == "" {
 			vm.Spec.Server = vimMachineCtx.VSphereCluster.Spec.Server
 		}
 		if vm.Spec.Thumbprint == "" {
 			vm.Spec.Thumbprint = vimMachineCtx.VSphereCluster.Spec.Thumbprint
 		}
+		// The resource pool created for the cluster takes precedence. As the
+		// resource pool of a VSphereVM is immutable, it is only set on creation.
+		if resourcePool := vimMachineCtx.VSphereCluster.Status.ResourcePool; resourcePool != "" {
+			if vsphereVM != nil {
+				vm.Spec.ResourcePool = vsphereVM.Spec.ResourcePool
+			} else {
+				vm.Spec.ResourcePool = resourcePool
+			}
+		}
 		if vsphereVM != nil {
 			vm.Spec.BiosUUID = vsphereVM.Spec.BiosUUID
 		}
+		// A redeploy changes the template of the VSphereVM and writes the MAC addresses of its
+		// VM to the network devices, which are kept like the BIOS UUID. The template can not be
+		// changed otherwise, so it is also kept once the redeploy annotation is removed.
+		if vsphereVM != nil {
+			vm.Spec.Template = vsphereVM.Spec.Template
+			for i := range vm.Spec.Network.Devices {
+				if i < len(vsphereVM.Spec.Network.Devices) && vm.Spec.Network.Devices[i].MACAddr == "" {
+					vm.Spec.Network.Devices[i].MACAddr = vsphereVM.Spec.Network.Devices[i].MACAddr
+				}
+			}
+		}
+		// The SSH authorized keys are only applied when the VM is created, so
+		// they are not updated on existing VSphereVMs.
+		if vsphereVM != nil {
+			vm.Spec.SSHAuthorizedKeys = vsphereVM.Spec.SSHAuthorizedKeys
+		} else {
+			vm.Spec.SSHAuthorizedKeys = vimMachineCtx.VSphereCluster.Spec.SSHAuthorizedKeys
+		}
+		// Like the SSH authorized keys, the proxy is only applied when the VM is created.
+		if vsphereVM != nil {
+			vm.Spec.Proxy = vsphereVM.Spec.Proxy
+		} else {
+			vm.Spec.Proxy = clusterProxyConfiguration(vimMachineCtx.Cluster, vimMachineCtx.VSphereCluster)
+		}
+		// Like the proxy, the free memory threshold of the hosts only applies to the placement
+		// of new VMs.
+		if vsphereVM != nil {
+			vm.Spec.MinHostFreeMemoryMiB = vsphereVM.Spec.MinHostFreeMemoryMiB
+		} else {
+			vm.Spec.MinHostFreeMemoryMiB = vimMachineCtx.VSphereCluster.Spec.MinHostFreeMemoryMiB
+		}
+		// Like the proxy, the registry mirrors are only applied when the VM is created.
+		if vsphereVM != nil {
+			vm.Spec.RegistryMirrors = vsphereVM.Spec.RegistryMirrors
+		} else {
+			vm.Spec.RegistryMirrors = vimMachineCtx.VSphereCluster.Spec.RegistryMirrors
+		}
+		// Unlike the placement of new VMs, draining datastores apply to existing VMs.
+		vm.Spec.DrainingDatastores = vimMachineCtx.VSphereCluster.Spec.DrainingDatastores
 		vm.Spec.PowerOffMode = vimMachineCtx.VSphereMachine.Spec.PowerOffMode
 		vm.Spec.GuestSoftPowerOffTimeout = vimMachineCtx.VSphereMachine.Spec.GuestSoftPowerOffTimeout
 		return nil
@@ -391,6 +469,57 @@ func (v *VimMachineService) createOrPatchVSphereVM(ctx context.Context, vimMachi
 	return vm, nil
 }
 
+// clusterProxyConfiguration returns the proxy configuration of the VSphereCluster with the
+// pod and service CIDRs of the cluster and the host of its control plane endpoint added to
+// the no proxy list, so traffic within the cluster does not go through the proxy.
+func clusterProxyConfiguration(cluster *clusterv1.Cluster, vsphereCluster *infrav1.VSphereCluster) *infrav1.ProxyConfiguration {
+	if vsphereCluster.Spec.Proxy == nil {
+		return nil
+	}
+	proxy := vsphereCluster.Spec.Proxy.DeepCopy()
+
+	var noProxy []string
+	if cluster != nil && cluster.Spec.ClusterNetwork != nil {
+		if pods := cluster.Spec.ClusterNetwork.Pods; pods != nil {
+			noProxy = append(noProxy, pods.CIDRBlocks...)
+		}
+		if services := cluster.Spec.ClusterNetwork.Services; services != nil {
+			noProxy = append(noProxy, services.CIDRBlocks...)
+		}
+	}
+	if host := vsphereCluster.Spec.ControlPlaneEndpoint.Host; host != "" {
+		noProxy = append(noProxy, host)
+	}
+	for _, entry := range noProxy {
+		if !slices.Contains(proxy.NoProxy, entry) {
+			proxy.NoProxy = append(proxy.NoProxy, entry)
+		}
+	}
+	return proxy
+}
+
+// getVSphereVMLabels returns the labels of the VSphereVM with the labels owned by CAPV
+// ensured. Other labels, e.g. labels applied by users, are kept as is, so they survive
+// the VSphereVM being moved to another management cluster by clusterctl move, and the
+// labels owned by CAPV are re-established if they were lost.
+func getVSphereVMLabels(vimMachineCtx *capvcontext.VIMMachineContext, vmLabels map[string]string) map[string]string {
+	if vmLabels == nil {
+		vmLabels = map[string]string{}
+	}
+
+	// Ensure the VSphereVM has a label that can be used when searching for
+	// resources associated with the target cluster.
+	vmLabels[clusterv1.ClusterNameLabel] = vimMachineCtx.Cluster.Name
+
+	// For convenience, add a label that makes it easy to figure out if the
+	// VSphereVM resource is part of some control plane.
+	if val, ok := vimMachineCtx.Machine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
+		vmLabels[clusterv1.MachineControlPlaneLabel] = val
+	}
+
+	return vmLabels
+}
+
 // generateVMObjectName returns a new VM object name in specific cases, otherwise return the same
 // passed in the parameter.
 func generateVMObjectName(vimMachineCtx *capvcontext.VIMMachineContext, machineName string) string {
@@ -329,6 +329,208 @@ func Test_VimMachineService_createOrPatchVSphereVM(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 		g.Expect(vmName).To(Equal(fakeLongClusterName))
 	})
+
+	t.Run("keeps the labels of a VSphereVM moved by clusterctl", func(t *testing.T) {
+		g := NewWithT(t)
+		// A VSphereVM as created by clusterctl move on the target management cluster,
+		// with labels applied by users but without the control plane label owned by CAPV.
+		vsphereVM := getVSphereVM(hostAddr, corev1.ConditionTrue)
+		vsphereVM.Labels = map[string]string{
+			clusterv1.ClusterNameLabel: fake.Clusterv1a2Name,
+			"example.com/team":         "payments",
+		}
+		controllerManagerContext := fake.NewControllerManagerContext(vsphereVM)
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		machineCtx.Machine.SetLabels(map[string]string{clusterv1.MachineControlPlaneLabel: ""})
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, vsphereVM)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Labels).To(Equal(map[string]string{
+			clusterv1.ClusterNameLabel:         fake.Clusterv1a2Name,
+			clusterv1.MachineControlPlaneLabel: "",
+			"example.com/team":                 "payments",
+		}))
+
+		// Re-establishing the labels owned by CAPV is idempotent.
+		resourceVersion := vm.ResourceVersion
+		vm, err = vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, vm)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.ResourceVersion).To(Equal(resourceVersion))
+		g.Expect(vm.Labels).To(HaveKeyWithValue("example.com/team", "payments"))
+	})
+
+	t.Run("sets the cluster name label when the Machine lacks it", func(t *testing.T) {
+		g := NewWithT(t)
+		controllerManagerContext := fake.NewControllerManagerContext()
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		machineCtx.Machine.SetLabels(nil)
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Labels).To(Equal(map[string]string{clusterv1.ClusterNameLabel: fake.Clusterv1a2Name}))
+	})
+
+	placementProfile := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: fake.Namespace, Name: "profile-one"},
+			Data:       data,
+		}
+	}
+
+	t.Run("uses the placement profile selected by the Machine when creating the VSphereVM", func(t *testing.T) {
+		g := NewWithT(t)
+		controllerManagerContext := fake.NewControllerManagerContext(placementProfile(map[string]string{
+			"datacenter":   "dc-one",
+			"resourcePool": "/dc-one/host/cluster-one/Resources",
+			"datastore":    "ds-one",
+		}))
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.VSphereMachine.Spec.Datacenter = "dc-template"
+		machineCtx.VSphereMachine.Spec.Folder = "folder-template"
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		machineCtx.Machine.SetLabels(map[string]string{infrav1.PlacementProfileLabel: "profile-one"})
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Spec.Datacenter).To(Equal("dc-one"))
+		g.Expect(vm.Spec.ResourcePool).To(Equal("/dc-one/host/cluster-one/Resources"))
+		g.Expect(vm.Spec.Datastore).To(Equal("ds-one"))
+		g.Expect(vm.Spec.Folder).To(Equal("folder-template"))
+	})
+
+	t.Run("keeps the placement of an existing VSphereVM when the placement profile changes", func(t *testing.T) {
+		g := NewWithT(t)
+		vsphereVM := getVSphereVM(hostAddr, corev1.ConditionTrue)
+		vsphereVM.Spec.Datacenter = "dc-one"
+		vsphereVM.Spec.Datastore = "ds-one"
+		controllerManagerContext := fake.NewControllerManagerContext(vsphereVM, placementProfile(map[string]string{
+			"datacenter": "dc-two",
+			"datastore":  "ds-two",
+		}))
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		machineCtx.Machine.SetLabels(map[string]string{infrav1.PlacementProfileLabel: "profile-one"})
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, vsphereVM)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Spec.Datacenter).To(Equal("dc-one"))
+		g.Expect(vm.Spec.Datastore).To(Equal("ds-one"))
+	})
+
+	for _, tt := range []struct {
+		name    string
+		objects []ctrlclient.Object
+		wantErr string
+	}{
+		{
+			name:    "fails when the placement profile selected by the Machine does not exist",
+			wantErr: "failed to get placement profile profile-one",
+		},
+		{
+			name:    "fails when the placement profile selected by the Machine defines unknown keys",
+			objects: []ctrlclient.Object{placementProfile(map[string]string{"datacenter": "dc-one", "cluster": "cluster-one"})},
+			wantErr: "placement profile profile-one defines unknown keys [cluster]",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			controllerManagerContext := fake.NewControllerManagerContext(tt.objects...)
+			machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+			machineCtx.Machine.SetName(fakeLongClusterName)
+			machineCtx.Machine.SetLabels(map[string]string{infrav1.PlacementProfileLabel: "profile-one"})
+			vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+			_, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, nil)
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(conditions.GetReason(machineCtx.VSphereMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.PlacementProfileInvalidReason))
+		})
+	}
+
+	machineClass := func(numCPUs int32, memoryMiB int64) *infrav1.VSphereMachineClass {
+		return &infrav1.VSphereMachineClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "medium"},
+			Spec: infrav1.VSphereMachineClassSpec{
+				NumCPUs:   numCPUs,
+				MemoryMiB: memoryMiB,
+				ResourceAllocation: &infrav1.VirtualMachineResourceAllocation{
+					Memory: &infrav1.ResourceAllocationSpec{Reservation: ptr.To[int64](memoryMiB / 2)},
+				},
+			},
+		}
+	}
+
+	t.Run("uses the machine class referenced by the VSphereMachine when creating the VSphereVM", func(t *testing.T) {
+		g := NewWithT(t)
+		controllerManagerContext := fake.NewControllerManagerContext(machineClass(4, 8192))
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.VSphereMachine.Spec.ClassName = "medium"
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Spec.NumCPUs).To(Equal(int32(4)))
+		g.Expect(vm.Spec.MemoryMiB).To(Equal(int64(8192)))
+		g.Expect(vm.Spec.ResourceAllocation.Memory.Reservation).To(Equal(ptr.To[int64](4096)))
+	})
+
+	t.Run("keeps the CPU and memory of an existing VSphereVM when the machine class changes", func(t *testing.T) {
+		g := NewWithT(t)
+		vsphereVM := getVSphereVM(hostAddr, corev1.ConditionTrue)
+		vsphereVM.Spec.NumCPUs = 4
+		vsphereVM.Spec.MemoryMiB = 8192
+		controllerManagerContext := fake.NewControllerManagerContext(vsphereVM, machineClass(8, 16384))
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.VSphereMachine.Spec.ClassName = "medium"
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, vsphereVM)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Spec.NumCPUs).To(Equal(int32(4)))
+		g.Expect(vm.Spec.MemoryMiB).To(Equal(int64(8192)))
+		g.Expect(vm.Spec.ResourceAllocation).To(BeNil())
+	})
+
+	t.Run("keeps the template and MAC addresses of a VSphereVM being redeployed", func(t *testing.T) {
+		g := NewWithT(t)
+		vsphereVM := getVSphereVM(hostAddr, corev1.ConditionTrue)
+		vsphereVM.Annotations = map[string]string{infrav1.RedeployAnnotation: ""}
+		vsphereVM.Spec.Template = "ubuntu-2204-kube-v1.29.0"
+		vsphereVM.Spec.Network.Devices = []infrav1.NetworkDeviceSpec{
+			{NetworkName: "VM Network", DHCP4: true, DHCP6: true, MACAddr: "00:50:56:00:00:01"},
+		}
+		controllerManagerContext := fake.NewControllerManagerContext(vsphereVM)
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.VSphereMachine.Spec.Template = "ubuntu-2204-kube-v1.28.0"
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		vm, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, vsphereVM)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(vm.Spec.Template).To(Equal("ubuntu-2204-kube-v1.29.0"))
+		g.Expect(vm.Spec.Network.Devices).To(HaveLen(1))
+		g.Expect(vm.Spec.Network.Devices[0].MACAddr).To(Equal("00:50:56:00:00:01"))
+	})
+
+	t.Run("fails when the machine class referenced by the VSphereMachine does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		controllerManagerContext := fake.NewControllerManagerContext()
+		machineCtx := fake.NewMachineContext(ctx, fake.NewClusterContext(ctx, controllerManagerContext), controllerManagerContext)
+		machineCtx.VSphereMachine.Spec.ClassName = "medium"
+		machineCtx.Machine.SetName(fakeLongClusterName)
+		vimMachineService := &VimMachineService{controllerManagerContext.Client}
+
+		_, err := vimMachineService.createOrPatchVSphereVM(ctx, machineCtx, nil)
+		g.Expect(err).To(MatchError(ContainSubstring("failed to get VSphereMachineClass medium")))
+		g.Expect(conditions.GetReason(machineCtx.VSphereMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.MachineClassNotFoundReason))
+	})
 }
 
 func Test_VimMachineService_reconcileProviderID(t *testing.T) {
@@ -738,3 +940,41 @@ func Test_VimMachineService_SyncFailureReason(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 	})
 }
+
+func Test_clusterProxyConfiguration(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+				Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+			},
+		},
+	}
+	newVSphereCluster := func(proxy *infrav1.ProxyConfiguration) *infrav1.VSphereCluster {
+		return &infrav1.VSphereCluster{
+			Spec: infrav1.VSphereClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+				Proxy:                proxy,
+			},
+		}
+	}
+
+	t.Run("returns nil without proxy", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterProxyConfiguration(cluster, newVSphereCluster(nil))).To(BeNil())
+	})
+
+	t.Run("adds the networks of the cluster to the no proxy list", func(t *testing.T) {
+		g := NewWithT(t)
+		vsphereCluster := newVSphereCluster(&infrav1.ProxyConfiguration{
+			HTTPProxy: "http://proxy:3128",
+			NoProxy:   []string{".example.com", "10.96.0.0/12"},
+		})
+		g.Expect(clusterProxyConfiguration(cluster, vsphereCluster)).To(Equal(&infrav1.ProxyConfiguration{
+			HTTPProxy: "http://proxy:3128",
+			NoProxy:   []string{".example.com", "10.96.0.0/12", "192.168.0.0/16", "10.0.0.10"},
+		}))
+		// The proxy of the VSphereCluster is not modified.
+		g.Expect(vsphereCluster.Spec.Proxy.NoProxy).To(HaveLen(2))
+	})
+}
@@ -20,12 +20,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	vmoprv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -200,6 +204,11 @@ func (v *VmopMachineService) ReconcileNormal(ctx context.Context, machineCtx cap
 	// Update the VM's state to Pending
 	supervisorMachineCtx.VSphereMachine.Status.VMStatus = vmwarev1.VirtualMachineStatePending
 
+	// Mirror the conditions of the VM Operator VirtualMachine to surface their root cause.
+	if err := mirrorVMOperatorConditions(supervisorMachineCtx.VSphereMachine, vmOperatorVM); err != nil {
+		log.Error(err, "Failed to mirror the conditions of the VirtualMachine")
+	}
+
 	// Since vm operator only has one condition for now, we can't set vspheremachine's condition fully based on virtualmachine's
 	// condition. Once vm operator surfaces enough conditions in virtualmachine, we could simply mirror the conditions in vspheremachine.
 	// For now, we set conditions based on the whole virtualmachine status.
@@ -623,3 +632,68 @@ func getTopologyLabels(supervisorMachineCtx *vmware.SupervisorMachineContext) ma
 func getMachineDeploymentNameForCluster(cluster *clusterv1.Cluster) string {
 	return fmt.Sprintf("%s-workers-0", cluster.Name)
 }
+
+// mirrorVMOperatorConditions sets the conditions of the VM Operator VirtualMachine
+// on the VSphereMachine, prefixed with VMOperatorConditionPrefix, and removes the
+// mirrored conditions which no longer exist on the VirtualMachine.
+// The conditions are read from the unstructured object as the condition type differs
+// between the API versions of VM Operator, e.g. only some of them define a severity.
+func mirrorVMOperatorConditions(vsphereMachine *vmwarev1.VSphereMachine, vmOperatorVM client.Object) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(vmOperatorVM)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert VirtualMachine %s to unstructured", vmOperatorVM.GetName())
+	}
+	vmConditions, _, err := unstructured.NestedSlice(obj, "status", "conditions")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get conditions of VirtualMachine %s", vmOperatorVM.GetName())
+	}
+
+	mirrored := map[clusterv1.ConditionType]bool{}
+	for _, c := range vmConditions {
+		vmCondition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(vmCondition, "type")
+		if conditionType == "" {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(vmCondition, "status")
+		reason, _, _ := unstructured.NestedString(vmCondition, "reason")
+		message, _, _ := unstructured.NestedString(vmCondition, "message")
+		severity, _, _ := unstructured.NestedString(vmCondition, "severity")
+
+		condition := &clusterv1.Condition{
+			Type:    clusterv1.ConditionType(vmwarev1.VMOperatorConditionPrefix + conditionType),
+			Status:  corev1.ConditionStatus(status),
+			Reason:  reason,
+			Message: message,
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			condition.Reason = ""
+			condition.Message = ""
+		case corev1.ConditionFalse:
+			condition.Severity = clusterv1.ConditionSeverity(severity)
+			if condition.Severity == clusterv1.ConditionSeverityNone {
+				condition.Severity = clusterv1.ConditionSeverityWarning
+			}
+		default:
+			condition.Status = corev1.ConditionUnknown
+		}
+		if lastTransitionTime, _, _ := unstructured.NestedString(vmCondition, "lastTransitionTime"); lastTransitionTime != "" {
+			if t, err := time.Parse(time.RFC3339, lastTransitionTime); err == nil {
+				condition.LastTransitionTime = metav1.NewTime(t)
+			}
+		}
+		conditions.Set(vsphereMachine, condition)
+		mirrored[condition.Type] = true
+	}
+
+	for _, condition := range vsphereMachine.GetConditions() {
+		if strings.HasPrefix(string(condition.Type), vmwarev1.VMOperatorConditionPrefix) && !mirrored[condition.Type] {
+			conditions.Delete(vsphereMachine, condition.Type)
+		}
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -449,6 +450,12 @@ var _ = Describe("VirtualMachine tests", func() {
 				Severity: clusterv1.ConditionSeverityError,
 				Reason:   vmoprv1.VirtualMachineClassBindingNotFoundReason,
 				Message:  errMessage,
+			}, clusterv1.Condition{
+				Type:     clusterv1.ConditionType(vmwarev1.VMOperatorConditionPrefix + string(vmoprv1.VirtualMachinePrereqReadyCondition)),
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityError,
+				Reason:   vmoprv1.VirtualMachineClassBindingNotFoundReason,
+				Message:  errMessage,
 			})
 			verifyOutput(supervisorMachineContext)
 		})
@@ -548,6 +555,44 @@ var _ = Describe("VirtualMachine tests", func() {
 		})
 	})
 
+	Context("Mirror VirtualMachine conditions", func() {
+		Specify("Mirror conditions of VirtualMachines without severity", func() {
+			vsphereMachine := &vmwarev1.VSphereMachine{}
+			conditions.MarkTrue(vsphereMachine, clusterv1.ConditionType(vmwarev1.VMOperatorConditionPrefix+"Stale"))
+			conditions.MarkTrue(vsphereMachine, infrav1.VMProvisionedCondition)
+
+			vm := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{
+							"type":               "VirtualMachineImageReady",
+							"status":             "False",
+							"reason":             "NotFound",
+							"message":            "image not found",
+							"lastTransitionTime": "2024-01-02T03:04:05Z",
+						},
+						map[string]interface{}{
+							"type":   "VirtualMachineClassReady",
+							"status": "True",
+						},
+					},
+				},
+			}}
+			Expect(mirrorVMOperatorConditions(vsphereMachine, vm)).To(Succeed())
+
+			imageReady := conditions.Get(vsphereMachine, vmwarev1.VMOperatorConditionPrefix+"VirtualMachineImageReady")
+			Expect(imageReady).NotTo(BeNil())
+			Expect(imageReady.Status).To(Equal(corev1.ConditionFalse))
+			Expect(imageReady.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			Expect(imageReady.Reason).To(Equal("NotFound"))
+			Expect(imageReady.Message).To(Equal("image not found"))
+			Expect(imageReady.LastTransitionTime.UTC()).To(Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+			Expect(conditions.IsTrue(vsphereMachine, vmwarev1.VMOperatorConditionPrefix+"VirtualMachineClassReady")).To(BeTrue())
+			Expect(conditions.Has(vsphereMachine, vmwarev1.VMOperatorConditionPrefix+"Stale")).To(BeFalse())
+			Expect(conditions.IsTrue(vsphereMachine, infrav1.VMProvisionedCondition)).To(BeTrue())
+		})
+	})
+
 	Context("Delete tests", func() {
 		timeout := time.Second * 5
 		interval := time.Second * 1
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	inventoryKindDatacenter   = "Datacenter"
+	inventoryKindFolder       = "Folder"
+	inventoryKindResourcePool = "ResourcePool"
+	inventoryKindDatastore    = "Datastore"
+)
+
+var (
+	// inventoryCache caches the references of resolved inventory objects, e.g. folders
+	// and resource pools, to reduce the number of calls to vCenter.
+	inventoryCache = &objectCache{entries: map[inventoryCacheKey]inventoryCacheEntry{}}
+
+	inventoryCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capv_inventory_cache_requests_total",
+		Help: "Number of lookups of vSphere inventory objects by result of the cache, i.e. hit or miss. " +
+			"The hit ratio is the rate of hits divided by the rate of all lookups.",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(inventoryCacheRequests)
+}
+
+// SetInventoryCacheTTL sets the time to live of the cached inventory objects.
+// Caching is disabled if the time to live is zero, which is the default.
+func SetInventoryCacheTTL(ttl time.Duration) {
+	inventoryCache.Lock()
+	defer inventoryCache.Unlock()
+	inventoryCache.ttl = ttl
+	inventoryCache.entries = map[inventoryCacheKey]inventoryCacheEntry{}
+}
+
+type inventoryCacheKey struct {
+	server     string
+	user       string
+	datacenter string
+	kind       string
+	path       string
+}
+
+type inventoryCacheEntry struct {
+	ref            types.ManagedObjectReference
+	inventoryPath  string
+	datacenterPath string
+	expires        time.Time
+}
+
+type objectCache struct {
+	sync.RWMutex
+	ttl     time.Duration
+	entries map[inventoryCacheKey]inventoryCacheEntry
+}
+
+// findDatacenter returns the datacenter at the given path, using the inventory cache.
+func (s *Session) findDatacenter(ctx context.Context, path string) (*object.Datacenter, error) {
+	ttl := s.inventoryCacheTTL()
+	if ttl <= 0 {
+		return s.Finder.Datacenter(ctx, path)
+	}
+	entry, err := s.resolve(ttl, inventoryKindDatacenter, path, func() (inventoryCacheEntry, error) {
+		dc, err := s.Finder.Datacenter(ctx, path)
+		if err != nil {
+			return inventoryCacheEntry{}, err
+		}
+		return inventoryCacheEntry{ref: dc.Reference(), inventoryPath: dc.InventoryPath}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dc := object.NewDatacenter(s.Client.Client, entry.ref)
+	dc.InventoryPath = entry.inventoryPath
+	return dc, nil
+}
+
+// FolderOrDefault returns the folder at the given path or the default folder,
+// using the inventory cache.
+func (s *Session) FolderOrDefault(ctx context.Context, path string) (*object.Folder, error) {
+	ttl := s.inventoryCacheTTL()
+	if ttl <= 0 {
+		return s.Finder.FolderOrDefault(ctx, path)
+	}
+	entry, err := s.resolve(ttl, inventoryKindFolder, path, func() (inventoryCacheEntry, error) {
+		folder, err := s.Finder.FolderOrDefault(ctx, path)
+		if err != nil {
+			return inventoryCacheEntry{}, err
+		}
+		return inventoryCacheEntry{ref: folder.Reference(), inventoryPath: folder.InventoryPath}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	folder := object.NewFolder(s.Client.Client, entry.ref)
+	folder.InventoryPath = entry.inventoryPath
+	return folder, nil
+}
+
+// ResourcePoolOrDefault returns the resource pool at the given path or the default
+// resource pool, using the inventory cache.
+func (s *Session) ResourcePoolOrDefault(ctx context.Context, path string) (*object.ResourcePool, error) {
+	ttl := s.inventoryCacheTTL()
+	if ttl <= 0 {
+		return s.Finder.ResourcePoolOrDefault(ctx, path)
+	}
+	entry, err := s.resolve(ttl, inventoryKindResourcePool, path, func() (inventoryCacheEntry, error) {
+		pool, err := s.Finder.ResourcePoolOrDefault(ctx, path)
+		if err != nil {
+			return inventoryCacheEntry{}, err
+		}
+		return inventoryCacheEntry{ref: pool.Reference(), inventoryPath: pool.InventoryPath}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	pool := object.NewResourcePool(s.Client.Client, entry.ref)
+	pool.InventoryPath = entry.inventoryPath
+	return pool, nil
+}
+
+// DatastoreOrDefault returns the datastore at the given path or the default
+// datastore, using the inventory cache.
+func (s *Session) DatastoreOrDefault(ctx context.Context, path string) (*object.Datastore, error) {
+	ttl := s.inventoryCacheTTL()
+	if ttl <= 0 {
+		return s.Finder.DatastoreOrDefault(ctx, path)
+	}
+	entry, err := s.resolve(ttl, inventoryKindDatastore, path, func() (inventoryCacheEntry, error) {
+		datastore, err := s.Finder.DatastoreOrDefault(ctx, path)
+		if err != nil {
+			return inventoryCacheEntry{}, err
+		}
+		return inventoryCacheEntry{ref: datastore.Reference(), inventoryPath: datastore.InventoryPath, datacenterPath: datastore.DatacenterPath}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	datastore := object.NewDatastore(s.Client.Client, entry.ref)
+	datastore.InventoryPath = entry.inventoryPath
+	datastore.DatacenterPath = entry.datacenterPath
+	return datastore, nil
+}
+
+// InvalidateInventoryObject removes the given object from the inventory cache, e.g.
+// after vCenter reported the object as not found.
+func (s *Session) InvalidateInventoryObject(ref types.ManagedObjectReference) {
+	server := s.server()
+	inventoryCache.Lock()
+	defer inventoryCache.Unlock()
+	for key, entry := range inventoryCache.entries {
+		if key.server == server && entry.ref == ref {
+			delete(inventoryCache.entries, key)
+		}
+	}
+}
+
+// resolve returns the cached entry of the object of the given kind at the given path,
+// or resolves and caches it. Objects are cached per user, as the objects visible to
+// sessions depend on the permissions of their user.
+func (s *Session) resolve(ttl time.Duration, kind, path string, find func() (inventoryCacheEntry, error)) (inventoryCacheEntry, error) {
+	key := inventoryCacheKey{server: s.server(), user: s.user, datacenter: s.datacenterPath(), kind: kind, path: path}
+	inventoryCache.RLock()
+	entry, ok := inventoryCache.entries[key]
+	inventoryCache.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		inventoryCacheRequests.WithLabelValues("hit").Inc()
+		return entry, nil
+	}
+
+	inventoryCacheRequests.WithLabelValues("miss").Inc()
+	entry, err := find()
+	inventoryCache.Lock()
+	defer inventoryCache.Unlock()
+	if err != nil {
+		// Objects which are not found anymore must not be served from the cache.
+		delete(inventoryCache.entries, key)
+		return inventoryCacheEntry{}, err
+	}
+	entry.expires = time.Now().Add(ttl)
+	inventoryCache.entries[key] = entry
+	return entry, nil
+}
+
+// inventoryCacheTTL returns the time to live of the cached inventory objects, or zero
+// if the inventory cache is disabled or cannot be used by the session.
+func (s *Session) inventoryCacheTTL() time.Duration {
+	if s.Client == nil {
+		return 0
+	}
+	inventoryCache.RLock()
+	defer inventoryCache.RUnlock()
+	return inventoryCache.ttl
+}
+
+func (s *Session) server() string {
+	if s.Client == nil || s.Client.URL() == nil {
+		return ""
+	}
+	return s.Client.URL().Host
+}
+
+func (s *Session) datacenterPath() string {
+	if s.datacenter == nil {
+		return ""
+	}
+	return s.datacenter.InventoryPath
+}
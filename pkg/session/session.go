@@ -20,6 +20,7 @@ package session
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/x509"
 	"fmt"
 	"net/netip"
 	"net/url"
@@ -42,6 +43,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/constants"
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/tracing"
 )
 
 var (
@@ -51,6 +53,10 @@ var (
 	// mutex to control access to the GetOrCreate function to avoid duplicate
 	// session creations on startup.
 	sessionMU sync.Mutex
+
+	// CA bundle used to verify the certificate of servers whose parameters
+	// have no CA bundle.
+	defaultCABundle []byte
 )
 
 // Session is a vSphere session with a configured Finder.
@@ -59,6 +65,9 @@ type Session struct {
 	Finder     *find.Finder
 	datacenter *object.Datacenter
 	TagManager *tags.Manager
+
+	// user identifies the credentials of the session in the inventory cache.
+	user string
 }
 
 // Feature is a set of Features of the session.
@@ -80,6 +89,7 @@ type Params struct {
 	datacenter string
 	userinfo   *url.Userinfo
 	thumbprint string
+	caBundle   []byte
 	feature    Feature
 }
 
@@ -114,12 +124,37 @@ func (p *Params) WithThumbprint(thumbprint string) *Params {
 	return p
 }
 
+// WithCABundle adds a PEM encoded bundle of CA certificates to parameters,
+// which is used to verify the certificate of the server.
+func (p *Params) WithCABundle(caBundle []byte) *Params {
+	p.caBundle = caBundle
+	return p
+}
+
 // WithFeatures adds features to parameters.
 func (p *Params) WithFeatures(feature Feature) *Params {
 	p.feature = feature
 	return p
 }
 
+// SetDefaultCABundle sets the PEM encoded bundle of CA certificates used to verify
+// the certificate of servers whose parameters have no CA bundle.
+func SetDefaultCABundle(caBundle []byte) {
+	sessionMU.Lock()
+	defer sessionMU.Unlock()
+	defaultCABundle = caBundle
+}
+
+// ParseCABundle returns a pool of the certificates of the PEM encoded CA bundle.
+// It returns an error if the bundle contains no valid certificate.
+func ParseCABundle(caBundle []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("CA bundle contains no valid PEM encoded certificate")
+	}
+	return pool, nil
+}
+
 // GetOrCreate gets a cached session or creates a new one if one does not
 // already exist.
 func GetOrCreate(ctx context.Context, params *Params) (*Session, error) {
@@ -132,9 +167,15 @@ func GetOrCreate(ctx context.Context, params *Params) (*Session, error) {
 	sessionMU.Lock()
 	defer sessionMU.Unlock()
 
+	caBundle := params.caBundle
+	if len(caBundle) == 0 {
+		caBundle = defaultCABundle
+	}
+
 	userPassword, _ := params.userinfo.Password()
 	h := sha256.New()
 	h.Write([]byte(userPassword))
+	h.Write(caBundle)
 	hashedUserPassword := h.Sum(nil)
 	sessionKey := fmt.Sprintf("%s#%s#%s#%x", params.server, params.datacenter, params.userinfo.Username(),
 		hashedUserPassword)
@@ -191,12 +232,12 @@ func GetOrCreate(ctx context.Context, params *Params) (*Session, error) {
 	}
 
 	soapURL.User = params.userinfo
-	client, err := newClient(ctx, sessionKey, soapURL, params.thumbprint, params.feature)
+	client, err := newClient(ctx, sessionKey, soapURL, params.thumbprint, caBundle, params.feature)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create vCenter session")
 	}
 
-	session := Session{Client: client}
+	session := Session{Client: client, user: fmt.Sprintf("%s#%x", params.userinfo.Username(), hashedUserPassword)}
 	session.UserAgent = infrav1.GroupVersion.String()
 
 	// Assign the finder to the session.
@@ -215,7 +256,7 @@ func GetOrCreate(ctx context.Context, params *Params) (*Session, error) {
 
 	// Assign the datacenter if one was specified.
 	if params.datacenter != "" {
-		dc, err := session.Finder.Datacenter(ctx, params.datacenter)
+		dc, err := session.findDatacenter(ctx, params.datacenter)
 		if err != nil {
 			log.Error(err, "Failed to get datacenter, will logout")
 			// Logout of previously logged session to not leak
@@ -238,20 +279,31 @@ func GetOrCreate(ctx context.Context, params *Params) (*Session, error) {
 	return &session, nil
 }
 
-func newClient(ctx context.Context, sessionKey string, url *url.URL, thumbprint string, feature Feature) (*govmomi.Client, error) {
+func newClient(ctx context.Context, sessionKey string, url *url.URL, thumbprint string, caBundle []byte, feature Feature) (*govmomi.Client, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	insecure := thumbprint == ""
+	insecure := thumbprint == "" && len(caBundle) == 0
 	soapClient := soap.NewClient(url, insecure)
-	if !insecure {
+	if thumbprint != "" {
 		soapClient.SetThumbprint(url.Host, thumbprint)
 	}
+	if len(caBundle) > 0 {
+		pool, err := ParseCABundle(caBundle)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create client")
+		}
+		soapClient.DefaultTransport().TLSClientConfig.RootCAs = pool
+	}
 
 	vimClient, err := vim25.NewClient(ctx, soapClient)
 	if err != nil {
+		if soap.IsCertificateUntrusted(err) {
+			return nil, errors.Wrapf(err, "failed to create client: certificate of %s is neither signed by a CA of the CA bundle nor matches the thumbprint", url.Host)
+		}
 		return nil, errors.Wrapf(err, "failed to create client")
 	}
 	vimClient.UserAgent = "k8s-capv-useragent"
+	vimClient.RoundTripper = tracing.RoundTripper(vimClient.RoundTripper)
 
 	c := &govmomi.Client{
 		Client:         vimClient,
@@ -19,13 +19,21 @@ package session
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/simulator"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -172,3 +180,156 @@ func TestGetSessionWithKeepAlive(t *testing.T) {
 	g.Expect(sessionInfo.Key).ToNot(BeEquivalentTo(firstSession))
 	assertSessionCountEqualTo(g, simr, 1)
 }
+
+func TestGetSessionWithCABundle(t *testing.T) {
+	ctrl.SetLogger(klog.Background())
+
+	simr, err := vcsim.NewBuilder().
+		WithModel(simulator.VPX()).Build()
+	if err != nil {
+		t.Fatalf("failed to create VC simulator")
+	}
+	defer simr.Destroy()
+
+	params := func() *Params {
+		return NewParams().
+			WithServer(simr.ServerURL().Host).
+			WithUserInfo(simr.Username(), simr.Password()).
+			WithDatacenter("*")
+	}
+
+	t.Run("the session is created if the CA bundle contains the CA of the server", func(t *testing.T) {
+		g := NewWithT(t)
+		caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: simr.Certificate().Raw})
+
+		s, err := GetOrCreate(context.Background(), params().WithCABundle(caBundle))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(s).ToNot(BeNil())
+	})
+
+	t.Run("the session is not created if the CA bundle does not contain the CA of the server", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := GetOrCreate(context.Background(), params().WithCABundle(newCABundle(g)))
+		g.Expect(err).To(MatchError(ContainSubstring("is neither signed by a CA of the CA bundle nor matches the thumbprint")))
+	})
+
+	t.Run("the session is created if the thumbprint matches the certificate of the server", func(t *testing.T) {
+		g := NewWithT(t)
+		info := &object.HostCertificateInfo{}
+		info.FromCertificate(simr.Certificate())
+		s, err := GetOrCreate(context.Background(), params().WithCABundle(newCABundle(g)).WithThumbprint(info.ThumbprintSHA1))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(s).ToNot(BeNil())
+	})
+
+	t.Run("the session is not created if the CA bundle is invalid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := GetOrCreate(context.Background(), params().WithCABundle([]byte("invalid")))
+		g.Expect(err).To(MatchError(ContainSubstring("CA bundle contains no valid PEM encoded certificate")))
+	})
+}
+
+func TestParseCABundle(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := ParseCABundle(newCABundle(g))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = ParseCABundle(nil)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = ParseCABundle([]byte("-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----\n"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+// newCABundle returns a PEM encoded bundle with a new self-signed CA certificate.
+func newCABundle(g *WithT) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).ToNot(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+}
+
+func TestInventoryCache(t *testing.T) {
+	g := NewWithT(t)
+	ctrl.SetLogger(klog.Background())
+
+	simr, err := vcsim.NewBuilder().Build()
+	if err != nil {
+		t.Fatalf("failed to create VC simulator")
+	}
+	defer simr.Destroy()
+
+	params := NewParams().
+		WithServer(simr.ServerURL().Host).
+		WithUserInfo(simr.Username(), simr.Password()).WithDatacenter("*")
+
+	ctx := context.Background()
+	s, err := GetOrCreate(ctx, params)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	SetInventoryCacheTTL(time.Minute)
+	defer SetInventoryCacheTTL(0)
+
+	folder, err := s.FolderOrDefault(ctx, "/DC0/vm/capv")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(folder).To(BeNil())
+
+	vmFolder, err := s.FolderOrDefault(ctx, "/DC0/vm")
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = vmFolder.CreateFolder(ctx, "capv")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Objects which were not found are not cached.
+	folder, err = s.FolderOrDefault(ctx, "/DC0/vm/capv")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(folder.InventoryPath).To(Equal("/DC0/vm/capv"))
+
+	// Cached objects are served without looking them up again.
+	task, err := folder.Rename(ctx, "capv-renamed")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(task.Wait(ctx)).To(Succeed())
+	cached, err := s.FolderOrDefault(ctx, "/DC0/vm/capv")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cached.Reference()).To(Equal(folder.Reference()))
+
+	// Invalidated objects are looked up again.
+	s.InvalidateInventoryObject(folder.Reference())
+	_, err = s.FolderOrDefault(ctx, "/DC0/vm/capv")
+	g.Expect(err).To(HaveOccurred())
+
+	// The datacenter of new sessions is served from the cache, too.
+	Clear()
+	s, err = GetOrCreate(ctx, params)
+	g.Expect(err).ToNot(HaveOccurred())
+	dc, err := s.Finder.Datacenter(ctx, "DC0")
+	g.Expect(err).ToNot(HaveOccurred())
+	task, err = dc.Rename(ctx, "DC0-renamed")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(task.Wait(ctx)).To(Succeed())
+	Clear()
+	s, err = GetOrCreate(ctx, params)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(s.datacenter.Reference()).To(Equal(dc.Reference()))
+
+	// Objects are cached per user.
+	inventoryCache.RLock()
+	defer inventoryCache.RUnlock()
+	for key := range inventoryCache.entries {
+		g.Expect(key.user).To(Equal(s.user))
+	}
+	g.Expect(inventoryCache.entries).To(HaveKey(inventoryCacheKey{server: s.server(), user: s.user, kind: inventoryKindDatacenter, path: "*"}))
+}
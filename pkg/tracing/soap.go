@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// roundTripper creates a span around each call of the vSphere API.
+type roundTripper struct {
+	soap.RoundTripper
+}
+
+// RoundTripper returns a RoundTripper which creates a span around each call of the
+// vSphere API made through the given RoundTripper. The spans are named after the
+// method and have the managed object reference of the object the method is called
+// on, and of the task the method started, as attributes.
+func RoundTripper(rt soap.RoundTripper) soap.RoundTripper {
+	return &roundTripper{RoundTripper: rt}
+}
+
+func (rt *roundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	ctx, span := Tracer().Start(ctx, "vsphere."+methodName(req), trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	if ref, ok := managedObjectReference(req, "Req", "This"); ok {
+		if ref.Type == "VirtualMachine" {
+			span.SetAttributes(VMRefKey.String(ref.Value))
+		} else {
+			span.SetAttributes(ObjectRefKey.String(ref.String()))
+		}
+	}
+
+	if err := rt.RoundTripper.RoundTrip(ctx, req, res); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if ref, ok := managedObjectReference(res, "Res", "Returnval"); ok && ref.Type == "Task" {
+		span.SetAttributes(TaskRefKey.String(ref.Value))
+	}
+	return nil
+}
+
+// methodName returns the name of the vSphere API method of the request body,
+// e.g. CloneVM_Task for methods.CloneVM_TaskBody.
+func methodName(req soap.HasFault) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Body")
+}
+
+// managedObjectReference returns the managed object reference found by following
+// the fields with the given names, if any.
+func managedObjectReference(v interface{}, fieldNames ...string) (types.ManagedObjectReference, bool) {
+	rv := reflect.ValueOf(v)
+	for _, name := range fieldNames {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return types.ManagedObjectReference{}, false
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return types.ManagedObjectReference{}, false
+		}
+		rv = rv.FieldByName(name)
+		if !rv.IsValid() {
+			return types.ManagedObjectReference{}, false
+		}
+	}
+	ref, ok := rv.Interface().(types.ManagedObjectReference)
+	return ref, ok && ref.Value != ""
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRoundTripper(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	simulator.Run(func(ctx context.Context, c *vim25.Client) error {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		c.RoundTripper = RoundTripper(c.RoundTripper)
+		task, err := vm.PowerOff(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var attributes map[attribute.Key]string
+		for _, span := range recorder.Ended() {
+			if span.Name() != "vsphere.PowerOffVM_Task" {
+				continue
+			}
+			attributes = map[attribute.Key]string{}
+			for _, kv := range span.Attributes() {
+				attributes[kv.Key] = kv.Value.AsString()
+			}
+		}
+		g.Expect(attributes).To(HaveKeyWithValue(VMRefKey, vm.Reference().Value))
+		g.Expect(attributes).To(HaveKeyWithValue(TaskRefKey, task.Reference().Value))
+		return nil
+	})
+}
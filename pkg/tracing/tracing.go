@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing contains tools to export OpenTelemetry traces of reconciles and vCenter calls.
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/version"
+)
+
+const tracerName = "sigs.k8s.io/cluster-api-provider-vsphere"
+
+const (
+	// VMRefKey is the span attribute of the managed object reference of a VM.
+	VMRefKey = attribute.Key("vsphere.vm.moref")
+
+	// TaskRefKey is the span attribute of the managed object reference of a task.
+	TaskRefKey = attribute.Key("vsphere.task.id")
+
+	// ObjectRefKey is the span attribute of the managed object reference of any
+	// other object a vCenter call is made on.
+	ObjectRefKey = attribute.Key("vsphere.moref")
+)
+
+// Options are the options of the export of traces.
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector. Traces are only
+	// exported if it is set.
+	Endpoint string
+
+	// Insecure disables TLS for the connection to the collector.
+	Insecure bool
+}
+
+// Setup registers a global tracer provider which exports the traces to the OTLP collector
+// of the options and returns a function which flushes and stops the export.
+// Nothing is exported if no collector endpoint is configured.
+func Setup(ctx context.Context, opts Options, serviceName string) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP trace exporter")
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version.Get().String()),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create tracing resource")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of CAPV. It does not record spans unless Setup
+// registered a tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
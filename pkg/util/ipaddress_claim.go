@@ -23,3 +23,9 @@ import "fmt"
 func IPAddressClaimName(vmName string, deviceIndex, poolIndex int) string {
 	return fmt.Sprintf("%s-%d-%d", vmName, deviceIndex, poolIndex)
 }
+
+// ControlPlaneEndpointIPAddressClaimName returns the name of the IPAddressClaim
+// of the control plane endpoint of a VSphereCluster.
+func ControlPlaneEndpointIPAddressClaimName(vsphereClusterName string) string {
+	return fmt.Sprintf("%s-control-plane-endpoint", vsphereClusterName)
+}
@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -87,6 +90,65 @@ func IsControlPlaneMachine(machine metav1.Object) bool {
 	return ok
 }
 
+// drainingHostDeleteValue is the value of the delete-machine annotation set on Machines whose
+// VM runs on a draining host, which tells it apart from the annotation set by users.
+const drainingHostDeleteValue = "draining-host"
+
+// GetDrainingHosts returns the names of the hosts listed by the DrainingHostsAnnotation of
+// the VSphereCluster.
+func GetDrainingHosts(vsphereCluster *infrav1.VSphereCluster) []string {
+	var hosts []string
+	for _, host := range strings.Split(vsphereCluster.GetAnnotations()[infrav1.DrainingHostsAnnotation], ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// SetDrainingHostDeleteAnnotation sets the delete-machine annotation on a worker Machine whose
+// VM runs on a host listed by the DrainingHostsAnnotation of the VSphereCluster, so it is
+// deleted first when its MachineSet scales down. The annotation is removed once the host is not
+// draining anymore, unless it was set by users. It returns true if the Machine was changed.
+func SetDrainingHostDeleteAnnotation(machine *clusterv1.Machine, vsphereCluster *infrav1.VSphereCluster, host string) bool {
+	if IsControlPlaneMachine(machine) {
+		return false
+	}
+	value, annotated := machine.GetAnnotations()[clusterv1.DeleteMachineAnnotation]
+	draining := host != "" && slices.Contains(GetDrainingHosts(vsphereCluster), host)
+	switch {
+	case draining && !annotated:
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[clusterv1.DeleteMachineAnnotation] = drainingHostDeleteValue
+		return true
+	case !draining && annotated && value == drainingHostDeleteValue:
+		delete(machine.Annotations, clusterv1.DeleteMachineAnnotation)
+		return true
+	default:
+		return false
+	}
+}
+
+// IsKeptPoweredOff returns true if the VM of the VSphereVM is not powered on, either because
+// its desired power state is poweredOff or because it is left powered off after clone.
+func IsKeptPoweredOff(vsphereVM *infrav1.VSphereVM) bool {
+	if vsphereVM.Spec.DesiredPowerState != "" {
+		return vsphereVM.Spec.DesiredPowerState == infrav1.VirtualMachinePowerStatePoweredOff
+	}
+	return !ptr.Deref(vsphereVM.Spec.PowerOnAfterClone, true)
+}
+
+// GetVMName returns the name of the VM of the VSphereVM in vCenter, which differs from the
+// name of the VSphereVM if the VM was cloned with a suffix to resolve a name conflict.
+func GetVMName(vsphereVM *infrav1.VSphereVM) string {
+	if vsphereVM.Status.VMName != "" {
+		return vsphereVM.Status.VMName
+	}
+	return vsphereVM.Name
+}
+
 // GetMachineMetadata the cloud-init metadata as a base-64 encoded
 // string for a given VSphereMachine.
 // IPAM state includes IP and Gateways that should be added to each device.
@@ -105,8 +167,14 @@ func GetMachineMetadata(hostname string, vsphereVM infrav1.VSphereVM, ipamState
 
 		if state, ok := ipamState[devices[i].MACAddr]; ok {
 			devices[i].IPAddrs = append(devices[i].IPAddrs, state.IPAddrs...)
-			devices[i].Gateway4 = state.Gateway4
-			devices[i].Gateway6 = state.Gateway6
+			// The gateways of the IPAddresses match those of the device spec, if both are
+			// set, so only the gateways missing from the device spec are taken from IPAM.
+			if state.Gateway4 != "" {
+				devices[i].Gateway4 = state.Gateway4
+			}
+			if state.Gateway6 != "" {
+				devices[i].Gateway6 = state.Gateway6
+			}
 		}
 
 		if waitForIPv4 && waitForIPv6 {
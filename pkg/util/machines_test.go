@@ -834,6 +834,49 @@ network:
       addresses:
       - "fe80::3/64"
       gateway6: "fe80::1"
+`,
+		},
+		{
+			name: "ipam state without gateway keeps the gateway of the device",
+			machine: &infrav1.VSphereVM{
+				Spec: infrav1.VSphereVMSpec{
+					VirtualMachineCloneSpec: infrav1.VirtualMachineCloneSpec{
+						Network: infrav1.NetworkSpec{
+							Devices: []infrav1.NetworkDeviceSpec{
+								{
+									NetworkName: "network1",
+									MACAddr:     "00:00:00:00:00",
+									Gateway4:    "10.10.50.1",
+								},
+							},
+						},
+					},
+				},
+			},
+			ipamState: map[string]infrav1.NetworkDeviceSpec{
+				"00:00:00:00:00": {
+					IPAddrs: []string{
+						"10.10.50.50/24",
+					},
+				},
+			},
+			expected: `
+instance-id: "test-vm"
+local-hostname: "test-vm"
+wait-on-network:
+  ipv4: false
+  ipv6: false
+network:
+  version: 2
+  ethernets:
+    id0:
+      match:
+        macaddress: "00:00:00:00:00"
+      set-name: "eth0"
+      wakeonlan: true
+      addresses:
+      - "10.10.50.50/24"
+      gateway4: "10.10.50.1"
 `,
 		},
 		{
@@ -1043,6 +1086,66 @@ func Test_MachinesAsString(t *testing.T) {
 	}
 }
 
+func Test_SetDrainingHostDeleteAnnotation(t *testing.T) {
+	vsphereCluster := &infrav1.VSphereCluster{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{infrav1.DrainingHostsAnnotation: "esx-01.example.com, esx-02.example.com"},
+	}}
+	machine := func(labels, annotations map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m1", Labels: labels, Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name               string
+		machine            *clusterv1.Machine
+		host               string
+		expectedChanged    bool
+		expectedAnnotation bool
+	}{
+		{
+			name:               "worker on a draining host",
+			machine:            machine(nil, nil),
+			host:               "esx-02.example.com",
+			expectedChanged:    true,
+			expectedAnnotation: true,
+		},
+		{
+			name:    "worker on another host",
+			machine: machine(nil, nil),
+			host:    "esx-03.example.com",
+		},
+		{
+			name:    "worker without host",
+			machine: machine(nil, nil),
+		},
+		{
+			name:    "control plane machine on a draining host",
+			machine: machine(map[string]string{clusterv1.MachineControlPlaneLabel: ""}, nil),
+			host:    "esx-01.example.com",
+		},
+		{
+			name:               "worker annotated for a host which is not draining anymore",
+			machine:            machine(nil, map[string]string{clusterv1.DeleteMachineAnnotation: "draining-host"}),
+			host:               "esx-03.example.com",
+			expectedChanged:    true,
+			expectedAnnotation: false,
+		},
+		{
+			name:               "worker annotated by users",
+			machine:            machine(nil, map[string]string{clusterv1.DeleteMachineAnnotation: "true"}),
+			host:               "esx-03.example.com",
+			expectedAnnotation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(util.SetDrainingHostDeleteAnnotation(tt.machine, vsphereCluster, tt.host)).To(gomega.Equal(tt.expectedChanged))
+			_, annotated := tt.machine.Annotations[clusterv1.DeleteMachineAnnotation]
+			g.Expect(annotated).To(gomega.Equal(tt.expectedAnnotation))
+		})
+	}
+}
+
 func Test_GetVSphereClusterFromVSphereMachine(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = clientgoscheme.AddToScheme(scheme)
@@ -30,6 +30,7 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cluster-api/test/framework"
 	. "sigs.k8s.io/cluster-api/test/framework/ginkgoextensions"
+	"sigs.k8s.io/cluster-api/test/framework/kubernetesversions"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
@@ -38,9 +39,17 @@ import (
 	vcsimv1 "sigs.k8s.io/cluster-api-provider-vsphere/test/infrastructure/vcsim/api/v1alpha1"
 )
 
+const (
+	kubernetesVersionUpgradeFromVariable = "KUBERNETES_VERSION_UPGRADE_FROM"
+	kubernetesVersionUpgradeToVariable   = "KUBERNETES_VERSION_UPGRADE_TO"
+)
+
 type setupOptions struct {
 	additionalIPVariableNames []string
 	gatewayIPVariableName     string
+	kubernetesVersionFrom     string
+	kubernetesVersionTo       string
+	postNamespaceCreatedFuncs []func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)
 }
 
 // SetupOption is a configuration option supplied to Setup.
@@ -61,6 +70,33 @@ func WithGateway(variableName string) SetupOption {
 	}
 }
 
+// WithKubernetesVersionFrom instructs Setup to set KUBERNETES_VERSION_UPGRADE_FROM to the given version.
+// NOTE: The version must be the value of one of the KUBERNETES_VERSION* variables of the e2e config, e.g. v1.28.0
+// or ci/latest-1.30; versions like the latter are resolved.
+func WithKubernetesVersionFrom(version string) SetupOption {
+	return func(o *setupOptions) {
+		o.kubernetesVersionFrom = version
+	}
+}
+
+// WithKubernetesVersionTo instructs Setup to set KUBERNETES_VERSION_UPGRADE_TO to the given version.
+// NOTE: The version must be the value of one of the KUBERNETES_VERSION* variables of the e2e config, e.g. v1.29.0
+// or ci/latest-1.30; versions like the latter are resolved.
+func WithKubernetesVersionTo(version string) SetupOption {
+	return func(o *setupOptions) {
+		o.kubernetesVersionTo = version
+	}
+}
+
+// WithPostNamespaceCreated instructs Setup to run the provided funcs after the namespace of the workload
+// cluster is created, e.g. to create secrets required by the test.
+// NOTE: The funcs are run in order, after the funcs Setup runs for the test mode, if any.
+func WithPostNamespaceCreated(funcs ...func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)) SetupOption {
+	return func(o *setupOptions) {
+		o.postNamespaceCreatedFuncs = append(o.postNamespaceCreatedFuncs, funcs...)
+	}
+}
+
 type testSettings struct {
 	ClusterctlConfigPath     string
 	PostNamespaceCreatedFunc func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)
@@ -79,6 +115,7 @@ func Setup(specName string, f func(testSpecificSettings func() testSettings), op
 		testSpecificIPAddressClaims      vsphereip.AddressClaims
 		testSpecificVariables            map[string]string
 		postNamespaceCreatedFunc         func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)
+		originalEnvVariables             map[string]*string
 	)
 	BeforeEach(func() {
 		Byf("Setting up test env for %s", specName)
@@ -145,8 +182,9 @@ func Setup(specName string, f func(testSpecificSettings func() testSettings), op
 			}
 		}
 
+		var postNamespaceCreatedFuncs []func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)
 		if testMode == SupervisorTestMode {
-			postNamespaceCreatedFunc = setupNamespaceWithVMOperatorDependencies
+			postNamespaceCreatedFuncs = append(postNamespaceCreatedFuncs, setupNamespaceWithVMOperatorDependencies)
 
 			// Update the CLUSTER_CLASS_NAME variable adding the supervisor suffix.
 			if e2eConfig.HasVariable("CLUSTER_CLASS_NAME") {
@@ -154,6 +192,34 @@ func Setup(specName string, f func(testSpecificSettings func() testSettings), op
 			}
 		}
 
+		// Compose the funcs to run after the namespace is created with the ones requested for the test.
+		postNamespaceCreatedFunc = composePostNamespaceCreatedFuncs(append(postNamespaceCreatedFuncs, options.postNamespaceCreatedFuncs...))
+
+		// Set the Kubernetes versions requested for the test.
+		originalEnvVariables = map[string]*string{}
+		for _, v := range []struct{ variable, version string }{
+			{variable: kubernetesVersionUpgradeFromVariable, version: options.kubernetesVersionFrom},
+			{variable: kubernetesVersionUpgradeToVariable, version: options.kubernetesVersionTo},
+		} {
+			if v.version == "" {
+				continue
+			}
+			version := resolveKubernetesVersion(v.version)
+			Byf("Setting test variable %s to %s", v.variable, version)
+			if testSpecificVariables == nil {
+				testSpecificVariables = map[string]string{}
+			}
+			testSpecificVariables[v.variable] = version
+
+			// Specs read the versions via e2eConfig.GetVariable, which gives precedence to environment variables;
+			// the environment variables are restored after the test.
+			originalEnvVariables[v.variable] = nil
+			if original, ok := os.LookupEnv(v.variable); ok {
+				originalEnvVariables[v.variable] = &original
+			}
+			Expect(os.Setenv(v.variable, version)).To(Succeed())
+		}
+
 		// Create a new clusterctl config file based on the passed file and add the new variables for the IPs.
 		testSpecificClusterctlConfigPath = fmt.Sprintf("%s-%s.yaml", strings.TrimSuffix(clusterctlConfigPath, ".yaml"), specName)
 		Byf("Writing a new clusterctl config to %s", testSpecificClusterctlConfigPath)
@@ -173,6 +239,15 @@ func Setup(specName string, f func(testSpecificSettings func() testSettings), op
 			// cleanup IPs/controlPlaneEndpoint created by the vcsim controller manager.
 			Expect(vcsimAddressManager.Cleanup(ctx, testSpecificIPAddressClaims)).To(Succeed())
 		}
+
+		// Restore the environment variables for the Kubernetes versions requested for the test.
+		for variable, original := range originalEnvVariables {
+			if original == nil {
+				Expect(os.Unsetenv(variable)).To(Succeed())
+				continue
+			}
+			Expect(os.Setenv(variable, *original)).To(Succeed())
+		}
 	})
 
 	// NOTE: it is required to use a function to pass the testSpecificClusterctlConfigPath value into the test func,
@@ -197,6 +272,18 @@ func Setup(specName string, f func(testSpecificSettings func() testSettings), op
 	})
 }
 
+// composePostNamespaceCreatedFuncs returns a func running the given funcs in order, or nil if there are none.
+func composePostNamespaceCreatedFuncs(funcs []func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string)) func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string) {
+	if len(funcs) == 0 {
+		return nil
+	}
+	return func(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string) {
+		for _, f := range funcs {
+			f(managementClusterProxy, workloadClusterNamespace)
+		}
+	}
+}
+
 func setupNamespaceWithVMOperatorDependencies(managementClusterProxy framework.ClusterProxy, workloadClusterNamespace string) {
 	c := managementClusterProxy.GetClient()
 
@@ -214,6 +301,8 @@ func setupNamespaceWithVMOperatorDependencies(managementClusterProxy framework.C
 				Namespace: vCenterSimulator.Namespace,
 				Name:      vCenterSimulator.Name,
 			},
+			// When targeting vcsim, the vm-operator lifecycle of VirtualMachines is simulated by the vcsim controller.
+			SimulateVMOperator: testTarget == VCSimTestTarget,
 		},
 	}
 	err = c.Create(ctx, dependenciesConfig)
@@ -227,6 +316,22 @@ func setupNamespaceWithVMOperatorDependencies(managementClusterProxy framework.C
 	}, 30*time.Second, 5*time.Second).Should(BeTrue(), "Failed to get VMOperatorDependencies on namespace %s", workloadClusterNamespace)
 }
 
+// resolveKubernetesVersion checks that version is one of the Kubernetes versions of the e2e config, i.e. the value
+// of a KUBERNETES_VERSION* variable, and resolves it if it is defined like stable-1.29 or ci/latest-1.30.
+func resolveKubernetesVersion(version string) string {
+	versions := sets.New[string]()
+	for name := range e2eConfig.Variables {
+		if strings.HasPrefix(name, "KUBERNETES_VERSION") {
+			versions.Insert(e2eConfig.GetVariable(name))
+		}
+	}
+	Expect(versions.Has(version)).To(BeTrue(), "Kubernetes version %s is not one of the Kubernetes versions of the e2e config: %s", version, strings.Join(sets.List(versions), ", "))
+
+	resolvedVersion, err := kubernetesversions.ResolveVersion(ctx, version)
+	Expect(err).ToNot(HaveOccurred(), "Failed to resolve Kubernetes version %s", version)
+	return resolvedVersion
+}
+
 // Note: Copy-paste from CAPI below.
 
 // copyAndAmendClusterctlConfigInput is the input for copyAndAmendClusterctlConfig.
@@ -119,6 +119,16 @@ var (
 	// IPAM provider to claim IPs for the control plane IPs of created clusters.
 	e2eIPAMKubeconfig string
 
+	// e2eIPAMServer is the address of the kube-apiserver of a remote cluster which provides IP address management
+	// via an in-cluster IPAM provider. If set, it is used instead of e2eIPAMKubeconfig.
+	e2eIPAMServer string
+
+	// e2eIPAMTokenFile is the path to a file containing the bearer token to authenticate to e2eIPAMServer.
+	e2eIPAMTokenFile string
+
+	// e2eIPAMCAFile is the path to the CA bundle to verify e2eIPAMServer.
+	e2eIPAMCAFile string
+
 	// inClusterAddressManager is used to claim and cleanup IP addresses used for kubernetes control plane API Servers.
 	inClusterAddressManager vsphereip.AddressManager
 
@@ -134,6 +144,9 @@ func init() {
 	flag.BoolVar(&skipCleanup, "e2e.skip-resource-cleanup", false, "if true, the resource cleanup after tests will be skipped")
 	flag.BoolVar(&useExistingCluster, "e2e.use-existing-cluster", false, "if true, the test uses the current cluster instead of creating a new one (default discovery rules apply)")
 	flag.StringVar(&e2eIPAMKubeconfig, "e2e.ipam-kubeconfig", "", "path to the kubeconfig for the IPAM cluster")
+	flag.StringVar(&e2eIPAMServer, "e2e.ipam-server", "", "address of the kube-apiserver of a remote IPAM cluster, takes precedence over e2e.ipam-kubeconfig")
+	flag.StringVar(&e2eIPAMTokenFile, "e2e.ipam-token-file", "", "path to the file containing the bearer token for the remote IPAM cluster")
+	flag.StringVar(&e2eIPAMCAFile, "e2e.ipam-ca-file", "", "path to the CA bundle for the remote IPAM cluster")
 }
 
 func TestE2E(t *testing.T) {
@@ -277,6 +290,12 @@ var _ = SynchronizedBeforeSuite(func() []byte {
 	// Setup the in cluster address manager
 	switch testTarget {
 	case VCenterTestTarget:
+		if e2eIPAMServer != "" {
+			// Create the address manager for the remote IPAM cluster
+			inClusterAddressManager, err = vsphereip.RemoteAddressManager(e2eIPAMServer, e2eIPAMTokenFile, e2eIPAMCAFile, ipClaimLabels, skipCleanup)
+			Expect(err).ToNot(HaveOccurred())
+			break
+		}
 		// Create the in cluster address manager
 		inClusterAddressManager, err = vsphereip.InClusterAddressManager(e2eIPAMKubeconfig, ipClaimLabels, skipCleanup)
 		Expect(err).ToNot(HaveOccurred())
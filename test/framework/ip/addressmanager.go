@@ -19,6 +19,7 @@ package ip
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vmware/govmomi"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -87,6 +88,23 @@ func VSphereClient(c *govmomi.Client) TearDownOption {
 	}
 }
 
+// PoolUtilization is the utilization of the IP pool an AddressManager claims IP addresses from.
+type PoolUtilization struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"`
+}
+
+func (u PoolUtilization) String() string {
+	return fmt.Sprintf("%d used, %d free of %d addresses", u.Used, u.Free, u.Total)
+}
+
+// PoolUtilizationReporter is implemented by AddressManagers which can report the utilization of their IP pool.
+type PoolUtilizationReporter interface {
+	// PoolUtilization returns the current utilization of the IP pool.
+	PoolUtilization(ctx context.Context) (*PoolUtilization, error)
+}
+
 type AddressManager interface {
 	// ClaimIPs claims IP addresses with the variable name `CONTROL_PLANE_ENDPOINT_IP` and whatever is passed as
 	// additionalIPVariableNames.
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -30,7 +31,9 @@ import (
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/vim25/mo"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -49,6 +52,18 @@ func init() {
 }
 
 var _ AddressManager = &inCluster{}
+var _ PoolUtilizationReporter = &inCluster{}
+
+const (
+	// ipPoolName is the name of the InClusterIPPool IP addresses are claimed from.
+	ipPoolName = "capv-e2e-ippool"
+
+	// ipPoolAPIVersion is the API version of the InClusterIPPool used to read its status.
+	ipPoolAPIVersion = "ipam.cluster.x-k8s.io/v1alpha2"
+
+	// ipPoolKind is the kind of the IP pool IP addresses are claimed from.
+	ipPoolKind = "InClusterIPPool"
+)
 
 type inCluster struct {
 	client      client.Client
@@ -89,14 +104,25 @@ func (h *inCluster) ClaimIPs(ctx context.Context, opts ...ClaimOption) (AddressC
 	ipAddressClaims := AddressClaims{}
 
 	// Claim an IP per variable.
-	for _, variable := range append(options.additionalIPVariableNames, ControlPlaneEndpointIPVariable) {
-		ip, ipAddressClaim, err := h.claimIPAddress(ctx)
-		Expect(err).ToNot(HaveOccurred())
+	variableNames := append(options.additionalIPVariableNames, ControlPlaneEndpointIPVariable)
+	Byf("Creating %d IPAddressClaims", len(variableNames))
+	ips, claims, err := h.claimIPAddresses(ctx, len(variableNames))
+
+	// Report the utilization of the IP pool, so pool pressure is visible in the test report.
+	utilization, utilizationErr := h.PoolUtilization(ctx)
+	if utilizationErr != nil {
+		Byf("Failed to get the utilization of IP pool %s: %v", ipPoolName, utilizationErr)
+	} else {
+		AddReportEntry(fmt.Sprintf("IP pool %s utilization", ipPoolName), utilization)
+	}
+	Expect(annotateClaimError(err, len(variableNames), utilization)).ToNot(HaveOccurred())
+	for i, variable := range variableNames {
+		ip, ipAddressClaim := ips[i], claims[i]
 		ipAddressClaims = append(ipAddressClaims, AddressClaim{
 			Namespace: ipAddressClaim.Namespace,
 			Name:      ipAddressClaim.Name,
 		})
-		Byf("Setting clusterctl variable %s to %s", variable, ip.Spec.Address)
+		Byf("Setting clusterctl variable %s to %s from IPAddressClaim %s", variable, ip.Spec.Address, klog.KObj(ipAddressClaim))
 		variables[variable] = ip.Spec.Address
 		if variable == ControlPlaneEndpointIPVariable && options.gatewayIPVariableName != "" {
 			// Set the gateway variable if requested to the gateway of the control plane IP.
@@ -110,6 +136,42 @@ func (h *inCluster) ClaimIPs(ctx context.Context, opts ...ClaimOption) (AddressC
 	return ipAddressClaims, variables
 }
 
+// PoolUtilization returns the utilization of the InClusterIPPool IP addresses are claimed from, as reported
+// in its status by the in-cluster IPAM provider.
+func (h *inCluster) PoolUtilization(ctx context.Context) (*PoolUtilization, error) {
+	pool := &unstructured.Unstructured{}
+	pool.SetAPIVersion(ipPoolAPIVersion)
+	pool.SetKind(ipPoolKind)
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: ipPoolName}, pool); err != nil {
+		return nil, errors.Wrapf(err, "getting %s %s", ipPoolKind, ipPoolName)
+	}
+
+	utilization := &PoolUtilization{}
+	for field, value := range map[string]*int64{"total": &utilization.Total, "used": &utilization.Used, "free": &utilization.Free} {
+		v, found, err := unstructured.NestedInt64(pool.Object, "status", "ipAddresses", field)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading status.ipAddresses.%s of %s %s", field, ipPoolKind, ipPoolName)
+		}
+		if !found {
+			return nil, errors.Errorf("status.ipAddresses.%s of %s %s is not set", field, ipPoolKind, ipPoolName)
+		}
+		*value = v
+	}
+	return utilization, nil
+}
+
+// annotateClaimError adds the utilization of the IP pool to an error claiming count IP addresses, so it
+// is obvious when claiming failed because the IP pool is exhausted.
+func annotateClaimError(err error, count int, utilization *PoolUtilization) error {
+	if err == nil || utilization == nil {
+		return err
+	}
+	if utilization.Free < int64(count) {
+		return errors.Wrapf(err, "IP pool %s is exhausted, %d IP addresses requested but %s", ipPoolName, count, utilization)
+	}
+	return errors.Wrapf(err, "IP pool %s has %s", ipPoolName, utilization)
+}
+
 // Cleanup deletes the IPAddressClaims passed.
 func (h *inCluster) Cleanup(ctx context.Context, ipAddressClaims AddressClaims) error {
 	if CurrentSpecReport().Failed() {
@@ -282,6 +344,40 @@ func getVirtualMachineIPAddresses(ctx context.Context, folderName string, vSpher
 	return virtualMachineIPAddresses, nil
 }
 
+// claimIPAddresses claims count IP addresses concurrently and waits for all of them to be bound, so the
+// time to claim many IP addresses is about the time to claim one.
+// If any of the IP addresses cannot be claimed, the IPAddressClaims which are bound are deleted, so no
+// IPAddressClaim is leaked when the claims are not returned to the caller.
+func (h *inCluster) claimIPAddresses(ctx context.Context, count int) ([]*ipamv1.IPAddress, []*ipamv1.IPAddressClaim, error) {
+	ips := make([]*ipamv1.IPAddress, count)
+	claims := make([]*ipamv1.IPAddressClaim, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ips[i], claims[i], errs[i] = h.claimIPAddress(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := kerrors.NewAggregate(errs); err != nil {
+		errList := []error{err}
+		for _, claim := range claims {
+			if claim == nil {
+				continue
+			}
+			if err := h.client.Delete(ctx, claim); err != nil && !apierrors.IsNotFound(err) {
+				errList = append(errList, errors.Wrapf(err, "deleting IPAddressClaim %s", klog.KObj(claim)))
+			}
+		}
+		return nil, nil, kerrors.NewAggregate(errList)
+	}
+	return ips, claims, nil
+}
+
 func (h *inCluster) claimIPAddress(ctx context.Context) (_ *ipamv1.IPAddress, _ *ipamv1.IPAddressClaim, err error) {
 	claim := &ipamv1.IPAddressClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -293,8 +389,8 @@ func (h *inCluster) claimIPAddress(ctx context.Context) (_ *ipamv1.IPAddress, _
 		Spec: ipamv1.IPAddressClaimSpec{
 			PoolRef: corev1.TypedLocalObjectReference{
 				APIGroup: ptr.To("ipam.cluster.x-k8s.io"),
-				Kind:     "InClusterIPPool",
-				Name:     "capv-e2e-ippool",
+				Kind:     ipPoolKind,
+				Name:     ipPoolName,
 			},
 		},
 	}
@@ -304,7 +400,6 @@ func (h *inCluster) claimIPAddress(ctx context.Context) (_ *ipamv1.IPAddress, _
 	}
 
 	// Create an IPAddressClaim
-	Byf("Creating IPAddressClaim %s", klog.KObj(claim))
 	if err := h.client.Create(ctx, claim); err != nil {
 		return nil, nil, err
 	}
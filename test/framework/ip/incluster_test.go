@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ip
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newFakeIPAMClient returns a client which binds every IPAddressClaim to a new IPAddress on creation,
+// like the in-cluster IPAM provider does, except for the failCreate-th IPAddressClaim which fails to be created.
+func newFakeIPAMClient(failCreate int32) client.Client {
+	var created atomic.Int32
+	return fake.NewClientBuilder().WithScheme(ipamScheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			claim, ok := obj.(*ipamv1.IPAddressClaim)
+			if !ok {
+				return c.Create(ctx, obj, opts...)
+			}
+			n := created.Add(1)
+			if n == failCreate {
+				return errors.New("injected error")
+			}
+			ip := &ipamv1.IPAddress{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: claim.Namespace,
+					Name:      claim.Name,
+				},
+				Spec: ipamv1.IPAddressSpec{
+					Address: fmt.Sprintf("10.0.0.%d", n),
+					Gateway: "10.0.0.254",
+				},
+			}
+			if err := c.Create(ctx, ip); err != nil {
+				return err
+			}
+			claim.Status.AddressRef.Name = ip.Name
+			return c.Create(ctx, claim, opts...)
+		},
+	}).Build()
+}
+
+func Test_inCluster_claimIPAddresses(t *testing.T) {
+	t.Run("claims all the IP addresses", func(t *testing.T) {
+		g := NewWithT(t)
+
+		h := &inCluster{
+			client: newFakeIPAMClient(0),
+			labels: map[string]string{"capv-testing/random-uid": "foo"},
+		}
+
+		ips, claims, err := h.claimIPAddresses(context.Background(), 3)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ips).To(HaveLen(3))
+		g.Expect(claims).To(HaveLen(3))
+		addresses := map[string]bool{}
+		for i := range ips {
+			g.Expect(ips[i].Name).To(Equal(claims[i].Status.AddressRef.Name))
+			addresses[ips[i].Spec.Address] = true
+		}
+		g.Expect(addresses).To(HaveLen(3))
+	})
+
+	t.Run("releases the claimed IP addresses if any claim fails", func(t *testing.T) {
+		g := NewWithT(t)
+
+		h := &inCluster{
+			client: newFakeIPAMClient(2),
+			labels: map[string]string{"capv-testing/random-uid": "foo"},
+		}
+
+		ips, claims, err := h.claimIPAddresses(context.Background(), 3)
+		g.Expect(err).To(MatchError(ContainSubstring("injected error")))
+		g.Expect(ips).To(BeNil())
+		g.Expect(claims).To(BeNil())
+
+		ipAddressClaims := &ipamv1.IPAddressClaimList{}
+		g.Expect(h.client.List(context.Background(), ipAddressClaims)).To(Succeed())
+		g.Expect(ipAddressClaims.Items).To(BeEmpty())
+	})
+}
+
+func Test_inCluster_PoolUtilization(t *testing.T) {
+	g := NewWithT(t)
+
+	pool := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"ipAddresses": map[string]interface{}{
+				"total": int64(10),
+				"used":  int64(8),
+				"free":  int64(2),
+			},
+		},
+	}}
+	pool.SetAPIVersion(ipPoolAPIVersion)
+	pool.SetKind(ipPoolKind)
+	pool.SetNamespace(metav1.NamespaceDefault)
+	pool.SetName(ipPoolName)
+
+	h := &inCluster{
+		client: fake.NewClientBuilder().WithScheme(ipamScheme).WithObjects(pool).Build(),
+	}
+
+	utilization, err := h.PoolUtilization(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(utilization).To(Equal(&PoolUtilization{Total: 10, Used: 8, Free: 2}))
+}
+
+func Test_annotateClaimError(t *testing.T) {
+	err := errors.New("IPAddressClaim.Status.AddressRef.Name is not set")
+
+	tests := []struct {
+		name        string
+		err         error
+		utilization *PoolUtilization
+		wantErr     string
+	}{
+		{
+			name:        "no error",
+			utilization: &PoolUtilization{Total: 10, Used: 10},
+		},
+		{
+			name:    "utilization unknown",
+			err:     err,
+			wantErr: "IPAddressClaim.Status.AddressRef.Name is not set",
+		},
+		{
+			name:        "pool exhausted",
+			err:         err,
+			utilization: &PoolUtilization{Total: 10, Used: 9, Free: 1},
+			wantErr:     "IP pool capv-e2e-ippool is exhausted, 2 IP addresses requested but 9 used, 1 free of 10 addresses: IPAddressClaim.Status.AddressRef.Name is not set",
+		},
+		{
+			name:        "pool not exhausted",
+			err:         err,
+			utilization: &PoolUtilization{Total: 10, Used: 2, Free: 8},
+			wantErr:     "IP pool capv-e2e-ippool has 2 used, 8 free of 10 addresses: IPAddressClaim.Status.AddressRef.Name is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := annotateClaimError(tt.err, 2, tt.utilization)
+			if tt.wantErr == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ip
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemoteAddressManager returns an ip.AddressManager implementation that leverage on the IPAM provider installed into a
+// remote cluster, which is reached at e2eIPAMServer and authenticates using the bearer token in e2eIPAMTokenFile.
+// The token file is read on every request, so it can be rotated while the tests are running.
+// If e2eIPAMCAFile is an empty string the system certificate pool is used to verify the remote cluster.
+func RemoteAddressManager(e2eIPAMServer, e2eIPAMTokenFile, e2eIPAMCAFile string, labels map[string]string, skipCleanup bool) (AddressManager, error) {
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("expecting labels to be set to prevent deletion of other IPAddressClaims")
+	}
+
+	if e2eIPAMServer == "" {
+		return nil, fmt.Errorf("expecting the server of the remote IPAM cluster to be set")
+	}
+	if e2eIPAMTokenFile == "" {
+		return nil, fmt.Errorf("expecting a token file to authenticate to the remote IPAM cluster %s", e2eIPAMServer)
+	}
+
+	if _, err := os.Stat(e2eIPAMTokenFile); err != nil {
+		return nil, err
+	}
+
+	restConfig := &rest.Config{
+		Host:            e2eIPAMServer,
+		BearerTokenFile: filepath.Clean(e2eIPAMTokenFile),
+	}
+	if e2eIPAMCAFile != "" {
+		restConfig.TLSClientConfig.CAFile = filepath.Clean(e2eIPAMCAFile)
+	}
+
+	ipamClient, err := client.New(restConfig, client.Options{Scheme: ipamScheme})
+	if err != nil {
+		return nil, err
+	}
+
+	// The remote cluster runs the same in-cluster IPAM provider, so IP addresses are claimed
+	// and cleaned up the same way as with a kubeconfig.
+	return &inCluster{
+		labels:      labels,
+		client:      ipamClient,
+		skipCleanup: skipCleanup,
+	}, nil
+}
@@ -44,6 +44,12 @@ type VMOperatorDependenciesSpec struct {
 
 	// VirtualMachineClasses defines a list of VirtualMachineClasses to be bound to the namespace where this object is created.
 	VirtualMachineClasses []string `json:"virtualMachineClasses,omitempty"`
+
+	// SimulateVMOperator instructs the vcsim controller to simulate the vm-operator lifecycle of the VirtualMachines
+	// in the namespace where this object is created, i.e. to mark them as created and powered on, and to assign them
+	// a BIOS UUID and an IP, so supervisor mode can be tested without a running vm-operator.
+	// NOTE: simulated VirtualMachines are paused for vm-operator, if any.
+	SimulateVMOperator bool `json:"simulateVMOperator,omitempty"`
 }
 
 // VMOperatorRef provide a reference to the running instance of vm-operator.
@@ -113,7 +119,7 @@ type VMOperatorDependencies struct {
 type VMOperatorDependenciesList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
-	Items           []VCenterSimulator `json:"items"`
+	Items           []VMOperatorDependencies `json:"items"`
 }
 
 func init() {
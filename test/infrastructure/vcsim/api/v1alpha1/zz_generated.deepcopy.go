@@ -539,7 +539,7 @@ func (in *VMOperatorDependenciesList) DeepCopyInto(out *VMOperatorDependenciesLi
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VCenterSimulator, len(*in))
+		*out = make([]VMOperatorDependencies, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -22,6 +22,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	vmoprv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -62,6 +63,8 @@ type VirtualMachineReconciler struct {
 }
 
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vcsim.infrastructure.cluster.x-k8s.io,resources=vmoperatordependencies,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=vsphereclusters,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.infrastructure.cluster.x-k8s.io,resources=vspheremachines,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
@@ -200,7 +203,7 @@ func (r *VirtualMachineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Always attempt to Patch the VSphereVM + conditionsTracker object and status after each reconciliation.
 	defer func() {
-		// NOTE: Patch on VSphereVM will only add/remove a finalizer.
+		// NOTE: Patch on VirtualMachine will only add/remove a finalizer, unless the vm-operator lifecycle is simulated.
 		if err := patchHelper.Patch(ctx, virtualMachine); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
 		}
@@ -228,6 +231,14 @@ func (r *VirtualMachineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 }
 
 func (r *VirtualMachineReconciler) reconcileNormal(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, virtualMachine *vmoprv1.VirtualMachine, conditionsTracker *infrav1.VSphereVM) (ctrl.Result, error) {
+	simulateVMOperator, err := r.isVMOperatorSimulated(ctx, virtualMachine.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if simulateVMOperator {
+		r.reconcileSimulatedVMOperator(ctx, virtualMachine)
+	}
+
 	ipReconciler := r.getVMIpReconciler(cluster, virtualMachine)
 	if ret, err := ipReconciler.ReconcileIP(ctx); !ret.IsZero() || err != nil {
 		return ret, err
@@ -251,6 +262,58 @@ func (r *VirtualMachineReconciler) reconcileDelete(ctx context.Context, cluster
 	return ctrl.Result{}, nil
 }
 
+// isVMOperatorSimulated returns true if a VMOperatorDependencies in the given namespace
+// instructs to simulate the vm-operator lifecycle of VirtualMachines.
+func (r *VirtualMachineReconciler) isVMOperatorSimulated(ctx context.Context, namespace string) (bool, error) {
+	dependenciesList := &vcsimv1.VMOperatorDependenciesList{}
+	if err := r.Client.List(ctx, dependenciesList, client.InNamespace(namespace)); err != nil {
+		return false, errors.Wrapf(err, "failed to list VMOperatorDependencies in namespace %s", namespace)
+	}
+	for _, d := range dependenciesList.Items {
+		if d.Spec.SimulateVMOperator {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcileSimulatedVMOperator simulates what vm-operator does for a VirtualMachine, i.e. it marks the
+// VirtualMachine as created, powers it on or off as requested, and assigns a BIOS UUID and an IP to it.
+// NOTE: The IP is assigned here because the simulated VirtualMachine does not exist in vcsim, and thus
+// the vmIPReconciler, which customizes the VM in vcsim, must be a no-op for it.
+func (r *VirtualMachineReconciler) reconcileSimulatedVMOperator(ctx context.Context, virtualMachine *vmoprv1.VirtualMachine) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Pause vm-operator for this VirtualMachine, if any, so it does not compete with the simulation.
+	if _, ok := virtualMachine.Annotations[vmoprv1.PauseAnnotation]; !ok {
+		if virtualMachine.Annotations == nil {
+			virtualMachine.Annotations = map[string]string{}
+		}
+		virtualMachine.Annotations[vmoprv1.PauseAnnotation] = ""
+	}
+
+	if virtualMachine.Status.Phase != vmoprv1.Created {
+		log.Info("Simulating vm-operator creating the VirtualMachine")
+		virtualMachine.Status.Phase = vmoprv1.Created
+		virtualMachine.Status.UniqueID = fmt.Sprintf("vm-%s", virtualMachine.UID)
+		virtualMachine.Status.BiosUUID = uuid.New().String()
+		virtualMachine.Status.InstanceUUID = uuid.New().String()
+	}
+
+	if virtualMachine.Status.PowerState != virtualMachine.Spec.PowerState {
+		log.Info("Simulating vm-operator powering the VirtualMachine", "powerState", virtualMachine.Spec.PowerState)
+		virtualMachine.Status.PowerState = virtualMachine.Spec.PowerState
+	}
+
+	switch {
+	case virtualMachine.Status.PowerState != vmoprv1.VirtualMachinePoweredOn:
+		virtualMachine.Status.VmIp = ""
+	case virtualMachine.Status.VmIp == "":
+		log.Info("Simulating vm-operator assigning an IP to the VirtualMachine", "ip", fakeVMIP)
+		virtualMachine.Status.VmIp = fakeVMIP
+	}
+}
+
 func (r *VirtualMachineReconciler) getVMIpReconciler(cluster *clusterv1.Cluster, virtualMachine *vmoprv1.VirtualMachine) *vmIPReconciler {
 	return &vmIPReconciler{
 		Client:            r.Client,
@@ -37,6 +37,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
 	vmwarev1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/vmware/v1beta1"
+	vcsimv1 "sigs.k8s.io/cluster-api-provider-vsphere/test/infrastructure/vcsim/api/v1alpha1"
 )
 
 func Test_Reconcile_VirtualMachine(t *testing.T) {
@@ -300,3 +301,67 @@ func Test_Reconcile_VirtualMachine(t *testing.T) {
 		g.Expect(c.Status).To(Equal(corev1.ConditionTrue))
 	})
 }
+
+func Test_reconcileSimulatedVMOperator(t *testing.T) {
+	t.Run("VirtualMachine is simulated only if a VMOperatorDependencies in the namespace requires it", func(t *testing.T) {
+		g := NewWithT(t)
+
+		dependencies := &vcsimv1.VMOperatorDependencies{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "vcsim",
+			},
+			Spec: vcsimv1.VMOperatorDependenciesSpec{
+				SimulateVMOperator: true,
+			},
+		}
+
+		r := VirtualMachineReconciler{
+			Client: fake.NewClientBuilder().WithObjects(dependencies).WithScheme(scheme).Build(),
+		}
+
+		simulated, err := r.isVMOperatorSimulated(ctx, "foo")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(simulated).To(BeTrue())
+
+		simulated, err = r.isVMOperatorSimulated(ctx, "bar")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(simulated).To(BeFalse())
+	})
+
+	t.Run("VirtualMachine gets created, powered on and an IP", func(t *testing.T) {
+		g := NewWithT(t)
+
+		virtualMachine := &vmoprv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "bar",
+			},
+			Spec: vmoprv1.VirtualMachineSpec{
+				PowerState: vmoprv1.VirtualMachinePoweredOn,
+			},
+		}
+
+		r := VirtualMachineReconciler{}
+		r.reconcileSimulatedVMOperator(ctx, virtualMachine)
+
+		g.Expect(virtualMachine.Annotations).To(HaveKey(vmoprv1.PauseAnnotation))
+		g.Expect(virtualMachine.Status.Phase).To(Equal(vmoprv1.Created))
+		g.Expect(virtualMachine.Status.UniqueID).To(Equal("vm-bar"))
+		g.Expect(virtualMachine.Status.BiosUUID).ToNot(BeEmpty())
+		g.Expect(virtualMachine.Status.PowerState).To(Equal(vmoprv1.VirtualMachinePoweredOn))
+		g.Expect(virtualMachine.Status.VmIp).To(Equal(fakeVMIP))
+
+		// BIOS UUID must not change across reconciles, because it is used to compute the Provider ID.
+		biosUUID := virtualMachine.Status.BiosUUID
+		r.reconcileSimulatedVMOperator(ctx, virtualMachine)
+		g.Expect(virtualMachine.Status.BiosUUID).To(Equal(biosUUID))
+
+		// Powering off the VirtualMachine releases the IP.
+		virtualMachine.Spec.PowerState = vmoprv1.VirtualMachinePoweredOff
+		r.reconcileSimulatedVMOperator(ctx, virtualMachine)
+		g.Expect(virtualMachine.Status.PowerState).To(Equal(vmoprv1.VirtualMachinePoweredOff))
+		g.Expect(virtualMachine.Status.VmIp).To(BeEmpty())
+	})
+}
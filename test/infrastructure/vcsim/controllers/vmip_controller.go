@@ -30,6 +30,9 @@ import (
 	"sigs.k8s.io/cluster-api-provider-vsphere/pkg/session"
 )
 
+// fakeVMIP is the IP assigned to VMs, given that there is no DHCP service in vcsim.
+const fakeVMIP = "192.168.1.100"
+
 type vmIPReconciler struct {
 	Client            client.Client
 	EnableKeepAlive   bool
@@ -89,7 +92,7 @@ func (r *vmIPReconciler) ReconcileIP(ctx context.Context) (ctrl.Result, error) {
 			{
 				Adapter: types.CustomizationIPSettings{
 					Ip: &types.CustomizationFixedIp{
-						IpAddress: "192.168.1.100",
+						IpAddress: fakeVMIP,
 					},
 					SubnetMask:    "255.255.255.0",
 					Gateway:       []string{"192.168.1.1"},
@@ -308,6 +308,7 @@ func (r *VSphereVMReconciler) getVCenterSession(ctx context.Context, vSphereClus
 		WithServer(vSphereVM.Spec.Server).
 		WithDatacenter(vSphereVM.Spec.Datacenter).
 		WithUserInfo(creds.Username, creds.Password).
+		WithCABundle(creds.CABundle).
 		WithThumbprint(vSphereVM.Spec.Thumbprint).
 		WithFeatures(session.Feature{
 			EnableKeepAlive:   r.EnableKeepAlive,